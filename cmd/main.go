@@ -1,8 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/user"
+	"inscripciones/internal/domain"
 	"inscripciones/internal/repository"
 	"inscripciones/internal/service"
 	"inscripciones/internal/ui"
@@ -10,6 +14,14 @@ import (
 )
 
 func main() {
+	// Los comandos de mantenimiento (respaldos) se ejecutan sin abrir la consola
+	if len(os.Args) > 1 {
+		if err := ejecutarComando(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Mostrar mensaje de bienvenida
 	fmt.Println("Sistema de Inscripciones Universitarias")
 	fmt.Println("======================================")
@@ -17,47 +29,141 @@ func main() {
 
 	// Inicializar base de datos
 	fmt.Println("Inicializando base de datos...")
-	db, err := repository.InitDB()
+	repos, err := repository.Abrir(repository.ConfigDesdeEntorno())
 	if err != nil {
 		log.Fatal("Error al inicializar base de datos:", err)
 	}
-	defer db.Close()
-	fmt.Println("✓ Base de datos inicializada correctamente")
+	defer repos.Close()
+	fmt.Printf("✓ Base de datos inicializada correctamente (%s)\n", repos.Backend())
+	if repos.Administrador() {
+		fmt.Printf("✓ Facultad activa: %s (modo administrativo)\n", repos.Facultad())
+	} else {
+		fmt.Printf("✓ Facultad activa: %s\n", repos.Facultad())
+	}
+	fmt.Printf("✓ Periodo académico: %s\n", repos.Periodo())
+	if repos.Cifrado() {
+		fmt.Println("✓ Datos personales cifrados")
+	}
 
-	// Crear repositorios
-	estudianteRepo := repository.NewEstudianteRepository(db)
-	materiaRepo := repository.NewMateriaRepository(db)
-	inscripcionRepo := repository.NewInscripcionRepository(db)
+	// Las lecturas repetidas de estudiantes y materias se sirven desde memoria
+	repos = repos.ConCache(repository.CachePorDefecto)
+
+	// Crear repositorios: los cambios quedan auditados a nombre del usuario del sistema,
+	// distinguiendo los hechos desde la consola de los que vienen de archivos
+	actor := usuarioActual()
+	reposConsola := repos.ConAuditoria(repository.ContextoAuditoria{Actor: actor, Origen: domain.OrigenConsola})
+	reposArchivo := repos.ConAuditoria(repository.ContextoAuditoria{Actor: actor, Origen: domain.OrigenArchivo})
+
+	estudianteRepo := reposConsola.Estudiantes
+	materiaRepo := reposConsola.Materias
+	inscripcionRepo := reposConsola.Inscripciones
+
+	// La carga de créditos permitida se aplica tanto a los archivos como a la consola
+	limitesCreditos, err := service.LimitesCreditosDesdeEntorno()
+	if err != nil {
+		log.Fatal("Error en la configuración de créditos:", err)
+	}
+
+	// Las reglas de la nota definitiva valen igual para la consola y para cada periodo
+	reglasCalificacion, err := service.ReglasCalificacionDesdeEntorno()
+	if err != nil {
+		log.Fatal("Error en la configuración de calificaciones:", err)
+	}
 
 	// Crear servicios
 	lectorArchivo := &fileutil.LectorArchivoTexto{}
 	procesadorArchivo := service.NewProcesadorArchivo(
 		lectorArchivo,
+		reposArchivo,
+	)
+	procesadorArchivo.EstablecerLimitesCreditos(limitesCreditos)
+	procesadorArchivo.EstablecerReglasCalificacion(reglasCalificacion)
+
+	inscripcionService := service.NewInscripcionService(
 		estudianteRepo,
 		materiaRepo,
 		inscripcionRepo,
 	)
 
-	inscripcionService := service.NewInscripcionService(
+	consultasAvanzadasService := service.NewConsultasAvanzadasService(
+		reposConsola,
 		estudianteRepo,
 		materiaRepo,
 		inscripcionRepo,
 	)
+	consultasAvanzadasService.EstablecerLimitesCreditos(limitesCreditos)
+	consultasAvanzadasService.EstablecerReglasCalificacion(reglasCalificacion)
 
-	consultasAvanzadasService := service.NewConsultasAvanzadasService(
+	historialService := service.NewHistorialService(repos.Auditoria)
+
+	eliminacionService := service.NewEliminacionService(
+		reposConsola,
 		estudianteRepo,
 		materiaRepo,
 		inscripcionRepo,
 	)
+	eliminacionService.EstablecerLimitesCreditos(limitesCreditos)
+
+	edicionService := service.NewEdicionService(
+		estudianteRepo,
+		materiaRepo,
+	)
+
+	facultadesService := service.NewFacultadesService(reposConsola)
+
+	periodosService := service.NewPeriodosService(reposConsola)
+	periodosService.EstablecerLimitesCreditos(limitesCreditos)
+	periodosService.EstablecerReglasCalificacion(reglasCalificacion)
+
+	prerrequisitosService := service.NewPrerrequisitosService(reposConsola)
+	prerrequisitosService.EstablecerReglasCalificacion(reglasCalificacion)
+
+	cuposService := service.NewCuposService(reposConsola)
+	cuposService.EstablecerLimitesCreditos(limitesCreditos)
+
+	gruposService := service.NewGruposService(reposConsola)
+
+	horariosService := service.NewHorariosService(reposConsola)
+
+	calificacionesService := service.NewCalificacionesService(reposConsola)
+	calificacionesService.EstablecerReglas(reglasCalificacion)
+	procesadorCalificaciones := service.NewProcesadorCalificaciones(lectorArchivo, reposArchivo)
+
+	historialAcademicoService := service.NewHistorialAcademicoService(reposConsola)
+	historialAcademicoService.EstablecerReglas(reglasCalificacion)
+
+	carrerasService := service.NewCarrerasService(reposConsola)
+	carrerasService.EstablecerReglas(reglasCalificacion)
+
+	docentesService := service.NewDocentesService(reposConsola)
+	procesadorDocentes := service.NewProcesadorDocentes(lectorArchivo, reposArchivo)
 
 	// Crear interfaz de usuario
 	consoleUI := ui.NewConsoleUI(
 		procesadorArchivo,
 		inscripcionService,
 		consultasAvanzadasService,
+		historialService,
+		eliminacionService,
+		edicionService,
+		facultadesService,
+		periodosService,
+		prerrequisitosService,
+		cuposService,
+		gruposService,
+		horariosService,
+		calificacionesService,
+		procesadorCalificaciones,
+		historialAcademicoService,
+		carrerasService,
+		docentesService,
+		procesadorDocentes,
 	)
 
 	fmt.Println("✓ Servicios inicializados correctamente")
+	fmt.Printf("✓ Carga por estudiante: entre %d y %d créditos\n", limitesCreditos.Minimo, limitesCreditos.Maximo)
+	fmt.Printf("✓ Nota aprobatoria: %s (definitiva: redondeo %s, decimales %d)\n",
+		domain.FormatearNota(reglasCalificacion.NotaAprobatoria, domain.MaximoDecimales), reglasCalificacion.Redondeo, reglasCalificacion.Decimales)
 	fmt.Println()
 	fmt.Println("INSTRUCCIONES:")
 	fmt.Println("- Para cargar archivos desde testdata/, solo escriba el nombre del archivo")
@@ -67,4 +173,206 @@ func main() {
 
 	// Iniciar la interfaz de usuario
 	consoleUI.MostrarMenu()
-}
\ No newline at end of file
+
+	estudiantes, materias := repos.EstadisticasCache()
+	fmt.Printf("Caché de lecturas: estudiantes %d aciertos / %d fallos, materias %d aciertos / %d fallos\n",
+		estudiantes.Aciertos, estudiantes.Fallos, materias.Aciertos, materias.Fallos)
+}
+
+// usuarioActual identifica al actor de los cambios con el usuario del sistema operativo
+func usuarioActual() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if nombre := os.Getenv("USER"); nombre != "" {
+		return nombre
+	}
+	return domain.OrigenSistema
+}
+
+// ejecutarComando atiende los comandos de mantenimiento que se ejecutan sin abrir la consola
+func ejecutarComando(nombre string, args []string) error {
+	cfg := repository.ConfigDesdeEntorno()
+
+	switch nombre {
+	case "respaldar":
+		flags := flag.NewFlagSet("respaldar", flag.ExitOnError)
+		directorio := flags.String("dir", "", "directorio de los respaldos (por defecto, respaldos/ junto a la base)")
+		conservar := flags.Int("conservar", repository.RespaldosPorDefecto, "cantidad de respaldos a conservar")
+		flags.Parse(args)
+
+		ruta, err := repository.Respaldar(cfg, repository.OpcionesRespaldo{Directorio: *directorio, Conservar: *conservar})
+		if err != nil {
+			return fmt.Errorf("error al respaldar: %w", err)
+		}
+		fmt.Printf("✓ Respaldo creado y verificado: %s\n", ruta)
+		return nil
+
+	case "restaurar":
+		// El respaldo reemplaza la base completa, con los datos de todas las facultades
+		if !cfg.Administrador {
+			return fmt.Errorf("restaurar reemplaza los datos de todas las facultades: %w (defina %s=1)",
+				repository.ErrNoAutorizado, repository.EnvAdministrador)
+		}
+		flags := flag.NewFlagSet("restaurar", flag.ExitOnError)
+		flags.Usage = func() {
+			fmt.Println("Uso: inscripciones restaurar <archivo de respaldo>")
+		}
+		flags.Parse(args)
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return fmt.Errorf("se debe indicar el archivo de respaldo")
+		}
+
+		if err := repository.Restaurar(cfg, flags.Arg(0)); err != nil {
+			return fmt.Errorf("error al restaurar: %w", err)
+		}
+		fmt.Printf("✓ Base de datos restaurada desde %s\n", flags.Arg(0))
+		return nil
+
+	case "respaldos":
+		flags := flag.NewFlagSet("respaldos", flag.ExitOnError)
+		directorio := flags.String("dir", "", "directorio de los respaldos (por defecto, respaldos/ junto a la base)")
+		flags.Parse(args)
+
+		respaldos, err := repository.ListarRespaldos(cfg, *directorio)
+		if err != nil {
+			return fmt.Errorf("error al listar respaldos: %w", err)
+		}
+		if len(respaldos) == 0 {
+			fmt.Println("No hay respaldos.")
+		}
+		for _, ruta := range respaldos {
+			fmt.Println(ruta)
+		}
+		return nil
+
+	case "volcar":
+		flags := flag.NewFlagSet("volcar", flag.ExitOnError)
+		archivo := flags.String("o", "", "archivo de salida (por defecto, la salida estándar)")
+		flags.Parse(args)
+
+		repos, err := repository.Abrir(cfg)
+		if err != nil {
+			return err
+		}
+		defer repos.Close()
+
+		salida := os.Stdout
+		if *archivo != "" {
+			salida, err = os.Create(*archivo)
+			if err != nil {
+				return fmt.Errorf("error al crear archivo: %w", err)
+			}
+			defer salida.Close()
+		}
+		if err := repos.Volcar(salida); err != nil {
+			return fmt.Errorf("error al volcar datos: %w", err)
+		}
+		if *archivo != "" {
+			fmt.Printf("✓ Datos volcados en %s\n", *archivo)
+		}
+		return nil
+
+	case "cargar":
+		flags := flag.NewFlagSet("cargar", flag.ExitOnError)
+		flags.Usage = func() {
+			fmt.Println("Uso: inscripciones cargar <script SQL generado con volcar>")
+		}
+		flags.Parse(args)
+		if flags.NArg() != 1 {
+			flags.Usage()
+			return fmt.Errorf("se debe indicar el script a cargar")
+		}
+
+		entrada, err := os.Open(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("error al abrir archivo: %w", err)
+		}
+		defer entrada.Close()
+
+		repos, err := repository.Abrir(cfg)
+		if err != nil {
+			return err
+		}
+		defer repos.Close()
+
+		auditados := repos.ConAuditoria(repository.ContextoAuditoria{Actor: usuarioActual(), Origen: domain.OrigenArchivo})
+		if err := auditados.Cargar(entrada); err != nil {
+			return fmt.Errorf("error al cargar datos: %w", err)
+		}
+		fmt.Printf("✓ Datos cargados desde %s (%s)\n", flags.Arg(0), repos.Backend())
+		return nil
+
+	case "generar-clave":
+		flags := flag.NewFlagSet("generar-clave", flag.ExitOnError)
+		archivo := flags.String("o", "", "archivo donde guardar la clave (por defecto, la salida estándar)")
+		flags.Parse(args)
+
+		clave := repository.GenerarClave()
+		if *archivo == "" {
+			fmt.Println(clave)
+			return nil
+		}
+		// O_EXCL evita pisar por error la clave de una base ya cifrada
+		salida, err := os.OpenFile(*archivo, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return fmt.Errorf("error al crear archivo de clave: %w", err)
+		}
+		defer salida.Close()
+		if _, err := fmt.Fprintln(salida, clave); err != nil {
+			return fmt.Errorf("error al guardar la clave: %w", err)
+		}
+		fmt.Printf("✓ Clave guardada en %s\n", *archivo)
+		return nil
+
+	case "rotar-clave":
+		// Vuelve a cifrar los datos personales de todas las facultades
+		if !cfg.Administrador {
+			return fmt.Errorf("rotar-clave modifica los datos de todas las facultades: %w (defina %s=1)",
+				repository.ErrNoAutorizado, repository.EnvAdministrador)
+		}
+		flags := flag.NewFlagSet("rotar-clave", flag.ExitOnError)
+		archivoNueva := flags.String("nueva", "", "archivo con la clave nueva")
+		descifrar := flags.Bool("descifrar", false, "dejar los datos sin cifrar en lugar de usar una clave nueva")
+		flags.Usage = func() {
+			fmt.Println("Uso: inscripciones rotar-clave -nueva <archivo de clave> | -descifrar")
+			fmt.Printf("La clave actual se toma de %s o %s; sin ninguna, se cifra una base en texto plano.\n",
+				repository.EnvClave, repository.EnvArchivoClave)
+		}
+		flags.Parse(args)
+		if (*archivoNueva == "") == !*descifrar {
+			flags.Usage()
+			return fmt.Errorf("se debe indicar -nueva o -descifrar")
+		}
+
+		actual, err := cfg.Cifrador()
+		if err != nil {
+			return err
+		}
+		var nueva *repository.Cifrador
+		if *archivoNueva != "" {
+			if nueva, err = repository.CifradorDesdeArchivo(*archivoNueva); err != nil {
+				return err
+			}
+		}
+
+		db, dialecto, err := repository.Conectar(cfg)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		if err := repository.Recifrar(db, dialecto, actual, nueva); err != nil {
+			return fmt.Errorf("error al rotar la clave: %w", err)
+		}
+		if nueva == nil {
+			fmt.Println("✓ Datos personales descifrados; la base ya no usa clave")
+		} else {
+			fmt.Printf("✓ Datos personales cifrados con la clave de %s; úsela desde ahora en %s\n", *archivoNueva, repository.EnvArchivoClave)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("comando desconocido %q (disponibles: respaldar, restaurar, respaldos, volcar, cargar, generar-clave, rotar-clave)", nombre)
+	}
+}
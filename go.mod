@@ -2,7 +2,10 @@ module inscripciones
 
 go 1.24.3
 
-require github.com/glebarez/go-sqlite v1.21.2
+require (
+	github.com/glebarez/go-sqlite v1.21.2
+	github.com/lib/pq v1.10.9
+)
 
 require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// Entidades auditadas
+const (
+    EntidadEstudiante   = "estudiante"
+    EntidadMateria      = "materia"
+    EntidadInscripcion  = "inscripcion"
+    EntidadCalificacion = "calificacion"
+    EntidadCarrera      = "carrera"
+)
+
+// Operaciones auditadas
+const (
+    OperacionCrear      = "crear"
+    OperacionActualizar = "actualizar"
+    OperacionEliminar   = "eliminar"
+    OperacionRestaurar  = "restaurar"
+)
+
+// Orígenes de un cambio
+const (
+    OrigenConsola = "consola"
+    OrigenArchivo = "archivo"
+    OrigenAPI     = "api"
+    OrigenSistema = "sistema"
+)
+
+// RegistroAuditoria describe un cambio sobre los datos: quién, cuándo, desde dónde y
+// los valores antes y después (en JSON; vacío si no aplica)
+type RegistroAuditoria struct {
+    ID        int64
+    Fecha     time.Time
+    Actor     string
+    Origen    string
+    Entidad   string
+    Clave     string
+    Operacion string
+    Antes     string
+    Despues   string
+}
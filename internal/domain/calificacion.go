@@ -0,0 +1,201 @@
+package domain
+
+import (
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+)
+
+// Escala de las notas: de 0.0 a 5.0, con a lo sumo dos decimales
+const (
+    NotaMinima = 0.0
+    NotaMaxima = 5.0
+)
+
+// PesoTotal es la suma, en porcentaje, de los pesos de los componentes de una materia
+// cuya evaluación está completa
+const PesoTotal = 100
+
+// Componente es una parte de la evaluación de una materia, como un parcial o el examen
+// final, con su peso en porcentaje sobre la nota definitiva. Como los grupos, son comunes
+// a todos los periodos.
+type Componente struct {
+    Materia string
+    Nombre  string
+    Peso    int
+}
+
+// Calificacion es la nota de un estudiante en un componente de una materia en un periodo
+type Calificacion struct {
+    Cedula     string
+    Materia    string
+    Periodo    string
+    Componente string
+    Nota       float64
+}
+
+// ParseNota interpreta una nota de la escala, con punto o coma decimal
+func ParseNota(texto string) (float64, error) {
+    texto = strings.TrimSpace(texto)
+    entero, decimales, _ := strings.Cut(strings.Replace(texto, ",", ".", 1), ".")
+    digitos := entero + decimales + strings.Repeat("0", max(0, 2-len(decimales)))
+    centesimas, err := strconv.Atoi(digitos)
+    if err != nil || entero == "" || len(decimales) > 2 || strings.ContainsAny(digitos, "+-") || centesimas > Centesimas(NotaMaxima) {
+        return 0, fmt.Errorf("la nota '%s' debe ser un número de %.1f a %.1f con a lo sumo dos decimales", texto, NotaMinima, NotaMaxima)
+    }
+    return NotaDeCentesimas(centesimas), nil
+}
+
+// ValidarNota comprueba que la nota esté en la escala y no tenga más de dos decimales
+func ValidarNota(nota float64) error {
+    if math.IsNaN(nota) || nota < NotaMinima || nota > NotaMaxima || math.Abs(nota*100-math.Round(nota*100)) > 1e-6 {
+        return fmt.Errorf("la nota %v debe estar entre %.1f y %.1f con a lo sumo dos decimales", nota, NotaMinima, NotaMaxima)
+    }
+    return nil
+}
+
+// Centesimas expresa la nota en centésimas, que es como se guarda y se promedia sin
+// errores de redondeo
+func Centesimas(nota float64) int {
+    return int(math.Round(nota * 100))
+}
+
+// NotaDeCentesimas convierte centésimas en la nota correspondiente
+func NotaDeCentesimas(centesimas int) float64 {
+    return float64(centesimas) / 100
+}
+
+// FormatearNota escribe la nota con la cantidad de decimales indicada
+func FormatearNota(nota float64, decimales int) string {
+    return fmt.Sprintf("%.*f", decimales, nota)
+}
+
+// ModoRedondeo indica cómo se lleva la nota definitiva a los decimales de las reglas
+type ModoRedondeo int
+
+const (
+    // Aproximar lleva la nota al valor más cercano; los medios suben (2.95 es 3.0)
+    Aproximar ModoRedondeo = iota
+    // Truncar descarta los decimales que sobran (2.99 es 2.9)
+    Truncar
+)
+
+func (m ModoRedondeo) String() string {
+    switch m {
+    case Aproximar:
+        return "aproximar"
+    case Truncar:
+        return "truncar"
+    }
+    return fmt.Sprintf("ModoRedondeo(%d)", int(m))
+}
+
+// ParseModoRedondeo reconoce "aproximar" o "truncar", sin importar mayúsculas
+func ParseModoRedondeo(texto string) (ModoRedondeo, error) {
+    for _, m := range []ModoRedondeo{Aproximar, Truncar} {
+        if strings.EqualFold(strings.TrimSpace(texto), m.String()) {
+            return m, nil
+        }
+    }
+    return 0, fmt.Errorf("el redondeo '%s' debe ser aproximar o truncar", texto)
+}
+
+// MaximoDecimales acota los decimales de la nota definitiva a los de las notas registradas
+const MaximoDecimales = 2
+
+// ReglasCalificacion fijan cómo se calcula la nota definitiva de una materia: con cuántos
+// decimales, cómo se redondea y desde qué nota se aprueba
+type ReglasCalificacion struct {
+    Decimales       int
+    Redondeo        ModoRedondeo
+    NotaAprobatoria float64
+}
+
+// ReglasCalificacionPorDefecto son las reglas que se usan cuando no se configuran otras
+var ReglasCalificacionPorDefecto = ReglasCalificacion{Decimales: 1, Redondeo: Aproximar, NotaAprobatoria: 3.0}
+
+// Validar comprueba que las reglas se puedan aplicar
+func (r ReglasCalificacion) Validar() error {
+    if r.Decimales < 0 || r.Decimales > MaximoDecimales {
+        return fmt.Errorf("los decimales de la nota definitiva deben estar entre 0 y %d, se recibió %d", MaximoDecimales, r.Decimales)
+    }
+    if r.Redondeo != Aproximar && r.Redondeo != Truncar {
+        return fmt.Errorf("modo de redondeo desconocido: %v", r.Redondeo)
+    }
+    if err := ValidarNota(r.NotaAprobatoria); err != nil {
+        return fmt.Errorf("nota aprobatoria inválida: %w", err)
+    }
+    return nil
+}
+
+// Aprueba indica si una nota definitiva alcanza la nota aprobatoria
+func (r ReglasCalificacion) Aprueba(nota float64) bool {
+    return Centesimas(nota) >= Centesimas(r.NotaAprobatoria)
+}
+
+// Definitiva es la nota definitiva de una inscripción. Mientras falten componentes por
+// calificar es parcial: acumula solo los calificados, como si los demás valieran cero.
+type Definitiva struct {
+    Nota float64
+    // PesoCalificado es la suma de los pesos de los componentes que tienen nota
+    PesoCalificado int
+    // Completa indica que todos los componentes tienen nota y sus pesos suman PesoTotal
+    Completa bool
+    // Aprobada solo puede ser verdadera en una definitiva completa
+    Aprobada bool
+}
+
+// Definitiva calcula la nota definitiva con las notas de cada componente, por nombre. El
+// promedio ponderado se hace en centésimas y solo al final se redondea según las reglas.
+func (r ReglasCalificacion) Definitiva(componentes []Componente, notas map[string]float64) Definitiva {
+    var d Definitiva
+    acumulado := 0 // centésimas de nota por punto porcentual de peso
+    for _, c := range componentes {
+        if nota, ok := notas[c.Nombre]; ok {
+            acumulado += Centesimas(nota) * c.Peso
+            d.PesoCalificado += c.Peso
+        }
+    }
+
+    d.Nota = r.redondear(acumulado, 100*PesoTotal, r.Decimales)
+    d.Completa = d.PesoCalificado == PesoTotal
+    d.Aprobada = d.Completa && r.Aprueba(d.Nota)
+    return d
+}
+
+// redondear divide acumulado entre divisor y lleva el resultado a los decimales indicados
+// con el modo de redondeo de las reglas, sin pasar por números de punto flotante
+func (r ReglasCalificacion) redondear(acumulado, divisor, decimales int) float64 {
+    escala := 1
+    for i := 0; i < decimales; i++ {
+        escala *= 10
+    }
+    valor := acumulado * escala / divisor
+    if r.Redondeo == Aproximar {
+        valor = (acumulado*escala + divisor/2) / divisor
+    }
+    return float64(valor) / float64(escala)
+}
+
+// PromedioPonderado acumula definitivas ponderadas por los créditos de su materia. El valor
+// cero sirve para empezar a acumular.
+type PromedioPonderado struct {
+    puntos   int // centésimas de nota por crédito
+    Creditos int
+}
+
+// Agregar suma al promedio la definitiva de una materia con sus créditos
+func (p *PromedioPonderado) Agregar(nota float64, creditos int) {
+    p.puntos += Centesimas(nota) * creditos
+    p.Creditos += creditos
+}
+
+// Valor retorna el promedio con MaximoDecimales decimales, redondeado según las reglas;
+// cero si no se ha agregado ninguna materia
+func (p PromedioPonderado) Valor(r ReglasCalificacion) float64 {
+    if p.Creditos == 0 {
+        return 0
+    }
+    return r.redondear(p.puntos, 100*p.Creditos, MaximoDecimales)
+}
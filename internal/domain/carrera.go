@@ -0,0 +1,28 @@
+package domain
+
+// MaximoSemestres es el último semestre en el que puede ubicarse una materia del pensum
+const MaximoSemestres = 12
+
+// Carrera es un programa académico de la facultad. Creditos son los que el estudiante
+// debe aprobar para graduarse, entre materias obligatorias y electivas.
+type Carrera struct {
+    Codigo   string
+    Nombre   string
+    Creditos int
+}
+
+// MateriaPensum ubica una materia en el pensum de una carrera: el semestre en el que se
+// recomienda cursarla y si es obligatoria o electiva
+type MateriaPensum struct {
+    Carrera     string
+    Materia     string
+    Semestre    int
+    Obligatoria bool
+}
+
+// AsignacionCarrera indica la carrera a la que pertenece un estudiante; cada estudiante
+// pertenece a lo sumo a una
+type AsignacionCarrera struct {
+    Cedula  string
+    Carrera string
+}
@@ -0,0 +1,28 @@
+package domain
+
+// CreditosPorDefecto son los créditos de una materia cuando no se indican otros
+const CreditosPorDefecto = 3
+
+// MaximoCreditosPorMateria acota los créditos que puede tener una sola materia
+const MaximoCreditosPorMateria = 10
+
+// LimitesCreditos es la carga de créditos permitida a cada estudiante en un periodo.
+// Superar el máximo es una sobrecarga y se rechaza; quedar por debajo del mínimo solo
+// se señala, porque la carga se completa inscripción por inscripción.
+type LimitesCreditos struct {
+    Minimo int
+    Maximo int
+}
+
+// LimitesCreditosPorDefecto son los límites que se usan cuando no se configuran otros
+var LimitesCreditosPorDefecto = LimitesCreditos{Minimo: 8, Maximo: 20}
+
+// Excede indica si la carga supera el máximo permitido
+func (l LimitesCreditos) Excede(creditos int) bool {
+    return creditos > l.Maximo
+}
+
+// PorDebajo indica si la carga no alcanza el mínimo
+func (l LimitesCreditos) PorDebajo(creditos int) bool {
+    return creditos < l.Minimo
+}
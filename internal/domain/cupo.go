@@ -0,0 +1,25 @@
+package domain
+
+// SinCupoLimite es el cupo de una materia que admite a todos los inscritos
+const SinCupoLimite = 0
+
+// Espera es un estudiante en la lista de espera de una materia sin cupos en un periodo.
+// La posición 1 es la del siguiente en recibir un cupo cuando se libere, en el grupo que
+// pidió el estudiante o sin grupo si es SinGrupo.
+type Espera struct {
+    Estudiante *Estudiante
+    Codigo     string
+    Periodo    string
+    Grupo      int
+    Posicion   int
+}
+
+// CupoAgotado indica si una materia con el cupo dado ya no admite más inscritos
+func CupoAgotado(cupo, inscritos int) bool {
+    return cupo != SinCupoLimite && inscritos >= cupo
+}
+
+// ExcedeCupo indica si los inscritos superan el cupo de la materia
+func ExcedeCupo(cupo, inscritos int) bool {
+    return cupo != SinCupoLimite && inscritos > cupo
+}
@@ -0,0 +1,25 @@
+package domain
+
+// Docente es un profesor de la facultad. Como los del estudiante, su cédula y su nombre
+// son datos personales y se guardan cifrados cuando la base tiene clave.
+type Docente struct {
+    Cedula string
+    Nombre string
+}
+
+func NewDocente(cedula, nombre string) *Docente {
+    return &Docente{
+        Cedula: cedula,
+        Nombre: nombre,
+    }
+}
+
+// AsignacionDocente indica qué docente dicta una materia, o uno de sus grupos, en un
+// periodo. Con Grupo SinGrupo el docente dicta la materia completa y tiene a todos sus
+// inscritos, como las sesiones de SinGrupo son las de todos ellos.
+type AsignacionDocente struct {
+    Docente string
+    Materia string
+    Periodo string
+    Grupo   int
+}
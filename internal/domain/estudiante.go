@@ -1,8 +1,14 @@
 package domain
 
+import "time"
+
 type Estudiante struct {
     Cedula string
     Nombre string
+    // EliminadoEn es la fecha de eliminación lógica; nil si el registro está activo
+    EliminadoEn *time.Time
+    // Version aumenta con cada actualización; Update la exige para no pisar cambios ajenos
+    Version int64
 }
 
 func NewEstudiante(cedula, nombre string) *Estudiante {
@@ -0,0 +1,73 @@
+package domain
+
+import (
+    "encoding/json"
+    "time"
+)
+
+// Tipos de eventos de dominio
+const (
+    EventoEstudianteCreado     = "EstudianteCreado"
+    EventoMateriaCreada        = "MateriaCreada"
+    EventoInscripcionCreada    = "InscripcionCreada"
+    EventoInscripcionCancelada = "InscripcionCancelada"
+)
+
+// Evento es un hecho ya ocurrido, tal como se guarda en el outbox y se entrega a los suscriptores
+type Evento struct {
+    ID    int64
+    Tipo  string
+    Fecha time.Time
+    // Datos es el contenido en JSON; Decodificar lo lee en la estructura del tipo de evento
+    Datos string
+    // Intentos es la cantidad de entregas fallidas hasta ahora
+    Intentos int
+}
+
+// DatosEvento es el contenido de un evento de dominio
+type DatosEvento interface {
+    TipoEvento() string
+}
+
+type EstudianteCreado struct {
+    Cedula string `json:"cedula"`
+    Nombre string `json:"nombre"`
+}
+
+type MateriaCreada struct {
+    Codigo string `json:"codigo"`
+    Nombre string `json:"nombre"`
+}
+
+type InscripcionCreada struct {
+    Cedula  string `json:"estudiante_cedula"`
+    Codigo  string `json:"materia_codigo"`
+    Periodo string `json:"periodo"`
+}
+
+type InscripcionCancelada struct {
+    Cedula  string `json:"estudiante_cedula"`
+    Codigo  string `json:"materia_codigo"`
+    Periodo string `json:"periodo"`
+}
+
+func (EstudianteCreado) TipoEvento() string     { return EventoEstudianteCreado }
+func (MateriaCreada) TipoEvento() string        { return EventoMateriaCreada }
+func (InscripcionCreada) TipoEvento() string    { return EventoInscripcionCreada }
+func (InscripcionCancelada) TipoEvento() string { return EventoInscripcionCancelada }
+
+// NewEvento arma el evento con la fecha actual
+func NewEvento(datos DatosEvento) *Evento {
+    // Los datos de los eventos son estructuras de texto plano: la codificación no falla
+    contenido, _ := json.Marshal(datos)
+    return &Evento{
+        Tipo:  datos.TipoEvento(),
+        Fecha: time.Now().UTC(),
+        Datos: string(contenido),
+    }
+}
+
+// Decodificar lee los datos del evento en destino, que debe ser la estructura de su tipo
+func (e *Evento) Decodificar(destino DatosEvento) error {
+    return json.Unmarshal([]byte(e.Datos), destino)
+}
@@ -0,0 +1,11 @@
+package domain
+
+// SinGrupo es el grupo de una inscripción que no está asignada a ninguno de los grupos de su materia
+const SinGrupo = 0
+
+// Grupo es una sección de una materia, con su propio horario y profesor. Los grupos se
+// numeran desde 1 dentro de cada materia y son comunes a todos los periodos.
+type Grupo struct {
+    Materia string
+    Numero  int
+}
@@ -0,0 +1,142 @@
+package domain
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// Dia es un día de la semana, de Lunes (1) a Domingo (7)
+type Dia int
+
+const (
+    Lunes Dia = iota + 1
+    Martes
+    Miercoles
+    Jueves
+    Viernes
+    Sabado
+    Domingo
+)
+
+var nombresDia = [...]string{"", "Lunes", "Martes", "Miércoles", "Jueves", "Viernes", "Sábado", "Domingo"}
+
+// sinTildes quita las tildes de los nombres de los días para compararlos
+var sinTildes = strings.NewReplacer("á", "a", "é", "e", "Á", "A", "É", "E")
+
+// Valido indica si el día está entre Lunes y Domingo
+func (d Dia) Valido() bool {
+    return d >= Lunes && d <= Domingo
+}
+
+func (d Dia) String() string {
+    if !d.Valido() {
+        return fmt.Sprintf("Dia(%d)", int(d))
+    }
+    return nombresDia[d]
+}
+
+// ParseDia reconoce el nombre del día, sin importar mayúsculas ni tildes, o su número de 1 a 7
+func ParseDia(texto string) (Dia, error) {
+    texto = strings.TrimSpace(texto)
+    if n, err := strconv.Atoi(texto); err == nil && Dia(n).Valido() {
+        return Dia(n), nil
+    }
+    for d := Lunes; d <= Domingo; d++ {
+        if strings.EqualFold(sinTildes.Replace(texto), sinTildes.Replace(nombresDia[d])) {
+            return d, nil
+        }
+    }
+    return 0, fmt.Errorf("el día '%s' debe ser un día de la semana o un número de 1 (lunes) a 7 (domingo)", texto)
+}
+
+// MinutosPorDia acota las horas de una sesión, que se expresan en minutos desde la medianoche
+const MinutosPorDia = 24 * 60
+
+// ParseHora interpreta una hora HH:MM como minutos desde la medianoche; 24:00 es el fin del día
+func ParseHora(texto string) (int, error) {
+    texto = strings.TrimSpace(texto)
+    horas, minutos, ok := strings.Cut(texto, ":")
+    h, errHoras := strconv.Atoi(horas)
+    m, errMinutos := strconv.Atoi(minutos)
+    if !ok || len(minutos) != 2 || errHoras != nil || errMinutos != nil || h < 0 || m < 0 || m > 59 || h*60+m > MinutosPorDia {
+        return 0, fmt.Errorf("la hora '%s' debe tener el formato HH:MM, entre 00:00 y 24:00", texto)
+    }
+    return h*60 + m, nil
+}
+
+// FormatearHora escribe los minutos desde la medianoche como HH:MM
+func FormatearHora(minutos int) string {
+    return fmt.Sprintf("%02d:%02d", minutos/60, minutos%60)
+}
+
+// Sesion es un encuentro semanal de clase de una materia, en un día, de Inicio a Fin y en
+// un salón. Las sesiones de SinGrupo son de todos los inscritos en la materia; las de un
+// grupo, solo de los inscritos en él. Como los grupos, son comunes a todos los periodos.
+type Sesion struct {
+    Materia string
+    Grupo   int
+    Dia     Dia
+    // Inicio y Fin son minutos desde la medianoche; la sesión ocupa desde Inicio hasta antes de Fin
+    Inicio int
+    Fin    int
+    Salon  string
+}
+
+// AplicaA indica si la sesión es de los inscritos en el grupo de la materia
+func (s Sesion) AplicaA(grupo int) bool {
+    return s.Grupo == SinGrupo || s.Grupo == grupo
+}
+
+// SeCruzaCon indica si las dos sesiones ocupan parte del mismo tiempo; una sesión que
+// empieza cuando la otra termina no se cruza con ella
+func (s Sesion) SeCruzaCon(otra Sesion) bool {
+    return s.Dia == otra.Dia && s.Inicio < otra.Fin && otra.Inicio < s.Fin
+}
+
+// Franja describe el día y las horas de la sesión, como "Lunes 08:00-10:00"
+func (s Sesion) Franja() string {
+    return fmt.Sprintf("%s %s-%s", s.Dia, FormatearHora(s.Inicio), FormatearHora(s.Fin))
+}
+
+// Antes ordena las sesiones por día, hora de inicio, materia y grupo
+func (s Sesion) Antes(otra Sesion) bool {
+    if s.Dia != otra.Dia {
+        return s.Dia < otra.Dia
+    }
+    if s.Inicio != otra.Inicio {
+        return s.Inicio < otra.Inicio
+    }
+    if s.Materia != otra.Materia {
+        return s.Materia < otra.Materia
+    }
+    return s.Grupo < otra.Grupo
+}
+
+// Cruce es un par de sesiones de materias distintas del horario de un estudiante que
+// ocupan el mismo tiempo; A es la que empieza primero
+type Cruce struct {
+    A Sesion
+    B Sesion
+}
+
+// Cruces retorna los pares de sesiones de materias distintas que se cruzan, ordenados por
+// día y hora
+func Cruces(sesiones []Sesion) []Cruce {
+    ordenadas := append([]Sesion(nil), sesiones...)
+    sort.Slice(ordenadas, func(i, j int) bool { return ordenadas[i].Antes(ordenadas[j]) })
+
+    var cruces []Cruce
+    for i, a := range ordenadas {
+        for _, b := range ordenadas[i+1:] {
+            if b.Dia != a.Dia || b.Inicio >= a.Fin {
+                break
+            }
+            if a.Materia != b.Materia {
+                cruces = append(cruces, Cruce{A: a, B: b})
+            }
+        }
+    }
+    return cruces
+}
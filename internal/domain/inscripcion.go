@@ -1,8 +1,16 @@
 package domain
 
+import "time"
+
 type Inscripcion struct {
     Estudiante *Estudiante
     Materia    *Materia
+    // Periodo es el código del periodo académico de la inscripción
+    Periodo string
+    // Grupo es el número del grupo de la materia; SinGrupo si no se asignó ninguno
+    Grupo int
+    // EliminadaEn es la fecha de cancelación lógica; nil si la inscripción está activa
+    EliminadaEn *time.Time
 }
 
 type ConsolidadoInscripciones struct {
@@ -1,13 +1,24 @@
 package domain
 
+import "time"
+
 type Materia struct {
     Codigo string
     Nombre string
+    // Creditos es el peso de la materia en la carga académica del estudiante
+    Creditos int
+    // Cupo es la cantidad de inscritos que admite en cada periodo; SinCupoLimite no pone límite
+    Cupo int
+    // EliminadoEn es la fecha de eliminación lógica; nil si el registro está activo
+    EliminadoEn *time.Time
+    // Version aumenta con cada actualización; Update la exige para no pisar cambios ajenos
+    Version int64
 }
 
 func NewMateria(codigo, nombre string) *Materia {
     return &Materia{
-        Codigo: codigo,
-        Nombre: nombre,
+        Codigo:   codigo,
+        Nombre:   nombre,
+        Creditos: CreditosPorDefecto,
     }
 }
\ No newline at end of file
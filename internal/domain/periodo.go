@@ -0,0 +1,23 @@
+package domain
+
+import (
+    "fmt"
+    "time"
+)
+
+// Periodo es un periodo académico, como 2026-1; las inscripciones se registran en uno
+type Periodo struct {
+    Codigo string
+    // Actual indica el periodo en el que se trabaja cuando no se indica otro
+    Actual bool
+}
+
+// PeriodoDeFecha retorna el periodo semestral de la fecha: de enero a junio el primero
+// del año y de julio a diciembre el segundo
+func PeriodoDeFecha(fecha time.Time) string {
+    semestre := 1
+    if fecha.Month() > time.June {
+        semestre = 2
+    }
+    return fmt.Sprintf("%04d-%d", fecha.Year(), semestre)
+}
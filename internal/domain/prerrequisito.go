@@ -0,0 +1,77 @@
+package domain
+
+import "sort"
+
+// Prerrequisito indica que, para inscribir Materia, el estudiante debe haber cursado Requisito
+type Prerrequisito struct {
+    Materia   string
+    Requisito string
+}
+
+// GrafoPrerrequisitos guarda, por código de materia, los códigos de sus prerrequisitos directos
+type GrafoPrerrequisitos map[string][]string
+
+// NewGrafoPrerrequisitos arma el grafo con los prerrequisitos de cada materia ordenados por código
+func NewGrafoPrerrequisitos(prerrequisitos []Prerrequisito) GrafoPrerrequisitos {
+    grafo := make(GrafoPrerrequisitos)
+    for _, p := range prerrequisitos {
+        grafo[p.Materia] = append(grafo[p.Materia], p.Requisito)
+    }
+    for _, requisitos := range grafo {
+        sort.Strings(requisitos)
+    }
+    return grafo
+}
+
+// CreaCiclo indica si agregar el prerrequisito haría que una materia se requiera a sí
+// misma, directa o indirectamente: ocurre cuando desde el requisito ya se llega a la materia
+func (g GrafoPrerrequisitos) CreaCiclo(p Prerrequisito) bool {
+    if p.Materia == p.Requisito {
+        return true
+    }
+    visitadas := map[string]bool{p.Requisito: true}
+    pendientes := []string{p.Requisito}
+    for len(pendientes) > 0 {
+        actual := pendientes[len(pendientes)-1]
+        pendientes = pendientes[:len(pendientes)-1]
+        for _, requisito := range g[actual] {
+            if requisito == p.Materia {
+                return true
+            }
+            if !visitadas[requisito] {
+                visitadas[requisito] = true
+                pendientes = append(pendientes, requisito)
+            }
+        }
+    }
+    return false
+}
+
+// EslabonPrerrequisito es una materia de la cadena de prerrequisitos; Nivel 1 son los
+// prerrequisitos directos, 2 los de estos, y así sucesivamente
+type EslabonPrerrequisito struct {
+    Codigo string
+    Nivel  int
+}
+
+// Cadena recorre en profundidad los prerrequisitos de la materia, directos e indirectos,
+// en el orden en que se muestran como árbol. Un requisito común a varias ramas aparece en cada una.
+func (g GrafoPrerrequisitos) Cadena(codigo string) []EslabonPrerrequisito {
+    var cadena []EslabonPrerrequisito
+    // enCamino evita recorrer para siempre un ciclo que no debería existir
+    enCamino := map[string]bool{codigo: true}
+    var recorrer func(codigo string, nivel int)
+    recorrer = func(codigo string, nivel int) {
+        for _, requisito := range g[codigo] {
+            if enCamino[requisito] {
+                continue
+            }
+            cadena = append(cadena, EslabonPrerrequisito{Codigo: requisito, Nivel: nivel})
+            enCamino[requisito] = true
+            recorrer(requisito, nivel+1)
+            enCamino[requisito] = false
+        }
+    }
+    recorrer(codigo, 1)
+    return cadena
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"inscripciones/internal/domain"
+)
+
+// ContextoAuditoria identifica quién hace los cambios y desde dónde
+type ContextoAuditoria struct {
+	Actor  string
+	Origen string
+}
+
+// contextoPorDefecto se usa cuando los repositorios se crean sin indicar actor ni origen
+var contextoPorDefecto = ContextoAuditoria{Actor: domain.OrigenSistema, Origen: domain.OrigenSistema}
+
+func (c ContextoAuditoria) completo() ContextoAuditoria {
+	if c.Actor == "" {
+		c.Actor = contextoPorDefecto.Actor
+	}
+	if c.Origen == "" {
+		c.Origen = contextoPorDefecto.Origen
+	}
+	return c
+}
+
+// AuditoriaRepository consulta el historial de cambios; los registros solo se agregan
+// desde los demás repositorios, en la misma transacción que el cambio
+type AuditoriaRepository interface {
+	HistorialEstudiante(cedula string) ([]*domain.RegistroAuditoria, error)
+	HistorialMateria(codigo string) ([]*domain.RegistroAuditoria, error)
+}
+
+// cambio es un registro de auditoría pendiente de escribir
+type cambio struct {
+	entidad   string
+	clave     string
+	cedula    string
+	codigo    string
+	operacion string
+	antes     any
+	despues   any
+}
+
+func cambioEstudiante(operacion string, antes, despues *domain.Estudiante) cambio {
+	c := cambio{entidad: domain.EntidadEstudiante, operacion: operacion}
+	for _, e := range []*domain.Estudiante{antes, despues} {
+		if e != nil {
+			c.clave, c.cedula = e.Cedula, e.Cedula
+		}
+	}
+	if antes != nil {
+		c.antes = map[string]string{"cedula": antes.Cedula, "nombre": antes.Nombre}
+	}
+	if despues != nil {
+		c.despues = map[string]string{"cedula": despues.Cedula, "nombre": despues.Nombre}
+	}
+	return c
+}
+
+func cambioMateria(operacion string, antes, despues *domain.Materia) cambio {
+	c := cambio{entidad: domain.EntidadMateria, operacion: operacion}
+	for _, m := range []*domain.Materia{antes, despues} {
+		if m != nil {
+			c.clave, c.codigo = m.Codigo, m.Codigo
+		}
+	}
+	if antes != nil {
+		c.antes = map[string]string{"codigo": antes.Codigo, "nombre": antes.Nombre, "creditos": strconv.Itoa(antes.Creditos), "cupo": strconv.Itoa(antes.Cupo)}
+	}
+	if despues != nil {
+		c.despues = map[string]string{"codigo": despues.Codigo, "nombre": despues.Nombre, "creditos": strconv.Itoa(despues.Creditos), "cupo": strconv.Itoa(despues.Cupo)}
+	}
+	return c
+}
+
+func cambioInscripcion(operacion, cedula, codigo, periodo string) cambio {
+	valores := map[string]string{"estudiante_cedula": cedula, "materia_codigo": codigo, "periodo": periodo}
+	c := cambio{
+		entidad:   domain.EntidadInscripcion,
+		clave:     cedula + "/" + codigo + "/" + periodo,
+		cedula:    cedula,
+		codigo:    codigo,
+		operacion: operacion,
+	}
+	if operacion == domain.OperacionEliminar {
+		c.antes = valores
+	} else {
+		c.despues = valores
+	}
+	return c
+}
+
+// cambioGrupoInscripcion registra el paso de una inscripción de un grupo a otro
+func cambioGrupoInscripcion(cedula, codigo, periodo string, antes, despues int) cambio {
+	valores := func(grupo int) map[string]string {
+		return map[string]string{"estudiante_cedula": cedula, "materia_codigo": codigo, "periodo": periodo, "grupo": strconv.Itoa(grupo)}
+	}
+	return cambio{
+		entidad:   domain.EntidadInscripcion,
+		clave:     cedula + "/" + codigo + "/" + periodo,
+		cedula:    cedula,
+		codigo:    codigo,
+		operacion: domain.OperacionActualizar,
+		antes:     valores(antes),
+		despues:   valores(despues),
+	}
+}
+
+// cambioCalificacion registra el alta, el cambio o la eliminación de una nota; antes o
+// despues es nil cuando no aplica
+func cambioCalificacion(operacion, cedula, codigo, periodo, componente string, antes, despues *float64) cambio {
+	valores := func(nota float64) map[string]string {
+		return map[string]string{"estudiante_cedula": cedula, "materia_codigo": codigo, "periodo": periodo,
+			"componente": componente, "nota": domain.FormatearNota(nota, 2)}
+	}
+	c := cambio{
+		entidad:   domain.EntidadCalificacion,
+		clave:     cedula + "/" + codigo + "/" + periodo + "/" + componente,
+		cedula:    cedula,
+		codigo:    codigo,
+		operacion: operacion,
+	}
+	if antes != nil {
+		c.antes = valores(*antes)
+	}
+	if despues != nil {
+		c.despues = valores(*despues)
+	}
+	return c
+}
+
+// cambioCarreraEstudiante registra la asignación del estudiante a una carrera, su cambio
+// de carrera o su retiro; antes o despues es vacío cuando no aplica
+func cambioCarreraEstudiante(operacion, cedula, antes, despues string) cambio {
+	c := cambio{entidad: domain.EntidadCarrera, clave: cedula, cedula: cedula, operacion: operacion}
+	if antes != "" {
+		c.antes = map[string]string{"estudiante_cedula": cedula, "carrera_codigo": antes}
+	}
+	if despues != "" {
+		c.despues = map[string]string{"estudiante_cedula": cedula, "carrera_codigo": despues}
+	}
+	return c
+}
+
+// registro convierte el cambio en el registro de auditoría que se guarda
+func (c cambio) registro(contexto ContextoAuditoria, fecha time.Time) *domain.RegistroAuditoria {
+	return &domain.RegistroAuditoria{
+		Fecha:     fecha,
+		Actor:     contexto.Actor,
+		Origen:    contexto.Origen,
+		Entidad:   c.entidad,
+		Clave:     c.clave,
+		Operacion: c.operacion,
+		Antes:     aJSON(c.antes),
+		Despues:   aJSON(c.despues),
+	}
+}
+
+func aJSON(valor any) string {
+	if valor == nil {
+		return ""
+	}
+	datos, _ := json.Marshal(valor)
+	return string(datos)
+}
+
+func textoNulo(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// registrarAuditoria agrega el cambio a la tabla de auditoría dentro de la transacción del
+// cambio. Con clave, la clave y las copias del registro se guardan cifradas, y la cédula
+// de forma determinista para poder consultar el historial.
+func registrarAuditoria(tx ejecutor, d Dialecto, facultad string, cifrador *Cifrador, contexto ContextoAuditoria, c cambio) error {
+	r := c.registro(contexto, time.Now().UTC())
+	_, err := tx.Exec(d.rebind(`
+		INSERT INTO auditoria (facultad, fecha, actor, origen, entidad, clave, estudiante_cedula, materia_codigo, operacion, antes, despues)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`),
+		facultad,
+		r.Fecha.Format(time.RFC3339Nano),
+		r.Actor,
+		r.Origen,
+		r.Entidad,
+		cifrador.cifrarDato(r.Clave),
+		textoNulo(cifrador.cifrarClave(c.cedula)),
+		textoNulo(c.codigo),
+		r.Operacion,
+		textoNulo(cifrador.cifrarDato(r.Antes)),
+		textoNulo(cifrador.cifrarDato(r.Despues)),
+	)
+	return err
+}
+
+type auditoriaRepo struct {
+	db       ejecutor
+	dialecto Dialecto
+	facultad string
+	cifrador *Cifrador
+}
+
+// HistorialEstudiante retorna, en orden cronológico, los cambios del estudiante y de sus inscripciones
+func (r *auditoriaRepo) HistorialEstudiante(cedula string) ([]*domain.RegistroAuditoria, error) {
+	return r.historial("estudiante_cedula", r.cifrador.cifrarClave(cedula))
+}
+
+// HistorialMateria retorna, en orden cronológico, los cambios de la materia y de sus inscripciones
+func (r *auditoriaRepo) HistorialMateria(codigo string) ([]*domain.RegistroAuditoria, error) {
+	return r.historial("materia_codigo", codigo)
+}
+
+func (r *auditoriaRepo) historial(columna, valor string) ([]*domain.RegistroAuditoria, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(`
+		SELECT id, fecha, actor, origen, entidad, clave, operacion, antes, despues
+		FROM auditoria
+		WHERE facultad = ? AND `+columna+` = ?
+		ORDER BY id
+	`), r.facultad, valor)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var registros []*domain.RegistroAuditoria
+	for rows.Next() {
+		var reg domain.RegistroAuditoria
+		var fecha string
+		var antes, despues sql.NullString
+		err := rows.Scan(&reg.ID, &fecha, &reg.Actor, &reg.Origen, &reg.Entidad, &reg.Clave, &reg.Operacion, &antes, &despues)
+		if err != nil {
+			return nil, err
+		}
+		reg.Fecha, err = time.Parse(time.RFC3339Nano, fecha)
+		if err != nil {
+			return nil, err
+		}
+		reg.Antes, reg.Despues = antes.String, despues.String
+		if err := r.cifrador.descifrar(&reg.Clave, &reg.Antes, &reg.Despues); err != nil {
+			return nil, err
+		}
+		registros = append(registros, &reg)
+	}
+
+	return registros, rows.Err()
+}
@@ -0,0 +1,133 @@
+package repository_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"inscripciones/internal/repository"
+)
+
+// Volumen de las pruebas de rendimiento: 20.000 estudiantes con 5 materias cada uno
+const (
+	benchEstudiantes           = 20000
+	benchMaterias              = 200
+	benchMateriasPorEstudiante = 5
+)
+
+// sembrarSQLite crea una base SQLite con 100.000 inscripciones insertadas en una sola transacción
+func sembrarSQLite(b *testing.B) *repository.Repositorios {
+	b.Helper()
+
+	db, dialecto, err := repository.Conectar(repository.Config{
+		Driver: repository.DriverSQLite,
+		DSN:    filepath.Join(b.TempDir(), "bench.db"),
+	})
+	if err != nil {
+		b.Fatalf("no se pudo abrir SQLite: %v", err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	tx, err := db.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for m := 0; m < benchMaterias; m++ {
+		if _, err := tx.Exec("INSERT INTO materias (codigo, nombre) VALUES (?, ?)", fmt.Sprintf("M%04d", m), fmt.Sprintf("Materia %d", m)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	for e := 0; e < benchEstudiantes; e++ {
+		cedula := fmt.Sprintf("%08d", e)
+		if _, err := tx.Exec("INSERT INTO estudiantes (cedula, nombre) VALUES (?, ?)", cedula, "Estudiante "+cedula); err != nil {
+			b.Fatal(err)
+		}
+		for k := 0; k < benchMateriasPorEstudiante; k++ {
+			codigo := fmt.Sprintf("M%04d", (e+k*37)%benchMaterias)
+			if _, err := tx.Exec("INSERT INTO inscripciones (estudiante_cedula, materia_codigo) VALUES (?, ?)", cedula, codigo); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+
+	return repository.NewRepositorios(db, dialecto)
+}
+
+func BenchmarkListadoInscripciones(b *testing.B) {
+	repos := sembrarSQLite(b)
+
+	// Enfoque anterior: una consulta por estudiante
+	b.Run("ConsultaPorEstudiante", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			estudiantes, err := repos.Estudiantes.GetAll()
+			if err != nil {
+				b.Fatal(err)
+			}
+			total := 0
+			for _, e := range estudiantes {
+				materias, err := repos.Inscripciones.GetByEstudiante(e.Cedula)
+				if err != nil {
+					b.Fatal(err)
+				}
+				total += len(materias)
+			}
+			if total != benchEstudiantes*benchMateriasPorEstudiante {
+				b.Fatalf("total = %d", total)
+			}
+		}
+	})
+
+	b.Run("Join", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			inscripciones, err := repos.Inscripciones.GetAll()
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(inscripciones) != benchEstudiantes*benchMateriasPorEstudiante {
+				b.Fatalf("total = %d", len(inscripciones))
+			}
+		}
+	})
+}
+
+func BenchmarkConteosInscripciones(b *testing.B) {
+	repos := sembrarSQLite(b)
+
+	// Enfoque anterior: un conteo por estudiante y un listado por materia
+	b.Run("ConsultaPorEntidad", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			estudiantes, err := repos.Estudiantes.GetAll()
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, e := range estudiantes {
+				if _, err := repos.Inscripciones.CountByEstudiante(e.Cedula); err != nil {
+					b.Fatal(err)
+				}
+			}
+			materias, err := repos.Materias.GetAll()
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, m := range materias {
+				if _, err := repos.Inscripciones.GetByMateria(m.Codigo); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("Agrupado", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repos.Inscripciones.CountGroupedByEstudiante(); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := repos.Inscripciones.CountGroupedByMateria(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
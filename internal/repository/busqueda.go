@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LimiteBusquedaPorDefecto es la cantidad de resultados cuando no se indica un límite
+const LimiteBusquedaPorDefecto = 20
+
+// sinTildes reemplaza las letras acentuadas más comunes del español y el portugués
+var sinTildes = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a", "ã", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ñ", "n", "ç", "c",
+)
+
+// Equivalente SQL de sinTildes para poblar el índice de PostgreSQL desde una migración
+const (
+	pgLetrasConTilde = "áàäâãéèëêíìïîóòöôõúùüûñç"
+	pgLetrasSinTilde = "aaaaaeeeeiiiiooooouuuunc"
+)
+
+// normalizarTexto pasa el texto a minúsculas y sin tildes para compararlo
+func normalizarTexto(texto string) string {
+	return sinTildes.Replace(strings.ToLower(texto))
+}
+
+// palabras separa un texto normalizado en palabras, igual que el tokenizador unicode61 de SQLite
+func palabras(texto string) []string {
+	return strings.FieldsFunc(normalizarTexto(texto), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// puntuar calcula la relevancia de un nombre para los términos buscados. Cada término
+// debe ser prefijo de alguna palabra; las palabras completas valen más y los nombres
+// con palabras de sobra valen menos.
+func puntuar(nombre string, terminos []string) (float64, bool) {
+	palabrasNombre := palabras(nombre)
+	puntaje := 0.0
+	for _, termino := range terminos {
+		mejor := 0.0
+		for _, palabra := range palabrasNombre {
+			if palabra == termino {
+				mejor = 2
+				break
+			}
+			if strings.HasPrefix(palabra, termino) {
+				mejor = 1
+			}
+		}
+		if mejor == 0 {
+			return 0, false
+		}
+		puntaje += mejor
+	}
+	return puntaje - 0.1*float64(len(palabrasNombre)), true
+}
+
+// resultadoBusqueda asocia un nombre con su clave y puntaje para ordenarlo
+type resultadoBusqueda struct {
+	clave   string
+	nombre  string
+	version int64
+	puntaje float64
+}
+
+// ordenarResultados ordena por relevancia y luego por nombre, y recorta al límite
+func ordenarResultados(resultados []resultadoBusqueda, limite int) []resultadoBusqueda {
+	sort.Slice(resultados, func(i, j int) bool {
+		if resultados[i].puntaje != resultados[j].puntaje {
+			return resultados[i].puntaje > resultados[j].puntaje
+		}
+		return resultados[i].nombre < resultados[j].nombre
+	})
+	if len(resultados) > limite {
+		resultados = resultados[:limite]
+	}
+	return resultados
+}
+
+func limiteBusqueda(limite int) int {
+	if limite <= 0 {
+		return LimiteBusquedaPorDefecto
+	}
+	return limite
+}
+
+// indiceBusqueda describe el índice de nombres de una tabla (estudiantes o materias)
+type indiceBusqueda struct {
+	tabla        string
+	columnaClave string
+}
+
+var (
+	indiceEstudiantes = indiceBusqueda{tabla: "estudiantes", columnaClave: "cedula"}
+	indiceMaterias    = indiceBusqueda{tabla: "materias", columnaClave: "codigo"}
+)
+
+// En SQLite el índice es una tabla FTS5 que ignora tildes; en PostgreSQL, una tabla
+// con el nombre normalizado
+func (i indiceBusqueda) nombreTabla(d Dialecto) string {
+	if d == DialectoPostgres {
+		return i.tabla + "_busqueda"
+	}
+	return i.tabla + "_fts"
+}
+
+// indexar agrega un nombre al índice de búsqueda dentro de la transacción del alta
+func (i indiceBusqueda) indexar(tx ejecutor, d Dialecto, facultad, clave, nombre string) error {
+	texto := nombre
+	if d == DialectoPostgres {
+		texto = normalizarTexto(nombre)
+	}
+	_, err := tx.Exec(
+		d.rebind("INSERT INTO "+i.nombreTabla(d)+" (facultad, clave, texto) VALUES (?, ?, ?)"),
+		facultad,
+		clave,
+		texto,
+	)
+	return err
+}
+
+// reindexar reemplaza el nombre indexado de una clave cuando el registro se actualiza
+func (i indiceBusqueda) reindexar(tx ejecutor, d Dialecto, facultad, clave, nombre string) error {
+	if _, err := tx.Exec(d.rebind("DELETE FROM "+i.nombreTabla(d)+" WHERE facultad = ? AND clave = ?"), facultad, clave); err != nil {
+		return err
+	}
+	return i.indexar(tx, d, facultad, clave, nombre)
+}
+
+// buscar retorna las claves y nombres de la facultad que coinciden con el texto, ordenados por relevancia
+func (i indiceBusqueda) buscar(db ejecutor, d Dialecto, facultad, texto string, limite int) ([]resultadoBusqueda, error) {
+	terminos := palabras(texto)
+	if len(terminos) == 0 {
+		return nil, nil
+	}
+	limite = limiteBusqueda(limite)
+
+	if d == DialectoPostgres {
+		return i.buscarPostgres(db, facultad, terminos, limite)
+	}
+
+	// Cada término entre comillas y con '*' busca por prefijo; FTS5 exige que todos coincidan
+	var consulta []string
+	for _, termino := range terminos {
+		consulta = append(consulta, `"`+termino+`"*`)
+	}
+
+	indice := i.nombreTabla(d)
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT t.%[1]s, t.nombre, t.version
+		FROM %[2]s
+		JOIN %[3]s t ON t.facultad = %[2]s.facultad AND t.%[1]s = %[2]s.clave
+		WHERE %[2]s MATCH ? AND %[2]s.facultad = ? AND t.deleted_at IS NULL
+		ORDER BY bm25(%[2]s), t.nombre
+		LIMIT ?
+	`, i.columnaClave, indice, i.tabla), strings.Join(consulta, " "), facultad, limite)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resultados []resultadoBusqueda
+	for rows.Next() {
+		var r resultadoBusqueda
+		if err := rows.Scan(&r.clave, &r.nombre, &r.version); err != nil {
+			return nil, err
+		}
+		resultados = append(resultados, r)
+	}
+	return resultados, rows.Err()
+}
+
+func (i indiceBusqueda) buscarPostgres(db ejecutor, facultad string, terminos []string, limite int) ([]resultadoBusqueda, error) {
+	condiciones := []string{"b.facultad = ?"}
+	args := []any{facultad}
+	for _, termino := range terminos {
+		// Prefijo de palabra: el término aparece después de un separador. Los términos
+		// solo contienen letras y dígitos, así que no hay que escapar la expresión regular.
+		condiciones = append(condiciones, `(' ' || b.texto) ~ ?`)
+		args = append(args, `[^[:alnum:]]`+termino)
+	}
+
+	rows, err := db.Query(DialectoPostgres.rebind(fmt.Sprintf(`
+		SELECT t.%[1]s, t.nombre, t.version
+		FROM %[2]s b
+		JOIN %[3]s t ON t.facultad = b.facultad AND t.%[1]s = b.clave
+		WHERE t.deleted_at IS NULL AND %[4]s
+	`, i.columnaClave, i.nombreTabla(DialectoPostgres), i.tabla, strings.Join(condiciones, " AND "))), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resultados []resultadoBusqueda
+	for rows.Next() {
+		var r resultadoBusqueda
+		if err := rows.Scan(&r.clave, &r.nombre, &r.version); err != nil {
+			return nil, err
+		}
+		puntaje, ok := puntuar(r.nombre, terminos)
+		if !ok {
+			continue
+		}
+		r.puntaje = puntaje
+		resultados = append(resultados, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ordenarResultados(resultados, limite), nil
+}
@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"inscripciones/internal/domain"
+)
+
+// OpcionesCache configura las cachés de lectura de estudiantes y materias
+type OpcionesCache struct {
+	// Capacidad es la cantidad máxima de registros por caché; al superarla se descarta
+	// el usado hace más tiempo
+	Capacidad int
+	// Vigencia limita cuánto se sirve un registro sin volver a leerlo, para que los cambios
+	// hechos desde otras instancias de la aplicación terminen viéndose; cero es sin límite
+	Vigencia time.Duration
+}
+
+// CachePorDefecto es la configuración que usa la consola
+var CachePorDefecto = OpcionesCache{Capacidad: 1000, Vigencia: time.Minute}
+
+// EstadisticasCache cuenta las lecturas servidas desde la caché (aciertos) y las que
+// tuvieron que ir a la base de datos (fallos)
+type EstadisticasCache struct {
+	Aciertos int64
+	Fallos   int64
+	Entradas int
+}
+
+func (e EstadisticasCache) sumar(otra EstadisticasCache) EstadisticasCache {
+	return EstadisticasCache{
+		Aciertos: e.Aciertos + otra.Aciertos,
+		Fallos:   e.Fallos + otra.Fallos,
+		Entradas: e.Entradas + otra.Entradas,
+	}
+}
+
+// lru es una caché de capacidad fija que descarta primero la entrada usada hace más tiempo
+type lru[K comparable, V any] struct {
+	mu        sync.Mutex
+	capacidad int
+	vigencia  time.Duration
+	reloj     func() time.Time
+
+	// orden tiene al frente la entrada usada más recientemente
+	orden    *list.List
+	entradas map[K]*list.Element
+	aciertos int64
+	fallos   int64
+}
+
+type entradaLRU[K comparable, V any] struct {
+	clave    K
+	valor    V
+	guardada time.Time
+}
+
+func nuevoLRU[K comparable, V any](opciones OpcionesCache) *lru[K, V] {
+	capacidad := opciones.Capacidad
+	if capacidad < 1 {
+		capacidad = 1
+	}
+	return &lru[K, V]{
+		capacidad: capacidad,
+		vigencia:  opciones.Vigencia,
+		reloj:     time.Now,
+		orden:     list.New(),
+		entradas:  make(map[K]*list.Element),
+	}
+}
+
+func (c *lru[K, V]) obtener(clave K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elemento, ok := c.entradas[clave]; ok {
+		entrada := elemento.Value.(*entradaLRU[K, V])
+		if c.vigencia == 0 || c.reloj().Sub(entrada.guardada) < c.vigencia {
+			c.orden.MoveToFront(elemento)
+			c.aciertos++
+			return entrada.valor, true
+		}
+		c.orden.Remove(elemento)
+		delete(c.entradas, clave)
+	}
+	c.fallos++
+	var vacio V
+	return vacio, false
+}
+
+func (c *lru[K, V]) guardar(clave K, valor V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elemento, ok := c.entradas[clave]; ok {
+		c.orden.Remove(elemento)
+	}
+	c.entradas[clave] = c.orden.PushFront(&entradaLRU[K, V]{clave: clave, valor: valor, guardada: c.reloj()})
+	if c.orden.Len() > c.capacidad {
+		ultimo := c.orden.Back()
+		c.orden.Remove(ultimo)
+		delete(c.entradas, ultimo.Value.(*entradaLRU[K, V]).clave)
+	}
+}
+
+func (c *lru[K, V]) quitar(clave K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elemento, ok := c.entradas[clave]; ok {
+		c.orden.Remove(elemento)
+		delete(c.entradas, clave)
+	}
+}
+
+func (c *lru[K, V]) vaciar() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.orden.Init()
+	c.entradas = make(map[K]*list.Element)
+}
+
+func (c *lru[K, V]) estadisticas() EstadisticasCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return EstadisticasCache{Aciertos: c.aciertos, Fallos: c.fallos, Entradas: len(c.entradas)}
+}
+
+// EstudiantesEnCache decora un EstudianteRepository guardando en memoria los estudiantes
+// leídos con GetByCedula. Las escrituras hechas a través del decorador invalidan la
+// entrada del estudiante; las hechas por otros repositorios solo se ven al vencer la vigencia.
+type EstudiantesEnCache struct {
+	EstudianteRepository
+	cache *lru[string, domain.Estudiante]
+}
+
+func NewEstudiantesEnCache(repo EstudianteRepository, opciones OpcionesCache) *EstudiantesEnCache {
+	return &EstudiantesEnCache{EstudianteRepository: repo, cache: nuevoLRU[string, domain.Estudiante](opciones)}
+}
+
+// Estadisticas retorna los aciertos y fallos acumulados de la caché
+func (r *EstudiantesEnCache) Estadisticas() EstadisticasCache {
+	return r.cache.estadisticas()
+}
+
+// GetByCedula retorna una copia del estudiante guardado; las ausencias no se guardan,
+// para que un alta hecha desde otra instancia se vea de inmediato
+func (r *EstudiantesEnCache) GetByCedula(cedula string) (*domain.Estudiante, error) {
+	if e, ok := r.cache.obtener(cedula); ok {
+		return &e, nil
+	}
+
+	e, err := r.EstudianteRepository.GetByCedula(cedula)
+	if err != nil || e == nil {
+		return e, err
+	}
+	r.cache.guardar(cedula, *e)
+	return e, nil
+}
+
+func (r *EstudiantesEnCache) Create(estudiante *domain.Estudiante) error {
+	defer r.cache.quitar(estudiante.Cedula)
+	return r.EstudianteRepository.Create(estudiante)
+}
+
+// Update invalida la entrada también si falla: tras un conflicto, la siguiente lectura
+// debe traer la versión actual y no la que lo provocó
+func (r *EstudiantesEnCache) Update(estudiante *domain.Estudiante) error {
+	defer r.cache.quitar(estudiante.Cedula)
+	return r.EstudianteRepository.Update(estudiante)
+}
+
+func (r *EstudiantesEnCache) Delete(cedula string) error {
+	defer r.cache.quitar(cedula)
+	return r.EstudianteRepository.Delete(cedula)
+}
+
+func (r *EstudiantesEnCache) Restore(cedula string) error {
+	defer r.cache.quitar(cedula)
+	return r.EstudianteRepository.Restore(cedula)
+}
+
+// MateriasEnCache decora un MateriaRepository guardando en memoria las materias leídas
+// con GetByCodigo y el listado completo de GetAll, que la consola y las exportaciones
+// piden una y otra vez. Cualquier escritura a través del decorador invalida el listado.
+type MateriasEnCache struct {
+	MateriaRepository
+	cache *lru[string, domain.Materia]
+	todas *lru[struct{}, []domain.Materia]
+}
+
+func NewMateriasEnCache(repo MateriaRepository, opciones OpcionesCache) *MateriasEnCache {
+	return &MateriasEnCache{
+		MateriaRepository: repo,
+		cache:             nuevoLRU[string, domain.Materia](opciones),
+		todas:             nuevoLRU[struct{}, []domain.Materia](opciones),
+	}
+}
+
+// Estadisticas retorna los aciertos y fallos acumulados de GetByCodigo y GetAll
+func (r *MateriasEnCache) Estadisticas() EstadisticasCache {
+	return r.cache.estadisticas().sumar(r.todas.estadisticas())
+}
+
+// GetByCodigo retorna una copia de la materia guardada; las ausencias no se guardan
+func (r *MateriasEnCache) GetByCodigo(codigo string) (*domain.Materia, error) {
+	if m, ok := r.cache.obtener(codigo); ok {
+		return &m, nil
+	}
+
+	m, err := r.MateriaRepository.GetByCodigo(codigo)
+	if err != nil || m == nil {
+		return m, err
+	}
+	r.cache.guardar(codigo, *m)
+	return m, nil
+}
+
+// GetAll retorna copias de las materias, para que quien las modifique no altere la caché
+func (r *MateriasEnCache) GetAll() ([]*domain.Materia, error) {
+	guardadas, ok := r.todas.obtener(struct{}{})
+	if !ok {
+		materias, err := r.MateriaRepository.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		guardadas = make([]domain.Materia, 0, len(materias))
+		for _, m := range materias {
+			guardadas = append(guardadas, *m)
+		}
+		r.todas.guardar(struct{}{}, guardadas)
+	}
+
+	var materias []*domain.Materia
+	for _, m := range guardadas {
+		copia := m
+		materias = append(materias, &copia)
+	}
+	return materias, nil
+}
+
+func (r *MateriasEnCache) Create(materia *domain.Materia) error {
+	defer r.invalidar(materia.Codigo)
+	return r.MateriaRepository.Create(materia)
+}
+
+func (r *MateriasEnCache) Update(materia *domain.Materia) error {
+	defer r.invalidar(materia.Codigo)
+	return r.MateriaRepository.Update(materia)
+}
+
+func (r *MateriasEnCache) Delete(codigo string) error {
+	defer r.invalidar(codigo)
+	return r.MateriaRepository.Delete(codigo)
+}
+
+func (r *MateriasEnCache) Restore(codigo string) error {
+	defer r.invalidar(codigo)
+	return r.MateriaRepository.Restore(codigo)
+}
+
+func (r *MateriasEnCache) invalidar(codigo string) {
+	r.cache.quitar(codigo)
+	r.todas.vaciar()
+}
+
+// ConCache retorna repositorios sobre los mismos datos cuyas lecturas de estudiantes y
+// materias pasan por una caché. La caché se comparte con los repositorios derivados con
+// ConAuditoria y se vacía al terminar cada unidad de trabajo o carga, cuyos cambios no
+// pasan por los decoradores. Los derivados con ConFacultad tienen una caché propia.
+func (r *Repositorios) ConCache(opciones OpcionesCache) *Repositorios {
+	return r.conCaches(opciones, NewEstudiantesEnCache(r.Estudiantes, opciones), NewMateriasEnCache(r.Materias, opciones))
+}
+
+func (r *Repositorios) conCaches(opciones OpcionesCache, estudiantes *EstudiantesEnCache, materias *MateriasEnCache) *Repositorios {
+	vaciar := func() {
+		estudiantes.cache.vaciar()
+		materias.cache.vaciar()
+		materias.todas.vaciar()
+	}
+
+	decorados := *r
+	decorados.Estudiantes = estudiantes
+	decorados.Materias = materias
+	decorados.conAuditoria = func(c ContextoAuditoria) *Repositorios {
+		otros := r.conAuditoria(c)
+		return otros.conCaches(
+			opciones,
+			&EstudiantesEnCache{EstudianteRepository: otros.Estudiantes, cache: estudiantes.cache},
+			&MateriasEnCache{MateriaRepository: otros.Materias, cache: materias.cache, todas: materias.todas},
+		)
+	}
+	// Las cédulas y códigos solo son únicos dentro de una facultad
+	decorados.conFacultad = func(facultad string) (*Repositorios, error) {
+		otros, err := r.conFacultad(facultad)
+		if err != nil {
+			return nil, err
+		}
+		return otros.ConCache(opciones), nil
+	}
+	// Los estudiantes y las materias son los mismos en todos los periodos
+	decorados.enPeriodo = func(periodo string) (*Repositorios, error) {
+		otros, err := r.enPeriodo(periodo)
+		if err != nil {
+			return nil, err
+		}
+		return otros.conCaches(
+			opciones,
+			&EstudiantesEnCache{EstudianteRepository: otros.Estudiantes, cache: estudiantes.cache},
+			&MateriasEnCache{MateriaRepository: otros.Materias, cache: materias.cache, todas: materias.todas},
+		), nil
+	}
+	decorados.enTransaccion = func(fn func(*Repositorios) error) error {
+		defer vaciar()
+		return r.enTransaccion(fn)
+	}
+	decorados.cargar = func(v *volcado) error {
+		defer vaciar()
+		return r.cargar(v)
+	}
+	decorados.estadisticasCache = func() (EstadisticasCache, EstadisticasCache) {
+		return estudiantes.Estadisticas(), materias.Estadisticas()
+	}
+	return &decorados
+}
+
+// EstadisticasCache retorna los contadores de las cachés de estudiantes y de materias;
+// ceros si los repositorios no usan caché
+func (r *Repositorios) EstadisticasCache() (estudiantes, materias EstadisticasCache) {
+	if r.estadisticasCache == nil {
+		return EstadisticasCache{}, EstadisticasCache{}
+	}
+	return r.estadisticasCache()
+}
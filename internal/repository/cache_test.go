@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"inscripciones/internal/domain"
+)
+
+func TestLRUDescartaElMenosUsado(t *testing.T) {
+	c := nuevoLRU[string, int](OpcionesCache{Capacidad: 2})
+	c.guardar("a", 1)
+	c.guardar("b", 2)
+	c.obtener("a") // "b" pasa a ser el menos usado
+	c.guardar("c", 3)
+
+	if _, ok := c.obtener("b"); ok {
+		t.Error("se esperaba que \"b\" fuera descartado")
+	}
+	for _, clave := range []string{"a", "c"} {
+		if _, ok := c.obtener(clave); !ok {
+			t.Errorf("se esperaba conservar %q", clave)
+		}
+	}
+	if e := c.estadisticas(); e.Aciertos != 3 || e.Fallos != 1 || e.Entradas != 2 {
+		t.Errorf("estadisticas = %+v, se esperaban 3 aciertos, 1 fallo y 2 entradas", e)
+	}
+}
+
+func TestLRUVigencia(t *testing.T) {
+	ahora := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	c := nuevoLRU[string, int](OpcionesCache{Capacidad: 10, Vigencia: time.Minute})
+	c.reloj = func() time.Time { return ahora }
+
+	c.guardar("a", 1)
+	ahora = ahora.Add(59 * time.Second)
+	if _, ok := c.obtener("a"); !ok {
+		t.Fatal("la entrada venció antes de tiempo")
+	}
+	ahora = ahora.Add(time.Second)
+	if _, ok := c.obtener("a"); ok {
+		t.Fatal("se sirvió una entrada vencida")
+	}
+	if e := c.estadisticas(); e.Entradas != 0 {
+		t.Errorf("la entrada vencida sigue en la caché: %+v", e)
+	}
+}
+
+func TestEstudiantesEnCache(t *testing.T) {
+	repos := NewRepositoriosEnMemoria().ConCache(CachePorDefecto)
+	if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	primero, _ := repos.Estudiantes.GetByCedula("1234567")
+	segundo, _ := repos.Estudiantes.GetByCedula("1234567")
+	// Quien modifica lo que recibió no altera lo guardado en la caché
+	segundo.Nombre = "Modificado"
+	tercero, _ := repos.Estudiantes.GetByCedula("1234567")
+	if primero.Nombre != "Lulú López" || tercero.Nombre != "Lulú López" {
+		t.Fatalf("GetByCedula = %+v, %+v; se esperaba Lulú López", primero, tercero)
+	}
+	// Las ausencias no se guardan
+	repos.Estudiantes.GetByCedula("9999999")
+	repos.Estudiantes.GetByCedula("9999999")
+
+	estudiantes, _ := repos.EstadisticasCache()
+	if estudiantes.Aciertos != 2 || estudiantes.Fallos != 3 {
+		t.Fatalf("estadisticas = %+v, se esperaban 2 aciertos y 3 fallos", estudiantes)
+	}
+
+	// Un conflicto invalida la entrada, para que al recargar se lea la versión actual
+	otraSesion := repos.ConAuditoria(ContextoAuditoria{Actor: "otra", Origen: domain.OrigenConsola})
+	obsoleto, _ := otraSesion.Estudiantes.GetByCedula("1234567")
+	primero.Nombre = "Lucía López"
+	if err := repos.Estudiantes.Update(primero); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	obsoleto.Nombre = "Lulú Lozano"
+	if err := otraSesion.Estudiantes.Update(obsoleto); !errors.Is(err, ErrConflicto) {
+		t.Fatalf("Update obsoleto = %v, se esperaba ErrConflicto", err)
+	}
+	recargado, _ := otraSesion.Estudiantes.GetByCedula("1234567")
+	if recargado.Nombre != "Lucía López" || recargado.Version != 2 {
+		t.Fatalf("GetByCedula tras el conflicto = %+v, se esperaba la versión 2", recargado)
+	}
+}
+
+func TestMateriasEnCache(t *testing.T) {
+	repos := NewRepositoriosEnMemoria().ConCache(CachePorDefecto)
+	for _, m := range []*domain.Materia{domain.NewMateria("1040", "Cálculo"), domain.NewMateria("1050", "Física I")} {
+		if err := repos.Materias.Create(m); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	todas, _ := repos.Materias.GetAll()
+	todas[0].Nombre = "Modificada"
+	if todas, _ = repos.Materias.GetAll(); len(todas) != 2 || todas[0].Nombre != "Cálculo" {
+		t.Fatalf("GetAll = %+v, se esperaban las materias sin modificar", todas)
+	}
+
+	// Una escritura a través del decorador invalida el listado
+	if err := repos.Materias.Delete("1050"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if todas, _ = repos.Materias.GetAll(); len(todas) != 1 {
+		t.Fatalf("GetAll tras Delete = %d materias, se esperaba 1", len(todas))
+	}
+
+	// Los cambios de una unidad de trabajo no pasan por el decorador: al terminar se vacía la caché
+	err := repos.EnTransaccion(func(tx *Repositorios) error {
+		return tx.Materias.Create(domain.NewMateria("1060", "Administración"))
+	})
+	if err != nil {
+		t.Fatalf("EnTransaccion: %v", err)
+	}
+	if todas, _ = repos.Materias.GetAll(); len(todas) != 2 {
+		t.Fatalf("GetAll tras la unidad de trabajo = %d materias, se esperaban 2", len(todas))
+	}
+
+	_, materias := repos.EstadisticasCache()
+	if materias.Aciertos != 1 || materias.Fallos != 3 {
+		t.Fatalf("estadisticas = %+v, se esperaban 1 acierto y 3 fallos", materias)
+	}
+}
+
+func TestEstadisticasSinCache(t *testing.T) {
+	estudiantes, materias := NewRepositoriosEnMemoria().EstadisticasCache()
+	if estudiantes != (EstadisticasCache{}) || materias != (EstadisticasCache{}) {
+		t.Fatalf("EstadisticasCache sin caché = %+v, %+v; se esperaban ceros", estudiantes, materias)
+	}
+}
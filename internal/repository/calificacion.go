@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"inscripciones/internal/domain"
+)
+
+// CalificacionRepository administra, por periodo, las notas de cada inscripción en los
+// componentes de evaluación de su materia. Cada alta, cambio o eliminación de una nota
+// queda en la auditoría, en el historial del estudiante y en el de la materia.
+type CalificacionRepository interface {
+	// Registrar guarda la nota del estudiante en el componente, o la reemplaza si ya tenía
+	// una. Retorna ErrReferenciaInvalida si el estudiante no tiene una inscripción vigente
+	// en la materia en el periodo o si la materia no tiene ese componente.
+	Registrar(estudianteCedula, materiaCodigo, componente string, nota float64) error
+	// Delete quita la nota del estudiante en el componente; retorna ErrNoEncontrado si no tenía
+	Delete(estudianteCedula, materiaCodigo, componente string) error
+	// GetByInscripcion retorna las notas del estudiante en la materia ordenadas por componente
+	GetByInscripcion(estudianteCedula, materiaCodigo string) ([]domain.Calificacion, error)
+	// GetByMateria retorna las notas de las inscripciones vigentes en la materia, ordenadas
+	// por cédula y componente
+	GetByMateria(materiaCodigo string) ([]domain.Calificacion, error)
+	// GetAll retorna todas las notas del periodo, también las de inscripciones canceladas o
+	// de estudiantes y materias eliminados, ordenadas por materia, cédula y componente
+	GetAll() ([]domain.Calificacion, error)
+	// HistorialByEstudiante retorna las notas del estudiante en todos los periodos, no solo
+	// en el de los repositorios, también las de inscripciones canceladas, ordenadas por
+	// periodo, materia y componente
+	HistorialByEstudiante(cedula string) ([]domain.Calificacion, error)
+}
+
+type calificacionRepo struct {
+	db        ejecutor
+	dialecto  Dialecto
+	facultad  string
+	periodo   string
+	cifrador  *Cifrador
+	auditoria ContextoAuditoria
+}
+
+// Las notas se guardan en centésimas, así que las sumas y promedios son exactos
+const columnasCalificacion = "estudiante_cedula, materia_codigo, componente, nota"
+
+func (r *calificacionRepo) Registrar(estudianteCedula, materiaCodigo, componente string, nota float64) error {
+	cedula := r.cifrador.cifrarClave(estudianteCedula)
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var inscrito, conComponente bool
+		err := tx.QueryRow(r.dialecto.rebind(`
+			SELECT EXISTS(SELECT 1 `+joinInscripciones+` WHERE i.facultad = ? AND i.periodo = ? AND i.estudiante_cedula = ? AND i.materia_codigo = ? AND `+inscripcionVigente+`),
+				EXISTS(SELECT 1 FROM componentes WHERE facultad = ? AND materia_codigo = ? AND nombre = ?)
+		`), r.facultad, r.periodo, cedula, materiaCodigo, r.facultad, materiaCodigo, componente).Scan(&inscrito, &conComponente)
+		if err != nil {
+			return err
+		}
+		if !inscrito {
+			return fmt.Errorf("%w: el estudiante %s no está inscrito en %s en el periodo %s", ErrReferenciaInvalida, estudianteCedula, materiaCodigo, r.periodo)
+		}
+		if !conComponente {
+			return fmt.Errorf("%w: la materia %s no tiene el componente %s", ErrReferenciaInvalida, materiaCodigo, componente)
+		}
+
+		anterior, err := r.notaGuardada(tx, cedula, materiaCodigo, componente)
+		if err != nil {
+			return err
+		}
+		operacion := domain.OperacionCrear
+		if anterior == nil {
+			_, err = tx.Exec(r.dialecto.rebind("INSERT INTO calificaciones (facultad, periodo, "+columnasCalificacion+") VALUES (?, ?, ?, ?, ?, ?)"),
+				r.facultad, r.periodo, cedula, materiaCodigo, componente, domain.Centesimas(nota))
+		} else {
+			if domain.Centesimas(*anterior) == domain.Centesimas(nota) {
+				return nil
+			}
+			operacion = domain.OperacionActualizar
+			_, err = tx.Exec(r.dialecto.rebind("UPDATE calificaciones SET nota = ? WHERE facultad = ? AND periodo = ? AND estudiante_cedula = ? AND materia_codigo = ? AND componente = ?"),
+				domain.Centesimas(nota), r.facultad, r.periodo, cedula, materiaCodigo, componente)
+		}
+		if err != nil {
+			return traducirError(err)
+		}
+		cambio := cambioCalificacion(operacion, estudianteCedula, materiaCodigo, r.periodo, componente, anterior, &nota)
+		return registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio)
+	})
+	if err != nil {
+		return fmt.Errorf("error al registrar la nota de %s en %s (%s): %w", estudianteCedula, materiaCodigo, componente, err)
+	}
+	return nil
+}
+
+func (r *calificacionRepo) Delete(estudianteCedula, materiaCodigo, componente string) error {
+	cedula := r.cifrador.cifrarClave(estudianteCedula)
+	err := transaccion(r.db, func(tx ejecutor) error {
+		anterior, err := r.notaGuardada(tx, cedula, materiaCodigo, componente)
+		if err != nil {
+			return err
+		}
+		if anterior == nil {
+			return ErrNoEncontrado
+		}
+		_, err = tx.Exec(r.dialecto.rebind("DELETE FROM calificaciones WHERE facultad = ? AND periodo = ? AND estudiante_cedula = ? AND materia_codigo = ? AND componente = ?"),
+			r.facultad, r.periodo, cedula, materiaCodigo, componente)
+		if err != nil {
+			return err
+		}
+		cambio := cambioCalificacion(domain.OperacionEliminar, estudianteCedula, materiaCodigo, r.periodo, componente, anterior, nil)
+		return registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio)
+	})
+	if err != nil {
+		return fmt.Errorf("error al quitar la nota de %s en %s (%s): %w", estudianteCedula, materiaCodigo, componente, err)
+	}
+	return nil
+}
+
+// notaGuardada retorna la nota que ya tiene el estudiante, con la cédula cifrada, en el
+// componente; nil si no tiene
+func (r *calificacionRepo) notaGuardada(tx ejecutor, cedula, materiaCodigo, componente string) (*float64, error) {
+	var centesimas int
+	err := tx.QueryRow(r.dialecto.rebind("SELECT nota FROM calificaciones WHERE facultad = ? AND periodo = ? AND estudiante_cedula = ? AND materia_codigo = ? AND componente = ?"),
+		r.facultad, r.periodo, cedula, materiaCodigo, componente).Scan(&centesimas)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	nota := domain.NotaDeCentesimas(centesimas)
+	return &nota, nil
+}
+
+func (r *calificacionRepo) GetByInscripcion(estudianteCedula, materiaCodigo string) ([]domain.Calificacion, error) {
+	return r.consultar("SELECT "+columnasCalificacion+" FROM calificaciones WHERE facultad = ? AND periodo = ? AND estudiante_cedula = ? AND materia_codigo = ?",
+		r.facultad, r.periodo, r.cifrador.cifrarClave(estudianteCedula), materiaCodigo)
+}
+
+func (r *calificacionRepo) GetByMateria(materiaCodigo string) ([]domain.Calificacion, error) {
+	return r.consultar(`
+		SELECT c.estudiante_cedula, c.materia_codigo, c.componente, c.nota
+		`+joinInscripciones+`
+		JOIN calificaciones c ON c.facultad = i.facultad AND c.periodo = i.periodo
+			AND c.materia_codigo = i.materia_codigo AND c.estudiante_cedula = i.estudiante_cedula
+		WHERE i.facultad = ? AND i.periodo = ? AND i.materia_codigo = ? AND `+inscripcionVigente,
+		r.facultad, r.periodo, materiaCodigo)
+}
+
+func (r *calificacionRepo) GetAll() ([]domain.Calificacion, error) {
+	return r.consultar("SELECT "+columnasCalificacion+" FROM calificaciones WHERE facultad = ? AND periodo = ?", r.facultad, r.periodo)
+}
+
+func (r *calificacionRepo) HistorialByEstudiante(cedula string) ([]domain.Calificacion, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(`
+		SELECT periodo, `+columnasCalificacion+` FROM calificaciones
+		WHERE facultad = ? AND estudiante_cedula = ?
+		ORDER BY periodo, materia_codigo, componente
+	`), r.facultad, r.cifrador.cifrarClave(cedula))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var calificaciones []domain.Calificacion
+	for rows.Next() {
+		var c domain.Calificacion
+		var centesimas int
+		if err := rows.Scan(&c.Periodo, &c.Cedula, &c.Materia, &c.Componente, &centesimas); err != nil {
+			return nil, err
+		}
+		c.Cedula = cedula
+		c.Nota = domain.NotaDeCentesimas(centesimas)
+		calificaciones = append(calificaciones, c)
+	}
+	return calificaciones, rows.Err()
+}
+
+// consultar lee las notas y las ordena por materia, cédula y componente; el orden se
+// hace después de descifrar las cédulas
+func (r *calificacionRepo) consultar(consulta string, args ...any) ([]domain.Calificacion, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(consulta), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var calificaciones []domain.Calificacion
+	for rows.Next() {
+		c := domain.Calificacion{Periodo: r.periodo}
+		var centesimas int
+		if err := rows.Scan(&c.Cedula, &c.Materia, &c.Componente, &centesimas); err != nil {
+			return nil, err
+		}
+		if err := r.cifrador.descifrar(&c.Cedula); err != nil {
+			return nil, err
+		}
+		c.Nota = domain.NotaDeCentesimas(centesimas)
+		calificaciones = append(calificaciones, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	ordenarCalificaciones(calificaciones)
+	return calificaciones, nil
+}
+
+// ordenarCalificaciones ordena las notas por materia, cédula y componente
+func ordenarCalificaciones(calificaciones []domain.Calificacion) {
+	sort.Slice(calificaciones, func(i, j int) bool {
+		a, b := calificaciones[i], calificaciones[j]
+		if a.Materia != b.Materia {
+			return a.Materia < b.Materia
+		}
+		if a.Cedula != b.Cedula {
+			return a.Cedula < b.Cedula
+		}
+		return a.Componente < b.Componente
+	})
+}
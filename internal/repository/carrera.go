@@ -0,0 +1,375 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"inscripciones/internal/domain"
+)
+
+// ErrCarreraConEstudiantes impide eliminar una carrera a la que todavía pertenece algún estudiante
+var ErrCarreraConEstudiantes = errors.New("la carrera tiene estudiantes")
+
+// CarreraRepository administra las carreras de la facultad, sus pensums y la carrera de
+// cada estudiante. Las carreras y los pensums son comunes a todos los periodos y no se
+// auditan; la asignación de un estudiante a una carrera queda en su historial.
+type CarreraRepository interface {
+	// Create agrega la carrera; retorna ErrDuplicado si el código ya existe
+	Create(c *domain.Carrera) error
+	// Update cambia el nombre y los créditos de la carrera; retorna ErrNoEncontrado si no existe
+	Update(c *domain.Carrera) error
+	// Delete quita la carrera junto con su pensum; retorna ErrNoEncontrado si no existe y
+	// ErrCarreraConEstudiantes si algún estudiante, incluso eliminado, pertenece a ella
+	Delete(codigo string) error
+	// GetByCodigo retorna la carrera; nil si no existe
+	GetByCodigo(codigo string) (*domain.Carrera, error)
+	// GetAll retorna las carreras ordenadas por código
+	GetAll() ([]*domain.Carrera, error)
+
+	// AgregarAlPensum ubica la materia en el pensum de la carrera. Retorna
+	// ErrReferenciaInvalida si la carrera no existe o la materia no existe o está eliminada,
+	// y ErrDuplicado si la materia ya estaba en el pensum.
+	AgregarAlPensum(m domain.MateriaPensum) error
+	// QuitarDelPensum saca la materia del pensum; retorna ErrNoEncontrado si no estaba
+	QuitarDelPensum(carreraCodigo, materiaCodigo string) error
+	// GetPensum retorna el pensum de la carrera ordenado por semestre y materia
+	GetPensum(carreraCodigo string) ([]domain.MateriaPensum, error)
+	// GetAllPensum retorna los pensums de todas las carreras, también con materias
+	// eliminadas, ordenados por carrera, semestre y materia
+	GetAllPensum() ([]domain.MateriaPensum, error)
+
+	// AsignarEstudiante pone al estudiante en la carrera, o lo cambia de carrera si ya tenía
+	// una. Retorna ErrReferenciaInvalida si el estudiante no existe o está eliminado, o si la
+	// carrera no existe.
+	AsignarEstudiante(estudianteCedula, carreraCodigo string) error
+	// QuitarEstudiante saca al estudiante de su carrera; retorna ErrNoEncontrado si no tenía
+	QuitarEstudiante(estudianteCedula string) error
+	// CarreraDeEstudiante retorna el código de la carrera del estudiante; vacío si no tiene
+	CarreraDeEstudiante(estudianteCedula string) (string, error)
+	// GetEstudiantes retorna los estudiantes activos de la carrera ordenados por cédula
+	GetEstudiantes(carreraCodigo string) ([]*domain.Estudiante, error)
+	// GetAsignaciones retorna la carrera de cada estudiante, también de los eliminados,
+	// ordenadas por cédula
+	GetAsignaciones() ([]domain.AsignacionCarrera, error)
+}
+
+type carreraRepo struct {
+	db        ejecutor
+	dialecto  Dialecto
+	facultad  string
+	cifrador  *Cifrador
+	auditoria ContextoAuditoria
+}
+
+func (r *carreraRepo) Create(c *domain.Carrera) error {
+	_, err := r.db.Exec(r.dialecto.rebind("INSERT INTO carreras (facultad, codigo, nombre, creditos) VALUES (?, ?, ?, ?)"),
+		r.facultad, c.Codigo, c.Nombre, c.Creditos)
+	if err != nil {
+		return fmt.Errorf("error al crear la carrera %s: %w", c.Codigo, traducirError(err))
+	}
+	return nil
+}
+
+func (r *carreraRepo) Update(c *domain.Carrera) error {
+	resultado, err := r.db.Exec(r.dialecto.rebind("UPDATE carreras SET nombre = ?, creditos = ? WHERE facultad = ? AND codigo = ?"),
+		c.Nombre, c.Creditos, r.facultad, c.Codigo)
+	if err != nil {
+		return fmt.Errorf("error al actualizar la carrera %s: %w", c.Codigo, err)
+	}
+	filas, err := resultado.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al actualizar la carrera %s: %w", c.Codigo, err)
+	}
+	if filas == 0 {
+		return fmt.Errorf("error al actualizar la carrera %s: %w", c.Codigo, ErrNoEncontrado)
+	}
+	return nil
+}
+
+func (r *carreraRepo) Delete(codigo string) error {
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var conEstudiantes bool
+		err := tx.QueryRow(r.dialecto.rebind("SELECT EXISTS(SELECT 1 FROM carrera_estudiantes WHERE facultad = ? AND carrera_codigo = ?)"),
+			r.facultad, codigo).Scan(&conEstudiantes)
+		if err != nil {
+			return err
+		}
+		if conEstudiantes {
+			return ErrCarreraConEstudiantes
+		}
+
+		if _, err := tx.Exec(r.dialecto.rebind("DELETE FROM pensum WHERE facultad = ? AND carrera_codigo = ?"), r.facultad, codigo); err != nil {
+			return err
+		}
+		resultado, err := tx.Exec(r.dialecto.rebind("DELETE FROM carreras WHERE facultad = ? AND codigo = ?"), r.facultad, codigo)
+		if err != nil {
+			return err
+		}
+		filas, err := resultado.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if filas == 0 {
+			return ErrNoEncontrado
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error al eliminar la carrera %s: %w", codigo, err)
+	}
+	return nil
+}
+
+func (r *carreraRepo) GetByCodigo(codigo string) (*domain.Carrera, error) {
+	var c domain.Carrera
+	err := r.db.QueryRow(r.dialecto.rebind("SELECT codigo, nombre, creditos FROM carreras WHERE facultad = ? AND codigo = ?"),
+		r.facultad, codigo).Scan(&c.Codigo, &c.Nombre, &c.Creditos)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *carreraRepo) GetAll() ([]*domain.Carrera, error) {
+	rows, err := r.db.Query(r.dialecto.rebind("SELECT codigo, nombre, creditos FROM carreras WHERE facultad = ? ORDER BY codigo"), r.facultad)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var carreras []*domain.Carrera
+	for rows.Next() {
+		var c domain.Carrera
+		if err := rows.Scan(&c.Codigo, &c.Nombre, &c.Creditos); err != nil {
+			return nil, err
+		}
+		carreras = append(carreras, &c)
+	}
+	return carreras, rows.Err()
+}
+
+func (r *carreraRepo) AgregarAlPensum(m domain.MateriaPensum) error {
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var carrera, activa bool
+		err := tx.QueryRow(r.dialecto.rebind(`
+			SELECT EXISTS(SELECT 1 FROM carreras WHERE facultad = ? AND codigo = ?),
+				EXISTS(SELECT 1 FROM materias WHERE facultad = ? AND codigo = ? AND deleted_at IS NULL)
+		`), r.facultad, m.Carrera, r.facultad, m.Materia).Scan(&carrera, &activa)
+		if err != nil {
+			return err
+		}
+		if !carrera {
+			return fmt.Errorf("%w: carrera %s", ErrReferenciaInvalida, m.Carrera)
+		}
+		if !activa {
+			return fmt.Errorf("%w: materia %s", ErrReferenciaInvalida, m.Materia)
+		}
+
+		_, err = tx.Exec(r.dialecto.rebind("INSERT INTO pensum (facultad, carrera_codigo, materia_codigo, semestre, obligatoria) VALUES (?, ?, ?, ?, ?)"),
+			r.facultad, m.Carrera, m.Materia, m.Semestre, enteroBooleano(m.Obligatoria))
+		return traducirError(err)
+	})
+	if err != nil {
+		return fmt.Errorf("error al agregar %s al pensum de %s: %w", m.Materia, m.Carrera, err)
+	}
+	return nil
+}
+
+func (r *carreraRepo) QuitarDelPensum(carreraCodigo, materiaCodigo string) error {
+	resultado, err := r.db.Exec(r.dialecto.rebind("DELETE FROM pensum WHERE facultad = ? AND carrera_codigo = ? AND materia_codigo = ?"),
+		r.facultad, carreraCodigo, materiaCodigo)
+	if err != nil {
+		return fmt.Errorf("error al quitar %s del pensum de %s: %w", materiaCodigo, carreraCodigo, err)
+	}
+	filas, err := resultado.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al quitar %s del pensum de %s: %w", materiaCodigo, carreraCodigo, err)
+	}
+	if filas == 0 {
+		return fmt.Errorf("error al quitar %s del pensum de %s: %w", materiaCodigo, carreraCodigo, ErrNoEncontrado)
+	}
+	return nil
+}
+
+func (r *carreraRepo) GetPensum(carreraCodigo string) ([]domain.MateriaPensum, error) {
+	return r.consultarPensum("SELECT carrera_codigo, materia_codigo, semestre, obligatoria FROM pensum WHERE facultad = ? AND carrera_codigo = ? ORDER BY semestre, materia_codigo",
+		r.facultad, carreraCodigo)
+}
+
+func (r *carreraRepo) GetAllPensum() ([]domain.MateriaPensum, error) {
+	return r.consultarPensum("SELECT carrera_codigo, materia_codigo, semestre, obligatoria FROM pensum WHERE facultad = ? ORDER BY carrera_codigo, semestre, materia_codigo",
+		r.facultad)
+}
+
+func (r *carreraRepo) consultarPensum(consulta string, args ...any) ([]domain.MateriaPensum, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(consulta), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pensum []domain.MateriaPensum
+	for rows.Next() {
+		var m domain.MateriaPensum
+		var obligatoria int
+		if err := rows.Scan(&m.Carrera, &m.Materia, &m.Semestre, &obligatoria); err != nil {
+			return nil, err
+		}
+		m.Obligatoria = obligatoria == 1
+		pensum = append(pensum, m)
+	}
+	return pensum, rows.Err()
+}
+
+func (r *carreraRepo) AsignarEstudiante(estudianteCedula, carreraCodigo string) error {
+	cedula := r.cifrador.cifrarClave(estudianteCedula)
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var activo, carrera bool
+		err := tx.QueryRow(r.dialecto.rebind(`
+			SELECT EXISTS(SELECT 1 FROM estudiantes WHERE facultad = ? AND cedula = ? AND deleted_at IS NULL),
+				EXISTS(SELECT 1 FROM carreras WHERE facultad = ? AND codigo = ?)
+		`), r.facultad, cedula, r.facultad, carreraCodigo).Scan(&activo, &carrera)
+		if err != nil {
+			return err
+		}
+		if !activo {
+			return fmt.Errorf("%w: estudiante %s", ErrReferenciaInvalida, estudianteCedula)
+		}
+		if !carrera {
+			return fmt.Errorf("%w: carrera %s", ErrReferenciaInvalida, carreraCodigo)
+		}
+
+		anterior, err := r.carreraGuardada(tx, cedula)
+		if err != nil {
+			return err
+		}
+		operacion := domain.OperacionCrear
+		switch anterior {
+		case carreraCodigo:
+			return nil
+		case "":
+			_, err = tx.Exec(r.dialecto.rebind("INSERT INTO carrera_estudiantes (facultad, estudiante_cedula, carrera_codigo) VALUES (?, ?, ?)"),
+				r.facultad, cedula, carreraCodigo)
+		default:
+			operacion = domain.OperacionActualizar
+			_, err = tx.Exec(r.dialecto.rebind("UPDATE carrera_estudiantes SET carrera_codigo = ? WHERE facultad = ? AND estudiante_cedula = ?"),
+				carreraCodigo, r.facultad, cedula)
+		}
+		if err != nil {
+			return traducirError(err)
+		}
+		cambio := cambioCarreraEstudiante(operacion, estudianteCedula, anterior, carreraCodigo)
+		return registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio)
+	})
+	if err != nil {
+		return fmt.Errorf("error al asignar al estudiante %s a la carrera %s: %w", estudianteCedula, carreraCodigo, err)
+	}
+	return nil
+}
+
+func (r *carreraRepo) QuitarEstudiante(estudianteCedula string) error {
+	cedula := r.cifrador.cifrarClave(estudianteCedula)
+	err := transaccion(r.db, func(tx ejecutor) error {
+		anterior, err := r.carreraGuardada(tx, cedula)
+		if err != nil {
+			return err
+		}
+		if anterior == "" {
+			return ErrNoEncontrado
+		}
+		_, err = tx.Exec(r.dialecto.rebind("DELETE FROM carrera_estudiantes WHERE facultad = ? AND estudiante_cedula = ?"), r.facultad, cedula)
+		if err != nil {
+			return err
+		}
+		cambio := cambioCarreraEstudiante(domain.OperacionEliminar, estudianteCedula, anterior, "")
+		return registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio)
+	})
+	if err != nil {
+		return fmt.Errorf("error al quitar al estudiante %s de su carrera: %w", estudianteCedula, err)
+	}
+	return nil
+}
+
+func (r *carreraRepo) CarreraDeEstudiante(estudianteCedula string) (string, error) {
+	return r.carreraGuardada(r.db, r.cifrador.cifrarClave(estudianteCedula))
+}
+
+// carreraGuardada retorna la carrera del estudiante, con la cédula cifrada; vacío si no tiene
+func (r *carreraRepo) carreraGuardada(tx ejecutor, cedula string) (string, error) {
+	var carrera string
+	err := tx.QueryRow(r.dialecto.rebind("SELECT carrera_codigo FROM carrera_estudiantes WHERE facultad = ? AND estudiante_cedula = ?"),
+		r.facultad, cedula).Scan(&carrera)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return carrera, err
+}
+
+func (r *carreraRepo) GetEstudiantes(carreraCodigo string) ([]*domain.Estudiante, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(`
+		SELECT e.cedula, e.nombre, e.version
+		FROM carrera_estudiantes c
+		JOIN estudiantes e ON e.facultad = c.facultad AND e.cedula = c.estudiante_cedula
+		WHERE c.facultad = ? AND c.carrera_codigo = ? AND e.deleted_at IS NULL
+	`), r.facultad, carreraCodigo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var estudiantes []*domain.Estudiante
+	for rows.Next() {
+		var e domain.Estudiante
+		if err := rows.Scan(&e.Cedula, &e.Nombre, &e.Version); err != nil {
+			return nil, err
+		}
+		if err := r.cifrador.descifrar(&e.Cedula, &e.Nombre); err != nil {
+			return nil, err
+		}
+		estudiantes = append(estudiantes, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// El orden se hace después de descifrar las cédulas
+	sort.Slice(estudiantes, func(i, j int) bool { return estudiantes[i].Cedula < estudiantes[j].Cedula })
+	return estudiantes, nil
+}
+
+func (r *carreraRepo) GetAsignaciones() ([]domain.AsignacionCarrera, error) {
+	rows, err := r.db.Query(r.dialecto.rebind("SELECT estudiante_cedula, carrera_codigo FROM carrera_estudiantes WHERE facultad = ?"), r.facultad)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var asignaciones []domain.AsignacionCarrera
+	for rows.Next() {
+		var a domain.AsignacionCarrera
+		if err := rows.Scan(&a.Cedula, &a.Carrera); err != nil {
+			return nil, err
+		}
+		if err := r.cifrador.descifrar(&a.Cedula); err != nil {
+			return nil, err
+		}
+		asignaciones = append(asignaciones, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(asignaciones, func(i, j int) bool { return asignaciones[i].Cedula < asignaciones[j].Cedula })
+	return asignaciones, nil
+}
+
+// enteroBooleano convierte el valor en el 0 o 1 con que se guardan los booleanos
+func enteroBooleano(valor bool) int {
+	if valor {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,489 @@
+package repository
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Variables de entorno con la clave que cifra los datos personales: el valor en base64
+// o la ruta de un archivo que lo contiene
+const (
+	EnvClave        = "INSCRIPCIONES_CLAVE"
+	EnvArchivoClave = "INSCRIPCIONES_CLAVE_ARCHIVO"
+)
+
+// LongitudClave es la cantidad de bytes de una clave de cifrado (AES-256)
+const LongitudClave = 32
+
+// prefijoCifrado distingue los valores cifrados de los guardados en texto plano
+const prefijoCifrado = "c1:"
+
+var (
+	ErrClaveInvalida   = errors.New("la clave de cifrado no es válida")
+	ErrClaveRequerida  = errors.New("la base de datos está cifrada y no se indicó la clave")
+	ErrClaveIncorrecta = errors.New("la clave de cifrado no corresponde a la de la base de datos")
+	ErrBaseSinCifrar   = errors.New("la base de datos tiene datos sin cifrar; cífrelos con el comando rotar-clave")
+)
+
+// Cifrador cifra las columnas con datos personales: la cédula y el nombre del estudiante y
+// del docente, y las copias que de ellos guardan la auditoría y el outbox. La cédula se cifra de forma
+// determinista (el nonce se deriva del propio valor), así que el mismo valor produce
+// siempre el mismo texto cifrado y siguen funcionando las búsquedas por igualdad, las
+// claves foráneas y los JOIN. El resto se cifra con un nonce aleatorio.
+//
+// Un *Cifrador nil no cifra nada: es lo que usan las bases sin clave.
+type Cifrador struct {
+	aead        cipher.AEAD
+	claveNonce  []byte
+	verificador string
+}
+
+// NewCifrador crea un cifrador a partir de una clave de LongitudClave bytes
+func NewCifrador(clave []byte) (*Cifrador, error) {
+	if len(clave) != LongitudClave {
+		return nil, fmt.Errorf("%w: tiene %d bytes y debe tener %d", ErrClaveInvalida, len(clave), LongitudClave)
+	}
+	derivar := func(proposito string) []byte {
+		// La clave ya es aleatoria y uniforme; HKDF separa las subclaves de cada uso
+		subclave, err := hkdf.Key(sha256.New, clave, nil, "inscripciones "+proposito, LongitudClave)
+		if err != nil {
+			panic(err) // solo falla si se piden más bytes de los que SHA-256 puede derivar
+		}
+		return subclave
+	}
+
+	bloque, err := aes.NewCipher(derivar("cifrado"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(bloque)
+	if err != nil {
+		return nil, err
+	}
+	return &Cifrador{
+		aead:        aead,
+		claveNonce:  derivar("nonce"),
+		verificador: hex.EncodeToString(derivar("verificador")),
+	}, nil
+}
+
+// GenerarClave retorna una clave nueva y aleatoria, codificada en base64
+func GenerarClave() string {
+	clave := make([]byte, LongitudClave)
+	rand.Read(clave)
+	return base64.StdEncoding.EncodeToString(clave)
+}
+
+// CifradorDesdeTexto crea el cifrador de una clave codificada en base64
+func CifradorDesdeTexto(texto string) (*Cifrador, error) {
+	clave, err := base64.StdEncoding.DecodeString(strings.TrimSpace(texto))
+	if err != nil {
+		return nil, fmt.Errorf("%w: no está codificada en base64", ErrClaveInvalida)
+	}
+	return NewCifrador(clave)
+}
+
+// CifradorDesdeArchivo crea el cifrador de la clave guardada en un archivo
+func CifradorDesdeArchivo(ruta string) (*Cifrador, error) {
+	contenido, err := os.ReadFile(ruta)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer la clave de cifrado: %w", err)
+	}
+	return CifradorDesdeTexto(string(contenido))
+}
+
+// activo indica si hay una clave con la que cifrar
+func (c *Cifrador) activo() bool {
+	return c != nil
+}
+
+// cifrarClave cifra de forma determinista un valor que se usa para buscar o relacionar
+// filas. El texto vacío queda vacío para conservar los NULL de las columnas opcionales.
+func (c *Cifrador) cifrarClave(texto string) string {
+	if c == nil || texto == "" {
+		return texto
+	}
+	mac := hmac.New(sha256.New, c.claveNonce)
+	mac.Write([]byte(texto))
+	return c.sellar(mac.Sum(nil)[:c.aead.NonceSize()], texto)
+}
+
+// cifrarDato cifra con un nonce aleatorio un valor que nunca se compara en SQL
+func (c *Cifrador) cifrarDato(texto string) string {
+	if c == nil || texto == "" {
+		return texto
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	rand.Read(nonce)
+	return c.sellar(nonce, texto)
+}
+
+func (c *Cifrador) sellar(nonce []byte, texto string) string {
+	cifrado := c.aead.Seal(nonce, nonce, []byte(texto), nil)
+	return prefijoCifrado + base64.RawStdEncoding.EncodeToString(cifrado)
+}
+
+// descifrar reemplaza cada valor por su texto plano
+func (c *Cifrador) descifrar(valores ...*string) error {
+	if c == nil {
+		return nil
+	}
+	for _, valor := range valores {
+		if *valor == "" {
+			continue
+		}
+		texto, err := c.abrir(*valor)
+		if err != nil {
+			return err
+		}
+		*valor = texto
+	}
+	return nil
+}
+
+func (c *Cifrador) abrir(valor string) (string, error) {
+	codificado, ok := strings.CutPrefix(valor, prefijoCifrado)
+	if !ok {
+		return "", fmt.Errorf("%w: hay un valor sin cifrar", ErrClaveIncorrecta)
+	}
+	datos, err := base64.RawStdEncoding.DecodeString(codificado)
+	if err != nil || len(datos) < c.aead.NonceSize() {
+		return "", fmt.Errorf("%w: valor cifrado dañado", ErrClaveIncorrecta)
+	}
+	nonce, cifrado := datos[:c.aead.NonceSize()], datos[c.aead.NonceSize():]
+	texto, err := c.aead.Open(nil, nonce, cifrado, nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: no se pudo descifrar un valor", ErrClaveIncorrecta)
+	}
+	return string(texto), nil
+}
+
+// verificadorGuardado lee el verificador de la clave con que se cifró la base; vacío si no está cifrada
+func verificadorGuardado(db ejecutor) (string, error) {
+	var verificador string
+	err := db.QueryRow("SELECT verificador FROM cifrado").Scan(&verificador)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error al leer el estado del cifrado: %w", err)
+	}
+	return verificador, nil
+}
+
+// comprobarClave verifica que el cifrador corresponda a la clave con que se cifró la base
+func comprobarClave(db ejecutor, c *Cifrador) error {
+	verificador, err := verificadorGuardado(db)
+	if err != nil {
+		return err
+	}
+	switch {
+	case verificador == "" && c == nil:
+		return nil
+	case verificador == "":
+		return ErrBaseSinCifrar
+	case c == nil:
+		return ErrClaveRequerida
+	case !hmac.Equal([]byte(verificador), []byte(c.verificador)):
+		return ErrClaveIncorrecta
+	}
+	return nil
+}
+
+// prepararCifrado comprueba la clave al abrir la base. Una base sin datos personales
+// empieza a cifrarse con la primera clave que se le indique; una que ya tiene datos en
+// texto plano debe cifrarse antes con Recifrar.
+func prepararCifrado(db *sql.DB, d Dialecto, c *Cifrador) error {
+	err := comprobarClave(db, c)
+	if !errors.Is(err, ErrBaseSinCifrar) {
+		return err
+	}
+
+	var conDatos bool
+	err = db.QueryRow(`SELECT EXISTS(SELECT 1 FROM estudiantes)
+		OR EXISTS(SELECT 1 FROM docentes)
+		OR EXISTS(SELECT 1 FROM auditoria)
+		OR EXISTS(SELECT 1 FROM outbox)`).Scan(&conDatos)
+	if err != nil {
+		return fmt.Errorf("error al revisar los datos sin cifrar: %w", err)
+	}
+	if conDatos {
+		return ErrBaseSinCifrar
+	}
+	if _, err := db.Exec(d.rebind("INSERT INTO cifrado (id, verificador) VALUES (1, ?)"), c.verificador); err != nil {
+		return fmt.Errorf("error al registrar la clave de cifrado: %w", err)
+	}
+	return nil
+}
+
+// Recifrar vuelve a cifrar, en una sola transacción, los datos personales de todas las
+// facultades: los descifra con la clave actual y los cifra con la nueva. Con actual nil
+// cifra una base en texto plano; con nueva nil la deja sin cifrar. Si algo falla no se
+// modifica nada.
+func Recifrar(db *sql.DB, d Dialecto, actual, nueva *Cifrador) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := comprobarClave(tx, actual); err != nil {
+		return err
+	}
+
+	// Sin clave no hay índice de búsqueda de estudiantes: se vacía ahora, antes de mover
+	// las filas a las que apunta, y se reconstruye al final si la base queda sin cifrar
+	if _, err := tx.Exec("DELETE FROM " + indiceEstudiantes.nombreTabla(d)); err != nil {
+		return fmt.Errorf("error al vaciar el índice de búsqueda: %w", err)
+	}
+	if err := recifrarEstudiantes(tx, d, actual, nueva); err != nil {
+		return err
+	}
+	if err := recifrarDocentes(tx, d, actual, nueva); err != nil {
+		return err
+	}
+	if err := recifrarAuditoria(tx, d, actual, nueva); err != nil {
+		return err
+	}
+	if err := recifrarOutbox(tx, d, actual, nueva); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM cifrado"); err != nil {
+		return fmt.Errorf("error al registrar la clave de cifrado: %w", err)
+	}
+	if nueva.activo() {
+		if _, err := tx.Exec(d.rebind("INSERT INTO cifrado (id, verificador) VALUES (1, ?)"), nueva.verificador); err != nil {
+			return fmt.Errorf("error al registrar la clave de cifrado: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+type filaCifrada struct {
+	facultad  string
+	cedula    string
+	nombre    string
+	deletedAt sql.NullString
+	version   int64
+}
+
+// recifrarEstudiantes cambia la cédula de cada estudiante. Como la cédula es parte de la
+// clave primaria, se inserta la fila nueva, se mueven sus inscripciones, sus lugares en
+// las listas de espera, sus notas y su carrera y se borra la anterior, así ninguna clave
+// foránea queda rota ni siquiera dentro de la transacción.
+func recifrarEstudiantes(tx *sql.Tx, d Dialecto, actual, nueva *Cifrador) error {
+	rows, err := tx.Query("SELECT facultad, cedula, nombre, deleted_at, version FROM estudiantes")
+	if err != nil {
+		return fmt.Errorf("error al leer estudiantes: %w", err)
+	}
+	var filas []filaCifrada
+	for rows.Next() {
+		var f filaCifrada
+		if err := rows.Scan(&f.facultad, &f.cedula, &f.nombre, &f.deletedAt, &f.version); err != nil {
+			rows.Close()
+			return err
+		}
+		filas = append(filas, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, f := range filas {
+		cedula, nombre := f.cedula, f.nombre
+		if err := actual.descifrar(&cedula, &nombre); err != nil {
+			return fmt.Errorf("error al descifrar estudiante: %w", err)
+		}
+		nuevaCedula := nueva.cifrarClave(cedula)
+
+		_, err := tx.Exec(d.rebind("INSERT INTO estudiantes (facultad, cedula, nombre, deleted_at, version) VALUES (?, ?, ?, ?, ?)"),
+			f.facultad, nuevaCedula, nueva.cifrarDato(nombre), f.deletedAt, f.version)
+		if err != nil {
+			return fmt.Errorf("error al recifrar estudiante: %w", err)
+		}
+		_, err = tx.Exec(d.rebind("UPDATE inscripciones SET estudiante_cedula = ? WHERE facultad = ? AND estudiante_cedula = ?"),
+			nuevaCedula, f.facultad, f.cedula)
+		if err != nil {
+			return fmt.Errorf("error al recifrar inscripciones: %w", err)
+		}
+		_, err = tx.Exec(d.rebind("UPDATE lista_espera SET estudiante_cedula = ? WHERE facultad = ? AND estudiante_cedula = ?"),
+			nuevaCedula, f.facultad, f.cedula)
+		if err != nil {
+			return fmt.Errorf("error al recifrar listas de espera: %w", err)
+		}
+		_, err = tx.Exec(d.rebind("UPDATE calificaciones SET estudiante_cedula = ? WHERE facultad = ? AND estudiante_cedula = ?"),
+			nuevaCedula, f.facultad, f.cedula)
+		if err != nil {
+			return fmt.Errorf("error al recifrar calificaciones: %w", err)
+		}
+		_, err = tx.Exec(d.rebind("UPDATE carrera_estudiantes SET estudiante_cedula = ? WHERE facultad = ? AND estudiante_cedula = ?"),
+			nuevaCedula, f.facultad, f.cedula)
+		if err != nil {
+			return fmt.Errorf("error al recifrar carreras de estudiantes: %w", err)
+		}
+		if _, err := tx.Exec(d.rebind("DELETE FROM estudiantes WHERE facultad = ? AND cedula = ?"), f.facultad, f.cedula); err != nil {
+			return fmt.Errorf("error al recifrar estudiante: %w", err)
+		}
+		if !nueva.activo() {
+			if err := indiceEstudiantes.indexar(tx, d, f.facultad, cedula, nombre); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recifrarDocentes cambia la cédula y el nombre de cada docente del mismo modo que
+// recifrarEstudiantes: inserta la fila nueva, mueve sus asignaciones y borra la anterior
+func recifrarDocentes(tx *sql.Tx, d Dialecto, actual, nueva *Cifrador) error {
+	rows, err := tx.Query("SELECT facultad, cedula, nombre FROM docentes")
+	if err != nil {
+		return fmt.Errorf("error al leer docentes: %w", err)
+	}
+	var filas []filaCifrada
+	for rows.Next() {
+		var f filaCifrada
+		if err := rows.Scan(&f.facultad, &f.cedula, &f.nombre); err != nil {
+			rows.Close()
+			return err
+		}
+		filas = append(filas, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, f := range filas {
+		cedula, nombre := f.cedula, f.nombre
+		if err := actual.descifrar(&cedula, &nombre); err != nil {
+			return fmt.Errorf("error al descifrar docente: %w", err)
+		}
+		nuevaCedula := nueva.cifrarClave(cedula)
+
+		_, err := tx.Exec(d.rebind("INSERT INTO docentes (facultad, cedula, nombre) VALUES (?, ?, ?)"),
+			f.facultad, nuevaCedula, nueva.cifrarDato(nombre))
+		if err != nil {
+			return fmt.Errorf("error al recifrar docente: %w", err)
+		}
+		_, err = tx.Exec(d.rebind("UPDATE asignaciones_docentes SET docente_cedula = ? WHERE facultad = ? AND docente_cedula = ?"),
+			nuevaCedula, f.facultad, f.cedula)
+		if err != nil {
+			return fmt.Errorf("error al recifrar asignaciones de docentes: %w", err)
+		}
+		if _, err := tx.Exec(d.rebind("DELETE FROM docentes WHERE facultad = ? AND cedula = ?"), f.facultad, f.cedula); err != nil {
+			return fmt.Errorf("error al recifrar docente: %w", err)
+		}
+	}
+	return nil
+}
+
+// recifrarAuditoria cifra de nuevo la clave, la cédula y las copias de cada registro. Es
+// la única modificación permitida sobre la auditoría: la protección de solo inserción se
+// suspende dentro de la transacción y se restablece antes de confirmarla.
+func recifrarAuditoria(tx *sql.Tx, d Dialecto, actual, nueva *Cifrador) error {
+	suspender, restablecer := "DROP TRIGGER auditoria_sin_actualizaciones", triggerAuditoriaSinActualizaciones
+	if d == DialectoPostgres {
+		suspender = "ALTER TABLE auditoria DISABLE TRIGGER auditoria_sin_modificaciones"
+		restablecer = "ALTER TABLE auditoria ENABLE TRIGGER auditoria_sin_modificaciones"
+	}
+	if _, err := tx.Exec(suspender); err != nil {
+		return fmt.Errorf("error al preparar la auditoría: %w", err)
+	}
+	if err := actualizarAuditoria(tx, d, actual, nueva); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(restablecer); err != nil {
+		return fmt.Errorf("error al proteger la auditoría: %w", err)
+	}
+	return nil
+}
+
+func actualizarAuditoria(tx *sql.Tx, d Dialecto, actual, nueva *Cifrador) error {
+	type registro struct {
+		id             int64
+		clave, cedula  string
+		antes, despues string
+	}
+	rows, err := tx.Query("SELECT id, clave, estudiante_cedula, antes, despues FROM auditoria")
+	if err != nil {
+		return fmt.Errorf("error al leer la auditoría: %w", err)
+	}
+	var registros []registro
+	for rows.Next() {
+		var r registro
+		var cedula, antes, despues sql.NullString
+		if err := rows.Scan(&r.id, &r.clave, &cedula, &antes, &despues); err != nil {
+			rows.Close()
+			return err
+		}
+		r.cedula, r.antes, r.despues = cedula.String, antes.String, despues.String
+		registros = append(registros, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, r := range registros {
+		if err := actual.descifrar(&r.clave, &r.cedula, &r.antes, &r.despues); err != nil {
+			return fmt.Errorf("error al descifrar la auditoría: %w", err)
+		}
+		_, err := tx.Exec(d.rebind("UPDATE auditoria SET clave = ?, estudiante_cedula = ?, antes = ?, despues = ? WHERE id = ?"),
+			nueva.cifrarDato(r.clave),
+			textoNulo(nueva.cifrarClave(r.cedula)),
+			textoNulo(nueva.cifrarDato(r.antes)),
+			textoNulo(nueva.cifrarDato(r.despues)),
+			r.id,
+		)
+		if err != nil {
+			return fmt.Errorf("error al recifrar la auditoría: %w", err)
+		}
+	}
+	return nil
+}
+
+// recifrarOutbox cifra de nuevo los datos de cada evento, entregado o no
+func recifrarOutbox(tx *sql.Tx, d Dialecto, actual, nueva *Cifrador) error {
+	rows, err := tx.Query("SELECT id, datos FROM outbox")
+	if err != nil {
+		return fmt.Errorf("error al leer el outbox: %w", err)
+	}
+	datos := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var valor string
+		if err := rows.Scan(&id, &valor); err != nil {
+			rows.Close()
+			return err
+		}
+		datos[id] = valor
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, valor := range datos {
+		if err := actual.descifrar(&valor); err != nil {
+			return fmt.Errorf("error al descifrar el outbox: %w", err)
+		}
+		if _, err := tx.Exec(d.rebind("UPDATE outbox SET datos = ? WHERE id = ?"), nueva.cifrarDato(valor), id); err != nil {
+			return fmt.Errorf("error al recifrar el outbox: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package repository_test
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// abrirCifrado abre la base con la clave indicada; sin clave, en texto plano
+func abrirCifrado(t *testing.T, dsn, clave string) (*repository.Repositorios, error) {
+	t.Helper()
+	repos, err := repository.Abrir(repository.Config{Driver: repository.DriverSQLite, DSN: dsn, Clave: clave})
+	if err == nil {
+		t.Cleanup(func() { repos.Close() })
+	}
+	return repos, err
+}
+
+func conectar(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
+	db, _, err := repository.Conectar(repository.Config{Driver: repository.DriverSQLite, DSN: dsn})
+	if err != nil {
+		t.Fatalf("Conectar: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func cargarDatosPersonales(t *testing.T, repos *repository.Repositorios) {
+	t.Helper()
+	if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+		t.Fatalf("Create estudiante: %v", err)
+	}
+	if err := repos.Materias.Create(domain.NewMateria("1040", "Cálculo")); err != nil {
+		t.Fatalf("Create materia: %v", err)
+	}
+	if err := repos.Inscripciones.Create("1234567", "1040"); err != nil {
+		t.Fatalf("Create inscripción: %v", err)
+	}
+	if err := repos.Componentes.Create(domain.Componente{Materia: "1040", Nombre: "Final", Peso: domain.PesoTotal}); err != nil {
+		t.Fatalf("Create componente: %v", err)
+	}
+	if err := repos.Calificaciones.Registrar("1234567", "1040", "Final", 4.5); err != nil {
+		t.Fatalf("Registrar nota: %v", err)
+	}
+	if err := repos.Carreras.Create(&domain.Carrera{Codigo: "IS", Nombre: "Ingeniería de Sistemas", Creditos: 160}); err != nil {
+		t.Fatalf("Create carrera: %v", err)
+	}
+	if err := repos.Carreras.AsignarEstudiante("1234567", "IS"); err != nil {
+		t.Fatalf("AsignarEstudiante: %v", err)
+	}
+	if err := repos.Docentes.Create(domain.NewDocente("7654321", "Ramiro Ruiz")); err != nil {
+		t.Fatalf("Create docente: %v", err)
+	}
+	if err := repos.Docentes.Asignar("7654321", "1040", domain.SinGrupo); err != nil {
+		t.Fatalf("Asignar docente: %v", err)
+	}
+	if err := repos.Materias.Create(domain.NewMateria("1050", "Física I")); err != nil {
+		t.Fatalf("Create materia: %v", err)
+	}
+	if _, err := repos.ListaEspera.Agregar("1234567", "1050", domain.SinGrupo); err != nil {
+		t.Fatalf("Agregar a la lista de espera: %v", err)
+	}
+	if err := repos.Eventos.Publicar(domain.NewEvento(domain.EstudianteCreado{Cedula: "1234567", Nombre: "Lulú López"})); err != nil {
+		t.Fatalf("Publicar: %v", err)
+	}
+}
+
+// textoEnDisco concatena todo lo que las tablas guardan sobre los estudiantes y los docentes
+func textoEnDisco(t *testing.T, db *sql.DB) string {
+	t.Helper()
+	var partes []string
+	for _, consulta := range []string{
+		"SELECT cedula || ' ' || nombre FROM estudiantes",
+		"SELECT estudiante_cedula FROM inscripciones",
+		"SELECT estudiante_cedula FROM lista_espera",
+		"SELECT estudiante_cedula FROM calificaciones",
+		"SELECT estudiante_cedula FROM carrera_estudiantes",
+		"SELECT cedula || ' ' || nombre FROM docentes",
+		"SELECT docente_cedula FROM asignaciones_docentes",
+		"SELECT clave || ' ' || COALESCE(estudiante_cedula, '') || ' ' || COALESCE(antes, '') || ' ' || COALESCE(despues, '') FROM auditoria",
+		"SELECT datos FROM outbox",
+		"SELECT clave || ' ' || texto FROM estudiantes_fts",
+	} {
+		rows, err := db.Query(consulta)
+		if err != nil {
+			t.Fatalf("%s: %v", consulta, err)
+		}
+		for rows.Next() {
+			var texto string
+			if err := rows.Scan(&texto); err != nil {
+				t.Fatalf("%s: %v", consulta, err)
+			}
+			partes = append(partes, texto)
+		}
+		rows.Close()
+	}
+	return strings.Join(partes, "\n")
+}
+
+func comprobarDatosPersonales(t *testing.T, repos *repository.Repositorios) {
+	t.Helper()
+	if e, err := repos.Estudiantes.GetByCedula("1234567"); err != nil || e == nil || e.Nombre != "Lulú López" {
+		t.Fatalf("GetByCedula = %+v, %v; se esperaba el estudiante descifrado", e, err)
+	}
+	if materias, _ := repos.Inscripciones.GetByEstudiante("1234567"); len(materias) != 1 {
+		t.Fatalf("GetByEstudiante = %d materias, se esperaba 1", len(materias))
+	}
+	if notas, _ := repos.Calificaciones.GetByMateria("1040"); len(notas) != 1 || notas[0].Cedula != "1234567" || notas[0].Nota != 4.5 {
+		t.Fatalf("GetByMateria = %+v, se esperaba la nota del estudiante", notas)
+	}
+	if estudiantes, _ := repos.Carreras.GetEstudiantes("IS"); len(estudiantes) != 1 || estudiantes[0].Cedula != "1234567" {
+		t.Fatalf("GetEstudiantes = %+v, se esperaba el estudiante en su carrera", estudiantes)
+	}
+	if estudiantes, _ := repos.Docentes.GetEstudiantes("7654321"); len(estudiantes) != 1 || estudiantes[0].Nombre != "Lulú López" {
+		t.Fatalf("GetEstudiantes del docente = %+v, se esperaba el estudiante", estudiantes)
+	}
+	if docente, _ := repos.Docentes.GetByCedula("7654321"); docente == nil || docente.Nombre != "Ramiro Ruiz" {
+		t.Fatalf("GetByCedula docente = %+v, se esperaba el docente descifrado", docente)
+	}
+	if posicion, _ := repos.ListaEspera.Posicion("1234567", "1050"); posicion != 1 {
+		t.Fatalf("Posicion = %d, se esperaba el estudiante primero en la lista de espera", posicion)
+	}
+	if encontrados, _ := repos.Estudiantes.Search("lulu", 10); len(encontrados) != 1 || encontrados[0].Cedula != "1234567" {
+		t.Fatalf("Search = %+v, se esperaba el estudiante", encontrados)
+	}
+	historial, err := repos.Auditoria.HistorialEstudiante("1234567")
+	if err != nil || len(historial) != 4 || !strings.Contains(historial[0].Despues, "Lulú López") {
+		t.Fatalf("HistorialEstudiante = %+v, %v; se esperaban el alta, la inscripción, la nota y la carrera descifradas", historial, err)
+	}
+	pendientes, err := repos.Eventos.Pendientes(10)
+	if err != nil || len(pendientes) != 1 || !strings.Contains(pendientes[0].Datos, "1234567") {
+		t.Fatalf("Pendientes = %+v, %v; se esperaba el evento descifrado", pendientes, err)
+	}
+}
+
+func TestDatosPersonalesCifradosEnDisco(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "inscripciones.db")
+	clave := repository.GenerarClave()
+	repos, err := abrirCifrado(t, dsn, clave)
+	if err != nil {
+		t.Fatalf("Abrir: %v", err)
+	}
+	if !repos.Cifrado() {
+		t.Fatal("Cifrado = false con clave configurada")
+	}
+	cargarDatosPersonales(t, repos)
+	comprobarDatosPersonales(t, repos)
+
+	enDisco := textoEnDisco(t, conectar(t, dsn))
+	for _, dato := range []string{"1234567", "Lulú", "López", "7654321", "Ramiro"} {
+		if strings.Contains(enDisco, dato) {
+			t.Errorf("%q quedó en texto plano en la base", dato)
+		}
+	}
+
+	// La cédula cifrada es la misma en el estudiante y en su inscripción
+	var cedulas int
+	if err := conectar(t, dsn).QueryRow("SELECT COUNT(DISTINCT cedula) FROM estudiantes JOIN inscripciones ON estudiante_cedula = cedula").Scan(&cedulas); err != nil || cedulas != 1 {
+		t.Fatalf("JOIN por cédula cifrada = %d, %v; se esperaba 1", cedulas, err)
+	}
+}
+
+func TestAbrirVerificaLaClave(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "inscripciones.db")
+	repos, err := abrirCifrado(t, dsn, repository.GenerarClave())
+	if err != nil {
+		t.Fatalf("Abrir: %v", err)
+	}
+	cargarDatosPersonales(t, repos)
+	repos.Close()
+
+	if _, err := abrirCifrado(t, dsn, ""); !errors.Is(err, repository.ErrClaveRequerida) {
+		t.Errorf("Abrir sin clave = %v, se esperaba ErrClaveRequerida", err)
+	}
+	if _, err := abrirCifrado(t, dsn, repository.GenerarClave()); !errors.Is(err, repository.ErrClaveIncorrecta) {
+		t.Errorf("Abrir con otra clave = %v, se esperaba ErrClaveIncorrecta", err)
+	}
+	if _, err := abrirCifrado(t, dsn, "no es base64"); !errors.Is(err, repository.ErrClaveInvalida) {
+		t.Errorf("Abrir con clave mal codificada = %v, se esperaba ErrClaveInvalida", err)
+	}
+
+	// Una base con datos en texto plano no se cifra a medias al abrirla con clave
+	plano := filepath.Join(t.TempDir(), "plano.db")
+	sinClave, err := abrirCifrado(t, plano, "")
+	if err != nil {
+		t.Fatalf("Abrir sin clave: %v", err)
+	}
+	cargarDatosPersonales(t, sinClave)
+	if _, err := abrirCifrado(t, plano, repository.GenerarClave()); !errors.Is(err, repository.ErrBaseSinCifrar) {
+		t.Errorf("Abrir base en texto plano con clave = %v, se esperaba ErrBaseSinCifrar", err)
+	}
+}
+
+func TestRecifrar(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "inscripciones.db")
+	repos, err := abrirCifrado(t, dsn, "")
+	if err != nil {
+		t.Fatalf("Abrir: %v", err)
+	}
+	cargarDatosPersonales(t, repos)
+	repos.Close()
+
+	primera, segunda := repository.GenerarClave(), repository.GenerarClave()
+	recifrar := func(actual, nueva string) error {
+		t.Helper()
+		var cifradores [2]*repository.Cifrador
+		for i, clave := range []string{actual, nueva} {
+			if clave == "" {
+				continue
+			}
+			c, err := repository.CifradorDesdeTexto(clave)
+			if err != nil {
+				t.Fatalf("CifradorDesdeTexto: %v", err)
+			}
+			cifradores[i] = c
+		}
+		return repository.Recifrar(conectar(t, dsn), repository.DialectoSQLite, cifradores[0], cifradores[1])
+	}
+
+	// Cifrar la base existente, rotar la clave y volver a dejarla en texto plano
+	pasos := []struct{ actual, nueva string }{{"", primera}, {primera, segunda}, {segunda, ""}}
+	for _, paso := range pasos {
+		if err := recifrar(paso.actual, paso.nueva); err != nil {
+			t.Fatalf("Recifrar: %v", err)
+		}
+		repos, err := abrirCifrado(t, dsn, paso.nueva)
+		if err != nil {
+			t.Fatalf("Abrir tras recifrar: %v", err)
+		}
+		comprobarDatosPersonales(t, repos)
+		repos.Close()
+		if enDisco := textoEnDisco(t, conectar(t, dsn)); paso.nueva != "" && (strings.Contains(enDisco, "1234567") || strings.Contains(enDisco, "7654321")) {
+			t.Fatal("quedó una cédula en texto plano tras recifrar")
+		}
+	}
+
+	if err := recifrar(primera, segunda); !errors.Is(err, repository.ErrBaseSinCifrar) {
+		t.Fatalf("Recifrar con una clave actual que la base no tiene = %v, se esperaba ErrBaseSinCifrar", err)
+	}
+	// La auditoría sigue siendo de solo inserción después de recifrar
+	if _, err := conectar(t, dsn).Exec("UPDATE auditoria SET actor = 'otro'"); err == nil {
+		t.Fatal("se pudo modificar la auditoría después de recifrar")
+	}
+}
+
+func TestConfigCifradorDesdeArchivo(t *testing.T) {
+	archivo := filepath.Join(t.TempDir(), "clave")
+	if err := os.WriteFile(archivo, []byte(repository.GenerarClave()+"\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	cifrador, err := repository.Config{ArchivoClave: archivo}.Cifrador()
+	if err != nil || cifrador == nil {
+		t.Fatalf("Cifrador desde archivo = %v, %v", cifrador, err)
+	}
+	if cifrador, err := (repository.Config{}).Cifrador(); err != nil || cifrador != nil {
+		t.Fatalf("Cifrador sin clave = %v, %v; se esperaba nil", cifrador, err)
+	}
+	if _, err := (repository.Config{Clave: repository.GenerarClave(), ArchivoClave: archivo}).Cifrador(); !errors.Is(err, repository.ErrClaveInvalida) {
+		t.Fatalf("Cifrador con dos claves = %v, se esperaba ErrClaveInvalida", err)
+	}
+}
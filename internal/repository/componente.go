@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"inscripciones/internal/domain"
+)
+
+var (
+	// ErrPesoExcedido indica que los pesos de los componentes de una materia sumarían más de domain.PesoTotal
+	ErrPesoExcedido = errors.New("los pesos de los componentes superan el 100%")
+	// ErrComponenteConNotas impide eliminar un componente que ya tiene calificaciones
+	ErrComponenteConNotas = errors.New("el componente tiene calificaciones")
+)
+
+// ComponenteRepository administra los componentes de evaluación de las materias de la
+// facultad. Son comunes a todos los periodos y no se auditan: lo que se audita son las
+// calificaciones. Se identifican por materia y nombre.
+type ComponenteRepository interface {
+	// Create agrega el componente. Retorna ErrReferenciaInvalida si la materia no existe o
+	// está eliminada, ErrDuplicado si ya tenía uno con ese nombre y ErrPesoExcedido si los
+	// pesos de la materia pasarían de domain.PesoTotal.
+	Create(c domain.Componente) error
+	// CambiarPeso actualiza el peso del componente; retorna ErrNoEncontrado si no existe y
+	// ErrPesoExcedido si los pesos de la materia pasarían de domain.PesoTotal
+	CambiarPeso(c domain.Componente) error
+	// Delete quita el componente; retorna ErrNoEncontrado si no estaba y
+	// ErrComponenteConNotas si tiene calificaciones en cualquier periodo
+	Delete(materiaCodigo, nombre string) error
+	// GetByMateria retorna los componentes de la materia ordenados por nombre
+	GetByMateria(materiaCodigo string) ([]domain.Componente, error)
+	// GetAll retorna todos los componentes, también los de materias eliminadas, ordenados
+	// por materia y nombre
+	GetAll() ([]domain.Componente, error)
+}
+
+type componenteRepo struct {
+	db       ejecutor
+	dialecto Dialecto
+	facultad string
+}
+
+func (r *componenteRepo) Create(c domain.Componente) error {
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var activa bool
+		err := tx.QueryRow(r.dialecto.rebind("SELECT EXISTS(SELECT 1 FROM materias WHERE facultad = ? AND codigo = ? AND deleted_at IS NULL)"),
+			r.facultad, c.Materia).Scan(&activa)
+		if err != nil {
+			return err
+		}
+		if !activa {
+			return fmt.Errorf("%w: materia %s", ErrReferenciaInvalida, c.Materia)
+		}
+
+		_, err = tx.Exec(r.dialecto.rebind("INSERT INTO componentes (facultad, materia_codigo, nombre, peso) VALUES (?, ?, ?, ?)"),
+			r.facultad, c.Materia, c.Nombre, c.Peso)
+		if err != nil {
+			return traducirError(err)
+		}
+		return r.verificarPesos(tx, c.Materia)
+	})
+	if err != nil {
+		return fmt.Errorf("error al agregar el componente %s a %s: %w", c.Nombre, c.Materia, err)
+	}
+	return nil
+}
+
+func (r *componenteRepo) CambiarPeso(c domain.Componente) error {
+	err := transaccion(r.db, func(tx ejecutor) error {
+		resultado, err := tx.Exec(r.dialecto.rebind("UPDATE componentes SET peso = ? WHERE facultad = ? AND materia_codigo = ? AND nombre = ?"),
+			c.Peso, r.facultad, c.Materia, c.Nombre)
+		if err != nil {
+			return err
+		}
+		filas, err := resultado.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if filas == 0 {
+			return ErrNoEncontrado
+		}
+		return r.verificarPesos(tx, c.Materia)
+	})
+	if err != nil {
+		return fmt.Errorf("error al cambiar el peso del componente %s de %s: %w", c.Nombre, c.Materia, err)
+	}
+	return nil
+}
+
+// verificarPesos comprueba, ya guardado el cambio, que los pesos de la materia no pasen
+// de domain.PesoTotal; así el error deshace la transacción que lo produjo
+func (r *componenteRepo) verificarPesos(tx ejecutor, materiaCodigo string) error {
+	var total int
+	err := tx.QueryRow(r.dialecto.rebind("SELECT COALESCE(SUM(peso), 0) FROM componentes WHERE facultad = ? AND materia_codigo = ?"),
+		r.facultad, materiaCodigo).Scan(&total)
+	if err != nil {
+		return err
+	}
+	if total > domain.PesoTotal {
+		return fmt.Errorf("%w: sumarían %d%%", ErrPesoExcedido, total)
+	}
+	return nil
+}
+
+func (r *componenteRepo) Delete(materiaCodigo, nombre string) error {
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var conNotas bool
+		err := tx.QueryRow(r.dialecto.rebind("SELECT EXISTS(SELECT 1 FROM calificaciones WHERE facultad = ? AND materia_codigo = ? AND componente = ?)"),
+			r.facultad, materiaCodigo, nombre).Scan(&conNotas)
+		if err != nil {
+			return err
+		}
+		if conNotas {
+			return ErrComponenteConNotas
+		}
+
+		resultado, err := tx.Exec(r.dialecto.rebind("DELETE FROM componentes WHERE facultad = ? AND materia_codigo = ? AND nombre = ?"),
+			r.facultad, materiaCodigo, nombre)
+		if err != nil {
+			return err
+		}
+		filas, err := resultado.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if filas == 0 {
+			return ErrNoEncontrado
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error al quitar el componente %s de %s: %w", nombre, materiaCodigo, err)
+	}
+	return nil
+}
+
+func (r *componenteRepo) GetByMateria(materiaCodigo string) ([]domain.Componente, error) {
+	return r.consultar("SELECT materia_codigo, nombre, peso FROM componentes WHERE facultad = ? AND materia_codigo = ? ORDER BY nombre",
+		r.facultad, materiaCodigo)
+}
+
+func (r *componenteRepo) GetAll() ([]domain.Componente, error) {
+	return r.consultar("SELECT materia_codigo, nombre, peso FROM componentes WHERE facultad = ? ORDER BY materia_codigo, nombre", r.facultad)
+}
+
+func (r *componenteRepo) consultar(consulta string, args ...any) ([]domain.Componente, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(consulta), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var componentes []domain.Componente
+	for rows.Next() {
+		var c domain.Componente
+		if err := rows.Scan(&c.Materia, &c.Nombre, &c.Peso); err != nil {
+			return nil, err
+		}
+		componentes = append(componentes, c)
+	}
+	return componentes, rows.Err()
+}
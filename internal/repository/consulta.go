@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Límites de tamaño de página para los métodos List
+const (
+	LimitePorDefecto = 50
+	LimiteMaximo     = 1000
+)
+
+// CampoOrden indica por qué campo se ordena un listado
+type CampoOrden string
+
+const (
+	// OrdenPorClave ordena por cédula o código (en inscripciones: cédula y luego código)
+	OrdenPorClave CampoOrden = ""
+	// OrdenPorNombre ordena por nombre (en inscripciones: nombre del estudiante)
+	OrdenPorNombre CampoOrden = "nombre"
+	// OrdenPorMateria ordena las inscripciones por código de materia
+	OrdenPorMateria CampoOrden = "materia"
+)
+
+var (
+	ErrCursorInvalido = errors.New("cursor de paginación inválido")
+	ErrOrdenInvalido  = errors.New("campo de ordenamiento no soportado")
+)
+
+// Consulta describe una página de un listado: tamaño, posición, orden y filtros.
+// Nombre filtra por coincidencia parcial sin distinguir mayúsculas y Codigo por
+// prefijo de la cédula o el código; en las inscripciones Nombre se aplica al
+// estudiante y Codigo a la materia. Los registros eliminados lógicamente solo
+// aparecen si Eliminados lo pide explícitamente.
+type Consulta struct {
+	Limite      int
+	Cursor      string
+	Orden       CampoOrden
+	Descendente bool
+	Nombre      string
+	Codigo      string
+	Eliminados  FiltroEliminados
+}
+
+// Pagina es el resultado de un listado paginado; SiguienteCursor queda vacío en la última página
+type Pagina[T any] struct {
+	Elementos       []T
+	SiguienteCursor string
+}
+
+func (q Consulta) limite() int {
+	switch {
+	case q.Limite <= 0:
+		return LimitePorDefecto
+	case q.Limite > LimiteMaximo:
+		return LimiteMaximo
+	default:
+		return q.Limite
+	}
+}
+
+// El cursor es opaco para los clientes: codifica los valores de orden del último elemento entregado
+func codificarCursor(valores []string) string {
+	datos, _ := json.Marshal(valores)
+	return base64.RawURLEncoding.EncodeToString(datos)
+}
+
+func decodificarCursor(cursor string, columnas int) ([]string, error) {
+	datos, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrCursorInvalido
+	}
+	var valores []string
+	if err := json.Unmarshal(datos, &valores); err != nil || len(valores) != columnas {
+		return nil, ErrCursorInvalido
+	}
+	return valores, nil
+}
+
+// patronLike escapa los comodines de LIKE en un valor ingresado por el usuario
+func patronLike(valor string) string {
+	reemplazo := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return reemplazo.Replace(valor)
+}
+
+// listadoSQL arma y ejecuta la consulta paginada por conjunto de claves (keyset)
+type listadoSQL[T any] struct {
+	db       ejecutor
+	dialecto Dialecto
+	// seleccion incluye el SELECT y los JOIN, sin WHERE
+	seleccion     string
+	condiciones   []string
+	args          []any
+	columnaNombre string
+	columnaCodigo string
+	// columnasEliminado son las columnas deleted_at a las que se aplica el filtro de eliminados
+	columnasEliminado []string
+	columnasOrden     []string
+	escanear          func(*sql.Rows) (T, []string, error)
+	// filtrarEnMemoria se define cuando el nombre o la clave están cifrados: SQL no puede
+	// filtrarlos ni ordenarlos, así que se leen todas las filas y, ya descifradas, se
+	// filtran y paginan en memoria. Retorna los valores a los que se aplican Nombre y Codigo.
+	filtrarEnMemoria func(T) (nombre, codigo string)
+}
+
+// filaOrdenada guarda un elemento junto a sus valores de orden
+type filaOrdenada[T any] struct {
+	elemento T
+	clave    []string
+}
+
+func (l *listadoSQL[T]) ejecutar(q Consulta) (*Pagina[T], error) {
+	if l.filtrarEnMemoria != nil {
+		return l.ejecutarEnMemoria(q)
+	}
+
+	condiciones := append([]string{}, l.condiciones...)
+	args := append([]any{}, l.args...)
+
+	if q.Nombre != "" {
+		condiciones = append(condiciones, l.columnaNombre+" "+l.dialecto.like()+` ? ESCAPE '\'`)
+		args = append(args, "%"+patronLike(q.Nombre)+"%")
+	}
+	if q.Codigo != "" {
+		condiciones = append(condiciones, l.columnaCodigo+` LIKE ? ESCAPE '\'`)
+		args = append(args, patronLike(q.Codigo)+"%")
+	}
+	if condicion := condicionEliminados(q.Eliminados, l.columnasEliminado); condicion != "" {
+		condiciones = append(condiciones, condicion)
+	}
+
+	comparador, direccion := ">", "ASC"
+	if q.Descendente {
+		comparador, direccion = "<", "DESC"
+	}
+
+	if q.Cursor != "" {
+		valores, err := decodificarCursor(q.Cursor, len(l.columnasOrden))
+		if err != nil {
+			return nil, err
+		}
+		marcadores := strings.TrimSuffix(strings.Repeat("?, ", len(valores)), ", ")
+		condiciones = append(condiciones, fmt.Sprintf("(%s) %s (%s)", strings.Join(l.columnasOrden, ", "), comparador, marcadores))
+		for _, v := range valores {
+			args = append(args, v)
+		}
+	}
+
+	var orden []string
+	for _, columna := range l.columnasOrden {
+		orden = append(orden, columna+" "+direccion)
+	}
+
+	query := l.seleccion
+	if len(condiciones) > 0 {
+		query += " WHERE " + strings.Join(condiciones, " AND ")
+	}
+	limite := q.limite()
+	query += fmt.Sprintf(" ORDER BY %s LIMIT %d", strings.Join(orden, ", "), limite+1)
+
+	rows, err := l.db.Query(l.dialecto.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pagina := &Pagina[T]{}
+	var ultimaClave []string
+	for rows.Next() {
+		elemento, clave, err := l.escanear(rows)
+		if err != nil {
+			return nil, err
+		}
+		if len(pagina.Elementos) == limite {
+			pagina.SiguienteCursor = codificarCursor(ultimaClave)
+			break
+		}
+		pagina.Elementos = append(pagina.Elementos, elemento)
+		ultimaClave = clave
+	}
+
+	return pagina, rows.Err()
+}
+
+func (l *listadoSQL[T]) ejecutarEnMemoria(q Consulta) (*Pagina[T], error) {
+	condiciones := append([]string{}, l.condiciones...)
+	if condicion := condicionEliminados(q.Eliminados, l.columnasEliminado); condicion != "" {
+		condiciones = append(condiciones, condicion)
+	}
+	query := l.seleccion
+	if len(condiciones) > 0 {
+		query += " WHERE " + strings.Join(condiciones, " AND ")
+	}
+
+	rows, err := l.db.Query(l.dialecto.rebind(query), l.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var filas []filaOrdenada[T]
+	for rows.Next() {
+		elemento, clave, err := l.escanear(rows)
+		if err != nil {
+			return nil, err
+		}
+		if q.coincide(l.filtrarEnMemoria(elemento)) {
+			filas = append(filas, filaOrdenada[T]{elemento: elemento, clave: clave})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ordenadas, err := paginarEnMemoria(filas, len(l.columnasOrden), func(f filaOrdenada[T]) []string { return f.clave }, q)
+	if err != nil {
+		return nil, err
+	}
+	pagina := &Pagina[T]{SiguienteCursor: ordenadas.SiguienteCursor}
+	for _, f := range ordenadas.Elementos {
+		pagina.Elementos = append(pagina.Elementos, f.elemento)
+	}
+	return pagina, nil
+}
+
+// paginarEnMemoria aplica a un conjunto ya filtrado el mismo orden y cursor que los listados SQL
+func paginarEnMemoria[T any](elementos []T, columnas int, claves func(T) []string, q Consulta) (*Pagina[T], error) {
+	menor := func(a, b []string) bool {
+		for i := range a {
+			if a[i] != b[i] {
+				return a[i] < b[i]
+			}
+		}
+		return false
+	}
+	antes := func(a, b []string) bool {
+		if q.Descendente {
+			return menor(b, a)
+		}
+		return menor(a, b)
+	}
+
+	sort.Slice(elementos, func(i, j int) bool { return antes(claves(elementos[i]), claves(elementos[j])) })
+
+	inicio := 0
+	if q.Cursor != "" {
+		valores, err := decodificarCursor(q.Cursor, columnas)
+		if err != nil {
+			return nil, err
+		}
+		inicio = sort.Search(len(elementos), func(i int) bool { return antes(valores, claves(elementos[i])) })
+	}
+
+	pagina := &Pagina[T]{}
+	fin := inicio + q.limite()
+	if fin < len(elementos) {
+		pagina.Elementos = elementos[inicio:fin]
+		pagina.SiguienteCursor = codificarCursor(claves(elementos[fin-1]))
+	} else {
+		pagina.Elementos = elementos[inicio:]
+	}
+	return pagina, nil
+}
+
+// coincide aplica en memoria la semántica de los filtros Nombre y Codigo
+func (q Consulta) coincide(nombre, codigo string) bool {
+	if q.Nombre != "" && !strings.Contains(strings.ToLower(nombre), strings.ToLower(q.Nombre)) {
+		return false
+	}
+	if q.Codigo != "" && !strings.HasPrefix(codigo, q.Codigo) {
+		return false
+	}
+	return true
+}
@@ -2,41 +2,343 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
+	"os"
+	"strings"
 
 	_ "github.com/glebarez/go-sqlite"
+	_ "github.com/lib/pq"
 )
 
+// Drivers soportados para la configuración de la base de datos
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMemoria  = "memoria"
+)
+
+// Variables de entorno que seleccionan el backend de persistencia
+const (
+	EnvDriver = "INSCRIPCIONES_DB_DRIVER"
+	EnvDSN    = "INSCRIPCIONES_DB_DSN"
+)
+
+// Variables de entorno que eligen la facultad y el periodo activos y habilitan el modo administrativo
+const (
+	EnvFacultad      = "INSCRIPCIONES_FACULTAD"
+	EnvPeriodo       = "INSCRIPCIONES_PERIODO"
+	EnvAdministrador = "INSCRIPCIONES_ADMIN"
+)
+
+const dsnSQLitePorDefecto = "./inscripciones.db"
+
+// Config describe a qué base de datos debe conectarse la aplicación
+type Config struct {
+	Driver string
+	DSN    string
+	// Facultad limita todos los datos a una facultad; vacía es FacultadPorDefecto
+	Facultad string
+	// Periodo limita las inscripciones a un periodo académico existente; vacío es el
+	// periodo actual de la facultad
+	Periodo string
+	// Administrador habilita los reportes que cruzan facultades
+	Administrador bool
+	// Clave, en base64, o ArchivoClave, la ruta de un archivo que la contiene, cifran los
+	// datos personales; sin ninguna de las dos se guardan en texto plano
+	Clave        string
+	ArchivoClave string
+}
+
+// ConfigDesdeEntorno lee la configuración desde las variables de entorno,
+// usando el archivo SQLite local cuando no se especifica nada
+func ConfigDesdeEntorno() Config {
+	cfg := Config{
+		Driver:        os.Getenv(EnvDriver),
+		DSN:           os.Getenv(EnvDSN),
+		Facultad:      os.Getenv(EnvFacultad),
+		Periodo:       os.Getenv(EnvPeriodo),
+		Administrador: activado(os.Getenv(EnvAdministrador)),
+		Clave:         os.Getenv(EnvClave),
+		ArchivoClave:  os.Getenv(EnvArchivoClave),
+	}
+	if cfg.Driver == "" {
+		cfg.Driver = DriverSQLite
+	}
+	if cfg.DSN == "" && cfg.Driver == DriverSQLite {
+		cfg.DSN = dsnSQLitePorDefecto
+	}
+	return cfg
+}
+
+// activado interpreta el valor de una variable de entorno que funciona como interruptor
+func activado(valor string) bool {
+	switch strings.ToLower(strings.TrimSpace(valor)) {
+	case "1", "true", "si", "sí":
+		return true
+	default:
+		return false
+	}
+}
+
+// Dialecto devuelve el dialecto SQL correspondiente al driver configurado
+func (c Config) Dialecto() (Dialecto, error) {
+	switch c.Driver {
+	case DriverSQLite:
+		return DialectoSQLite, nil
+	case DriverPostgres:
+		return DialectoPostgres, nil
+	default:
+		return 0, fmt.Errorf("driver de base de datos no soportado: %q", c.Driver)
+	}
+}
+
+// Cifrador crea el cifrador de la clave configurada; nil si no se configuró ninguna
+func (c Config) Cifrador() (*Cifrador, error) {
+	switch {
+	case c.Clave != "" && c.ArchivoClave != "":
+		return nil, fmt.Errorf("%w: defina %s o %s, no ambas", ErrClaveInvalida, EnvClave, EnvArchivoClave)
+	case c.Clave != "":
+		return CifradorDesdeTexto(c.Clave)
+	case c.ArchivoClave != "":
+		return CifradorDesdeArchivo(c.ArchivoClave)
+	default:
+		return nil, nil
+	}
+}
+
+// Conectar abre la base de datos indicada en la configuración y aplica las migraciones pendientes
+func Conectar(cfg Config) (*sql.DB, Dialecto, error) {
+	dialecto, err := cfg.Dialecto()
+	if err != nil {
+		return nil, 0, err
+	}
+	if cfg.DSN == "" {
+		return nil, 0, fmt.Errorf("no se especificó la cadena de conexión (%s)", EnvDSN)
+	}
+
+	dsn := cfg.DSN
+	if dialecto == DialectoSQLite {
+		dsn = dsnSQLite(dsn)
+	}
+
+	db, err := sql.Open(cfg.Driver, dsn)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, 0, err
+	}
+
+	if err := Migrar(db, dialecto); err != nil {
+		db.Close()
+		return nil, 0, err
+	}
+
+	return db, dialecto, nil
+}
+
+// dsnSQLite activa la verificación de claves foráneas, que SQLite deshabilita por defecto
+func dsnSQLite(dsn string) string {
+	if strings.Contains(dsn, "foreign_keys") {
+		return dsn
+	}
+	separador := "?"
+	if strings.Contains(dsn, "?") {
+		separador = "&"
+	}
+	return dsn + separador + "_pragma=foreign_keys(1)"
+}
+
+// InitDB abre la base de datos SQLite local por defecto
 func InitDB() (*sql.DB, error) {
-	db, err := sql.Open("sqlite", "./inscripciones.db")
+	db, _, err := Conectar(Config{Driver: DriverSQLite, DSN: dsnSQLitePorDefecto})
+	return db, err
+}
+
+// Abrir crea los repositorios del backend configurado, incluido el backend en memoria,
+// limitados a la facultad y al periodo de la configuración. La memoria no guarda nada al
+// salir, así que no usa la clave de cifrado.
+func Abrir(cfg Config) (*Repositorios, error) {
+	acceso := Acceso{Facultad: cfg.Facultad, Periodo: cfg.Periodo, Administrador: cfg.Administrador}
+	if cfg.Driver == DriverMemoria {
+		return NewRepositoriosEnMemoriaConAcceso(acceso)
+	}
+
+	cifrador, err := cfg.Cifrador()
+	if err != nil {
+		return nil, err
+	}
+	db, dialecto, err := Conectar(cfg)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := NewRepositoriosCifrados(db, dialecto, acceso, cifrador)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return repos, nil
+}
+
+// Repositorios agrupa las implementaciones de los repositorios para un mismo backend
+type Repositorios struct {
+	Estudiantes   EstudianteRepository
+	Materias      MateriaRepository
+	Inscripciones InscripcionRepository
+	Auditoria     AuditoriaRepository
+	Eventos       EventoRepository
+	Periodos      PeriodoRepository
+	// Prerrequisitos son comunes a todos los periodos de la facultad
+	Prerrequisitos PrerrequisitoRepository
+	// ListaEspera es la de las materias en el periodo de los repositorios
+	ListaEspera ListaEsperaRepository
+	// Grupos son, como los prerrequisitos, comunes a todos los periodos de la facultad
+	Grupos GrupoRepository
+	// Horarios son las sesiones semanales de las materias y de sus grupos, también comunes
+	// a todos los periodos
+	Horarios HorarioRepository
+	// Componentes son los de evaluación de cada materia, comunes a todos los periodos
+	Componentes ComponenteRepository
+	// Calificaciones son las notas de las inscripciones del periodo de los repositorios
+	Calificaciones CalificacionRepository
+	// Carreras son las de la facultad con sus pensums y la carrera de cada estudiante,
+	// comunes a todos los periodos
+	Carreras CarreraRepository
+	// Docentes son los de la facultad, comunes a todos los periodos; las materias que dicta
+	// cada uno son las del periodo de los repositorios
+	Docentes DocenteRepository
+
+	db       *sql.DB
+	backend  string
+	acceso   Acceso
+	cifrador *Cifrador
+	// conAuditoria recrea los repositorios sobre el mismo backend con otro actor y origen
+	conAuditoria func(ContextoAuditoria) *Repositorios
+	// cargar inserta un volcado completo de una sola vez en el backend
+	cargar func(*volcado) error
+	// enTransaccion ejecuta una unidad de trabajo sobre el backend
+	enTransaccion func(func(*Repositorios) error) error
+	// conFacultad recrea los repositorios sobre los datos de otra facultad, en su periodo actual
+	conFacultad func(facultad string) (*Repositorios, error)
+	// enPeriodo recrea los repositorios sobre las inscripciones de otro periodo
+	enPeriodo func(periodo string) (*Repositorios, error)
+	// facultades lista las facultades con datos en el backend
+	facultades func() ([]string, error)
+	// estadisticasCache lee los contadores de las cachés; nil si no hay caché
+	estadisticasCache func() (estudiantes, materias EstadisticasCache)
+}
+
+// NewRepositorios crea los repositorios adecuados para el dialecto de la base de datos,
+// limitados a la facultad por defecto y a su periodo actual
+func NewRepositorios(db *sql.DB, dialecto Dialecto) *Repositorios {
+	acceso := Acceso{Facultad: FacultadPorDefecto, Periodo: periodoPorDefecto(db, dialecto)}
+	return newRepositoriosSQL(db, dialecto, contextoPorDefecto, acceso, nil)
+}
+
+// NewRepositoriosConAcceso crea los repositorios limitados a la facultad indicada sobre
+// una base sin cifrar
+func NewRepositoriosConAcceso(db *sql.DB, dialecto Dialecto, acceso Acceso) (*Repositorios, error) {
+	return NewRepositoriosCifrados(db, dialecto, acceso, nil)
+}
+
+// NewRepositoriosCifrados crea los repositorios limitados a la facultad y al periodo indicados
+// que cifran los datos personales con cifrador. Retorna ErrClaveRequerida, ErrClaveIncorrecta
+// o ErrBaseSinCifrar si la clave no corresponde al estado de la base, y ErrNoEncontrado si
+// el periodo indicado no existe.
+func NewRepositoriosCifrados(db *sql.DB, dialecto Dialecto, acceso Acceso, cifrador *Cifrador) (*Repositorios, error) {
+	acceso, err := acceso.completo()
 	if err != nil {
 		return nil, err
 	}
+	if err := prepararCifrado(db, dialecto, cifrador); err != nil {
+		return nil, err
+	}
+	if acceso.Periodo, err = periodoVigente(db, dialecto, acceso.Facultad, acceso.Periodo); err != nil {
+		return nil, err
+	}
+	return newRepositoriosSQL(db, dialecto, contextoPorDefecto, acceso, cifrador), nil
+}
+
+// newRepositoriosSQL crea los repositorios sobre la conexión o sobre una transacción en
+// curso; el periodo del acceso ya debe existir
+func newRepositoriosSQL(db ejecutor, dialecto Dialecto, auditoria ContextoAuditoria, acceso Acceso, cifrador *Cifrador) *Repositorios {
+	facultad, periodo := acceso.Facultad, acceso.Periodo
+	repos := &Repositorios{
+		Estudiantes:    &estudianteRepo{db: db, dialecto: dialecto, facultad: facultad, cifrador: cifrador, auditoria: auditoria},
+		Materias:       &materiaRepo{db: db, dialecto: dialecto, facultad: facultad, cifrador: cifrador, auditoria: auditoria},
+		Inscripciones:  &inscripcionRepo{db: db, dialecto: dialecto, facultad: facultad, periodo: periodo, cifrador: cifrador, auditoria: auditoria},
+		Auditoria:      &auditoriaRepo{db: db, dialecto: dialecto, facultad: facultad, cifrador: cifrador},
+		Eventos:        &eventoRepo{db: db, dialecto: dialecto, facultad: facultad, cifrador: cifrador},
+		Periodos:       &periodoRepo{db: db, dialecto: dialecto, facultad: facultad},
+		Prerrequisitos: &prerrequisitoRepo{db: db, dialecto: dialecto, facultad: facultad},
+		ListaEspera:    &listaEsperaRepo{db: db, dialecto: dialecto, facultad: facultad, periodo: periodo, cifrador: cifrador},
+		Grupos:         &grupoRepo{db: db, dialecto: dialecto, facultad: facultad},
+		Horarios:       &horarioRepo{db: db, dialecto: dialecto, facultad: facultad},
+		Componentes:    &componenteRepo{db: db, dialecto: dialecto, facultad: facultad},
+		Calificaciones: &calificacionRepo{db: db, dialecto: dialecto, facultad: facultad, periodo: periodo, cifrador: cifrador, auditoria: auditoria},
+		Carreras:       &carreraRepo{db: db, dialecto: dialecto, facultad: facultad, cifrador: cifrador, auditoria: auditoria},
+		Docentes:       &docenteRepo{db: db, dialecto: dialecto, facultad: facultad, periodo: periodo, cifrador: cifrador},
+		backend:        dialecto.String(),
+		acceso:         acceso,
+		cifrador:       cifrador,
+		conAuditoria: func(c ContextoAuditoria) *Repositorios {
+			return newRepositoriosSQL(db, dialecto, c, acceso, cifrador)
+		},
+		cargar: func(v *volcado) error {
+			return cargarSQL(db, dialecto, facultad, cifrador, auditoria, v)
+		},
+		enTransaccion: func(fn func(*Repositorios) error) error {
+			return enTransaccionSQL(db, dialecto, auditoria, acceso, cifrador, fn)
+		},
+		conFacultad: func(f string) (*Repositorios, error) {
+			actual, err := periodoVigente(db, dialecto, f, "")
+			if err != nil {
+				return nil, err
+			}
+			return newRepositoriosSQL(db, dialecto, auditoria, Acceso{Facultad: f, Periodo: actual, Administrador: acceso.Administrador}, cifrador), nil
+		},
+		enPeriodo: func(p string) (*Repositorios, error) {
+			p, err := periodoVigente(db, dialecto, facultad, p)
+			if err != nil {
+				return nil, err
+			}
+			otro := acceso
+			otro.Periodo = p
+			return newRepositoriosSQL(db, dialecto, auditoria, otro, cifrador), nil
+		},
+		facultades: func() ([]string, error) {
+			return facultadesSQL(db)
+		},
+	}
+	// Solo los repositorios sobre la conexión la cierran; los de una transacción no
+	if conexion, ok := db.(*sql.DB); ok {
+		repos.db = conexion
+	}
+	return repos
+}
+
+// ConAuditoria retorna repositorios sobre los mismos datos cuyos cambios quedan
+// registrados a nombre del actor y origen indicados
+func (r *Repositorios) ConAuditoria(contexto ContextoAuditoria) *Repositorios {
+	return r.conAuditoria(contexto.completo())
+}
 
-	// Crear tablas si no existen
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS estudiantes (
-            cedula TEXT PRIMARY KEY,
-            nombre TEXT NOT NULL
-        )`,
-		`CREATE TABLE IF NOT EXISTS materias (
-            codigo TEXT PRIMARY KEY,
-            nombre TEXT NOT NULL
-        )`,
-		`CREATE TABLE IF NOT EXISTS inscripciones (
-            estudiante_cedula TEXT,
-            materia_codigo TEXT,
-            FOREIGN KEY(estudiante_cedula) REFERENCES estudiantes(cedula),
-            FOREIGN KEY(materia_codigo) REFERENCES materias(codigo),
-            PRIMARY KEY(estudiante_cedula, materia_codigo)
-        )`,
-	}
-
-	for _, query := range queries {
-		_, err = db.Exec(query)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	return db, nil
+// Cifrado indica si los datos personales se guardan cifrados
+func (r *Repositorios) Cifrado() bool {
+	return r.cifrador.activo()
+}
+
+// Backend retorna el nombre del backend que respalda a los repositorios
+func (r *Repositorios) Backend() string {
+	return r.backend
+}
+
+// Close libera la conexión a la base de datos, si la hay
+func (r *Repositorios) Close() error {
+	if r.db == nil {
+		return nil
+	}
+	return r.db.Close()
 }
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialecto identifica las particularidades SQL de cada backend
+type Dialecto int
+
+const (
+	DialectoSQLite Dialecto = iota
+	DialectoPostgres
+)
+
+func (d Dialecto) String() string {
+	switch d {
+	case DialectoSQLite:
+		return DriverSQLite
+	case DialectoPostgres:
+		return DriverPostgres
+	default:
+		return "desconocido"
+	}
+}
+
+// like retorna el operador LIKE que no distingue mayúsculas en el dialecto
+func (d Dialecto) like() string {
+	if d == DialectoPostgres {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
+
+// rebind adapta los marcadores '?' de una consulta al estilo del dialecto ($1, $2, ... en PostgreSQL)
+func (d Dialecto) rebind(query string) string {
+	if d != DialectoPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	enLiteral := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			enLiteral = !enLiteral
+			b.WriteRune(r)
+		case r == '?' && !enLiteral:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
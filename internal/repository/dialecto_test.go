@@ -0,0 +1,22 @@
+package repository
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	casos := []struct {
+		dialecto Dialecto
+		entrada  string
+		esperado string
+	}{
+		{DialectoSQLite, "SELECT 1 FROM t WHERE a = ? AND b = ?", "SELECT 1 FROM t WHERE a = ? AND b = ?"},
+		{DialectoPostgres, "SELECT 1 FROM t WHERE a = ? AND b = ?", "SELECT 1 FROM t WHERE a = $1 AND b = $2"},
+		{DialectoPostgres, "SELECT '?' FROM t WHERE a = ?", "SELECT '?' FROM t WHERE a = $1"},
+		{DialectoPostgres, "SELECT codigo FROM materias", "SELECT codigo FROM materias"},
+	}
+
+	for _, c := range casos {
+		if obtenido := c.dialecto.rebind(c.entrada); obtenido != c.esperado {
+			t.Errorf("%s.rebind(%q) = %q, se esperaba %q", c.dialecto, c.entrada, obtenido, c.esperado)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"inscripciones/internal/domain"
+)
+
+// ErrDocenteConAsignaciones impide eliminar un docente que dicta alguna materia
+var ErrDocenteConAsignaciones = errors.New("el docente tiene materias asignadas")
+
+// DocenteRepository administra los docentes de la facultad, comunes a todos los periodos,
+// y las materias y grupos que dicta cada uno en el periodo de los repositorios. Ni los
+// docentes ni sus asignaciones se auditan.
+type DocenteRepository interface {
+	// Create agrega el docente; retorna ErrDuplicado si la cédula ya existe
+	Create(d *domain.Docente) error
+	// Update cambia el nombre del docente; retorna ErrNoEncontrado si no existe
+	Update(d *domain.Docente) error
+	// Delete quita el docente; retorna ErrNoEncontrado si no existe y
+	// ErrDocenteConAsignaciones si dicta alguna materia en cualquier periodo
+	Delete(cedula string) error
+	// GetByCedula retorna el docente; nil si no existe
+	GetByCedula(cedula string) (*domain.Docente, error)
+	// GetAll retorna los docentes ordenados por cédula
+	GetAll() ([]*domain.Docente, error)
+
+	// Asignar pone al docente a dictar la materia, o uno de sus grupos con un grupo
+	// distinto de domain.SinGrupo, en lugar del que la dictaba. Retorna
+	// ErrReferenciaInvalida si el docente no existe, si la materia no existe o está
+	// eliminada, o si no tiene ese grupo.
+	Asignar(docenteCedula, materiaCodigo string, grupo int) error
+	// Desasignar deja la materia o el grupo sin docente; retorna ErrNoEncontrado si no tenía
+	Desasignar(materiaCodigo string, grupo int) error
+	// GetByDocente retorna lo que dicta el docente en el periodo, sin las materias
+	// eliminadas, ordenado por materia y grupo
+	GetByDocente(docenteCedula string) ([]domain.AsignacionDocente, error)
+	// GetAsignaciones retorna todas las asignaciones del periodo, también las de materias
+	// eliminadas, ordenadas por materia y grupo
+	GetAsignaciones() ([]domain.AsignacionDocente, error)
+	// GetEstudiantes retorna, ordenados por cédula y sin repetir, los inscritos vigentes
+	// del periodo en las materias y grupos que dicta el docente
+	GetEstudiantes(docenteCedula string) ([]*domain.Estudiante, error)
+}
+
+type docenteRepo struct {
+	db       ejecutor
+	dialecto Dialecto
+	facultad string
+	periodo  string
+	cifrador *Cifrador
+}
+
+func (r *docenteRepo) Create(d *domain.Docente) error {
+	_, err := r.db.Exec(r.dialecto.rebind("INSERT INTO docentes (facultad, cedula, nombre) VALUES (?, ?, ?)"),
+		r.facultad, r.cifrador.cifrarClave(d.Cedula), r.cifrador.cifrarDato(d.Nombre))
+	if err != nil {
+		return fmt.Errorf("error al crear el docente %s: %w", d.Cedula, traducirError(err))
+	}
+	return nil
+}
+
+func (r *docenteRepo) Update(d *domain.Docente) error {
+	resultado, err := r.db.Exec(r.dialecto.rebind("UPDATE docentes SET nombre = ? WHERE facultad = ? AND cedula = ?"),
+		r.cifrador.cifrarDato(d.Nombre), r.facultad, r.cifrador.cifrarClave(d.Cedula))
+	if err != nil {
+		return fmt.Errorf("error al actualizar el docente %s: %w", d.Cedula, err)
+	}
+	filas, err := resultado.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al actualizar el docente %s: %w", d.Cedula, err)
+	}
+	if filas == 0 {
+		return fmt.Errorf("error al actualizar el docente %s: %w", d.Cedula, ErrNoEncontrado)
+	}
+	return nil
+}
+
+func (r *docenteRepo) Delete(cedula string) error {
+	cifrada := r.cifrador.cifrarClave(cedula)
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var conAsignaciones bool
+		err := tx.QueryRow(r.dialecto.rebind("SELECT EXISTS(SELECT 1 FROM asignaciones_docentes WHERE facultad = ? AND docente_cedula = ?)"),
+			r.facultad, cifrada).Scan(&conAsignaciones)
+		if err != nil {
+			return err
+		}
+		if conAsignaciones {
+			return ErrDocenteConAsignaciones
+		}
+
+		resultado, err := tx.Exec(r.dialecto.rebind("DELETE FROM docentes WHERE facultad = ? AND cedula = ?"), r.facultad, cifrada)
+		if err != nil {
+			return err
+		}
+		filas, err := resultado.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if filas == 0 {
+			return ErrNoEncontrado
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error al eliminar el docente %s: %w", cedula, err)
+	}
+	return nil
+}
+
+func (r *docenteRepo) GetByCedula(cedula string) (*domain.Docente, error) {
+	var d domain.Docente
+	err := r.db.QueryRow(r.dialecto.rebind("SELECT cedula, nombre FROM docentes WHERE facultad = ? AND cedula = ?"),
+		r.facultad, r.cifrador.cifrarClave(cedula)).Scan(&d.Cedula, &d.Nombre)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.cifrador.descifrar(&d.Cedula, &d.Nombre); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *docenteRepo) GetAll() ([]*domain.Docente, error) {
+	rows, err := r.db.Query(r.dialecto.rebind("SELECT cedula, nombre FROM docentes WHERE facultad = ?"), r.facultad)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docentes []*domain.Docente
+	for rows.Next() {
+		var d domain.Docente
+		if err := rows.Scan(&d.Cedula, &d.Nombre); err != nil {
+			return nil, err
+		}
+		if err := r.cifrador.descifrar(&d.Cedula, &d.Nombre); err != nil {
+			return nil, err
+		}
+		docentes = append(docentes, &d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// El orden se hace después de descifrar las cédulas
+	sort.Slice(docentes, func(i, j int) bool { return docentes[i].Cedula < docentes[j].Cedula })
+	return docentes, nil
+}
+
+func (r *docenteRepo) Asignar(docenteCedula, materiaCodigo string, grupo int) error {
+	cedula := r.cifrador.cifrarClave(docenteCedula)
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var docente, activa bool
+		err := tx.QueryRow(r.dialecto.rebind(`
+			SELECT EXISTS(SELECT 1 FROM docentes WHERE facultad = ? AND cedula = ?),
+				EXISTS(SELECT 1 FROM materias WHERE facultad = ? AND codigo = ? AND deleted_at IS NULL)
+		`), r.facultad, cedula, r.facultad, materiaCodigo).Scan(&docente, &activa)
+		if err != nil {
+			return err
+		}
+		if !docente {
+			return fmt.Errorf("%w: docente %s", ErrReferenciaInvalida, docenteCedula)
+		}
+		if !activa {
+			return fmt.Errorf("%w: materia %s", ErrReferenciaInvalida, materiaCodigo)
+		}
+		existe, err := existeGrupo(tx, r.dialecto, r.facultad, materiaCodigo, grupo)
+		if err != nil {
+			return err
+		}
+		if !existe {
+			return fmt.Errorf("%w: la materia %s no tiene grupo %d", ErrReferenciaInvalida, materiaCodigo, grupo)
+		}
+
+		resultado, err := tx.Exec(r.dialecto.rebind("UPDATE asignaciones_docentes SET docente_cedula = ? WHERE facultad = ? AND periodo = ? AND materia_codigo = ? AND grupo = ?"),
+			cedula, r.facultad, r.periodo, materiaCodigo, grupo)
+		if err != nil {
+			return err
+		}
+		if filas, err := resultado.RowsAffected(); err != nil || filas > 0 {
+			return err
+		}
+		_, err = tx.Exec(r.dialecto.rebind("INSERT INTO asignaciones_docentes (facultad, periodo, materia_codigo, grupo, docente_cedula) VALUES (?, ?, ?, ?, ?)"),
+			r.facultad, r.periodo, materiaCodigo, grupo, cedula)
+		return traducirError(err)
+	})
+	if err != nil {
+		return fmt.Errorf("error al asignar al docente %s a %s: %w", docenteCedula, materiaCodigo, err)
+	}
+	return nil
+}
+
+func (r *docenteRepo) Desasignar(materiaCodigo string, grupo int) error {
+	resultado, err := r.db.Exec(r.dialecto.rebind("DELETE FROM asignaciones_docentes WHERE facultad = ? AND periodo = ? AND materia_codigo = ? AND grupo = ?"),
+		r.facultad, r.periodo, materiaCodigo, grupo)
+	if err != nil {
+		return fmt.Errorf("error al quitar el docente de %s: %w", materiaCodigo, err)
+	}
+	filas, err := resultado.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error al quitar el docente de %s: %w", materiaCodigo, err)
+	}
+	if filas == 0 {
+		return fmt.Errorf("error al quitar el docente de %s: %w", materiaCodigo, ErrNoEncontrado)
+	}
+	return nil
+}
+
+func (r *docenteRepo) GetByDocente(docenteCedula string) ([]domain.AsignacionDocente, error) {
+	return r.consultarAsignaciones(`
+		SELECT a.docente_cedula, a.materia_codigo, a.periodo, a.grupo
+		FROM asignaciones_docentes a
+		JOIN materias m ON m.facultad = a.facultad AND m.codigo = a.materia_codigo
+		WHERE a.facultad = ? AND a.periodo = ? AND a.docente_cedula = ? AND m.deleted_at IS NULL
+		ORDER BY a.materia_codigo, a.grupo
+	`, r.facultad, r.periodo, r.cifrador.cifrarClave(docenteCedula))
+}
+
+func (r *docenteRepo) GetAsignaciones() ([]domain.AsignacionDocente, error) {
+	return r.consultarAsignaciones("SELECT docente_cedula, materia_codigo, periodo, grupo FROM asignaciones_docentes WHERE facultad = ? AND periodo = ? ORDER BY materia_codigo, grupo",
+		r.facultad, r.periodo)
+}
+
+func (r *docenteRepo) consultarAsignaciones(consulta string, args ...any) ([]domain.AsignacionDocente, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(consulta), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var asignaciones []domain.AsignacionDocente
+	for rows.Next() {
+		var a domain.AsignacionDocente
+		if err := rows.Scan(&a.Docente, &a.Materia, &a.Periodo, &a.Grupo); err != nil {
+			return nil, err
+		}
+		if err := r.cifrador.descifrar(&a.Docente); err != nil {
+			return nil, err
+		}
+		asignaciones = append(asignaciones, a)
+	}
+	return asignaciones, rows.Err()
+}
+
+func (r *docenteRepo) GetEstudiantes(docenteCedula string) ([]*domain.Estudiante, error) {
+	// Un docente de la materia completa tiene a los inscritos de todos sus grupos
+	rows, err := r.db.Query(r.dialecto.rebind(`
+		SELECT DISTINCT e.cedula, e.nombre
+		`+joinInscripciones+`
+		JOIN asignaciones_docentes a ON a.facultad = i.facultad AND a.periodo = i.periodo AND a.materia_codigo = i.materia_codigo
+			AND (a.grupo = ? OR a.grupo = i.grupo)
+		WHERE i.facultad = ? AND i.periodo = ? AND a.docente_cedula = ? AND `+inscripcionVigente+`
+	`), domain.SinGrupo, r.facultad, r.periodo, r.cifrador.cifrarClave(docenteCedula))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var estudiantes []*domain.Estudiante
+	for rows.Next() {
+		var e domain.Estudiante
+		if err := rows.Scan(&e.Cedula, &e.Nombre); err != nil {
+			return nil, err
+		}
+		if err := r.cifrador.descifrar(&e.Cedula, &e.Nombre); err != nil {
+			return nil, err
+		}
+		estudiantes = append(estudiantes, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	ordenarPorCedula(estudiantes)
+	return estudiantes, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// FiltroEliminados indica si un listado muestra los registros eliminados lógicamente
+type FiltroEliminados int
+
+const (
+	// OcultarEliminados es el comportamiento por defecto de todas las consultas
+	OcultarEliminados FiltroEliminados = iota
+	IncluirEliminados
+	SoloEliminados
+)
+
+// ahora retorna la fecha actual en el formato con que se guardan las fechas
+func ahora() (time.Time, string) {
+	t := time.Now().UTC()
+	return t, t.Format(time.RFC3339Nano)
+}
+
+// fechaEliminacion interpreta la columna deleted_at
+func fechaEliminacion(valor sql.NullString) (*time.Time, error) {
+	if !valor.Valid {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, valor.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// estaEliminado indica si la clave existe en la tabla pero está eliminada lógicamente
+func estaEliminado(db ejecutor, d Dialecto, tabla, condicion string, args ...any) (bool, error) {
+	var eliminado bool
+	err := db.QueryRow(
+		d.rebind("SELECT EXISTS(SELECT 1 FROM "+tabla+" WHERE "+condicion+" AND deleted_at IS NOT NULL)"),
+		args...,
+	).Scan(&eliminado)
+	return eliminado, err
+}
+
+// marcarEliminado fija o limpia deleted_at; retorna false si no había un registro en el estado esperado
+func marcarEliminado(tx ejecutor, d Dialecto, tabla, condicion string, eliminar bool, args ...any) (bool, error) {
+	var query string
+	var valores []any
+	if eliminar {
+		_, fecha := ahora()
+		query = "UPDATE " + tabla + " SET deleted_at = ? WHERE " + condicion + " AND deleted_at IS NULL"
+		valores = append([]any{fecha}, args...)
+	} else {
+		query = "UPDATE " + tabla + " SET deleted_at = NULL WHERE " + condicion + " AND deleted_at IS NOT NULL"
+		valores = args
+	}
+
+	resultado, err := tx.Exec(d.rebind(query), valores...)
+	if err != nil {
+		return false, err
+	}
+	filas, err := resultado.RowsAffected()
+	return filas > 0, err
+}
+
+// condicionEliminados arma el filtro de un listado sobre las columnas deleted_at involucradas
+func condicionEliminados(filtro FiltroEliminados, columnas []string) string {
+	var partes []string
+	switch filtro {
+	case IncluirEliminados:
+		return ""
+	case SoloEliminados:
+		for _, c := range columnas {
+			partes = append(partes, c+" IS NOT NULL")
+		}
+		return "(" + strings.Join(partes, " OR ") + ")"
+	default:
+		for _, c := range columnas {
+			partes = append(partes, c+" IS NULL")
+		}
+		return strings.Join(partes, " AND ")
+	}
+}
+
+// incluye aplica en memoria la semántica del filtro de eliminados
+func (f FiltroEliminados) incluye(eliminado bool) bool {
+	switch f {
+	case IncluirEliminados:
+		return true
+	case SoloEliminados:
+		return eliminado
+	default:
+		return !eliminado
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	sqlite "github.com/glebarez/go-sqlite"
+	"github.com/lib/pq"
+)
+
+// Errores comunes a todas las implementaciones de los repositorios
+var (
+	ErrDuplicado          = errors.New("el registro ya existe")
+	ErrReferenciaInvalida = errors.New("el estudiante o la materia referenciados no existen")
+	ErrNoEncontrado       = errors.New("el registro no existe")
+	ErrEliminado          = errors.New("el registro está eliminado; debe restaurarse antes de usarlo")
+	ErrRespaldoInvalido   = errors.New("el respaldo no es válido")
+	ErrEsquemaDistinto    = errors.New("el esquema de la base no coincide con el de esta aplicación")
+	ErrConflicto          = errors.New("otro usuario modificó el registro; recárguelo y vuelva a intentar")
+	ErrNoAutorizado       = errors.New("la operación solo está disponible en modo administrativo")
+)
+
+// Códigos de error de SQLite (extendidos) y PostgreSQL para violaciones de restricciones
+const (
+	sqliteConstraintForeignKey = 787
+	sqliteConstraintPrimaryKey = 1555
+	sqliteConstraintUnique     = 2067
+
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// traducirError convierte los errores de restricción del driver en los errores del paquete
+func traducirError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqliteConstraintPrimaryKey, sqliteConstraintUnique:
+			return fmt.Errorf("%w: %v", ErrDuplicado, err)
+		case sqliteConstraintForeignKey:
+			return fmt.Errorf("%w: %v", ErrReferenciaInvalida, err)
+		}
+	}
+
+	var pgErr *pq.Error
+	if errors.As(err, &pgErr) {
+		switch string(pgErr.Code) {
+		case pgUniqueViolation:
+			return fmt.Errorf("%w: %v", ErrDuplicado, err)
+		case pgForeignKeyViolation:
+			return fmt.Errorf("%w: %v", ErrReferenciaInvalida, err)
+		}
+	}
+
+	return err
+}
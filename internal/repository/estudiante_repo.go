@@ -2,7 +2,10 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"inscripciones/internal/domain"
+	"sort"
 )
 
 type EstudianteRepository interface {
@@ -10,30 +13,65 @@ type EstudianteRepository interface {
 	GetByCedula(cedula string) (*domain.Estudiante, error)
 	GetAll() ([]*domain.Estudiante, error)  // Agregar este método a la interfaz
 	Exists(cedula string) (bool, error)
+	List(consulta Consulta) (*Pagina[*domain.Estudiante], error)
+	Search(texto string, limite int) ([]*domain.Estudiante, error)
+	Delete(cedula string) error
+	Restore(cedula string) error
+	// Update guarda el nombre si la versión coincide con la almacenada e incrementa la
+	// versión; si otro usuario lo modificó antes, retorna ErrConflicto sin cambiar nada
+	Update(estudiante *domain.Estudiante) error
 }
 
 type estudianteRepo struct {
-	db *sql.DB
+	db        ejecutor
+	dialecto  Dialecto
+	facultad  string
+	cifrador  *Cifrador
+	auditoria ContextoAuditoria
 }
 
 func NewEstudianteRepository(db *sql.DB) EstudianteRepository {
-	return &estudianteRepo{db: db}
+	return &estudianteRepo{db: db, dialecto: DialectoSQLite, facultad: FacultadPorDefecto, auditoria: contextoPorDefecto}
 }
 
 func (r *estudianteRepo) Create(estudiante *domain.Estudiante) error {
-	_, err := r.db.Exec(
-		"INSERT INTO estudiantes (cedula, nombre) VALUES (?, ?)",
-		estudiante.Cedula,
-		estudiante.Nombre,
-	)
-	return err
+	err := transaccion(r.db, func(tx ejecutor) error {
+		_, err := tx.Exec(
+			r.dialecto.rebind("INSERT INTO estudiantes (facultad, cedula, nombre) VALUES (?, ?, ?)"),
+			r.facultad,
+			r.cifrador.cifrarClave(estudiante.Cedula),
+			r.cifrador.cifrarDato(estudiante.Nombre),
+		)
+		if err != nil {
+			return traducirError(err)
+		}
+
+		// Mantener sincronizado el índice de búsqueda por nombre; con los nombres cifrados no hay índice
+		if !r.cifrador.activo() {
+			if err := indiceEstudiantes.indexar(tx, r.dialecto, r.facultad, estudiante.Cedula, estudiante.Nombre); err != nil {
+				return err
+			}
+		}
+
+		cambio := cambioEstudiante(domain.OperacionCrear, nil, estudiante)
+		if err := registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return r.errorAlCrear(estudiante.Cedula, err)
+	}
+	estudiante.Version = 1
+	return nil
 }
 
 func (r *estudianteRepo) GetByCedula(cedula string) (*domain.Estudiante, error) {
-	row := r.db.QueryRow("SELECT cedula, nombre FROM estudiantes WHERE cedula = ?", cedula)
+	row := r.db.QueryRow(r.dialecto.rebind("SELECT cedula, nombre, version FROM estudiantes WHERE facultad = ? AND cedula = ? AND deleted_at IS NULL"), r.facultad, r.cifrador.cifrarClave(cedula))
 
 	var e domain.Estudiante
-	err := row.Scan(&e.Cedula, &e.Nombre)
+	err := row.Scan(&e.Cedula, &e.Nombre, &e.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -41,20 +79,24 @@ func (r *estudianteRepo) GetByCedula(cedula string) (*domain.Estudiante, error)
 		return nil, err
 	}
 
+	if err := r.cifrador.descifrar(&e.Cedula, &e.Nombre); err != nil {
+		return nil, err
+	}
 	return &e, nil
 }
 
 func (r *estudianteRepo) Exists(cedula string) (bool, error) {
 	var exists bool
 	err := r.db.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM estudiantes WHERE cedula = ?)",
-		cedula,
+		r.dialecto.rebind("SELECT EXISTS(SELECT 1 FROM estudiantes WHERE facultad = ? AND cedula = ? AND deleted_at IS NULL)"),
+		r.facultad,
+		r.cifrador.cifrarClave(cedula),
 	).Scan(&exists)
 	return exists, err
 }
 
 func (r *estudianteRepo) GetAll() ([]*domain.Estudiante, error) {
-	rows, err := r.db.Query("SELECT cedula, nombre FROM estudiantes")
+	rows, err := r.db.Query(r.dialecto.rebind("SELECT cedula, nombre, version FROM estudiantes WHERE facultad = ? AND deleted_at IS NULL ORDER BY cedula"), r.facultad)
 	if err != nil {
 		return nil, err
 	}
@@ -63,10 +105,259 @@ func (r *estudianteRepo) GetAll() ([]*domain.Estudiante, error) {
 	var estudiantes []*domain.Estudiante
 	for rows.Next() {
 		var e domain.Estudiante
-		if err := rows.Scan(&e.Cedula, &e.Nombre); err != nil {
+		if err := rows.Scan(&e.Cedula, &e.Nombre, &e.Version); err != nil {
+			return nil, err
+		}
+		if err := r.cifrador.descifrar(&e.Cedula, &e.Nombre); err != nil {
 			return nil, err
 		}
 		estudiantes = append(estudiantes, &e)
 	}
+	if r.cifrador.activo() {
+		ordenarPorCedula(estudiantes)
+	}
 	return estudiantes, nil
+}
+
+// ordenarPorCedula restablece el orden por cédula que SQL no puede dar sobre las cédulas cifradas
+func ordenarPorCedula(estudiantes []*domain.Estudiante) {
+	sort.Slice(estudiantes, func(i, j int) bool { return estudiantes[i].Cedula < estudiantes[j].Cedula })
+}
+
+// List retorna una página de estudiantes filtrada por nombre y prefijo de cédula
+func (r *estudianteRepo) List(consulta Consulta) (*Pagina[*domain.Estudiante], error) {
+	columnas, err := columnasOrdenEstudiantes(consulta.Orden, "")
+	if err != nil {
+		return nil, err
+	}
+
+	listado := &listadoSQL[*domain.Estudiante]{
+		db:                r.db,
+		dialecto:          r.dialecto,
+		seleccion:         "SELECT cedula, nombre, deleted_at, version FROM estudiantes",
+		condiciones:       []string{"facultad = ?"},
+		args:              []any{r.facultad},
+		columnaNombre:     "nombre",
+		columnaCodigo:     "cedula",
+		columnasEliminado: []string{"deleted_at"},
+		columnasOrden:     columnas,
+		escanear:          escanearEstudiante(consulta.Orden, r.cifrador),
+	}
+	if r.cifrador.activo() {
+		listado.filtrarEnMemoria = nombreYCedula
+	}
+	return listado.ejecutar(consulta)
+}
+
+// columnasOrdenEstudiantes retorna las columnas de orden; la cédula siempre desempata
+func columnasOrdenEstudiantes(orden CampoOrden, alias string) ([]string, error) {
+	switch orden {
+	case OrdenPorClave:
+		return []string{alias + "cedula"}, nil
+	case OrdenPorNombre:
+		return []string{alias + "nombre", alias + "cedula"}, nil
+	default:
+		return nil, fmt.Errorf("%w para estudiantes: %q", ErrOrdenInvalido, orden)
+	}
+}
+
+func clavesEstudiante(orden CampoOrden) func(*domain.Estudiante) []string {
+	if orden == OrdenPorNombre {
+		return func(e *domain.Estudiante) []string { return []string{e.Nombre, e.Cedula} }
+	}
+	return func(e *domain.Estudiante) []string { return []string{e.Cedula} }
+}
+
+// nombreYCedula son los valores a los que se aplican los filtros de un listado de estudiantes
+func nombreYCedula(e *domain.Estudiante) (string, string) {
+	return e.Nombre, e.Cedula
+}
+
+func escanearEstudiante(orden CampoOrden, cifrador *Cifrador) func(*sql.Rows) (*domain.Estudiante, []string, error) {
+	claves := clavesEstudiante(orden)
+	return func(rows *sql.Rows) (*domain.Estudiante, []string, error) {
+		var e domain.Estudiante
+		var eliminado sql.NullString
+		if err := rows.Scan(&e.Cedula, &e.Nombre, &eliminado, &e.Version); err != nil {
+			return nil, nil, err
+		}
+		if err := cifrador.descifrar(&e.Cedula, &e.Nombre); err != nil {
+			return nil, nil, err
+		}
+		fecha, err := fechaEliminacion(eliminado)
+		if err != nil {
+			return nil, nil, err
+		}
+		e.EliminadoEn = fecha
+		return &e, claves(&e), nil
+	}
+}
+
+// Search busca por nombre parcial sin distinguir tildes ni mayúsculas, ordenando por relevancia
+func (r *estudianteRepo) Search(texto string, limite int) ([]*domain.Estudiante, error) {
+	buscar := indiceEstudiantes.buscar
+	if r.cifrador.activo() {
+		buscar = r.buscarDescifrando
+	}
+	resultados, err := buscar(r.db, r.dialecto, r.facultad, texto, limite)
+	if err != nil {
+		return nil, err
+	}
+
+	var encontrados []*domain.Estudiante
+	for _, res := range resultados {
+		encontrados = append(encontrados, &domain.Estudiante{Cedula: res.clave, Nombre: res.nombre, Version: res.version})
+	}
+	return encontrados, nil
+}
+
+// buscarDescifrando puntúa, como el índice de PostgreSQL, todos los estudiantes vigentes
+// de la facultad: con los nombres cifrados no hay índice de búsqueda
+func (r *estudianteRepo) buscarDescifrando(db ejecutor, d Dialecto, facultad, texto string, limite int) ([]resultadoBusqueda, error) {
+	terminos := palabras(texto)
+	if len(terminos) == 0 {
+		return nil, nil
+	}
+
+	rows, err := db.Query(d.rebind("SELECT cedula, nombre, version FROM estudiantes WHERE facultad = ? AND deleted_at IS NULL"), facultad)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resultados []resultadoBusqueda
+	for rows.Next() {
+		var res resultadoBusqueda
+		if err := rows.Scan(&res.clave, &res.nombre, &res.version); err != nil {
+			return nil, err
+		}
+		if err := r.cifrador.descifrar(&res.clave, &res.nombre); err != nil {
+			return nil, err
+		}
+		if puntaje, ok := puntuar(res.nombre, terminos); ok {
+			res.puntaje = puntaje
+			resultados = append(resultados, res)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ordenarResultados(resultados, limiteBusqueda(limite)), nil
+}
+
+// Delete elimina lógicamente el estudiante; sigue existiendo, pero las consultas lo ocultan
+func (r *estudianteRepo) Delete(cedula string) error {
+	return r.cambiarEliminado(cedula, true)
+}
+
+// Restore deshace la eliminación lógica del estudiante
+func (r *estudianteRepo) Restore(cedula string) error {
+	return r.cambiarEliminado(cedula, false)
+}
+
+func (r *estudianteRepo) cambiarEliminado(cedula string, eliminar bool) error {
+	return transaccion(r.db, func(tx ejecutor) error {
+		var nombre string
+		cifrada := r.cifrador.cifrarClave(cedula)
+		err := tx.QueryRow(r.dialecto.rebind("SELECT nombre FROM estudiantes WHERE facultad = ? AND cedula = ?"), r.facultad, cifrada).Scan(&nombre)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: estudiante %s", ErrNoEncontrado, cedula)
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.cifrador.descifrar(&nombre); err != nil {
+			return err
+		}
+
+		ok, err := marcarEliminado(tx, r.dialecto, "estudiantes", "facultad = ? AND cedula = ?", eliminar, r.facultad, cifrada)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: no hay estudiante %s en ese estado", ErrNoEncontrado, cedula)
+		}
+
+		e := &domain.Estudiante{Cedula: cedula, Nombre: nombre}
+		cambio := cambioEstudiante(domain.OperacionEliminar, e, nil)
+		if !eliminar {
+			cambio = cambioEstudiante(domain.OperacionRestaurar, nil, e)
+		}
+		if err := registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Update cambia el nombre del estudiante solo si nadie lo modificó desde que se leyó la versión
+func (r *estudianteRepo) Update(estudiante *domain.Estudiante) error {
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var antes domain.Estudiante
+		cifrada := r.cifrador.cifrarClave(estudiante.Cedula)
+		err := tx.QueryRow(
+			r.dialecto.rebind("SELECT cedula, nombre, version FROM estudiantes WHERE facultad = ? AND cedula = ? AND deleted_at IS NULL"),
+			r.facultad,
+			cifrada,
+		).Scan(&antes.Cedula, &antes.Nombre, &antes.Version)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: estudiante %s", ErrNoEncontrado, estudiante.Cedula)
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.cifrador.descifrar(&antes.Cedula, &antes.Nombre); err != nil {
+			return err
+		}
+
+		// La condición sobre la versión hace atómica la comprobación: si otra transacción
+		// actualizó la fila después de la lectura anterior, no se modifica ninguna
+		resultado, err := tx.Exec(
+			r.dialecto.rebind("UPDATE estudiantes SET nombre = ?, version = version + 1 WHERE facultad = ? AND cedula = ? AND version = ? AND deleted_at IS NULL"),
+			r.cifrador.cifrarDato(estudiante.Nombre),
+			r.facultad,
+			cifrada,
+			estudiante.Version,
+		)
+		if err != nil {
+			return err
+		}
+		filas, err := resultado.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if filas == 0 {
+			return fmt.Errorf("%w: estudiante %s (versión %d, se esperaba %d)", ErrConflicto, estudiante.Cedula, antes.Version, estudiante.Version)
+		}
+
+		if !r.cifrador.activo() {
+			if err := indiceEstudiantes.reindexar(tx, r.dialecto, r.facultad, estudiante.Cedula, estudiante.Nombre); err != nil {
+				return err
+			}
+		}
+
+		cambio := cambioEstudiante(domain.OperacionActualizar, &antes, estudiante)
+		if err := registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	estudiante.Version++
+	return nil
+}
+
+// errorAlCrear distingue un alta repetida de una sobre un registro eliminado lógicamente
+func (r *estudianteRepo) errorAlCrear(cedula string, err error) error {
+	if !errors.Is(err, ErrDuplicado) {
+		return err
+	}
+	if eliminado, errConsulta := estaEliminado(r.db, r.dialecto, "estudiantes", "facultad = ? AND cedula = ?", r.facultad, r.cifrador.cifrarClave(cedula)); errConsulta == nil && eliminado {
+		return fmt.Errorf("%w: estudiante %s", ErrEliminado, cedula)
+	}
+	return err
 }
\ No newline at end of file
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"inscripciones/internal/domain"
+)
+
+// EventoRepository es el outbox de eventos de dominio. Los servicios publican cada evento
+// en la misma unidad de trabajo que el cambio que describe, así que solo queda guardado
+// si el cambio se confirma; el despachador los entrega después.
+type EventoRepository interface {
+	// Publicar guarda el evento como pendiente y le asigna su ID
+	Publicar(evento *domain.Evento) error
+	// Pendientes retorna, en el orden en que se publicaron, hasta limite eventos sin
+	// entregar cuyo próximo intento ya llegó
+	Pendientes(limite int) ([]*domain.Evento, error)
+	MarcarEntregado(id int64) error
+	// MarcarFallido suma un intento fallido y posterga el siguiente hasta proximoIntento
+	MarcarFallido(id int64, proximoIntento time.Time, motivo string) error
+}
+
+// El próximo intento se guarda en milisegundos Unix para compararlo en SQL sin depender
+// de cómo cada motor ordena las fechas en texto. Cada facultad tiene su propio outbox.
+type eventoRepo struct {
+	db       ejecutor
+	dialecto Dialecto
+	facultad string
+	// cifrador cifra los datos de los eventos, que copian cédulas y nombres
+	cifrador *Cifrador
+}
+
+func (r *eventoRepo) Publicar(evento *domain.Evento) error {
+	err := r.db.QueryRow(r.dialecto.rebind(`
+		INSERT INTO outbox (facultad, tipo, fecha, datos, proximo_intento)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`),
+		r.facultad,
+		evento.Tipo,
+		evento.Fecha.UTC().Format(time.RFC3339Nano),
+		r.cifrador.cifrarDato(evento.Datos),
+		evento.Fecha.UnixMilli(),
+	).Scan(&evento.ID)
+	if err != nil {
+		return fmt.Errorf("error al publicar evento %s: %w", evento.Tipo, err)
+	}
+	return nil
+}
+
+func (r *eventoRepo) Pendientes(limite int) ([]*domain.Evento, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(`
+		SELECT id, tipo, fecha, datos, intentos
+		FROM outbox
+		WHERE facultad = ? AND entregado_en IS NULL AND proximo_intento <= ?
+		ORDER BY id
+		LIMIT ?
+	`), r.facultad, time.Now().UnixMilli(), limite)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var eventos []*domain.Evento
+	for rows.Next() {
+		var e domain.Evento
+		var fecha string
+		if err := rows.Scan(&e.ID, &e.Tipo, &fecha, &e.Datos, &e.Intentos); err != nil {
+			return nil, err
+		}
+		e.Fecha, err = time.Parse(time.RFC3339Nano, fecha)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.cifrador.descifrar(&e.Datos); err != nil {
+			return nil, err
+		}
+		eventos = append(eventos, &e)
+	}
+	return eventos, rows.Err()
+}
+
+// MarcarEntregado es idempotente: marcar de nuevo un evento entregado no cambia su fecha de entrega
+func (r *eventoRepo) MarcarEntregado(id int64) error {
+	_, fecha := ahora()
+	return r.actualizar(id, "UPDATE outbox SET entregado_en = COALESCE(entregado_en, ?) WHERE id = ? AND facultad = ?", fecha, id, r.facultad)
+}
+
+func (r *eventoRepo) MarcarFallido(id int64, proximoIntento time.Time, motivo string) error {
+	return r.actualizar(id,
+		"UPDATE outbox SET intentos = intentos + 1, proximo_intento = ?, ultimo_error = ? WHERE id = ? AND facultad = ? AND entregado_en IS NULL",
+		proximoIntento.UnixMilli(), motivo, id, r.facultad)
+}
+
+func (r *eventoRepo) actualizar(id int64, sentencia string, args ...any) error {
+	resultado, err := r.db.Exec(r.dialecto.rebind(sentencia), args...)
+	if err != nil {
+		return err
+	}
+	filas, err := resultado.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if filas == 0 {
+		return fmt.Errorf("%w: evento pendiente %d", ErrNoEncontrado, id)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FacultadPorDefecto es la facultad de los datos creados antes de separar por facultades
+// y la que se usa cuando no se indica otra
+const FacultadPorDefecto = "principal"
+
+// ErrFacultadInvalida indica un nombre de facultad con caracteres no admitidos
+var ErrFacultadInvalida = errors.New("nombre de facultad inválido")
+
+// Acceso indica sobre qué facultad operan los repositorios y si, en modo administrativo,
+// pueden pasar a las demás. Cada consulta y cada cambio se limita a la facultad activa, y
+// las inscripciones además al periodo académico; vacío es el periodo actual de la facultad.
+type Acceso struct {
+	Facultad      string
+	Periodo       string
+	Administrador bool
+}
+
+// completo normaliza la facultad y usa la facultad por defecto si no se indicó ninguna
+func (a Acceso) completo() (Acceso, error) {
+	facultad, err := normalizarFacultad(a.Facultad)
+	if err != nil {
+		return Acceso{}, err
+	}
+	a.Facultad = facultad
+	return a, nil
+}
+
+// normalizarFacultad pasa el nombre a minúsculas; solo se admiten letras sin tilde,
+// dígitos, '-' y '_', para que sirva también como nombre de archivo o de carpeta
+func normalizarFacultad(facultad string) (string, error) {
+	facultad = strings.ToLower(strings.TrimSpace(facultad))
+	if facultad == "" {
+		return FacultadPorDefecto, nil
+	}
+	for _, r := range facultad {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return "", fmt.Errorf("%w: %q", ErrFacultadInvalida, facultad)
+		}
+	}
+	return facultad, nil
+}
+
+// Facultad retorna la facultad a la que están limitados los repositorios
+func (r *Repositorios) Facultad() string {
+	return r.acceso.Facultad
+}
+
+// Administrador indica si los repositorios pueden consultar otras facultades
+func (r *Repositorios) Administrador() bool {
+	return r.acceso.Administrador
+}
+
+// ConFacultad retorna repositorios sobre los datos de otra facultad, en su periodo actual y
+// con el mismo contexto de auditoría. Solo está disponible en modo administrativo.
+func (r *Repositorios) ConFacultad(facultad string) (*Repositorios, error) {
+	if !r.acceso.Administrador {
+		return nil, fmt.Errorf("%w: cambiar a la facultad %q", ErrNoAutorizado, facultad)
+	}
+	facultad, err := normalizarFacultad(facultad)
+	if err != nil {
+		return nil, err
+	}
+	return r.conFacultad(facultad)
+}
+
+// Facultades retorna, ordenadas, las facultades que tienen al menos un estudiante o una
+// materia, incluidos los eliminados lógicamente. Solo está disponible en modo administrativo.
+func (r *Repositorios) Facultades() ([]string, error) {
+	if !r.acceso.Administrador {
+		return nil, fmt.Errorf("%w: listar las facultades", ErrNoAutorizado)
+	}
+	return r.facultades()
+}
+
+// facultadesSQL lista las facultades con datos; las inscripciones no pueden existir sin ellos
+func facultadesSQL(db ejecutor) ([]string, error) {
+	rows, err := db.Query("SELECT facultad FROM estudiantes UNION SELECT facultad FROM materias ORDER BY 1")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var facultades []string
+	for rows.Next() {
+		var facultad string
+		if err := rows.Scan(&facultad); err != nil {
+			return nil, err
+		}
+		facultades = append(facultades, facultad)
+	}
+	return facultades, rows.Err()
+}
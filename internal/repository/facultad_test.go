@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"inscripciones/internal/domain"
+)
+
+func TestSoloElAdministradorCruzaFacultades(t *testing.T) {
+	repos := NewRepositoriosEnMemoria()
+	if repos.Facultad() != FacultadPorDefecto || repos.Administrador() {
+		t.Fatalf("Facultad = %q, Administrador = %v; se esperaba la facultad por defecto sin modo administrativo",
+			repos.Facultad(), repos.Administrador())
+	}
+	if _, err := repos.ConFacultad("sede-norte"); !errors.Is(err, ErrNoAutorizado) {
+		t.Errorf("ConFacultad = %v, se esperaba ErrNoAutorizado", err)
+	}
+	if _, err := repos.Facultades(); !errors.Is(err, ErrNoAutorizado) {
+		t.Errorf("Facultades = %v, se esperaba ErrNoAutorizado", err)
+	}
+
+	// Los repositorios derivados conservan el modo de acceso
+	admin, err := NewRepositoriosEnMemoriaConAcceso(Acceso{Facultad: "sede-norte", Administrador: true})
+	if err != nil {
+		t.Fatalf("NewRepositoriosEnMemoriaConAcceso: %v", err)
+	}
+	auditados := admin.ConAuditoria(ContextoAuditoria{Actor: "ana"}).ConCache(CachePorDefecto)
+	if auditados.Facultad() != "sede-norte" || !auditados.Administrador() {
+		t.Fatalf("ConAuditoria perdió el acceso: %q, %v", auditados.Facultad(), auditados.Administrador())
+	}
+	if _, err := auditados.ConFacultad(FacultadPorDefecto); err != nil {
+		t.Fatalf("ConFacultad desde repositorios derivados: %v", err)
+	}
+}
+
+func TestAbrirRechazaFacultadInvalida(t *testing.T) {
+	_, err := Abrir(Config{Driver: DriverMemoria, Facultad: "Facultad de Ingeniería"})
+	if !errors.Is(err, ErrFacultadInvalida) {
+		t.Fatalf("Abrir = %v, se esperaba ErrFacultadInvalida", err)
+	}
+}
+
+func TestConfigDesdeEntornoFacultad(t *testing.T) {
+	t.Setenv(EnvFacultad, "sede-norte")
+	t.Setenv(EnvAdministrador, "si")
+	cfg := ConfigDesdeEntorno()
+	if cfg.Facultad != "sede-norte" || !cfg.Administrador {
+		t.Fatalf("ConfigDesdeEntorno = %+v, se esperaba sede-norte en modo administrativo", cfg)
+	}
+
+	t.Setenv(EnvAdministrador, "no")
+	if ConfigDesdeEntorno().Administrador {
+		t.Fatal("se activó el modo administrativo con \"no\"")
+	}
+}
+
+// Una base con datos anteriores a las facultades los conserva en la facultad por defecto
+func TestMigracionAFacultades(t *testing.T) {
+	db, err := sql.Open(DriverSQLite, dsnSQLite(filepath.Join(t.TempDir(), "inscripciones.db")))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, descripcion TEXT NOT NULL)"); err != nil {
+		t.Fatalf("schema_migrations: %v", err)
+	}
+	for _, m := range migraciones {
+		if m.version > 7 {
+			break
+		}
+		if err := aplicarMigracion(db, DialectoSQLite, m); err != nil {
+			t.Fatalf("migración %d: %v", m.version, err)
+		}
+	}
+	for _, sentencia := range []string{
+		"INSERT INTO estudiantes (cedula, nombre) VALUES ('1234567', 'Lulú López')",
+		"INSERT INTO estudiantes_fts (clave, texto) VALUES ('1234567', 'Lulú López')",
+		"INSERT INTO materias (codigo, nombre, version) VALUES ('1040', 'Cálculo', 3)",
+		"INSERT INTO inscripciones (estudiante_cedula, materia_codigo) VALUES ('1234567', '1040')",
+	} {
+		if _, err := db.Exec(sentencia); err != nil {
+			t.Fatalf("%s: %v", sentencia, err)
+		}
+	}
+
+	if err := Migrar(db, DialectoSQLite); err != nil {
+		t.Fatalf("Migrar: %v", err)
+	}
+
+	repos := NewRepositorios(db, DialectoSQLite)
+	materias, err := repos.Inscripciones.GetByEstudiante("1234567")
+	if err != nil || len(materias) != 1 || materias[0].Codigo != "1040" {
+		t.Fatalf("GetByEstudiante = %v, %v; se esperaba la inscripción migrada", materias, err)
+	}
+	if m, _ := repos.Materias.GetByCodigo("1040"); m == nil || m.Version != 3 || m.Creditos != domain.CreditosPorDefecto {
+		t.Fatalf("GetByCodigo = %+v, se esperaba conservar la versión con los créditos por defecto", m)
+	}
+	if encontrados, _ := repos.Estudiantes.Search("lulu", 10); len(encontrados) != 1 {
+		t.Fatalf("Search = %v, se esperaba el estudiante reindexado", encontrados)
+	}
+
+	// Tras la migración, la misma cédula puede darse de alta en otra facultad
+	norte, err := NewRepositoriosConAcceso(db, DialectoSQLite, Acceso{Facultad: "sede-norte"})
+	if err != nil {
+		t.Fatalf("NewRepositoriosConAcceso: %v", err)
+	}
+	if err := norte.Estudiantes.Create(domain.NewEstudiante("1234567", "Otra persona")); err != nil {
+		t.Fatalf("Create en sede-norte: %v", err)
+	}
+}
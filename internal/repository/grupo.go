@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"inscripciones/internal/domain"
+)
+
+// ErrGrupoConInscritos impide eliminar un grupo al que todavía apunta alguna inscripción o
+// alguna espera
+var ErrGrupoConInscritos = errors.New("el grupo tiene inscripciones")
+
+// GrupoRepository administra los grupos de las materias de la facultad. Son comunes a
+// todos los periodos y no se auditan: lo que se audita es el grupo de cada inscripción.
+type GrupoRepository interface {
+	// Create agrega el grupo. Retorna ErrReferenciaInvalida si la materia no existe o está
+	// eliminada y ErrDuplicado si el grupo ya estaba.
+	Create(g domain.Grupo) error
+	// Delete quita el grupo junto con sus sesiones y los docentes que lo dictaban en cada
+	// periodo; retorna ErrNoEncontrado si no estaba y ErrGrupoConInscritos si alguna
+	// inscripción de cualquier periodo, incluso cancelada, está en él o alguien lo espera
+	Delete(g domain.Grupo) error
+	// GetByMateria retorna los grupos de la materia ordenados por número
+	GetByMateria(materiaCodigo string) ([]domain.Grupo, error)
+	// GetAll retorna todos los grupos, también los de materias eliminadas, ordenados
+	GetAll() ([]domain.Grupo, error)
+}
+
+type grupoRepo struct {
+	db       ejecutor
+	dialecto Dialecto
+	facultad string
+}
+
+func (r *grupoRepo) Create(g domain.Grupo) error {
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var activa bool
+		err := tx.QueryRow(r.dialecto.rebind("SELECT EXISTS(SELECT 1 FROM materias WHERE facultad = ? AND codigo = ? AND deleted_at IS NULL)"),
+			r.facultad, g.Materia).Scan(&activa)
+		if err != nil {
+			return err
+		}
+		if !activa {
+			return fmt.Errorf("%w: materia %s", ErrReferenciaInvalida, g.Materia)
+		}
+
+		_, err = tx.Exec(r.dialecto.rebind("INSERT INTO grupos (facultad, materia_codigo, numero) VALUES (?, ?, ?)"),
+			r.facultad, g.Materia, g.Numero)
+		return traducirError(err)
+	})
+	if err != nil {
+		return fmt.Errorf("error al crear el grupo %d de %s: %w", g.Numero, g.Materia, err)
+	}
+	return nil
+}
+
+func (r *grupoRepo) Delete(g domain.Grupo) error {
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var conInscritos bool
+		err := tx.QueryRow(r.dialecto.rebind(`
+			SELECT EXISTS(SELECT 1 FROM inscripciones WHERE facultad = ? AND materia_codigo = ? AND grupo = ?)
+				OR EXISTS(SELECT 1 FROM lista_espera WHERE facultad = ? AND materia_codigo = ? AND grupo = ?)
+		`), r.facultad, g.Materia, g.Numero, r.facultad, g.Materia, g.Numero).Scan(&conInscritos)
+		if err != nil {
+			return err
+		}
+		if conInscritos {
+			return ErrGrupoConInscritos
+		}
+
+		_, err = tx.Exec(r.dialecto.rebind("DELETE FROM sesiones WHERE facultad = ? AND materia_codigo = ? AND grupo = ?"),
+			r.facultad, g.Materia, g.Numero)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(r.dialecto.rebind("DELETE FROM asignaciones_docentes WHERE facultad = ? AND materia_codigo = ? AND grupo = ?"),
+			r.facultad, g.Materia, g.Numero)
+		if err != nil {
+			return err
+		}
+		resultado, err := tx.Exec(r.dialecto.rebind("DELETE FROM grupos WHERE facultad = ? AND materia_codigo = ? AND numero = ?"),
+			r.facultad, g.Materia, g.Numero)
+		if err != nil {
+			return err
+		}
+		filas, err := resultado.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if filas == 0 {
+			return fmt.Errorf("%w: la materia %s no tiene grupo %d", ErrNoEncontrado, g.Materia, g.Numero)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error al eliminar el grupo %d de %s: %w", g.Numero, g.Materia, err)
+	}
+	return nil
+}
+
+func (r *grupoRepo) GetByMateria(materiaCodigo string) ([]domain.Grupo, error) {
+	return r.consultar("SELECT materia_codigo, numero FROM grupos WHERE facultad = ? AND materia_codigo = ? ORDER BY numero",
+		r.facultad, materiaCodigo)
+}
+
+func (r *grupoRepo) GetAll() ([]domain.Grupo, error) {
+	return r.consultar("SELECT materia_codigo, numero FROM grupos WHERE facultad = ? ORDER BY materia_codigo, numero", r.facultad)
+}
+
+func (r *grupoRepo) consultar(consulta string, args ...any) ([]domain.Grupo, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(consulta), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grupos []domain.Grupo
+	for rows.Next() {
+		var g domain.Grupo
+		if err := rows.Scan(&g.Materia, &g.Numero); err != nil {
+			return nil, err
+		}
+		grupos = append(grupos, g)
+	}
+	return grupos, rows.Err()
+}
+
+// existeGrupo indica si la materia tiene el grupo; SinGrupo existe siempre
+func existeGrupo(tx ejecutor, d Dialecto, facultad, materiaCodigo string, grupo int) (bool, error) {
+	if grupo == domain.SinGrupo {
+		return true, nil
+	}
+	var existe bool
+	err := tx.QueryRow(d.rebind("SELECT EXISTS(SELECT 1 FROM grupos WHERE facultad = ? AND materia_codigo = ? AND numero = ?)"),
+		facultad, materiaCodigo, grupo).Scan(&existe)
+	return existe, err
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"fmt"
+
+	"inscripciones/internal/domain"
+)
+
+// HorarioRepository administra las sesiones semanales de clase de las materias de la
+// facultad. Son comunes a todos los periodos y no se auditan; se identifican por materia,
+// grupo, día y hora de inicio.
+type HorarioRepository interface {
+	// Create agrega la sesión. Retorna ErrReferenciaInvalida si la materia no existe o está
+	// eliminada o si no tiene el grupo, y ErrDuplicado si ya había una sesión que empieza
+	// a la misma hora del mismo día en ese grupo.
+	Create(s domain.Sesion) error
+	// Delete quita la sesión de la materia y el grupo que empieza a esa hora de ese día;
+	// retorna ErrNoEncontrado si no estaba
+	Delete(s domain.Sesion) error
+	// GetByMateria retorna las sesiones de la materia ordenadas por grupo, día y hora
+	GetByMateria(materiaCodigo string) ([]domain.Sesion, error)
+	// GetAll retorna todas las sesiones, también las de materias eliminadas, ordenadas por
+	// materia, grupo, día y hora
+	GetAll() ([]domain.Sesion, error)
+}
+
+type horarioRepo struct {
+	db       ejecutor
+	dialecto Dialecto
+	facultad string
+}
+
+const columnasSesion = "materia_codigo, grupo, dia, inicio, fin, salon"
+
+func (r *horarioRepo) Create(s domain.Sesion) error {
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var activa bool
+		err := tx.QueryRow(r.dialecto.rebind("SELECT EXISTS(SELECT 1 FROM materias WHERE facultad = ? AND codigo = ? AND deleted_at IS NULL)"),
+			r.facultad, s.Materia).Scan(&activa)
+		if err != nil {
+			return err
+		}
+		if !activa {
+			return fmt.Errorf("%w: materia %s", ErrReferenciaInvalida, s.Materia)
+		}
+		existe, err := existeGrupo(tx, r.dialecto, r.facultad, s.Materia, s.Grupo)
+		if err != nil {
+			return err
+		}
+		if !existe {
+			return fmt.Errorf("%w: la materia %s no tiene grupo %d", ErrReferenciaInvalida, s.Materia, s.Grupo)
+		}
+
+		_, err = tx.Exec(r.dialecto.rebind("INSERT INTO sesiones (facultad, "+columnasSesion+") VALUES (?, ?, ?, ?, ?, ?, ?)"),
+			r.facultad, s.Materia, s.Grupo, int(s.Dia), s.Inicio, s.Fin, s.Salon)
+		return traducirError(err)
+	})
+	if err != nil {
+		return fmt.Errorf("error al agregar la sesión del %s a %s: %w", s.Franja(), s.Materia, err)
+	}
+	return nil
+}
+
+func (r *horarioRepo) Delete(s domain.Sesion) error {
+	resultado, err := r.db.Exec(r.dialecto.rebind("DELETE FROM sesiones WHERE facultad = ? AND materia_codigo = ? AND grupo = ? AND dia = ? AND inicio = ?"),
+		r.facultad, s.Materia, s.Grupo, int(s.Dia), s.Inicio)
+	if err != nil {
+		return fmt.Errorf("error al quitar la sesión de %s: %w", s.Materia, err)
+	}
+	filas, err := resultado.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if filas == 0 {
+		return fmt.Errorf("%w: %s no tiene sesión el %s a las %s en el grupo %d",
+			ErrNoEncontrado, s.Materia, s.Dia, domain.FormatearHora(s.Inicio), s.Grupo)
+	}
+	return nil
+}
+
+func (r *horarioRepo) GetByMateria(materiaCodigo string) ([]domain.Sesion, error) {
+	return r.consultar("SELECT "+columnasSesion+" FROM sesiones WHERE facultad = ? AND materia_codigo = ? ORDER BY grupo, dia, inicio",
+		r.facultad, materiaCodigo)
+}
+
+func (r *horarioRepo) GetAll() ([]domain.Sesion, error) {
+	return r.consultar("SELECT "+columnasSesion+" FROM sesiones WHERE facultad = ? ORDER BY materia_codigo, grupo, dia, inicio", r.facultad)
+}
+
+func (r *horarioRepo) consultar(consulta string, args ...any) ([]domain.Sesion, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(consulta), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sesiones []domain.Sesion
+	for rows.Next() {
+		var s domain.Sesion
+		var dia int
+		if err := rows.Scan(&s.Materia, &s.Grupo, &dia, &s.Inicio, &s.Fin, &s.Salon); err != nil {
+			return nil, err
+		}
+		s.Dia = domain.Dia(dia)
+		sesiones = append(sesiones, s)
+	}
+	return sesiones, rows.Err()
+}
@@ -2,41 +2,125 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"inscripciones/internal/domain"
+	"sort"
+	"time"
 )
 
 type InscripcionRepository interface {
 	Create(estudianteCedula, materiaCodigo string) error
+	// CreateEnGrupo inscribe al estudiante en un grupo de la materia, domain.SinGrupo para
+	// ninguno; retorna ErrReferenciaInvalida si la materia no tiene ese grupo
+	CreateEnGrupo(estudianteCedula, materiaCodigo string, grupo int) error
+	// CambiarGrupo pasa la inscripción no cancelada del periodo a otro grupo de la materia.
+	// Retorna ErrNoEncontrado si no hay inscripción y ErrReferenciaInvalida si no hay grupo.
+	CambiarGrupo(estudianteCedula, materiaCodigo string, grupo int) error
 	GetByEstudiante(cedula string) ([]*domain.Materia, error)
 	GetByMateria(codigo string) ([]*domain.Estudiante, error)
+	// GetByGrupo retorna los inscritos vigentes en un grupo de la materia, ordenados por cédula
+	GetByGrupo(codigo string, grupo int) ([]*domain.Estudiante, error)
 	CountByEstudiante(cedula string) (int, error)
 	Exists(estudianteCedula, materiaCodigo string) (bool, error)
+	GetAll() ([]*domain.Inscripcion, error)
+	// HistorialByEstudiante retorna las inscripciones vigentes del estudiante en todos los
+	// periodos, no solo en el de los repositorios, ordenadas por periodo y código de materia
+	HistorialByEstudiante(cedula string) ([]*domain.Inscripcion, error)
+	CountGroupedByEstudiante() (map[string]int, error)
+	CountGroupedByMateria() (map[string]int, error)
+	// CreditosGroupedByEstudiante retorna la suma de los créditos inscritos por cédula
+	CreditosGroupedByEstudiante() (map[string]int, error)
+	List(consulta Consulta) (*Pagina[*domain.Inscripcion], error)
+	ListByEstudiante(cedula string, consulta Consulta) (*Pagina[*domain.Materia], error)
+	ListByMateria(codigo string, consulta Consulta) (*Pagina[*domain.Estudiante], error)
+	Delete(estudianteCedula, materiaCodigo string) error
+	Restore(estudianteCedula, materiaCodigo string) error
 }
 
+// Una inscripción solo está vigente si ni ella, ni su estudiante, ni su materia están
+// eliminados. El estudiante y la materia son siempre de la facultad de la inscripción, y
+// cada consulta se limita además al periodo de los repositorios.
+const (
+	joinInscripciones = `FROM inscripciones i
+		JOIN estudiantes e ON e.facultad = i.facultad AND e.cedula = i.estudiante_cedula
+		JOIN materias m ON m.facultad = i.facultad AND m.codigo = i.materia_codigo`
+	inscripcionVigente = "i.deleted_at IS NULL AND e.deleted_at IS NULL AND m.deleted_at IS NULL"
+)
+
 type inscripcionRepo struct {
-	db *sql.DB
+	db        ejecutor
+	dialecto  Dialecto
+	facultad  string
+	periodo   string
+	cifrador  *Cifrador
+	auditoria ContextoAuditoria
 }
 
 func NewInscripcionRepository(db *sql.DB) InscripcionRepository {
-	return &inscripcionRepo{db: db}
+	return &inscripcionRepo{db: db, dialecto: DialectoSQLite, facultad: FacultadPorDefecto,
+		periodo: periodoPorDefecto(db, DialectoSQLite), auditoria: contextoPorDefecto}
 }
 
 func (r *inscripcionRepo) Create(estudianteCedula, materiaCodigo string) error {
-	_, err := r.db.Exec(
-		"INSERT INTO inscripciones (estudiante_cedula, materia_codigo) VALUES (?, ?)",
-		estudianteCedula,
-		materiaCodigo,
-	)
-	return err
+	return r.CreateEnGrupo(estudianteCedula, materiaCodigo, domain.SinGrupo)
+}
+
+func (r *inscripcionRepo) CreateEnGrupo(estudianteCedula, materiaCodigo string, grupo int) error {
+	cedula := r.cifrador.cifrarClave(estudianteCedula)
+	err := transaccion(r.db, func(tx ejecutor) error {
+		// La inserción solo ocurre si el estudiante y la materia existen y no están eliminados
+		resultado, err := tx.Exec(
+			r.dialecto.rebind(`
+			INSERT INTO inscripciones (facultad, estudiante_cedula, materia_codigo, periodo, grupo)
+			SELECT ?, ?, ?, ?, ?
+			WHERE EXISTS(SELECT 1 FROM estudiantes WHERE facultad = ? AND cedula = ? AND deleted_at IS NULL)
+			  AND EXISTS(SELECT 1 FROM materias WHERE facultad = ? AND codigo = ? AND deleted_at IS NULL)
+		`),
+			r.facultad,
+			cedula,
+			materiaCodigo,
+			r.periodo,
+			grupo,
+			r.facultad,
+			cedula,
+			r.facultad,
+			materiaCodigo,
+		)
+		if err != nil {
+			return traducirError(err)
+		}
+		filas, err := resultado.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if filas == 0 {
+			return fmt.Errorf("%w: estudiante %s o materia %s no existe", ErrReferenciaInvalida, estudianteCedula, materiaCodigo)
+		}
+		if err := r.verificarGrupo(tx, materiaCodigo, grupo); err != nil {
+			return err
+		}
+		if err := r.verificarCupo(tx, materiaCodigo); err != nil {
+			return err
+		}
+
+		cambio := cambioInscripcion(domain.OperacionCrear, estudianteCedula, materiaCodigo, r.periodo)
+		if err := registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	return r.errorAlCrear(estudianteCedula, materiaCodigo, err)
 }
 
 func (r *inscripcionRepo) GetByEstudiante(cedula string) ([]*domain.Materia, error) {
-	rows, err := r.db.Query(`
-		SELECT m.codigo, m.nombre 
-		FROM materias m
-		JOIN inscripciones i ON m.codigo = i.materia_codigo
-		WHERE i.estudiante_cedula = ?
-	`, cedula)
+	rows, err := r.db.Query(r.dialecto.rebind(`
+		SELECT m.codigo, m.nombre, m.creditos
+		`+joinInscripciones+`
+		WHERE i.facultad = ? AND i.periodo = ? AND i.estudiante_cedula = ? AND `+inscripcionVigente+`
+		ORDER BY m.codigo
+	`), r.facultad, r.periodo, r.cifrador.cifrarClave(cedula))
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +129,7 @@ func (r *inscripcionRepo) GetByEstudiante(cedula string) ([]*domain.Materia, err
 	var materias []*domain.Materia
 	for rows.Next() {
 		var m domain.Materia
-		if err := rows.Scan(&m.Codigo, &m.Nombre); err != nil {
+		if err := rows.Scan(&m.Codigo, &m.Nombre, &m.Creditos); err != nil {
 			return nil, err
 		}
 		materias = append(materias, &m)
@@ -54,13 +138,54 @@ func (r *inscripcionRepo) GetByEstudiante(cedula string) ([]*domain.Materia, err
 	return materias, nil
 }
 
+// CambiarGrupo actualiza el grupo de la inscripción y deja el cambio en la auditoría
+func (r *inscripcionRepo) CambiarGrupo(estudianteCedula, materiaCodigo string, grupo int) error {
+	cedula := r.cifrador.cifrarClave(estudianteCedula)
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var anterior int
+		err := tx.QueryRow(r.dialecto.rebind("SELECT grupo FROM inscripciones WHERE facultad = ? AND periodo = ? AND estudiante_cedula = ? AND materia_codigo = ? AND deleted_at IS NULL"),
+			r.facultad, r.periodo, cedula, materiaCodigo).Scan(&anterior)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s no está inscrito en %s", ErrNoEncontrado, estudianteCedula, materiaCodigo)
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.verificarGrupo(tx, materiaCodigo, grupo); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(r.dialecto.rebind("UPDATE inscripciones SET grupo = ? WHERE facultad = ? AND periodo = ? AND estudiante_cedula = ? AND materia_codigo = ?"),
+			grupo, r.facultad, r.periodo, cedula, materiaCodigo)
+		if err != nil {
+			return err
+		}
+		cambio := cambioGrupoInscripcion(estudianteCedula, materiaCodigo, r.periodo, anterior, grupo)
+		return registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio)
+	})
+	if err != nil {
+		return fmt.Errorf("error al cambiar el grupo de %s en %s: %w", estudianteCedula, materiaCodigo, err)
+	}
+	return nil
+}
+
 func (r *inscripcionRepo) GetByMateria(codigo string) ([]*domain.Estudiante, error) {
-	rows, err := r.db.Query(`
+	return r.inscritos("i.materia_codigo = ?", codigo)
+}
+
+func (r *inscripcionRepo) GetByGrupo(codigo string, grupo int) ([]*domain.Estudiante, error) {
+	return r.inscritos("i.materia_codigo = ? AND i.grupo = ?", codigo, grupo)
+}
+
+// inscritos retorna, ordenados por cédula, los estudiantes de las inscripciones vigentes
+// del periodo que cumplen la condición
+func (r *inscripcionRepo) inscritos(condicion string, args ...any) ([]*domain.Estudiante, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(`
 		SELECT e.cedula, e.nombre 
-		FROM estudiantes e
-		JOIN inscripciones i ON e.cedula = i.estudiante_cedula
-		WHERE i.materia_codigo = ?
-	`, codigo)
+		`+joinInscripciones+`
+		WHERE i.facultad = ? AND i.periodo = ? AND `+condicion+` AND `+inscripcionVigente+`
+		ORDER BY e.cedula
+	`), append([]any{r.facultad, r.periodo}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -72,28 +197,379 @@ func (r *inscripcionRepo) GetByMateria(codigo string) ([]*domain.Estudiante, err
 		if err := rows.Scan(&e.Cedula, &e.Nombre); err != nil {
 			return nil, err
 		}
+		if err := r.cifrador.descifrar(&e.Cedula, &e.Nombre); err != nil {
+			return nil, err
+		}
 		estudiantes = append(estudiantes, &e)
 	}
+	if r.cifrador.activo() {
+		ordenarPorCedula(estudiantes)
+	}
 
 	return estudiantes, nil
 }
 
 func (r *inscripcionRepo) CountByEstudiante(cedula string) (int, error) {
 	var count int
-	err := r.db.QueryRow(`
+	err := r.db.QueryRow(r.dialecto.rebind(`
 		SELECT COUNT(*) 
-		FROM inscripciones 
-		WHERE estudiante_cedula = ?
-	`, cedula).Scan(&count)
+		`+joinInscripciones+`
+		WHERE i.facultad = ? AND i.periodo = ? AND i.estudiante_cedula = ? AND `+inscripcionVigente+`
+	`), r.facultad, r.periodo, r.cifrador.cifrarClave(cedula)).Scan(&count)
 	return count, err
 }
 
 func (r *inscripcionRepo) Exists(estudianteCedula, materiaCodigo string) (bool, error) {
 	var exists bool
 	err := r.db.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM inscripciones WHERE estudiante_cedula = ? AND materia_codigo = ?)",
-		estudianteCedula,
+		r.dialecto.rebind("SELECT EXISTS(SELECT 1 "+joinInscripciones+" WHERE i.facultad = ? AND i.periodo = ? AND i.estudiante_cedula = ? AND i.materia_codigo = ? AND "+inscripcionVigente+")"),
+		r.facultad,
+		r.periodo,
+		r.cifrador.cifrarClave(estudianteCedula),
 		materiaCodigo,
 	).Scan(&exists)
 	return exists, err
-}
\ No newline at end of file
+}
+
+// GetAll retorna todas las inscripciones con los datos del estudiante y la materia en una sola consulta
+func (r *inscripcionRepo) GetAll() ([]*domain.Inscripcion, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(`
+		SELECT e.cedula, e.nombre, m.codigo, m.nombre, m.creditos, i.grupo
+		`+joinInscripciones+`
+		WHERE i.facultad = ? AND i.periodo = ? AND `+inscripcionVigente+`
+		ORDER BY i.estudiante_cedula, i.materia_codigo
+	`), r.facultad, r.periodo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var inscripciones []*domain.Inscripcion
+	var anterior *domain.Estudiante
+	for rows.Next() {
+		var e domain.Estudiante
+		var m domain.Materia
+		var grupo int
+		if err := rows.Scan(&e.Cedula, &e.Nombre, &m.Codigo, &m.Nombre, &m.Creditos, &grupo); err != nil {
+			return nil, err
+		}
+		if err := r.cifrador.descifrar(&e.Cedula, &e.Nombre); err != nil {
+			return nil, err
+		}
+		// Las filas vienen agrupadas por cédula: se comparte el mismo estudiante entre sus inscripciones
+		if anterior == nil || anterior.Cedula != e.Cedula {
+			anterior = &e
+		}
+		inscripciones = append(inscripciones, &domain.Inscripcion{Estudiante: anterior, Materia: &m, Periodo: r.periodo, Grupo: grupo})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if r.cifrador.activo() {
+		// Cada estudiante conserva sus materias en orden; solo falta ordenar por cédula descifrada
+		sort.SliceStable(inscripciones, func(a, b int) bool {
+			return inscripciones[a].Estudiante.Cedula < inscripciones[b].Estudiante.Cedula
+		})
+	}
+
+	return inscripciones, nil
+}
+
+// CountGroupedByEstudiante retorna la cantidad de materias inscritas por cédula
+func (r *inscripcionRepo) CountGroupedByEstudiante() (map[string]int, error) {
+	return r.porEstudiante("COUNT(*)")
+}
+
+func (r *inscripcionRepo) HistorialByEstudiante(cedula string) ([]*domain.Inscripcion, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(`
+		SELECT e.cedula, e.nombre, m.codigo, m.nombre, m.creditos, i.periodo, i.grupo
+		`+joinInscripciones+`
+		WHERE i.facultad = ? AND i.estudiante_cedula = ? AND `+inscripcionVigente+`
+		ORDER BY i.periodo, i.materia_codigo
+	`), r.facultad, r.cifrador.cifrarClave(cedula))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var inscripciones []*domain.Inscripcion
+	var estudiante *domain.Estudiante
+	for rows.Next() {
+		var e domain.Estudiante
+		var m domain.Materia
+		var periodo string
+		var grupo int
+		if err := rows.Scan(&e.Cedula, &e.Nombre, &m.Codigo, &m.Nombre, &m.Creditos, &periodo, &grupo); err != nil {
+			return nil, err
+		}
+		if estudiante == nil {
+			if err := r.cifrador.descifrar(&e.Cedula, &e.Nombre); err != nil {
+				return nil, err
+			}
+			estudiante = &e
+		}
+		inscripciones = append(inscripciones, &domain.Inscripcion{Estudiante: estudiante, Materia: &m, Periodo: periodo, Grupo: grupo})
+	}
+	return inscripciones, rows.Err()
+}
+
+// CreditosGroupedByEstudiante retorna la suma de los créditos inscritos por cédula
+func (r *inscripcionRepo) CreditosGroupedByEstudiante() (map[string]int, error) {
+	return r.porEstudiante("SUM(m.creditos)")
+}
+
+// porEstudiante agrega las inscripciones vigentes por cédula, descifrándolas si hace falta
+func (r *inscripcionRepo) porEstudiante(agregado string) (map[string]int, error) {
+	conteos, err := r.contarAgrupado("i.estudiante_cedula", agregado)
+	if err != nil || !r.cifrador.activo() {
+		return conteos, err
+	}
+	descifrados := make(map[string]int, len(conteos))
+	for cedula, total := range conteos {
+		if err := r.cifrador.descifrar(&cedula); err != nil {
+			return nil, err
+		}
+		descifrados[cedula] = total
+	}
+	return descifrados, nil
+}
+
+// CountGroupedByMateria retorna la cantidad de estudiantes inscritos por código de materia
+func (r *inscripcionRepo) CountGroupedByMateria() (map[string]int, error) {
+	return r.contarAgrupado("i.materia_codigo", "COUNT(*)")
+}
+
+func (r *inscripcionRepo) contarAgrupado(columna, agregado string) (map[string]int, error) {
+	rows, err := r.db.Query(r.dialecto.rebind("SELECT "+columna+", "+agregado+" "+joinInscripciones+
+		" WHERE i.facultad = ? AND i.periodo = ? AND "+inscripcionVigente+" GROUP BY "+columna), r.facultad, r.periodo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conteos := make(map[string]int)
+	for rows.Next() {
+		var clave string
+		var count int
+		if err := rows.Scan(&clave, &count); err != nil {
+			return nil, err
+		}
+		conteos[clave] = count
+	}
+
+	return conteos, rows.Err()
+}
+
+// List retorna una página de inscripciones; Nombre filtra por estudiante y Codigo por materia
+func (r *inscripcionRepo) List(consulta Consulta) (*Pagina[*domain.Inscripcion], error) {
+	columnas, err := columnasOrdenInscripciones(consulta.Orden)
+	if err != nil {
+		return nil, err
+	}
+
+	claves := clavesInscripcion(consulta.Orden)
+	listado := &listadoSQL[*domain.Inscripcion]{
+		db:       r.db,
+		dialecto: r.dialecto,
+		seleccion: `SELECT e.cedula, e.nombre, e.deleted_at, m.codigo, m.nombre, m.creditos, m.deleted_at, i.grupo, i.deleted_at
+		` + joinInscripciones,
+		condiciones:       []string{"i.facultad = ?", "i.periodo = ?"},
+		args:              []any{r.facultad, r.periodo},
+		columnaNombre:     "e.nombre",
+		columnaCodigo:     "i.materia_codigo",
+		columnasEliminado: []string{"i.deleted_at", "e.deleted_at", "m.deleted_at"},
+		columnasOrden:     columnas,
+		escanear: func(rows *sql.Rows) (*domain.Inscripcion, []string, error) {
+			var e domain.Estudiante
+			var m domain.Materia
+			var grupo int
+			var eliminados [3]sql.NullString
+			if err := rows.Scan(&e.Cedula, &e.Nombre, &eliminados[0], &m.Codigo, &m.Nombre, &m.Creditos, &eliminados[1], &grupo, &eliminados[2]); err != nil {
+				return nil, nil, err
+			}
+			if err := r.cifrador.descifrar(&e.Cedula, &e.Nombre); err != nil {
+				return nil, nil, err
+			}
+			var fechas [3]*time.Time
+			for k, valor := range eliminados {
+				fecha, err := fechaEliminacion(valor)
+				if err != nil {
+					return nil, nil, err
+				}
+				fechas[k] = fecha
+			}
+			e.EliminadoEn, m.EliminadoEn = fechas[0], fechas[1]
+			inscripcion := &domain.Inscripcion{Estudiante: &e, Materia: &m, Periodo: r.periodo, Grupo: grupo, EliminadaEn: fechas[2]}
+			return inscripcion, claves(inscripcion), nil
+		},
+	}
+	if r.cifrador.activo() {
+		listado.filtrarEnMemoria = func(i *domain.Inscripcion) (string, string) {
+			return i.Estudiante.Nombre, i.Materia.Codigo
+		}
+	}
+	return listado.ejecutar(consulta)
+}
+
+// ListByEstudiante retorna una página de las materias inscritas por un estudiante
+func (r *inscripcionRepo) ListByEstudiante(cedula string, consulta Consulta) (*Pagina[*domain.Materia], error) {
+	columnas, err := columnasOrdenMaterias(consulta.Orden, "m.")
+	if err != nil {
+		return nil, err
+	}
+
+	listado := &listadoSQL[*domain.Materia]{
+		db:       r.db,
+		dialecto: r.dialecto,
+		seleccion: `SELECT m.codigo, m.nombre, m.creditos, m.cupo, m.deleted_at, m.version
+		` + joinInscripciones,
+		condiciones:       []string{"i.facultad = ?", "i.periodo = ?", "i.estudiante_cedula = ?", "e.deleted_at IS NULL"},
+		args:              []any{r.facultad, r.periodo, r.cifrador.cifrarClave(cedula)},
+		columnaNombre:     "m.nombre",
+		columnaCodigo:     "m.codigo",
+		columnasEliminado: []string{"i.deleted_at", "m.deleted_at"},
+		columnasOrden:     columnas,
+		escanear:          escanearMateria(consulta.Orden),
+	}
+	return listado.ejecutar(consulta)
+}
+
+// ListByMateria retorna una página de los estudiantes inscritos en una materia
+func (r *inscripcionRepo) ListByMateria(codigo string, consulta Consulta) (*Pagina[*domain.Estudiante], error) {
+	columnas, err := columnasOrdenEstudiantes(consulta.Orden, "e.")
+	if err != nil {
+		return nil, err
+	}
+
+	listado := &listadoSQL[*domain.Estudiante]{
+		db:       r.db,
+		dialecto: r.dialecto,
+		seleccion: `SELECT e.cedula, e.nombre, e.deleted_at, e.version
+		` + joinInscripciones,
+		condiciones:       []string{"i.facultad = ?", "i.periodo = ?", "i.materia_codigo = ?", "m.deleted_at IS NULL"},
+		args:              []any{r.facultad, r.periodo, codigo},
+		columnaNombre:     "e.nombre",
+		columnaCodigo:     "e.cedula",
+		columnasEliminado: []string{"i.deleted_at", "e.deleted_at"},
+		columnasOrden:     columnas,
+		escanear:          escanearEstudiante(consulta.Orden, r.cifrador),
+	}
+	if r.cifrador.activo() {
+		listado.filtrarEnMemoria = nombreYCedula
+	}
+	return listado.ejecutar(consulta)
+}
+
+// columnasOrdenInscripciones retorna las columnas de orden; cédula y código siempre desempatan
+func columnasOrdenInscripciones(orden CampoOrden) ([]string, error) {
+	switch orden {
+	case OrdenPorClave:
+		return []string{"i.estudiante_cedula", "i.materia_codigo"}, nil
+	case OrdenPorNombre:
+		return []string{"e.nombre", "i.estudiante_cedula", "i.materia_codigo"}, nil
+	case OrdenPorMateria:
+		return []string{"i.materia_codigo", "i.estudiante_cedula"}, nil
+	default:
+		return nil, fmt.Errorf("%w para inscripciones: %q", ErrOrdenInvalido, orden)
+	}
+}
+
+func clavesInscripcion(orden CampoOrden) func(*domain.Inscripcion) []string {
+	switch orden {
+	case OrdenPorNombre:
+		return func(i *domain.Inscripcion) []string {
+			return []string{i.Estudiante.Nombre, i.Estudiante.Cedula, i.Materia.Codigo}
+		}
+	case OrdenPorMateria:
+		return func(i *domain.Inscripcion) []string { return []string{i.Materia.Codigo, i.Estudiante.Cedula} }
+	default:
+		return func(i *domain.Inscripcion) []string { return []string{i.Estudiante.Cedula, i.Materia.Codigo} }
+	}
+}
+
+// Delete elimina lógicamente la inscripción; el estudiante y la materia no se modifican
+func (r *inscripcionRepo) Delete(estudianteCedula, materiaCodigo string) error {
+	return r.cambiarEliminada(estudianteCedula, materiaCodigo, true)
+}
+
+// Restore deshace la eliminación lógica de la inscripción
+func (r *inscripcionRepo) Restore(estudianteCedula, materiaCodigo string) error {
+	return r.cambiarEliminada(estudianteCedula, materiaCodigo, false)
+}
+
+func (r *inscripcionRepo) cambiarEliminada(estudianteCedula, materiaCodigo string, eliminar bool) error {
+	return transaccion(r.db, func(tx ejecutor) error {
+		ok, err := marcarEliminado(tx, r.dialecto, "inscripciones", "facultad = ? AND periodo = ? AND estudiante_cedula = ? AND materia_codigo = ?",
+			eliminar, r.facultad, r.periodo, r.cifrador.cifrarClave(estudianteCedula), materiaCodigo)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: no hay inscripción de %s en %s en ese estado", ErrNoEncontrado, estudianteCedula, materiaCodigo)
+		}
+		if !eliminar {
+			if err := r.verificarCupo(tx, materiaCodigo); err != nil {
+				return err
+			}
+		}
+
+		operacion := domain.OperacionEliminar
+		if !eliminar {
+			operacion = domain.OperacionRestaurar
+		}
+		cambio := cambioInscripcion(operacion, estudianteCedula, materiaCodigo, r.periodo)
+		if err := registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// verificarCupo comprueba, ya guardada la inscripción, que la materia no tenga en el periodo
+// más inscritos vigentes que su cupo; si los tiene, la transacción deshace la inscripción
+func (r *inscripcionRepo) verificarCupo(tx ejecutor, materiaCodigo string) error {
+	var cupo int
+	err := tx.QueryRow(r.dialecto.rebind("SELECT cupo FROM materias WHERE facultad = ? AND codigo = ?"), r.facultad, materiaCodigo).Scan(&cupo)
+	if err != nil {
+		return err
+	}
+	if cupo == domain.SinCupoLimite {
+		return nil
+	}
+	var inscritos int
+	err = tx.QueryRow(r.dialecto.rebind("SELECT COUNT(*) "+joinInscripciones+
+		" WHERE i.facultad = ? AND i.periodo = ? AND i.materia_codigo = ? AND "+inscripcionVigente), r.facultad, r.periodo, materiaCodigo).Scan(&inscritos)
+	if err != nil {
+		return err
+	}
+	if domain.ExcedeCupo(cupo, inscritos) {
+		return fmt.Errorf("%w: materia %s (cupo %d)", ErrCupoAgotado, materiaCodigo, cupo)
+	}
+	return nil
+}
+
+// verificarGrupo comprueba que la materia tenga el grupo; domain.SinGrupo siempre es válido
+func (r *inscripcionRepo) verificarGrupo(tx ejecutor, materiaCodigo string, grupo int) error {
+	existe, err := existeGrupo(tx, r.dialecto, r.facultad, materiaCodigo, grupo)
+	if err != nil {
+		return err
+	}
+	if !existe {
+		return fmt.Errorf("%w: la materia %s no tiene grupo %d", ErrReferenciaInvalida, materiaCodigo, grupo)
+	}
+	return nil
+}
+
+// errorAlCrear distingue una inscripción repetida de una eliminada lógicamente
+func (r *inscripcionRepo) errorAlCrear(estudianteCedula, materiaCodigo string, err error) error {
+	if !errors.Is(err, ErrDuplicado) {
+		return err
+	}
+	eliminada, errConsulta := estaEliminado(r.db, r.dialecto, "inscripciones",
+		"facultad = ? AND periodo = ? AND estudiante_cedula = ? AND materia_codigo = ?", r.facultad, r.periodo, r.cifrador.cifrarClave(estudianteCedula), materiaCodigo)
+	if errConsulta == nil && eliminada {
+		return fmt.Errorf("%w: inscripción de %s en %s", ErrEliminado, estudianteCedula, materiaCodigo)
+	}
+	return err
+}
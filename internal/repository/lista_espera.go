@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"inscripciones/internal/domain"
+)
+
+// ErrCupoAgotado indica que la materia ya tiene en el periodo tantos inscritos como su cupo
+var ErrCupoAgotado = errors.New("la materia no tiene cupos disponibles")
+
+// ListaEsperaRepository administra, por periodo, los estudiantes que esperan un cupo en
+// cada materia, en el orden en que llegaron. Los cambios no se auditan: lo que queda en la
+// auditoría es la inscripción que se crea cuando a alguien le llega el turno.
+type ListaEsperaRepository interface {
+	// Agregar pone al estudiante al final de la lista de la materia, esperando el grupo
+	// indicado o domain.SinGrupo, y retorna su posición. Retorna ErrReferenciaInvalida si
+	// el estudiante o la materia no existen o están eliminados, o si la materia no tiene ese
+	// grupo, y ErrDuplicado si ya estaba esperando.
+	Agregar(estudianteCedula, materiaCodigo string, grupo int) (int, error)
+	// Quitar saca al estudiante de la lista; retorna ErrNoEncontrado si no estaba
+	Quitar(estudianteCedula, materiaCodigo string) error
+	// GetByMateria retorna la lista de la materia en orden, con posiciones desde 1; solo
+	// cuenta a los estudiantes no eliminados
+	GetByMateria(materiaCodigo string) ([]*domain.Espera, error)
+	// Posicion retorna el lugar del estudiante en la lista de la materia; 0 si no está
+	Posicion(estudianteCedula, materiaCodigo string) (int, error)
+	// CountGroupedByMateria cuenta cuántos esperan en cada materia no eliminada
+	CountGroupedByMateria() (map[string]int, error)
+	// GetAll retorna todas las esperas del periodo, también las de estudiantes y materias
+	// eliminados, ordenadas por materia y posición
+	GetAll() ([]*domain.Espera, error)
+}
+
+type listaEsperaRepo struct {
+	db       ejecutor
+	dialecto Dialecto
+	facultad string
+	periodo  string
+	cifrador *Cifrador
+}
+
+// Una espera solo cuenta si ni su estudiante ni su materia están eliminados; las eliminadas
+// se conservan para cuando se restauren
+const (
+	joinListaEspera = `FROM lista_espera l
+		JOIN estudiantes e ON e.facultad = l.facultad AND e.cedula = l.estudiante_cedula
+		JOIN materias m ON m.facultad = l.facultad AND m.codigo = l.materia_codigo`
+	esperaVigente = "e.deleted_at IS NULL AND m.deleted_at IS NULL"
+)
+
+func (r *listaEsperaRepo) Agregar(estudianteCedula, materiaCodigo string, grupo int) (int, error) {
+	cedula := r.cifrador.cifrarClave(estudianteCedula)
+	var posicion int
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var activos bool
+		err := tx.QueryRow(r.dialecto.rebind(`
+			SELECT EXISTS(SELECT 1 FROM estudiantes WHERE facultad = ? AND cedula = ? AND deleted_at IS NULL)
+				AND EXISTS(SELECT 1 FROM materias WHERE facultad = ? AND codigo = ? AND deleted_at IS NULL)
+		`), r.facultad, cedula, r.facultad, materiaCodigo).Scan(&activos)
+		if err != nil {
+			return err
+		}
+		if !activos {
+			return fmt.Errorf("%w: estudiante %s o materia %s no existe", ErrReferenciaInvalida, estudianteCedula, materiaCodigo)
+		}
+		existe, err := existeGrupo(tx, r.dialecto, r.facultad, materiaCodigo, grupo)
+		if err != nil {
+			return err
+		}
+		if !existe {
+			return fmt.Errorf("%w: la materia %s no tiene grupo %d", ErrReferenciaInvalida, materiaCodigo, grupo)
+		}
+
+		_, err = tx.Exec(r.dialecto.rebind(`
+			INSERT INTO lista_espera (facultad, periodo, materia_codigo, estudiante_cedula, grupo, orden)
+			SELECT ?, ?, ?, ?, ?, COALESCE(MAX(orden), 0) + 1
+			FROM lista_espera
+			WHERE facultad = ? AND periodo = ? AND materia_codigo = ?
+		`), r.facultad, r.periodo, materiaCodigo, cedula, grupo, r.facultad, r.periodo, materiaCodigo)
+		if err != nil {
+			return traducirError(err)
+		}
+
+		posicion, err = (&listaEsperaRepo{db: tx, dialecto: r.dialecto, facultad: r.facultad, periodo: r.periodo, cifrador: r.cifrador}).
+			Posicion(estudianteCedula, materiaCodigo)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error al poner a %s en la lista de espera de %s: %w", estudianteCedula, materiaCodigo, err)
+	}
+	return posicion, nil
+}
+
+func (r *listaEsperaRepo) Quitar(estudianteCedula, materiaCodigo string) error {
+	resultado, err := r.db.Exec(r.dialecto.rebind("DELETE FROM lista_espera WHERE facultad = ? AND periodo = ? AND materia_codigo = ? AND estudiante_cedula = ?"),
+		r.facultad, r.periodo, materiaCodigo, r.cifrador.cifrarClave(estudianteCedula))
+	if err != nil {
+		return fmt.Errorf("error al sacar a %s de la lista de espera de %s: %w", estudianteCedula, materiaCodigo, err)
+	}
+	filas, err := resultado.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if filas == 0 {
+		return fmt.Errorf("%w: %s no está en la lista de espera de %s", ErrNoEncontrado, estudianteCedula, materiaCodigo)
+	}
+	return nil
+}
+
+func (r *listaEsperaRepo) GetByMateria(materiaCodigo string) ([]*domain.Espera, error) {
+	return r.consultar(`
+		SELECT e.cedula, e.nombre, l.materia_codigo, l.grupo
+		`+joinListaEspera+`
+		WHERE l.facultad = ? AND l.periodo = ? AND l.materia_codigo = ? AND `+esperaVigente+`
+		ORDER BY l.orden
+	`, r.facultad, r.periodo, materiaCodigo)
+}
+
+func (r *listaEsperaRepo) Posicion(estudianteCedula, materiaCodigo string) (int, error) {
+	lista, err := r.GetByMateria(materiaCodigo)
+	if err != nil {
+		return 0, err
+	}
+	for _, espera := range lista {
+		if espera.Estudiante.Cedula == estudianteCedula {
+			return espera.Posicion, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *listaEsperaRepo) CountGroupedByMateria() (map[string]int, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(`
+		SELECT l.materia_codigo, COUNT(*)
+		`+joinListaEspera+`
+		WHERE l.facultad = ? AND l.periodo = ? AND `+esperaVigente+`
+		GROUP BY l.materia_codigo
+	`), r.facultad, r.periodo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conteos := make(map[string]int)
+	for rows.Next() {
+		var codigo string
+		var cantidad int
+		if err := rows.Scan(&codigo, &cantidad); err != nil {
+			return nil, err
+		}
+		conteos[codigo] = cantidad
+	}
+	return conteos, rows.Err()
+}
+
+func (r *listaEsperaRepo) GetAll() ([]*domain.Espera, error) {
+	return r.consultar(`
+		SELECT e.cedula, e.nombre, l.materia_codigo, l.grupo
+		`+joinListaEspera+`
+		WHERE l.facultad = ? AND l.periodo = ?
+		ORDER BY l.materia_codigo, l.orden
+	`, r.facultad, r.periodo)
+}
+
+// consultar lee esperas ordenadas por materia y numera las posiciones dentro de cada una
+func (r *listaEsperaRepo) consultar(consulta string, args ...any) ([]*domain.Espera, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(consulta), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var esperas []*domain.Espera
+	for rows.Next() {
+		e := &domain.Estudiante{}
+		espera := &domain.Espera{Estudiante: e, Periodo: r.periodo}
+		if err := rows.Scan(&e.Cedula, &e.Nombre, &espera.Codigo, &espera.Grupo); err != nil {
+			return nil, err
+		}
+		if err := r.cifrador.descifrar(&e.Cedula, &e.Nombre); err != nil {
+			return nil, err
+		}
+		espera.Posicion = 1
+		if n := len(esperas); n > 0 && esperas[n-1].Codigo == espera.Codigo {
+			espera.Posicion = esperas[n-1].Posicion + 1
+		}
+		esperas = append(esperas, espera)
+	}
+	return esperas, rows.Err()
+}
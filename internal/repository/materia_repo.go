@@ -2,6 +2,8 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"inscripciones/internal/domain"
 )
 
@@ -10,30 +12,66 @@ type MateriaRepository interface {
 	GetByCodigo(codigo string) (*domain.Materia, error)
 	GetAll() ([]*domain.Materia, error)  // Agregar este método a la interfaz
 	Exists(codigo string) (bool, error)
+	List(consulta Consulta) (*Pagina[*domain.Materia], error)
+	Search(texto string, limite int) ([]*domain.Materia, error)
+	Delete(codigo string) error
+	Restore(codigo string) error
+	// Update guarda el nombre, los créditos y el cupo si la versión coincide con la
+	// almacenada e incrementa la versión; si otro usuario lo modificó antes, retorna
+	// ErrConflicto sin cambiar nada
+	Update(materia *domain.Materia) error
 }
 
 type materiaRepo struct {
-	db *sql.DB
+	db        ejecutor
+	dialecto  Dialecto
+	facultad  string
+	cifrador  *Cifrador
+	auditoria ContextoAuditoria
 }
 
 func NewMateriaRepository(db *sql.DB) MateriaRepository {
-	return &materiaRepo{db: db}
+	return &materiaRepo{db: db, dialecto: DialectoSQLite, facultad: FacultadPorDefecto, auditoria: contextoPorDefecto}
 }
 
 func (r *materiaRepo) Create(materia *domain.Materia) error {
-	_, err := r.db.Exec(
-		"INSERT INTO materias (codigo, nombre) VALUES (?, ?)",
-		materia.Codigo,
-		materia.Nombre,
-	)
-	return err
+	err := transaccion(r.db, func(tx ejecutor) error {
+		_, err := tx.Exec(
+			r.dialecto.rebind("INSERT INTO materias (facultad, codigo, nombre, creditos, cupo) VALUES (?, ?, ?, ?, ?)"),
+			r.facultad,
+			materia.Codigo,
+			materia.Nombre,
+			materia.Creditos,
+			materia.Cupo,
+		)
+		if err != nil {
+			return traducirError(err)
+		}
+
+		// Mantener sincronizado el índice de búsqueda por nombre
+		if err := indiceMaterias.indexar(tx, r.dialecto, r.facultad, materia.Codigo, materia.Nombre); err != nil {
+			return err
+		}
+
+		cambio := cambioMateria(domain.OperacionCrear, nil, materia)
+		if err := registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return r.errorAlCrear(materia.Codigo, err)
+	}
+	materia.Version = 1
+	return nil
 }
 
 func (r *materiaRepo) GetByCodigo(codigo string) (*domain.Materia, error) {
-	row := r.db.QueryRow("SELECT codigo, nombre FROM materias WHERE codigo = ?", codigo)
+	row := r.db.QueryRow(r.dialecto.rebind("SELECT codigo, nombre, creditos, cupo, version FROM materias WHERE facultad = ? AND codigo = ? AND deleted_at IS NULL"), r.facultad, codigo)
 
 	var m domain.Materia
-	err := row.Scan(&m.Codigo, &m.Nombre)
+	err := row.Scan(&m.Codigo, &m.Nombre, &m.Creditos, &m.Cupo, &m.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -47,14 +85,15 @@ func (r *materiaRepo) GetByCodigo(codigo string) (*domain.Materia, error) {
 func (r *materiaRepo) Exists(codigo string) (bool, error) {
 	var exists bool
 	err := r.db.QueryRow(
-		"SELECT EXISTS(SELECT 1 FROM materias WHERE codigo = ?)",
+		r.dialecto.rebind("SELECT EXISTS(SELECT 1 FROM materias WHERE facultad = ? AND codigo = ? AND deleted_at IS NULL)"),
+		r.facultad,
 		codigo,
 	).Scan(&exists)
 	return exists, err
 }
 
 func (r *materiaRepo) GetAll() ([]*domain.Materia, error) {
-	rows, err := r.db.Query("SELECT codigo, nombre FROM materias")
+	rows, err := r.db.Query(r.dialecto.rebind("SELECT codigo, nombre, creditos, cupo, version FROM materias WHERE facultad = ? AND deleted_at IS NULL ORDER BY codigo"), r.facultad)
 	if err != nil {
 		return nil, err
 	}
@@ -63,10 +102,190 @@ func (r *materiaRepo) GetAll() ([]*domain.Materia, error) {
 	var materias []*domain.Materia
 	for rows.Next() {
 		var m domain.Materia
-		if err := rows.Scan(&m.Codigo, &m.Nombre); err != nil {
+		if err := rows.Scan(&m.Codigo, &m.Nombre, &m.Creditos, &m.Cupo, &m.Version); err != nil {
 			return nil, err
 		}
 		materias = append(materias, &m)
 	}
 	return materias, nil
+}
+
+// List retorna una página de materias filtrada por nombre y prefijo de código
+func (r *materiaRepo) List(consulta Consulta) (*Pagina[*domain.Materia], error) {
+	columnas, err := columnasOrdenMaterias(consulta.Orden, "")
+	if err != nil {
+		return nil, err
+	}
+
+	listado := &listadoSQL[*domain.Materia]{
+		db:                r.db,
+		dialecto:          r.dialecto,
+		seleccion:         "SELECT codigo, nombre, creditos, cupo, deleted_at, version FROM materias",
+		condiciones:       []string{"facultad = ?"},
+		args:              []any{r.facultad},
+		columnaNombre:     "nombre",
+		columnaCodigo:     "codigo",
+		columnasEliminado: []string{"deleted_at"},
+		columnasOrden:     columnas,
+		escanear:          escanearMateria(consulta.Orden),
+	}
+	return listado.ejecutar(consulta)
+}
+
+// columnasOrdenMaterias retorna las columnas de orden; el código siempre desempata
+func columnasOrdenMaterias(orden CampoOrden, alias string) ([]string, error) {
+	switch orden {
+	case OrdenPorClave:
+		return []string{alias + "codigo"}, nil
+	case OrdenPorNombre:
+		return []string{alias + "nombre", alias + "codigo"}, nil
+	default:
+		return nil, fmt.Errorf("%w para materias: %q", ErrOrdenInvalido, orden)
+	}
+}
+
+func clavesMateria(orden CampoOrden) func(*domain.Materia) []string {
+	if orden == OrdenPorNombre {
+		return func(m *domain.Materia) []string { return []string{m.Nombre, m.Codigo} }
+	}
+	return func(m *domain.Materia) []string { return []string{m.Codigo} }
+}
+
+func escanearMateria(orden CampoOrden) func(*sql.Rows) (*domain.Materia, []string, error) {
+	claves := clavesMateria(orden)
+	return func(rows *sql.Rows) (*domain.Materia, []string, error) {
+		var m domain.Materia
+		var eliminado sql.NullString
+		if err := rows.Scan(&m.Codigo, &m.Nombre, &m.Creditos, &m.Cupo, &eliminado, &m.Version); err != nil {
+			return nil, nil, err
+		}
+		fecha, err := fechaEliminacion(eliminado)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.EliminadoEn = fecha
+		return &m, claves(&m), nil
+	}
+}
+
+// Search busca por nombre parcial sin distinguir tildes ni mayúsculas, ordenando por relevancia
+func (r *materiaRepo) Search(texto string, limite int) ([]*domain.Materia, error) {
+	resultados, err := indiceMaterias.buscar(r.db, r.dialecto, r.facultad, texto, limite)
+	if err != nil {
+		return nil, err
+	}
+
+	var encontrados []*domain.Materia
+	for _, res := range resultados {
+		encontrados = append(encontrados, &domain.Materia{Codigo: res.clave, Nombre: res.nombre, Version: res.version})
+	}
+	return encontrados, nil
+}
+
+// Delete elimina lógicamente la materia; sigue existiendo, pero las consultas la ocultan
+func (r *materiaRepo) Delete(codigo string) error {
+	return r.cambiarEliminado(codigo, true)
+}
+
+// Restore deshace la eliminación lógica de la materia
+func (r *materiaRepo) Restore(codigo string) error {
+	return r.cambiarEliminado(codigo, false)
+}
+
+func (r *materiaRepo) cambiarEliminado(codigo string, eliminar bool) error {
+	return transaccion(r.db, func(tx ejecutor) error {
+		m := &domain.Materia{Codigo: codigo}
+		err := tx.QueryRow(r.dialecto.rebind("SELECT nombre, creditos, cupo FROM materias WHERE facultad = ? AND codigo = ?"), r.facultad, codigo).Scan(&m.Nombre, &m.Creditos, &m.Cupo)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: materia %s", ErrNoEncontrado, codigo)
+		}
+		if err != nil {
+			return err
+		}
+
+		ok, err := marcarEliminado(tx, r.dialecto, "materias", "facultad = ? AND codigo = ?", eliminar, r.facultad, codigo)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: no hay materia %s en ese estado", ErrNoEncontrado, codigo)
+		}
+
+		cambio := cambioMateria(domain.OperacionEliminar, m, nil)
+		if !eliminar {
+			cambio = cambioMateria(domain.OperacionRestaurar, nil, m)
+		}
+		if err := registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Update cambia el nombre, los créditos y el cupo de la materia solo si nadie lo modificó desde que se leyó la versión
+func (r *materiaRepo) Update(materia *domain.Materia) error {
+	err := transaccion(r.db, func(tx ejecutor) error {
+		var antes domain.Materia
+		err := tx.QueryRow(
+			r.dialecto.rebind("SELECT codigo, nombre, creditos, cupo, version FROM materias WHERE facultad = ? AND codigo = ? AND deleted_at IS NULL"),
+			r.facultad,
+			materia.Codigo,
+		).Scan(&antes.Codigo, &antes.Nombre, &antes.Creditos, &antes.Cupo, &antes.Version)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: materia %s", ErrNoEncontrado, materia.Codigo)
+		}
+		if err != nil {
+			return err
+		}
+
+		// La condición sobre la versión hace atómica la comprobación: si otra transacción
+		// actualizó la fila después de la lectura anterior, no se modifica ninguna
+		resultado, err := tx.Exec(
+			r.dialecto.rebind("UPDATE materias SET nombre = ?, creditos = ?, cupo = ?, version = version + 1 WHERE facultad = ? AND codigo = ? AND version = ? AND deleted_at IS NULL"),
+			materia.Nombre,
+			materia.Creditos,
+			materia.Cupo,
+			r.facultad,
+			materia.Codigo,
+			materia.Version,
+		)
+		if err != nil {
+			return err
+		}
+		filas, err := resultado.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if filas == 0 {
+			return fmt.Errorf("%w: materia %s (versión %d, se esperaba %d)", ErrConflicto, materia.Codigo, antes.Version, materia.Version)
+		}
+
+		if err := indiceMaterias.reindexar(tx, r.dialecto, r.facultad, materia.Codigo, materia.Nombre); err != nil {
+			return err
+		}
+
+		cambio := cambioMateria(domain.OperacionActualizar, &antes, materia)
+		if err := registrarAuditoria(tx, r.dialecto, r.facultad, r.cifrador, r.auditoria, cambio); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	materia.Version++
+	return nil
+}
+
+// errorAlCrear distingue un alta repetida de una sobre un registro eliminado lógicamente
+func (r *materiaRepo) errorAlCrear(codigo string, err error) error {
+	if !errors.Is(err, ErrDuplicado) {
+		return err
+	}
+	if eliminado, errConsulta := estaEliminado(r.db, r.dialecto, "materias", "facultad = ? AND codigo = ?", r.facultad, codigo); errConsulta == nil && eliminado {
+		return fmt.Errorf("%w: materia %s", ErrEliminado, codigo)
+	}
+	return err
 }
\ No newline at end of file
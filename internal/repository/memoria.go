@@ -0,0 +1,2306 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"inscripciones/internal/domain"
+)
+
+// facultadesMemoria reúne los almacenes de cada facultad; las facultades no comparten
+// ningún dato, ni siquiera la auditoría o el outbox
+type facultadesMemoria struct {
+	mu        sync.Mutex
+	almacenes map[string]*almacenMemoria
+}
+
+// almacenMemoria guarda los datos de una facultad compartidos por los repositorios en memoria
+type almacenMemoria struct {
+	mu sync.RWMutex
+	// transacciones serializa las unidades de trabajo
+	transacciones sync.Mutex
+	facultades    *facultadesMemoria
+
+	estudiantes   map[string]domain.Estudiante
+	materias      map[string]domain.Materia
+	inscripciones map[claveInscripcion]estadoInscripcion
+	auditoria     []entradaAuditoria
+	outbox        []entradaOutbox
+	periodos      map[string]bool
+	periodoActual string
+	// prerrequisitos guarda cada prerrequisito entre materias, sin importar si están eliminadas
+	prerrequisitos map[domain.Prerrequisito]bool
+	// listaEspera guarda el orden de llegada de cada estudiante que espera cupo en una
+	// materia y el grupo que pidió
+	listaEspera map[claveInscripcion]estadoEspera
+	// grupos guarda los grupos de cada materia, sin importar si está eliminada
+	grupos map[domain.Grupo]bool
+	// sesiones guarda las sesiones de clase de cada materia y grupo, sin importar si la materia está eliminada
+	sesiones map[claveSesion]domain.Sesion
+	// componentes guarda los componentes de evaluación de cada materia, sin importar si está eliminada
+	componentes map[claveComponente]int
+	// calificaciones guarda en centésimas la nota de cada inscripción en cada componente
+	calificaciones map[claveCalificacion]int
+	// carreras guarda las carreras de la facultad por código
+	carreras map[string]domain.Carrera
+	// pensum guarda la ubicación de cada materia en el pensum de cada carrera, sin importar
+	// si la materia está eliminada
+	pensum map[clavePensum]domain.MateriaPensum
+	// carreraEstudiantes guarda la carrera de cada estudiante, también de los eliminados
+	carreraEstudiantes map[string]string
+	// docentes guarda los docentes de la facultad por cédula
+	docentes map[string]domain.Docente
+	// asignacionesDocentes guarda la cédula del docente de cada materia y grupo en cada
+	// periodo, sin importar si la materia está eliminada
+	asignacionesDocentes map[claveAsignacionDocente]string
+}
+
+// entradaAuditoria conserva, junto al registro, las claves por las que se consulta el historial
+type entradaAuditoria struct {
+	registro domain.RegistroAuditoria
+	cedula   string
+	codigo   string
+}
+
+// entradaOutbox es un evento publicado junto con su estado de entrega
+type entradaOutbox struct {
+	evento         domain.Evento
+	proximoIntento time.Time
+	ultimoError    string
+	entregado      bool
+}
+
+type claveInscripcion struct {
+	cedula  string
+	codigo  string
+	periodo string
+}
+
+// claveSesion identifica una sesión como lo hace la clave primaria de la tabla sesiones
+type claveSesion struct {
+	materia string
+	grupo   int
+	dia     domain.Dia
+	inicio  int
+}
+
+func claveDeSesion(s domain.Sesion) claveSesion {
+	return claveSesion{materia: s.Materia, grupo: s.Grupo, dia: s.Dia, inicio: s.Inicio}
+}
+
+// claveComponente identifica un componente de evaluación por materia y nombre; el valor
+// guardado es su peso
+type claveComponente struct {
+	materia string
+	nombre  string
+}
+
+// claveCalificacion identifica la nota de una inscripción en un componente
+type claveCalificacion struct {
+	claveInscripcion
+	componente string
+}
+
+// clavePensum identifica una materia en el pensum de una carrera
+type clavePensum struct {
+	carrera string
+	materia string
+}
+
+// claveAsignacionDocente identifica la materia o el grupo que dicta un docente en un periodo
+type claveAsignacionDocente struct {
+	periodo string
+	materia string
+	grupo   int
+}
+
+type estadoInscripcion struct {
+	grupo       int
+	eliminadaEn *time.Time
+}
+
+type estadoEspera struct {
+	orden int
+	grupo int
+}
+
+// NewRepositoriosEnMemoria crea repositorios que no persisten nada, útiles para
+// sesiones efímeras y pruebas rápidas, limitados a la facultad por defecto y a su
+// periodo actual, el de la fecha de hoy
+func NewRepositoriosEnMemoria() *Repositorios {
+	a := nuevasFacultadesMemoria().almacen(FacultadPorDefecto)
+	return a.repositorios(contextoPorDefecto, Acceso{Facultad: FacultadPorDefecto, Periodo: a.periodoActual})
+}
+
+// NewRepositoriosEnMemoriaConAcceso crea repositorios en memoria limitados a la facultad
+// indicada. Al empezar vacía, la facultad solo tiene el periodo de la fecha de hoy.
+func NewRepositoriosEnMemoriaConAcceso(acceso Acceso) (*Repositorios, error) {
+	acceso, err := acceso.completo()
+	if err != nil {
+		return nil, err
+	}
+	a := nuevasFacultadesMemoria().almacen(acceso.Facultad)
+	if acceso.Periodo, err = a.periodoVigente(acceso.Periodo); err != nil {
+		return nil, err
+	}
+	return a.repositorios(contextoPorDefecto, acceso), nil
+}
+
+func nuevasFacultadesMemoria() *facultadesMemoria {
+	return &facultadesMemoria{almacenes: make(map[string]*almacenMemoria)}
+}
+
+// almacen retorna el almacén de la facultad, creándolo vacío la primera vez con el
+// periodo de la fecha de hoy como actual
+func (f *facultadesMemoria) almacen(facultad string) *almacenMemoria {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	a, ok := f.almacenes[facultad]
+	if !ok {
+		hoy := domain.PeriodoDeFecha(time.Now())
+		a = &almacenMemoria{
+			facultades:           f,
+			estudiantes:          make(map[string]domain.Estudiante),
+			materias:             make(map[string]domain.Materia),
+			inscripciones:        make(map[claveInscripcion]estadoInscripcion),
+			periodos:             map[string]bool{hoy: true},
+			periodoActual:        hoy,
+			prerrequisitos:       make(map[domain.Prerrequisito]bool),
+			listaEspera:          make(map[claveInscripcion]estadoEspera),
+			grupos:               make(map[domain.Grupo]bool),
+			sesiones:             make(map[claveSesion]domain.Sesion),
+			componentes:          make(map[claveComponente]int),
+			calificaciones:       make(map[claveCalificacion]int),
+			carreras:             make(map[string]domain.Carrera),
+			pensum:               make(map[clavePensum]domain.MateriaPensum),
+			carreraEstudiantes:   make(map[string]string),
+			docentes:             make(map[string]domain.Docente),
+			asignacionesDocentes: make(map[claveAsignacionDocente]string),
+		}
+		f.almacenes[facultad] = a
+	}
+	return a
+}
+
+// listar retorna, ordenadas, las facultades con al menos un estudiante o una materia
+func (f *facultadesMemoria) listar() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var facultades []string
+	for facultad, a := range f.almacenes {
+		a.mu.RLock()
+		conDatos := len(a.estudiantes)+len(a.materias) > 0
+		a.mu.RUnlock()
+		if conDatos {
+			facultades = append(facultades, facultad)
+		}
+	}
+	sort.Strings(facultades)
+	return facultades, nil
+}
+
+func (a *almacenMemoria) repositorios(auditoria ContextoAuditoria, acceso Acceso) *Repositorios {
+	return &Repositorios{
+		Estudiantes:    &estudianteMemoria{almacen: a, auditoria: auditoria},
+		Materias:       &materiaMemoria{almacen: a, auditoria: auditoria},
+		Inscripciones:  &inscripcionMemoria{almacen: a, periodo: acceso.Periodo, auditoria: auditoria},
+		Auditoria:      &auditoriaMemoria{almacen: a},
+		Eventos:        &eventoMemoria{almacen: a},
+		Periodos:       &periodoMemoria{almacen: a},
+		Prerrequisitos: &prerrequisitoMemoria{almacen: a},
+		ListaEspera:    &listaEsperaMemoria{almacen: a, periodo: acceso.Periodo},
+		Grupos:         &grupoMemoria{almacen: a},
+		Horarios:       &horarioMemoria{almacen: a},
+		Componentes:    &componenteMemoria{almacen: a},
+		Calificaciones: &calificacionMemoria{almacen: a, periodo: acceso.Periodo, auditoria: auditoria},
+		Carreras:       &carreraMemoria{almacen: a, auditoria: auditoria},
+		Docentes:       &docenteMemoria{almacen: a, periodo: acceso.Periodo},
+		backend:        DriverMemoria,
+		acceso:         acceso,
+		conAuditoria: func(c ContextoAuditoria) *Repositorios {
+			return a.repositorios(c, acceso)
+		},
+		cargar: func(v *volcado) error {
+			return a.cargar(auditoria, v)
+		},
+		enTransaccion: func(fn func(*Repositorios) error) error {
+			return a.enTransaccion(auditoria, acceso, fn)
+		},
+		conFacultad: func(f string) (*Repositorios, error) {
+			otra := a.facultades.almacen(f)
+			actual, err := otra.periodoVigente("")
+			if err != nil {
+				return nil, err
+			}
+			return otra.repositorios(auditoria, Acceso{Facultad: f, Periodo: actual, Administrador: acceso.Administrador}), nil
+		},
+		enPeriodo: func(p string) (*Repositorios, error) {
+			p, err := a.periodoVigente(p)
+			if err != nil {
+				return nil, err
+			}
+			otro := acceso
+			otro.Periodo = p
+			return a.repositorios(auditoria, otro), nil
+		},
+		facultades: a.facultades.listar,
+	}
+}
+
+// enTransaccion ejecuta la unidad de trabajo sobre una copia del almacén y la publica
+// solo si fn termina sin error. Las unidades de trabajo se serializan entre sí, pero un
+// cambio hecho fuera de ellas mientras una está en curso se pierde al publicarla: el
+// backend en memoria está pensado para sesiones de un solo usuario y para pruebas.
+func (a *almacenMemoria) enTransaccion(auditoria ContextoAuditoria, acceso Acceso, fn func(*Repositorios) error) error {
+	a.transacciones.Lock()
+	defer a.transacciones.Unlock()
+
+	copia := a.copiar()
+	if err := fn(copia.repositorios(auditoria, acceso)); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.estudiantes, a.materias, a.inscripciones = copia.estudiantes, copia.materias, copia.inscripciones
+	a.auditoria, a.outbox = copia.auditoria, copia.outbox
+	a.periodos, a.periodoActual = copia.periodos, copia.periodoActual
+	a.prerrequisitos, a.listaEspera, a.grupos, a.sesiones = copia.prerrequisitos, copia.listaEspera, copia.grupos, copia.sesiones
+	a.componentes, a.calificaciones = copia.componentes, copia.calificaciones
+	a.carreras, a.pensum, a.carreraEstudiantes = copia.carreras, copia.pensum, copia.carreraEstudiantes
+	a.docentes, a.asignacionesDocentes = copia.docentes, copia.asignacionesDocentes
+	return nil
+}
+
+func (a *almacenMemoria) copiar() *almacenMemoria {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	copia := &almacenMemoria{
+		facultades:           a.facultades,
+		estudiantes:          make(map[string]domain.Estudiante, len(a.estudiantes)),
+		materias:             make(map[string]domain.Materia, len(a.materias)),
+		inscripciones:        make(map[claveInscripcion]estadoInscripcion, len(a.inscripciones)),
+		auditoria:            append([]entradaAuditoria(nil), a.auditoria...),
+		outbox:               append([]entradaOutbox(nil), a.outbox...),
+		periodos:             make(map[string]bool, len(a.periodos)),
+		periodoActual:        a.periodoActual,
+		prerrequisitos:       make(map[domain.Prerrequisito]bool, len(a.prerrequisitos)),
+		listaEspera:          make(map[claveInscripcion]estadoEspera, len(a.listaEspera)),
+		grupos:               make(map[domain.Grupo]bool, len(a.grupos)),
+		sesiones:             make(map[claveSesion]domain.Sesion, len(a.sesiones)),
+		componentes:          make(map[claveComponente]int, len(a.componentes)),
+		calificaciones:       make(map[claveCalificacion]int, len(a.calificaciones)),
+		carreras:             make(map[string]domain.Carrera, len(a.carreras)),
+		pensum:               make(map[clavePensum]domain.MateriaPensum, len(a.pensum)),
+		carreraEstudiantes:   make(map[string]string, len(a.carreraEstudiantes)),
+		docentes:             make(map[string]domain.Docente, len(a.docentes)),
+		asignacionesDocentes: make(map[claveAsignacionDocente]string, len(a.asignacionesDocentes)),
+	}
+	for k, v := range a.prerrequisitos {
+		copia.prerrequisitos[k] = v
+	}
+	for k, v := range a.listaEspera {
+		copia.listaEspera[k] = v
+	}
+	for k, v := range a.grupos {
+		copia.grupos[k] = v
+	}
+	for k, v := range a.sesiones {
+		copia.sesiones[k] = v
+	}
+	for k, v := range a.componentes {
+		copia.componentes[k] = v
+	}
+	for k, v := range a.calificaciones {
+		copia.calificaciones[k] = v
+	}
+	for k, v := range a.carreras {
+		copia.carreras[k] = v
+	}
+	for k, v := range a.pensum {
+		copia.pensum[k] = v
+	}
+	for k, v := range a.carreraEstudiantes {
+		copia.carreraEstudiantes[k] = v
+	}
+	for k, v := range a.docentes {
+		copia.docentes[k] = v
+	}
+	for k, v := range a.asignacionesDocentes {
+		copia.asignacionesDocentes[k] = v
+	}
+	for k, v := range a.periodos {
+		copia.periodos[k] = v
+	}
+	for k, v := range a.estudiantes {
+		copia.estudiantes[k] = v
+	}
+	for k, v := range a.materias {
+		copia.materias[k] = v
+	}
+	for k, v := range a.inscripciones {
+		copia.inscripciones[k] = v
+	}
+	return copia
+}
+
+// registrar agrega un registro de auditoría; se llama con el candado de escritura tomado
+func (a *almacenMemoria) registrar(contexto ContextoAuditoria, c cambio) {
+	registro := c.registro(contexto, time.Now().UTC())
+	registro.ID = int64(len(a.auditoria) + 1)
+	a.auditoria = append(a.auditoria, entradaAuditoria{registro: *registro, cedula: c.cedula, codigo: c.codigo})
+}
+
+// Las funciones siguientes se llaman con el candado tomado
+
+func (a *almacenMemoria) estudianteActivo(cedula string) (domain.Estudiante, bool) {
+	e, ok := a.estudiantes[cedula]
+	return e, ok && e.EliminadoEn == nil
+}
+
+func (a *almacenMemoria) materiaActiva(codigo string) (domain.Materia, bool) {
+	m, ok := a.materias[codigo]
+	return m, ok && m.EliminadoEn == nil
+}
+
+// inscripcionesVigentes recorre las inscripciones del periodo que ni están eliminadas ni
+// apuntan a un estudiante o una materia eliminados
+func (a *almacenMemoria) inscripcionesVigentes(periodo string, visitar func(clave claveInscripcion, e domain.Estudiante, m domain.Materia)) {
+	for clave, estado := range a.inscripciones {
+		if clave.periodo != periodo || estado.eliminadaEn != nil {
+			continue
+		}
+		e, okEstudiante := a.estudianteActivo(clave.cedula)
+		m, okMateria := a.materiaActiva(clave.codigo)
+		if okEstudiante && okMateria {
+			visitar(clave, e, m)
+		}
+	}
+}
+
+// inscripcionVigente indica si la inscripción existe, no está eliminada y ni su estudiante
+// ni su materia lo están
+func (a *almacenMemoria) inscripcionVigente(clave claveInscripcion) bool {
+	estado, ok := a.inscripciones[clave]
+	_, okEstudiante := a.estudianteActivo(clave.cedula)
+	_, okMateria := a.materiaActiva(clave.codigo)
+	return ok && estado.eliminadaEn == nil && okEstudiante && okMateria
+}
+
+// grafoPrerrequisitos arma el grafo con los prerrequisitos entre materias no eliminadas
+func (a *almacenMemoria) grafoPrerrequisitos() domain.GrafoPrerrequisitos {
+	var prerrequisitos []domain.Prerrequisito
+	for p := range a.prerrequisitos {
+		_, okMateria := a.materiaActiva(p.Materia)
+		_, okRequisito := a.materiaActiva(p.Requisito)
+		if okMateria && okRequisito {
+			prerrequisitos = append(prerrequisitos, p)
+		}
+	}
+	return domain.NewGrafoPrerrequisitos(prerrequisitos)
+}
+
+// inscritosVigentes cuenta las inscripciones vigentes de la materia en el periodo
+func (a *almacenMemoria) inscritosVigentes(periodo, codigo string) int {
+	inscritos := 0
+	a.inscripcionesVigentes(periodo, func(clave claveInscripcion, _ domain.Estudiante, _ domain.Materia) {
+		if clave.codigo == codigo {
+			inscritos++
+		}
+	})
+	return inscritos
+}
+
+// existeGrupo indica si la materia tiene el grupo; domain.SinGrupo existe siempre
+func (a *almacenMemoria) existeGrupo(codigo string, grupo int) bool {
+	return grupo == domain.SinGrupo || a.grupos[domain.Grupo{Materia: codigo, Numero: grupo}]
+}
+
+// periodoVigente retorna el periodo pedido, que debe existir, o el actual
+func (a *almacenMemoria) periodoVigente(pedido string) (string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if pedido == "" {
+		return a.periodoActual, nil
+	}
+	codigo, err := normalizarPeriodo(pedido)
+	if err != nil {
+		return "", err
+	}
+	if !a.periodos[codigo] {
+		return "", fmt.Errorf("%w: periodo %s", ErrNoEncontrado, codigo)
+	}
+	return codigo, nil
+}
+
+// vacia indica si la facultad no tiene datos de ninguna de las tablas del volcado, ni
+// siquiera eliminados; los periodos no cuentan, como en vaciaSQL
+func (a *almacenMemoria) vacia() bool {
+	return len(a.estudiantes)+len(a.materias)+len(a.inscripciones)+len(a.prerrequisitos)+
+		len(a.listaEspera)+len(a.grupos)+len(a.sesiones)+len(a.componentes)+len(a.calificaciones)+
+		len(a.carreras)+len(a.pensum)+len(a.carreraEstudiantes)+len(a.docentes)+len(a.asignacionesDocentes) == 0
+}
+
+// cargar valida el volcado completo antes de insertar, para que falle sin dejar datos a medias
+func (a *almacenMemoria) cargar(contexto ContextoAuditoria, datos *volcado) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.vacia() {
+		return ErrBaseNoVacia
+	}
+
+	estudiantes := make(map[string]bool)
+	for _, e := range datos.estudiantes {
+		if _, ok := a.estudiantes[e.Cedula]; ok || estudiantes[e.Cedula] {
+			return fmt.Errorf("error al cargar estudiante %s: %w", e.Cedula, ErrDuplicado)
+		}
+		estudiantes[e.Cedula] = true
+	}
+	materias := make(map[string]bool)
+	for _, m := range datos.materias {
+		if _, ok := a.materias[m.Codigo]; ok || materias[m.Codigo] {
+			return fmt.Errorf("error al cargar materia %s: %w", m.Codigo, ErrDuplicado)
+		}
+		materias[m.Codigo] = true
+	}
+	prerrequisitos := make(map[domain.Prerrequisito]bool)
+	for _, p := range datos.prerrequisitos {
+		if !materias[p.Materia] || !materias[p.Requisito] {
+			return fmt.Errorf("error al cargar %s como prerrequisito de %s: %w", p.Requisito, p.Materia, ErrReferenciaInvalida)
+		}
+		if prerrequisitos[p] {
+			return fmt.Errorf("error al cargar %s como prerrequisito de %s: %w", p.Requisito, p.Materia, ErrDuplicado)
+		}
+		prerrequisitos[p] = true
+	}
+	grupos := make(map[domain.Grupo]bool)
+	for _, g := range datos.grupos {
+		if !materias[g.Materia] {
+			return fmt.Errorf("error al cargar el grupo %d de %s: %w", g.Numero, g.Materia, ErrReferenciaInvalida)
+		}
+		if grupos[g] {
+			return fmt.Errorf("error al cargar el grupo %d de %s: %w", g.Numero, g.Materia, ErrDuplicado)
+		}
+		grupos[g] = true
+	}
+	sesiones := make(map[claveSesion]domain.Sesion)
+	for _, s := range datos.sesiones {
+		if !materias[s.Materia] {
+			return fmt.Errorf("error al cargar la sesión del %s de %s: %w", s.Franja(), s.Materia, ErrReferenciaInvalida)
+		}
+		if _, ok := sesiones[claveDeSesion(s)]; ok {
+			return fmt.Errorf("error al cargar la sesión del %s de %s: %w", s.Franja(), s.Materia, ErrDuplicado)
+		}
+		sesiones[claveDeSesion(s)] = s
+	}
+	componentes := make(map[claveComponente]int)
+	for _, c := range datos.componentes {
+		clave := claveComponente{materia: c.Materia, nombre: c.Nombre}
+		if !materias[c.Materia] {
+			return fmt.Errorf("error al cargar el componente %s de %s: %w", c.Nombre, c.Materia, ErrReferenciaInvalida)
+		}
+		if _, ok := componentes[clave]; ok {
+			return fmt.Errorf("error al cargar el componente %s de %s: %w", c.Nombre, c.Materia, ErrDuplicado)
+		}
+		componentes[clave] = c.Peso
+	}
+	inscripciones := make(map[claveInscripcion]bool)
+	for _, i := range datos.inscripciones {
+		clave := claveInscripcion{cedula: i.cedula, codigo: i.codigo, periodo: i.periodo}
+		if !estudiantes[i.cedula] || !materias[i.codigo] {
+			return fmt.Errorf("error al cargar inscripción %s-%s: %w", i.cedula, i.codigo, ErrReferenciaInvalida)
+		}
+		if _, ok := a.inscripciones[clave]; ok || inscripciones[clave] {
+			return fmt.Errorf("error al cargar inscripción %s-%s: %w", i.cedula, i.codigo, ErrDuplicado)
+		}
+		inscripciones[clave] = true
+	}
+	esperas := make(map[claveInscripcion]bool)
+	for _, e := range datos.esperas {
+		clave := claveInscripcion{cedula: e.cedula, codigo: e.codigo, periodo: e.periodo}
+		if !estudiantes[e.cedula] || !materias[e.codigo] {
+			return fmt.Errorf("error al cargar la espera de %s en %s: %w", e.cedula, e.codigo, ErrReferenciaInvalida)
+		}
+		if _, ok := a.listaEspera[clave]; ok || esperas[clave] {
+			return fmt.Errorf("error al cargar la espera de %s en %s: %w", e.cedula, e.codigo, ErrDuplicado)
+		}
+		esperas[clave] = true
+	}
+	calificaciones := make(map[claveCalificacion]bool)
+	for _, c := range datos.calificaciones {
+		clave := claveCalificacion{claveInscripcion{cedula: c.Cedula, codigo: c.Materia, periodo: c.Periodo}, c.Componente}
+		if _, ok := componentes[claveComponente{materia: c.Materia, nombre: c.Componente}]; !ok || !inscripciones[clave.claveInscripcion] {
+			return fmt.Errorf("error al cargar la nota de %s en %s (%s): %w", c.Cedula, c.Materia, c.Componente, ErrReferenciaInvalida)
+		}
+		if calificaciones[clave] {
+			return fmt.Errorf("error al cargar la nota de %s en %s (%s): %w", c.Cedula, c.Materia, c.Componente, ErrDuplicado)
+		}
+		calificaciones[clave] = true
+	}
+	carreras := make(map[string]bool)
+	for _, c := range datos.carreras {
+		if _, ok := a.carreras[c.Codigo]; ok || carreras[c.Codigo] {
+			return fmt.Errorf("error al cargar la carrera %s: %w", c.Codigo, ErrDuplicado)
+		}
+		carreras[c.Codigo] = true
+	}
+	pensum := make(map[clavePensum]bool)
+	for _, m := range datos.pensum {
+		clave := clavePensum{carrera: m.Carrera, materia: m.Materia}
+		if !carreras[m.Carrera] || !materias[m.Materia] {
+			return fmt.Errorf("error al cargar %s en el pensum de %s: %w", m.Materia, m.Carrera, ErrReferenciaInvalida)
+		}
+		if pensum[clave] {
+			return fmt.Errorf("error al cargar %s en el pensum de %s: %w", m.Materia, m.Carrera, ErrDuplicado)
+		}
+		pensum[clave] = true
+	}
+	asignados := make(map[string]bool)
+	for _, c := range datos.carreraEstudiantes {
+		if !estudiantes[c.Cedula] || !carreras[c.Carrera] {
+			return fmt.Errorf("error al cargar la carrera de %s: %w", c.Cedula, ErrReferenciaInvalida)
+		}
+		if asignados[c.Cedula] {
+			return fmt.Errorf("error al cargar la carrera de %s: %w", c.Cedula, ErrDuplicado)
+		}
+		asignados[c.Cedula] = true
+	}
+	docentes := make(map[string]bool)
+	for _, d := range datos.docentes {
+		if _, ok := a.docentes[d.Cedula]; ok || docentes[d.Cedula] {
+			return fmt.Errorf("error al cargar el docente %s: %w", d.Cedula, ErrDuplicado)
+		}
+		docentes[d.Cedula] = true
+	}
+	asignaciones := make(map[claveAsignacionDocente]bool)
+	for _, d := range datos.asignacionesDocentes {
+		clave := claveAsignacionDocente{periodo: d.Periodo, materia: d.Materia, grupo: d.Grupo}
+		if !docentes[d.Docente] || !materias[d.Materia] {
+			return fmt.Errorf("error al cargar el docente de %s: %w", d.Materia, ErrReferenciaInvalida)
+		}
+		if asignaciones[clave] {
+			return fmt.Errorf("error al cargar el docente de %s: %w", d.Materia, ErrDuplicado)
+		}
+		asignaciones[clave] = true
+	}
+
+	for _, e := range datos.estudiantes {
+		cargado := *e
+		cargado.Version = 1
+		a.estudiantes[e.Cedula] = cargado
+		for _, c := range cambiosCargados(cambioEstudiante(domain.OperacionCrear, nil, e), cambioEstudiante(domain.OperacionEliminar, e, nil), e.EliminadoEn) {
+			a.registrar(contexto, c)
+		}
+	}
+	for _, m := range datos.materias {
+		cargada := *m
+		cargada.Version = 1
+		a.materias[m.Codigo] = cargada
+		for _, c := range cambiosCargados(cambioMateria(domain.OperacionCrear, nil, m), cambioMateria(domain.OperacionEliminar, m, nil), m.EliminadoEn) {
+			a.registrar(contexto, c)
+		}
+	}
+	for p := range prerrequisitos {
+		a.prerrequisitos[p] = true
+	}
+	for g := range grupos {
+		a.grupos[g] = true
+	}
+	for clave, s := range sesiones {
+		a.sesiones[clave] = s
+	}
+	for clave, peso := range componentes {
+		a.componentes[clave] = peso
+	}
+	for _, periodo := range datos.periodosUsados() {
+		a.periodos[periodo] = true
+	}
+	if actual := datos.periodoActual(); actual != "" {
+		a.periodoActual = actual
+	}
+	for _, i := range datos.inscripciones {
+		a.inscripciones[claveInscripcion{cedula: i.cedula, codigo: i.codigo, periodo: i.periodo}] = estadoInscripcion{grupo: i.grupo, eliminadaEn: i.eliminadaEn}
+		for _, c := range cambiosCargados(cambioInscripcion(domain.OperacionCrear, i.cedula, i.codigo, i.periodo), cambioInscripcion(domain.OperacionEliminar, i.cedula, i.codigo, i.periodo), i.eliminadaEn) {
+			a.registrar(contexto, c)
+		}
+	}
+	for _, e := range datos.esperas {
+		a.listaEspera[claveInscripcion{cedula: e.cedula, codigo: e.codigo, periodo: e.periodo}] = estadoEspera{orden: e.orden, grupo: e.grupo}
+	}
+	for _, c := range datos.calificaciones {
+		a.calificaciones[claveCalificacion{claveInscripcion{cedula: c.Cedula, codigo: c.Materia, periodo: c.Periodo}, c.Componente}] = domain.Centesimas(c.Nota)
+		a.registrar(contexto, cambioCalificacion(domain.OperacionCrear, c.Cedula, c.Materia, c.Periodo, c.Componente, nil, &c.Nota))
+	}
+	for _, c := range datos.carreras {
+		a.carreras[c.Codigo] = *c
+	}
+	for _, m := range datos.pensum {
+		a.pensum[clavePensum{carrera: m.Carrera, materia: m.Materia}] = m
+	}
+	for _, c := range datos.carreraEstudiantes {
+		a.carreraEstudiantes[c.Cedula] = c.Carrera
+		a.registrar(contexto, cambioCarreraEstudiante(domain.OperacionCrear, c.Cedula, "", c.Carrera))
+	}
+	for _, d := range datos.docentes {
+		a.docentes[d.Cedula] = *d
+	}
+	for _, d := range datos.asignacionesDocentes {
+		a.asignacionesDocentes[claveAsignacionDocente{periodo: d.Periodo, materia: d.Materia, grupo: d.Grupo}] = d.Docente
+	}
+	return nil
+}
+
+type estudianteMemoria struct {
+	almacen   *almacenMemoria
+	auditoria ContextoAuditoria
+}
+
+func (r *estudianteMemoria) Create(estudiante *domain.Estudiante) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if existente, ok := r.almacen.estudiantes[estudiante.Cedula]; ok {
+		if existente.EliminadoEn != nil {
+			return ErrEliminado
+		}
+		return ErrDuplicado
+	}
+	nuevo := *estudiante
+	nuevo.EliminadoEn = nil
+	nuevo.Version = 1
+	r.almacen.estudiantes[estudiante.Cedula] = nuevo
+	estudiante.Version = 1
+	r.almacen.registrar(r.auditoria, cambioEstudiante(domain.OperacionCrear, nil, estudiante))
+	return nil
+}
+
+func (r *estudianteMemoria) GetByCedula(cedula string) (*domain.Estudiante, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	e, ok := r.almacen.estudianteActivo(cedula)
+	if !ok {
+		return nil, nil
+	}
+	return &e, nil
+}
+
+func (r *estudianteMemoria) GetAll() ([]*domain.Estudiante, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var estudiantes []*domain.Estudiante
+	for _, e := range r.almacen.estudiantes {
+		if e.EliminadoEn == nil {
+			estudiantes = append(estudiantes, &e)
+		}
+	}
+	sort.Slice(estudiantes, func(i, j int) bool { return estudiantes[i].Cedula < estudiantes[j].Cedula })
+	return estudiantes, nil
+}
+
+func (r *estudianteMemoria) Exists(cedula string) (bool, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	_, ok := r.almacen.estudianteActivo(cedula)
+	return ok, nil
+}
+
+type materiaMemoria struct {
+	almacen   *almacenMemoria
+	auditoria ContextoAuditoria
+}
+
+func (r *materiaMemoria) Create(materia *domain.Materia) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if existente, ok := r.almacen.materias[materia.Codigo]; ok {
+		if existente.EliminadoEn != nil {
+			return ErrEliminado
+		}
+		return ErrDuplicado
+	}
+	nueva := *materia
+	nueva.EliminadoEn = nil
+	nueva.Version = 1
+	r.almacen.materias[materia.Codigo] = nueva
+	materia.Version = 1
+	r.almacen.registrar(r.auditoria, cambioMateria(domain.OperacionCrear, nil, materia))
+	return nil
+}
+
+func (r *materiaMemoria) GetByCodigo(codigo string) (*domain.Materia, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	m, ok := r.almacen.materiaActiva(codigo)
+	if !ok {
+		return nil, nil
+	}
+	return &m, nil
+}
+
+func (r *materiaMemoria) GetAll() ([]*domain.Materia, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var materias []*domain.Materia
+	for _, m := range r.almacen.materias {
+		if m.EliminadoEn == nil {
+			materias = append(materias, &m)
+		}
+	}
+	sort.Slice(materias, func(i, j int) bool { return materias[i].Codigo < materias[j].Codigo })
+	return materias, nil
+}
+
+func (r *materiaMemoria) Exists(codigo string) (bool, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	_, ok := r.almacen.materiaActiva(codigo)
+	return ok, nil
+}
+
+type inscripcionMemoria struct {
+	almacen   *almacenMemoria
+	periodo   string
+	auditoria ContextoAuditoria
+}
+
+func (r *inscripcionMemoria) Create(estudianteCedula, materiaCodigo string) error {
+	return r.CreateEnGrupo(estudianteCedula, materiaCodigo, domain.SinGrupo)
+}
+
+func (r *inscripcionMemoria) CreateEnGrupo(estudianteCedula, materiaCodigo string, grupo int) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	_, okEstudiante := r.almacen.estudianteActivo(estudianteCedula)
+	materia, okMateria := r.almacen.materiaActiva(materiaCodigo)
+	if !okEstudiante || !okMateria {
+		return ErrReferenciaInvalida
+	}
+
+	clave := claveInscripcion{cedula: estudianteCedula, codigo: materiaCodigo, periodo: r.periodo}
+	if estado, ok := r.almacen.inscripciones[clave]; ok {
+		if estado.eliminadaEn != nil {
+			return ErrEliminado
+		}
+		return ErrDuplicado
+	}
+	if !r.almacen.existeGrupo(materiaCodigo, grupo) {
+		return fmt.Errorf("%w: la materia %s no tiene grupo %d", ErrReferenciaInvalida, materiaCodigo, grupo)
+	}
+	if domain.CupoAgotado(materia.Cupo, r.almacen.inscritosVigentes(r.periodo, materiaCodigo)) {
+		return fmt.Errorf("%w: materia %s", ErrCupoAgotado, materiaCodigo)
+	}
+	r.almacen.inscripciones[clave] = estadoInscripcion{grupo: grupo}
+	r.almacen.registrar(r.auditoria, cambioInscripcion(domain.OperacionCrear, estudianteCedula, materiaCodigo, r.periodo))
+	return nil
+}
+
+func (r *inscripcionMemoria) CambiarGrupo(estudianteCedula, materiaCodigo string, grupo int) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	clave := claveInscripcion{cedula: estudianteCedula, codigo: materiaCodigo, periodo: r.periodo}
+	estado, ok := r.almacen.inscripciones[clave]
+	if !ok || estado.eliminadaEn != nil {
+		return fmt.Errorf("%w: %s no está inscrito en %s", ErrNoEncontrado, estudianteCedula, materiaCodigo)
+	}
+	if !r.almacen.existeGrupo(materiaCodigo, grupo) {
+		return fmt.Errorf("%w: la materia %s no tiene grupo %d", ErrReferenciaInvalida, materiaCodigo, grupo)
+	}
+	anterior := estado.grupo
+	estado.grupo = grupo
+	r.almacen.inscripciones[clave] = estado
+	r.almacen.registrar(r.auditoria, cambioGrupoInscripcion(estudianteCedula, materiaCodigo, r.periodo, anterior, grupo))
+	return nil
+}
+
+func (r *inscripcionMemoria) GetByEstudiante(cedula string) ([]*domain.Materia, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var materias []*domain.Materia
+	r.almacen.inscripcionesVigentes(r.periodo, func(clave claveInscripcion, _ domain.Estudiante, m domain.Materia) {
+		if clave.cedula == cedula {
+			materias = append(materias, &m)
+		}
+	})
+	sort.Slice(materias, func(i, j int) bool { return materias[i].Codigo < materias[j].Codigo })
+	return materias, nil
+}
+
+func (r *inscripcionMemoria) GetByMateria(codigo string) ([]*domain.Estudiante, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var estudiantes []*domain.Estudiante
+	r.almacen.inscripcionesVigentes(r.periodo, func(clave claveInscripcion, e domain.Estudiante, _ domain.Materia) {
+		if clave.codigo == codigo {
+			estudiantes = append(estudiantes, &e)
+		}
+	})
+	sort.Slice(estudiantes, func(i, j int) bool { return estudiantes[i].Cedula < estudiantes[j].Cedula })
+	return estudiantes, nil
+}
+
+func (r *inscripcionMemoria) GetByGrupo(codigo string, grupo int) ([]*domain.Estudiante, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var estudiantes []*domain.Estudiante
+	r.almacen.inscripcionesVigentes(r.periodo, func(clave claveInscripcion, e domain.Estudiante, _ domain.Materia) {
+		if clave.codigo == codigo && r.almacen.inscripciones[clave].grupo == grupo {
+			estudiantes = append(estudiantes, &e)
+		}
+	})
+	sort.Slice(estudiantes, func(i, j int) bool { return estudiantes[i].Cedula < estudiantes[j].Cedula })
+	return estudiantes, nil
+}
+
+func (r *inscripcionMemoria) CountByEstudiante(cedula string) (int, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	count := 0
+	r.almacen.inscripcionesVigentes(r.periodo, func(clave claveInscripcion, _ domain.Estudiante, _ domain.Materia) {
+		if clave.cedula == cedula {
+			count++
+		}
+	})
+	return count, nil
+}
+
+func (r *inscripcionMemoria) Exists(estudianteCedula, materiaCodigo string) (bool, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	existe := false
+	r.almacen.inscripcionesVigentes(r.periodo, func(clave claveInscripcion, _ domain.Estudiante, _ domain.Materia) {
+		existe = existe || (clave.cedula == estudianteCedula && clave.codigo == materiaCodigo)
+	})
+	return existe, nil
+}
+
+func (r *inscripcionMemoria) GetAll() ([]*domain.Inscripcion, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var inscripciones []*domain.Inscripcion
+	r.almacen.inscripcionesVigentes(r.periodo, func(clave claveInscripcion, e domain.Estudiante, m domain.Materia) {
+		grupo := r.almacen.inscripciones[clave].grupo
+		inscripciones = append(inscripciones, &domain.Inscripcion{Estudiante: &e, Materia: &m, Periodo: r.periodo, Grupo: grupo})
+	})
+	sort.Slice(inscripciones, func(i, j int) bool {
+		a, b := inscripciones[i], inscripciones[j]
+		if a.Estudiante.Cedula != b.Estudiante.Cedula {
+			return a.Estudiante.Cedula < b.Estudiante.Cedula
+		}
+		return a.Materia.Codigo < b.Materia.Codigo
+	})
+	return inscripciones, nil
+}
+
+func (r *inscripcionMemoria) HistorialByEstudiante(cedula string) ([]*domain.Inscripcion, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	e, ok := r.almacen.estudianteActivo(cedula)
+	if !ok {
+		return nil, nil
+	}
+	var inscripciones []*domain.Inscripcion
+	for clave, estado := range r.almacen.inscripciones {
+		if clave.cedula != cedula || estado.eliminadaEn != nil {
+			continue
+		}
+		if m, ok := r.almacen.materiaActiva(clave.codigo); ok {
+			inscripciones = append(inscripciones, &domain.Inscripcion{Estudiante: &e, Materia: &m, Periodo: clave.periodo, Grupo: estado.grupo})
+		}
+	}
+	sort.Slice(inscripciones, func(i, j int) bool {
+		a, b := inscripciones[i], inscripciones[j]
+		if a.Periodo != b.Periodo {
+			return a.Periodo < b.Periodo
+		}
+		return a.Materia.Codigo < b.Materia.Codigo
+	})
+	return inscripciones, nil
+}
+
+func (r *inscripcionMemoria) CountGroupedByEstudiante() (map[string]int, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	conteos := make(map[string]int)
+	r.almacen.inscripcionesVigentes(r.periodo, func(clave claveInscripcion, _ domain.Estudiante, _ domain.Materia) {
+		conteos[clave.cedula]++
+	})
+	return conteos, nil
+}
+
+func (r *inscripcionMemoria) CreditosGroupedByEstudiante() (map[string]int, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	creditos := make(map[string]int)
+	r.almacen.inscripcionesVigentes(r.periodo, func(clave claveInscripcion, _ domain.Estudiante, m domain.Materia) {
+		creditos[clave.cedula] += m.Creditos
+	})
+	return creditos, nil
+}
+
+func (r *inscripcionMemoria) CountGroupedByMateria() (map[string]int, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	conteos := make(map[string]int)
+	r.almacen.inscripcionesVigentes(r.periodo, func(clave claveInscripcion, _ domain.Estudiante, _ domain.Materia) {
+		conteos[clave.codigo]++
+	})
+	return conteos, nil
+}
+
+func (r *estudianteMemoria) List(consulta Consulta) (*Pagina[*domain.Estudiante], error) {
+	columnas, err := columnasOrdenEstudiantes(consulta.Orden, "")
+	if err != nil {
+		return nil, err
+	}
+
+	r.almacen.mu.RLock()
+	var estudiantes []*domain.Estudiante
+	for _, e := range r.almacen.estudiantes {
+		if consulta.Eliminados.incluye(e.EliminadoEn != nil) && consulta.coincide(e.Nombre, e.Cedula) {
+			estudiantes = append(estudiantes, &e)
+		}
+	}
+	r.almacen.mu.RUnlock()
+
+	return paginarEnMemoria(estudiantes, len(columnas), clavesEstudiante(consulta.Orden), consulta)
+}
+
+func (r *materiaMemoria) List(consulta Consulta) (*Pagina[*domain.Materia], error) {
+	columnas, err := columnasOrdenMaterias(consulta.Orden, "")
+	if err != nil {
+		return nil, err
+	}
+
+	r.almacen.mu.RLock()
+	var materias []*domain.Materia
+	for _, m := range r.almacen.materias {
+		if consulta.Eliminados.incluye(m.EliminadoEn != nil) && consulta.coincide(m.Nombre, m.Codigo) {
+			materias = append(materias, &m)
+		}
+	}
+	r.almacen.mu.RUnlock()
+
+	return paginarEnMemoria(materias, len(columnas), clavesMateria(consulta.Orden), consulta)
+}
+
+func (r *inscripcionMemoria) List(consulta Consulta) (*Pagina[*domain.Inscripcion], error) {
+	columnas, err := columnasOrdenInscripciones(consulta.Orden)
+	if err != nil {
+		return nil, err
+	}
+
+	r.almacen.mu.RLock()
+	var inscripciones []*domain.Inscripcion
+	for clave, estado := range r.almacen.inscripciones {
+		if clave.periodo != r.periodo {
+			continue
+		}
+		e := r.almacen.estudiantes[clave.cedula]
+		m := r.almacen.materias[clave.codigo]
+		eliminada := estado.eliminadaEn != nil || e.EliminadoEn != nil || m.EliminadoEn != nil
+		if consulta.Eliminados.incluye(eliminada) && consulta.coincide(e.Nombre, m.Codigo) {
+			inscripciones = append(inscripciones, &domain.Inscripcion{Estudiante: &e, Materia: &m, Periodo: r.periodo, Grupo: estado.grupo, EliminadaEn: estado.eliminadaEn})
+		}
+	}
+	r.almacen.mu.RUnlock()
+
+	return paginarEnMemoria(inscripciones, len(columnas), clavesInscripcion(consulta.Orden), consulta)
+}
+
+func (r *inscripcionMemoria) ListByEstudiante(cedula string, consulta Consulta) (*Pagina[*domain.Materia], error) {
+	columnas, err := columnasOrdenMaterias(consulta.Orden, "")
+	if err != nil {
+		return nil, err
+	}
+
+	r.almacen.mu.RLock()
+	var materias []*domain.Materia
+	_, activo := r.almacen.estudianteActivo(cedula)
+	for clave, estado := range r.almacen.inscripciones {
+		if !activo || clave.cedula != cedula || clave.periodo != r.periodo {
+			continue
+		}
+		m := r.almacen.materias[clave.codigo]
+		eliminada := estado.eliminadaEn != nil || m.EliminadoEn != nil
+		if consulta.Eliminados.incluye(eliminada) && consulta.coincide(m.Nombre, m.Codigo) {
+			materias = append(materias, &m)
+		}
+	}
+	r.almacen.mu.RUnlock()
+
+	return paginarEnMemoria(materias, len(columnas), clavesMateria(consulta.Orden), consulta)
+}
+
+func (r *inscripcionMemoria) ListByMateria(codigo string, consulta Consulta) (*Pagina[*domain.Estudiante], error) {
+	columnas, err := columnasOrdenEstudiantes(consulta.Orden, "")
+	if err != nil {
+		return nil, err
+	}
+
+	r.almacen.mu.RLock()
+	var estudiantes []*domain.Estudiante
+	_, activa := r.almacen.materiaActiva(codigo)
+	for clave, estado := range r.almacen.inscripciones {
+		if !activa || clave.codigo != codigo || clave.periodo != r.periodo {
+			continue
+		}
+		e := r.almacen.estudiantes[clave.cedula]
+		eliminada := estado.eliminadaEn != nil || e.EliminadoEn != nil
+		if consulta.Eliminados.incluye(eliminada) && consulta.coincide(e.Nombre, e.Cedula) {
+			estudiantes = append(estudiantes, &e)
+		}
+	}
+	r.almacen.mu.RUnlock()
+
+	return paginarEnMemoria(estudiantes, len(columnas), clavesEstudiante(consulta.Orden), consulta)
+}
+
+func (r *estudianteMemoria) Search(texto string, limite int) ([]*domain.Estudiante, error) {
+	terminos := palabras(texto)
+	if len(terminos) == 0 {
+		return nil, nil
+	}
+
+	r.almacen.mu.RLock()
+	var resultados []resultadoBusqueda
+	for _, e := range r.almacen.estudiantes {
+		if e.EliminadoEn != nil {
+			continue
+		}
+		if puntaje, ok := puntuar(e.Nombre, terminos); ok {
+			resultados = append(resultados, resultadoBusqueda{clave: e.Cedula, nombre: e.Nombre, version: e.Version, puntaje: puntaje})
+		}
+	}
+	r.almacen.mu.RUnlock()
+
+	var encontrados []*domain.Estudiante
+	for _, res := range ordenarResultados(resultados, limiteBusqueda(limite)) {
+		encontrados = append(encontrados, &domain.Estudiante{Cedula: res.clave, Nombre: res.nombre, Version: res.version})
+	}
+	return encontrados, nil
+}
+
+func (r *materiaMemoria) Search(texto string, limite int) ([]*domain.Materia, error) {
+	terminos := palabras(texto)
+	if len(terminos) == 0 {
+		return nil, nil
+	}
+
+	r.almacen.mu.RLock()
+	var resultados []resultadoBusqueda
+	for _, m := range r.almacen.materias {
+		if m.EliminadoEn != nil {
+			continue
+		}
+		if puntaje, ok := puntuar(m.Nombre, terminos); ok {
+			resultados = append(resultados, resultadoBusqueda{clave: m.Codigo, nombre: m.Nombre, version: m.Version, puntaje: puntaje})
+		}
+	}
+	r.almacen.mu.RUnlock()
+
+	var encontrados []*domain.Materia
+	for _, res := range ordenarResultados(resultados, limiteBusqueda(limite)) {
+		encontrados = append(encontrados, &domain.Materia{Codigo: res.clave, Nombre: res.nombre, Version: res.version})
+	}
+	return encontrados, nil
+}
+
+func (r *estudianteMemoria) Delete(cedula string) error {
+	return r.cambiarEliminado(cedula, true)
+}
+
+func (r *estudianteMemoria) Restore(cedula string) error {
+	return r.cambiarEliminado(cedula, false)
+}
+
+func (r *estudianteMemoria) cambiarEliminado(cedula string, eliminar bool) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	e, ok := r.almacen.estudiantes[cedula]
+	if !ok || (e.EliminadoEn == nil) != eliminar {
+		return ErrNoEncontrado
+	}
+	antes := e
+	if eliminar {
+		fecha, _ := ahora()
+		e.EliminadoEn = &fecha
+		r.almacen.registrar(r.auditoria, cambioEstudiante(domain.OperacionEliminar, &antes, nil))
+	} else {
+		e.EliminadoEn = nil
+		r.almacen.registrar(r.auditoria, cambioEstudiante(domain.OperacionRestaurar, nil, &e))
+	}
+	r.almacen.estudiantes[cedula] = e
+	return nil
+}
+
+func (r *materiaMemoria) Delete(codigo string) error {
+	return r.cambiarEliminado(codigo, true)
+}
+
+func (r *materiaMemoria) Restore(codigo string) error {
+	return r.cambiarEliminado(codigo, false)
+}
+
+func (r *materiaMemoria) cambiarEliminado(codigo string, eliminar bool) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	m, ok := r.almacen.materias[codigo]
+	if !ok || (m.EliminadoEn == nil) != eliminar {
+		return ErrNoEncontrado
+	}
+	antes := m
+	if eliminar {
+		fecha, _ := ahora()
+		m.EliminadoEn = &fecha
+		r.almacen.registrar(r.auditoria, cambioMateria(domain.OperacionEliminar, &antes, nil))
+	} else {
+		m.EliminadoEn = nil
+		r.almacen.registrar(r.auditoria, cambioMateria(domain.OperacionRestaurar, nil, &m))
+	}
+	r.almacen.materias[codigo] = m
+	return nil
+}
+
+func (r *estudianteMemoria) Update(estudiante *domain.Estudiante) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	antes, ok := r.almacen.estudianteActivo(estudiante.Cedula)
+	if !ok {
+		return ErrNoEncontrado
+	}
+	if antes.Version != estudiante.Version {
+		return fmt.Errorf("%w: estudiante %s (versión %d, se esperaba %d)", ErrConflicto, estudiante.Cedula, antes.Version, estudiante.Version)
+	}
+	nuevo := antes
+	nuevo.Nombre = estudiante.Nombre
+	nuevo.Version++
+	r.almacen.estudiantes[estudiante.Cedula] = nuevo
+	r.almacen.registrar(r.auditoria, cambioEstudiante(domain.OperacionActualizar, &antes, &nuevo))
+	estudiante.Version = nuevo.Version
+	return nil
+}
+
+func (r *materiaMemoria) Update(materia *domain.Materia) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	antes, ok := r.almacen.materiaActiva(materia.Codigo)
+	if !ok {
+		return ErrNoEncontrado
+	}
+	if antes.Version != materia.Version {
+		return fmt.Errorf("%w: materia %s (versión %d, se esperaba %d)", ErrConflicto, materia.Codigo, antes.Version, materia.Version)
+	}
+	nueva := antes
+	nueva.Nombre = materia.Nombre
+	nueva.Creditos = materia.Creditos
+	nueva.Cupo = materia.Cupo
+	nueva.Version++
+	r.almacen.materias[materia.Codigo] = nueva
+	r.almacen.registrar(r.auditoria, cambioMateria(domain.OperacionActualizar, &antes, &nueva))
+	materia.Version = nueva.Version
+	return nil
+}
+
+func (r *inscripcionMemoria) Delete(estudianteCedula, materiaCodigo string) error {
+	return r.cambiarEliminada(estudianteCedula, materiaCodigo, true)
+}
+
+func (r *inscripcionMemoria) Restore(estudianteCedula, materiaCodigo string) error {
+	return r.cambiarEliminada(estudianteCedula, materiaCodigo, false)
+}
+
+func (r *inscripcionMemoria) cambiarEliminada(estudianteCedula, materiaCodigo string, eliminar bool) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	clave := claveInscripcion{cedula: estudianteCedula, codigo: materiaCodigo, periodo: r.periodo}
+	estado, ok := r.almacen.inscripciones[clave]
+	if !ok || (estado.eliminadaEn == nil) != eliminar {
+		return ErrNoEncontrado
+	}
+	if materia, ok := r.almacen.materiaActiva(materiaCodigo); ok && !eliminar &&
+		domain.CupoAgotado(materia.Cupo, r.almacen.inscritosVigentes(r.periodo, materiaCodigo)) {
+		return fmt.Errorf("%w: materia %s", ErrCupoAgotado, materiaCodigo)
+	}
+	operacion := domain.OperacionRestaurar
+	estado.eliminadaEn = nil
+	if eliminar {
+		fecha, _ := ahora()
+		estado.eliminadaEn = &fecha
+		operacion = domain.OperacionEliminar
+	}
+	r.almacen.inscripciones[clave] = estado
+	r.almacen.registrar(r.auditoria, cambioInscripcion(operacion, estudianteCedula, materiaCodigo, r.periodo))
+	return nil
+}
+
+type periodoMemoria struct {
+	almacen *almacenMemoria
+}
+
+func (r *periodoMemoria) Create(codigo string) error {
+	codigo, err := normalizarPeriodo(codigo)
+	if err != nil {
+		return err
+	}
+
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if r.almacen.periodos[codigo] {
+		return fmt.Errorf("error al crear periodo %s: %w", codigo, ErrDuplicado)
+	}
+	r.almacen.periodos[codigo] = true
+	return nil
+}
+
+func (r *periodoMemoria) GetAll() ([]*domain.Periodo, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var periodos []*domain.Periodo
+	for codigo := range r.almacen.periodos {
+		periodos = append(periodos, &domain.Periodo{Codigo: codigo, Actual: codigo == r.almacen.periodoActual})
+	}
+	sort.Slice(periodos, func(i, j int) bool { return periodos[i].Codigo < periodos[j].Codigo })
+	return periodos, nil
+}
+
+func (r *periodoMemoria) Exists(codigo string) (bool, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	return r.almacen.periodos[codigo], nil
+}
+
+func (r *periodoMemoria) Actual() (string, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	return r.almacen.periodoActual, nil
+}
+
+func (r *periodoMemoria) EstablecerActual(codigo string) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if !r.almacen.periodos[codigo] {
+		return fmt.Errorf("%w: periodo %s", ErrNoEncontrado, codigo)
+	}
+	r.almacen.periodoActual = codigo
+	return nil
+}
+
+type prerrequisitoMemoria struct {
+	almacen *almacenMemoria
+}
+
+func (r *prerrequisitoMemoria) Create(p domain.Prerrequisito) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	for _, codigo := range []string{p.Materia, p.Requisito} {
+		if _, ok := r.almacen.materiaActiva(codigo); !ok {
+			return fmt.Errorf("error al agregar %s como prerrequisito de %s: %w: materia %s", p.Requisito, p.Materia, ErrReferenciaInvalida, codigo)
+		}
+	}
+	if r.almacen.grafoPrerrequisitos().CreaCiclo(p) {
+		return fmt.Errorf("error al agregar %s como prerrequisito de %s: %w", p.Requisito, p.Materia, ErrCiclo)
+	}
+	if r.almacen.prerrequisitos[p] {
+		return fmt.Errorf("error al agregar %s como prerrequisito de %s: %w", p.Requisito, p.Materia, ErrDuplicado)
+	}
+	r.almacen.prerrequisitos[p] = true
+	return nil
+}
+
+func (r *prerrequisitoMemoria) Delete(p domain.Prerrequisito) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if !r.almacen.prerrequisitos[p] {
+		return fmt.Errorf("%w: %s no es prerrequisito de %s", ErrNoEncontrado, p.Requisito, p.Materia)
+	}
+	delete(r.almacen.prerrequisitos, p)
+	return nil
+}
+
+func (r *prerrequisitoMemoria) GetGrafo() (domain.GrafoPrerrequisitos, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	return r.almacen.grafoPrerrequisitos(), nil
+}
+
+func (r *prerrequisitoMemoria) GetAll() ([]domain.Prerrequisito, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	prerrequisitos := make([]domain.Prerrequisito, 0, len(r.almacen.prerrequisitos))
+	for p := range r.almacen.prerrequisitos {
+		prerrequisitos = append(prerrequisitos, p)
+	}
+	sort.Slice(prerrequisitos, func(i, j int) bool {
+		a, b := prerrequisitos[i], prerrequisitos[j]
+		if a.Materia != b.Materia {
+			return a.Materia < b.Materia
+		}
+		return a.Requisito < b.Requisito
+	})
+	return prerrequisitos, nil
+}
+
+type listaEsperaMemoria struct {
+	almacen *almacenMemoria
+	periodo string
+}
+
+func (r *listaEsperaMemoria) Agregar(estudianteCedula, materiaCodigo string, grupo int) (int, error) {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	_, okEstudiante := r.almacen.estudianteActivo(estudianteCedula)
+	_, okMateria := r.almacen.materiaActiva(materiaCodigo)
+	if !okEstudiante || !okMateria {
+		return 0, fmt.Errorf("error al poner a %s en la lista de espera de %s: %w", estudianteCedula, materiaCodigo, ErrReferenciaInvalida)
+	}
+	if !r.almacen.existeGrupo(materiaCodigo, grupo) {
+		return 0, fmt.Errorf("error al poner a %s en la lista de espera de %s: %w: la materia no tiene grupo %d",
+			estudianteCedula, materiaCodigo, ErrReferenciaInvalida, grupo)
+	}
+	clave := claveInscripcion{cedula: estudianteCedula, codigo: materiaCodigo, periodo: r.periodo}
+	if _, ok := r.almacen.listaEspera[clave]; ok {
+		return 0, fmt.Errorf("error al poner a %s en la lista de espera de %s: %w", estudianteCedula, materiaCodigo, ErrDuplicado)
+	}
+	orden := 0
+	for k, e := range r.almacen.listaEspera {
+		if k.codigo == materiaCodigo && k.periodo == r.periodo && e.orden > orden {
+			orden = e.orden
+		}
+	}
+	r.almacen.listaEspera[clave] = estadoEspera{orden: orden + 1, grupo: grupo}
+	return len(r.esperas(materiaCodigo, true)), nil
+}
+
+func (r *listaEsperaMemoria) Quitar(estudianteCedula, materiaCodigo string) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	clave := claveInscripcion{cedula: estudianteCedula, codigo: materiaCodigo, periodo: r.periodo}
+	if _, ok := r.almacen.listaEspera[clave]; !ok {
+		return fmt.Errorf("%w: %s no está en la lista de espera de %s", ErrNoEncontrado, estudianteCedula, materiaCodigo)
+	}
+	delete(r.almacen.listaEspera, clave)
+	return nil
+}
+
+func (r *listaEsperaMemoria) GetByMateria(materiaCodigo string) ([]*domain.Espera, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	return r.esperas(materiaCodigo, true), nil
+}
+
+func (r *listaEsperaMemoria) Posicion(estudianteCedula, materiaCodigo string) (int, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	for _, espera := range r.esperas(materiaCodigo, true) {
+		if espera.Estudiante.Cedula == estudianteCedula {
+			return espera.Posicion, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *listaEsperaMemoria) CountGroupedByMateria() (map[string]int, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	conteos := make(map[string]int)
+	for _, espera := range r.esperas("", true) {
+		conteos[espera.Codigo]++
+	}
+	return conteos, nil
+}
+
+func (r *listaEsperaMemoria) GetAll() ([]*domain.Espera, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	return r.esperas("", false), nil
+}
+
+// esperas retorna las esperas del periodo, de la materia indicada o de todas si está vacía,
+// ordenadas por materia y orden de llegada y numeradas dentro de cada materia; con vigentes
+// omite las de estudiantes y materias eliminados. Se llama con el candado tomado.
+func (r *listaEsperaMemoria) esperas(materiaCodigo string, vigentes bool) []*domain.Espera {
+	type fila struct {
+		clave claveInscripcion
+		estadoEspera
+	}
+	var filas []fila
+	for clave, estado := range r.almacen.listaEspera {
+		if clave.periodo != r.periodo || (materiaCodigo != "" && clave.codigo != materiaCodigo) {
+			continue
+		}
+		if vigentes {
+			_, okEstudiante := r.almacen.estudianteActivo(clave.cedula)
+			_, okMateria := r.almacen.materiaActiva(clave.codigo)
+			if !okEstudiante || !okMateria {
+				continue
+			}
+		}
+		filas = append(filas, fila{clave: clave, estadoEspera: estado})
+	}
+	sort.Slice(filas, func(i, j int) bool {
+		if filas[i].clave.codigo != filas[j].clave.codigo {
+			return filas[i].clave.codigo < filas[j].clave.codigo
+		}
+		return filas[i].orden < filas[j].orden
+	})
+
+	esperas := make([]*domain.Espera, 0, len(filas))
+	for _, f := range filas {
+		e := r.almacen.estudiantes[f.clave.cedula]
+		espera := &domain.Espera{Estudiante: &e, Codigo: f.clave.codigo, Periodo: r.periodo, Grupo: f.grupo, Posicion: 1}
+		if n := len(esperas); n > 0 && esperas[n-1].Codigo == espera.Codigo {
+			espera.Posicion = esperas[n-1].Posicion + 1
+		}
+		esperas = append(esperas, espera)
+	}
+	return esperas
+}
+
+type grupoMemoria struct {
+	almacen *almacenMemoria
+}
+
+func (r *grupoMemoria) Create(g domain.Grupo) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.materiaActiva(g.Materia); !ok {
+		return fmt.Errorf("error al crear el grupo %d de %s: %w: materia %s", g.Numero, g.Materia, ErrReferenciaInvalida, g.Materia)
+	}
+	if r.almacen.grupos[g] {
+		return fmt.Errorf("error al crear el grupo %d de %s: %w", g.Numero, g.Materia, ErrDuplicado)
+	}
+	r.almacen.grupos[g] = true
+	return nil
+}
+
+func (r *grupoMemoria) Delete(g domain.Grupo) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if !r.almacen.grupos[g] {
+		return fmt.Errorf("error al eliminar el grupo %d de %s: %w", g.Numero, g.Materia, ErrNoEncontrado)
+	}
+	for clave, estado := range r.almacen.inscripciones {
+		if clave.codigo == g.Materia && estado.grupo == g.Numero {
+			return fmt.Errorf("error al eliminar el grupo %d de %s: %w", g.Numero, g.Materia, ErrGrupoConInscritos)
+		}
+	}
+	for clave, espera := range r.almacen.listaEspera {
+		if clave.codigo == g.Materia && espera.grupo == g.Numero {
+			return fmt.Errorf("error al eliminar el grupo %d de %s: %w", g.Numero, g.Materia, ErrGrupoConInscritos)
+		}
+	}
+	for clave := range r.almacen.sesiones {
+		if clave.materia == g.Materia && clave.grupo == g.Numero {
+			delete(r.almacen.sesiones, clave)
+		}
+	}
+	for clave := range r.almacen.asignacionesDocentes {
+		if clave.materia == g.Materia && clave.grupo == g.Numero {
+			delete(r.almacen.asignacionesDocentes, clave)
+		}
+	}
+	delete(r.almacen.grupos, g)
+	return nil
+}
+
+func (r *grupoMemoria) GetByMateria(materiaCodigo string) ([]domain.Grupo, error) {
+	return r.grupos(materiaCodigo), nil
+}
+
+func (r *grupoMemoria) GetAll() ([]domain.Grupo, error) {
+	return r.grupos(""), nil
+}
+
+// grupos retorna, ordenados, los grupos de la materia indicada o de todas si está vacía
+func (r *grupoMemoria) grupos(materiaCodigo string) []domain.Grupo {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var grupos []domain.Grupo
+	for g := range r.almacen.grupos {
+		if materiaCodigo == "" || g.Materia == materiaCodigo {
+			grupos = append(grupos, g)
+		}
+	}
+	sort.Slice(grupos, func(i, j int) bool {
+		if grupos[i].Materia != grupos[j].Materia {
+			return grupos[i].Materia < grupos[j].Materia
+		}
+		return grupos[i].Numero < grupos[j].Numero
+	})
+	return grupos
+}
+
+type horarioMemoria struct {
+	almacen *almacenMemoria
+}
+
+func (r *horarioMemoria) Create(s domain.Sesion) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.materiaActiva(s.Materia); !ok {
+		return fmt.Errorf("error al agregar la sesión del %s a %s: %w: materia %s", s.Franja(), s.Materia, ErrReferenciaInvalida, s.Materia)
+	}
+	if !r.almacen.existeGrupo(s.Materia, s.Grupo) {
+		return fmt.Errorf("error al agregar la sesión del %s a %s: %w: la materia %s no tiene grupo %d",
+			s.Franja(), s.Materia, ErrReferenciaInvalida, s.Materia, s.Grupo)
+	}
+	if _, ok := r.almacen.sesiones[claveDeSesion(s)]; ok {
+		return fmt.Errorf("error al agregar la sesión del %s a %s: %w", s.Franja(), s.Materia, ErrDuplicado)
+	}
+	r.almacen.sesiones[claveDeSesion(s)] = s
+	return nil
+}
+
+func (r *horarioMemoria) Delete(s domain.Sesion) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.sesiones[claveDeSesion(s)]; !ok {
+		return fmt.Errorf("%w: %s no tiene sesión el %s a las %s en el grupo %d",
+			ErrNoEncontrado, s.Materia, s.Dia, domain.FormatearHora(s.Inicio), s.Grupo)
+	}
+	delete(r.almacen.sesiones, claveDeSesion(s))
+	return nil
+}
+
+func (r *horarioMemoria) GetByMateria(materiaCodigo string) ([]domain.Sesion, error) {
+	return r.sesiones(materiaCodigo), nil
+}
+
+func (r *horarioMemoria) GetAll() ([]domain.Sesion, error) {
+	return r.sesiones(""), nil
+}
+
+// sesiones retorna, ordenadas, las sesiones de la materia indicada o de todas si está vacía
+func (r *horarioMemoria) sesiones(materiaCodigo string) []domain.Sesion {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var sesiones []domain.Sesion
+	for _, s := range r.almacen.sesiones {
+		if materiaCodigo == "" || s.Materia == materiaCodigo {
+			sesiones = append(sesiones, s)
+		}
+	}
+	sort.Slice(sesiones, func(i, j int) bool {
+		a, b := sesiones[i], sesiones[j]
+		if a.Materia != b.Materia {
+			return a.Materia < b.Materia
+		}
+		if a.Grupo != b.Grupo {
+			return a.Grupo < b.Grupo
+		}
+		if a.Dia != b.Dia {
+			return a.Dia < b.Dia
+		}
+		return a.Inicio < b.Inicio
+	})
+	return sesiones
+}
+
+type componenteMemoria struct {
+	almacen *almacenMemoria
+}
+
+func (r *componenteMemoria) Create(c domain.Componente) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.materiaActiva(c.Materia); !ok {
+		return fmt.Errorf("error al agregar el componente %s a %s: %w: materia %s", c.Nombre, c.Materia, ErrReferenciaInvalida, c.Materia)
+	}
+	clave := claveComponente{materia: c.Materia, nombre: c.Nombre}
+	if _, ok := r.almacen.componentes[clave]; ok {
+		return fmt.Errorf("error al agregar el componente %s a %s: %w", c.Nombre, c.Materia, ErrDuplicado)
+	}
+	if total := r.almacen.pesoComponentes(c.Materia) + c.Peso; total > domain.PesoTotal {
+		return fmt.Errorf("error al agregar el componente %s a %s: %w: sumarían %d%%", c.Nombre, c.Materia, ErrPesoExcedido, total)
+	}
+	r.almacen.componentes[clave] = c.Peso
+	return nil
+}
+
+func (r *componenteMemoria) CambiarPeso(c domain.Componente) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	clave := claveComponente{materia: c.Materia, nombre: c.Nombre}
+	anterior, ok := r.almacen.componentes[clave]
+	if !ok {
+		return fmt.Errorf("error al cambiar el peso del componente %s de %s: %w", c.Nombre, c.Materia, ErrNoEncontrado)
+	}
+	if total := r.almacen.pesoComponentes(c.Materia) - anterior + c.Peso; total > domain.PesoTotal {
+		return fmt.Errorf("error al cambiar el peso del componente %s de %s: %w: sumarían %d%%", c.Nombre, c.Materia, ErrPesoExcedido, total)
+	}
+	r.almacen.componentes[clave] = c.Peso
+	return nil
+}
+
+func (r *componenteMemoria) Delete(materiaCodigo, nombre string) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	clave := claveComponente{materia: materiaCodigo, nombre: nombre}
+	if _, ok := r.almacen.componentes[clave]; !ok {
+		return fmt.Errorf("error al quitar el componente %s de %s: %w", nombre, materiaCodigo, ErrNoEncontrado)
+	}
+	for c := range r.almacen.calificaciones {
+		if c.codigo == materiaCodigo && c.componente == nombre {
+			return fmt.Errorf("error al quitar el componente %s de %s: %w", nombre, materiaCodigo, ErrComponenteConNotas)
+		}
+	}
+	delete(r.almacen.componentes, clave)
+	return nil
+}
+
+func (r *componenteMemoria) GetByMateria(materiaCodigo string) ([]domain.Componente, error) {
+	return r.componentes(materiaCodigo), nil
+}
+
+func (r *componenteMemoria) GetAll() ([]domain.Componente, error) {
+	return r.componentes(""), nil
+}
+
+// componentes retorna, ordenados, los componentes de la materia indicada o de todas si está vacía
+func (r *componenteMemoria) componentes(materiaCodigo string) []domain.Componente {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var componentes []domain.Componente
+	for clave, peso := range r.almacen.componentes {
+		if materiaCodigo == "" || clave.materia == materiaCodigo {
+			componentes = append(componentes, domain.Componente{Materia: clave.materia, Nombre: clave.nombre, Peso: peso})
+		}
+	}
+	sort.Slice(componentes, func(i, j int) bool {
+		if componentes[i].Materia != componentes[j].Materia {
+			return componentes[i].Materia < componentes[j].Materia
+		}
+		return componentes[i].Nombre < componentes[j].Nombre
+	})
+	return componentes
+}
+
+// pesoComponentes suma los pesos de los componentes de la materia; se llama con el candado tomado
+func (a *almacenMemoria) pesoComponentes(materiaCodigo string) int {
+	total := 0
+	for clave, peso := range a.componentes {
+		if clave.materia == materiaCodigo {
+			total += peso
+		}
+	}
+	return total
+}
+
+type calificacionMemoria struct {
+	almacen   *almacenMemoria
+	periodo   string
+	auditoria ContextoAuditoria
+}
+
+func (r *calificacionMemoria) Registrar(estudianteCedula, materiaCodigo, componente string, nota float64) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	inscripcion := claveInscripcion{cedula: estudianteCedula, codigo: materiaCodigo, periodo: r.periodo}
+	if !r.almacen.inscripcionVigente(inscripcion) {
+		return fmt.Errorf("error al registrar la nota de %s en %s (%s): %w: el estudiante %s no está inscrito en %s en el periodo %s",
+			estudianteCedula, materiaCodigo, componente, ErrReferenciaInvalida, estudianteCedula, materiaCodigo, r.periodo)
+	}
+	if _, ok := r.almacen.componentes[claveComponente{materia: materiaCodigo, nombre: componente}]; !ok {
+		return fmt.Errorf("error al registrar la nota de %s en %s (%s): %w: la materia %s no tiene el componente %s",
+			estudianteCedula, materiaCodigo, componente, ErrReferenciaInvalida, materiaCodigo, componente)
+	}
+
+	clave := claveCalificacion{inscripcion, componente}
+	operacion := domain.OperacionCrear
+	var anterior *float64
+	if centesimas, ok := r.almacen.calificaciones[clave]; ok {
+		if centesimas == domain.Centesimas(nota) {
+			return nil
+		}
+		valor := domain.NotaDeCentesimas(centesimas)
+		operacion, anterior = domain.OperacionActualizar, &valor
+	}
+	r.almacen.calificaciones[clave] = domain.Centesimas(nota)
+	r.almacen.registrar(r.auditoria, cambioCalificacion(operacion, estudianteCedula, materiaCodigo, r.periodo, componente, anterior, &nota))
+	return nil
+}
+
+func (r *calificacionMemoria) Delete(estudianteCedula, materiaCodigo, componente string) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	clave := claveCalificacion{claveInscripcion{cedula: estudianteCedula, codigo: materiaCodigo, periodo: r.periodo}, componente}
+	centesimas, ok := r.almacen.calificaciones[clave]
+	if !ok {
+		return fmt.Errorf("error al quitar la nota de %s en %s (%s): %w", estudianteCedula, materiaCodigo, componente, ErrNoEncontrado)
+	}
+	delete(r.almacen.calificaciones, clave)
+	anterior := domain.NotaDeCentesimas(centesimas)
+	r.almacen.registrar(r.auditoria, cambioCalificacion(domain.OperacionEliminar, estudianteCedula, materiaCodigo, r.periodo, componente, &anterior, nil))
+	return nil
+}
+
+func (r *calificacionMemoria) GetByInscripcion(estudianteCedula, materiaCodigo string) ([]domain.Calificacion, error) {
+	return r.calificaciones(func(clave claveCalificacion) bool {
+		return clave.cedula == estudianteCedula && clave.codigo == materiaCodigo
+	}), nil
+}
+
+func (r *calificacionMemoria) GetByMateria(materiaCodigo string) ([]domain.Calificacion, error) {
+	return r.calificaciones(func(clave claveCalificacion) bool {
+		return clave.codigo == materiaCodigo && r.almacen.inscripcionVigente(clave.claveInscripcion)
+	}), nil
+}
+
+func (r *calificacionMemoria) GetAll() ([]domain.Calificacion, error) {
+	return r.calificaciones(func(claveCalificacion) bool { return true }), nil
+}
+
+func (r *calificacionMemoria) HistorialByEstudiante(cedula string) ([]domain.Calificacion, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var calificaciones []domain.Calificacion
+	for clave, centesimas := range r.almacen.calificaciones {
+		if clave.cedula == cedula {
+			calificaciones = append(calificaciones, domain.Calificacion{
+				Cedula:     clave.cedula,
+				Materia:    clave.codigo,
+				Periodo:    clave.periodo,
+				Componente: clave.componente,
+				Nota:       domain.NotaDeCentesimas(centesimas),
+			})
+		}
+	}
+	sort.Slice(calificaciones, func(i, j int) bool {
+		a, b := calificaciones[i], calificaciones[j]
+		if a.Periodo != b.Periodo {
+			return a.Periodo < b.Periodo
+		}
+		if a.Materia != b.Materia {
+			return a.Materia < b.Materia
+		}
+		return a.Componente < b.Componente
+	})
+	return calificaciones, nil
+}
+
+// calificaciones retorna, ordenadas, las notas del periodo que cumplen el filtro
+func (r *calificacionMemoria) calificaciones(incluir func(claveCalificacion) bool) []domain.Calificacion {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var calificaciones []domain.Calificacion
+	for clave, centesimas := range r.almacen.calificaciones {
+		if clave.periodo == r.periodo && incluir(clave) {
+			calificaciones = append(calificaciones, domain.Calificacion{
+				Cedula:     clave.cedula,
+				Materia:    clave.codigo,
+				Periodo:    clave.periodo,
+				Componente: clave.componente,
+				Nota:       domain.NotaDeCentesimas(centesimas),
+			})
+		}
+	}
+	ordenarCalificaciones(calificaciones)
+	return calificaciones
+}
+
+type carreraMemoria struct {
+	almacen   *almacenMemoria
+	auditoria ContextoAuditoria
+}
+
+func (r *carreraMemoria) Create(c *domain.Carrera) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.carreras[c.Codigo]; ok {
+		return fmt.Errorf("error al crear la carrera %s: %w", c.Codigo, ErrDuplicado)
+	}
+	r.almacen.carreras[c.Codigo] = *c
+	return nil
+}
+
+func (r *carreraMemoria) Update(c *domain.Carrera) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.carreras[c.Codigo]; !ok {
+		return fmt.Errorf("error al actualizar la carrera %s: %w", c.Codigo, ErrNoEncontrado)
+	}
+	r.almacen.carreras[c.Codigo] = *c
+	return nil
+}
+
+func (r *carreraMemoria) Delete(codigo string) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.carreras[codigo]; !ok {
+		return fmt.Errorf("error al eliminar la carrera %s: %w", codigo, ErrNoEncontrado)
+	}
+	for _, carrera := range r.almacen.carreraEstudiantes {
+		if carrera == codigo {
+			return fmt.Errorf("error al eliminar la carrera %s: %w", codigo, ErrCarreraConEstudiantes)
+		}
+	}
+	for clave := range r.almacen.pensum {
+		if clave.carrera == codigo {
+			delete(r.almacen.pensum, clave)
+		}
+	}
+	delete(r.almacen.carreras, codigo)
+	return nil
+}
+
+func (r *carreraMemoria) GetByCodigo(codigo string) (*domain.Carrera, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	c, ok := r.almacen.carreras[codigo]
+	if !ok {
+		return nil, nil
+	}
+	return &c, nil
+}
+
+func (r *carreraMemoria) GetAll() ([]*domain.Carrera, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	carreras := make([]*domain.Carrera, 0, len(r.almacen.carreras))
+	for _, c := range r.almacen.carreras {
+		carrera := c
+		carreras = append(carreras, &carrera)
+	}
+	sort.Slice(carreras, func(i, j int) bool { return carreras[i].Codigo < carreras[j].Codigo })
+	return carreras, nil
+}
+
+func (r *carreraMemoria) AgregarAlPensum(m domain.MateriaPensum) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.carreras[m.Carrera]; !ok {
+		return fmt.Errorf("error al agregar %s al pensum de %s: %w: carrera %s", m.Materia, m.Carrera, ErrReferenciaInvalida, m.Carrera)
+	}
+	if _, ok := r.almacen.materiaActiva(m.Materia); !ok {
+		return fmt.Errorf("error al agregar %s al pensum de %s: %w: materia %s", m.Materia, m.Carrera, ErrReferenciaInvalida, m.Materia)
+	}
+	clave := clavePensum{carrera: m.Carrera, materia: m.Materia}
+	if _, ok := r.almacen.pensum[clave]; ok {
+		return fmt.Errorf("error al agregar %s al pensum de %s: %w", m.Materia, m.Carrera, ErrDuplicado)
+	}
+	r.almacen.pensum[clave] = m
+	return nil
+}
+
+func (r *carreraMemoria) QuitarDelPensum(carreraCodigo, materiaCodigo string) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	clave := clavePensum{carrera: carreraCodigo, materia: materiaCodigo}
+	if _, ok := r.almacen.pensum[clave]; !ok {
+		return fmt.Errorf("error al quitar %s del pensum de %s: %w", materiaCodigo, carreraCodigo, ErrNoEncontrado)
+	}
+	delete(r.almacen.pensum, clave)
+	return nil
+}
+
+func (r *carreraMemoria) GetPensum(carreraCodigo string) ([]domain.MateriaPensum, error) {
+	return r.pensum(carreraCodigo), nil
+}
+
+func (r *carreraMemoria) GetAllPensum() ([]domain.MateriaPensum, error) {
+	return r.pensum(""), nil
+}
+
+// pensum retorna, ordenado, el pensum de la carrera indicada o el de todas si está vacía
+func (r *carreraMemoria) pensum(carreraCodigo string) []domain.MateriaPensum {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var pensum []domain.MateriaPensum
+	for clave, m := range r.almacen.pensum {
+		if carreraCodigo == "" || clave.carrera == carreraCodigo {
+			pensum = append(pensum, m)
+		}
+	}
+	sort.Slice(pensum, func(i, j int) bool {
+		a, b := pensum[i], pensum[j]
+		if a.Carrera != b.Carrera {
+			return a.Carrera < b.Carrera
+		}
+		if a.Semestre != b.Semestre {
+			return a.Semestre < b.Semestre
+		}
+		return a.Materia < b.Materia
+	})
+	return pensum
+}
+
+func (r *carreraMemoria) AsignarEstudiante(estudianteCedula, carreraCodigo string) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.estudianteActivo(estudianteCedula); !ok {
+		return fmt.Errorf("error al asignar al estudiante %s a la carrera %s: %w: estudiante %s",
+			estudianteCedula, carreraCodigo, ErrReferenciaInvalida, estudianteCedula)
+	}
+	if _, ok := r.almacen.carreras[carreraCodigo]; !ok {
+		return fmt.Errorf("error al asignar al estudiante %s a la carrera %s: %w: carrera %s",
+			estudianteCedula, carreraCodigo, ErrReferenciaInvalida, carreraCodigo)
+	}
+	anterior := r.almacen.carreraEstudiantes[estudianteCedula]
+	if anterior == carreraCodigo {
+		return nil
+	}
+	operacion := domain.OperacionCrear
+	if anterior != "" {
+		operacion = domain.OperacionActualizar
+	}
+	r.almacen.carreraEstudiantes[estudianteCedula] = carreraCodigo
+	r.almacen.registrar(r.auditoria, cambioCarreraEstudiante(operacion, estudianteCedula, anterior, carreraCodigo))
+	return nil
+}
+
+func (r *carreraMemoria) QuitarEstudiante(estudianteCedula string) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	anterior, ok := r.almacen.carreraEstudiantes[estudianteCedula]
+	if !ok {
+		return fmt.Errorf("error al quitar al estudiante %s de su carrera: %w", estudianteCedula, ErrNoEncontrado)
+	}
+	delete(r.almacen.carreraEstudiantes, estudianteCedula)
+	r.almacen.registrar(r.auditoria, cambioCarreraEstudiante(domain.OperacionEliminar, estudianteCedula, anterior, ""))
+	return nil
+}
+
+func (r *carreraMemoria) CarreraDeEstudiante(estudianteCedula string) (string, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	return r.almacen.carreraEstudiantes[estudianteCedula], nil
+}
+
+func (r *carreraMemoria) GetEstudiantes(carreraCodigo string) ([]*domain.Estudiante, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var estudiantes []*domain.Estudiante
+	for cedula, carrera := range r.almacen.carreraEstudiantes {
+		if carrera != carreraCodigo {
+			continue
+		}
+		if e, ok := r.almacen.estudianteActivo(cedula); ok {
+			estudiantes = append(estudiantes, &e)
+		}
+	}
+	sort.Slice(estudiantes, func(i, j int) bool { return estudiantes[i].Cedula < estudiantes[j].Cedula })
+	return estudiantes, nil
+}
+
+func (r *carreraMemoria) GetAsignaciones() ([]domain.AsignacionCarrera, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	asignaciones := make([]domain.AsignacionCarrera, 0, len(r.almacen.carreraEstudiantes))
+	for cedula, carrera := range r.almacen.carreraEstudiantes {
+		asignaciones = append(asignaciones, domain.AsignacionCarrera{Cedula: cedula, Carrera: carrera})
+	}
+	sort.Slice(asignaciones, func(i, j int) bool { return asignaciones[i].Cedula < asignaciones[j].Cedula })
+	return asignaciones, nil
+}
+
+type docenteMemoria struct {
+	almacen *almacenMemoria
+	periodo string
+}
+
+func (r *docenteMemoria) Create(d *domain.Docente) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.docentes[d.Cedula]; ok {
+		return fmt.Errorf("error al crear el docente %s: %w", d.Cedula, ErrDuplicado)
+	}
+	r.almacen.docentes[d.Cedula] = *d
+	return nil
+}
+
+func (r *docenteMemoria) Update(d *domain.Docente) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.docentes[d.Cedula]; !ok {
+		return fmt.Errorf("error al actualizar el docente %s: %w", d.Cedula, ErrNoEncontrado)
+	}
+	r.almacen.docentes[d.Cedula] = *d
+	return nil
+}
+
+func (r *docenteMemoria) Delete(cedula string) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.docentes[cedula]; !ok {
+		return fmt.Errorf("error al eliminar el docente %s: %w", cedula, ErrNoEncontrado)
+	}
+	for _, docente := range r.almacen.asignacionesDocentes {
+		if docente == cedula {
+			return fmt.Errorf("error al eliminar el docente %s: %w", cedula, ErrDocenteConAsignaciones)
+		}
+	}
+	delete(r.almacen.docentes, cedula)
+	return nil
+}
+
+func (r *docenteMemoria) GetByCedula(cedula string) (*domain.Docente, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	d, ok := r.almacen.docentes[cedula]
+	if !ok {
+		return nil, nil
+	}
+	return &d, nil
+}
+
+func (r *docenteMemoria) GetAll() ([]*domain.Docente, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	docentes := make([]*domain.Docente, 0, len(r.almacen.docentes))
+	for _, d := range r.almacen.docentes {
+		docente := d
+		docentes = append(docentes, &docente)
+	}
+	sort.Slice(docentes, func(i, j int) bool { return docentes[i].Cedula < docentes[j].Cedula })
+	return docentes, nil
+}
+
+func (r *docenteMemoria) Asignar(docenteCedula, materiaCodigo string, grupo int) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if _, ok := r.almacen.docentes[docenteCedula]; !ok {
+		return fmt.Errorf("error al asignar al docente %s a %s: %w: docente %s", docenteCedula, materiaCodigo, ErrReferenciaInvalida, docenteCedula)
+	}
+	if _, ok := r.almacen.materiaActiva(materiaCodigo); !ok {
+		return fmt.Errorf("error al asignar al docente %s a %s: %w: materia %s", docenteCedula, materiaCodigo, ErrReferenciaInvalida, materiaCodigo)
+	}
+	if !r.almacen.existeGrupo(materiaCodigo, grupo) {
+		return fmt.Errorf("error al asignar al docente %s a %s: %w: la materia %s no tiene grupo %d",
+			docenteCedula, materiaCodigo, ErrReferenciaInvalida, materiaCodigo, grupo)
+	}
+	r.almacen.asignacionesDocentes[claveAsignacionDocente{periodo: r.periodo, materia: materiaCodigo, grupo: grupo}] = docenteCedula
+	return nil
+}
+
+func (r *docenteMemoria) Desasignar(materiaCodigo string, grupo int) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	clave := claveAsignacionDocente{periodo: r.periodo, materia: materiaCodigo, grupo: grupo}
+	if _, ok := r.almacen.asignacionesDocentes[clave]; !ok {
+		return fmt.Errorf("error al quitar el docente de %s: %w", materiaCodigo, ErrNoEncontrado)
+	}
+	delete(r.almacen.asignacionesDocentes, clave)
+	return nil
+}
+
+func (r *docenteMemoria) GetByDocente(docenteCedula string) ([]domain.AsignacionDocente, error) {
+	return r.asignaciones(func(a domain.AsignacionDocente) bool {
+		_, activa := r.almacen.materiaActiva(a.Materia)
+		return a.Docente == docenteCedula && activa
+	}), nil
+}
+
+func (r *docenteMemoria) GetAsignaciones() ([]domain.AsignacionDocente, error) {
+	return r.asignaciones(func(domain.AsignacionDocente) bool { return true }), nil
+}
+
+// asignaciones retorna, ordenadas por materia y grupo, las asignaciones del periodo que cumplen el filtro
+func (r *docenteMemoria) asignaciones(incluir func(domain.AsignacionDocente) bool) []domain.AsignacionDocente {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var asignaciones []domain.AsignacionDocente
+	for clave, docente := range r.almacen.asignacionesDocentes {
+		a := domain.AsignacionDocente{Docente: docente, Materia: clave.materia, Periodo: clave.periodo, Grupo: clave.grupo}
+		if clave.periodo == r.periodo && incluir(a) {
+			asignaciones = append(asignaciones, a)
+		}
+	}
+	sort.Slice(asignaciones, func(i, j int) bool {
+		if asignaciones[i].Materia != asignaciones[j].Materia {
+			return asignaciones[i].Materia < asignaciones[j].Materia
+		}
+		return asignaciones[i].Grupo < asignaciones[j].Grupo
+	})
+	return asignaciones
+}
+
+func (r *docenteMemoria) GetEstudiantes(docenteCedula string) ([]*domain.Estudiante, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	dicta := func(codigo string, grupo int) bool {
+		for _, g := range []int{domain.SinGrupo, grupo} {
+			if r.almacen.asignacionesDocentes[claveAsignacionDocente{periodo: r.periodo, materia: codigo, grupo: g}] == docenteCedula {
+				return true
+			}
+		}
+		return false
+	}
+	vistos := make(map[string]bool)
+	var estudiantes []*domain.Estudiante
+	r.almacen.inscripcionesVigentes(r.periodo, func(clave claveInscripcion, e domain.Estudiante, _ domain.Materia) {
+		if !vistos[e.Cedula] && dicta(clave.codigo, r.almacen.inscripciones[clave].grupo) {
+			vistos[e.Cedula] = true
+			estudiantes = append(estudiantes, &domain.Estudiante{Cedula: e.Cedula, Nombre: e.Nombre})
+		}
+	})
+	ordenarPorCedula(estudiantes)
+	return estudiantes, nil
+}
+
+type auditoriaMemoria struct {
+	almacen *almacenMemoria
+}
+
+func (r *auditoriaMemoria) HistorialEstudiante(cedula string) ([]*domain.RegistroAuditoria, error) {
+	return r.historial(func(e entradaAuditoria) bool { return e.cedula == cedula })
+}
+
+func (r *auditoriaMemoria) HistorialMateria(codigo string) ([]*domain.RegistroAuditoria, error) {
+	return r.historial(func(e entradaAuditoria) bool { return e.codigo == codigo })
+}
+
+func (r *auditoriaMemoria) historial(incluir func(entradaAuditoria) bool) ([]*domain.RegistroAuditoria, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	var registros []*domain.RegistroAuditoria
+	for _, entrada := range r.almacen.auditoria {
+		if incluir(entrada) {
+			registro := entrada.registro
+			registros = append(registros, &registro)
+		}
+	}
+	return registros, nil
+}
+
+type eventoMemoria struct {
+	almacen *almacenMemoria
+}
+
+func (r *eventoMemoria) Publicar(evento *domain.Evento) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	evento.ID = int64(len(r.almacen.outbox) + 1)
+	r.almacen.outbox = append(r.almacen.outbox, entradaOutbox{evento: *evento, proximoIntento: evento.Fecha})
+	return nil
+}
+
+func (r *eventoMemoria) Pendientes(limite int) ([]*domain.Evento, error) {
+	r.almacen.mu.RLock()
+	defer r.almacen.mu.RUnlock()
+
+	ahora := time.Now()
+	var eventos []*domain.Evento
+	for _, entrada := range r.almacen.outbox {
+		if len(eventos) == limite {
+			break
+		}
+		if !entrada.entregado && !entrada.proximoIntento.After(ahora) {
+			evento := entrada.evento
+			eventos = append(eventos, &evento)
+		}
+	}
+	return eventos, nil
+}
+
+func (r *eventoMemoria) MarcarEntregado(id int64) error {
+	return r.actualizar(id, false, func(entrada *entradaOutbox) {
+		entrada.entregado = true
+	})
+}
+
+func (r *eventoMemoria) MarcarFallido(id int64, proximoIntento time.Time, motivo string) error {
+	return r.actualizar(id, true, func(entrada *entradaOutbox) {
+		entrada.evento.Intentos++
+		entrada.proximoIntento = proximoIntento
+		entrada.ultimoError = motivo
+	})
+}
+
+func (r *eventoMemoria) actualizar(id int64, soloPendiente bool, cambiar func(*entradaOutbox)) error {
+	r.almacen.mu.Lock()
+	defer r.almacen.mu.Unlock()
+
+	if id < 1 || id > int64(len(r.almacen.outbox)) {
+		return fmt.Errorf("%w: evento pendiente %d", ErrNoEncontrado, id)
+	}
+	entrada := &r.almacen.outbox[id-1]
+	if soloPendiente && entrada.entregado {
+		return fmt.Errorf("%w: evento pendiente %d", ErrNoEncontrado, id)
+	}
+	cambiar(entrada)
+	return nil
+}
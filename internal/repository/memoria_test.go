@@ -0,0 +1,29 @@
+package repository_test
+
+import (
+	"testing"
+
+	"inscripciones/internal/repository"
+	"inscripciones/internal/repository/repotest"
+)
+
+func nuevosEnMemoria(t *testing.T) *repository.Repositorios {
+	t.Helper()
+
+	repos, err := repository.NewRepositoriosEnMemoriaConAcceso(repository.Acceso{Administrador: true})
+	if err != nil {
+		t.Fatalf("no se pudieron crear los repositorios en memoria: %v", err)
+	}
+	return repos
+}
+
+func TestContratoMemoria(t *testing.T) {
+	repotest.ProbarContrato(t, nuevosEnMemoria)
+}
+
+// Los decoradores con caché deben comportarse igual que los repositorios que envuelven
+func TestContratoMemoriaConCache(t *testing.T) {
+	repotest.ProbarContrato(t, func(t *testing.T) *repository.Repositorios {
+		return nuevosEnMemoria(t).ConCache(repository.CachePorDefecto)
+	})
+}
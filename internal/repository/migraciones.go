@@ -0,0 +1,576 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migracion es un paso versionado del esquema, compartido por todos los backends
+type migracion struct {
+	version     int
+	descripcion string
+	sentencias  []string
+	// sentenciasPostgres reemplaza a sentencias cuando el SQL difiere en PostgreSQL
+	sentenciasPostgres []string
+}
+
+func (m migracion) sentenciasPara(d Dialecto) []string {
+	if d == DialectoPostgres && m.sentenciasPostgres != nil {
+		return m.sentenciasPostgres
+	}
+	return m.sentencias
+}
+
+// triggerAuditoriaSinActualizaciones hace de solo inserción la auditoría en SQLite;
+// Recifrar lo quita y lo vuelve a crear dentro de su transacción
+const triggerAuditoriaSinActualizaciones = `CREATE TRIGGER IF NOT EXISTS auditoria_sin_actualizaciones BEFORE UPDATE ON auditoria
+            BEGIN SELECT RAISE(ABORT, 'la auditoría es de solo inserción'); END`
+
+var migraciones = []migracion{
+	{
+		version:     1,
+		descripcion: "tablas de estudiantes, materias e inscripciones",
+		sentencias: []string{
+			`CREATE TABLE IF NOT EXISTS estudiantes (
+            cedula TEXT PRIMARY KEY,
+            nombre TEXT NOT NULL
+        )`,
+			`CREATE TABLE IF NOT EXISTS materias (
+            codigo TEXT PRIMARY KEY,
+            nombre TEXT NOT NULL
+        )`,
+			`CREATE TABLE IF NOT EXISTS inscripciones (
+            estudiante_cedula TEXT,
+            materia_codigo TEXT,
+            FOREIGN KEY(estudiante_cedula) REFERENCES estudiantes(cedula),
+            FOREIGN KEY(materia_codigo) REFERENCES materias(codigo),
+            PRIMARY KEY(estudiante_cedula, materia_codigo)
+        )`,
+		},
+	},
+	{
+		version:     2,
+		descripcion: "índice de inscripciones por materia",
+		sentencias: []string{
+			`CREATE INDEX IF NOT EXISTS idx_inscripciones_materia ON inscripciones (materia_codigo)`,
+		},
+	},
+	{
+		version:     3,
+		descripcion: "índices de búsqueda por nombre sin tildes",
+		sentencias: []string{
+			`CREATE VIRTUAL TABLE IF NOT EXISTS estudiantes_fts USING fts5(
+            clave UNINDEXED,
+            texto,
+            tokenize = 'unicode61 remove_diacritics 2'
+        )`,
+			`CREATE VIRTUAL TABLE IF NOT EXISTS materias_fts USING fts5(
+            clave UNINDEXED,
+            texto,
+            tokenize = 'unicode61 remove_diacritics 2'
+        )`,
+			`INSERT INTO estudiantes_fts (clave, texto) SELECT cedula, nombre FROM estudiantes`,
+			`INSERT INTO materias_fts (clave, texto) SELECT codigo, nombre FROM materias`,
+		},
+		sentenciasPostgres: []string{
+			`CREATE TABLE IF NOT EXISTS estudiantes_busqueda (
+            clave TEXT PRIMARY KEY REFERENCES estudiantes(cedula),
+            texto TEXT NOT NULL
+        )`,
+			`CREATE TABLE IF NOT EXISTS materias_busqueda (
+            clave TEXT PRIMARY KEY REFERENCES materias(codigo),
+            texto TEXT NOT NULL
+        )`,
+			`INSERT INTO estudiantes_busqueda (clave, texto)
+            SELECT cedula, translate(lower(nombre), '` + pgLetrasConTilde + `', '` + pgLetrasSinTilde + `') FROM estudiantes`,
+			`INSERT INTO materias_busqueda (clave, texto)
+            SELECT codigo, translate(lower(nombre), '` + pgLetrasConTilde + `', '` + pgLetrasSinTilde + `') FROM materias`,
+		},
+	},
+	{
+		version:     4,
+		descripcion: "auditoría de cambios de solo inserción",
+		sentencias: []string{
+			`CREATE TABLE IF NOT EXISTS auditoria (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            fecha TEXT NOT NULL,
+            actor TEXT NOT NULL,
+            origen TEXT NOT NULL,
+            entidad TEXT NOT NULL,
+            clave TEXT NOT NULL,
+            estudiante_cedula TEXT,
+            materia_codigo TEXT,
+            operacion TEXT NOT NULL,
+            antes TEXT,
+            despues TEXT
+        )`,
+			`CREATE INDEX IF NOT EXISTS idx_auditoria_estudiante ON auditoria (estudiante_cedula)`,
+			`CREATE INDEX IF NOT EXISTS idx_auditoria_materia ON auditoria (materia_codigo)`,
+			triggerAuditoriaSinActualizaciones,
+			`CREATE TRIGGER IF NOT EXISTS auditoria_sin_eliminaciones BEFORE DELETE ON auditoria
+            BEGIN SELECT RAISE(ABORT, 'la auditoría es de solo inserción'); END`,
+		},
+		sentenciasPostgres: []string{
+			`CREATE TABLE IF NOT EXISTS auditoria (
+            id BIGSERIAL PRIMARY KEY,
+            fecha TEXT NOT NULL,
+            actor TEXT NOT NULL,
+            origen TEXT NOT NULL,
+            entidad TEXT NOT NULL,
+            clave TEXT NOT NULL,
+            estudiante_cedula TEXT,
+            materia_codigo TEXT,
+            operacion TEXT NOT NULL,
+            antes TEXT,
+            despues TEXT
+        )`,
+			`CREATE INDEX IF NOT EXISTS idx_auditoria_estudiante ON auditoria (estudiante_cedula)`,
+			`CREATE INDEX IF NOT EXISTS idx_auditoria_materia ON auditoria (materia_codigo)`,
+			`CREATE OR REPLACE FUNCTION auditoria_solo_insercion() RETURNS trigger AS $$
+            BEGIN
+                RAISE EXCEPTION 'la auditoría es de solo inserción';
+            END;
+            $$ LANGUAGE plpgsql`,
+			`CREATE TRIGGER auditoria_sin_modificaciones BEFORE UPDATE OR DELETE ON auditoria
+            FOR EACH ROW EXECUTE FUNCTION auditoria_solo_insercion()`,
+		},
+	},
+	{
+		version:     5,
+		descripcion: "eliminación lógica de estudiantes, materias e inscripciones",
+		sentencias: []string{
+			`ALTER TABLE estudiantes ADD COLUMN deleted_at TEXT`,
+			`ALTER TABLE materias ADD COLUMN deleted_at TEXT`,
+			`ALTER TABLE inscripciones ADD COLUMN deleted_at TEXT`,
+		},
+	},
+	{
+		version:     6,
+		descripcion: "versión de estudiantes y materias para detectar ediciones concurrentes",
+		sentencias: []string{
+			`ALTER TABLE estudiantes ADD COLUMN version INTEGER NOT NULL DEFAULT 1`,
+			`ALTER TABLE materias ADD COLUMN version INTEGER NOT NULL DEFAULT 1`,
+		},
+	},
+	{
+		version:     7,
+		descripcion: "outbox de eventos de dominio",
+		sentencias: []string{
+			`CREATE TABLE IF NOT EXISTS outbox (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            tipo TEXT NOT NULL,
+            fecha TEXT NOT NULL,
+            datos TEXT NOT NULL,
+            intentos INTEGER NOT NULL DEFAULT 0,
+            proximo_intento BIGINT NOT NULL,
+            ultimo_error TEXT,
+            entregado_en TEXT
+        )`,
+			`CREATE INDEX IF NOT EXISTS idx_outbox_pendientes ON outbox (proximo_intento) WHERE entregado_en IS NULL`,
+		},
+		sentenciasPostgres: []string{
+			`CREATE TABLE IF NOT EXISTS outbox (
+            id BIGSERIAL PRIMARY KEY,
+            tipo TEXT NOT NULL,
+            fecha TEXT NOT NULL,
+            datos TEXT NOT NULL,
+            intentos INTEGER NOT NULL DEFAULT 0,
+            proximo_intento BIGINT NOT NULL,
+            ultimo_error TEXT,
+            entregado_en TEXT
+        )`,
+			`CREATE INDEX IF NOT EXISTS idx_outbox_pendientes ON outbox (proximo_intento) WHERE entregado_en IS NULL`,
+		},
+	},
+	{
+		version:     8,
+		descripcion: "facultad en todas las claves para compartir la base entre facultades",
+		// SQLite no permite cambiar la clave primaria: las tablas se recrean y los datos
+		// existentes quedan en la facultad por defecto. Al renombrar, las claves foráneas
+		// de inscripciones pasan a apuntar a las tablas viejas, que se eliminan al final.
+		sentencias: []string{
+			`ALTER TABLE inscripciones RENAME TO inscripciones_v7`,
+			`ALTER TABLE estudiantes RENAME TO estudiantes_v7`,
+			`ALTER TABLE materias RENAME TO materias_v7`,
+			`CREATE TABLE estudiantes (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            cedula TEXT NOT NULL,
+            nombre TEXT NOT NULL,
+            deleted_at TEXT,
+            version INTEGER NOT NULL DEFAULT 1,
+            PRIMARY KEY(facultad, cedula)
+        )`,
+			`CREATE TABLE materias (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            codigo TEXT NOT NULL,
+            nombre TEXT NOT NULL,
+            deleted_at TEXT,
+            version INTEGER NOT NULL DEFAULT 1,
+            PRIMARY KEY(facultad, codigo)
+        )`,
+			`CREATE TABLE inscripciones (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            estudiante_cedula TEXT NOT NULL,
+            materia_codigo TEXT NOT NULL,
+            deleted_at TEXT,
+            FOREIGN KEY(facultad, estudiante_cedula) REFERENCES estudiantes(facultad, cedula),
+            FOREIGN KEY(facultad, materia_codigo) REFERENCES materias(facultad, codigo),
+            PRIMARY KEY(facultad, estudiante_cedula, materia_codigo)
+        )`,
+			`INSERT INTO estudiantes (cedula, nombre, deleted_at, version)
+            SELECT cedula, nombre, deleted_at, version FROM estudiantes_v7`,
+			`INSERT INTO materias (codigo, nombre, deleted_at, version)
+            SELECT codigo, nombre, deleted_at, version FROM materias_v7`,
+			`INSERT INTO inscripciones (estudiante_cedula, materia_codigo, deleted_at)
+            SELECT estudiante_cedula, materia_codigo, deleted_at FROM inscripciones_v7`,
+			`DROP TABLE inscripciones_v7`,
+			`DROP TABLE estudiantes_v7`,
+			`DROP TABLE materias_v7`,
+			`CREATE INDEX idx_inscripciones_materia ON inscripciones (facultad, materia_codigo)`,
+			`DROP TABLE estudiantes_fts`,
+			`DROP TABLE materias_fts`,
+			`CREATE VIRTUAL TABLE estudiantes_fts USING fts5(
+            facultad UNINDEXED,
+            clave UNINDEXED,
+            texto,
+            tokenize = 'unicode61 remove_diacritics 2'
+        )`,
+			`CREATE VIRTUAL TABLE materias_fts USING fts5(
+            facultad UNINDEXED,
+            clave UNINDEXED,
+            texto,
+            tokenize = 'unicode61 remove_diacritics 2'
+        )`,
+			`INSERT INTO estudiantes_fts (facultad, clave, texto) SELECT facultad, cedula, nombre FROM estudiantes`,
+			`INSERT INTO materias_fts (facultad, clave, texto) SELECT facultad, codigo, nombre FROM materias`,
+			`ALTER TABLE auditoria ADD COLUMN facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `'`,
+			`ALTER TABLE outbox ADD COLUMN facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `'`,
+		},
+		sentenciasPostgres: []string{
+			`ALTER TABLE estudiantes ADD COLUMN facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `'`,
+			`ALTER TABLE materias ADD COLUMN facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `'`,
+			`ALTER TABLE inscripciones ADD COLUMN facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `'`,
+			`ALTER TABLE estudiantes_busqueda ADD COLUMN facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `'`,
+			`ALTER TABLE materias_busqueda ADD COLUMN facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `'`,
+			`ALTER TABLE inscripciones
+            DROP CONSTRAINT inscripciones_estudiante_cedula_fkey,
+            DROP CONSTRAINT inscripciones_materia_codigo_fkey`,
+			`ALTER TABLE estudiantes_busqueda DROP CONSTRAINT estudiantes_busqueda_clave_fkey`,
+			`ALTER TABLE materias_busqueda DROP CONSTRAINT materias_busqueda_clave_fkey`,
+			`ALTER TABLE estudiantes DROP CONSTRAINT estudiantes_pkey, ADD PRIMARY KEY (facultad, cedula)`,
+			`ALTER TABLE materias DROP CONSTRAINT materias_pkey, ADD PRIMARY KEY (facultad, codigo)`,
+			`ALTER TABLE inscripciones
+            DROP CONSTRAINT inscripciones_pkey,
+            ADD PRIMARY KEY (facultad, estudiante_cedula, materia_codigo),
+            ADD FOREIGN KEY (facultad, estudiante_cedula) REFERENCES estudiantes (facultad, cedula),
+            ADD FOREIGN KEY (facultad, materia_codigo) REFERENCES materias (facultad, codigo)`,
+			`ALTER TABLE estudiantes_busqueda
+            DROP CONSTRAINT estudiantes_busqueda_pkey,
+            ADD PRIMARY KEY (facultad, clave),
+            ADD FOREIGN KEY (facultad, clave) REFERENCES estudiantes (facultad, cedula)`,
+			`ALTER TABLE materias_busqueda
+            DROP CONSTRAINT materias_busqueda_pkey,
+            ADD PRIMARY KEY (facultad, clave),
+            ADD FOREIGN KEY (facultad, clave) REFERENCES materias (facultad, codigo)`,
+			`DROP INDEX idx_inscripciones_materia`,
+			`CREATE INDEX idx_inscripciones_materia ON inscripciones (facultad, materia_codigo)`,
+			`ALTER TABLE auditoria ADD COLUMN facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `'`,
+			`ALTER TABLE outbox ADD COLUMN facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `'`,
+		},
+	},
+	{
+		version:     9,
+		descripcion: "verificador de la clave de cifrado de los datos personales",
+		sentencias: []string{
+			`CREATE TABLE IF NOT EXISTS cifrado (
+            id INTEGER PRIMARY KEY CHECK (id = 1),
+            verificador TEXT NOT NULL
+        )`,
+		},
+	},
+	{
+		version:     10,
+		descripcion: "periodos académicos en la clave de las inscripciones",
+		// Las inscripciones existentes quedan en el periodo de la fecha de la migración, que
+		// pasa a ser el actual de cada facultad con datos
+		sentencias: []string{
+			`CREATE TABLE periodos (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            codigo TEXT NOT NULL,
+            actual INTEGER NOT NULL DEFAULT 0,
+            PRIMARY KEY(facultad, codigo)
+        )`,
+			`INSERT INTO periodos (facultad, codigo, actual)
+            SELECT facultad, strftime('%Y', 'now') || '-' || CASE WHEN CAST(strftime('%m', 'now') AS INTEGER) <= 6 THEN '1' ELSE '2' END, 1
+            FROM (SELECT facultad FROM estudiantes UNION SELECT facultad FROM materias)`,
+			`ALTER TABLE inscripciones RENAME TO inscripciones_v9`,
+			`CREATE TABLE inscripciones (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            estudiante_cedula TEXT NOT NULL,
+            materia_codigo TEXT NOT NULL,
+            periodo TEXT NOT NULL,
+            deleted_at TEXT,
+            FOREIGN KEY(facultad, estudiante_cedula) REFERENCES estudiantes(facultad, cedula),
+            FOREIGN KEY(facultad, materia_codigo) REFERENCES materias(facultad, codigo),
+            FOREIGN KEY(facultad, periodo) REFERENCES periodos(facultad, codigo),
+            PRIMARY KEY(facultad, estudiante_cedula, materia_codigo, periodo)
+        )`,
+			`INSERT INTO inscripciones (facultad, estudiante_cedula, materia_codigo, periodo, deleted_at)
+            SELECT i.facultad, i.estudiante_cedula, i.materia_codigo, p.codigo, i.deleted_at
+            FROM inscripciones_v9 i JOIN periodos p ON p.facultad = i.facultad AND p.actual = 1`,
+			`DROP TABLE inscripciones_v9`,
+			`CREATE INDEX idx_inscripciones_materia ON inscripciones (facultad, periodo, materia_codigo)`,
+		},
+		sentenciasPostgres: []string{
+			`CREATE TABLE periodos (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            codigo TEXT NOT NULL,
+            actual INTEGER NOT NULL DEFAULT 0,
+            PRIMARY KEY(facultad, codigo)
+        )`,
+			`INSERT INTO periodos (facultad, codigo, actual)
+            SELECT facultad, to_char(now(), 'YYYY') || '-' || CASE WHEN extract(month FROM now()) <= 6 THEN '1' ELSE '2' END, 1
+            FROM (SELECT facultad FROM estudiantes UNION SELECT facultad FROM materias) f`,
+			`ALTER TABLE inscripciones ADD COLUMN periodo TEXT`,
+			`UPDATE inscripciones i SET periodo = p.codigo FROM periodos p WHERE p.facultad = i.facultad AND p.actual = 1`,
+			`ALTER TABLE inscripciones
+            ALTER COLUMN periodo SET NOT NULL,
+            DROP CONSTRAINT inscripciones_pkey,
+            ADD PRIMARY KEY (facultad, estudiante_cedula, materia_codigo, periodo),
+            ADD FOREIGN KEY (facultad, periodo) REFERENCES periodos (facultad, codigo)`,
+			`DROP INDEX idx_inscripciones_materia`,
+			`CREATE INDEX idx_inscripciones_materia ON inscripciones (facultad, periodo, materia_codigo)`,
+		},
+	},
+	{
+		// Las materias existentes quedan con domain.CreditosPorDefecto
+		version:     11,
+		descripcion: "créditos de las materias",
+		sentencias: []string{
+			`ALTER TABLE materias ADD COLUMN creditos INTEGER NOT NULL DEFAULT 3`,
+		},
+	},
+	{
+		version:     12,
+		descripcion: "prerrequisitos entre materias",
+		sentencias: []string{
+			`CREATE TABLE prerrequisitos (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            materia_codigo TEXT NOT NULL,
+            requisito_codigo TEXT NOT NULL,
+            FOREIGN KEY(facultad, materia_codigo) REFERENCES materias(facultad, codigo),
+            FOREIGN KEY(facultad, requisito_codigo) REFERENCES materias(facultad, codigo),
+            PRIMARY KEY(facultad, materia_codigo, requisito_codigo)
+        )`,
+		},
+	},
+	{
+		// Las materias existentes quedan sin límite de cupo
+		version:     13,
+		descripcion: "cupos de las materias y listas de espera",
+		sentencias: []string{
+			`ALTER TABLE materias ADD COLUMN cupo INTEGER NOT NULL DEFAULT 0`,
+			`CREATE TABLE lista_espera (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            periodo TEXT NOT NULL,
+            materia_codigo TEXT NOT NULL,
+            estudiante_cedula TEXT NOT NULL,
+            orden INTEGER NOT NULL,
+            FOREIGN KEY(facultad, estudiante_cedula) REFERENCES estudiantes(facultad, cedula),
+            FOREIGN KEY(facultad, materia_codigo) REFERENCES materias(facultad, codigo),
+            FOREIGN KEY(facultad, periodo) REFERENCES periodos(facultad, codigo),
+            PRIMARY KEY(facultad, periodo, materia_codigo, estudiante_cedula)
+        )`,
+		},
+	},
+	{
+		// Las inscripciones existentes quedan sin grupo
+		version:     14,
+		descripcion: "grupos de las materias",
+		sentencias: []string{
+			`CREATE TABLE grupos (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            materia_codigo TEXT NOT NULL,
+            numero INTEGER NOT NULL,
+            FOREIGN KEY(facultad, materia_codigo) REFERENCES materias(facultad, codigo),
+            PRIMARY KEY(facultad, materia_codigo, numero)
+        )`,
+			`ALTER TABLE inscripciones ADD COLUMN grupo INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		// El grupo 0 son las sesiones de todos los inscritos en la materia
+		version:     15,
+		descripcion: "horarios de las materias",
+		sentencias: []string{
+			`CREATE TABLE sesiones (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            materia_codigo TEXT NOT NULL,
+            grupo INTEGER NOT NULL DEFAULT 0,
+            dia INTEGER NOT NULL,
+            inicio INTEGER NOT NULL,
+            fin INTEGER NOT NULL,
+            salon TEXT NOT NULL,
+            FOREIGN KEY(facultad, materia_codigo) REFERENCES materias(facultad, codigo),
+            PRIMARY KEY(facultad, materia_codigo, grupo, dia, inicio)
+        )`,
+		},
+	},
+	{
+		// Las notas se guardan en centésimas, de 0 (0.00) a 500 (5.00)
+		version:     16,
+		descripcion: "componentes de evaluación y calificaciones",
+		sentencias: []string{
+			`CREATE TABLE componentes (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            materia_codigo TEXT NOT NULL,
+            nombre TEXT NOT NULL,
+            peso INTEGER NOT NULL CHECK (peso BETWEEN 1 AND 100),
+            FOREIGN KEY(facultad, materia_codigo) REFERENCES materias(facultad, codigo),
+            PRIMARY KEY(facultad, materia_codigo, nombre)
+        )`,
+			`CREATE TABLE calificaciones (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            periodo TEXT NOT NULL,
+            materia_codigo TEXT NOT NULL,
+            estudiante_cedula TEXT NOT NULL,
+            componente TEXT NOT NULL,
+            nota INTEGER NOT NULL CHECK (nota BETWEEN 0 AND 500),
+            FOREIGN KEY(facultad, estudiante_cedula) REFERENCES estudiantes(facultad, cedula),
+            FOREIGN KEY(facultad, materia_codigo, componente) REFERENCES componentes(facultad, materia_codigo, nombre),
+            FOREIGN KEY(facultad, periodo) REFERENCES periodos(facultad, codigo),
+            PRIMARY KEY(facultad, periodo, materia_codigo, estudiante_cedula, componente)
+        )`,
+		},
+	},
+	{
+		version:     17,
+		descripcion: "carreras, pensum y carrera de cada estudiante",
+		sentencias: []string{
+			`CREATE TABLE carreras (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            codigo TEXT NOT NULL,
+            nombre TEXT NOT NULL,
+            creditos INTEGER NOT NULL CHECK (creditos > 0),
+            PRIMARY KEY(facultad, codigo)
+        )`,
+			`CREATE TABLE pensum (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            carrera_codigo TEXT NOT NULL,
+            materia_codigo TEXT NOT NULL,
+            semestre INTEGER NOT NULL CHECK (semestre BETWEEN 1 AND 12),
+            obligatoria INTEGER NOT NULL DEFAULT 1 CHECK (obligatoria IN (0, 1)),
+            FOREIGN KEY(facultad, carrera_codigo) REFERENCES carreras(facultad, codigo),
+            FOREIGN KEY(facultad, materia_codigo) REFERENCES materias(facultad, codigo),
+            PRIMARY KEY(facultad, carrera_codigo, materia_codigo)
+        )`,
+			`CREATE TABLE carrera_estudiantes (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            estudiante_cedula TEXT NOT NULL,
+            carrera_codigo TEXT NOT NULL,
+            FOREIGN KEY(facultad, estudiante_cedula) REFERENCES estudiantes(facultad, cedula),
+            FOREIGN KEY(facultad, carrera_codigo) REFERENCES carreras(facultad, codigo),
+            PRIMARY KEY(facultad, estudiante_cedula)
+        )`,
+		},
+	},
+	{
+		// El grupo 0 es la materia completa, como en las sesiones
+		version:     18,
+		descripcion: "docentes y sus asignaciones a materias",
+		sentencias: []string{
+			`CREATE TABLE docentes (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            cedula TEXT NOT NULL,
+            nombre TEXT NOT NULL,
+            PRIMARY KEY(facultad, cedula)
+        )`,
+			`CREATE TABLE asignaciones_docentes (
+            facultad TEXT NOT NULL DEFAULT '` + FacultadPorDefecto + `',
+            periodo TEXT NOT NULL,
+            materia_codigo TEXT NOT NULL,
+            grupo INTEGER NOT NULL DEFAULT 0,
+            docente_cedula TEXT NOT NULL,
+            FOREIGN KEY(facultad, docente_cedula) REFERENCES docentes(facultad, cedula),
+            FOREIGN KEY(facultad, materia_codigo) REFERENCES materias(facultad, codigo),
+            FOREIGN KEY(facultad, periodo) REFERENCES periodos(facultad, codigo),
+            PRIMARY KEY(facultad, periodo, materia_codigo, grupo)
+        )`,
+			`CREATE INDEX idx_asignaciones_docente ON asignaciones_docentes (facultad, periodo, docente_cedula)`,
+		},
+	},
+	{
+		// Las esperas existentes quedan sin grupo
+		version:     19,
+		descripcion: "grupo pedido en las listas de espera",
+		sentencias: []string{
+			`ALTER TABLE lista_espera ADD COLUMN grupo INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+}
+
+// VersionEsquema retorna la versión más reciente del esquema conocida por esta aplicación
+func VersionEsquema() int {
+	return migraciones[len(migraciones)-1].version
+}
+
+// Migrar aplica, en orden y dentro de una transacción cada una, las migraciones aún no registradas
+func Migrar(db *sql.DB, dialecto Dialecto) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+        version INTEGER PRIMARY KEY,
+        descripcion TEXT NOT NULL
+    )`)
+	if err != nil {
+		return fmt.Errorf("error al crear tabla de migraciones: %w", err)
+	}
+
+	actual, err := versionAplicada(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migraciones {
+		if m.version <= actual {
+			continue
+		}
+		if err := aplicarMigracion(db, dialecto, m); err != nil {
+			return fmt.Errorf("error al aplicar migración %d (%s): %w", m.version, m.descripcion, err)
+		}
+	}
+
+	return nil
+}
+
+func versionAplicada(db *sql.DB) (int, error) {
+	var version sql.NullInt64
+	err := db.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("error al consultar versión del esquema: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+func aplicarMigracion(db *sql.DB, dialecto Dialecto, m migracion) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, sentencia := range m.sentenciasPara(dialecto) {
+		if _, err := tx.Exec(sentencia); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		dialecto.rebind("INSERT INTO schema_migrations (version, descripcion) VALUES (?, ?)"),
+		m.version,
+		m.descripcion,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
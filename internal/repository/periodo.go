@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"inscripciones/internal/domain"
+)
+
+// ErrPeriodoInvalido indica un código de periodo que no tiene la forma AAAA-N
+var ErrPeriodoInvalido = errors.New("código de periodo inválido; se espera AAAA-N, como 2026-1")
+
+// PeriodoRepository administra los periodos académicos de la facultad. Siempre hay un
+// periodo actual, en el que se trabaja cuando no se indica otro.
+type PeriodoRepository interface {
+	Create(codigo string) error
+	// GetAll retorna los periodos ordenados del más antiguo al más reciente
+	GetAll() ([]*domain.Periodo, error)
+	Exists(codigo string) (bool, error)
+	Actual() (string, error)
+	// EstablecerActual marca el periodo como actual y desmarca el anterior
+	EstablecerActual(codigo string) error
+}
+
+// normalizarPeriodo valida el código: cuatro dígitos del año, un guion y el número del
+// periodo dentro del año. Así el orden alfabético de los códigos es el cronológico.
+func normalizarPeriodo(codigo string) (string, error) {
+	codigo = strings.TrimSpace(codigo)
+	if len(codigo) != 6 || codigo[4] != '-' || codigo[5] < '1' || codigo[5] > '9' {
+		return "", fmt.Errorf("%w: %q", ErrPeriodoInvalido, codigo)
+	}
+	for _, c := range codigo[:4] {
+		if c < '0' || c > '9' {
+			return "", fmt.Errorf("%w: %q", ErrPeriodoInvalido, codigo)
+		}
+	}
+	return codigo, nil
+}
+
+// Periodo retorna el periodo académico al que están limitadas las inscripciones
+func (r *Repositorios) Periodo() string {
+	return r.acceso.Periodo
+}
+
+// EnPeriodo retorna repositorios sobre las inscripciones de otro periodo de la misma
+// facultad, con el mismo contexto de auditoría. Dentro de una unidad de trabajo, los
+// repositorios retornados pertenecen a ella. Retorna ErrNoEncontrado si el periodo no existe.
+func (r *Repositorios) EnPeriodo(codigo string) (*Repositorios, error) {
+	codigo, err := normalizarPeriodo(codigo)
+	if err != nil {
+		return nil, err
+	}
+	return r.enPeriodo(codigo)
+}
+
+type periodoRepo struct {
+	db       ejecutor
+	dialecto Dialecto
+	facultad string
+}
+
+func (r *periodoRepo) Create(codigo string) error {
+	codigo, err := normalizarPeriodo(codigo)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(r.dialecto.rebind("INSERT INTO periodos (facultad, codigo) VALUES (?, ?)"), r.facultad, codigo)
+	if err = traducirError(err); err != nil {
+		return fmt.Errorf("error al crear periodo %s: %w", codigo, err)
+	}
+	return nil
+}
+
+func (r *periodoRepo) GetAll() ([]*domain.Periodo, error) {
+	rows, err := r.db.Query(r.dialecto.rebind("SELECT codigo, actual FROM periodos WHERE facultad = ? ORDER BY codigo"), r.facultad)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periodos []*domain.Periodo
+	for rows.Next() {
+		var p domain.Periodo
+		var actual int
+		if err := rows.Scan(&p.Codigo, &actual); err != nil {
+			return nil, err
+		}
+		p.Actual = actual == 1
+		periodos = append(periodos, &p)
+	}
+	return periodos, rows.Err()
+}
+
+func (r *periodoRepo) Exists(codigo string) (bool, error) {
+	var existe bool
+	err := r.db.QueryRow(r.dialecto.rebind("SELECT EXISTS(SELECT 1 FROM periodos WHERE facultad = ? AND codigo = ?)"),
+		r.facultad, codigo).Scan(&existe)
+	return existe, err
+}
+
+// Actual retorna el código del periodo actual; vacío si la facultad no tiene periodos
+func (r *periodoRepo) Actual() (string, error) {
+	var codigo string
+	err := r.db.QueryRow(r.dialecto.rebind("SELECT codigo FROM periodos WHERE facultad = ? AND actual = 1"), r.facultad).Scan(&codigo)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return codigo, err
+}
+
+func (r *periodoRepo) EstablecerActual(codigo string) error {
+	return transaccion(r.db, func(tx ejecutor) error {
+		var existe bool
+		err := tx.QueryRow(r.dialecto.rebind("SELECT EXISTS(SELECT 1 FROM periodos WHERE facultad = ? AND codigo = ?)"),
+			r.facultad, codigo).Scan(&existe)
+		if err != nil {
+			return err
+		}
+		if !existe {
+			return fmt.Errorf("%w: periodo %s", ErrNoEncontrado, codigo)
+		}
+		_, err = tx.Exec(r.dialecto.rebind("UPDATE periodos SET actual = CASE WHEN codigo = ? THEN 1 ELSE 0 END WHERE facultad = ?"),
+			codigo, r.facultad)
+		return err
+	})
+}
+
+// periodoVigente retorna el periodo pedido, que debe existir, o el actual de la facultad.
+// Si la facultad todavía no tiene periodo actual, usa el de la fecha de hoy, creándolo si
+// hace falta.
+func periodoVigente(db ejecutor, d Dialecto, facultad, pedido string) (string, error) {
+	periodos := &periodoRepo{db: db, dialecto: d, facultad: facultad}
+	if pedido != "" {
+		codigo, err := normalizarPeriodo(pedido)
+		if err != nil {
+			return "", err
+		}
+		existe, err := periodos.Exists(codigo)
+		if err != nil {
+			return "", fmt.Errorf("error al consultar periodo: %w", err)
+		}
+		if !existe {
+			return "", fmt.Errorf("%w: periodo %s en la facultad %s", ErrNoEncontrado, codigo, facultad)
+		}
+		return codigo, nil
+	}
+
+	actual, err := periodos.Actual()
+	if err != nil || actual != "" {
+		return actual, err
+	}
+	actual = domain.PeriodoDeFecha(time.Now())
+	err = transaccion(db, func(tx ejecutor) error {
+		_, err := tx.Exec(d.rebind(`
+			INSERT INTO periodos (facultad, codigo)
+			SELECT ?, ?
+			WHERE NOT EXISTS(SELECT 1 FROM periodos WHERE facultad = ? AND codigo = ?)
+		`), facultad, actual, facultad, actual)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(d.rebind(`
+			UPDATE periodos SET actual = 1
+			WHERE facultad = ? AND codigo = ?
+			  AND NOT EXISTS(SELECT 1 FROM periodos WHERE facultad = ? AND actual = 1)
+		`), facultad, actual, facultad)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("error al crear el periodo actual: %w", err)
+	}
+	// Otra instancia pudo crear un periodo distinto al mismo tiempo
+	return periodos.Actual()
+}
+
+// periodoPorDefecto resuelve el periodo de los constructores que no retornan errores: el
+// actual de la facultad por defecto o, si no se puede leer, el de la fecha de hoy
+func periodoPorDefecto(db ejecutor, d Dialecto) string {
+	periodo, err := periodoVigente(db, d, FacultadPorDefecto, "")
+	if err != nil || periodo == "" {
+		return domain.PeriodoDeFecha(time.Now())
+	}
+	return periodo
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"inscripciones/internal/domain"
+)
+
+// Una base con inscripciones anteriores a los periodos las conserva en el periodo actual
+func TestMigracionAPeriodos(t *testing.T) {
+	db, err := sql.Open(DriverSQLite, dsnSQLite(filepath.Join(t.TempDir(), "inscripciones.db")))
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, descripcion TEXT NOT NULL)"); err != nil {
+		t.Fatalf("schema_migrations: %v", err)
+	}
+	for _, m := range migraciones {
+		if m.version > 9 {
+			break
+		}
+		if err := aplicarMigracion(db, DialectoSQLite, m); err != nil {
+			t.Fatalf("migración %d: %v", m.version, err)
+		}
+	}
+	for _, sentencia := range []string{
+		"INSERT INTO estudiantes (facultad, cedula, nombre) VALUES ('sede-norte', '1234567', 'Lulú López')",
+		"INSERT INTO materias (facultad, codigo, nombre) VALUES ('sede-norte', '1040', 'Cálculo')",
+		"INSERT INTO inscripciones (facultad, estudiante_cedula, materia_codigo) VALUES ('sede-norte', '1234567', '1040')",
+	} {
+		if _, err := db.Exec(sentencia); err != nil {
+			t.Fatalf("%s: %v", sentencia, err)
+		}
+	}
+
+	if err := Migrar(db, DialectoSQLite); err != nil {
+		t.Fatalf("Migrar: %v", err)
+	}
+
+	norte, err := NewRepositoriosConAcceso(db, DialectoSQLite, Acceso{Facultad: "sede-norte"})
+	if err != nil {
+		t.Fatalf("NewRepositoriosConAcceso: %v", err)
+	}
+	if esperado := domain.PeriodoDeFecha(time.Now()); norte.Periodo() != esperado {
+		t.Fatalf("Periodo = %q, se esperaba el de la fecha de la migración %q", norte.Periodo(), esperado)
+	}
+	inscripciones, err := norte.Inscripciones.GetAll()
+	if err != nil || len(inscripciones) != 1 || inscripciones[0].Periodo != norte.Periodo() {
+		t.Fatalf("GetAll = %+v, %v; se esperaba la inscripción migrada al periodo actual", inscripciones, err)
+	}
+
+	// La misma inscripción puede repetirse en otro periodo
+	if err := norte.Periodos.Create("2020-2"); err != nil {
+		t.Fatalf("Create periodo: %v", err)
+	}
+	anterior, err := norte.EnPeriodo("2020-2")
+	if err != nil {
+		t.Fatalf("EnPeriodo: %v", err)
+	}
+	if err := anterior.Inscripciones.Create("1234567", "1040"); err != nil {
+		t.Fatalf("Create en 2020-2: %v", err)
+	}
+}
+
+func TestAbrirEnPeriodo(t *testing.T) {
+	ruta := filepath.Join(t.TempDir(), "inscripciones.db")
+	repos, err := Abrir(Config{Driver: DriverSQLite, DSN: ruta})
+	if err != nil {
+		t.Fatalf("Abrir: %v", err)
+	}
+	if err := repos.Periodos.Create("2020-2"); err != nil {
+		t.Fatalf("Create periodo: %v", err)
+	}
+	repos.Close()
+
+	repos, err = Abrir(Config{Driver: DriverSQLite, DSN: ruta, Periodo: "2020-2"})
+	if err != nil {
+		t.Fatalf("Abrir en 2020-2: %v", err)
+	}
+	defer repos.Close()
+	if repos.Periodo() != "2020-2" {
+		t.Fatalf("Periodo = %q, se esperaba 2020-2", repos.Periodo())
+	}
+
+	if _, err := Abrir(Config{Driver: DriverSQLite, DSN: ruta, Periodo: "2099-1"}); !errors.Is(err, ErrNoEncontrado) {
+		t.Fatalf("Abrir en un periodo inexistente = %v, se esperaba ErrNoEncontrado", err)
+	}
+}
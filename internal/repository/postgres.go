@@ -0,0 +1,19 @@
+package repository
+
+import "database/sql"
+
+// NewPostgresEstudianteRepository crea un repositorio de estudiantes sobre PostgreSQL
+func NewPostgresEstudianteRepository(db *sql.DB) EstudianteRepository {
+	return &estudianteRepo{db: db, dialecto: DialectoPostgres, facultad: FacultadPorDefecto, auditoria: contextoPorDefecto}
+}
+
+// NewPostgresMateriaRepository crea un repositorio de materias sobre PostgreSQL
+func NewPostgresMateriaRepository(db *sql.DB) MateriaRepository {
+	return &materiaRepo{db: db, dialecto: DialectoPostgres, facultad: FacultadPorDefecto, auditoria: contextoPorDefecto}
+}
+
+// NewPostgresInscripcionRepository crea un repositorio de inscripciones sobre PostgreSQL
+func NewPostgresInscripcionRepository(db *sql.DB) InscripcionRepository {
+	return &inscripcionRepo{db: db, dialecto: DialectoPostgres, facultad: FacultadPorDefecto,
+		periodo: periodoPorDefecto(db, DialectoPostgres), auditoria: contextoPorDefecto}
+}
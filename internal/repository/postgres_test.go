@@ -0,0 +1,47 @@
+package repository_test
+
+import (
+	"os"
+	"testing"
+
+	"inscripciones/internal/repository"
+	"inscripciones/internal/repository/repotest"
+)
+
+// envTestPostgresDSN apunta a una base de datos PostgreSQL local y desechable:
+// las pruebas eliminan y vuelven a crear sus tablas.
+const envTestPostgresDSN = "INSCRIPCIONES_TEST_POSTGRES_DSN"
+
+func nuevosPostgres(t *testing.T) *repository.Repositorios {
+	t.Helper()
+
+	dsn := os.Getenv(envTestPostgresDSN)
+	if dsn == "" {
+		t.Skipf("%s no está definida; se omiten las pruebas contra PostgreSQL", envTestPostgresDSN)
+	}
+
+	db, _, err := repository.Conectar(repository.Config{Driver: repository.DriverPostgres, DSN: dsn})
+	if err != nil {
+		t.Fatalf("no se pudo conectar a PostgreSQL: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// Partir de un esquema limpio en cada prueba
+	_, err = db.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public")
+	if err != nil {
+		t.Fatalf("no se pudo limpiar el esquema: %v", err)
+	}
+	if err := repository.Migrar(db, repository.DialectoPostgres); err != nil {
+		t.Fatalf("no se pudieron aplicar las migraciones: %v", err)
+	}
+
+	repos, err := repository.NewRepositoriosConAcceso(db, repository.DialectoPostgres, repository.Acceso{Administrador: true})
+	if err != nil {
+		t.Fatalf("no se pudieron crear los repositorios: %v", err)
+	}
+	return repos
+}
+
+func TestContratoPostgres(t *testing.T) {
+	repotest.ProbarContrato(t, nuevosPostgres)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"inscripciones/internal/domain"
+)
+
+// ErrCiclo indica un prerrequisito que haría que una materia se requiera a sí misma
+var ErrCiclo = errors.New("el prerrequisito crearía un ciclo entre las materias")
+
+// PrerrequisitoRepository administra los prerrequisitos entre las materias de la facultad,
+// que forman un grafo sin ciclos. Son comunes a todos los periodos y no se auditan.
+type PrerrequisitoRepository interface {
+	// Create agrega el prerrequisito. Retorna ErrReferenciaInvalida si alguna de las materias
+	// no existe o está eliminada, ErrCiclo si cerraría un ciclo y ErrDuplicado si ya estaba.
+	Create(p domain.Prerrequisito) error
+	// Delete quita el prerrequisito; retorna ErrNoEncontrado si no estaba
+	Delete(p domain.Prerrequisito) error
+	// GetGrafo retorna los prerrequisitos entre materias no eliminadas
+	GetGrafo() (domain.GrafoPrerrequisitos, error)
+	// GetAll retorna todos los prerrequisitos, también los de materias eliminadas, ordenados
+	GetAll() ([]domain.Prerrequisito, error)
+}
+
+type prerrequisitoRepo struct {
+	db       ejecutor
+	dialecto Dialecto
+	facultad string
+}
+
+func (r *prerrequisitoRepo) Create(p domain.Prerrequisito) error {
+	err := transaccion(r.db, func(tx ejecutor) error {
+		for _, codigo := range []string{p.Materia, p.Requisito} {
+			var activa bool
+			err := tx.QueryRow(r.dialecto.rebind("SELECT EXISTS(SELECT 1 FROM materias WHERE facultad = ? AND codigo = ? AND deleted_at IS NULL)"),
+				r.facultad, codigo).Scan(&activa)
+			if err != nil {
+				return err
+			}
+			if !activa {
+				return fmt.Errorf("%w: materia %s", ErrReferenciaInvalida, codigo)
+			}
+		}
+
+		// El grafo se lee dentro de la misma transacción que la inserción
+		grafo, err := (&prerrequisitoRepo{db: tx, dialecto: r.dialecto, facultad: r.facultad}).GetGrafo()
+		if err != nil {
+			return err
+		}
+		if grafo.CreaCiclo(p) {
+			return ErrCiclo
+		}
+
+		_, err = tx.Exec(r.dialecto.rebind("INSERT INTO prerrequisitos (facultad, materia_codigo, requisito_codigo) VALUES (?, ?, ?)"),
+			r.facultad, p.Materia, p.Requisito)
+		return traducirError(err)
+	})
+	if err != nil {
+		return fmt.Errorf("error al agregar %s como prerrequisito de %s: %w", p.Requisito, p.Materia, err)
+	}
+	return nil
+}
+
+func (r *prerrequisitoRepo) Delete(p domain.Prerrequisito) error {
+	resultado, err := r.db.Exec(r.dialecto.rebind("DELETE FROM prerrequisitos WHERE facultad = ? AND materia_codigo = ? AND requisito_codigo = ?"),
+		r.facultad, p.Materia, p.Requisito)
+	if err != nil {
+		return fmt.Errorf("error al quitar %s como prerrequisito de %s: %w", p.Requisito, p.Materia, err)
+	}
+	filas, err := resultado.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if filas == 0 {
+		return fmt.Errorf("%w: %s no es prerrequisito de %s", ErrNoEncontrado, p.Requisito, p.Materia)
+	}
+	return nil
+}
+
+func (r *prerrequisitoRepo) GetGrafo() (domain.GrafoPrerrequisitos, error) {
+	prerrequisitos, err := r.consultar(`
+		SELECT p.materia_codigo, p.requisito_codigo
+		FROM prerrequisitos p
+		JOIN materias m ON m.facultad = p.facultad AND m.codigo = p.materia_codigo
+		JOIN materias req ON req.facultad = p.facultad AND req.codigo = p.requisito_codigo
+		WHERE p.facultad = ? AND m.deleted_at IS NULL AND req.deleted_at IS NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewGrafoPrerrequisitos(prerrequisitos), nil
+}
+
+func (r *prerrequisitoRepo) GetAll() ([]domain.Prerrequisito, error) {
+	return r.consultar("SELECT materia_codigo, requisito_codigo FROM prerrequisitos WHERE facultad = ? ORDER BY materia_codigo, requisito_codigo")
+}
+
+func (r *prerrequisitoRepo) consultar(consulta string) ([]domain.Prerrequisito, error) {
+	rows, err := r.db.Query(r.dialecto.rebind(consulta), r.facultad)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prerrequisitos []domain.Prerrequisito
+	for rows.Next() {
+		var p domain.Prerrequisito
+		if err := rows.Scan(&p.Materia, &p.Requisito); err != nil {
+			return nil, err
+		}
+		prerrequisitos = append(prerrequisitos, p)
+	}
+	return prerrequisitos, rows.Err()
+}
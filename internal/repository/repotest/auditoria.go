@@ -0,0 +1,78 @@
+package repotest
+
+import (
+	"strings"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func probarAuditoria(t *testing.T, nuevos Fabrica) {
+	t.Run("HistorialDeCambios", func(t *testing.T) {
+		base := nuevos(t)
+		consola := base.ConAuditoria(repository.ContextoAuditoria{Actor: "coordinadora", Origen: domain.OrigenConsola})
+		archivo := base.ConAuditoria(repository.ContextoAuditoria{Actor: "carga", Origen: domain.OrigenArchivo})
+
+		if err := consola.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+			t.Fatalf("Create estudiante: %v", err)
+		}
+		if err := archivo.Materias.Create(domain.NewMateria("1040", "Cálculo")); err != nil {
+			t.Fatalf("Create materia: %v", err)
+		}
+		if err := archivo.Inscripciones.Create("1234567", "1040"); err != nil {
+			t.Fatalf("Create inscripción: %v", err)
+		}
+		// Un cambio rechazado no deja rastro
+		if err := consola.Estudiantes.Create(domain.NewEstudiante("1234567", "Otro")); err == nil {
+			t.Fatal("se esperaba error al duplicar el estudiante")
+		}
+
+		historial, err := base.Auditoria.HistorialEstudiante("1234567")
+		if err != nil {
+			t.Fatalf("HistorialEstudiante: %v", err)
+		}
+		if len(historial) != 2 {
+			t.Fatalf("HistorialEstudiante retornó %d registros, se esperaban 2", len(historial))
+		}
+
+		alta := historial[0]
+		if alta.Entidad != domain.EntidadEstudiante || alta.Operacion != domain.OperacionCrear || alta.Clave != "1234567" {
+			t.Errorf("primer registro = %+v, se esperaba el alta del estudiante", alta)
+		}
+		if alta.Actor != "coordinadora" || alta.Origen != domain.OrigenConsola {
+			t.Errorf("actor/origen = %s/%s, se esperaba coordinadora/consola", alta.Actor, alta.Origen)
+		}
+		if alta.Antes != "" || !strings.Contains(alta.Despues, "Lulú López") {
+			t.Errorf("valores antes/después = %q / %q", alta.Antes, alta.Despues)
+		}
+		if alta.Fecha.IsZero() {
+			t.Error("el registro no tiene fecha")
+		}
+
+		inscripcion := historial[1]
+		if inscripcion.Entidad != domain.EntidadInscripcion || inscripcion.Origen != domain.OrigenArchivo {
+			t.Errorf("segundo registro = %+v, se esperaba la inscripción desde archivo", inscripcion)
+		}
+		if inscripcion.ID <= alta.ID || inscripcion.Fecha.Before(alta.Fecha) {
+			t.Errorf("el historial no está en orden cronológico: %+v, %+v", alta, inscripcion)
+		}
+
+		historial, err = base.Auditoria.HistorialMateria("1040")
+		if err != nil {
+			t.Fatalf("HistorialMateria: %v", err)
+		}
+		if len(historial) != 2 || historial[0].Entidad != domain.EntidadMateria || historial[1].Entidad != domain.EntidadInscripcion {
+			t.Fatalf("HistorialMateria = %d registros, se esperaba el alta de la materia y la inscripción", len(historial))
+		}
+	})
+
+	t.Run("SinHistorial", func(t *testing.T) {
+		repos := nuevos(t)
+
+		historial, err := repos.Auditoria.HistorialEstudiante("0000000")
+		if err != nil || len(historial) != 0 {
+			t.Fatalf("HistorialEstudiante inexistente = %v, %v; se esperaba vacío", historial, err)
+		}
+	})
+}
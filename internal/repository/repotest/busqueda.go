@@ -0,0 +1,90 @@
+package repotest
+
+import (
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func probarBusqueda(t *testing.T, nuevos Fabrica) {
+	poblar := func(t *testing.T) *repository.Repositorios {
+		t.Helper()
+		repos := nuevos(t)
+		for _, e := range []*domain.Estudiante{
+			domain.NewEstudiante("1234567", "Lulú López"),
+			domain.NewEstudiante("7654321", "Lulú López Gómez"),
+			domain.NewEstudiante("9876534", "Pepito Pérez"),
+			domain.NewEstudiante("3333333", "MARÍA RODRÍGUEZ"),
+		} {
+			if err := repos.Estudiantes.Create(e); err != nil {
+				t.Fatalf("Create estudiante %s: %v", e.Cedula, err)
+			}
+		}
+		for _, m := range []*domain.Materia{
+			domain.NewMateria("1050", "Física I"),
+			domain.NewMateria("1051", "Física II"),
+			domain.NewMateria("1040", "Cálculo"),
+		} {
+			if err := repos.Materias.Create(m); err != nil {
+				t.Fatalf("Create materia %s: %v", m.Codigo, err)
+			}
+		}
+		return repos
+	}
+
+	t.Run("EstudiantesSinTildes", func(t *testing.T) {
+		repos := poblar(t)
+
+		encontrados, err := repos.Estudiantes.Search("lulu lopez", 0)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		// La coincidencia exacta es más relevante que el nombre con palabras de sobra
+		verificarOrden(t, cedulas(encontrados), []string{"1234567", "7654321"})
+		if encontrados[0].Nombre != "Lulú López" {
+			t.Errorf("Search no retorna el nombre original: %+v", encontrados[0])
+		}
+
+		encontrados, err = repos.Estudiantes.Search("maria rodri", 0)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		verificarOrden(t, cedulas(encontrados), []string{"3333333"})
+
+		encontrados, err = repos.Estudiantes.Search("Pérez", 1)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		verificarOrden(t, cedulas(encontrados), []string{"9876534"})
+	})
+
+	t.Run("MateriasSinTildes", func(t *testing.T) {
+		repos := poblar(t)
+
+		encontradas, err := repos.Materias.Search("fisica", 0)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		verificarOrden(t, codigos(encontradas), []string{"1050", "1051"})
+
+		encontradas, err = repos.Materias.Search("FIS", 1)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if len(encontradas) != 1 {
+			t.Fatalf("Search con límite 1 retornó %d resultados", len(encontradas))
+		}
+	})
+
+	t.Run("SinCoincidencias", func(t *testing.T) {
+		repos := poblar(t)
+
+		for _, texto := range []string{"quimica", "lulu perez", "", "  ,; "} {
+			encontrados, err := repos.Estudiantes.Search(texto, 0)
+			if err != nil || len(encontrados) != 0 {
+				t.Fatalf("Search(%q) = %v, %v; se esperaba vacío", texto, encontrados, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,267 @@
+package repotest
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// Componentes de la evaluación de Cálculo: los parciales valen 60% y el final 40%
+var (
+	parcialesCalculo = domain.Componente{Materia: "1040", Nombre: "Parciales", Peso: 60}
+	finalCalculo     = domain.Componente{Materia: "1040", Nombre: "Final", Peso: 40}
+)
+
+// evaluacionCalculo crea Cálculo con sus dos componentes e inscribe en ella a Lulú y a Pepito
+func evaluacionCalculo(t *testing.T, repos *repository.Repositorios) {
+	t.Helper()
+	estudiantesCupos(t, repos)
+	materiaConCupo(t, repos, "1040", "Cálculo", domain.SinCupoLimite)
+	for _, c := range []domain.Componente{parcialesCalculo, finalCalculo} {
+		if err := repos.Componentes.Create(c); err != nil {
+			t.Fatalf("Create componente %s: %v", c.Nombre, err)
+		}
+	}
+	for _, cedula := range []string{"1234567", "9876534"} {
+		if err := repos.Inscripciones.Create(cedula, "1040"); err != nil {
+			t.Fatalf("Create inscripción %s: %v", cedula, err)
+		}
+	}
+}
+
+func probarCalificaciones(t *testing.T, nuevos Fabrica) {
+	t.Run("Componentes", func(t *testing.T) {
+		repos := nuevos(t)
+		evaluacionCalculo(t, repos)
+
+		componentes, err := repos.Componentes.GetByMateria("1040")
+		if err != nil {
+			t.Fatalf("GetByMateria: %v", err)
+		}
+		if !reflect.DeepEqual(componentes, []domain.Componente{finalCalculo, parcialesCalculo}) {
+			t.Fatalf("GetByMateria = %+v, se esperaban el final y los parciales", componentes)
+		}
+
+		// Los pesos de una materia no pasan del 100%, ni al crear ni al cambiar
+		quiz := domain.Componente{Materia: "1040", Nombre: "Quices", Peso: 10}
+		if err := repos.Componentes.Create(quiz); !errors.Is(err, repository.ErrPesoExcedido) {
+			t.Fatalf("Create por encima del 100%% = %v, se esperaba ErrPesoExcedido", err)
+		}
+		final := finalCalculo
+		final.Peso = 30
+		if err := repos.Componentes.CambiarPeso(final); err != nil {
+			t.Fatalf("CambiarPeso: %v", err)
+		}
+		if err := repos.Componentes.Create(quiz); err != nil {
+			t.Fatalf("Create con el peso disponible: %v", err)
+		}
+		parciales := parcialesCalculo
+		parciales.Peso = 70
+		if err := repos.Componentes.CambiarPeso(parciales); !errors.Is(err, repository.ErrPesoExcedido) {
+			t.Fatalf("CambiarPeso por encima del 100%% = %v, se esperaba ErrPesoExcedido", err)
+		}
+		if err := repos.Componentes.Create(parcialesCalculo); !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Create repetido = %v, se esperaba ErrDuplicado", err)
+		}
+		if err := repos.Componentes.Create(domain.Componente{Materia: "9999", Nombre: "Final", Peso: 40}); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Create en una materia inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+
+		// Los componentes son comunes a todos los periodos
+		if componentes, _ := otroPeriodo(t, repos, "2020-2").Componentes.GetByMateria("1040"); len(componentes) != 3 {
+			t.Fatalf("GetByMateria en 2020-2 = %+v, se esperaban los mismos componentes", componentes)
+		}
+
+		if err := repos.Componentes.Delete("1040", "Quices"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Componentes.Delete("1040", "Quices"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Delete repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if err := repos.Componentes.CambiarPeso(quiz); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("CambiarPeso de un componente eliminado = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if todos, _ := repos.Componentes.GetAll(); !reflect.DeepEqual(todos, []domain.Componente{final, parcialesCalculo}) {
+			t.Fatalf("GetAll = %+v, se esperaban el final al 30%% y los parciales", todos)
+		}
+	})
+
+	t.Run("RegistrarYConsultar", func(t *testing.T) {
+		repos := nuevos(t)
+		evaluacionCalculo(t, repos)
+
+		for _, n := range []struct {
+			cedula, componente string
+			nota               float64
+		}{
+			{"9876534", "Parciales", 3.2},
+			{"1234567", "Parciales", 4.5},
+			{"1234567", "Final", 3.75},
+		} {
+			if err := repos.Calificaciones.Registrar(n.cedula, "1040", n.componente, n.nota); err != nil {
+				t.Fatalf("Registrar %s %s: %v", n.cedula, n.componente, err)
+			}
+		}
+		// Registrar otra vez reemplaza la nota
+		if err := repos.Calificaciones.Registrar("9876534", "1040", "Parciales", 2.9); err != nil {
+			t.Fatalf("Registrar de nuevo: %v", err)
+		}
+
+		notas, err := repos.Calificaciones.GetByInscripcion("1234567", "1040")
+		if err != nil {
+			t.Fatalf("GetByInscripcion: %v", err)
+		}
+		periodo := repos.Periodo()
+		esperadas := []domain.Calificacion{
+			{Cedula: "1234567", Materia: "1040", Periodo: periodo, Componente: "Final", Nota: 3.75},
+			{Cedula: "1234567", Materia: "1040", Periodo: periodo, Componente: "Parciales", Nota: 4.5},
+		}
+		if !reflect.DeepEqual(notas, esperadas) {
+			t.Fatalf("GetByInscripcion = %+v, se esperaba %+v", notas, esperadas)
+		}
+		notas, _ = repos.Calificaciones.GetByMateria("1040")
+		esperadas = append(esperadas, domain.Calificacion{Cedula: "9876534", Materia: "1040", Periodo: periodo, Componente: "Parciales", Nota: 2.9})
+		if !reflect.DeepEqual(notas, esperadas) {
+			t.Fatalf("GetByMateria = %+v, se esperaba %+v", notas, esperadas)
+		}
+
+		// Sin inscripción vigente o sin componente no hay nota
+		if err := repos.Calificaciones.Registrar("5555555", "1040", "Final", 4); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Registrar sin inscripción = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if err := repos.Calificaciones.Registrar("1234567", "1040", "Quices", 4); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Registrar en un componente inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if err := otroPeriodo(t, repos, "2020-2").Calificaciones.Registrar("1234567", "1040", "Final", 4); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Registrar en un periodo sin la inscripción = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+
+		// El historial reúne las notas del estudiante en todos los periodos
+		anterior := otroPeriodo(t, repos, "2020-2")
+		if err := anterior.Inscripciones.Create("1234567", "1040"); err != nil {
+			t.Fatalf("Create inscripción en 2020-2: %v", err)
+		}
+		if err := anterior.Calificaciones.Registrar("1234567", "1040", "Final", 2); err != nil {
+			t.Fatalf("Registrar en 2020-2: %v", err)
+		}
+		historial, err := repos.Calificaciones.HistorialByEstudiante("1234567")
+		if err != nil {
+			t.Fatalf("HistorialByEstudiante: %v", err)
+		}
+		esperadas = append([]domain.Calificacion{{Cedula: "1234567", Materia: "1040", Periodo: "2020-2", Componente: "Final", Nota: 2}}, esperadas[:2]...)
+		if !reflect.DeepEqual(historial, esperadas) {
+			t.Fatalf("HistorialByEstudiante = %+v, se esperaba %+v", historial, esperadas)
+		}
+
+		// Un componente con notas no se puede quitar
+		if err := repos.Componentes.Delete("1040", "Final"); !errors.Is(err, repository.ErrComponenteConNotas) {
+			t.Fatalf("Delete componente con notas = %v, se esperaba ErrComponenteConNotas", err)
+		}
+
+		// Las notas de una inscripción cancelada se conservan, pero no cuentan en la materia
+		if err := repos.Inscripciones.Delete("9876534", "1040"); err != nil {
+			t.Fatalf("Delete inscripción: %v", err)
+		}
+		if notas, _ := repos.Calificaciones.GetByMateria("1040"); len(notas) != 2 {
+			t.Fatalf("GetByMateria tras cancelar = %+v, se esperaban solo las notas de Lulú", notas)
+		}
+		if notas, _ := repos.Calificaciones.GetAll(); len(notas) != 3 {
+			t.Fatalf("GetAll = %+v, se esperaban las 3 notas", notas)
+		}
+
+		if err := repos.Calificaciones.Delete("1234567", "1040", "Final"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Calificaciones.Delete("1234567", "1040", "Final"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Delete repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+	})
+
+	t.Run("Auditoria", func(t *testing.T) {
+		repos := nuevos(t)
+		evaluacionCalculo(t, repos)
+
+		for _, nota := range []float64{3.5, 3.5, 4} {
+			if err := repos.Calificaciones.Registrar("1234567", "1040", "Final", nota); err != nil {
+				t.Fatalf("Registrar: %v", err)
+			}
+		}
+		if err := repos.Calificaciones.Delete("1234567", "1040", "Final"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		// Registrar la misma nota no es un cambio
+		historial, err := repos.Auditoria.HistorialEstudiante("1234567")
+		if err != nil {
+			t.Fatalf("HistorialEstudiante: %v", err)
+		}
+		var operaciones []string
+		for _, r := range historial {
+			if r.Entidad == domain.EntidadCalificacion {
+				operaciones = append(operaciones, r.Operacion)
+			}
+		}
+		esperadas := []string{domain.OperacionCrear, domain.OperacionActualizar, domain.OperacionEliminar}
+		if !reflect.DeepEqual(operaciones, esperadas) {
+			t.Fatalf("operaciones sobre la nota = %v, se esperaba %v", operaciones, esperadas)
+		}
+		if cambio := historial[len(historial)-2]; !strings.Contains(cambio.Antes, `"nota":"3.50"`) || !strings.Contains(cambio.Despues, `"nota":"4.00"`) {
+			t.Fatalf("cambio de nota = %s -> %s, se esperaba de 3.50 a 4.00", cambio.Antes, cambio.Despues)
+		}
+		if historial, _ := repos.Auditoria.HistorialMateria("1040"); historial[len(historial)-1].Entidad != domain.EntidadCalificacion {
+			t.Fatalf("la eliminación de la nota no quedó en el historial de la materia: %+v", historial[len(historial)-1])
+		}
+	})
+
+	t.Run("Volcado", func(t *testing.T) {
+		origen := nuevos(t)
+		evaluacionCalculo(t, origen)
+		if err := origen.Calificaciones.Registrar("1234567", "1040", "Parciales", 4.25); err != nil {
+			t.Fatalf("Registrar: %v", err)
+		}
+
+		var script bytes.Buffer
+		if err := origen.Volcar(&script); err != nil {
+			t.Fatalf("Volcar: %v", err)
+		}
+		if !strings.Contains(script.String(), "'Parciales', '425')") {
+			t.Fatalf("el volcado no trae la nota en centésimas:\n%s", script.String())
+		}
+		destino := nuevos(t)
+		if err := destino.Cargar(strings.NewReader(script.String())); err != nil {
+			t.Fatalf("Cargar: %v", err)
+		}
+		if componentes, _ := destino.Componentes.GetAll(); !reflect.DeepEqual(componentes, []domain.Componente{finalCalculo, parcialesCalculo}) {
+			t.Fatalf("Componentes tras cargar = %+v, se esperaban los dos", componentes)
+		}
+		if notas, _ := destino.Calificaciones.GetByInscripcion("1234567", "1040"); len(notas) != 1 || notas[0].Nota != 4.25 {
+			t.Fatalf("GetByInscripcion tras cargar = %+v, se esperaba la nota de 4.25", notas)
+		}
+
+		// Una nota sin inscripción o unos pesos por encima del 100% se rechazan antes de cargar nada
+		materia := "INSERT INTO materias (codigo, nombre, deleted_at) VALUES ('1040', 'Cálculo', NULL);\n"
+		vacio := nuevos(t)
+		for nombre, script := range map[string]string{
+			"una nota sin inscripción": materia +
+				"INSERT INTO componentes (materia_codigo, nombre, peso) VALUES ('1040', 'Final', '40');\n" +
+				"INSERT INTO calificaciones (estudiante_cedula, materia_codigo, periodo, componente, nota) VALUES ('1234567', '1040', '2026-1', 'Final', '300');\n",
+			"pesos por encima del 100%": materia +
+				"INSERT INTO componentes (materia_codigo, nombre, peso) VALUES ('1040', 'Final', '60');\n" +
+				"INSERT INTO componentes (materia_codigo, nombre, peso) VALUES ('1040', 'Parciales', '60');\n",
+			"una nota fuera de la escala": materia +
+				"INSERT INTO calificaciones (estudiante_cedula, materia_codigo, periodo, componente, nota) VALUES ('1234567', '1040', '2026-1', 'Final', '510');\n",
+		} {
+			if err := vacio.Cargar(strings.NewReader(script)); !errors.Is(err, repository.ErrVolcadoInvalido) {
+				t.Fatalf("Cargar con %s = %v, se esperaba ErrVolcadoInvalido", nombre, err)
+			}
+		}
+		if existe, _ := vacio.Materias.Exists("1040"); existe {
+			t.Fatal("el volcado rechazado dejó datos a medias")
+		}
+	})
+}
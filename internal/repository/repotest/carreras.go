@@ -0,0 +1,260 @@
+package repotest
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// sistemas es la carrera de las pruebas: Cálculo y Física son obligatorias y Administración electiva
+var sistemas = domain.Carrera{Codigo: "IS", Nombre: "Ingeniería de Sistemas", Creditos: 160}
+
+// pensumSistemas crea la carrera, sus materias y su pensum, y a Lulú, Pepito y Ana
+func pensumSistemas(t *testing.T, repos *repository.Repositorios) []domain.MateriaPensum {
+	t.Helper()
+	estudiantesCupos(t, repos)
+	for _, m := range [][2]string{{"1040", "Cálculo"}, {"1050", "Física I"}, {"1060", "Administración"}} {
+		materiaConCupo(t, repos, m[0], m[1], domain.SinCupoLimite)
+	}
+	carrera := sistemas
+	if err := repos.Carreras.Create(&carrera); err != nil {
+		t.Fatalf("Create carrera: %v", err)
+	}
+	pensum := []domain.MateriaPensum{
+		{Carrera: "IS", Materia: "1040", Semestre: 1, Obligatoria: true},
+		{Carrera: "IS", Materia: "1060", Semestre: 1, Obligatoria: false},
+		{Carrera: "IS", Materia: "1050", Semestre: 2, Obligatoria: true},
+	}
+	for _, m := range pensum {
+		if err := repos.Carreras.AgregarAlPensum(m); err != nil {
+			t.Fatalf("AgregarAlPensum %s: %v", m.Materia, err)
+		}
+	}
+	return pensum
+}
+
+func probarCarreras(t *testing.T, nuevos Fabrica) {
+	t.Run("CarrerasYPensum", func(t *testing.T) {
+		repos := nuevos(t)
+		pensum := pensumSistemas(t, repos)
+
+		carrera, err := repos.Carreras.GetByCodigo("IS")
+		if err != nil || carrera == nil || *carrera != sistemas {
+			t.Fatalf("GetByCodigo = %+v, %v; se esperaba %+v", carrera, err, sistemas)
+		}
+		if carrera, _ := repos.Carreras.GetByCodigo("XX"); carrera != nil {
+			t.Fatalf("GetByCodigo de una carrera inexistente = %+v, se esperaba nil", carrera)
+		}
+		if err := repos.Carreras.Create(&domain.Carrera{Codigo: "IS", Nombre: "Otra", Creditos: 10}); !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Create repetido = %v, se esperaba ErrDuplicado", err)
+		}
+		cambiada := domain.Carrera{Codigo: "IS", Nombre: "Ingeniería de Sistemas y Computación", Creditos: 170}
+		if err := repos.Carreras.Update(&cambiada); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if err := repos.Carreras.Update(&domain.Carrera{Codigo: "XX", Nombre: "Nada", Creditos: 1}); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Update de una carrera inexistente = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if todas, _ := repos.Carreras.GetAll(); len(todas) != 1 || *todas[0] != cambiada {
+			t.Fatalf("GetAll = %+v, se esperaba la carrera actualizada", todas)
+		}
+
+		// El pensum se ordena por semestre y materia, y es común a todos los periodos
+		if obtenido, _ := otroPeriodo(t, repos, "2020-2").Carreras.GetPensum("IS"); !reflect.DeepEqual(obtenido, pensum) {
+			t.Fatalf("GetPensum = %+v, se esperaba %+v", obtenido, pensum)
+		}
+		if err := repos.Carreras.AgregarAlPensum(pensum[0]); !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("AgregarAlPensum repetido = %v, se esperaba ErrDuplicado", err)
+		}
+		for nombre, m := range map[string]domain.MateriaPensum{
+			"una carrera inexistente": {Carrera: "XX", Materia: "1040", Semestre: 1},
+			"una materia inexistente": {Carrera: "IS", Materia: "9999", Semestre: 1},
+		} {
+			if err := repos.Carreras.AgregarAlPensum(m); !errors.Is(err, repository.ErrReferenciaInvalida) {
+				t.Fatalf("AgregarAlPensum con %s = %v, se esperaba ErrReferenciaInvalida", nombre, err)
+			}
+		}
+
+		if err := repos.Carreras.QuitarDelPensum("IS", "1060"); err != nil {
+			t.Fatalf("QuitarDelPensum: %v", err)
+		}
+		if err := repos.Carreras.QuitarDelPensum("IS", "1060"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("QuitarDelPensum repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if todos, _ := repos.Carreras.GetAllPensum(); !reflect.DeepEqual(todos, []domain.MateriaPensum{pensum[0], pensum[2]}) {
+			t.Fatalf("GetAllPensum = %+v, se esperaban Cálculo y Física", todos)
+		}
+
+		// Eliminar la carrera se lleva su pensum
+		if err := repos.Carreras.Delete("IS"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Carreras.Delete("IS"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Delete repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if todos, _ := repos.Carreras.GetAllPensum(); len(todos) != 0 {
+			t.Fatalf("GetAllPensum tras eliminar la carrera = %+v, se esperaba vacío", todos)
+		}
+	})
+
+	t.Run("Estudiantes", func(t *testing.T) {
+		repos := nuevos(t)
+		pensumSistemas(t, repos)
+		if err := repos.Carreras.Create(&domain.Carrera{Codigo: "AD", Nombre: "Administración", Creditos: 150}); err != nil {
+			t.Fatalf("Create carrera: %v", err)
+		}
+
+		for _, cedula := range []string{"9876534", "1234567"} {
+			if err := repos.Carreras.AsignarEstudiante(cedula, "IS"); err != nil {
+				t.Fatalf("AsignarEstudiante %s: %v", cedula, err)
+			}
+		}
+		if err := repos.Carreras.AsignarEstudiante("5555555", "AD"); err != nil {
+			t.Fatalf("AsignarEstudiante: %v", err)
+		}
+		estudiantes, err := repos.Carreras.GetEstudiantes("IS")
+		if err != nil {
+			t.Fatalf("GetEstudiantes: %v", err)
+		}
+		if len(estudiantes) != 2 || estudiantes[0].Cedula != "1234567" || estudiantes[1].Nombre != "Pepito Pérez" {
+			t.Fatalf("GetEstudiantes = %+v, se esperaban Lulú y Pepito", estudiantes)
+		}
+
+		// Cada estudiante tiene una sola carrera: asignarlo otra vez lo cambia de carrera
+		if err := repos.Carreras.AsignarEstudiante("9876534", "AD"); err != nil {
+			t.Fatalf("AsignarEstudiante a otra carrera: %v", err)
+		}
+		if carrera, _ := repos.Carreras.CarreraDeEstudiante("9876534"); carrera != "AD" {
+			t.Fatalf("CarreraDeEstudiante = %q, se esperaba AD", carrera)
+		}
+		if estudiantes, _ := repos.Carreras.GetEstudiantes("IS"); len(estudiantes) != 1 {
+			t.Fatalf("GetEstudiantes tras el cambio = %+v, se esperaba solo a Lulú", estudiantes)
+		}
+
+		for nombre, asignacion := range map[string][2]string{
+			"un estudiante inexistente": {"0000000", "IS"},
+			"una carrera inexistente":   {"1234567", "XX"},
+		} {
+			if err := repos.Carreras.AsignarEstudiante(asignacion[0], asignacion[1]); !errors.Is(err, repository.ErrReferenciaInvalida) {
+				t.Fatalf("AsignarEstudiante con %s = %v, se esperaba ErrReferenciaInvalida", nombre, err)
+			}
+		}
+		if err := repos.Carreras.Delete("AD"); !errors.Is(err, repository.ErrCarreraConEstudiantes) {
+			t.Fatalf("Delete con estudiantes = %v, se esperaba ErrCarreraConEstudiantes", err)
+		}
+
+		// Un estudiante eliminado sigue en su carrera, pero no aparece entre sus estudiantes
+		if err := repos.Estudiantes.Delete("5555555"); err != nil {
+			t.Fatalf("Delete estudiante: %v", err)
+		}
+		if estudiantes, _ := repos.Carreras.GetEstudiantes("AD"); len(estudiantes) != 1 || estudiantes[0].Cedula != "9876534" {
+			t.Fatalf("GetEstudiantes con un eliminado = %+v, se esperaba solo a Pepito", estudiantes)
+		}
+		esperadas := []domain.AsignacionCarrera{{Cedula: "1234567", Carrera: "IS"}, {Cedula: "5555555", Carrera: "AD"}, {Cedula: "9876534", Carrera: "AD"}}
+		if asignaciones, _ := repos.Carreras.GetAsignaciones(); !reflect.DeepEqual(asignaciones, esperadas) {
+			t.Fatalf("GetAsignaciones = %+v, se esperaba %+v", asignaciones, esperadas)
+		}
+
+		if err := repos.Carreras.QuitarEstudiante("9876534"); err != nil {
+			t.Fatalf("QuitarEstudiante: %v", err)
+		}
+		if err := repos.Carreras.QuitarEstudiante("9876534"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("QuitarEstudiante repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if carrera, _ := repos.Carreras.CarreraDeEstudiante("9876534"); carrera != "" {
+			t.Fatalf("CarreraDeEstudiante tras quitarlo = %q, se esperaba vacío", carrera)
+		}
+
+		// La asignación, el cambio de carrera y el retiro quedan en el historial del estudiante
+		historial, err := repos.Auditoria.HistorialEstudiante("9876534")
+		if err != nil {
+			t.Fatalf("HistorialEstudiante: %v", err)
+		}
+		var operaciones []string
+		for _, r := range historial {
+			if r.Entidad == domain.EntidadCarrera {
+				operaciones = append(operaciones, r.Operacion)
+			}
+		}
+		if esperadas := []string{domain.OperacionCrear, domain.OperacionActualizar, domain.OperacionEliminar}; !reflect.DeepEqual(operaciones, esperadas) {
+			t.Fatalf("operaciones sobre la carrera = %v, se esperaba %v", operaciones, esperadas)
+		}
+		if cambio := historial[len(historial)-2]; !strings.Contains(cambio.Antes, `"carrera_codigo":"IS"`) || !strings.Contains(cambio.Despues, `"carrera_codigo":"AD"`) {
+			t.Fatalf("cambio de carrera = %s -> %s, se esperaba de IS a AD", cambio.Antes, cambio.Despues)
+		}
+	})
+
+	t.Run("Volcado", func(t *testing.T) {
+		origen := nuevos(t)
+		pensum := pensumSistemas(t, origen)
+		if err := origen.Carreras.AsignarEstudiante("1234567", "IS"); err != nil {
+			t.Fatalf("AsignarEstudiante: %v", err)
+		}
+
+		var script bytes.Buffer
+		if err := origen.Volcar(&script); err != nil {
+			t.Fatalf("Volcar: %v", err)
+		}
+		if !strings.Contains(script.String(), "'IS', '1060', '1', '0')") {
+			t.Fatalf("el volcado no trae la electiva con obligatoria en 0:\n%s", script.String())
+		}
+		destino := nuevos(t)
+		if err := destino.Cargar(strings.NewReader(script.String())); err != nil {
+			t.Fatalf("Cargar: %v", err)
+		}
+		if carrera, _ := destino.Carreras.GetByCodigo("IS"); carrera == nil || *carrera != sistemas {
+			t.Fatalf("GetByCodigo tras cargar = %+v, se esperaba %+v", carrera, sistemas)
+		}
+		if obtenido, _ := destino.Carreras.GetPensum("IS"); !reflect.DeepEqual(obtenido, pensum) {
+			t.Fatalf("GetPensum tras cargar = %+v, se esperaba %+v", obtenido, pensum)
+		}
+		if carrera, _ := destino.Carreras.CarreraDeEstudiante("1234567"); carrera != "IS" {
+			t.Fatalf("CarreraDeEstudiante tras cargar = %q, se esperaba IS", carrera)
+		}
+
+		// Un semestre fuera del rango o un estudiante en una carrera inexistente se rechazan
+		vacio := nuevos(t)
+		for nombre, script := range map[string]string{
+			"un semestre fuera del rango": "INSERT INTO carreras (codigo, nombre, creditos) VALUES ('IS', 'Sistemas', '160');\n" +
+				"INSERT INTO pensum (carrera_codigo, materia_codigo, semestre, obligatoria) VALUES ('IS', '1040', '13', '1');\n",
+			"una carrera sin créditos": "INSERT INTO carreras (codigo, nombre, creditos) VALUES ('IS', 'Sistemas', '0');\n",
+		} {
+			if err := vacio.Cargar(strings.NewReader(script)); !errors.Is(err, repository.ErrVolcadoInvalido) {
+				t.Fatalf("Cargar con %s = %v, se esperaba ErrVolcadoInvalido", nombre, err)
+			}
+		}
+		script.Reset()
+		script.WriteString("INSERT INTO estudiantes (cedula, nombre, deleted_at) VALUES ('1234567', 'Lulú López', NULL);\n" +
+			"INSERT INTO carrera_estudiantes (estudiante_cedula, carrera_codigo) VALUES ('1234567', 'XX');\n")
+		if err := vacio.Cargar(&script); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Cargar con una carrera inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if existe, _ := vacio.Estudiantes.Exists("1234567"); existe {
+			t.Fatal("el volcado rechazado dejó datos a medias")
+		}
+	})
+
+	t.Run("BaseSoloConCarreras", func(t *testing.T) {
+		repos := nuevos(t)
+		carrera := sistemas
+		if err := repos.Carreras.Create(&carrera); err != nil {
+			t.Fatalf("Create carrera: %v", err)
+		}
+		for nombre, script := range map[string]string{
+			"la misma carrera": "INSERT INTO carreras (codigo, nombre, creditos) VALUES ('IS', 'Sistemas', '160');\n",
+			"otros datos":      "INSERT INTO estudiantes (cedula, nombre, deleted_at) VALUES ('1234567', 'Lulú López', NULL);\n",
+		} {
+			if err := repos.Cargar(strings.NewReader(script)); !errors.Is(err, repository.ErrBaseNoVacia) {
+				t.Fatalf("Cargar %s sobre una base con carreras = %v, se esperaba ErrBaseNoVacia", nombre, err)
+			}
+		}
+		if existe, _ := repos.Estudiantes.Exists("1234567"); existe {
+			t.Fatal("el volcado rechazado dejó datos a medias")
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func probarConcurrencia(t *testing.T, nuevos Fabrica) {
+	t.Run("CreateAsignaVersionInicial", func(t *testing.T) {
+		repos := nuevos(t)
+		e := domain.NewEstudiante("1234567", "Lulú López")
+		if err := repos.Estudiantes.Create(e); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if e.Version != 1 {
+			t.Fatalf("Version tras Create = %d, se esperaba 1", e.Version)
+		}
+		if leido, _ := repos.Estudiantes.GetByCedula("1234567"); leido == nil || leido.Version != 1 {
+			t.Fatalf("GetByCedula = %+v, se esperaba la versión 1", leido)
+		}
+	})
+
+	t.Run("UpdateEstudiante", func(t *testing.T) {
+		repos := nuevos(t)
+		if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulu Lopez")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		e, _ := repos.Estudiantes.GetByCedula("1234567")
+		e.Nombre = "Lulú López"
+		if err := repos.Estudiantes.Update(e); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if e.Version != 2 {
+			t.Fatalf("Version tras Update = %d, se esperaba 2", e.Version)
+		}
+
+		// Todas las lecturas ven el nombre y la versión nuevos, incluida la búsqueda
+		if leido, _ := repos.Estudiantes.GetByCedula("1234567"); leido.Nombre != "Lulú López" || leido.Version != 2 {
+			t.Errorf("GetByCedula = %+v, se esperaba Lulú López en la versión 2", leido)
+		}
+		pagina, _ := repos.Estudiantes.List(repository.Consulta{})
+		if len(pagina.Elementos) != 1 || pagina.Elementos[0].Version != 2 {
+			t.Errorf("List = %+v, se esperaba la versión 2", pagina.Elementos)
+		}
+		encontrados, _ := repos.Estudiantes.Search("lulu", 0)
+		if len(encontrados) != 1 || encontrados[0].Nombre != "Lulú López" || encontrados[0].Version != 2 {
+			t.Errorf("Search = %+v, se esperaba Lulú López en la versión 2", encontrados)
+		}
+
+		historial, _ := repos.Auditoria.HistorialEstudiante("1234567")
+		ultimo := historial[len(historial)-1]
+		if ultimo.Operacion != domain.OperacionActualizar || ultimo.Antes == "" || ultimo.Despues == "" {
+			t.Errorf("último registro de auditoría = %+v, se esperaba la actualización con valores antes y después", ultimo)
+		}
+	})
+
+	t.Run("UpdateMateria", func(t *testing.T) {
+		repos := nuevos(t)
+		if err := repos.Materias.Create(domain.NewMateria("1040", "Calculo")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		m, _ := repos.Materias.GetByCodigo("1040")
+		m.Nombre = "Cálculo Diferencial"
+		if err := repos.Materias.Update(m); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if leida, _ := repos.Materias.GetByCodigo("1040"); leida.Nombre != "Cálculo Diferencial" || leida.Version != 2 {
+			t.Errorf("GetByCodigo = %+v, se esperaba Cálculo Diferencial en la versión 2", leida)
+		}
+		if encontradas, _ := repos.Materias.Search("diferencial", 0); len(encontradas) != 1 {
+			t.Errorf("Search = %+v, se esperaba la materia renombrada", encontradas)
+		}
+		// El nombre anterior ya no está indexado
+		if encontradas, _ := repos.Materias.Search("calculo", 0); len(encontradas) != 1 {
+			t.Errorf("Search = %+v, se esperaba una sola coincidencia", encontradas)
+		}
+	})
+
+	t.Run("EdicionesConcurrentes", func(t *testing.T) {
+		repos := nuevos(t)
+		if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		// Dos coordinadores leen la misma versión; el segundo en guardar recibe el conflicto
+		primero, _ := repos.Estudiantes.GetByCedula("1234567")
+		segundo, _ := repos.Estudiantes.GetByCedula("1234567")
+		primero.Nombre = "Lucía López"
+		if err := repos.Estudiantes.Update(primero); err != nil {
+			t.Fatalf("Update primero: %v", err)
+		}
+		segundo.Nombre = "Lulú Lozano"
+		if err := repos.Estudiantes.Update(segundo); !errors.Is(err, repository.ErrConflicto) {
+			t.Fatalf("Update segundo = %v, se esperaba ErrConflicto", err)
+		}
+		if segundo.Version != 1 {
+			t.Errorf("un Update fallido cambió la versión a %d", segundo.Version)
+		}
+
+		if leido, _ := repos.Estudiantes.GetByCedula("1234567"); leido.Nombre != "Lucía López" || leido.Version != 2 {
+			t.Fatalf("GetByCedula = %+v, se esperaba conservar el cambio del primero", leido)
+		}
+		historial, _ := repos.Auditoria.HistorialEstudiante("1234567")
+		if len(historial) != 2 {
+			t.Errorf("HistorialEstudiante = %d registros, se esperaban 2 (alta y una actualización)", len(historial))
+		}
+
+		// Tras recargar, el segundo puede reintentar
+		recargado, _ := repos.Estudiantes.GetByCedula("1234567")
+		recargado.Nombre = "Lulú Lozano"
+		if err := repos.Estudiantes.Update(recargado); err != nil {
+			t.Fatalf("Update tras recargar: %v", err)
+		}
+	})
+
+	t.Run("UpdateInexistenteOEliminado", func(t *testing.T) {
+		repos := nuevos(t)
+		if err := repos.Materias.Update(&domain.Materia{Codigo: "9999", Nombre: "Nada", Version: 1}); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Update inexistente = %v, se esperaba ErrNoEncontrado", err)
+		}
+
+		m := domain.NewMateria("1040", "Cálculo")
+		if err := repos.Materias.Create(m); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := repos.Materias.Delete("1040"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		m.Nombre = "Cálculo I"
+		if err := repos.Materias.Update(m); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Update eliminada = %v, se esperaba ErrNoEncontrado", err)
+		}
+	})
+
+	t.Run("ConflictoDentroDeUnidadDeTrabajo", func(t *testing.T) {
+		repos := nuevos(t)
+		if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		obsoleto, _ := repos.Estudiantes.GetByCedula("1234567")
+
+		err := repos.EnTransaccion(func(tx *repository.Repositorios) error {
+			e, _ := tx.Estudiantes.GetByCedula("1234567")
+			e.Nombre = "Lucía López"
+			if err := tx.Estudiantes.Update(e); err != nil {
+				return err
+			}
+			obsoleto.Nombre = "Lulú Lozano"
+			return tx.Estudiantes.Update(obsoleto)
+		})
+		if !errors.Is(err, repository.ErrConflicto) {
+			t.Fatalf("EnTransaccion = %v, se esperaba ErrConflicto", err)
+		}
+		// El conflicto descarta toda la unidad de trabajo
+		if leido, _ := repos.Estudiantes.GetByCedula("1234567"); leido.Nombre != "Lulú López" || leido.Version != 1 {
+			t.Fatalf("GetByCedula = %+v, se esperaba el registro sin cambios", leido)
+		}
+	})
+}
@@ -0,0 +1,387 @@
+// Package repotest contiene la suite de contrato que toda implementación de los
+// repositorios de inscripciones debe cumplir.
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// Fabrica crea repositorios vacíos e independientes para cada prueba, en modo
+// administrativo para poder comprobar el aislamiento entre facultades
+type Fabrica func(t *testing.T) *repository.Repositorios
+
+// ProbarContrato ejecuta la suite completa contra los repositorios creados por la fábrica
+func ProbarContrato(t *testing.T, nuevos Fabrica) {
+	t.Run("Estudiantes", func(t *testing.T) { probarEstudiantes(t, nuevos) })
+	t.Run("Materias", func(t *testing.T) { probarMaterias(t, nuevos) })
+	t.Run("Inscripciones", func(t *testing.T) { probarInscripciones(t, nuevos) })
+	t.Run("Listados", func(t *testing.T) { probarListados(t, nuevos) })
+	t.Run("Busqueda", func(t *testing.T) { probarBusqueda(t, nuevos) })
+	t.Run("Auditoria", func(t *testing.T) { probarAuditoria(t, nuevos) })
+	t.Run("Eliminacion", func(t *testing.T) { probarEliminacion(t, nuevos) })
+	t.Run("Volcado", func(t *testing.T) { probarVolcado(t, nuevos) })
+	t.Run("UnidadDeTrabajo", func(t *testing.T) { probarUnidadDeTrabajo(t, nuevos) })
+	t.Run("Concurrencia", func(t *testing.T) { probarConcurrencia(t, nuevos) })
+	t.Run("Eventos", func(t *testing.T) { probarEventos(t, nuevos) })
+	t.Run("Facultades", func(t *testing.T) { probarFacultades(t, nuevos) })
+	t.Run("Periodos", func(t *testing.T) { probarPeriodos(t, nuevos) })
+	t.Run("Creditos", func(t *testing.T) { probarCreditos(t, nuevos) })
+	t.Run("Prerrequisitos", func(t *testing.T) { probarPrerrequisitos(t, nuevos) })
+	t.Run("Cupos", func(t *testing.T) { probarCupos(t, nuevos) })
+	t.Run("Grupos", func(t *testing.T) { probarGrupos(t, nuevos) })
+	t.Run("Horarios", func(t *testing.T) { probarHorarios(t, nuevos) })
+	t.Run("Calificaciones", func(t *testing.T) { probarCalificaciones(t, nuevos) })
+	t.Run("Carreras", func(t *testing.T) { probarCarreras(t, nuevos) })
+	t.Run("Docentes", func(t *testing.T) { probarDocentes(t, nuevos) })
+}
+
+func probarEstudiantes(t *testing.T, nuevos Fabrica) {
+	t.Run("CreateYGetByCedula", func(t *testing.T) {
+		repo := nuevos(t).Estudiantes
+		if err := repo.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		e, err := repo.GetByCedula("1234567")
+		if err != nil {
+			t.Fatalf("GetByCedula: %v", err)
+		}
+		if e == nil || e.Cedula != "1234567" || e.Nombre != "Lulú López" {
+			t.Fatalf("GetByCedula = %+v, se esperaba Lulú López", e)
+		}
+
+		existe, err := repo.Exists("1234567")
+		if err != nil || !existe {
+			t.Fatalf("Exists = %v, %v; se esperaba true", existe, err)
+		}
+	})
+
+	t.Run("Duplicado", func(t *testing.T) {
+		repo := nuevos(t).Estudiantes
+		if err := repo.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		err := repo.Create(domain.NewEstudiante("1234567", "Otro Nombre"))
+		if !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Create duplicado = %v, se esperaba ErrDuplicado", err)
+		}
+
+		e, _ := repo.GetByCedula("1234567")
+		if e == nil || e.Nombre != "Lulú López" {
+			t.Fatalf("el duplicado sobrescribió el registro original: %+v", e)
+		}
+	})
+
+	t.Run("NoEncontrado", func(t *testing.T) {
+		repo := nuevos(t).Estudiantes
+
+		e, err := repo.GetByCedula("0000000")
+		if err != nil || e != nil {
+			t.Fatalf("GetByCedula inexistente = %+v, %v; se esperaba nil, nil", e, err)
+		}
+
+		existe, err := repo.Exists("0000000")
+		if err != nil || existe {
+			t.Fatalf("Exists inexistente = %v, %v; se esperaba false", existe, err)
+		}
+
+		todos, err := repo.GetAll()
+		if err != nil || len(todos) != 0 {
+			t.Fatalf("GetAll vacío = %v, %v", todos, err)
+		}
+	})
+
+	t.Run("GetAllOrdenadoPorCedula", func(t *testing.T) {
+		repo := nuevos(t).Estudiantes
+		for _, cedula := range []string{"3333333", "1111111", "2222222"} {
+			if err := repo.Create(domain.NewEstudiante(cedula, "Estudiante "+cedula)); err != nil {
+				t.Fatalf("Create %s: %v", cedula, err)
+			}
+		}
+
+		todos, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		verificarOrden(t, cedulas(todos), []string{"1111111", "2222222", "3333333"})
+	})
+}
+
+func probarMaterias(t *testing.T, nuevos Fabrica) {
+	t.Run("CreateYGetByCodigo", func(t *testing.T) {
+		repo := nuevos(t).Materias
+		if err := repo.Create(domain.NewMateria("1050", "Física I")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		m, err := repo.GetByCodigo("1050")
+		if err != nil {
+			t.Fatalf("GetByCodigo: %v", err)
+		}
+		if m == nil || m.Codigo != "1050" || m.Nombre != "Física I" {
+			t.Fatalf("GetByCodigo = %+v, se esperaba Física I", m)
+		}
+
+		existe, err := repo.Exists("1050")
+		if err != nil || !existe {
+			t.Fatalf("Exists = %v, %v; se esperaba true", existe, err)
+		}
+	})
+
+	t.Run("Duplicado", func(t *testing.T) {
+		repo := nuevos(t).Materias
+		if err := repo.Create(domain.NewMateria("1050", "Física I")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		err := repo.Create(domain.NewMateria("1050", "Física II"))
+		if !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Create duplicado = %v, se esperaba ErrDuplicado", err)
+		}
+	})
+
+	t.Run("NoEncontrado", func(t *testing.T) {
+		repo := nuevos(t).Materias
+
+		m, err := repo.GetByCodigo("9999")
+		if err != nil || m != nil {
+			t.Fatalf("GetByCodigo inexistente = %+v, %v; se esperaba nil, nil", m, err)
+		}
+
+		existe, err := repo.Exists("9999")
+		if err != nil || existe {
+			t.Fatalf("Exists inexistente = %v, %v; se esperaba false", existe, err)
+		}
+	})
+
+	t.Run("GetAllOrdenadoPorCodigo", func(t *testing.T) {
+		repo := nuevos(t).Materias
+		for _, codigo := range []string{"1070", "1040", "1060"} {
+			if err := repo.Create(domain.NewMateria(codigo, "Materia "+codigo)); err != nil {
+				t.Fatalf("Create %s: %v", codigo, err)
+			}
+		}
+
+		todas, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		verificarOrden(t, codigos(todas), []string{"1040", "1060", "1070"})
+	})
+}
+
+func probarInscripciones(t *testing.T, nuevos Fabrica) {
+	// poblar crea tres estudiantes y tres materias sin inscripciones
+	poblar := func(t *testing.T) *repository.Repositorios {
+		t.Helper()
+		repos := nuevos(t)
+		for _, e := range []*domain.Estudiante{
+			domain.NewEstudiante("4567766", "Calvin Clein"),
+			domain.NewEstudiante("1234567", "Lulú López"),
+			domain.NewEstudiante("9876534", "Pepito Pérez"),
+		} {
+			if err := repos.Estudiantes.Create(e); err != nil {
+				t.Fatalf("Create estudiante %s: %v", e.Cedula, err)
+			}
+		}
+		for _, m := range []*domain.Materia{
+			domain.NewMateria("1060", "Administración"),
+			domain.NewMateria("1040", "Cálculo"),
+			domain.NewMateria("1050", "Física I"),
+		} {
+			if err := repos.Materias.Create(m); err != nil {
+				t.Fatalf("Create materia %s: %v", m.Codigo, err)
+			}
+		}
+		return repos
+	}
+
+	inscribir := func(t *testing.T, repo repository.InscripcionRepository, pares ...[2]string) {
+		t.Helper()
+		for _, p := range pares {
+			if err := repo.Create(p[0], p[1]); err != nil {
+				t.Fatalf("Create inscripción %s-%s: %v", p[0], p[1], err)
+			}
+		}
+	}
+
+	t.Run("CreateYExists", func(t *testing.T) {
+		repo := poblar(t).Inscripciones
+		inscribir(t, repo, [2]string{"1234567", "1040"})
+
+		existe, err := repo.Exists("1234567", "1040")
+		if err != nil || !existe {
+			t.Fatalf("Exists = %v, %v; se esperaba true", existe, err)
+		}
+
+		existe, err = repo.Exists("1234567", "1050")
+		if err != nil || existe {
+			t.Fatalf("Exists de otra materia = %v, %v; se esperaba false", existe, err)
+		}
+	})
+
+	t.Run("Duplicado", func(t *testing.T) {
+		repo := poblar(t).Inscripciones
+		inscribir(t, repo, [2]string{"1234567", "1040"})
+
+		err := repo.Create("1234567", "1040")
+		if !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Create duplicado = %v, se esperaba ErrDuplicado", err)
+		}
+
+		count, err := repo.CountByEstudiante("1234567")
+		if err != nil || count != 1 {
+			t.Fatalf("CountByEstudiante = %d, %v; se esperaba 1", count, err)
+		}
+	})
+
+	t.Run("ReferenciaInvalida", func(t *testing.T) {
+		repo := poblar(t).Inscripciones
+
+		if err := repo.Create("0000000", "1040"); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Create con estudiante inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if err := repo.Create("1234567", "9999"); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Create con materia inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+	})
+
+	t.Run("NoEncontrado", func(t *testing.T) {
+		repo := poblar(t).Inscripciones
+
+		materias, err := repo.GetByEstudiante("0000000")
+		if err != nil || len(materias) != 0 {
+			t.Fatalf("GetByEstudiante inexistente = %v, %v; se esperaba vacío", materias, err)
+		}
+
+		estudiantes, err := repo.GetByMateria("9999")
+		if err != nil || len(estudiantes) != 0 {
+			t.Fatalf("GetByMateria inexistente = %v, %v; se esperaba vacío", estudiantes, err)
+		}
+
+		count, err := repo.CountByEstudiante("0000000")
+		if err != nil || count != 0 {
+			t.Fatalf("CountByEstudiante inexistente = %d, %v; se esperaba 0", count, err)
+		}
+	})
+
+	t.Run("Ordenamiento", func(t *testing.T) {
+		repo := poblar(t).Inscripciones
+		inscribir(t, repo,
+			[2]string{"9876534", "1040"},
+			[2]string{"1234567", "1060"},
+			[2]string{"1234567", "1040"},
+			[2]string{"4567766", "1040"},
+			[2]string{"1234567", "1050"},
+		)
+
+		materias, err := repo.GetByEstudiante("1234567")
+		if err != nil {
+			t.Fatalf("GetByEstudiante: %v", err)
+		}
+		verificarOrden(t, codigos(materias), []string{"1040", "1050", "1060"})
+		if materias[0].Nombre != "Cálculo" {
+			t.Errorf("GetByEstudiante no incluye el nombre de la materia: %+v", materias[0])
+		}
+
+		estudiantes, err := repo.GetByMateria("1040")
+		if err != nil {
+			t.Fatalf("GetByMateria: %v", err)
+		}
+		verificarOrden(t, cedulas(estudiantes), []string{"1234567", "4567766", "9876534"})
+		if estudiantes[0].Nombre != "Lulú López" {
+			t.Errorf("GetByMateria no incluye el nombre del estudiante: %+v", estudiantes[0])
+		}
+
+		count, err := repo.CountByEstudiante("1234567")
+		if err != nil || count != 3 {
+			t.Fatalf("CountByEstudiante = %d, %v; se esperaba 3", count, err)
+		}
+	})
+
+	t.Run("ListadoYConteosAgrupados", func(t *testing.T) {
+		repo := poblar(t).Inscripciones
+
+		vacio, err := repo.GetAll()
+		if err != nil || len(vacio) != 0 {
+			t.Fatalf("GetAll sin inscripciones = %v, %v; se esperaba vacío", vacio, err)
+		}
+
+		inscribir(t, repo,
+			[2]string{"9876534", "1040"},
+			[2]string{"1234567", "1060"},
+			[2]string{"1234567", "1040"},
+			[2]string{"4567766", "1050"},
+		)
+
+		todas, err := repo.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		var pares []string
+		for _, i := range todas {
+			pares = append(pares, i.Estudiante.Cedula+"-"+i.Materia.Codigo)
+		}
+		verificarOrden(t, pares, []string{"1234567-1040", "1234567-1060", "4567766-1050", "9876534-1040"})
+		if todas[0].Estudiante.Nombre != "Lulú López" || todas[0].Materia.Nombre != "Cálculo" {
+			t.Errorf("GetAll no incluye los nombres: %+v %+v", todas[0].Estudiante, todas[0].Materia)
+		}
+
+		porEstudiante, err := repo.CountGroupedByEstudiante()
+		if err != nil {
+			t.Fatalf("CountGroupedByEstudiante: %v", err)
+		}
+		verificarConteos(t, porEstudiante, map[string]int{"1234567": 2, "4567766": 1, "9876534": 1})
+
+		porMateria, err := repo.CountGroupedByMateria()
+		if err != nil {
+			t.Fatalf("CountGroupedByMateria: %v", err)
+		}
+		verificarConteos(t, porMateria, map[string]int{"1040": 2, "1050": 1, "1060": 1})
+	})
+}
+
+func cedulas(estudiantes []*domain.Estudiante) []string {
+	var resultado []string
+	for _, e := range estudiantes {
+		resultado = append(resultado, e.Cedula)
+	}
+	return resultado
+}
+
+func codigos(materias []*domain.Materia) []string {
+	var resultado []string
+	for _, m := range materias {
+		resultado = append(resultado, m.Codigo)
+	}
+	return resultado
+}
+
+func verificarOrden(t *testing.T, obtenido, esperado []string) {
+	t.Helper()
+	if len(obtenido) != len(esperado) {
+		t.Fatalf("se obtuvieron %v, se esperaba %v", obtenido, esperado)
+	}
+	for i := range esperado {
+		if obtenido[i] != esperado[i] {
+			t.Fatalf("orden incorrecto: %v, se esperaba %v", obtenido, esperado)
+		}
+	}
+}
+
+func verificarConteos(t *testing.T, obtenido, esperado map[string]int) {
+	t.Helper()
+	if len(obtenido) != len(esperado) {
+		t.Fatalf("conteos = %v, se esperaba %v", obtenido, esperado)
+	}
+	for clave, n := range esperado {
+		if obtenido[clave] != n {
+			t.Fatalf("conteos = %v, se esperaba %v", obtenido, esperado)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package repotest
+
+import (
+	"bytes"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// materiaConCreditos crea la materia con los créditos indicados
+func materiaConCreditos(t *testing.T, repos *repository.Repositorios, codigo, nombre string, creditos int) {
+	t.Helper()
+	m := domain.NewMateria(codigo, nombre)
+	m.Creditos = creditos
+	if err := repos.Materias.Create(m); err != nil {
+		t.Fatalf("Create materia %s: %v", codigo, err)
+	}
+}
+
+func probarCreditos(t *testing.T, nuevos Fabrica) {
+	t.Run("SeGuardanConLaMateria", func(t *testing.T) {
+		repos := nuevos(t)
+		materiaConCreditos(t, repos, "1040", "Cálculo", 4)
+		if err := repos.Materias.Create(domain.NewMateria("1050", "Física I")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		if m, _ := repos.Materias.GetByCodigo("1040"); m == nil || m.Creditos != 4 {
+			t.Fatalf("GetByCodigo = %+v, se esperaban 4 créditos", m)
+		}
+		todas, _ := repos.Materias.GetAll()
+		if len(todas) != 2 || todas[0].Creditos != 4 || todas[1].Creditos != domain.CreditosPorDefecto {
+			t.Fatalf("GetAll = %+v, %+v; se esperaban 4 y %d créditos", todas[0], todas[1], domain.CreditosPorDefecto)
+		}
+		if pagina, _ := repos.Materias.List(repository.Consulta{}); len(pagina.Elementos) != 2 || pagina.Elementos[0].Creditos != 4 {
+			t.Fatalf("List = %+v, se esperaban los créditos de cada materia", pagina.Elementos)
+		}
+
+		m, _ := repos.Materias.GetByCodigo("1050")
+		m.Creditos = 6
+		if err := repos.Materias.Update(m); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if m, _ := repos.Materias.GetByCodigo("1050"); m == nil || m.Creditos != 6 || m.Version != 2 {
+			t.Fatalf("GetByCodigo tras Update = %+v, se esperaban 6 créditos en la versión 2", m)
+		}
+	})
+
+	t.Run("CargaPorEstudiante", func(t *testing.T) {
+		repos := nuevos(t)
+		for _, cedula := range []string{"1234567", "7654321"} {
+			if err := repos.Estudiantes.Create(domain.NewEstudiante(cedula, "Estudiante "+cedula)); err != nil {
+				t.Fatalf("Create estudiante: %v", err)
+			}
+		}
+		materiaConCreditos(t, repos, "1040", "Cálculo", 4)
+		materiaConCreditos(t, repos, "1050", "Física I", 5)
+		materiaConCreditos(t, repos, "1060", "Administración", 2)
+		for _, i := range [][2]string{{"1234567", "1040"}, {"1234567", "1050"}, {"1234567", "1060"}, {"7654321", "1060"}} {
+			if err := repos.Inscripciones.Create(i[0], i[1]); err != nil {
+				t.Fatalf("Create inscripción %v: %v", i, err)
+			}
+		}
+		// Las inscripciones canceladas y las de otros periodos no suman
+		if err := repos.Inscripciones.Delete("1234567", "1060"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		anterior := otroPeriodo(t, repos, "2020-2")
+		if err := anterior.Inscripciones.Create("7654321", "1050"); err != nil {
+			t.Fatalf("Create en 2020-2: %v", err)
+		}
+
+		creditos, err := repos.Inscripciones.CreditosGroupedByEstudiante()
+		if err != nil {
+			t.Fatalf("CreditosGroupedByEstudiante: %v", err)
+		}
+		verificarConteos(t, creditos, map[string]int{"1234567": 9, "7654321": 2})
+
+		materias, _ := repos.Inscripciones.GetByEstudiante("1234567")
+		if len(materias) != 2 || materias[0].Creditos != 4 || materias[1].Creditos != 5 {
+			t.Fatalf("GetByEstudiante = %+v, se esperaban los créditos de cada materia", materias)
+		}
+		inscripciones, _ := repos.Inscripciones.GetAll()
+		if len(inscripciones) != 3 || inscripciones[0].Materia.Creditos != 4 {
+			t.Fatalf("GetAll = %+v, se esperaban los créditos de cada materia", inscripciones)
+		}
+	})
+
+	t.Run("Volcado", func(t *testing.T) {
+		origen := nuevos(t)
+		materiaConCreditos(t, origen, "1040", "Cálculo", 7)
+
+		var script bytes.Buffer
+		if err := origen.Volcar(&script); err != nil {
+			t.Fatalf("Volcar: %v", err)
+		}
+		destino := nuevos(t)
+		if err := destino.Cargar(&script); err != nil {
+			t.Fatalf("Cargar: %v", err)
+		}
+		if m, _ := destino.Materias.GetByCodigo("1040"); m == nil || m.Creditos != 7 {
+			t.Fatalf("GetByCodigo tras cargar = %+v, se esperaban 7 créditos", m)
+		}
+	})
+}
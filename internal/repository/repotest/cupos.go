@@ -0,0 +1,267 @@
+package repotest
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// materiaConCupo crea la materia con el cupo indicado
+func materiaConCupo(t *testing.T, repos *repository.Repositorios, codigo, nombre string, cupo int) {
+	t.Helper()
+	m := domain.NewMateria(codigo, nombre)
+	m.Cupo = cupo
+	if err := repos.Materias.Create(m); err != nil {
+		t.Fatalf("Create materia %s: %v", codigo, err)
+	}
+}
+
+// estudiantesCupos crea a Lulú, Pepito y Ana
+func estudiantesCupos(t *testing.T, repos *repository.Repositorios) {
+	t.Helper()
+	for _, e := range [][2]string{{"1234567", "Lulú López"}, {"9876534", "Pepito Pérez"}, {"5555555", "Ana Ávila"}} {
+		if err := repos.Estudiantes.Create(domain.NewEstudiante(e[0], e[1])); err != nil {
+			t.Fatalf("Create estudiante %s: %v", e[0], err)
+		}
+	}
+}
+
+// cedulasEnEspera retorna las cédulas de la lista con sus posiciones como "cedula:posicion"
+func cedulasEnEspera(lista []*domain.Espera) []string {
+	resultado := make([]string, 0, len(lista))
+	for _, espera := range lista {
+		resultado = append(resultado, espera.Estudiante.Cedula+":"+strconv.Itoa(espera.Posicion))
+	}
+	return resultado
+}
+
+func probarCupos(t *testing.T, nuevos Fabrica) {
+	t.Run("SeGuardaConLaMateria", func(t *testing.T) {
+		repos := nuevos(t)
+		materiaConCupo(t, repos, "1040", "Cálculo", 30)
+		if m, _ := repos.Materias.GetByCodigo("1040"); m == nil || m.Cupo != 30 {
+			t.Fatalf("GetByCodigo = %+v, se esperaba cupo 30", m)
+		}
+
+		m, _ := repos.Materias.GetByCodigo("1040")
+		m.Cupo = domain.SinCupoLimite
+		if err := repos.Materias.Update(m); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if todas, _ := repos.Materias.GetAll(); len(todas) != 1 || todas[0].Cupo != domain.SinCupoLimite {
+			t.Fatalf("GetAll = %+v, se esperaba la materia sin límite de cupo", todas)
+		}
+	})
+
+	t.Run("CreateRespetaElCupo", func(t *testing.T) {
+		repos := nuevos(t)
+		estudiantesCupos(t, repos)
+		materiaConCupo(t, repos, "1040", "Cálculo", 2)
+
+		for _, cedula := range []string{"1234567", "9876534"} {
+			if err := repos.Inscripciones.Create(cedula, "1040"); err != nil {
+				t.Fatalf("Create %s: %v", cedula, err)
+			}
+		}
+		if err := repos.Inscripciones.Create("5555555", "1040"); !errors.Is(err, repository.ErrCupoAgotado) {
+			t.Fatalf("Create sobre el cupo = %v, se esperaba ErrCupoAgotado", err)
+		}
+		if existe, _ := repos.Inscripciones.Exists("5555555", "1040"); existe {
+			t.Fatal("la inscripción sobre el cupo quedó guardada")
+		}
+		// Repetir una inscripción sigue siendo un duplicado aunque no haya cupo
+		if err := repos.Inscripciones.Create("1234567", "1040"); !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Create repetido = %v, se esperaba ErrDuplicado", err)
+		}
+
+		// El cupo es por periodo
+		anterior := otroPeriodo(t, repos, "2020-2")
+		if err := anterior.Inscripciones.Create("5555555", "1040"); err != nil {
+			t.Fatalf("Create en 2020-2: %v", err)
+		}
+
+		// Cancelar libera el cupo, y restaurar lo vuelve a ocupar
+		if err := repos.Inscripciones.Delete("9876534", "1040"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Inscripciones.Create("5555555", "1040"); err != nil {
+			t.Fatalf("Create con el cupo liberado: %v", err)
+		}
+		if err := repos.Inscripciones.Restore("9876534", "1040"); !errors.Is(err, repository.ErrCupoAgotado) {
+			t.Fatalf("Restore sin cupo = %v, se esperaba ErrCupoAgotado", err)
+		}
+		if existe, _ := repos.Inscripciones.Exists("9876534", "1040"); existe {
+			t.Fatal("la inscripción restaurada sin cupo quedó vigente")
+		}
+	})
+
+	t.Run("ListaEspera", func(t *testing.T) {
+		repos := nuevos(t)
+		estudiantesCupos(t, repos)
+		materiaConCupo(t, repos, "1040", "Cálculo", 1)
+		materiaConCupo(t, repos, "1050", "Física I", 1)
+
+		if err := repos.Grupos.Create(domain.Grupo{Materia: "1040", Numero: 2}); err != nil {
+			t.Fatalf("Create grupo: %v", err)
+		}
+		// Cada uno espera el grupo que pidió
+		for i, e := range []struct {
+			cedula string
+			grupo  int
+		}{{"9876534", domain.SinGrupo}, {"1234567", 2}, {"5555555", domain.SinGrupo}} {
+			posicion, err := repos.ListaEspera.Agregar(e.cedula, "1040", e.grupo)
+			if err != nil || posicion != i+1 {
+				t.Fatalf("Agregar(%s) = %d, %v; se esperaba la posición %d", e.cedula, posicion, err, i+1)
+			}
+		}
+		if _, err := repos.ListaEspera.Agregar("1234567", "1040", domain.SinGrupo); !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Agregar repetido = %v, se esperaba ErrDuplicado", err)
+		}
+		if _, err := repos.ListaEspera.Agregar("0000000", "1040", domain.SinGrupo); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Agregar estudiante inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if _, err := repos.ListaEspera.Agregar("1234567", "1050", 3); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Agregar en un grupo inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if _, err := repos.ListaEspera.Agregar("1234567", "1050", domain.SinGrupo); err != nil {
+			t.Fatalf("Agregar en otra materia: %v", err)
+		}
+
+		lista, err := repos.ListaEspera.GetByMateria("1040")
+		if err != nil {
+			t.Fatalf("GetByMateria: %v", err)
+		}
+		verificarOrden(t, cedulasEnEspera(lista), []string{"9876534:1", "1234567:2", "5555555:3"})
+		if lista[0].Estudiante.Nombre != "Pepito Pérez" || lista[0].Periodo != repos.Periodo() {
+			t.Fatalf("GetByMateria[0] = %+v, se esperaba a Pepito en el periodo actual", lista[0])
+		}
+		if lista[0].Grupo != domain.SinGrupo || lista[1].Grupo != 2 {
+			t.Fatalf("grupos en espera = %d y %d, se esperaban sin grupo y 2", lista[0].Grupo, lista[1].Grupo)
+		}
+
+		// El grupo que alguien espera no se puede eliminar
+		if err := repos.Grupos.Delete(domain.Grupo{Materia: "1040", Numero: 2}); !errors.Is(err, repository.ErrGrupoConInscritos) {
+			t.Fatalf("Delete de un grupo esperado = %v, se esperaba ErrGrupoConInscritos", err)
+		}
+
+		// Salir de la lista adelanta a los de atrás, sin dejar huecos
+		if err := repos.ListaEspera.Quitar("9876534", "1040"); err != nil {
+			t.Fatalf("Quitar: %v", err)
+		}
+		if err := repos.ListaEspera.Quitar("9876534", "1040"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Quitar repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if posicion, _ := repos.ListaEspera.Posicion("5555555", "1040"); posicion != 2 {
+			t.Fatalf("Posicion = %d, se esperaba 2", posicion)
+		}
+		if posicion, _ := repos.ListaEspera.Posicion("9876534", "1040"); posicion != 0 {
+			t.Fatalf("Posicion fuera de la lista = %d, se esperaba 0", posicion)
+		}
+
+		// Volver a la lista es ir al final
+		if posicion, _ := repos.ListaEspera.Agregar("9876534", "1040", domain.SinGrupo); posicion != 3 {
+			t.Fatalf("Agregar de nuevo = %d, se esperaba el final de la lista", posicion)
+		}
+
+		// Un estudiante eliminado deja de contar, pero conserva su lugar
+		if err := repos.Estudiantes.Delete("1234567"); err != nil {
+			t.Fatalf("Delete estudiante: %v", err)
+		}
+		verificarConteos(t, contarEnEspera(t, repos), map[string]int{"1040": 2})
+		if todas, _ := repos.ListaEspera.GetAll(); len(todas) != 4 {
+			t.Fatalf("GetAll = %d esperas, se esperaban también las del estudiante eliminado", len(todas))
+		}
+		if err := repos.Estudiantes.Restore("1234567"); err != nil {
+			t.Fatalf("Restore estudiante: %v", err)
+		}
+		if posicion, _ := repos.ListaEspera.Posicion("1234567", "1040"); posicion != 1 {
+			t.Fatalf("Posicion tras restaurar = %d, se esperaba 1", posicion)
+		}
+
+		// Cada periodo tiene sus propias listas
+		if lista, _ := otroPeriodo(t, repos, "2020-2").ListaEspera.GetByMateria("1040"); len(lista) != 0 {
+			t.Fatalf("GetByMateria en 2020-2 = %v, se esperaba vacía", cedulasEnEspera(lista))
+		}
+	})
+
+	t.Run("UnidadDeTrabajo", func(t *testing.T) {
+		repos := nuevos(t)
+		estudiantesCupos(t, repos)
+		materiaConCupo(t, repos, "1040", "Cálculo", 1)
+
+		errForzado := errors.New("forzado")
+		err := repos.EnTransaccion(func(tx *repository.Repositorios) error {
+			if _, err := tx.ListaEspera.Agregar("1234567", "1040", domain.SinGrupo); err != nil {
+				return err
+			}
+			return errForzado
+		})
+		if !errors.Is(err, errForzado) {
+			t.Fatalf("EnTransaccion = %v, se esperaba el error forzado", err)
+		}
+		if posicion, _ := repos.ListaEspera.Posicion("1234567", "1040"); posicion != 0 {
+			t.Fatal("la espera de una unidad de trabajo fallida quedó guardada")
+		}
+	})
+
+	t.Run("Volcado", func(t *testing.T) {
+		origen := nuevos(t)
+		estudiantesCupos(t, origen)
+		materiaConCupo(t, origen, "1040", "Cálculo", 1)
+		if err := origen.Grupos.Create(domain.Grupo{Materia: "1040", Numero: 2}); err != nil {
+			t.Fatalf("Create grupo: %v", err)
+		}
+		for i, cedula := range []string{"5555555", "1234567"} {
+			if _, err := origen.ListaEspera.Agregar(cedula, "1040", 2*i); err != nil {
+				t.Fatalf("Agregar: %v", err)
+			}
+		}
+		if _, err := otroPeriodo(t, origen, "2020-2").ListaEspera.Agregar("9876534", "1040", domain.SinGrupo); err != nil {
+			t.Fatalf("Agregar en 2020-2: %v", err)
+		}
+
+		var script bytes.Buffer
+		if err := origen.Volcar(&script); err != nil {
+			t.Fatalf("Volcar: %v", err)
+		}
+		destino := nuevos(t)
+		if err := destino.Cargar(strings.NewReader(script.String())); err != nil {
+			t.Fatalf("Cargar: %v", err)
+		}
+		if m, _ := destino.Materias.GetByCodigo("1040"); m == nil || m.Cupo != 1 {
+			t.Fatalf("GetByCodigo tras cargar = %+v, se esperaba cupo 1", m)
+		}
+		lista, _ := destino.ListaEspera.GetByMateria("1040")
+		verificarOrden(t, cedulasEnEspera(lista), []string{"5555555:1", "1234567:2"})
+		if lista[0].Grupo != domain.SinGrupo || lista[1].Grupo != 2 {
+			t.Fatalf("grupos en espera tras cargar = %d y %d, se esperaban sin grupo y 2", lista[0].Grupo, lista[1].Grupo)
+		}
+		anterior, _ := otroPeriodo(t, destino, "2020-2").ListaEspera.GetByMateria("1040")
+		verificarOrden(t, cedulasEnEspera(anterior), []string{"9876534:1"})
+
+		// Los volcados anteriores a los cupos cargan las materias sin límite
+		antiguo := "INSERT INTO materias (codigo, nombre, creditos, deleted_at) VALUES ('1040', 'Cálculo', '4', NULL);\n"
+		sinCupo := nuevos(t)
+		if err := sinCupo.Cargar(strings.NewReader(antiguo)); err != nil {
+			t.Fatalf("Cargar volcado antiguo: %v", err)
+		}
+		if m, _ := sinCupo.Materias.GetByCodigo("1040"); m == nil || m.Cupo != domain.SinCupoLimite {
+			t.Fatalf("GetByCodigo = %+v, se esperaba la materia sin límite de cupo", m)
+		}
+	})
+}
+
+// contarEnEspera cuenta cuántos esperan en cada materia del periodo
+func contarEnEspera(t *testing.T, repos *repository.Repositorios) map[string]int {
+	t.Helper()
+	conteos, err := repos.ListaEspera.CountGroupedByMateria()
+	if err != nil {
+		t.Fatalf("CountGroupedByMateria: %v", err)
+	}
+	return conteos
+}
@@ -0,0 +1,238 @@
+package repotest
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// docentesCalculo crea Cálculo con los grupos 1 y 2, Física, a Lulú, Pepito y Ana, y a
+// los docentes Ramiro y Sofía
+func docentesCalculo(t *testing.T, repos *repository.Repositorios) {
+	t.Helper()
+	gruposCalculo(t, repos)
+	materiaConCupo(t, repos, "1050", "Física I", domain.SinCupoLimite)
+	for _, d := range [][2]string{{"8765432", "Sofía Suárez"}, {"7654321", "Ramiro Ruiz"}} {
+		if err := repos.Docentes.Create(domain.NewDocente(d[0], d[1])); err != nil {
+			t.Fatalf("Create docente %s: %v", d[0], err)
+		}
+	}
+}
+
+func probarDocentes(t *testing.T, nuevos Fabrica) {
+	t.Run("DocentesYAsignaciones", func(t *testing.T) {
+		repos := nuevos(t)
+		docentesCalculo(t, repos)
+
+		if docente, err := repos.Docentes.GetByCedula("7654321"); err != nil || docente == nil || docente.Nombre != "Ramiro Ruiz" {
+			t.Fatalf("GetByCedula = %+v, %v; se esperaba a Ramiro", docente, err)
+		}
+		if docente, _ := repos.Docentes.GetByCedula("0000000"); docente != nil {
+			t.Fatalf("GetByCedula de un docente inexistente = %+v, se esperaba nil", docente)
+		}
+		if err := repos.Docentes.Create(domain.NewDocente("7654321", "Otro")); !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Create repetido = %v, se esperaba ErrDuplicado", err)
+		}
+		if err := repos.Docentes.Update(domain.NewDocente("8765432", "Sofía Suárez Sierra")); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if err := repos.Docentes.Update(domain.NewDocente("0000000", "Nadie")); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Update de un docente inexistente = %v, se esperaba ErrNoEncontrado", err)
+		}
+		docentes, err := repos.Docentes.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(docentes) != 2 || docentes[0].Cedula != "7654321" || docentes[1].Nombre != "Sofía Suárez Sierra" {
+			t.Fatalf("GetAll = %+v, se esperaban Ramiro y Sofía actualizada", docentes)
+		}
+
+		for _, a := range []struct {
+			docente, materia string
+			grupo            int
+		}{{"7654321", "1040", 1}, {"8765432", "1040", 2}, {"7654321", "1050", domain.SinGrupo}} {
+			if err := repos.Docentes.Asignar(a.docente, a.materia, a.grupo); err != nil {
+				t.Fatalf("Asignar %s a %s-%d: %v", a.docente, a.materia, a.grupo, err)
+			}
+		}
+		for nombre, a := range map[string]struct {
+			docente, materia string
+			grupo            int
+		}{
+			"un docente inexistente":  {"0000000", "1040", 1},
+			"una materia inexistente": {"7654321", "9999", domain.SinGrupo},
+			"un grupo inexistente":    {"7654321", "1040", 3},
+		} {
+			if err := repos.Docentes.Asignar(a.docente, a.materia, a.grupo); !errors.Is(err, repository.ErrReferenciaInvalida) {
+				t.Fatalf("Asignar con %s = %v, se esperaba ErrReferenciaInvalida", nombre, err)
+			}
+		}
+
+		// Asignar un grupo que ya tenía docente lo cambia de docente
+		if err := repos.Docentes.Asignar("7654321", "1040", 2); err != nil {
+			t.Fatalf("Asignar a un grupo con docente: %v", err)
+		}
+		periodo := repos.Periodo()
+		esperadas := []domain.AsignacionDocente{
+			{Docente: "7654321", Materia: "1040", Periodo: periodo, Grupo: 1},
+			{Docente: "7654321", Materia: "1040", Periodo: periodo, Grupo: 2},
+			{Docente: "7654321", Materia: "1050", Periodo: periodo, Grupo: domain.SinGrupo},
+		}
+		if obtenidas, _ := repos.Docentes.GetByDocente("7654321"); !reflect.DeepEqual(obtenidas, esperadas) {
+			t.Fatalf("GetByDocente = %+v, se esperaba %+v", obtenidas, esperadas)
+		}
+		if obtenidas, _ := repos.Docentes.GetByDocente("8765432"); len(obtenidas) != 0 {
+			t.Fatalf("GetByDocente de Sofía = %+v, se esperaba vacío", obtenidas)
+		}
+		// Las asignaciones son del periodo de los repositorios
+		if obtenidas, _ := otroPeriodo(t, repos, "2020-2").Docentes.GetAsignaciones(); len(obtenidas) != 0 {
+			t.Fatalf("GetAsignaciones en otro periodo = %+v, se esperaba vacío", obtenidas)
+		}
+
+		if err := repos.Docentes.Delete("7654321"); !errors.Is(err, repository.ErrDocenteConAsignaciones) {
+			t.Fatalf("Delete con asignaciones = %v, se esperaba ErrDocenteConAsignaciones", err)
+		}
+		if err := repos.Docentes.Delete("8765432"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Docentes.Delete("8765432"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Delete repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+
+		if err := repos.Docentes.Desasignar("1040", 1); err != nil {
+			t.Fatalf("Desasignar: %v", err)
+		}
+		if err := repos.Docentes.Desasignar("1040", 1); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Desasignar repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+		// Eliminar el grupo se lleva su docente
+		if err := repos.Grupos.Delete(domain.Grupo{Materia: "1040", Numero: 2}); err != nil {
+			t.Fatalf("Delete grupo: %v", err)
+		}
+		// Una materia eliminada deja de contar entre las del docente, pero la asignación se conserva
+		if err := repos.Materias.Delete("1050"); err != nil {
+			t.Fatalf("Delete materia: %v", err)
+		}
+		if obtenidas, _ := repos.Docentes.GetByDocente("7654321"); len(obtenidas) != 0 {
+			t.Fatalf("GetByDocente con la materia eliminada = %+v, se esperaba vacío", obtenidas)
+		}
+		if obtenidas, _ := repos.Docentes.GetAsignaciones(); !reflect.DeepEqual(obtenidas, esperadas[2:]) {
+			t.Fatalf("GetAsignaciones = %+v, se esperaba solo Física", obtenidas)
+		}
+	})
+
+	t.Run("Estudiantes", func(t *testing.T) {
+		repos := nuevos(t)
+		docentesCalculo(t, repos)
+		for _, i := range []struct {
+			cedula, codigo string
+			grupo          int
+		}{{"1234567", "1040", 1}, {"9876534", "1040", 2}, {"5555555", "1040", 1}, {"5555555", "1050", domain.SinGrupo}} {
+			if err := repos.Inscripciones.CreateEnGrupo(i.cedula, i.codigo, i.grupo); err != nil {
+				t.Fatalf("CreateEnGrupo %s-%s: %v", i.cedula, i.codigo, err)
+			}
+		}
+		if err := repos.Docentes.Asignar("7654321", "1040", 1); err != nil {
+			t.Fatalf("Asignar: %v", err)
+		}
+		if err := repos.Docentes.Asignar("7654321", "1050", domain.SinGrupo); err != nil {
+			t.Fatalf("Asignar: %v", err)
+		}
+		if err := repos.Docentes.Asignar("8765432", "1040", domain.SinGrupo); err != nil {
+			t.Fatalf("Asignar: %v", err)
+		}
+
+		// Ramiro tiene al grupo 1 de Cálculo y a Física, así que Ana aparece una sola vez
+		estudiantes, err := repos.Docentes.GetEstudiantes("7654321")
+		if err != nil {
+			t.Fatalf("GetEstudiantes: %v", err)
+		}
+		verificarOrden(t, cedulas(estudiantes), []string{"1234567", "5555555"})
+		// Sofía dicta Cálculo completa y tiene a los inscritos de todos los grupos
+		estudiantes, _ = repos.Docentes.GetEstudiantes("8765432")
+		verificarOrden(t, cedulas(estudiantes), []string{"1234567", "5555555", "9876534"})
+
+		if err := repos.Inscripciones.Delete("1234567", "1040"); err != nil {
+			t.Fatalf("Delete inscripción: %v", err)
+		}
+		estudiantes, _ = repos.Docentes.GetEstudiantes("7654321")
+		verificarOrden(t, cedulas(estudiantes), []string{"5555555"})
+		if estudiantes, _ := otroPeriodo(t, repos, "2020-2").Docentes.GetEstudiantes("8765432"); len(estudiantes) != 0 {
+			t.Fatalf("GetEstudiantes en otro periodo = %+v, se esperaba vacío", estudiantes)
+		}
+	})
+
+	t.Run("Volcado", func(t *testing.T) {
+		origen := nuevos(t)
+		docentesCalculo(t, origen)
+		if err := origen.Docentes.Asignar("7654321", "1040", 2); err != nil {
+			t.Fatalf("Asignar: %v", err)
+		}
+		anterior := otroPeriodo(t, origen, "2025-2")
+		if err := anterior.Docentes.Asignar("8765432", "1050", domain.SinGrupo); err != nil {
+			t.Fatalf("Asignar en 2025-2: %v", err)
+		}
+
+		var script bytes.Buffer
+		if err := origen.Volcar(&script); err != nil {
+			t.Fatalf("Volcar: %v", err)
+		}
+		destino := nuevos(t)
+		if err := destino.Cargar(strings.NewReader(script.String())); err != nil {
+			t.Fatalf("Cargar: %v", err)
+		}
+		if docentes, _ := destino.Docentes.GetAll(); len(docentes) != 2 || docentes[1].Nombre != "Sofía Suárez" {
+			t.Fatalf("GetAll tras cargar = %+v, se esperaban Ramiro y Sofía", docentes)
+		}
+		esperada := []domain.AsignacionDocente{{Docente: "7654321", Materia: "1040", Periodo: destino.Periodo(), Grupo: 2}}
+		if obtenidas, _ := destino.Docentes.GetAsignaciones(); !reflect.DeepEqual(obtenidas, esperada) {
+			t.Fatalf("GetAsignaciones tras cargar = %+v, se esperaba %+v", obtenidas, esperada)
+		}
+		if obtenidas, _ := otroPeriodo(t, destino, "2025-2").Docentes.GetByDocente("8765432"); len(obtenidas) != 1 || obtenidas[0].Materia != "1050" {
+			t.Fatalf("GetByDocente en 2025-2 tras cargar = %+v, se esperaba Física", obtenidas)
+		}
+
+		// Un grupo que no existe en el volcado o un docente inexistente se rechazan
+		vacio := nuevos(t)
+		materia := "INSERT INTO materias (codigo, nombre, creditos, cupo, deleted_at) VALUES ('1040', 'Cálculo', '4', '0', NULL);\n"
+		docente := "INSERT INTO docentes (cedula, nombre) VALUES ('7654321', 'Ramiro Ruiz');\n"
+		script.Reset()
+		script.WriteString(materia + docente +
+			"INSERT INTO asignaciones_docentes (docente_cedula, materia_codigo, grupo) VALUES ('7654321', '1040', '3');\n")
+		if err := vacio.Cargar(&script); !errors.Is(err, repository.ErrVolcadoInvalido) {
+			t.Fatalf("Cargar con un grupo inexistente = %v, se esperaba ErrVolcadoInvalido", err)
+		}
+		script.Reset()
+		script.WriteString(materia +
+			"INSERT INTO asignaciones_docentes (docente_cedula, materia_codigo) VALUES ('7654321', '1040');\n")
+		if err := vacio.Cargar(&script); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Cargar con un docente inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if existe, _ := vacio.Materias.Exists("1040"); existe {
+			t.Fatal("el volcado rechazado dejó datos a medias")
+		}
+	})
+
+	t.Run("BaseSoloConDocentes", func(t *testing.T) {
+		repos := nuevos(t)
+		if err := repos.Docentes.Create(domain.NewDocente("7654321", "Ramiro Ruiz")); err != nil {
+			t.Fatalf("Create docente: %v", err)
+		}
+		for nombre, script := range map[string]string{
+			"el mismo docente": "INSERT INTO docentes (cedula, nombre) VALUES ('7654321', 'Ramiro Ruiz');\n",
+			"otros datos":      "INSERT INTO materias (codigo, nombre, creditos, cupo, deleted_at) VALUES ('1040', 'Cálculo', '4', '0', NULL);\n",
+		} {
+			if err := repos.Cargar(strings.NewReader(script)); !errors.Is(err, repository.ErrBaseNoVacia) {
+				t.Fatalf("Cargar %s sobre una base con docentes = %v, se esperaba ErrBaseNoVacia", nombre, err)
+			}
+		}
+		if existe, _ := repos.Materias.Exists("1040"); existe {
+			t.Fatal("el volcado rechazado dejó datos a medias")
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func probarEliminacion(t *testing.T, nuevos Fabrica) {
+	cedula := func(e *domain.Estudiante) string { return e.Cedula }
+	codigo := func(m *domain.Materia) string { return m.Codigo }
+	inscripcion := func(i *domain.Inscripcion) string { return i.Estudiante.Cedula + "/" + i.Materia.Codigo }
+
+	t.Run("EstudianteOcultoYRestaurado", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+		if err := repos.Estudiantes.Delete("1111111"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		if e, err := repos.Estudiantes.GetByCedula("1111111"); err != nil || e != nil {
+			t.Fatalf("GetByCedula eliminado = %+v, %v; se esperaba nil, nil", e, err)
+		}
+		if existe, _ := repos.Estudiantes.Exists("1111111"); existe {
+			t.Fatal("Exists retornó true para un estudiante eliminado")
+		}
+		todos, err := repos.Estudiantes.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		verificarOrden(t, cedulas(todos), []string{"2222222", "3333333", "4444444", "5555555"})
+		if encontrados, _ := repos.Estudiantes.Search("ana", 0); len(encontrados) != 1 || encontrados[0].Cedula != "3333333" {
+			t.Fatalf("Search incluyó al estudiante eliminado: %v", cedulas(encontrados))
+		}
+
+		// Sus inscripciones desaparecen de las consultas sin haberse borrado
+		if n, _ := repos.Inscripciones.CountByEstudiante("1111111"); n != 0 {
+			t.Fatalf("CountByEstudiante eliminado = %d, se esperaba 0", n)
+		}
+		conteos, err := repos.Inscripciones.CountGroupedByMateria()
+		if err != nil {
+			t.Fatalf("CountGroupedByMateria: %v", err)
+		}
+		verificarConteos(t, conteos, map[string]int{"1040": 3, "1050": 1, "1080": 1})
+		estudiantes, _ := repos.Inscripciones.GetByMateria("1040")
+		verificarOrden(t, cedulas(estudiantes), []string{"3333333", "4444444", "5555555"})
+
+		if err := repos.Estudiantes.Restore("1111111"); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if e, _ := repos.Estudiantes.GetByCedula("1111111"); e == nil || e.Nombre != "Ana Garcia" {
+			t.Fatalf("GetByCedula restaurado = %+v", e)
+		}
+		if n, _ := repos.Inscripciones.CountByEstudiante("1111111"); n != 2 {
+			t.Fatalf("CountByEstudiante restaurado = %d, se esperaba 2", n)
+		}
+	})
+
+	t.Run("MateriaOcultaYRestaurada", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+		if err := repos.Materias.Delete("1040"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		if m, err := repos.Materias.GetByCodigo("1040"); err != nil || m != nil {
+			t.Fatalf("GetByCodigo eliminada = %+v, %v; se esperaba nil, nil", m, err)
+		}
+		if encontradas, _ := repos.Materias.Search("calculo", 0); len(encontradas) != 0 {
+			t.Fatalf("Search incluyó la materia eliminada: %v", codigos(encontradas))
+		}
+		materias, _ := repos.Inscripciones.GetByEstudiante("1111111")
+		verificarOrden(t, codigos(materias), []string{"1050"})
+		todas, err := repos.Inscripciones.GetAll()
+		if err != nil {
+			t.Fatalf("GetAll: %v", err)
+		}
+		if len(todas) != 3 {
+			t.Fatalf("GetAll retornó %d inscripciones, se esperaban 3", len(todas))
+		}
+
+		if err := repos.Materias.Restore("1040"); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if existe, _ := repos.Materias.Exists("1040"); !existe {
+			t.Fatal("la materia restaurada no existe")
+		}
+	})
+
+	t.Run("Inscripcion", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+		if err := repos.Inscripciones.Delete("1111111", "1040"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		if existe, _ := repos.Inscripciones.Exists("1111111", "1040"); existe {
+			t.Fatal("Exists retornó true para una inscripción eliminada")
+		}
+		if n, _ := repos.Inscripciones.CountByEstudiante("1111111"); n != 1 {
+			t.Fatalf("CountByEstudiante = %d, se esperaba 1", n)
+		}
+
+		// Volver a inscribir exige restaurar la inscripción eliminada
+		if err := repos.Inscripciones.Create("1111111", "1040"); !errors.Is(err, repository.ErrEliminado) {
+			t.Fatalf("Create sobre inscripción eliminada = %v, se esperaba ErrEliminado", err)
+		}
+		if err := repos.Inscripciones.Restore("1111111", "1040"); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if existe, _ := repos.Inscripciones.Exists("1111111", "1040"); !existe {
+			t.Fatal("la inscripción restaurada no existe")
+		}
+	})
+
+	t.Run("CreateSobreEliminado", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+		if err := repos.Estudiantes.Delete("2222222"); err != nil {
+			t.Fatalf("Delete estudiante: %v", err)
+		}
+		if err := repos.Materias.Delete("1050"); err != nil {
+			t.Fatalf("Delete materia: %v", err)
+		}
+
+		if err := repos.Estudiantes.Create(domain.NewEstudiante("2222222", "Otro")); !errors.Is(err, repository.ErrEliminado) {
+			t.Fatalf("Create estudiante eliminado = %v, se esperaba ErrEliminado", err)
+		}
+		if err := repos.Materias.Create(domain.NewMateria("1050", "Otra")); !errors.Is(err, repository.ErrEliminado) {
+			t.Fatalf("Create materia eliminada = %v, se esperaba ErrEliminado", err)
+		}
+		if err := repos.Inscripciones.Create("2222222", "1040"); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Create inscripción de estudiante eliminado = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if err := repos.Inscripciones.Create("3333333", "1050"); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Create inscripción en materia eliminada = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+	})
+
+	t.Run("NoEncontrado", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+
+		if err := repos.Estudiantes.Delete("0000000"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Delete inexistente = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if err := repos.Estudiantes.Restore("1111111"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Restore de estudiante activo = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if err := repos.Materias.Delete("1040"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Materias.Delete("1040"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Delete repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if err := repos.Inscripciones.Delete("2222222", "1040"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Delete de inscripción inexistente = %v, se esperaba ErrNoEncontrado", err)
+		}
+	})
+
+	t.Run("Listados", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+		if err := repos.Estudiantes.Delete("2222222"); err != nil {
+			t.Fatalf("Delete estudiante: %v", err)
+		}
+		if err := repos.Materias.Delete("1050"); err != nil {
+			t.Fatalf("Delete materia: %v", err)
+		}
+		if err := repos.Inscripciones.Delete("3333333", "1040"); err != nil {
+			t.Fatalf("Delete inscripción: %v", err)
+		}
+
+		verificarOrden(t, recorrer(t, repos.Estudiantes.List, repository.Consulta{Limite: 2}, cedula),
+			[]string{"1111111", "3333333", "4444444", "5555555"})
+		verificarOrden(t, recorrer(t, repos.Estudiantes.List, repository.Consulta{Limite: 2, Eliminados: repository.SoloEliminados}, cedula),
+			[]string{"2222222"})
+		verificarOrden(t, recorrer(t, repos.Materias.List, repository.Consulta{Eliminados: repository.IncluirEliminados}, codigo),
+			[]string{"1040", "1050", "1080"})
+
+		pagina, err := repos.Estudiantes.List(repository.Consulta{Eliminados: repository.SoloEliminados})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(pagina.Elementos) != 1 || pagina.Elementos[0].EliminadoEn == nil {
+			t.Fatalf("el estudiante eliminado no trae la fecha de eliminación: %+v", pagina.Elementos)
+		}
+
+		verificarOrden(t, recorrer(t, repos.Inscripciones.List, repository.Consulta{Limite: 2}, inscripcion),
+			[]string{"1111111/1040", "4444444/1040", "5555555/1040"})
+		verificarOrden(t, recorrer(t, repos.Inscripciones.List, repository.Consulta{Limite: 2, Eliminados: repository.SoloEliminados}, inscripcion),
+			[]string{"1111111/1050", "2222222/1080", "3333333/1040", "4444444/1050"})
+
+		eliminadas, err := repos.Inscripciones.List(repository.Consulta{Codigo: "1040", Eliminados: repository.SoloEliminados})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(eliminadas.Elementos) != 1 || eliminadas.Elementos[0].EliminadaEn == nil {
+			t.Fatalf("la inscripción eliminada no trae la fecha de eliminación: %+v", eliminadas.Elementos)
+		}
+
+		verificarOrden(t, recorrer(t, func(c repository.Consulta) (*repository.Pagina[*domain.Materia], error) {
+			return repos.Inscripciones.ListByEstudiante("1111111", c)
+		}, repository.Consulta{Eliminados: repository.SoloEliminados}, codigo), []string{"1050"})
+		verificarOrden(t, recorrer(t, func(c repository.Consulta) (*repository.Pagina[*domain.Estudiante], error) {
+			return repos.Inscripciones.ListByMateria("1040", c)
+		}, repository.Consulta{}, cedula), []string{"1111111", "4444444", "5555555"})
+	})
+
+	t.Run("Auditoria", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+		if err := repos.Estudiantes.Delete("2222222"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Estudiantes.Restore("2222222"); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+
+		historial, err := repos.Auditoria.HistorialEstudiante("2222222")
+		if err != nil {
+			t.Fatalf("HistorialEstudiante: %v", err)
+		}
+		var operaciones []string
+		for _, registro := range historial {
+			operaciones = append(operaciones, registro.Operacion)
+		}
+		verificarOrden(t, operaciones, []string{
+			domain.OperacionCrear, domain.OperacionCrear, domain.OperacionEliminar, domain.OperacionRestaurar,
+		})
+	})
+}
@@ -0,0 +1,134 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func tiposEventos(eventos []*domain.Evento) []string {
+	var tipos []string
+	for _, e := range eventos {
+		tipos = append(tipos, e.Tipo)
+	}
+	return tipos
+}
+
+func probarEventos(t *testing.T, nuevos Fabrica) {
+	t.Run("PublicarYPendientes", func(t *testing.T) {
+		repo := nuevos(t).Eventos
+		creado := domain.NewEvento(domain.EstudianteCreado{Cedula: "1234567", Nombre: "Lulú López"})
+		if err := repo.Publicar(creado); err != nil {
+			t.Fatalf("Publicar: %v", err)
+		}
+		inscrito := domain.NewEvento(domain.InscripcionCreada{Cedula: "1234567", Codigo: "1040"})
+		if err := repo.Publicar(inscrito); err != nil {
+			t.Fatalf("Publicar: %v", err)
+		}
+		if creado.ID == 0 || inscrito.ID <= creado.ID {
+			t.Fatalf("IDs = %d, %d; se esperaban crecientes", creado.ID, inscrito.ID)
+		}
+
+		pendientes, err := repo.Pendientes(10)
+		if err != nil {
+			t.Fatalf("Pendientes: %v", err)
+		}
+		if len(pendientes) != 2 || pendientes[0].ID != creado.ID || pendientes[1].ID != inscrito.ID {
+			t.Fatalf("Pendientes = %v, se esperaban los dos eventos en orden", tiposEventos(pendientes))
+		}
+		var datos domain.EstudianteCreado
+		if err := pendientes[0].Decodificar(&datos); err != nil || datos.Nombre != "Lulú López" {
+			t.Fatalf("Decodificar = %+v, %v; se esperaba Lulú López", datos, err)
+		}
+		if !pendientes[0].Fecha.Equal(creado.Fecha) {
+			t.Errorf("Fecha = %v, se esperaba %v", pendientes[0].Fecha, creado.Fecha)
+		}
+
+		if limitados, _ := repo.Pendientes(1); len(limitados) != 1 {
+			t.Errorf("Pendientes(1) = %d eventos, se esperaba 1", len(limitados))
+		}
+	})
+
+	t.Run("MarcarEntregado", func(t *testing.T) {
+		repo := nuevos(t).Eventos
+		evento := domain.NewEvento(domain.MateriaCreada{Codigo: "1040", Nombre: "Cálculo"})
+		if err := repo.Publicar(evento); err != nil {
+			t.Fatalf("Publicar: %v", err)
+		}
+		if err := repo.MarcarEntregado(evento.ID); err != nil {
+			t.Fatalf("MarcarEntregado: %v", err)
+		}
+		// Entregar dos veces el mismo evento no es un error: la entrega es al menos una vez
+		if err := repo.MarcarEntregado(evento.ID); err != nil {
+			t.Fatalf("MarcarEntregado repetido: %v", err)
+		}
+		if pendientes, _ := repo.Pendientes(10); len(pendientes) != 0 {
+			t.Fatalf("Pendientes = %v, no se esperaba ninguno", tiposEventos(pendientes))
+		}
+		if err := repo.MarcarFallido(evento.ID, time.Now(), "tarde"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("MarcarFallido entregado = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if err := repo.MarcarEntregado(evento.ID + 100); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("MarcarEntregado inexistente = %v, se esperaba ErrNoEncontrado", err)
+		}
+	})
+
+	t.Run("MarcarFallidoPostergaElReintento", func(t *testing.T) {
+		repo := nuevos(t).Eventos
+		evento := domain.NewEvento(domain.InscripcionCancelada{Cedula: "1234567", Codigo: "1040"})
+		if err := repo.Publicar(evento); err != nil {
+			t.Fatalf("Publicar: %v", err)
+		}
+
+		if err := repo.MarcarFallido(evento.ID, time.Now().Add(time.Hour), "suscriptor caído"); err != nil {
+			t.Fatalf("MarcarFallido: %v", err)
+		}
+		if pendientes, _ := repo.Pendientes(10); len(pendientes) != 0 {
+			t.Fatalf("Pendientes = %v, el reintento debía esperar", tiposEventos(pendientes))
+		}
+
+		if err := repo.MarcarFallido(evento.ID, time.Now().Add(-time.Second), "otra vez"); err != nil {
+			t.Fatalf("MarcarFallido: %v", err)
+		}
+		pendientes, _ := repo.Pendientes(10)
+		if len(pendientes) != 1 || pendientes[0].Intentos != 2 {
+			t.Fatalf("Pendientes = %+v, se esperaba el evento con 2 intentos", pendientes)
+		}
+	})
+
+	t.Run("SeConfirmaConLaUnidadDeTrabajo", func(t *testing.T) {
+		repos := nuevos(t)
+		errAbortar := errors.New("abortar")
+		err := repos.EnTransaccion(func(tx *repository.Repositorios) error {
+			if err := tx.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+				return err
+			}
+			if err := tx.Eventos.Publicar(domain.NewEvento(domain.EstudianteCreado{Cedula: "1234567"})); err != nil {
+				return err
+			}
+			return errAbortar
+		})
+		if !errors.Is(err, errAbortar) {
+			t.Fatalf("EnTransaccion = %v, se esperaba el error de fn", err)
+		}
+		if pendientes, _ := repos.Eventos.Pendientes(10); len(pendientes) != 0 {
+			t.Fatalf("Pendientes = %v, el evento de un cambio descartado no debe quedar", tiposEventos(pendientes))
+		}
+
+		err = repos.EnTransaccion(func(tx *repository.Repositorios) error {
+			if err := tx.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+				return err
+			}
+			return tx.Eventos.Publicar(domain.NewEvento(domain.EstudianteCreado{Cedula: "1234567"}))
+		})
+		if err != nil {
+			t.Fatalf("EnTransaccion: %v", err)
+		}
+		if pendientes, _ := repos.Eventos.Pendientes(10); len(pendientes) != 1 {
+			t.Fatalf("Pendientes = %v, se esperaba el evento confirmado", tiposEventos(pendientes))
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package repotest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// otraFacultad crea, sobre los mismos datos, repositorios de otra facultad
+func otraFacultad(t *testing.T, repos *repository.Repositorios, facultad string) *repository.Repositorios {
+	t.Helper()
+	otra, err := repos.ConFacultad(facultad)
+	if err != nil {
+		t.Fatalf("ConFacultad(%q): %v", facultad, err)
+	}
+	return otra
+}
+
+func probarFacultades(t *testing.T, nuevos Fabrica) {
+	t.Run("AislaLosDatos", func(t *testing.T) {
+		principal := nuevos(t)
+		norte := otraFacultad(t, principal, "sede-norte")
+
+		// La misma cédula y el mismo código pueden existir en las dos facultades
+		for _, repos := range []*repository.Repositorios{principal, norte} {
+			nombre := "Lulú López de " + repos.Facultad()
+			if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", nombre)); err != nil {
+				t.Fatalf("Create estudiante en %s: %v", repos.Facultad(), err)
+			}
+			if err := repos.Materias.Create(domain.NewMateria("1040", "Cálculo")); err != nil {
+				t.Fatalf("Create materia en %s: %v", repos.Facultad(), err)
+			}
+		}
+		if err := principal.Materias.Create(domain.NewMateria("1050", "Física I")); err != nil {
+			t.Fatalf("Create materia: %v", err)
+		}
+		if err := principal.Inscripciones.Create("1234567", "1050"); err != nil {
+			t.Fatalf("Create inscripción: %v", err)
+		}
+
+		if e, _ := norte.Estudiantes.GetByCedula("1234567"); e == nil || e.Nombre != "Lulú López de sede-norte" {
+			t.Fatalf("GetByCedula en sede-norte = %+v, se esperaba su propio estudiante", e)
+		}
+		if existe, _ := norte.Materias.Exists("1050"); existe {
+			t.Fatal("sede-norte ve una materia de otra facultad")
+		}
+		// Una inscripción no puede apuntar a una materia de otra facultad
+		if err := norte.Inscripciones.Create("1234567", "1050"); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Create inscripción entre facultades = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if inscripciones, _ := norte.Inscripciones.GetAll(); len(inscripciones) != 0 {
+			t.Fatalf("sede-norte ve %d inscripciones de otra facultad", len(inscripciones))
+		}
+		if conteos, _ := norte.Inscripciones.CountGroupedByEstudiante(); len(conteos) != 0 {
+			t.Fatalf("CountGroupedByEstudiante en sede-norte = %v, se esperaba vacío", conteos)
+		}
+		if pagina, _ := norte.Materias.List(repository.Consulta{}); len(pagina.Elementos) != 1 {
+			t.Fatalf("List de materias en sede-norte = %d, se esperaba 1", len(pagina.Elementos))
+		}
+		if encontrados, _ := norte.Estudiantes.Search("lopez", 10); len(encontrados) != 1 || encontrados[0].Nombre != "Lulú López de sede-norte" {
+			t.Fatalf("Search en sede-norte = %+v, se esperaba solo su estudiante", encontrados)
+		}
+
+		// Eliminar o editar en una facultad no afecta a la otra
+		if err := principal.Estudiantes.Delete("1234567"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		e, _ := norte.Estudiantes.GetByCedula("1234567")
+		if e == nil {
+			t.Fatal("eliminar en principal ocultó el estudiante de sede-norte")
+		}
+		e.Nombre = "Lucía López"
+		if err := norte.Estudiantes.Update(e); err != nil {
+			t.Fatalf("Update en sede-norte: %v", err)
+		}
+		if err := principal.Estudiantes.Restore("1234567"); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+		if e, _ := principal.Estudiantes.GetByCedula("1234567"); e == nil || e.Nombre != "Lulú López de principal" || e.Version != 1 {
+			t.Fatalf("GetByCedula en principal = %+v, no debía cambiar", e)
+		}
+
+		historial, _ := norte.Auditoria.HistorialEstudiante("1234567")
+		if len(historial) != 2 {
+			t.Fatalf("HistorialEstudiante en sede-norte = %d registros, se esperaban 2 (alta y edición)", len(historial))
+		}
+	})
+
+	t.Run("EventosPorFacultad", func(t *testing.T) {
+		principal := nuevos(t)
+		norte := otraFacultad(t, principal, "sede-norte")
+		if err := principal.Eventos.Publicar(domain.NewEvento(domain.MateriaCreada{Codigo: "1040"})); err != nil {
+			t.Fatalf("Publicar: %v", err)
+		}
+		if pendientes, _ := norte.Eventos.Pendientes(10); len(pendientes) != 0 {
+			t.Fatalf("Pendientes en sede-norte = %v, no se esperaba ninguno", tiposEventos(pendientes))
+		}
+		if pendientes, _ := principal.Eventos.Pendientes(10); len(pendientes) != 1 {
+			t.Fatalf("Pendientes en principal = %v, se esperaba el evento publicado", tiposEventos(pendientes))
+		}
+	})
+
+	t.Run("UnidadDeTrabajoConservaLaFacultad", func(t *testing.T) {
+		principal := nuevos(t)
+		norte := otraFacultad(t, principal, "sede-norte")
+		err := norte.EnTransaccion(func(tx *repository.Repositorios) error {
+			if tx.Facultad() != "sede-norte" {
+				t.Errorf("Facultad en la unidad de trabajo = %q, se esperaba sede-norte", tx.Facultad())
+			}
+			return tx.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López"))
+		})
+		if err != nil {
+			t.Fatalf("EnTransaccion: %v", err)
+		}
+		if existe, _ := principal.Estudiantes.Exists("1234567"); existe {
+			t.Fatal("el alta hecha en la unidad de trabajo de sede-norte aparece en principal")
+		}
+		if existe, _ := norte.Estudiantes.Exists("1234567"); !existe {
+			t.Fatal("el alta de la unidad de trabajo no quedó en sede-norte")
+		}
+	})
+
+	t.Run("ListarFacultades", func(t *testing.T) {
+		principal := nuevos(t)
+		if err := principal.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		norte := otraFacultad(t, principal, " Sede-Norte ")
+		if norte.Facultad() != "sede-norte" {
+			t.Fatalf("Facultad = %q, se esperaba el nombre normalizado", norte.Facultad())
+		}
+		if err := norte.Materias.Create(domain.NewMateria("1040", "Cálculo")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		// Una facultad sin datos no aparece
+		otraFacultad(t, principal, "sede-sur")
+
+		facultades, err := principal.Facultades()
+		if err != nil {
+			t.Fatalf("Facultades: %v", err)
+		}
+		if esperadas := []string{repository.FacultadPorDefecto, "sede-norte"}; !reflect.DeepEqual(facultades, esperadas) {
+			t.Fatalf("Facultades = %v, se esperaban %v", facultades, esperadas)
+		}
+
+		if _, err := principal.ConFacultad("sede norte"); !errors.Is(err, repository.ErrFacultadInvalida) {
+			t.Fatalf("ConFacultad con espacios = %v, se esperaba ErrFacultadInvalida", err)
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package repotest
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// gruposCalculo crea Cálculo con los grupos 1 y 2, y a Lulú, Pepito y Ana
+func gruposCalculo(t *testing.T, repos *repository.Repositorios) {
+	t.Helper()
+	estudiantesCupos(t, repos)
+	materiaConCupo(t, repos, "1040", "Cálculo", domain.SinCupoLimite)
+	for _, numero := range []int{2, 1} {
+		if err := repos.Grupos.Create(domain.Grupo{Materia: "1040", Numero: numero}); err != nil {
+			t.Fatalf("Create grupo %d: %v", numero, err)
+		}
+	}
+}
+
+func probarGrupos(t *testing.T, nuevos Fabrica) {
+	t.Run("CreateYDelete", func(t *testing.T) {
+		repos := nuevos(t)
+		gruposCalculo(t, repos)
+
+		grupos, err := repos.Grupos.GetByMateria("1040")
+		if err != nil {
+			t.Fatalf("GetByMateria: %v", err)
+		}
+		esperados := []domain.Grupo{{Materia: "1040", Numero: 1}, {Materia: "1040", Numero: 2}}
+		if len(grupos) != 2 || grupos[0] != esperados[0] || grupos[1] != esperados[1] {
+			t.Fatalf("GetByMateria = %v, se esperaban %v", grupos, esperados)
+		}
+		if err := repos.Grupos.Create(domain.Grupo{Materia: "1040", Numero: 1}); !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Create repetido = %v, se esperaba ErrDuplicado", err)
+		}
+		if err := repos.Grupos.Create(domain.Grupo{Materia: "9999", Numero: 1}); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Create en materia inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+
+		// Los grupos son comunes a todos los periodos
+		if grupos, _ := otroPeriodo(t, repos, "2020-2").Grupos.GetByMateria("1040"); len(grupos) != 2 {
+			t.Fatalf("GetByMateria en 2020-2 = %v, se esperaban los mismos grupos", grupos)
+		}
+
+		if err := repos.Grupos.Delete(domain.Grupo{Materia: "1040", Numero: 2}); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Grupos.Delete(domain.Grupo{Materia: "1040", Numero: 2}); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Delete repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if todos, _ := repos.Grupos.GetAll(); len(todos) != 1 {
+			t.Fatalf("GetAll = %v, se esperaba solo el grupo 1", todos)
+		}
+	})
+
+	t.Run("InscripcionEnGrupo", func(t *testing.T) {
+		repos := nuevos(t)
+		gruposCalculo(t, repos)
+
+		for _, i := range []struct {
+			cedula string
+			grupo  int
+		}{{"1234567", 1}, {"9876534", 2}, {"5555555", domain.SinGrupo}} {
+			if err := repos.Inscripciones.CreateEnGrupo(i.cedula, "1040", i.grupo); err != nil {
+				t.Fatalf("CreateEnGrupo(%s, %d): %v", i.cedula, i.grupo, err)
+			}
+		}
+		if err := repos.Inscripciones.Delete("5555555", "1040"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Inscripciones.CreateEnGrupo("5555555", "1040", 3); !errors.Is(err, repository.ErrEliminado) {
+			t.Fatalf("CreateEnGrupo sobre una cancelada = %v, se esperaba ErrEliminado", err)
+		}
+		if err := repos.Inscripciones.Restore("5555555", "1040"); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+
+		otro := otroPeriodo(t, repos, "2020-2")
+		if err := otro.Inscripciones.CreateEnGrupo("5555555", "1040", 3); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("CreateEnGrupo en un grupo inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if existe, _ := otro.Inscripciones.Exists("5555555", "1040"); existe {
+			t.Fatal("la inscripción en un grupo inexistente quedó guardada")
+		}
+
+		for grupo, esperadas := range map[int][]string{1: {"1234567"}, 2: {"9876534"}, domain.SinGrupo: {"5555555"}} {
+			estudiantes, err := repos.Inscripciones.GetByGrupo("1040", grupo)
+			if err != nil {
+				t.Fatalf("GetByGrupo(%d): %v", grupo, err)
+			}
+			verificarOrden(t, cedulas(estudiantes), esperadas)
+		}
+		todas, _ := repos.Inscripciones.GetAll()
+		if len(todas) != 3 || todas[0].Grupo != 1 || todas[1].Grupo != domain.SinGrupo || todas[2].Grupo != 2 {
+			t.Fatalf("GetAll = %+v, se esperaban los grupos de cada inscripción", todas)
+		}
+		if historial, _ := repos.Inscripciones.HistorialByEstudiante("9876534"); len(historial) != 1 || historial[0].Grupo != 2 {
+			t.Fatalf("HistorialByEstudiante = %+v, se esperaba el grupo 2", historial)
+		}
+
+		// Un grupo con inscritos no se puede eliminar
+		if err := repos.Grupos.Delete(domain.Grupo{Materia: "1040", Numero: 1}); !errors.Is(err, repository.ErrGrupoConInscritos) {
+			t.Fatalf("Delete con inscritos = %v, se esperaba ErrGrupoConInscritos", err)
+		}
+	})
+
+	t.Run("CambiarGrupo", func(t *testing.T) {
+		repos := nuevos(t)
+		gruposCalculo(t, repos)
+		if err := repos.Inscripciones.Create("1234567", "1040"); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		if err := repos.Inscripciones.CambiarGrupo("1234567", "1040", 2); err != nil {
+			t.Fatalf("CambiarGrupo: %v", err)
+		}
+		verificarOrden(t, cedulas(inscritosEnGrupo(t, repos, 2)), []string{"1234567"})
+		if err := repos.Inscripciones.CambiarGrupo("1234567", "1040", 5); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("CambiarGrupo a un grupo inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if err := repos.Inscripciones.CambiarGrupo("9876534", "1040", 1); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("CambiarGrupo sin inscripción = %v, se esperaba ErrNoEncontrado", err)
+		}
+
+		// El cambio queda en la auditoría con el grupo anterior y el nuevo
+		historial, err := repos.Auditoria.HistorialEstudiante("1234567")
+		if err != nil {
+			t.Fatalf("HistorialEstudiante: %v", err)
+		}
+		ultimo := historial[len(historial)-1]
+		if ultimo.Operacion != domain.OperacionActualizar || !strings.Contains(ultimo.Antes, `"grupo":"0"`) || !strings.Contains(ultimo.Despues, `"grupo":"2"`) {
+			t.Fatalf("último registro = %+v, se esperaba el cambio al grupo 2", ultimo)
+		}
+
+		// Volver a SinGrupo siempre es posible, y libera el grupo
+		if err := repos.Inscripciones.CambiarGrupo("1234567", "1040", domain.SinGrupo); err != nil {
+			t.Fatalf("CambiarGrupo a SinGrupo: %v", err)
+		}
+		if err := repos.Grupos.Delete(domain.Grupo{Materia: "1040", Numero: 2}); err != nil {
+			t.Fatalf("Delete del grupo sin inscritos: %v", err)
+		}
+	})
+
+	t.Run("Volcado", func(t *testing.T) {
+		origen := nuevos(t)
+		gruposCalculo(t, origen)
+		if err := origen.Inscripciones.CreateEnGrupo("1234567", "1040", 2); err != nil {
+			t.Fatalf("CreateEnGrupo: %v", err)
+		}
+		if err := origen.Inscripciones.Create("9876534", "1040"); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		var script bytes.Buffer
+		if err := origen.Volcar(&script); err != nil {
+			t.Fatalf("Volcar: %v", err)
+		}
+		destino := nuevos(t)
+		if err := destino.Cargar(strings.NewReader(script.String())); err != nil {
+			t.Fatalf("Cargar: %v", err)
+		}
+		if grupos, _ := destino.Grupos.GetByMateria("1040"); len(grupos) != 2 {
+			t.Fatalf("GetByMateria tras cargar = %v, se esperaban 2 grupos", grupos)
+		}
+		verificarOrden(t, cedulas(inscritosEnGrupo(t, destino, 2)), []string{"1234567"})
+		verificarOrden(t, cedulas(inscritosEnGrupo(t, destino, domain.SinGrupo)), []string{"9876534"})
+
+		// Una inscripción en un grupo que el volcado no trae se rechaza antes de cargar nada
+		huerfana := "INSERT INTO estudiantes (cedula, nombre, deleted_at) VALUES ('1234567', 'Lulú López', NULL);\n" +
+			"INSERT INTO materias (codigo, nombre, deleted_at) VALUES ('1040', 'Cálculo', NULL);\n" +
+			"INSERT INTO inscripciones (estudiante_cedula, materia_codigo, grupo, deleted_at) VALUES ('1234567', '1040', '3', NULL);\n"
+		vacio := nuevos(t)
+		if err := vacio.Cargar(strings.NewReader(huerfana)); !errors.Is(err, repository.ErrVolcadoInvalido) {
+			t.Fatalf("Cargar con un grupo inexistente = %v, se esperaba ErrVolcadoInvalido", err)
+		}
+		if existe, _ := vacio.Estudiantes.Exists("1234567"); existe {
+			t.Fatal("el volcado rechazado dejó datos a medias")
+		}
+	})
+}
+
+// inscritosEnGrupo retorna los inscritos vigentes en el grupo de Cálculo
+func inscritosEnGrupo(t *testing.T, repos *repository.Repositorios, grupo int) []*domain.Estudiante {
+	t.Helper()
+	estudiantes, err := repos.Inscripciones.GetByGrupo("1040", grupo)
+	if err != nil {
+		t.Fatalf("GetByGrupo(%d): %v", grupo, err)
+	}
+	return estudiantes
+}
@@ -0,0 +1,121 @@
+package repotest
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// Sesiones de Cálculo: la clase magistral, de todos los inscritos, y el taller del grupo 1
+var (
+	magistralCalculo = domain.Sesion{Materia: "1040", Grupo: domain.SinGrupo, Dia: domain.Lunes, Inicio: 8 * 60, Fin: 10 * 60, Salon: "A-101"}
+	tallerCalculo    = domain.Sesion{Materia: "1040", Grupo: 1, Dia: domain.Miercoles, Inicio: 14 * 60, Fin: 16 * 60, Salon: "B-202"}
+)
+
+// horarioCalculo crea Cálculo con sus grupos y sus dos sesiones
+func horarioCalculo(t *testing.T, repos *repository.Repositorios) {
+	t.Helper()
+	gruposCalculo(t, repos)
+	for _, s := range []domain.Sesion{tallerCalculo, magistralCalculo} {
+		if err := repos.Horarios.Create(s); err != nil {
+			t.Fatalf("Create sesión %s: %v", s.Franja(), err)
+		}
+	}
+}
+
+func probarHorarios(t *testing.T, nuevos Fabrica) {
+	t.Run("CreateYDelete", func(t *testing.T) {
+		repos := nuevos(t)
+		horarioCalculo(t, repos)
+
+		sesiones, err := repos.Horarios.GetByMateria("1040")
+		if err != nil {
+			t.Fatalf("GetByMateria: %v", err)
+		}
+		if !reflect.DeepEqual(sesiones, []domain.Sesion{magistralCalculo, tallerCalculo}) {
+			t.Fatalf("GetByMateria = %+v, se esperaban la magistral y el taller", sesiones)
+		}
+
+		// La clave es materia, grupo, día e inicio: otro salón no la hace distinta
+		repetida := magistralCalculo
+		repetida.Salon = "C-303"
+		if err := repos.Horarios.Create(repetida); !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Create repetida = %v, se esperaba ErrDuplicado", err)
+		}
+		enGrupoInexistente := tallerCalculo
+		enGrupoInexistente.Grupo = 7
+		if err := repos.Horarios.Create(enGrupoInexistente); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Create en un grupo inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		enMateriaInexistente := magistralCalculo
+		enMateriaInexistente.Materia = "9999"
+		if err := repos.Horarios.Create(enMateriaInexistente); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Create en una materia inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+
+		// Las sesiones son comunes a todos los periodos
+		if sesiones, _ := otroPeriodo(t, repos, "2020-2").Horarios.GetByMateria("1040"); len(sesiones) != 2 {
+			t.Fatalf("GetByMateria en 2020-2 = %+v, se esperaban las mismas sesiones", sesiones)
+		}
+
+		if err := repos.Horarios.Delete(magistralCalculo); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Horarios.Delete(magistralCalculo); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Delete repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if todas, _ := repos.Horarios.GetAll(); !reflect.DeepEqual(todas, []domain.Sesion{tallerCalculo}) {
+			t.Fatalf("GetAll = %+v, se esperaba solo el taller", todas)
+		}
+	})
+
+	t.Run("EliminarGrupoQuitaSusSesiones", func(t *testing.T) {
+		repos := nuevos(t)
+		horarioCalculo(t, repos)
+
+		if err := repos.Grupos.Delete(domain.Grupo{Materia: "1040", Numero: 1}); err != nil {
+			t.Fatalf("Delete grupo: %v", err)
+		}
+		if sesiones, _ := repos.Horarios.GetByMateria("1040"); !reflect.DeepEqual(sesiones, []domain.Sesion{magistralCalculo}) {
+			t.Fatalf("GetByMateria = %+v, se esperaba solo la magistral", sesiones)
+		}
+	})
+
+	t.Run("Volcado", func(t *testing.T) {
+		origen := nuevos(t)
+		horarioCalculo(t, origen)
+
+		var script bytes.Buffer
+		if err := origen.Volcar(&script); err != nil {
+			t.Fatalf("Volcar: %v", err)
+		}
+		destino := nuevos(t)
+		if err := destino.Cargar(strings.NewReader(script.String())); err != nil {
+			t.Fatalf("Cargar: %v", err)
+		}
+		if sesiones, _ := destino.Horarios.GetAll(); !reflect.DeepEqual(sesiones, []domain.Sesion{magistralCalculo, tallerCalculo}) {
+			t.Fatalf("GetAll tras cargar = %+v, se esperaban las dos sesiones", sesiones)
+		}
+
+		// Una sesión de un grupo que el volcado no trae se rechaza antes de cargar nada
+		huerfana := "INSERT INTO materias (codigo, nombre, deleted_at) VALUES ('1040', 'Cálculo', NULL);\n" +
+			"INSERT INTO sesiones (materia_codigo, grupo, dia, inicio, fin, salon) VALUES ('1040', '2', '1', '480', '600', 'A-101');\n"
+		vacio := nuevos(t)
+		if err := vacio.Cargar(strings.NewReader(huerfana)); !errors.Is(err, repository.ErrVolcadoInvalido) {
+			t.Fatalf("Cargar con un grupo inexistente = %v, se esperaba ErrVolcadoInvalido", err)
+		}
+		invertida := "INSERT INTO materias (codigo, nombre, deleted_at) VALUES ('1040', 'Cálculo', NULL);\n" +
+			"INSERT INTO sesiones (materia_codigo, grupo, dia, inicio, fin, salon) VALUES ('1040', '0', '1', '600', '480', 'A-101');\n"
+		if err := vacio.Cargar(strings.NewReader(invertida)); !errors.Is(err, repository.ErrVolcadoInvalido) {
+			t.Fatalf("Cargar con una sesión que termina antes de empezar = %v, se esperaba ErrVolcadoInvalido", err)
+		}
+		if existe, _ := vacio.Materias.Exists("1040"); existe {
+			t.Fatal("el volcado rechazado dejó datos a medias")
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// datosListados crea cinco estudiantes y tres materias con varias inscripciones
+func datosListados(t *testing.T, nuevos Fabrica) *repository.Repositorios {
+	t.Helper()
+	repos := nuevos(t)
+
+	for _, e := range []*domain.Estudiante{
+		domain.NewEstudiante("5555555", "Laura Fernandez"),
+		domain.NewEstudiante("1111111", "Ana Garcia"),
+		domain.NewEstudiante("4444444", "Juan Martinez"),
+		domain.NewEstudiante("2222222", "Carlos Ruiz"),
+		domain.NewEstudiante("3333333", "Ana Rodriguez"),
+	} {
+		if err := repos.Estudiantes.Create(e); err != nil {
+			t.Fatalf("Create estudiante %s: %v", e.Cedula, err)
+		}
+	}
+	for _, m := range []*domain.Materia{
+		domain.NewMateria("1080", "Programacion I"),
+		domain.NewMateria("1040", "Calculo"),
+		domain.NewMateria("1050", "Fisica I"),
+	} {
+		if err := repos.Materias.Create(m); err != nil {
+			t.Fatalf("Create materia %s: %v", m.Codigo, err)
+		}
+	}
+	for _, p := range [][2]string{
+		{"1111111", "1040"}, {"1111111", "1050"}, {"2222222", "1080"},
+		{"3333333", "1040"}, {"4444444", "1040"}, {"4444444", "1050"},
+		{"5555555", "1040"},
+	} {
+		if err := repos.Inscripciones.Create(p[0], p[1]); err != nil {
+			t.Fatalf("Create inscripción %v: %v", p, err)
+		}
+	}
+	return repos
+}
+
+// recorrer sigue los cursores hasta la última página y retorna las claves visitadas
+func recorrer[T any](t *testing.T, listar func(repository.Consulta) (*repository.Pagina[T], error), consulta repository.Consulta, clave func(T) string) []string {
+	t.Helper()
+	var claves []string
+	for paginas := 0; ; paginas++ {
+		if paginas > 20 {
+			t.Fatal("la paginación no termina")
+		}
+		pagina, err := listar(consulta)
+		if err != nil {
+			t.Fatalf("List(%+v): %v", consulta, err)
+		}
+		if consulta.Limite > 0 && len(pagina.Elementos) > consulta.Limite {
+			t.Fatalf("la página tiene %d elementos, límite %d", len(pagina.Elementos), consulta.Limite)
+		}
+		for _, e := range pagina.Elementos {
+			claves = append(claves, clave(e))
+		}
+		if pagina.SiguienteCursor == "" {
+			return claves
+		}
+		consulta.Cursor = pagina.SiguienteCursor
+	}
+}
+
+func probarListados(t *testing.T, nuevos Fabrica) {
+	cedula := func(e *domain.Estudiante) string { return e.Cedula }
+	codigo := func(m *domain.Materia) string { return m.Codigo }
+	par := func(i *domain.Inscripcion) string { return i.Estudiante.Cedula + "-" + i.Materia.Codigo }
+
+	t.Run("PaginasPorClave", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+		obtenido := recorrer(t, repos.Estudiantes.List, repository.Consulta{Limite: 2}, cedula)
+		verificarOrden(t, obtenido, []string{"1111111", "2222222", "3333333", "4444444", "5555555"})
+
+		pagina, err := repos.Estudiantes.List(repository.Consulta{Limite: 5})
+		if err != nil || pagina.SiguienteCursor != "" {
+			t.Fatalf("una página exacta no debe tener cursor: %q, %v", pagina.SiguienteCursor, err)
+		}
+	})
+
+	t.Run("OrdenPorNombreDescendente", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+		consulta := repository.Consulta{Limite: 2, Orden: repository.OrdenPorNombre, Descendente: true}
+		obtenido := recorrer(t, repos.Estudiantes.List, consulta, cedula)
+		verificarOrden(t, obtenido, []string{"5555555", "4444444", "2222222", "3333333", "1111111"})
+
+		materias := recorrer(t, repos.Materias.List, repository.Consulta{Limite: 1, Orden: repository.OrdenPorNombre}, codigo)
+		verificarOrden(t, materias, []string{"1040", "1050", "1080"})
+	})
+
+	t.Run("Filtros", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+		obtenido := recorrer(t, repos.Estudiantes.List, repository.Consulta{Limite: 1, Nombre: "ana"}, cedula)
+		verificarOrden(t, obtenido, []string{"1111111", "3333333"})
+
+		obtenido = recorrer(t, repos.Estudiantes.List, repository.Consulta{Codigo: "44"}, cedula)
+		verificarOrden(t, obtenido, []string{"4444444"})
+
+		// Los comodines de LIKE se tratan como texto
+		obtenido = recorrer(t, repos.Estudiantes.List, repository.Consulta{Nombre: "%"}, cedula)
+		verificarOrden(t, obtenido, nil)
+
+		materias := recorrer(t, repos.Materias.List, repository.Consulta{Nombre: "I", Codigo: "10"}, codigo)
+		verificarOrden(t, materias, []string{"1050", "1080"})
+	})
+
+	t.Run("Inscripciones", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+
+		todas := recorrer(t, repos.Inscripciones.List, repository.Consulta{Limite: 3}, par)
+		verificarOrden(t, todas, []string{"1111111-1040", "1111111-1050", "2222222-1080", "3333333-1040", "4444444-1040", "4444444-1050", "5555555-1040"})
+
+		porMateria := recorrer(t, repos.Inscripciones.List, repository.Consulta{Limite: 2, Orden: repository.OrdenPorMateria, Codigo: "105"}, par)
+		verificarOrden(t, porMateria, []string{"1111111-1050", "4444444-1050"})
+
+		porNombre := recorrer(t, repos.Inscripciones.List, repository.Consulta{Limite: 2, Orden: repository.OrdenPorNombre, Nombre: "ana"}, par)
+		verificarOrden(t, porNombre, []string{"1111111-1040", "1111111-1050", "3333333-1040"})
+
+		listarPorEstudiante := func(q repository.Consulta) (*repository.Pagina[*domain.Materia], error) {
+			return repos.Inscripciones.ListByEstudiante("4444444", q)
+		}
+		materias := recorrer(t, listarPorEstudiante, repository.Consulta{Limite: 1, Descendente: true}, codigo)
+		verificarOrden(t, materias, []string{"1050", "1040"})
+
+		listarPorMateria := func(q repository.Consulta) (*repository.Pagina[*domain.Estudiante], error) {
+			return repos.Inscripciones.ListByMateria("1040", q)
+		}
+		estudiantes := recorrer(t, listarPorMateria, repository.Consulta{Limite: 3, Orden: repository.OrdenPorNombre}, cedula)
+		verificarOrden(t, estudiantes, []string{"1111111", "3333333", "4444444", "5555555"})
+
+		vacia, err := repos.Inscripciones.ListByMateria("9999", repository.Consulta{})
+		if err != nil || len(vacia.Elementos) != 0 || vacia.SiguienteCursor != "" {
+			t.Fatalf("ListByMateria inexistente = %+v, %v; se esperaba página vacía", vacia, err)
+		}
+	})
+
+	t.Run("ConsultaInvalida", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+
+		_, err := repos.Estudiantes.List(repository.Consulta{Cursor: "no-es-un-cursor"})
+		if !errors.Is(err, repository.ErrCursorInvalido) {
+			t.Fatalf("cursor inválido = %v, se esperaba ErrCursorInvalido", err)
+		}
+
+		_, err = repos.Materias.List(repository.Consulta{Orden: repository.OrdenPorMateria})
+		if !errors.Is(err, repository.ErrOrdenInvalido) {
+			t.Fatalf("orden inválido = %v, se esperaba ErrOrdenInvalido", err)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// otroPeriodo crea el periodo si hace falta y retorna los repositorios de sus inscripciones
+func otroPeriodo(t *testing.T, repos *repository.Repositorios, codigo string) *repository.Repositorios {
+	t.Helper()
+	if existe, _ := repos.Periodos.Exists(codigo); !existe {
+		if err := repos.Periodos.Create(codigo); err != nil {
+			t.Fatalf("Create periodo %s: %v", codigo, err)
+		}
+	}
+	otro, err := repos.EnPeriodo(codigo)
+	if err != nil {
+		t.Fatalf("EnPeriodo(%q): %v", codigo, err)
+	}
+	return otro
+}
+
+func probarPeriodos(t *testing.T, nuevos Fabrica) {
+	t.Run("PeriodoActualPorDefecto", func(t *testing.T) {
+		repos := nuevos(t)
+		esperado := domain.PeriodoDeFecha(time.Now())
+		if repos.Periodo() != esperado {
+			t.Fatalf("Periodo = %q, se esperaba el de la fecha actual %q", repos.Periodo(), esperado)
+		}
+		if actual, err := repos.Periodos.Actual(); err != nil || actual != esperado {
+			t.Fatalf("Actual = %q, %v; se esperaba %q", actual, err, esperado)
+		}
+	})
+
+	t.Run("AislaLasInscripciones", func(t *testing.T) {
+		actual := nuevos(t)
+		anterior := otroPeriodo(t, actual, "2020-2")
+		if err := actual.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+			t.Fatalf("Create estudiante: %v", err)
+		}
+		for _, codigo := range []string{"1040", "1050"} {
+			if err := actual.Materias.Create(domain.NewMateria(codigo, "Materia "+codigo)); err != nil {
+				t.Fatalf("Create materia: %v", err)
+			}
+		}
+
+		// Los estudiantes y las materias son comunes; las inscripciones son de cada periodo
+		if existe, _ := anterior.Estudiantes.Exists("1234567"); !existe {
+			t.Fatal("el estudiante no es visible desde otro periodo")
+		}
+		if err := actual.Inscripciones.Create("1234567", "1040"); err != nil {
+			t.Fatalf("Create en el periodo actual: %v", err)
+		}
+		if err := anterior.Inscripciones.Create("1234567", "1040"); err != nil {
+			t.Fatalf("Create de la misma inscripción en 2020-2: %v", err)
+		}
+		if err := anterior.Inscripciones.Create("1234567", "1050"); err != nil {
+			t.Fatalf("Create en 2020-2: %v", err)
+		}
+
+		if existe, _ := actual.Inscripciones.Exists("1234567", "1050"); existe {
+			t.Fatal("el periodo actual ve una inscripción de 2020-2")
+		}
+		if conteos, _ := anterior.Inscripciones.CountGroupedByEstudiante(); conteos["1234567"] != 2 {
+			t.Fatalf("CountGroupedByEstudiante en 2020-2 = %v, se esperaban 2 materias", conteos)
+		}
+		inscripciones, err := actual.Inscripciones.GetAll()
+		if err != nil || len(inscripciones) != 1 || inscripciones[0].Periodo != actual.Periodo() {
+			t.Fatalf("GetAll en el periodo actual = %+v, %v; se esperaba una inscripción del periodo", inscripciones, err)
+		}
+
+		// Cancelar en un periodo no afecta al otro
+		if err := anterior.Inscripciones.Delete("1234567", "1040"); err != nil {
+			t.Fatalf("Delete en 2020-2: %v", err)
+		}
+		if existe, _ := actual.Inscripciones.Exists("1234567", "1040"); !existe {
+			t.Fatal("cancelar en 2020-2 canceló la inscripción del periodo actual")
+		}
+	})
+
+	t.Run("CrearYEstablecerActual", func(t *testing.T) {
+		repos := nuevos(t)
+		if err := repos.Periodos.Create("2026-01"); !errors.Is(err, repository.ErrPeriodoInvalido) {
+			t.Fatalf("Create con código inválido = %v, se esperaba ErrPeriodoInvalido", err)
+		}
+		if err := repos.Periodos.Create("2099-1"); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := repos.Periodos.Create("2099-1"); !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Create duplicado = %v, se esperaba ErrDuplicado", err)
+		}
+		if err := repos.Periodos.EstablecerActual("2099-2"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("EstablecerActual inexistente = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if err := repos.Periodos.EstablecerActual("2099-1"); err != nil {
+			t.Fatalf("EstablecerActual: %v", err)
+		}
+
+		periodos, err := repos.Periodos.GetAll()
+		if err != nil || len(periodos) != 2 {
+			t.Fatalf("GetAll = %+v, %v; se esperaban dos periodos", periodos, err)
+		}
+		if periodos[0].Actual || periodos[1].Codigo != "2099-1" || !periodos[1].Actual {
+			t.Fatalf("GetAll = %+v, %+v; se esperaba 2099-1 como único periodo actual", periodos[0], periodos[1])
+		}
+		if actual, _ := repos.Periodos.Actual(); actual != "2099-1" {
+			t.Fatalf("Actual = %q, se esperaba 2099-1", actual)
+		}
+	})
+
+	t.Run("EnPeriodoInexistente", func(t *testing.T) {
+		repos := nuevos(t)
+		if _, err := repos.EnPeriodo("2099-1"); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("EnPeriodo inexistente = %v, se esperaba ErrNoEncontrado", err)
+		}
+		if _, err := repos.EnPeriodo("primero"); !errors.Is(err, repository.ErrPeriodoInvalido) {
+			t.Fatalf("EnPeriodo inválido = %v, se esperaba ErrPeriodoInvalido", err)
+		}
+	})
+
+	t.Run("UnidadDeTrabajoEnOtroPeriodo", func(t *testing.T) {
+		repos := nuevos(t)
+		if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+			t.Fatalf("Create estudiante: %v", err)
+		}
+		if err := repos.Materias.Create(domain.NewMateria("1040", "Cálculo")); err != nil {
+			t.Fatalf("Create materia: %v", err)
+		}
+		errAbortar := errors.New("abortar")
+		err := repos.EnTransaccion(func(tx *repository.Repositorios) error {
+			if err := tx.Periodos.Create("2020-2"); err != nil {
+				return err
+			}
+			anterior, err := tx.EnPeriodo("2020-2")
+			if err != nil {
+				return err
+			}
+			if err := anterior.Inscripciones.Create("1234567", "1040"); err != nil {
+				return err
+			}
+			return errAbortar
+		})
+		if !errors.Is(err, errAbortar) {
+			t.Fatalf("EnTransaccion = %v, se esperaba el error de la función", err)
+		}
+		if existe, _ := repos.Periodos.Exists("2020-2"); existe {
+			t.Fatal("el periodo creado en una unidad de trabajo abortada quedó guardado")
+		}
+	})
+}
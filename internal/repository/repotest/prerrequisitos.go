@@ -0,0 +1,155 @@
+package repotest
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// cadenaCalculo crea Cálculo, Física I y Física II, cada una prerrequisito de la siguiente
+func cadenaCalculo(t *testing.T, repos *repository.Repositorios) {
+	t.Helper()
+	for _, m := range [][2]string{{"1040", "Cálculo"}, {"1050", "Física I"}, {"1060", "Física II"}} {
+		if err := repos.Materias.Create(domain.NewMateria(m[0], m[1])); err != nil {
+			t.Fatalf("Create materia %s: %v", m[0], err)
+		}
+	}
+	for _, p := range []domain.Prerrequisito{{Materia: "1050", Requisito: "1040"}, {Materia: "1060", Requisito: "1050"}} {
+		if err := repos.Prerrequisitos.Create(p); err != nil {
+			t.Fatalf("Create prerrequisito %+v: %v", p, err)
+		}
+	}
+}
+
+func probarPrerrequisitos(t *testing.T, nuevos Fabrica) {
+	t.Run("CreateYGetGrafo", func(t *testing.T) {
+		repos := nuevos(t)
+		cadenaCalculo(t, repos)
+
+		grafo, err := repos.Prerrequisitos.GetGrafo()
+		if err != nil {
+			t.Fatalf("GetGrafo: %v", err)
+		}
+		esperado := domain.GrafoPrerrequisitos{"1050": {"1040"}, "1060": {"1050"}}
+		if !reflect.DeepEqual(grafo, esperado) {
+			t.Fatalf("GetGrafo = %v, se esperaba %v", grafo, esperado)
+		}
+		if err := repos.Prerrequisitos.Create(domain.Prerrequisito{Materia: "1050", Requisito: "1040"}); !errors.Is(err, repository.ErrDuplicado) {
+			t.Fatalf("Create duplicado = %v, se esperaba ErrDuplicado", err)
+		}
+		if err := repos.Prerrequisitos.Create(domain.Prerrequisito{Materia: "1050", Requisito: "9999"}); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Create con materia inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+	})
+
+	t.Run("RechazaCiclos", func(t *testing.T) {
+		repos := nuevos(t)
+		cadenaCalculo(t, repos)
+
+		for _, p := range []domain.Prerrequisito{
+			{Materia: "1040", Requisito: "1040"},
+			{Materia: "1040", Requisito: "1050"},
+			{Materia: "1040", Requisito: "1060"},
+		} {
+			if err := repos.Prerrequisitos.Create(p); !errors.Is(err, repository.ErrCiclo) {
+				t.Fatalf("Create(%+v) = %v, se esperaba ErrCiclo", p, err)
+			}
+		}
+		// Un atajo hacia adelante no cierra ningún ciclo
+		if err := repos.Prerrequisitos.Create(domain.Prerrequisito{Materia: "1060", Requisito: "1040"}); err != nil {
+			t.Fatalf("Create sin ciclo: %v", err)
+		}
+	})
+
+	t.Run("DeleteYMateriasEliminadas", func(t *testing.T) {
+		repos := nuevos(t)
+		cadenaCalculo(t, repos)
+
+		if err := repos.Prerrequisitos.Delete(domain.Prerrequisito{Materia: "1060", Requisito: "1050"}); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := repos.Prerrequisitos.Delete(domain.Prerrequisito{Materia: "1060", Requisito: "1050"}); !errors.Is(err, repository.ErrNoEncontrado) {
+			t.Fatalf("Delete repetido = %v, se esperaba ErrNoEncontrado", err)
+		}
+
+		// La materia eliminada sale del grafo, pero conserva sus prerrequisitos para cuando se restaure
+		if err := repos.Materias.Delete("1040"); err != nil {
+			t.Fatalf("Delete materia: %v", err)
+		}
+		if grafo, _ := repos.Prerrequisitos.GetGrafo(); len(grafo) != 0 {
+			t.Fatalf("GetGrafo con la materia eliminada = %v, se esperaba vacío", grafo)
+		}
+		if todos, _ := repos.Prerrequisitos.GetAll(); len(todos) != 1 {
+			t.Fatalf("GetAll = %+v, se esperaba el prerrequisito de la materia eliminada", todos)
+		}
+		if err := repos.Prerrequisitos.Create(domain.Prerrequisito{Materia: "1060", Requisito: "1040"}); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Create con materia eliminada = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		if err := repos.Materias.Restore("1040"); err != nil {
+			t.Fatalf("Restore materia: %v", err)
+		}
+		if grafo, _ := repos.Prerrequisitos.GetGrafo(); len(grafo["1050"]) != 1 {
+			t.Fatalf("GetGrafo tras restaurar = %v, se esperaba 1040 como prerrequisito de 1050", grafo)
+		}
+	})
+
+	t.Run("HistorialEntrePeriodos", func(t *testing.T) {
+		repos := nuevos(t)
+		cadenaCalculo(t, repos)
+		if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+			t.Fatalf("Create estudiante: %v", err)
+		}
+		anterior := otroPeriodo(t, repos, "2020-2")
+		if err := anterior.Inscripciones.Create("1234567", "1040"); err != nil {
+			t.Fatalf("Create en 2020-2: %v", err)
+		}
+		if err := repos.Inscripciones.Create("1234567", "1050"); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := repos.Inscripciones.Create("1234567", "1060"); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := repos.Inscripciones.Delete("1234567", "1060"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		historial, err := repos.Inscripciones.HistorialByEstudiante("1234567")
+		if err != nil {
+			t.Fatalf("HistorialByEstudiante: %v", err)
+		}
+		if len(historial) != 2 || historial[0].Periodo != "2020-2" || historial[0].Materia.Codigo != "1040" ||
+			historial[1].Periodo != repos.Periodo() || historial[1].Materia.Codigo != "1050" || historial[1].Estudiante.Nombre != "Lulú López" {
+			t.Fatalf("HistorialByEstudiante = %+v, se esperaban 1040 en 2020-2 y 1050 en el periodo actual", historial)
+		}
+		if historial, _ := anterior.Inscripciones.HistorialByEstudiante("0000000"); len(historial) != 0 {
+			t.Fatalf("HistorialByEstudiante inexistente = %+v, se esperaba vacío", historial)
+		}
+	})
+
+	t.Run("Volcado", func(t *testing.T) {
+		origen := nuevos(t)
+		cadenaCalculo(t, origen)
+
+		var script bytes.Buffer
+		if err := origen.Volcar(&script); err != nil {
+			t.Fatalf("Volcar: %v", err)
+		}
+		destino := nuevos(t)
+		if err := destino.Cargar(strings.NewReader(script.String())); err != nil {
+			t.Fatalf("Cargar: %v", err)
+		}
+		if todos, _ := destino.Prerrequisitos.GetAll(); len(todos) != 2 {
+			t.Fatalf("GetAll tras cargar = %+v, se esperaban dos prerrequisitos", todos)
+		}
+
+		ciclico := script.String() + "INSERT INTO prerrequisitos (materia_codigo, requisito_codigo) VALUES ('1040', '1060');\n"
+		if err := nuevos(t).Cargar(strings.NewReader(ciclico)); !errors.Is(err, repository.ErrVolcadoInvalido) {
+			t.Fatalf("Cargar con ciclo = %v, se esperaba ErrVolcadoInvalido", err)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func probarUnidadDeTrabajo(t *testing.T, nuevos Fabrica) {
+	errAbortar := errors.New("abortar")
+
+	t.Run("Confirma", func(t *testing.T) {
+		repos := nuevos(t)
+		err := repos.EnTransaccion(func(tx *repository.Repositorios) error {
+			if err := tx.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+				return err
+			}
+			if err := tx.Materias.Create(domain.NewMateria("1040", "Cálculo")); err != nil {
+				return err
+			}
+			// Dentro de la unidad de trabajo se ven los cambios propios
+			if existe, err := tx.Estudiantes.Exists("1234567"); err != nil || !existe {
+				t.Errorf("Exists dentro de la transacción = %v, %v; se esperaba true", existe, err)
+			}
+			return tx.Inscripciones.Create("1234567", "1040")
+		})
+		if err != nil {
+			t.Fatalf("EnTransaccion: %v", err)
+		}
+
+		if n, _ := repos.Inscripciones.CountByEstudiante("1234567"); n != 1 {
+			t.Fatalf("CountByEstudiante = %d, se esperaba 1", n)
+		}
+		if historial, _ := repos.Auditoria.HistorialEstudiante("1234567"); len(historial) != 2 {
+			t.Fatalf("HistorialEstudiante = %d registros, se esperaban 2", len(historial))
+		}
+	})
+
+	t.Run("DescartaAnteError", func(t *testing.T) {
+		repos := nuevos(t)
+		err := repos.EnTransaccion(func(tx *repository.Repositorios) error {
+			if err := tx.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+				return err
+			}
+			if err := tx.Materias.Create(domain.NewMateria("1040", "Cálculo")); err != nil {
+				return err
+			}
+			return errAbortar
+		})
+		if !errors.Is(err, errAbortar) {
+			t.Fatalf("EnTransaccion = %v, se esperaba el error de fn", err)
+		}
+
+		if existe, _ := repos.Estudiantes.Exists("1234567"); existe {
+			t.Error("el estudiante quedó guardado pese al error")
+		}
+		if existe, _ := repos.Materias.Exists("1040"); existe {
+			t.Error("la materia quedó guardada pese al error")
+		}
+		if historial, _ := repos.Auditoria.HistorialEstudiante("1234567"); len(historial) != 0 {
+			t.Error("la auditoría registró cambios descartados")
+		}
+	})
+
+	t.Run("DescartaAntePanico", func(t *testing.T) {
+		repos := nuevos(t)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("se esperaba que el pánico se propagara")
+				}
+			}()
+			repos.EnTransaccion(func(tx *repository.Repositorios) error {
+				if err := tx.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+					return err
+				}
+				panic("fallo inesperado")
+			})
+		}()
+
+		if existe, _ := repos.Estudiantes.Exists("1234567"); existe {
+			t.Error("el estudiante quedó guardado pese al pánico")
+		}
+	})
+
+	t.Run("PasoFallidoNoInvalidaLaTransaccion", func(t *testing.T) {
+		repos := nuevos(t)
+		if err := repos.Materias.Create(domain.NewMateria("1040", "Cálculo")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+
+		err := repos.EnTransaccion(func(tx *repository.Repositorios) error {
+			if err := tx.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+				return err
+			}
+			// Un paso que falla se deshace solo; la unidad de trabajo puede continuar
+			if err := tx.Materias.Create(domain.NewMateria("1040", "Otra")); !errors.Is(err, repository.ErrDuplicado) {
+				t.Errorf("Create duplicado = %v, se esperaba ErrDuplicado", err)
+			}
+			if err := tx.Inscripciones.Create("1234567", "9999"); !errors.Is(err, repository.ErrReferenciaInvalida) {
+				t.Errorf("Create inscripción inválida = %v, se esperaba ErrReferenciaInvalida", err)
+			}
+			return tx.Inscripciones.Create("1234567", "1040")
+		})
+		if err != nil {
+			t.Fatalf("EnTransaccion: %v", err)
+		}
+
+		if n, _ := repos.Inscripciones.CountByEstudiante("1234567"); n != 1 {
+			t.Fatalf("CountByEstudiante = %d, se esperaba 1", n)
+		}
+		if m, _ := repos.Materias.GetByCodigo("1040"); m == nil || m.Nombre != "Cálculo" {
+			t.Fatalf("la materia original cambió: %+v", m)
+		}
+	})
+
+	t.Run("Anidada", func(t *testing.T) {
+		repos := nuevos(t)
+		err := repos.EnTransaccion(func(tx *repository.Repositorios) error {
+			err := tx.EnTransaccion(func(interior *repository.Repositorios) error {
+				return interior.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López"))
+			})
+			if err != nil {
+				return err
+			}
+			return errAbortar
+		})
+		if !errors.Is(err, errAbortar) {
+			t.Fatalf("EnTransaccion = %v, se esperaba el error de fn", err)
+		}
+
+		// La unidad interior se confirma con la exterior, que se descartó
+		if existe, _ := repos.Estudiantes.Exists("1234567"); existe {
+			t.Error("la unidad de trabajo interior sobrevivió al descarte de la exterior")
+		}
+	})
+
+	t.Run("ConservaElContextoDeAuditoria", func(t *testing.T) {
+		repos := nuevos(t).ConAuditoria(repository.ContextoAuditoria{Actor: "coordinadora", Origen: domain.OrigenConsola})
+		err := repos.EnTransaccion(func(tx *repository.Repositorios) error {
+			return tx.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López"))
+		})
+		if err != nil {
+			t.Fatalf("EnTransaccion: %v", err)
+		}
+
+		historial, err := repos.Auditoria.HistorialEstudiante("1234567")
+		if err != nil || len(historial) != 1 || historial[0].Actor != "coordinadora" {
+			t.Fatalf("HistorialEstudiante = %+v, %v; se esperaba un alta de coordinadora", historial, err)
+		}
+	})
+}
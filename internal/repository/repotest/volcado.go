@@ -0,0 +1,122 @@
+package repotest
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// sinComentarios descarta las líneas de comentario, que incluyen la fecha del volcado
+func sinComentarios(script string) string {
+	var lineas []string
+	for _, linea := range strings.Split(script, "\n") {
+		if !strings.HasPrefix(linea, "--") {
+			lineas = append(lineas, linea)
+		}
+	}
+	return strings.Join(lineas, "\n")
+}
+
+func probarVolcado(t *testing.T, nuevos Fabrica) {
+	t.Run("IdaYVuelta", func(t *testing.T) {
+		origen := datosListados(t, nuevos)
+		if err := origen.Estudiantes.Create(domain.NewEstudiante("6666666", "Ana O'Brien")); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := origen.Estudiantes.Delete("2222222"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if err := origen.Inscripciones.Delete("1111111", "1050"); err != nil {
+			t.Fatalf("Delete inscripción: %v", err)
+		}
+
+		var script bytes.Buffer
+		if err := origen.Volcar(&script); err != nil {
+			t.Fatalf("Volcar: %v", err)
+		}
+		if !strings.Contains(script.String(), "'Ana O''Brien'") {
+			t.Fatalf("las comillas no se escaparon:\n%s", script.String())
+		}
+
+		destino := nuevos(t)
+		if err := destino.Cargar(strings.NewReader(script.String())); err != nil {
+			t.Fatalf("Cargar: %v", err)
+		}
+
+		var copia bytes.Buffer
+		if err := destino.Volcar(&copia); err != nil {
+			t.Fatalf("Volcar copia: %v", err)
+		}
+		if sinComentarios(copia.String()) != sinComentarios(script.String()) {
+			t.Fatalf("la copia difiere del original:\n%s\n---\n%s", script.String(), copia.String())
+		}
+
+		// Los datos cargados se comportan como los originales: búsqueda y eliminados incluidos
+		if encontrados, _ := destino.Estudiantes.Search("brien", 0); len(encontrados) != 1 {
+			t.Errorf("Search sobre datos cargados = %v, se esperaba Ana O'Brien", cedulas(encontrados))
+		}
+		if existe, _ := destino.Estudiantes.Exists("2222222"); existe {
+			t.Error("el estudiante eliminado quedó activo al cargarse")
+		}
+		if n, _ := destino.Inscripciones.CountByEstudiante("1111111"); n != 1 {
+			t.Errorf("CountByEstudiante = %d, se esperaba 1", n)
+		}
+		historial, _ := destino.Auditoria.HistorialEstudiante("2222222")
+		if len(historial) == 0 {
+			t.Error("la carga no quedó registrada en la auditoría")
+		}
+	})
+
+	t.Run("BaseNoVacia", func(t *testing.T) {
+		repos := datosListados(t, nuevos)
+		var script bytes.Buffer
+		if err := repos.Volcar(&script); err != nil {
+			t.Fatalf("Volcar: %v", err)
+		}
+		if err := repos.Cargar(&script); !errors.Is(err, repository.ErrBaseNoVacia) {
+			t.Fatalf("Cargar sobre datos existentes = %v, se esperaba ErrBaseNoVacia", err)
+		}
+	})
+
+	t.Run("ScriptsInvalidos", func(t *testing.T) {
+		for nombre, script := range map[string]string{
+			"OtraSentencia":   "DROP TABLE estudiantes;",
+			"OtraTabla":       "INSERT INTO auditoria (actor) VALUES ('x');",
+			"OtraColumna":     "INSERT INTO estudiantes (cedula, clave) VALUES ('1', 'x');",
+			"FaltaValor":      "INSERT INTO estudiantes (cedula, nombre) VALUES ('1');",
+			"LiteralAbierto":  "INSERT INTO estudiantes (cedula, nombre) VALUES ('1', 'x);",
+			"SinPuntoYComa":   "INSERT INTO estudiantes (cedula, nombre) VALUES ('1', 'x')",
+			"Expresion":       "INSERT INTO estudiantes (cedula, nombre) VALUES ('1', lower('X'));",
+			"FechaInvalida":   "INSERT INTO estudiantes (cedula, nombre, deleted_at) VALUES ('1', 'x', 'ayer');",
+			"ClaveVacia":      "INSERT INTO materias (codigo, nombre) VALUES ('', 'x');",
+			"DosSentencias":   "INSERT INTO materias (codigo, nombre) VALUES ('1', 'x'); DELETE FROM materias;",
+			"TextoAlFinal":    "INSERT INTO materias (codigo, nombre) VALUES ('1', 'x') RETURNING codigo;",
+			"ValoresDeMas":    "INSERT INTO materias (codigo, nombre) VALUES ('1', 'x', 'y');",
+			"SinListaColumna": "INSERT INTO materias VALUES ('1', 'x');",
+		} {
+			t.Run(nombre, func(t *testing.T) {
+				repos := nuevos(t)
+				if err := repos.Cargar(strings.NewReader(script)); !errors.Is(err, repository.ErrVolcadoInvalido) {
+					t.Fatalf("Cargar(%q) = %v, se esperaba ErrVolcadoInvalido", script, err)
+				}
+			})
+		}
+	})
+
+	t.Run("ReferenciaInexistente", func(t *testing.T) {
+		repos := nuevos(t)
+		script := `INSERT INTO estudiantes (cedula, nombre) VALUES ('1234567', 'Lulú López');
+INSERT INTO inscripciones (estudiante_cedula, materia_codigo) VALUES ('1234567', '9999');`
+		if err := repos.Cargar(strings.NewReader(script)); !errors.Is(err, repository.ErrReferenciaInvalida) {
+			t.Fatalf("Cargar = %v, se esperaba ErrReferenciaInvalida", err)
+		}
+		// La carga es atómica: el estudiante tampoco quedó guardado
+		if existe, _ := repos.Estudiantes.Exists("1234567"); existe {
+			t.Fatal("una carga fallida dejó datos a medias")
+		}
+	})
+}
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RespaldosPorDefecto es la cantidad de respaldos que se conservan si no se indica otra
+const RespaldosPorDefecto = 7
+
+// sufijoAnterior nombra las copias de la base que Restaurar reemplaza: inscripciones.db.antes-de-restaurar-<fecha>
+const sufijoAnterior = ".antes-de-restaurar-"
+
+// formatoFechaRespaldo forma parte del nombre del archivo; ordena igual como texto que como fecha
+const formatoFechaRespaldo = "20060102-150405.000000"
+
+// OpcionesRespaldo indica dónde se guardan los respaldos y cuántos se conservan
+type OpcionesRespaldo struct {
+	Directorio string
+	// Conservar es la cantidad de respaldos más recientes que sobreviven a la rotación
+	Conservar int
+}
+
+// Respaldar crea una copia consistente de la base de datos SQLite mientras la aplicación
+// puede seguir escribiendo en ella, verifica su integridad y rota los respaldos antiguos.
+// La base se respalda tal como está, sin migrarla, para que pueda respaldarse justo antes de
+// una actualización; solo falla con ErrEsquemaDistinto si su esquema es más nuevo que el de
+// esta aplicación. Retorna la ruta del respaldo creado.
+func Respaldar(cfg Config, opciones OpcionesRespaldo) (string, error) {
+	origen, err := rutaSQLite(cfg)
+	if err != nil {
+		return "", err
+	}
+	if opciones.Conservar <= 0 {
+		opciones.Conservar = RespaldosPorDefecto
+	}
+	if opciones.Directorio == "" {
+		opciones.Directorio = filepath.Join(filepath.Dir(origen), "respaldos")
+	}
+	if err := os.MkdirAll(opciones.Directorio, 0o755); err != nil {
+		return "", fmt.Errorf("error al crear directorio de respaldos: %w", err)
+	}
+
+	db, err := abrirSinMigrar(cfg, origen)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+	version, err := versionAplicada(db)
+	if err != nil {
+		return "", err
+	}
+	if version > VersionEsquema() {
+		return "", fmt.Errorf("%w: la base tiene el esquema %d y esta aplicación solo conoce hasta el %d",
+			ErrEsquemaDistinto, version, VersionEsquema())
+	}
+
+	prefijo := prefijoRespaldo(origen)
+	destino := filepath.Join(opciones.Directorio, prefijo+time.Now().Format(formatoFechaRespaldo)+".db")
+
+	// VACUUM INTO lee dentro de una transacción, así que la copia refleja un único instante
+	if _, err := db.Exec("VACUUM INTO ?", destino); err != nil {
+		return "", fmt.Errorf("error al copiar la base de datos: %w", err)
+	}
+
+	if _, err := verificarRespaldo(destino); err != nil {
+		os.Remove(destino)
+		return "", err
+	}
+
+	if err := rotarRespaldos(opciones.Directorio, prefijo, ".db", opciones.Conservar); err != nil {
+		return destino, fmt.Errorf("respaldo creado, pero falló la rotación: %w", err)
+	}
+	return destino, nil
+}
+
+// Restaurar reemplaza la base de datos configurada por el respaldo indicado, después de
+// verificar su integridad y que su esquema no sea más nuevo que el de esta aplicación.
+// La base actual se copia antes de reemplazarse y de esas copias se conservan las
+// RespaldosPorDefecto más recientes. No debe haber otro proceso usándola.
+func Restaurar(cfg Config, respaldo string) error {
+	destino, err := rutaSQLite(cfg)
+	if err != nil {
+		return err
+	}
+
+	version, err := verificarRespaldo(respaldo)
+	if err != nil {
+		return err
+	}
+	if version > VersionEsquema() {
+		return fmt.Errorf("%w: el respaldo tiene el esquema %d y esta aplicación solo conoce hasta el %d",
+			ErrRespaldoInvalido, version, VersionEsquema())
+	}
+
+	// Se conserva la base actual junto a ella por si la restauración no era la deseada
+	if _, err := os.Stat(destino); err == nil {
+		if err := conservarBaseActual(cfg, destino); err != nil {
+			return fmt.Errorf("error al conservar la base actual: %w", err)
+		}
+	}
+
+	// Copiar a un temporal en el mismo directorio y renombrar deja el reemplazo atómico
+	temporal := destino + ".restaurando"
+	if err := copiarArchivo(respaldo, temporal); err != nil {
+		os.Remove(temporal)
+		return fmt.Errorf("error al copiar el respaldo: %w", err)
+	}
+	for _, sufijo := range []string{"-wal", "-shm", "-journal"} {
+		os.Remove(destino + sufijo)
+	}
+	if err := os.Rename(temporal, destino); err != nil {
+		os.Remove(temporal)
+		return fmt.Errorf("error al reemplazar la base de datos: %w", err)
+	}
+
+	// Un respaldo de una versión anterior queda al día con las migraciones pendientes
+	db, _, err := Conectar(cfg)
+	if err != nil {
+		return fmt.Errorf("la base restaurada no pudo abrirse: %w", err)
+	}
+	return db.Close()
+}
+
+// ListarRespaldos retorna las rutas de los respaldos de la base configurada, del más reciente al más antiguo
+func ListarRespaldos(cfg Config, directorio string) ([]string, error) {
+	origen, err := rutaSQLite(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if directorio == "" {
+		directorio = filepath.Join(filepath.Dir(origen), "respaldos")
+	}
+	respaldos, err := buscarRespaldos(directorio, prefijoRespaldo(origen), ".db")
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(respaldos)))
+	return respaldos, nil
+}
+
+// conservarBaseActual copia la base que Restaurar va a reemplazar junto a ella y rota las
+// copias anteriores. Como en Respaldar, VACUUM INTO incluye lo que todavía está en el -wal.
+func conservarBaseActual(cfg Config, ruta string) error {
+	db, err := abrirSinMigrar(cfg, ruta)
+	if err != nil {
+		return err
+	}
+	anterior := ruta + sufijoAnterior + time.Now().Format(formatoFechaRespaldo)
+	if _, err := db.Exec("VACUUM INTO ?", anterior); err != nil {
+		db.Close()
+		return err
+	}
+	if err := db.Close(); err != nil {
+		return err
+	}
+	return rotarRespaldos(filepath.Dir(ruta), filepath.Base(ruta)+sufijoAnterior, "", RespaldosPorDefecto)
+}
+
+// abrirSinMigrar abre la base SQLite configurada sin aplicar migraciones y sin crearla si
+// el archivo no existe
+func abrirSinMigrar(cfg Config, ruta string) (*sql.DB, error) {
+	if _, err := os.Stat(ruta); err != nil {
+		return nil, fmt.Errorf("error al abrir la base de datos: %w", err)
+	}
+	db, err := sql.Open(DriverSQLite, dsnSQLite(cfg.DSN))
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// verificarRespaldo comprueba que el archivo sea una base SQLite íntegra con el esquema
+// de la aplicación y retorna su versión de esquema
+func verificarRespaldo(ruta string) (int, error) {
+	if _, err := os.Stat(ruta); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrRespaldoInvalido, err)
+	}
+
+	db, err := sql.Open(DriverSQLite, ruta)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var resultado string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&resultado); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrRespaldoInvalido, err)
+	}
+	if resultado != "ok" {
+		return 0, fmt.Errorf("%w: la verificación de integridad reportó %q", ErrRespaldoInvalido, resultado)
+	}
+
+	version, err := versionAplicada(db)
+	if err != nil || version == 0 {
+		return 0, fmt.Errorf("%w: no tiene el esquema de inscripciones", ErrRespaldoInvalido)
+	}
+	return version, nil
+}
+
+// rotarRespaldos elimina los respaldos más antiguos hasta dejar solo los indicados
+func rotarRespaldos(directorio, prefijo, sufijo string, conservar int) error {
+	respaldos, err := buscarRespaldos(directorio, prefijo, sufijo)
+	if err != nil {
+		return err
+	}
+	sort.Strings(respaldos)
+	for len(respaldos) > conservar {
+		if err := os.Remove(respaldos[0]); err != nil {
+			return err
+		}
+		respaldos = respaldos[1:]
+	}
+	return nil
+}
+
+// buscarRespaldos retorna los archivos del directorio cuyo nombre empieza por el prefijo y
+// termina en el sufijo
+func buscarRespaldos(directorio, prefijo, sufijo string) ([]string, error) {
+	entradas, err := os.ReadDir(directorio)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var respaldos []string
+	for _, entrada := range entradas {
+		nombre := entrada.Name()
+		if !entrada.IsDir() && strings.HasPrefix(nombre, prefijo) && strings.HasSuffix(nombre, sufijo) {
+			respaldos = append(respaldos, filepath.Join(directorio, nombre))
+		}
+	}
+	return respaldos, nil
+}
+
+// prefijoRespaldo nombra los respaldos a partir del archivo original: inscripciones.db → inscripciones-
+func prefijoRespaldo(origen string) string {
+	base := filepath.Base(origen)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "-"
+}
+
+// rutaSQLite extrae la ruta del archivo de la cadena de conexión de SQLite
+func rutaSQLite(cfg Config) (string, error) {
+	if cfg.Driver != DriverSQLite {
+		return "", fmt.Errorf("los respaldos solo están disponibles para SQLite (driver actual: %q)", cfg.Driver)
+	}
+	ruta := strings.TrimPrefix(cfg.DSN, "file:")
+	if i := strings.Index(ruta, "?"); i >= 0 {
+		ruta = ruta[:i]
+	}
+	if ruta == "" || ruta == ":memory:" {
+		return "", fmt.Errorf("la cadena de conexión %q no corresponde a un archivo", cfg.DSN)
+	}
+	return ruta, nil
+}
+
+func copiarArchivo(origen, destino string) error {
+	entrada, err := os.Open(origen)
+	if err != nil {
+		return err
+	}
+	defer entrada.Close()
+
+	salida, err := os.Create(destino)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(salida, entrada); err != nil {
+		salida.Close()
+		return err
+	}
+	if err := salida.Sync(); err != nil {
+		salida.Close()
+		return err
+	}
+	return salida.Close()
+}
@@ -0,0 +1,246 @@
+package repository_test
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// configConDatos crea una base SQLite en un directorio temporal con un estudiante
+func configConDatos(t *testing.T) repository.Config {
+	t.Helper()
+	cfg := repository.Config{Driver: repository.DriverSQLite, DSN: filepath.Join(t.TempDir(), "inscripciones.db")}
+
+	repos, err := repository.Abrir(cfg)
+	if err != nil {
+		t.Fatalf("Abrir: %v", err)
+	}
+	defer repos.Close()
+	if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return cfg
+}
+
+func TestRespaldarYRotar(t *testing.T) {
+	cfg := configConDatos(t)
+	opciones := repository.OpcionesRespaldo{Directorio: filepath.Join(t.TempDir(), "respaldos"), Conservar: 2}
+
+	var creados []string
+	for i := 0; i < 3; i++ {
+		ruta, err := repository.Respaldar(cfg, opciones)
+		if err != nil {
+			t.Fatalf("Respaldar: %v", err)
+		}
+		creados = append(creados, ruta)
+	}
+
+	respaldos, err := repository.ListarRespaldos(cfg, opciones.Directorio)
+	if err != nil {
+		t.Fatalf("ListarRespaldos: %v", err)
+	}
+	verificarOrdenRutas(t, respaldos, []string{creados[2], creados[1]})
+	if _, err := os.Stat(creados[0]); !os.IsNotExist(err) {
+		t.Errorf("el respaldo más antiguo no se eliminó: %v", err)
+	}
+
+	// El respaldo es una base independiente con los datos del momento
+	repos, err := repository.Abrir(repository.Config{Driver: repository.DriverSQLite, DSN: creados[2]})
+	if err != nil {
+		t.Fatalf("Abrir respaldo: %v", err)
+	}
+	defer repos.Close()
+	if e, _ := repos.Estudiantes.GetByCedula("1234567"); e == nil {
+		t.Fatal("el respaldo no contiene al estudiante")
+	}
+}
+
+func TestRestaurar(t *testing.T) {
+	cfg := configConDatos(t)
+	respaldo, err := repository.Respaldar(cfg, repository.OpcionesRespaldo{Directorio: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Respaldar: %v", err)
+	}
+
+	repos, err := repository.Abrir(cfg)
+	if err != nil {
+		t.Fatalf("Abrir: %v", err)
+	}
+	if err := repos.Estudiantes.Create(domain.NewEstudiante("7654321", "Posterior al respaldo")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	repos.Close()
+
+	if err := repository.Restaurar(cfg, respaldo); err != nil {
+		t.Fatalf("Restaurar: %v", err)
+	}
+
+	repos, err = repository.Abrir(cfg)
+	if err != nil {
+		t.Fatalf("Abrir restaurada: %v", err)
+	}
+	defer repos.Close()
+	if existe, _ := repos.Estudiantes.Exists("7654321"); existe {
+		t.Error("la base restaurada contiene datos posteriores al respaldo")
+	}
+	if existe, _ := repos.Estudiantes.Exists("1234567"); !existe {
+		t.Error("la base restaurada perdió datos del respaldo")
+	}
+
+	// La base reemplazada se conserva junto a la original, con los datos posteriores al respaldo
+	anteriores, _ := filepath.Glob(cfg.DSN + ".antes-de-restaurar-*")
+	if len(anteriores) != 1 {
+		t.Fatalf("se esperaba una copia de la base reemplazada, hay %d", len(anteriores))
+	}
+	copia, err := repository.Abrir(repository.Config{Driver: repository.DriverSQLite, DSN: anteriores[0]})
+	if err != nil {
+		t.Fatalf("Abrir copia: %v", err)
+	}
+	defer copia.Close()
+	if existe, _ := copia.Estudiantes.Exists("7654321"); !existe {
+		t.Error("la copia de la base reemplazada no tiene los datos posteriores al respaldo")
+	}
+}
+
+func TestRestaurarRotaLasCopias(t *testing.T) {
+	cfg := configConDatos(t)
+	respaldo, err := repository.Respaldar(cfg, repository.OpcionesRespaldo{Directorio: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Respaldar: %v", err)
+	}
+	for i := 0; i <= repository.RespaldosPorDefecto; i++ {
+		if err := repository.Restaurar(cfg, respaldo); err != nil {
+			t.Fatalf("Restaurar: %v", err)
+		}
+	}
+	anteriores, _ := filepath.Glob(cfg.DSN + ".antes-de-restaurar-*")
+	if len(anteriores) != repository.RespaldosPorDefecto {
+		t.Errorf("quedaron %d copias de la base reemplazada, se esperaban %d", len(anteriores), repository.RespaldosPorDefecto)
+	}
+}
+
+func TestRestaurarRechazaRespaldosInvalidos(t *testing.T) {
+	cfg := configConDatos(t)
+	dir := t.TempDir()
+
+	corrupto := filepath.Join(dir, "corrupto.db")
+	if err := os.WriteFile(corrupto, []byte("esto no es una base de datos"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.Restaurar(cfg, corrupto); !errors.Is(err, repository.ErrRespaldoInvalido) {
+		t.Errorf("Restaurar archivo corrupto = %v, se esperaba ErrRespaldoInvalido", err)
+	}
+
+	// Un respaldo hecho por una versión más nueva de la aplicación no se puede restaurar
+	futuro, err := repository.Respaldar(cfg, repository.OpcionesRespaldo{Directorio: dir})
+	if err != nil {
+		t.Fatalf("Respaldar: %v", err)
+	}
+	db, err := sql.Open(repository.DriverSQLite, futuro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO schema_migrations (version, descripcion) VALUES (?, 'futura')", repository.VersionEsquema()+1); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if err := repository.Restaurar(cfg, futuro); !errors.Is(err, repository.ErrRespaldoInvalido) {
+		t.Errorf("Restaurar esquema más nuevo = %v, se esperaba ErrRespaldoInvalido", err)
+	}
+
+	// Ningún intento fallido tocó la base actual
+	repos, err := repository.Abrir(cfg)
+	if err != nil {
+		t.Fatalf("Abrir: %v", err)
+	}
+	defer repos.Close()
+	if existe, _ := repos.Estudiantes.Exists("1234567"); !existe {
+		t.Error("un intento de restauración fallido modificó la base")
+	}
+}
+
+func TestRespaldarNoMigra(t *testing.T) {
+	cfg := configConDatos(t)
+	opciones := repository.OpcionesRespaldo{Directorio: t.TempDir()}
+	db, err := sql.Open(repository.DriverSQLite, cfg.DSN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	versionActual := func() int {
+		var version int
+		if err := db.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+			t.Fatal(err)
+		}
+		return version
+	}
+
+	// Una base con migraciones pendientes se respalda sin actualizarla
+	if _, err := db.Exec("DELETE FROM schema_migrations WHERE version = ?", repository.VersionEsquema()); err != nil {
+		t.Fatal(err)
+	}
+	respaldo, err := repository.Respaldar(cfg, opciones)
+	if err != nil {
+		t.Fatalf("Respaldar esquema anterior: %v", err)
+	}
+	if version := versionActual(); version != repository.VersionEsquema()-1 {
+		t.Errorf("Respaldar dejó la base en el esquema %d, se esperaba %d", version, repository.VersionEsquema()-1)
+	}
+	copia, err := sql.Open(repository.DriverSQLite, respaldo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer copia.Close()
+	var versionCopia int
+	if err := copia.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&versionCopia); err != nil {
+		t.Fatal(err)
+	}
+	if versionCopia != repository.VersionEsquema()-1 {
+		t.Errorf("el respaldo tiene el esquema %d, se esperaba %d", versionCopia, repository.VersionEsquema()-1)
+	}
+
+	// Ni se respalda una base de una versión más nueva de la aplicación
+	if _, err := db.Exec("INSERT INTO schema_migrations (version, descripcion) VALUES (?, 'futura'), (?, 'futura')",
+		repository.VersionEsquema(), repository.VersionEsquema()+1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.Respaldar(cfg, opciones); !errors.Is(err, repository.ErrEsquemaDistinto) {
+		t.Errorf("Respaldar esquema más nuevo = %v, se esperaba ErrEsquemaDistinto", err)
+	}
+	if respaldos, _ := repository.ListarRespaldos(cfg, opciones.Directorio); len(respaldos) != 1 {
+		t.Errorf("respaldos = %v, se esperaba solo el del esquema anterior", respaldos)
+	}
+
+	// Una base que no existe no se crea
+	inexistente := repository.Config{Driver: repository.DriverSQLite, DSN: filepath.Join(t.TempDir(), "no-existe.db")}
+	if _, err := repository.Respaldar(inexistente, opciones); err == nil {
+		t.Error("se esperaba error al respaldar una base que no existe")
+	}
+	if _, err := os.Stat(inexistente.DSN); !os.IsNotExist(err) {
+		t.Errorf("Respaldar creó la base inexistente: %v", err)
+	}
+}
+
+func TestRespaldarSoloSQLite(t *testing.T) {
+	_, err := repository.Respaldar(repository.Config{Driver: repository.DriverPostgres, DSN: "postgres://localhost/x"}, repository.OpcionesRespaldo{})
+	if err == nil {
+		t.Fatal("se esperaba error al respaldar un backend distinto de SQLite")
+	}
+}
+
+func verificarOrdenRutas(t *testing.T, obtenido, esperado []string) {
+	t.Helper()
+	if len(obtenido) != len(esperado) {
+		t.Fatalf("se obtuvieron %v, se esperaban %v", obtenido, esperado)
+	}
+	for i := range esperado {
+		if obtenido[i] != esperado[i] {
+			t.Fatalf("se obtuvieron %v, se esperaban %v", obtenido, esperado)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package repository_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+	"inscripciones/internal/repository/repotest"
+)
+
+func nuevosSQLite(t *testing.T) *repository.Repositorios {
+	t.Helper()
+
+	repos, err := repository.Abrir(repository.Config{
+		Driver:        repository.DriverSQLite,
+		DSN:           filepath.Join(t.TempDir(), "inscripciones.db"),
+		Administrador: true,
+	})
+	if err != nil {
+		t.Fatalf("no se pudo abrir SQLite: %v", err)
+	}
+	t.Cleanup(func() { repos.Close() })
+	return repos
+}
+
+func TestContratoSQLite(t *testing.T) {
+	repotest.ProbarContrato(t, nuevosSQLite)
+}
+
+func TestContratoSQLiteConCache(t *testing.T) {
+	repotest.ProbarContrato(t, func(t *testing.T) *repository.Repositorios {
+		return nuevosSQLite(t).ConCache(repository.CachePorDefecto)
+	})
+}
+
+// El contrato también se cumple con los datos personales cifrados, que SQL ya no puede ordenar ni filtrar
+func TestContratoSQLiteCifrado(t *testing.T) {
+	repotest.ProbarContrato(t, func(t *testing.T) *repository.Repositorios {
+		repos, err := repository.Abrir(repository.Config{
+			Driver:        repository.DriverSQLite,
+			DSN:           filepath.Join(t.TempDir(), "inscripciones.db"),
+			Administrador: true,
+			Clave:         repository.GenerarClave(),
+		})
+		if err != nil {
+			t.Fatalf("no se pudo abrir SQLite cifrado: %v", err)
+		}
+		t.Cleanup(func() { repos.Close() })
+		return repos
+	})
+}
+
+func TestAuditoriaSoloInsercionSQLite(t *testing.T) {
+	db, dialecto, err := repository.Conectar(repository.Config{
+		Driver: repository.DriverSQLite,
+		DSN:    filepath.Join(t.TempDir(), "inscripciones.db"),
+	})
+	if err != nil {
+		t.Fatalf("no se pudo abrir SQLite: %v", err)
+	}
+	defer db.Close()
+
+	repos := repository.NewRepositorios(db, dialecto)
+	if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := db.Exec("UPDATE auditoria SET actor = 'otro'"); err == nil {
+		t.Error("se pudo modificar la auditoría")
+	}
+	if _, err := db.Exec("DELETE FROM auditoria"); err == nil {
+		t.Error("se pudo eliminar la auditoría")
+	}
+}
+
+func TestVolcadoEntreBackends(t *testing.T) {
+	memoria := repository.NewRepositoriosEnMemoria()
+	if err := memoria.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+		t.Fatalf("Create estudiante: %v", err)
+	}
+	if err := memoria.Materias.Create(domain.NewMateria("1040", "Cálculo")); err != nil {
+		t.Fatalf("Create materia: %v", err)
+	}
+	if err := memoria.Inscripciones.Create("1234567", "1040"); err != nil {
+		t.Fatalf("Create inscripción: %v", err)
+	}
+
+	var script bytes.Buffer
+	if err := memoria.Volcar(&script); err != nil {
+		t.Fatalf("Volcar: %v", err)
+	}
+
+	sqlite := nuevosSQLite(t)
+	if err := sqlite.Cargar(&script); err != nil {
+		t.Fatalf("Cargar: %v", err)
+	}
+	materias, err := sqlite.Inscripciones.GetByEstudiante("1234567")
+	if err != nil || len(materias) != 1 || materias[0].Nombre != "Cálculo" {
+		t.Fatalf("GetByEstudiante tras la carga = %v, %v", materias, err)
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ejecutor es lo que los repositorios SQL necesitan para consultar: lo cumplen tanto
+// la conexión (*sql.DB) como una transacción en curso (*sql.Tx)
+type ejecutor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// UnidadDeTrabajo ejecuta varias operaciones de los repositorios de forma atómica
+type UnidadDeTrabajo interface {
+	// EnTransaccion llama a fn con repositorios cuyos cambios se confirman juntos si fn
+	// termina sin error, y se descartan todos si retorna un error o entra en pánico
+	EnTransaccion(fn func(repos *Repositorios) error) error
+}
+
+// EnTransaccion implementa UnidadDeTrabajo. Si los repositorios ya pertenecen a una
+// unidad de trabajo, fn se suma a ella en lugar de abrir otra.
+func (r *Repositorios) EnTransaccion(fn func(repos *Repositorios) error) error {
+	return r.enTransaccion(fn)
+}
+
+// enTransaccionSQL abre una transacción y crea sobre ella repositorios con el mismo contexto
+// de auditoría, la misma facultad y la misma clave de cifrado
+func enTransaccionSQL(e ejecutor, dialecto Dialecto, auditoria ContextoAuditoria, acceso Acceso, cifrador *Cifrador, fn func(*Repositorios) error) error {
+	db, ok := e.(*sql.DB)
+	if !ok {
+		// Ya dentro de una transacción: los cambios se confirman con la unidad de trabajo exterior
+		return fn(newRepositoriosSQL(e, dialecto, auditoria, acceso, cifrador))
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("error al iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(newRepositoriosSQL(tx, dialecto, auditoria, acceso, cifrador)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// transaccion ejecuta los pasos de una operación de un repositorio de forma atómica. Sobre
+// la conexión abre una transacción propia; dentro de una unidad de trabajo usa un punto de
+// guardado, para que un paso fallido se deshaga sin invalidar la transacción exterior
+// (PostgreSQL rechaza cualquier sentencia posterior a un error hasta volver a un punto de guardado).
+func transaccion(e ejecutor, fn func(tx ejecutor) error) error {
+	db, ok := e.(*sql.DB)
+	if !ok {
+		if _, err := e.Exec("SAVEPOINT paso"); err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			if _, errRollback := e.Exec("ROLLBACK TO SAVEPOINT paso"); errRollback != nil {
+				return fmt.Errorf("%w (además falló la reversión: %v)", err, errRollback)
+			}
+			e.Exec("RELEASE SAVEPOINT paso")
+			return err
+		}
+		_, err := e.Exec("RELEASE SAVEPOINT paso")
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
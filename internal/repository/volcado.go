@@ -0,0 +1,1141 @@
+package repository
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"inscripciones/internal/domain"
+)
+
+// ErrVolcadoInvalido indica que el script no respeta el formato que produce Volcar
+var ErrVolcadoInvalido = errors.New("script de volcado inválido")
+
+// ErrBaseNoVacia impide cargar un volcado sobre datos existentes
+var ErrBaseNoVacia = errors.New("la base de datos de destino no está vacía")
+
+// volcado contiene todos los datos, incluidos los eliminados lógicamente
+type volcado struct {
+	periodos      []*domain.Periodo
+	estudiantes   []*domain.Estudiante
+	materias      []*domain.Materia
+	inscripciones []filaInscripcion
+	// prerrequisitos se cargan después de las materias a las que apuntan
+	prerrequisitos []domain.Prerrequisito
+	// esperas se cargan con su orden de llegada dentro de cada materia y periodo
+	esperas []filaEspera
+	// grupos se cargan antes que las inscripciones que están en ellos
+	grupos []domain.Grupo
+	// sesiones se cargan después de los grupos a los que pertenecen
+	sesiones []domain.Sesion
+	// componentes se cargan después de las materias y antes que las notas que tienen
+	componentes []domain.Componente
+	// calificaciones se cargan después de las inscripciones a las que pertenecen
+	calificaciones []domain.Calificacion
+	// carreras se cargan antes que sus pensums y que los estudiantes asignados a ellas
+	carreras           []*domain.Carrera
+	pensum             []domain.MateriaPensum
+	carreraEstudiantes []domain.AsignacionCarrera
+	// docentes se cargan antes que las materias y grupos que dictan en cada periodo
+	docentes             []*domain.Docente
+	asignacionesDocentes []domain.AsignacionDocente
+}
+
+type filaInscripcion struct {
+	cedula      string
+	codigo      string
+	periodo     string
+	grupo       int
+	eliminadaEn *time.Time
+}
+
+type filaEspera struct {
+	cedula  string
+	codigo  string
+	periodo string
+	grupo   int
+	orden   int
+}
+
+// columnasVolcado son las columnas que se exportan de cada tabla, en orden. Los volcados de
+// versiones anteriores del esquema pueden no traer las tablas y columnas agregadas después
+// (periodos, créditos, prerrequisitos, cupos, listas de espera, grupos, horarios,
+// componentes, calificaciones, carreras y docentes); al cargarlos se usan los valores por defecto.
+var columnasVolcado = map[string][]string{
+	"periodos":       {"codigo", "actual"},
+	"estudiantes":    {"cedula", "nombre", "deleted_at"},
+	"materias":       {"codigo", "nombre", "creditos", "cupo", "deleted_at"},
+	"prerrequisitos": {"materia_codigo", "requisito_codigo"},
+	"grupos":         {"materia_codigo", "numero"},
+	"sesiones":       {"materia_codigo", "grupo", "dia", "inicio", "fin", "salon"},
+	"inscripciones":  {"estudiante_cedula", "materia_codigo", "periodo", "grupo", "deleted_at"},
+	"lista_espera":   {"estudiante_cedula", "materia_codigo", "periodo", "grupo", "orden"},
+	"componentes":    {"materia_codigo", "nombre", "peso"},
+	// Las notas se vuelcan en centésimas, como se guardan
+	"calificaciones": {"estudiante_cedula", "materia_codigo", "periodo", "componente", "nota"},
+	"carreras":       {"codigo", "nombre", "creditos"},
+	// Las materias obligatorias se vuelcan con 1 y las electivas con 0, como se guardan
+	"pensum":              {"carrera_codigo", "materia_codigo", "semestre", "obligatoria"},
+	"carrera_estudiantes": {"estudiante_cedula", "carrera_codigo"},
+	"docentes":            {"cedula", "nombre"},
+	// El grupo 0 es la materia completa
+	"asignaciones_docentes": {"docente_cedula", "materia_codigo", "periodo", "grupo"},
+}
+
+// periodosUsados retorna, ordenados, los periodos del volcado y los de sus inscripciones
+func (v *volcado) periodosUsados() []string {
+	usados := make(map[string]bool)
+	for _, p := range v.periodos {
+		usados[p.Codigo] = true
+	}
+	for _, i := range v.inscripciones {
+		usados[i.periodo] = true
+	}
+	for _, e := range v.esperas {
+		usados[e.periodo] = true
+	}
+	for _, c := range v.calificaciones {
+		usados[c.Periodo] = true
+	}
+	for _, a := range v.asignacionesDocentes {
+		usados[a.Periodo] = true
+	}
+	periodos := make([]string, 0, len(usados))
+	for periodo := range usados {
+		periodos = append(periodos, periodo)
+	}
+	sort.Strings(periodos)
+	return periodos
+}
+
+// periodoActual retorna el periodo que el volcado marca como actual; vacío si no marca ninguno
+func (v *volcado) periodoActual() string {
+	for _, p := range v.periodos {
+		if p.Actual {
+			return p.Codigo
+		}
+	}
+	return ""
+}
+
+// Volcar escribe todos los datos como un script SQL portable: una sentencia INSERT
+// por fila, con literales estándar, que puede cargarse con Cargar en cualquier backend
+// o directamente con sqlite3 o psql sobre una base con el esquema ya creado
+func (r *Repositorios) Volcar(w io.Writer) error {
+	salida := bufio.NewWriter(w)
+	fmt.Fprintln(salida, "-- Volcado de datos de inscripciones")
+	fmt.Fprintf(salida, "-- esquema: %d\n", VersionEsquema())
+	fmt.Fprintf(salida, "-- generado: %s\n", time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintln(salida, "BEGIN;")
+
+	todo := Consulta{Limite: LimiteMaximo, Eliminados: IncluirEliminados}
+
+	periodos, err := r.Periodos.GetAll()
+	if err != nil {
+		return fmt.Errorf("error al volcar periodos: %w", err)
+	}
+	for _, p := range periodos {
+		actual := "0"
+		if p.Actual {
+			actual = "1"
+		}
+		fmt.Fprintf(salida, "INSERT INTO periodos (%s) VALUES (%s, %s);\n",
+			strings.Join(columnasVolcado["periodos"], ", "), literalSQL(p.Codigo), literalSQL(actual))
+	}
+
+	err = recorrerPaginas(r.Estudiantes.List, todo, func(e *domain.Estudiante) {
+		escribirInsert(salida, "estudiantes", e.EliminadoEn, e.Cedula, e.Nombre)
+	})
+	if err != nil {
+		return fmt.Errorf("error al volcar estudiantes: %w", err)
+	}
+
+	err = recorrerPaginas(r.Materias.List, todo, func(m *domain.Materia) {
+		escribirInsert(salida, "materias", m.EliminadoEn, m.Codigo, m.Nombre, strconv.Itoa(m.Creditos), strconv.Itoa(m.Cupo))
+	})
+	if err != nil {
+		return fmt.Errorf("error al volcar materias: %w", err)
+	}
+
+	prerrequisitos, err := r.Prerrequisitos.GetAll()
+	if err != nil {
+		return fmt.Errorf("error al volcar prerrequisitos: %w", err)
+	}
+	for _, p := range prerrequisitos {
+		fmt.Fprintf(salida, "INSERT INTO prerrequisitos (%s) VALUES (%s, %s);\n",
+			strings.Join(columnasVolcado["prerrequisitos"], ", "), literalSQL(p.Materia), literalSQL(p.Requisito))
+	}
+
+	grupos, err := r.Grupos.GetAll()
+	if err != nil {
+		return fmt.Errorf("error al volcar grupos: %w", err)
+	}
+	for _, g := range grupos {
+		fmt.Fprintf(salida, "INSERT INTO grupos (%s) VALUES (%s, %s);\n",
+			strings.Join(columnasVolcado["grupos"], ", "), literalSQL(g.Materia), literalSQL(strconv.Itoa(g.Numero)))
+	}
+
+	sesiones, err := r.Horarios.GetAll()
+	if err != nil {
+		return fmt.Errorf("error al volcar horarios: %w", err)
+	}
+	for _, s := range sesiones {
+		fmt.Fprintf(salida, "INSERT INTO sesiones (%s) VALUES (%s, %s, %s, %s, %s, %s);\n",
+			strings.Join(columnasVolcado["sesiones"], ", "), literalSQL(s.Materia), literalSQL(strconv.Itoa(s.Grupo)),
+			literalSQL(strconv.Itoa(int(s.Dia))), literalSQL(strconv.Itoa(s.Inicio)), literalSQL(strconv.Itoa(s.Fin)), literalSQL(s.Salon))
+	}
+
+	componentes, err := r.Componentes.GetAll()
+	if err != nil {
+		return fmt.Errorf("error al volcar componentes: %w", err)
+	}
+	for _, c := range componentes {
+		fmt.Fprintf(salida, "INSERT INTO componentes (%s) VALUES (%s, %s, %s);\n",
+			strings.Join(columnasVolcado["componentes"], ", "), literalSQL(c.Materia), literalSQL(c.Nombre), literalSQL(strconv.Itoa(c.Peso)))
+	}
+
+	carreras, err := r.Carreras.GetAll()
+	if err != nil {
+		return fmt.Errorf("error al volcar carreras: %w", err)
+	}
+	for _, c := range carreras {
+		fmt.Fprintf(salida, "INSERT INTO carreras (%s) VALUES (%s, %s, %s);\n",
+			strings.Join(columnasVolcado["carreras"], ", "), literalSQL(c.Codigo), literalSQL(c.Nombre), literalSQL(strconv.Itoa(c.Creditos)))
+	}
+	pensum, err := r.Carreras.GetAllPensum()
+	if err != nil {
+		return fmt.Errorf("error al volcar pensums: %w", err)
+	}
+	for _, m := range pensum {
+		fmt.Fprintf(salida, "INSERT INTO pensum (%s) VALUES (%s, %s, %s, %s);\n",
+			strings.Join(columnasVolcado["pensum"], ", "), literalSQL(m.Carrera), literalSQL(m.Materia),
+			literalSQL(strconv.Itoa(m.Semestre)), literalSQL(strconv.Itoa(enteroBooleano(m.Obligatoria))))
+	}
+	asignaciones, err := r.Carreras.GetAsignaciones()
+	if err != nil {
+		return fmt.Errorf("error al volcar carreras de estudiantes: %w", err)
+	}
+	for _, a := range asignaciones {
+		fmt.Fprintf(salida, "INSERT INTO carrera_estudiantes (%s) VALUES (%s, %s);\n",
+			strings.Join(columnasVolcado["carrera_estudiantes"], ", "), literalSQL(a.Cedula), literalSQL(a.Carrera))
+	}
+
+	docentes, err := r.Docentes.GetAll()
+	if err != nil {
+		return fmt.Errorf("error al volcar docentes: %w", err)
+	}
+	for _, d := range docentes {
+		fmt.Fprintf(salida, "INSERT INTO docentes (%s) VALUES (%s, %s);\n",
+			strings.Join(columnasVolcado["docentes"], ", "), literalSQL(d.Cedula), literalSQL(d.Nombre))
+	}
+
+	for _, p := range periodos {
+		enPeriodo, err := r.EnPeriodo(p.Codigo)
+		if err != nil {
+			return fmt.Errorf("error al volcar inscripciones de %s: %w", p.Codigo, err)
+		}
+		err = recorrerPaginas(enPeriodo.Inscripciones.List, todo, func(i *domain.Inscripcion) {
+			escribirInsert(salida, "inscripciones", i.EliminadaEn, i.Estudiante.Cedula, i.Materia.Codigo, i.Periodo, strconv.Itoa(i.Grupo))
+		})
+		if err != nil {
+			return fmt.Errorf("error al volcar inscripciones de %s: %w", p.Codigo, err)
+		}
+		esperas, err := enPeriodo.ListaEspera.GetAll()
+		if err != nil {
+			return fmt.Errorf("error al volcar listas de espera de %s: %w", p.Codigo, err)
+		}
+		for _, e := range esperas {
+			fmt.Fprintf(salida, "INSERT INTO lista_espera (%s) VALUES (%s, %s, %s, %s, %s);\n",
+				strings.Join(columnasVolcado["lista_espera"], ", "), literalSQL(e.Estudiante.Cedula),
+				literalSQL(e.Codigo), literalSQL(e.Periodo), literalSQL(strconv.Itoa(e.Grupo)), literalSQL(strconv.Itoa(e.Posicion)))
+		}
+		calificaciones, err := enPeriodo.Calificaciones.GetAll()
+		if err != nil {
+			return fmt.Errorf("error al volcar calificaciones de %s: %w", p.Codigo, err)
+		}
+		for _, c := range calificaciones {
+			fmt.Fprintf(salida, "INSERT INTO calificaciones (%s) VALUES (%s, %s, %s, %s, %s);\n",
+				strings.Join(columnasVolcado["calificaciones"], ", "), literalSQL(c.Cedula), literalSQL(c.Materia),
+				literalSQL(c.Periodo), literalSQL(c.Componente), literalSQL(strconv.Itoa(domain.Centesimas(c.Nota))))
+		}
+		asignaciones, err := enPeriodo.Docentes.GetAsignaciones()
+		if err != nil {
+			return fmt.Errorf("error al volcar docentes de %s: %w", p.Codigo, err)
+		}
+		for _, a := range asignaciones {
+			fmt.Fprintf(salida, "INSERT INTO asignaciones_docentes (%s) VALUES (%s, %s, %s, %s);\n",
+				strings.Join(columnasVolcado["asignaciones_docentes"], ", "), literalSQL(a.Docente), literalSQL(a.Materia),
+				literalSQL(a.Periodo), literalSQL(strconv.Itoa(a.Grupo)))
+		}
+	}
+
+	fmt.Fprintln(salida, "COMMIT;")
+	return salida.Flush()
+}
+
+// Cargar lee un script generado por Volcar y lo inserta, en una sola operación, en una
+// base vacía: si la facultad tiene filas en alguna tabla del volcado, salvo los periodos,
+// falla con ErrBaseNoVacia. Solo se aceptan sentencias INSERT sobre las tablas de datos.
+func (r *Repositorios) Cargar(entrada io.Reader) error {
+	datos, err := leerVolcado(entrada)
+	if err != nil {
+		return err
+	}
+
+	// Las inscripciones de un volcado sin periodos quedan en el periodo de los repositorios
+	for k := range datos.inscripciones {
+		if datos.inscripciones[k].periodo == "" {
+			datos.inscripciones[k].periodo = r.acceso.Periodo
+		}
+	}
+	for k := range datos.esperas {
+		if datos.esperas[k].periodo == "" {
+			datos.esperas[k].periodo = r.acceso.Periodo
+		}
+	}
+	for k := range datos.calificaciones {
+		if datos.calificaciones[k].Periodo == "" {
+			datos.calificaciones[k].Periodo = r.acceso.Periodo
+		}
+	}
+	for k := range datos.asignacionesDocentes {
+		if datos.asignacionesDocentes[k].Periodo == "" {
+			datos.asignacionesDocentes[k].Periodo = r.acceso.Periodo
+		}
+	}
+	return r.cargar(datos)
+}
+
+func recorrerPaginas[T any](listar func(Consulta) (*Pagina[T], error), consulta Consulta, visitar func(T)) error {
+	for {
+		pagina, err := listar(consulta)
+		if err != nil {
+			return err
+		}
+		for _, elemento := range pagina.Elementos {
+			visitar(elemento)
+		}
+		if pagina.SiguienteCursor == "" {
+			return nil
+		}
+		consulta.Cursor = pagina.SiguienteCursor
+	}
+}
+
+// escribirInsert escribe una fila con los valores en el orden de columnasVolcado, seguidos
+// de la fecha de eliminación
+func escribirInsert(w io.Writer, tabla string, eliminado *time.Time, valores ...string) {
+	literales := make([]string, 0, len(valores)+1)
+	for _, valor := range valores {
+		literales = append(literales, literalSQL(valor))
+	}
+	fecha := "NULL"
+	if eliminado != nil {
+		fecha = literalSQL(eliminado.UTC().Format(time.RFC3339Nano))
+	}
+	literales = append(literales, fecha)
+	fmt.Fprintf(w, "INSERT INTO %s (%s) VALUES (%s);\n",
+		tabla, strings.Join(columnasVolcado[tabla], ", "), strings.Join(literales, ", "))
+}
+
+// literalSQL escribe una cadena con la sintaxis estándar, común a SQLite y PostgreSQL
+func literalSQL(valor string) string {
+	return "'" + strings.ReplaceAll(valor, "'", "''") + "'"
+}
+
+// leerVolcado interpreta el script. No ejecuta SQL arbitrario: reconoce solo los INSERT
+// que produce Volcar, así que un volcado adjunto a un reporte no puede alterar el esquema.
+func leerVolcado(entrada io.Reader) (*volcado, error) {
+	contenido, err := io.ReadAll(entrada)
+	if err != nil {
+		return nil, err
+	}
+	sentencias, err := separarSentencias(string(contenido))
+	if err != nil {
+		return nil, err
+	}
+
+	datos := &volcado{}
+	for n, sentencia := range sentencias {
+		switch strings.ToUpper(sentencia) {
+		case "BEGIN", "BEGIN TRANSACTION", "COMMIT", "END":
+			continue
+		}
+
+		tabla, valores, err := interpretarInsert(sentencia)
+		if err != nil {
+			return nil, fmt.Errorf("%w: sentencia %d: %v", ErrVolcadoInvalido, n+1, err)
+		}
+		if err := datos.agregar(tabla, valores); err != nil {
+			return nil, fmt.Errorf("%w: sentencia %d: %v", ErrVolcadoInvalido, n+1, err)
+		}
+	}
+	if err := datos.validarPrerrequisitos(); err != nil {
+		return nil, err
+	}
+	if err := datos.validarGrupos(); err != nil {
+		return nil, err
+	}
+	if err := datos.validarCalificaciones(); err != nil {
+		return nil, err
+	}
+	return datos, nil
+}
+
+func (v *volcado) agregar(tabla string, valores map[string]*string) error {
+	var eliminado *time.Time
+	if fecha := valores["deleted_at"]; fecha != nil {
+		t, err := time.Parse(time.RFC3339Nano, *fecha)
+		if err != nil {
+			return fmt.Errorf("fecha de eliminación inválida %q", *fecha)
+		}
+		eliminado = &t
+	}
+
+	requerido := func(columna string) (string, error) {
+		valor := valores[columna]
+		if valor == nil || *valor == "" {
+			return "", fmt.Errorf("falta el valor de %s.%s", tabla, columna)
+		}
+		return *valor, nil
+	}
+
+	switch tabla {
+	case "periodos":
+		codigo, err := requerido("codigo")
+		if err != nil {
+			return err
+		}
+		if codigo, err = normalizarPeriodo(codigo); err != nil {
+			return err
+		}
+		actual := valores["actual"] != nil && *valores["actual"] == "1"
+		v.periodos = append(v.periodos, &domain.Periodo{Codigo: codigo, Actual: actual})
+	case "estudiantes":
+		cedula, err := requerido("cedula")
+		if err != nil {
+			return err
+		}
+		nombre, err := requerido("nombre")
+		if err != nil {
+			return err
+		}
+		e := domain.NewEstudiante(cedula, nombre)
+		e.EliminadoEn = eliminado
+		v.estudiantes = append(v.estudiantes, e)
+	case "materias":
+		codigo, err := requerido("codigo")
+		if err != nil {
+			return err
+		}
+		nombre, err := requerido("nombre")
+		if err != nil {
+			return err
+		}
+		m := domain.NewMateria(codigo, nombre)
+		if valor := valores["creditos"]; valor != nil {
+			creditos, err := strconv.Atoi(*valor)
+			if err != nil || creditos < 0 {
+				return fmt.Errorf("créditos inválidos %q en la materia %s", *valor, codigo)
+			}
+			m.Creditos = creditos
+		}
+		if valor := valores["cupo"]; valor != nil {
+			cupo, err := strconv.Atoi(*valor)
+			if err != nil || cupo < 0 {
+				return fmt.Errorf("cupo inválido %q en la materia %s", *valor, codigo)
+			}
+			m.Cupo = cupo
+		}
+		m.EliminadoEn = eliminado
+		v.materias = append(v.materias, m)
+	case "inscripciones":
+		cedula, err := requerido("estudiante_cedula")
+		if err != nil {
+			return err
+		}
+		codigo, err := requerido("materia_codigo")
+		if err != nil {
+			return err
+		}
+		var periodo string
+		if valor := valores["periodo"]; valor != nil {
+			if periodo, err = normalizarPeriodo(*valor); err != nil {
+				return err
+			}
+		}
+		grupo := domain.SinGrupo
+		if valor := valores["grupo"]; valor != nil {
+			if grupo, err = strconv.Atoi(*valor); err != nil || grupo < 0 {
+				return fmt.Errorf("grupo inválido %q en la inscripción %s-%s", *valor, cedula, codigo)
+			}
+		}
+		v.inscripciones = append(v.inscripciones, filaInscripcion{cedula: cedula, codigo: codigo, periodo: periodo, grupo: grupo, eliminadaEn: eliminado})
+	case "prerrequisitos":
+		materia, err := requerido("materia_codigo")
+		if err != nil {
+			return err
+		}
+		requisito, err := requerido("requisito_codigo")
+		if err != nil {
+			return err
+		}
+		v.prerrequisitos = append(v.prerrequisitos, domain.Prerrequisito{Materia: materia, Requisito: requisito})
+	case "grupos":
+		materia, err := requerido("materia_codigo")
+		if err != nil {
+			return err
+		}
+		valor, err := requerido("numero")
+		if err != nil {
+			return err
+		}
+		numero, err := strconv.Atoi(valor)
+		if err != nil || numero < 1 {
+			return fmt.Errorf("número de grupo inválido %q en la materia %s", valor, materia)
+		}
+		v.grupos = append(v.grupos, domain.Grupo{Materia: materia, Numero: numero})
+	case "sesiones":
+		materia, err := requerido("materia_codigo")
+		if err != nil {
+			return err
+		}
+		s := domain.Sesion{Materia: materia}
+		for _, campo := range []struct {
+			columna string
+			valor   *int
+		}{{"grupo", &s.Grupo}, {"dia", (*int)(&s.Dia)}, {"inicio", &s.Inicio}, {"fin", &s.Fin}} {
+			texto, err := requerido(campo.columna)
+			if err != nil {
+				return err
+			}
+			if *campo.valor, err = strconv.Atoi(texto); err != nil {
+				return fmt.Errorf("%s inválido %q en una sesión de %s", campo.columna, texto, materia)
+			}
+		}
+		if s.Salon, err = requerido("salon"); err != nil {
+			return err
+		}
+		if s.Grupo < 0 || !s.Dia.Valido() || s.Inicio < 0 || s.Inicio >= s.Fin || s.Fin > domain.MinutosPorDia {
+			return fmt.Errorf("sesión inválida de %s: grupo %d, día %d, de %d a %d minutos", materia, s.Grupo, int(s.Dia), s.Inicio, s.Fin)
+		}
+		v.sesiones = append(v.sesiones, s)
+	case "lista_espera":
+		cedula, err := requerido("estudiante_cedula")
+		if err != nil {
+			return err
+		}
+		codigo, err := requerido("materia_codigo")
+		if err != nil {
+			return err
+		}
+		var periodo string
+		if valor := valores["periodo"]; valor != nil {
+			if periodo, err = normalizarPeriodo(*valor); err != nil {
+				return err
+			}
+		}
+		grupo := domain.SinGrupo
+		if valor := valores["grupo"]; valor != nil {
+			if grupo, err = strconv.Atoi(*valor); err != nil || grupo < 0 {
+				return fmt.Errorf("grupo inválido %q en la lista de espera de %s", *valor, codigo)
+			}
+		}
+		valor, err := requerido("orden")
+		if err != nil {
+			return err
+		}
+		orden, err := strconv.Atoi(valor)
+		if err != nil || orden < 1 {
+			return fmt.Errorf("orden inválido %q en la lista de espera de %s", valor, codigo)
+		}
+		v.esperas = append(v.esperas, filaEspera{cedula: cedula, codigo: codigo, periodo: periodo, grupo: grupo, orden: orden})
+	case "componentes":
+		materia, err := requerido("materia_codigo")
+		if err != nil {
+			return err
+		}
+		nombre, err := requerido("nombre")
+		if err != nil {
+			return err
+		}
+		valor, err := requerido("peso")
+		if err != nil {
+			return err
+		}
+		peso, err := strconv.Atoi(valor)
+		if err != nil || peso < 1 || peso > domain.PesoTotal {
+			return fmt.Errorf("peso inválido %q en el componente %s de %s", valor, nombre, materia)
+		}
+		v.componentes = append(v.componentes, domain.Componente{Materia: materia, Nombre: nombre, Peso: peso})
+	case "calificaciones":
+		var c domain.Calificacion
+		var err error
+		for _, campo := range []struct {
+			columna string
+			valor   *string
+		}{{"estudiante_cedula", &c.Cedula}, {"materia_codigo", &c.Materia}, {"componente", &c.Componente}} {
+			if *campo.valor, err = requerido(campo.columna); err != nil {
+				return err
+			}
+		}
+		if valor := valores["periodo"]; valor != nil {
+			if c.Periodo, err = normalizarPeriodo(*valor); err != nil {
+				return err
+			}
+		}
+		valor, err := requerido("nota")
+		if err != nil {
+			return err
+		}
+		centesimas, err := strconv.Atoi(valor)
+		if err != nil || domain.ValidarNota(domain.NotaDeCentesimas(centesimas)) != nil {
+			return fmt.Errorf("nota inválida %q de %s en %s (%s)", valor, c.Cedula, c.Materia, c.Componente)
+		}
+		c.Nota = domain.NotaDeCentesimas(centesimas)
+		v.calificaciones = append(v.calificaciones, c)
+	case "carreras":
+		codigo, err := requerido("codigo")
+		if err != nil {
+			return err
+		}
+		nombre, err := requerido("nombre")
+		if err != nil {
+			return err
+		}
+		valor, err := requerido("creditos")
+		if err != nil {
+			return err
+		}
+		creditos, err := strconv.Atoi(valor)
+		if err != nil || creditos < 1 {
+			return fmt.Errorf("créditos inválidos %q en la carrera %s", valor, codigo)
+		}
+		v.carreras = append(v.carreras, &domain.Carrera{Codigo: codigo, Nombre: nombre, Creditos: creditos})
+	case "pensum":
+		carrera, err := requerido("carrera_codigo")
+		if err != nil {
+			return err
+		}
+		materia, err := requerido("materia_codigo")
+		if err != nil {
+			return err
+		}
+		valor, err := requerido("semestre")
+		if err != nil {
+			return err
+		}
+		semestre, err := strconv.Atoi(valor)
+		if err != nil || semestre < 1 || semestre > domain.MaximoSemestres {
+			return fmt.Errorf("semestre inválido %q de %s en el pensum de %s", valor, materia, carrera)
+		}
+		// Sin la columna, la materia es obligatoria como en el esquema
+		obligatoria := valores["obligatoria"] == nil || *valores["obligatoria"] != "0"
+		v.pensum = append(v.pensum, domain.MateriaPensum{Carrera: carrera, Materia: materia, Semestre: semestre, Obligatoria: obligatoria})
+	case "carrera_estudiantes":
+		cedula, err := requerido("estudiante_cedula")
+		if err != nil {
+			return err
+		}
+		carrera, err := requerido("carrera_codigo")
+		if err != nil {
+			return err
+		}
+		v.carreraEstudiantes = append(v.carreraEstudiantes, domain.AsignacionCarrera{Cedula: cedula, Carrera: carrera})
+	case "docentes":
+		cedula, err := requerido("cedula")
+		if err != nil {
+			return err
+		}
+		nombre, err := requerido("nombre")
+		if err != nil {
+			return err
+		}
+		v.docentes = append(v.docentes, domain.NewDocente(cedula, nombre))
+	case "asignaciones_docentes":
+		var a domain.AsignacionDocente
+		var err error
+		if a.Docente, err = requerido("docente_cedula"); err != nil {
+			return err
+		}
+		if a.Materia, err = requerido("materia_codigo"); err != nil {
+			return err
+		}
+		if valor := valores["periodo"]; valor != nil {
+			if a.Periodo, err = normalizarPeriodo(*valor); err != nil {
+				return err
+			}
+		}
+		if valor := valores["grupo"]; valor != nil {
+			if a.Grupo, err = strconv.Atoi(*valor); err != nil || a.Grupo < 0 {
+				return fmt.Errorf("grupo inválido %q del docente de %s", *valor, a.Materia)
+			}
+		}
+		v.asignacionesDocentes = append(v.asignacionesDocentes, a)
+	}
+	return nil
+}
+
+// validarPrerrequisitos comprueba que los prerrequisitos del volcado no formen ciclos;
+// las referencias a materias las comprueba cada backend al cargar
+func (v *volcado) validarPrerrequisitos() error {
+	grafo := make(domain.GrafoPrerrequisitos)
+	for _, p := range v.prerrequisitos {
+		if grafo.CreaCiclo(p) {
+			return fmt.Errorf("%w: %s como prerrequisito de %s: %v", ErrVolcadoInvalido, p.Requisito, p.Materia, ErrCiclo)
+		}
+		grafo[p.Materia] = append(grafo[p.Materia], p.Requisito)
+	}
+	return nil
+}
+
+// validarGrupos comprueba que cada inscripción, cada sesión y cada docente con grupo apunten
+// a un grupo del volcado; las referencias a materias las comprueba cada backend al cargar
+func (v *volcado) validarGrupos() error {
+	grupos := make(map[domain.Grupo]bool, len(v.grupos))
+	for _, g := range v.grupos {
+		grupos[g] = true
+	}
+	for _, i := range v.inscripciones {
+		if i.grupo != domain.SinGrupo && !grupos[domain.Grupo{Materia: i.codigo, Numero: i.grupo}] {
+			return fmt.Errorf("%w: la inscripción %s-%s está en el grupo %d, que no existe", ErrVolcadoInvalido, i.cedula, i.codigo, i.grupo)
+		}
+	}
+	for _, s := range v.sesiones {
+		if s.Grupo != domain.SinGrupo && !grupos[domain.Grupo{Materia: s.Materia, Numero: s.Grupo}] {
+			return fmt.Errorf("%w: la sesión del %s de %s es del grupo %d, que no existe", ErrVolcadoInvalido, s.Franja(), s.Materia, s.Grupo)
+		}
+	}
+	for _, a := range v.asignacionesDocentes {
+		if a.Grupo != domain.SinGrupo && !grupos[domain.Grupo{Materia: a.Materia, Numero: a.Grupo}] {
+			return fmt.Errorf("%w: el docente %s dicta el grupo %d de %s, que no existe", ErrVolcadoInvalido, a.Docente, a.Grupo, a.Materia)
+		}
+	}
+	return nil
+}
+
+// validarCalificaciones comprueba que los pesos de los componentes de cada materia no
+// pasen de domain.PesoTotal y que cada nota sea de un componente y de una inscripción del
+// volcado; las referencias a materias las comprueba cada backend al cargar
+func (v *volcado) validarCalificaciones() error {
+	pesos := make(map[string]int)
+	componentes := make(map[[2]string]bool, len(v.componentes))
+	for _, c := range v.componentes {
+		pesos[c.Materia] += c.Peso
+		if pesos[c.Materia] > domain.PesoTotal {
+			return fmt.Errorf("%w: los componentes de %s pesan más del %d%%", ErrVolcadoInvalido, c.Materia, domain.PesoTotal)
+		}
+		componentes[[2]string{c.Materia, c.Nombre}] = true
+	}
+	inscripciones := make(map[[3]string]bool, len(v.inscripciones))
+	for _, i := range v.inscripciones {
+		inscripciones[[3]string{i.cedula, i.codigo, i.periodo}] = true
+	}
+	for _, c := range v.calificaciones {
+		if !componentes[[2]string{c.Materia, c.Componente}] {
+			return fmt.Errorf("%w: la nota de %s en %s es del componente %s, que no existe", ErrVolcadoInvalido, c.Cedula, c.Materia, c.Componente)
+		}
+		if !inscripciones[[3]string{c.Cedula, c.Materia, c.Periodo}] {
+			return fmt.Errorf("%w: la nota de %s en %s (%s) no corresponde a ninguna inscripción", ErrVolcadoInvalido, c.Cedula, c.Materia, c.Componente)
+		}
+	}
+	return nil
+}
+
+// separarSentencias divide el script en sentencias por ';', ignorando los comentarios
+// de línea y respetando los literales entre comillas simples
+func separarSentencias(script string) ([]string, error) {
+	var sentencias []string
+	var actual strings.Builder
+	enLiteral := false
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case enLiteral:
+			actual.WriteByte(c)
+			if c == '\'' {
+				if i+1 < len(script) && script[i+1] == '\'' {
+					actual.WriteByte('\'')
+					i++
+				} else {
+					enLiteral = false
+				}
+			}
+		case c == '\'':
+			enLiteral = true
+			actual.WriteByte(c)
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+		case c == ';':
+			if s := strings.TrimSpace(actual.String()); s != "" {
+				sentencias = append(sentencias, s)
+			}
+			actual.Reset()
+		default:
+			actual.WriteByte(c)
+		}
+	}
+
+	if enLiteral {
+		return nil, fmt.Errorf("%w: literal sin cerrar", ErrVolcadoInvalido)
+	}
+	if s := strings.TrimSpace(actual.String()); s != "" {
+		return nil, fmt.Errorf("%w: la última sentencia no termina en ';'", ErrVolcadoInvalido)
+	}
+	return sentencias, nil
+}
+
+// interpretarInsert reconoce INSERT INTO tabla (columnas) VALUES (valores) con una sola fila
+func interpretarInsert(sentencia string) (string, map[string]*string, error) {
+	l := &lexico{texto: sentencia}
+	if !l.palabra("INSERT") || !l.palabra("INTO") {
+		return "", nil, fmt.Errorf("solo se admiten sentencias INSERT INTO")
+	}
+
+	tabla := l.identificador()
+	permitidas, ok := columnasVolcado[tabla]
+	if !ok {
+		return "", nil, fmt.Errorf("tabla no admitida %q", tabla)
+	}
+
+	if !l.simbolo('(') {
+		return "", nil, fmt.Errorf("se esperaba la lista de columnas")
+	}
+	var columnas []string
+	for {
+		columna := l.identificador()
+		if !contiene(permitidas, columna) {
+			return "", nil, fmt.Errorf("columna no admitida %q en %s", columna, tabla)
+		}
+		columnas = append(columnas, columna)
+		if l.simbolo(')') {
+			break
+		}
+		if !l.simbolo(',') {
+			return "", nil, fmt.Errorf("lista de columnas mal formada")
+		}
+	}
+
+	if !l.palabra("VALUES") || !l.simbolo('(') {
+		return "", nil, fmt.Errorf("se esperaba VALUES (...)")
+	}
+	valores := make(map[string]*string)
+	for i, columna := range columnas {
+		valor, err := l.valor()
+		if err != nil {
+			return "", nil, err
+		}
+		valores[columna] = valor
+		separador := ','
+		if i == len(columnas)-1 {
+			separador = ')'
+		}
+		if !l.simbolo(byte(separador)) {
+			return "", nil, fmt.Errorf("la cantidad de valores no coincide con la de columnas")
+		}
+	}
+	if !l.fin() {
+		return "", nil, fmt.Errorf("texto inesperado después de VALUES")
+	}
+	return tabla, valores, nil
+}
+
+func contiene(lista []string, valor string) bool {
+	for _, elemento := range lista {
+		if elemento == valor {
+			return true
+		}
+	}
+	return false
+}
+
+// lexico recorre una sentencia reconociendo palabras clave, identificadores y literales
+type lexico struct {
+	texto string
+	pos   int
+}
+
+func (l *lexico) saltarEspacios() {
+	for l.pos < len(l.texto) && strings.ContainsRune(" \t\r\n", rune(l.texto[l.pos])) {
+		l.pos++
+	}
+}
+
+func (l *lexico) identificador() string {
+	l.saltarEspacios()
+	inicio := l.pos
+	for l.pos < len(l.texto) {
+		c := l.texto[l.pos]
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			break
+		}
+		l.pos++
+	}
+	return strings.ToLower(l.texto[inicio:l.pos])
+}
+
+func (l *lexico) palabra(esperada string) bool {
+	inicio := l.pos
+	if strings.EqualFold(l.identificador(), esperada) {
+		return true
+	}
+	l.pos = inicio
+	return false
+}
+
+func (l *lexico) simbolo(c byte) bool {
+	l.saltarEspacios()
+	if l.pos < len(l.texto) && l.texto[l.pos] == c {
+		l.pos++
+		return true
+	}
+	return false
+}
+
+func (l *lexico) fin() bool {
+	l.saltarEspacios()
+	return l.pos == len(l.texto)
+}
+
+// valor lee un literal entre comillas simples o NULL
+func (l *lexico) valor() (*string, error) {
+	if l.palabra("NULL") {
+		return nil, nil
+	}
+	if !l.simbolo('\'') {
+		return nil, fmt.Errorf("solo se admiten literales de texto o NULL")
+	}
+	var b strings.Builder
+	for l.pos < len(l.texto) {
+		c := l.texto[l.pos]
+		l.pos++
+		if c != '\'' {
+			b.WriteByte(c)
+			continue
+		}
+		if l.pos < len(l.texto) && l.texto[l.pos] == '\'' {
+			b.WriteByte('\'')
+			l.pos++
+			continue
+		}
+		valor := b.String()
+		return &valor, nil
+	}
+	return nil, fmt.Errorf("literal sin cerrar")
+}
+
+// vaciaSQL indica si la facultad no tiene filas, ni siquiera eliminadas, en ninguna de las
+// tablas del volcado. Los periodos no cuentan: toda facultad tiene al menos el actual y la
+// carga los une con los del volcado.
+func vaciaSQL(tx ejecutor, d Dialecto, facultad string) (bool, error) {
+	for tabla := range columnasVolcado {
+		if tabla == "periodos" {
+			continue
+		}
+		var conDatos bool
+		err := tx.QueryRow(d.rebind("SELECT EXISTS(SELECT 1 FROM "+tabla+" WHERE facultad = ?)"), facultad).Scan(&conDatos)
+		if err != nil {
+			return false, fmt.Errorf("error al revisar la tabla %s: %w", tabla, err)
+		}
+		if conDatos {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// cargarSQL inserta el volcado en una transacción, manteniendo el índice de búsqueda
+// y dejando constancia en la auditoría de cada registro cargado
+func cargarSQL(db ejecutor, d Dialecto, facultad string, cifrador *Cifrador, contexto ContextoAuditoria, datos *volcado) error {
+	return transaccion(db, func(tx ejecutor) error {
+		vacia, err := vaciaSQL(tx, d, facultad)
+		if err != nil {
+			return err
+		}
+		if !vacia {
+			return ErrBaseNoVacia
+		}
+
+		fecha := func(t *time.Time) any {
+			if t == nil {
+				return nil
+			}
+			return t.UTC().Format(time.RFC3339Nano)
+		}
+
+		for _, periodo := range datos.periodosUsados() {
+			_, err := tx.Exec(d.rebind(`
+				INSERT INTO periodos (facultad, codigo)
+				SELECT ?, ?
+				WHERE NOT EXISTS(SELECT 1 FROM periodos WHERE facultad = ? AND codigo = ?)
+			`), facultad, periodo, facultad, periodo)
+			if err != nil {
+				return fmt.Errorf("error al cargar periodo %s: %w", periodo, err)
+			}
+		}
+		if actual := datos.periodoActual(); actual != "" {
+			if err := (&periodoRepo{db: tx, dialecto: d, facultad: facultad}).EstablecerActual(actual); err != nil {
+				return fmt.Errorf("error al cargar periodo %s: %w", actual, err)
+			}
+		}
+
+		for _, e := range datos.estudiantes {
+			_, err := tx.Exec(d.rebind("INSERT INTO estudiantes (facultad, cedula, nombre, deleted_at) VALUES (?, ?, ?, ?)"),
+				facultad, cifrador.cifrarClave(e.Cedula), cifrador.cifrarDato(e.Nombre), fecha(e.EliminadoEn))
+			if err != nil {
+				return fmt.Errorf("error al cargar estudiante %s: %w", e.Cedula, traducirError(err))
+			}
+			if !cifrador.activo() {
+				if err := indiceEstudiantes.indexar(tx, d, facultad, e.Cedula, e.Nombre); err != nil {
+					return err
+				}
+			}
+			for _, c := range cambiosCargados(cambioEstudiante(domain.OperacionCrear, nil, e), cambioEstudiante(domain.OperacionEliminar, e, nil), e.EliminadoEn) {
+				if err := registrarAuditoria(tx, d, facultad, cifrador, contexto, c); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, m := range datos.materias {
+			_, err := tx.Exec(d.rebind("INSERT INTO materias (facultad, codigo, nombre, creditos, cupo, deleted_at) VALUES (?, ?, ?, ?, ?, ?)"),
+				facultad, m.Codigo, m.Nombre, m.Creditos, m.Cupo, fecha(m.EliminadoEn))
+			if err != nil {
+				return fmt.Errorf("error al cargar materia %s: %w", m.Codigo, traducirError(err))
+			}
+			if err := indiceMaterias.indexar(tx, d, facultad, m.Codigo, m.Nombre); err != nil {
+				return err
+			}
+			for _, c := range cambiosCargados(cambioMateria(domain.OperacionCrear, nil, m), cambioMateria(domain.OperacionEliminar, m, nil), m.EliminadoEn) {
+				if err := registrarAuditoria(tx, d, facultad, cifrador, contexto, c); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, p := range datos.prerrequisitos {
+			_, err := tx.Exec(d.rebind("INSERT INTO prerrequisitos (facultad, materia_codigo, requisito_codigo) VALUES (?, ?, ?)"),
+				facultad, p.Materia, p.Requisito)
+			if err != nil {
+				return fmt.Errorf("error al cargar %s como prerrequisito de %s: %w", p.Requisito, p.Materia, traducirError(err))
+			}
+		}
+
+		for _, g := range datos.grupos {
+			_, err := tx.Exec(d.rebind("INSERT INTO grupos (facultad, materia_codigo, numero) VALUES (?, ?, ?)"), facultad, g.Materia, g.Numero)
+			if err != nil {
+				return fmt.Errorf("error al cargar el grupo %d de %s: %w", g.Numero, g.Materia, traducirError(err))
+			}
+		}
+
+		for _, s := range datos.sesiones {
+			_, err := tx.Exec(d.rebind("INSERT INTO sesiones (facultad, "+columnasSesion+") VALUES (?, ?, ?, ?, ?, ?, ?)"),
+				facultad, s.Materia, s.Grupo, int(s.Dia), s.Inicio, s.Fin, s.Salon)
+			if err != nil {
+				return fmt.Errorf("error al cargar la sesión del %s de %s: %w", s.Franja(), s.Materia, traducirError(err))
+			}
+		}
+
+		for _, c := range datos.componentes {
+			_, err := tx.Exec(d.rebind("INSERT INTO componentes (facultad, materia_codigo, nombre, peso) VALUES (?, ?, ?, ?)"),
+				facultad, c.Materia, c.Nombre, c.Peso)
+			if err != nil {
+				return fmt.Errorf("error al cargar el componente %s de %s: %w", c.Nombre, c.Materia, traducirError(err))
+			}
+		}
+
+		for _, c := range datos.carreras {
+			_, err := tx.Exec(d.rebind("INSERT INTO carreras (facultad, codigo, nombre, creditos) VALUES (?, ?, ?, ?)"),
+				facultad, c.Codigo, c.Nombre, c.Creditos)
+			if err != nil {
+				return fmt.Errorf("error al cargar la carrera %s: %w", c.Codigo, traducirError(err))
+			}
+		}
+
+		for _, m := range datos.pensum {
+			_, err := tx.Exec(d.rebind("INSERT INTO pensum (facultad, carrera_codigo, materia_codigo, semestre, obligatoria) VALUES (?, ?, ?, ?, ?)"),
+				facultad, m.Carrera, m.Materia, m.Semestre, enteroBooleano(m.Obligatoria))
+			if err != nil {
+				return fmt.Errorf("error al cargar %s en el pensum de %s: %w", m.Materia, m.Carrera, traducirError(err))
+			}
+		}
+
+		for _, c := range datos.carreraEstudiantes {
+			_, err := tx.Exec(d.rebind("INSERT INTO carrera_estudiantes (facultad, estudiante_cedula, carrera_codigo) VALUES (?, ?, ?)"),
+				facultad, cifrador.cifrarClave(c.Cedula), c.Carrera)
+			if err != nil {
+				return fmt.Errorf("error al cargar la carrera de %s: %w", c.Cedula, traducirError(err))
+			}
+			if err := registrarAuditoria(tx, d, facultad, cifrador, contexto, cambioCarreraEstudiante(domain.OperacionCrear, c.Cedula, "", c.Carrera)); err != nil {
+				return err
+			}
+		}
+
+		for _, docente := range datos.docentes {
+			_, err := tx.Exec(d.rebind("INSERT INTO docentes (facultad, cedula, nombre) VALUES (?, ?, ?)"),
+				facultad, cifrador.cifrarClave(docente.Cedula), cifrador.cifrarDato(docente.Nombre))
+			if err != nil {
+				return fmt.Errorf("error al cargar el docente %s: %w", docente.Cedula, traducirError(err))
+			}
+		}
+
+		for _, a := range datos.asignacionesDocentes {
+			_, err := tx.Exec(d.rebind("INSERT INTO asignaciones_docentes (facultad, periodo, materia_codigo, grupo, docente_cedula) VALUES (?, ?, ?, ?, ?)"),
+				facultad, a.Periodo, a.Materia, a.Grupo, cifrador.cifrarClave(a.Docente))
+			if err != nil {
+				return fmt.Errorf("error al cargar el docente de %s: %w", a.Materia, traducirError(err))
+			}
+		}
+
+		for _, i := range datos.inscripciones {
+			_, err := tx.Exec(d.rebind("INSERT INTO inscripciones (facultad, estudiante_cedula, materia_codigo, periodo, grupo, deleted_at) VALUES (?, ?, ?, ?, ?, ?)"),
+				facultad, cifrador.cifrarClave(i.cedula), i.codigo, i.periodo, i.grupo, fecha(i.eliminadaEn))
+			if err != nil {
+				return fmt.Errorf("error al cargar inscripción %s-%s: %w", i.cedula, i.codigo, traducirError(err))
+			}
+			for _, c := range cambiosCargados(cambioInscripcion(domain.OperacionCrear, i.cedula, i.codigo, i.periodo), cambioInscripcion(domain.OperacionEliminar, i.cedula, i.codigo, i.periodo), i.eliminadaEn) {
+				if err := registrarAuditoria(tx, d, facultad, cifrador, contexto, c); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, e := range datos.esperas {
+			_, err := tx.Exec(d.rebind("INSERT INTO lista_espera (facultad, periodo, materia_codigo, estudiante_cedula, grupo, orden) VALUES (?, ?, ?, ?, ?, ?)"),
+				facultad, e.periodo, e.codigo, cifrador.cifrarClave(e.cedula), e.grupo, e.orden)
+			if err != nil {
+				return fmt.Errorf("error al cargar la espera de %s en %s: %w", e.cedula, e.codigo, traducirError(err))
+			}
+		}
+
+		for _, c := range datos.calificaciones {
+			_, err := tx.Exec(d.rebind("INSERT INTO calificaciones (facultad, periodo, "+columnasCalificacion+") VALUES (?, ?, ?, ?, ?, ?)"),
+				facultad, c.Periodo, cifrador.cifrarClave(c.Cedula), c.Materia, c.Componente, domain.Centesimas(c.Nota))
+			if err != nil {
+				return fmt.Errorf("error al cargar la nota de %s en %s (%s): %w", c.Cedula, c.Materia, c.Componente, traducirError(err))
+			}
+			cambio := cambioCalificacion(domain.OperacionCrear, c.Cedula, c.Materia, c.Periodo, c.Componente, nil, &c.Nota)
+			if err := registrarAuditoria(tx, d, facultad, cifrador, contexto, cambio); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// cambiosCargados retorna el alta y, si el registro llegó eliminado, también su eliminación
+func cambiosCargados(alta, eliminacion cambio, eliminado *time.Time) []cambio {
+	if eliminado == nil {
+		return []cambio{alta}
+	}
+	return []cambio{alta, eliminacion}
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// Variables de entorno con las reglas de la nota definitiva
+const (
+	EnvDecimalesNota   = "INSCRIPCIONES_NOTA_DECIMALES"
+	EnvRedondeoNota    = "INSCRIPCIONES_NOTA_REDONDEO"
+	EnvNotaAprobatoria = "INSCRIPCIONES_NOTA_APROBATORIA"
+)
+
+// ReglasCalificacionDesdeEntorno lee las reglas de la nota definitiva del entorno; las que
+// no se definen toman el valor de domain.ReglasCalificacionPorDefecto
+func ReglasCalificacionDesdeEntorno() (domain.ReglasCalificacion, error) {
+	reglas := domain.ReglasCalificacionPorDefecto
+	if texto := strings.TrimSpace(os.Getenv(EnvDecimalesNota)); texto != "" {
+		decimales, err := strconv.Atoi(texto)
+		if err != nil {
+			return domain.ReglasCalificacion{}, fmt.Errorf("%s debe ser un número entero, se recibió %q", EnvDecimalesNota, texto)
+		}
+		reglas.Decimales = decimales
+	}
+	if texto := strings.TrimSpace(os.Getenv(EnvRedondeoNota)); texto != "" {
+		redondeo, err := domain.ParseModoRedondeo(texto)
+		if err != nil {
+			return domain.ReglasCalificacion{}, fmt.Errorf("%s: %w", EnvRedondeoNota, err)
+		}
+		reglas.Redondeo = redondeo
+	}
+	if texto := strings.TrimSpace(os.Getenv(EnvNotaAprobatoria)); texto != "" {
+		nota, err := domain.ParseNota(texto)
+		if err != nil {
+			return domain.ReglasCalificacion{}, fmt.Errorf("%s: %w", EnvNotaAprobatoria, err)
+		}
+		reglas.NotaAprobatoria = nota
+	}
+	if err := reglas.Validar(); err != nil {
+		return domain.ReglasCalificacion{}, err
+	}
+	return reglas, nil
+}
+
+// CalificacionesService administra los componentes de evaluación de las materias, las
+// notas de cada inscripción del periodo y el cálculo de la nota definitiva
+type CalificacionesService struct {
+	repos  *repository.Repositorios
+	reglas domain.ReglasCalificacion
+}
+
+func NewCalificacionesService(repos *repository.Repositorios) *CalificacionesService {
+	return &CalificacionesService{repos: repos, reglas: domain.ReglasCalificacionPorDefecto}
+}
+
+// EstablecerReglas cambia los decimales, el redondeo y la nota aprobatoria de la definitiva
+func (s *CalificacionesService) EstablecerReglas(reglas domain.ReglasCalificacion) {
+	s.reglas = reglas
+}
+
+// Reglas retorna las reglas con las que se calcula la nota definitiva
+func (s *CalificacionesService) Reglas() domain.ReglasCalificacion {
+	return s.reglas
+}
+
+// NotasEstudiante son las notas de un estudiante en cada componente de una materia, por
+// nombre del componente, con la definitiva que resulta de ellas
+type NotasEstudiante struct {
+	Estudiante *domain.Estudiante
+	Notas      map[string]float64
+	Definitiva domain.Definitiva
+}
+
+// PlanillaMateria es la planilla de notas de una materia en el periodo: sus componentes y
+// las notas de cada inscrito, ordenados por cédula
+type PlanillaMateria struct {
+	Materia     *domain.Materia
+	Componentes []domain.Componente
+	Estudiantes []NotasEstudiante
+}
+
+// AgregarComponente agrega a la materia un componente de evaluación con su peso en
+// porcentaje; falla con repository.ErrPesoExcedido si los pesos pasarían del 100%
+func (s *CalificacionesService) AgregarComponente(codigo, nombre string, peso int) error {
+	componente, err := nuevoComponente(codigo, nombre, peso)
+	if err != nil {
+		return err
+	}
+	if err := s.repos.Componentes.Create(componente); err != nil {
+		return fmt.Errorf("error al agregar componente: %w", err)
+	}
+	return nil
+}
+
+// CambiarPeso cambia el peso del componente de la materia
+func (s *CalificacionesService) CambiarPeso(codigo, nombre string, peso int) error {
+	componente, err := nuevoComponente(codigo, nombre, peso)
+	if err != nil {
+		return err
+	}
+	if err := s.repos.Componentes.CambiarPeso(componente); err != nil {
+		return fmt.Errorf("error al cambiar el peso: %w", err)
+	}
+	return nil
+}
+
+// QuitarComponente elimina el componente de la materia; falla con
+// repository.ErrComponenteConNotas mientras tenga notas en cualquier periodo
+func (s *CalificacionesService) QuitarComponente(codigo, nombre string) error {
+	if err := s.repos.Componentes.Delete(strings.TrimSpace(codigo), strings.TrimSpace(nombre)); err != nil {
+		return fmt.Errorf("error al quitar componente: %w", err)
+	}
+	return nil
+}
+
+// ComponentesDeMateria retorna los componentes de evaluación de la materia ordenados por nombre
+func (s *CalificacionesService) ComponentesDeMateria(codigo string) ([]domain.Componente, error) {
+	componentes, err := s.repos.Componentes.GetByMateria(strings.TrimSpace(codigo))
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener componentes: %w", err)
+	}
+	return componentes, nil
+}
+
+// RegistrarNota guarda, o reemplaza, la nota del estudiante en el componente de la materia.
+// La nota se escribe en la escala de 0.0 a 5.0, con punto o coma decimal.
+func (s *CalificacionesService) RegistrarNota(cedula, codigo, componente, nota string) error {
+	valor, err := domain.ParseNota(nota)
+	if err != nil {
+		return err
+	}
+	err = s.repos.Calificaciones.Registrar(strings.TrimSpace(cedula), strings.TrimSpace(codigo), strings.TrimSpace(componente), valor)
+	if err != nil {
+		return fmt.Errorf("error al registrar nota: %w", err)
+	}
+	return nil
+}
+
+// QuitarNota elimina la nota del estudiante en el componente de la materia
+func (s *CalificacionesService) QuitarNota(cedula, codigo, componente string) error {
+	err := s.repos.Calificaciones.Delete(strings.TrimSpace(cedula), strings.TrimSpace(codigo), strings.TrimSpace(componente))
+	if err != nil {
+		return fmt.Errorf("error al quitar nota: %w", err)
+	}
+	return nil
+}
+
+// DefinitivaDeInscripcion retorna las notas del estudiante en la materia y su definitiva;
+// nil si el estudiante no está inscrito en ella en el periodo
+func (s *CalificacionesService) DefinitivaDeInscripcion(cedula, codigo string) (*NotasEstudiante, error) {
+	cedula, codigo = strings.TrimSpace(cedula), strings.TrimSpace(codigo)
+	inscrito, err := s.repos.Inscripciones.Exists(cedula, codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar la inscripción: %w", err)
+	}
+	if !inscrito {
+		return nil, nil
+	}
+	estudiante, err := s.repos.Estudiantes.GetByCedula(cedula)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar estudiante: %w", err)
+	}
+	componentes, err := s.ComponentesDeMateria(codigo)
+	if err != nil {
+		return nil, err
+	}
+	calificaciones, err := s.repos.Calificaciones.GetByInscripcion(cedula, codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las notas: %w", err)
+	}
+	notas := notasPorInscripcion(calificaciones)[claveNotas{cedula, codigo}]
+	return &NotasEstudiante{Estudiante: estudiante, Notas: notas, Definitiva: s.reglas.Definitiva(componentes, notas)}, nil
+}
+
+// PlanillaDeMateria retorna la planilla de notas de la materia en el periodo; nil si la
+// materia no existe
+func (s *CalificacionesService) PlanillaDeMateria(codigo string) (*PlanillaMateria, error) {
+	codigo = strings.TrimSpace(codigo)
+	materia, err := s.repos.Materias.GetByCodigo(codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar materia: %w", err)
+	}
+	if materia == nil {
+		return nil, nil
+	}
+	componentes, err := s.ComponentesDeMateria(codigo)
+	if err != nil {
+		return nil, err
+	}
+	inscritos, err := s.repos.Inscripciones.GetByMateria(codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los inscritos: %w", err)
+	}
+	calificaciones, err := s.repos.Calificaciones.GetByMateria(codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las notas: %w", err)
+	}
+
+	notas := notasPorInscripcion(calificaciones)
+	planilla := &PlanillaMateria{Materia: materia, Componentes: componentes, Estudiantes: make([]NotasEstudiante, 0, len(inscritos))}
+	for _, e := range inscritos {
+		propias := notas[claveNotas{e.Cedula, codigo}]
+		planilla.Estudiantes = append(planilla.Estudiantes, NotasEstudiante{
+			Estudiante: e,
+			Notas:      propias,
+			Definitiva: s.reglas.Definitiva(componentes, propias),
+		})
+	}
+	return planilla, nil
+}
+
+// claveNotas identifica una inscripción por cédula y código de materia
+type claveNotas struct {
+	cedula, materia string
+}
+
+// notasPorInscripcion agrupa las notas por inscripción y, dentro de cada una, por componente
+func notasPorInscripcion(calificaciones []domain.Calificacion) map[claveNotas]map[string]float64 {
+	notas := make(map[claveNotas]map[string]float64)
+	for _, c := range calificaciones {
+		clave := claveNotas{c.Cedula, c.Materia}
+		if notas[clave] == nil {
+			notas[clave] = make(map[string]float64)
+		}
+		notas[clave][c.Componente] = c.Nota
+	}
+	return notas
+}
+
+// claveNotasPeriodo identifica las notas de un estudiante en una materia cursada en un periodo
+type claveNotasPeriodo struct {
+	periodo, materia string
+}
+
+// notasPorPeriodo agrupa las notas de un estudiante por periodo y materia y, dentro de cada
+// inscripción, por componente
+func notasPorPeriodo(calificaciones []domain.Calificacion) map[claveNotasPeriodo]map[string]float64 {
+	notas := make(map[claveNotasPeriodo]map[string]float64)
+	for _, c := range calificaciones {
+		clave := claveNotasPeriodo{c.Periodo, c.Materia}
+		if notas[clave] == nil {
+			notas[clave] = make(map[string]float64)
+		}
+		notas[clave][c.Componente] = c.Nota
+	}
+	return notas
+}
+
+// nuevoComponente valida los datos de un componente escritos en la consola
+func nuevoComponente(codigo, nombre string, peso int) (domain.Componente, error) {
+	componente := domain.Componente{Materia: strings.TrimSpace(codigo), Nombre: strings.TrimSpace(nombre), Peso: peso}
+	if len(componente.Nombre) < 2 {
+		return domain.Componente{}, fmt.Errorf("el nombre del componente '%s' debe tener al menos 2 caracteres", componente.Nombre)
+	}
+	if peso < 1 || peso > domain.PesoTotal {
+		return domain.Componente{}, fmt.Errorf("el peso %d debe estar entre 1 y %d", peso, domain.PesoTotal)
+	}
+	return componente, nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+	"inscripciones/pkg/fileutil"
+)
+
+// nuevaCalificacionesEnMemoria inscribe a Lulú y a Pepito en Cálculo, evaluada con
+// parciales al 60% y un final al 40%
+func nuevaCalificacionesEnMemoria(t *testing.T) (*CalificacionesService, *repository.Repositorios) {
+	t.Helper()
+	svc, repos := nuevaConsultasEnMemoria()
+	calificaciones := NewCalificacionesService(repos)
+	for _, e := range [][2]string{{"1234567", "Lulú López"}, {"9876534", "Pepito Pérez"}} {
+		if err := svc.InsertarNuevoRegistro(e[0], e[1], "1040", "Cálculo"); err != nil {
+			t.Fatalf("InsertarNuevoRegistro: %v", err)
+		}
+	}
+	if err := calificaciones.AgregarComponente("1040", "Parciales", 60); err != nil {
+		t.Fatalf("AgregarComponente: %v", err)
+	}
+	if err := calificaciones.AgregarComponente(" 1040 ", " Final ", 40); err != nil {
+		t.Fatalf("AgregarComponente: %v", err)
+	}
+	return calificaciones, repos
+}
+
+func TestReglasCalificacionDesdeEntorno(t *testing.T) {
+	t.Setenv(EnvDecimalesNota, "")
+	t.Setenv(EnvRedondeoNota, "")
+	t.Setenv(EnvNotaAprobatoria, "")
+	if reglas, err := ReglasCalificacionDesdeEntorno(); err != nil || reglas != domain.ReglasCalificacionPorDefecto {
+		t.Fatalf("ReglasCalificacionDesdeEntorno sin variables = %+v, %v; se esperaban las reglas por defecto", reglas, err)
+	}
+
+	t.Setenv(EnvDecimalesNota, "2")
+	t.Setenv(EnvRedondeoNota, "Truncar")
+	t.Setenv(EnvNotaAprobatoria, "3,5")
+	reglas, err := ReglasCalificacionDesdeEntorno()
+	if err != nil || reglas != (domain.ReglasCalificacion{Decimales: 2, Redondeo: domain.Truncar, NotaAprobatoria: 3.5}) {
+		t.Fatalf("ReglasCalificacionDesdeEntorno = %+v, %v; se esperaban 2 decimales, truncar y 3.5", reglas, err)
+	}
+
+	for variable, valor := range map[string]string{EnvDecimalesNota: "3", EnvRedondeoNota: "al azar", EnvNotaAprobatoria: "5.5"} {
+		t.Run(variable, func(t *testing.T) {
+			t.Setenv(variable, valor)
+			if _, err := ReglasCalificacionDesdeEntorno(); err == nil {
+				t.Fatalf("se esperaba error con %s=%s", variable, valor)
+			}
+		})
+	}
+}
+
+func TestRegistrarNotaValidaLaEscala(t *testing.T) {
+	calificaciones, _ := nuevaCalificacionesEnMemoria(t)
+
+	for _, nota := range []string{"5.01", "-1", "3.456", "tres", "", "+3", "3,,5"} {
+		if err := calificaciones.RegistrarNota("1234567", "1040", "Final", nota); err == nil {
+			t.Errorf("RegistrarNota(%q): se esperaba error", nota)
+		}
+	}
+	for nota, esperada := range map[string]float64{"0": 0, "5": 5, "4,25": 4.25, " 3.8 ": 3.8, "2.05": 2.05} {
+		if err := calificaciones.RegistrarNota("1234567", "1040", "Final", nota); err != nil {
+			t.Fatalf("RegistrarNota(%q): %v", nota, err)
+		}
+		notas, _ := calificaciones.DefinitivaDeInscripcion("1234567", "1040")
+		if notas.Notas["Final"] != esperada {
+			t.Errorf("RegistrarNota(%q) guardó %v, se esperaba %v", nota, notas.Notas["Final"], esperada)
+		}
+	}
+
+	if err := calificaciones.RegistrarNota("1234567", "1040", "Quices", "4"); !errors.Is(err, repository.ErrReferenciaInvalida) {
+		t.Fatalf("RegistrarNota en un componente inexistente = %v, se esperaba ErrReferenciaInvalida", err)
+	}
+	if err := calificaciones.AgregarComponente("1040", "Quices", 0); err == nil {
+		t.Fatal("AgregarComponente con peso 0: se esperaba error")
+	}
+	if err := calificaciones.AgregarComponente("1040", "Quices", 1); !errors.Is(err, repository.ErrPesoExcedido) {
+		t.Fatalf("AgregarComponente por encima del 100%% = %v, se esperaba ErrPesoExcedido", err)
+	}
+}
+
+func TestDefinitivaSegunLasReglas(t *testing.T) {
+	calificaciones, _ := nuevaCalificacionesEnMemoria(t)
+	if err := calificaciones.RegistrarNota("1234567", "1040", "Parciales", "2.8"); err != nil {
+		t.Fatalf("RegistrarNota: %v", err)
+	}
+
+	// Sin el final la definitiva es parcial y no aprueba: 2.8 × 60% = 1.68
+	notas, err := calificaciones.DefinitivaDeInscripcion("1234567", "1040")
+	if err != nil {
+		t.Fatalf("DefinitivaDeInscripcion: %v", err)
+	}
+	if d := notas.Definitiva; d.Nota != 1.7 || d.PesoCalificado != 60 || d.Completa || d.Aprobada {
+		t.Fatalf("Definitiva parcial = %+v, se esperaba 1.7 con el 60%% calificado", d)
+	}
+
+	// 2.8 × 60% + 3.2 × 40% = 2.96: con un decimal aproxima a 3.0 y aprueba, truncando es 2.9
+	if err := calificaciones.RegistrarNota("1234567", "1040", "Final", "3.2"); err != nil {
+		t.Fatalf("RegistrarNota: %v", err)
+	}
+	for _, caso := range []struct {
+		reglas   domain.ReglasCalificacion
+		nota     float64
+		aprobada bool
+	}{
+		{domain.ReglasCalificacionPorDefecto, 3.0, true},
+		{domain.ReglasCalificacion{Decimales: 1, Redondeo: domain.Truncar, NotaAprobatoria: 3}, 2.9, false},
+		{domain.ReglasCalificacion{Decimales: 2, Redondeo: domain.Aproximar, NotaAprobatoria: 3}, 2.96, false},
+		{domain.ReglasCalificacion{Decimales: 0, Redondeo: domain.Aproximar, NotaAprobatoria: 3}, 3, true},
+		{domain.ReglasCalificacion{Decimales: 2, Redondeo: domain.Truncar, NotaAprobatoria: 2.95}, 2.96, true},
+	} {
+		calificaciones.EstablecerReglas(caso.reglas)
+		notas, _ := calificaciones.DefinitivaDeInscripcion("1234567", "1040")
+		if d := notas.Definitiva; d.Nota != caso.nota || !d.Completa || d.Aprobada != caso.aprobada {
+			t.Errorf("Definitiva con %+v = %+v, se esperaba %v (aprobada: %v)", caso.reglas, d, caso.nota, caso.aprobada)
+		}
+	}
+
+	if notas, _ := calificaciones.DefinitivaDeInscripcion("5555555", "1040"); notas != nil {
+		t.Fatalf("DefinitivaDeInscripcion sin inscripción = %+v, se esperaba nil", notas)
+	}
+}
+
+func TestPlanillaDeMateria(t *testing.T) {
+	calificaciones, repos := nuevaCalificacionesEnMemoria(t)
+	for _, n := range [][3]string{{"1234567", "Parciales", "4.5"}, {"1234567", "Final", "3.75"}, {"9876534", "Final", "2"}} {
+		if err := calificaciones.RegistrarNota(n[0], "1040", n[1], n[2]); err != nil {
+			t.Fatalf("RegistrarNota: %v", err)
+		}
+	}
+
+	planilla, err := calificaciones.PlanillaDeMateria("1040")
+	if err != nil {
+		t.Fatalf("PlanillaDeMateria: %v", err)
+	}
+	if len(planilla.Componentes) != 2 || len(planilla.Estudiantes) != 2 {
+		t.Fatalf("PlanillaDeMateria = %+v, se esperaban 2 componentes y 2 inscritos", planilla)
+	}
+	lulu, pepito := planilla.Estudiantes[0], planilla.Estudiantes[1]
+	if lulu.Estudiante.Cedula != "1234567" || lulu.Definitiva.Nota != 4.2 || !lulu.Definitiva.Aprobada {
+		t.Fatalf("fila de Lulú = %+v, se esperaba la definitiva aprobada de 4.2", lulu)
+	}
+	if pepito.Definitiva.Nota != 0.8 || pepito.Definitiva.Completa || len(pepito.Notas) != 1 {
+		t.Fatalf("fila de Pepito = %+v, se esperaba la definitiva parcial de 0.8", pepito)
+	}
+
+	// Un inscrito que cancela sale de la planilla; una materia inexistente no tiene planilla
+	if err := repos.Inscripciones.Delete("9876534", "1040"); err != nil {
+		t.Fatalf("Delete inscripción: %v", err)
+	}
+	if planilla, _ := calificaciones.PlanillaDeMateria("1040"); len(planilla.Estudiantes) != 1 {
+		t.Fatalf("PlanillaDeMateria tras cancelar = %+v, se esperaba solo a Lulú", planilla.Estudiantes)
+	}
+	if planilla, _ := calificaciones.PlanillaDeMateria("9999"); planilla != nil {
+		t.Fatalf("PlanillaDeMateria de una materia inexistente = %+v, se esperaba nil", planilla)
+	}
+
+	if err := calificaciones.QuitarComponente("1040", "Final"); !errors.Is(err, repository.ErrComponenteConNotas) {
+		t.Fatalf("QuitarComponente con notas = %v, se esperaba ErrComponenteConNotas", err)
+	}
+	if err := calificaciones.QuitarNota("1234567", "1040", "Final"); err != nil {
+		t.Fatalf("QuitarNota: %v", err)
+	}
+	if err := calificaciones.QuitarNota("9876534", "1040", "Final"); err != nil {
+		t.Fatalf("QuitarNota de una inscripción cancelada: %v", err)
+	}
+	if err := calificaciones.QuitarComponente("1040", "Final"); err != nil {
+		t.Fatalf("QuitarComponente sin notas: %v", err)
+	}
+}
+
+func TestProcesarArchivoDeNotas(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_validas.txt"); err != nil {
+		t.Fatalf("ProcesarArchivo: %v", err)
+	}
+	calificaciones := NewCalificacionesService(repos)
+	if err := calificaciones.AgregarComponente("1040", "Parciales", 60); err != nil {
+		t.Fatalf("AgregarComponente: %v", err)
+	}
+	if err := calificaciones.AgregarComponente("1040", "Final", 40); err != nil {
+		t.Fatalf("AgregarComponente: %v", err)
+	}
+
+	// La nota fuera de escala, la del estudiante que no está en Cálculo y la del componente
+	// inexistente se omiten; las demás quedan registradas
+	notas := NewProcesadorCalificaciones(&fileutil.LectorArchivoTexto{}, repos)
+	registradas, err := notas.ProcesarArchivo("../../testdata/calificaciones.txt")
+	if err != nil {
+		t.Fatalf("ProcesarArchivo de notas: %v", err)
+	}
+	if registradas != 7 {
+		t.Fatalf("registradas = %d, se esperaban 7", registradas)
+	}
+	planilla, _ := calificaciones.PlanillaDeMateria("1040")
+	aprobados := 0
+	for _, fila := range planilla.Estudiantes {
+		if fila.Definitiva.Aprobada {
+			aprobados++
+		}
+	}
+	if aprobados != 2 {
+		t.Fatalf("aprobados = %d, se esperaban Lulú y Ana", aprobados)
+	}
+
+	// Las notas de otro periodo exigen inscripciones en ese periodo
+	if _, err := notas.ProcesarArchivoEnPeriodo("../../testdata/calificaciones.txt", "2020-2"); err != nil {
+		t.Fatalf("ProcesarArchivoEnPeriodo: %v", err)
+	}
+	otro, _ := repos.EnPeriodo("2020-2")
+	if todas, _ := otro.Calificaciones.GetAll(); len(todas) != 0 {
+		t.Fatalf("notas en 2020-2 = %+v, no se esperaba ninguna", todas)
+	}
+
+	if _, err := notas.ProcesarArchivo("../../testdata/inscripciones_validas.txt"); err == nil {
+		t.Fatal("se esperaba error con un archivo sin notas válidas")
+	}
+}
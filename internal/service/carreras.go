@@ -0,0 +1,286 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// ErrEstudianteSinCarrera impide auditar el grado de un estudiante que no pertenece a ninguna carrera
+var ErrEstudianteSinCarrera = errors.New("el estudiante no tiene carrera asignada")
+
+// Estados de una materia del pensum en la auditoría de grado; las que siguen en curso
+// usan EstadoEnCurso, como en el historial académico
+const (
+	EstadoCompletada = "Completada"
+	EstadoFaltante   = "Faltante"
+)
+
+// CarrerasService administra las carreras, sus pensums y los estudiantes de cada una, y
+// arma la auditoría de grado a partir del historial académico
+type CarrerasService struct {
+	repos       *repository.Repositorios
+	historiales *HistorialAcademicoService
+}
+
+func NewCarrerasService(repos *repository.Repositorios) *CarrerasService {
+	return &CarrerasService{repos: repos, historiales: NewHistorialAcademicoService(repos)}
+}
+
+// EstablecerReglas cambia las reglas con las que se decide si una materia está aprobada
+func (s *CarrerasService) EstablecerReglas(reglas domain.ReglasCalificacion) {
+	s.historiales.EstablecerReglas(reglas)
+}
+
+// CrearCarrera agrega la carrera con los créditos que exige para graduarse
+func (s *CarrerasService) CrearCarrera(codigo, nombre string, creditos int) error {
+	carrera, err := nuevaCarrera(codigo, nombre, creditos)
+	if err != nil {
+		return err
+	}
+	if err := s.repos.Carreras.Create(carrera); err != nil {
+		return fmt.Errorf("error al crear carrera: %w", err)
+	}
+	return nil
+}
+
+// ActualizarCarrera cambia el nombre y los créditos de la carrera
+func (s *CarrerasService) ActualizarCarrera(codigo, nombre string, creditos int) error {
+	carrera, err := nuevaCarrera(codigo, nombre, creditos)
+	if err != nil {
+		return err
+	}
+	if err := s.repos.Carreras.Update(carrera); err != nil {
+		return fmt.Errorf("error al actualizar carrera: %w", err)
+	}
+	return nil
+}
+
+// EliminarCarrera quita la carrera y su pensum; falla con repository.ErrCarreraConEstudiantes
+// mientras algún estudiante pertenezca a ella
+func (s *CarrerasService) EliminarCarrera(codigo string) error {
+	if err := s.repos.Carreras.Delete(strings.TrimSpace(codigo)); err != nil {
+		return fmt.Errorf("error al eliminar carrera: %w", err)
+	}
+	return nil
+}
+
+// ListarCarreras retorna las carreras ordenadas por código
+func (s *CarrerasService) ListarCarreras() ([]*domain.Carrera, error) {
+	carreras, err := s.repos.Carreras.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener carreras: %w", err)
+	}
+	return carreras, nil
+}
+
+// AgregarAlPensum ubica la materia en el semestre indicado del pensum de la carrera, como
+// obligatoria o como electiva
+func (s *CarrerasService) AgregarAlPensum(carrera, materia string, semestre int, obligatoria bool) error {
+	if semestre < 1 || semestre > domain.MaximoSemestres {
+		return fmt.Errorf("el semestre %d debe estar entre 1 y %d", semestre, domain.MaximoSemestres)
+	}
+	m := domain.MateriaPensum{Carrera: strings.TrimSpace(carrera), Materia: strings.TrimSpace(materia), Semestre: semestre, Obligatoria: obligatoria}
+	if err := s.repos.Carreras.AgregarAlPensum(m); err != nil {
+		return fmt.Errorf("error al agregar al pensum: %w", err)
+	}
+	return nil
+}
+
+// QuitarDelPensum saca la materia del pensum de la carrera
+func (s *CarrerasService) QuitarDelPensum(carrera, materia string) error {
+	if err := s.repos.Carreras.QuitarDelPensum(strings.TrimSpace(carrera), strings.TrimSpace(materia)); err != nil {
+		return fmt.Errorf("error al quitar del pensum: %w", err)
+	}
+	return nil
+}
+
+// MateriaDelPensum es una materia del pensum con sus datos; Materia es nil si la materia
+// fue eliminada después de agregarla al pensum
+type MateriaDelPensum struct {
+	Codigo      string
+	Semestre    int
+	Obligatoria bool
+	Materia     *domain.Materia
+}
+
+// Pensum es el pensum de una carrera ordenado por semestre y código de materia
+type Pensum struct {
+	Carrera  *domain.Carrera
+	Materias []MateriaDelPensum
+}
+
+// PensumDeCarrera retorna el pensum de la carrera; nil si la carrera no existe
+func (s *CarrerasService) PensumDeCarrera(codigo string) (*Pensum, error) {
+	carrera, err := s.repos.Carreras.GetByCodigo(strings.TrimSpace(codigo))
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar carrera: %w", err)
+	}
+	if carrera == nil {
+		return nil, nil
+	}
+	pensum, err := s.repos.Carreras.GetPensum(carrera.Codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el pensum: %w", err)
+	}
+	resultado := &Pensum{Carrera: carrera, Materias: make([]MateriaDelPensum, 0, len(pensum))}
+	for _, m := range pensum {
+		materia, err := s.repos.Materias.GetByCodigo(m.Materia)
+		if err != nil {
+			return nil, fmt.Errorf("error al buscar materia: %w", err)
+		}
+		resultado.Materias = append(resultado.Materias, MateriaDelPensum{Codigo: m.Materia, Semestre: m.Semestre, Obligatoria: m.Obligatoria, Materia: materia})
+	}
+	return resultado, nil
+}
+
+// AsignarEstudiante pone al estudiante en la carrera, o lo cambia de carrera si ya tenía una
+func (s *CarrerasService) AsignarEstudiante(cedula, carrera string) error {
+	if err := s.repos.Carreras.AsignarEstudiante(strings.TrimSpace(cedula), strings.TrimSpace(carrera)); err != nil {
+		return fmt.Errorf("error al asignar carrera: %w", err)
+	}
+	return nil
+}
+
+// QuitarEstudiante saca al estudiante de su carrera
+func (s *CarrerasService) QuitarEstudiante(cedula string) error {
+	if err := s.repos.Carreras.QuitarEstudiante(strings.TrimSpace(cedula)); err != nil {
+		return fmt.Errorf("error al quitar carrera: %w", err)
+	}
+	return nil
+}
+
+// EstudiantesDeCarrera retorna los estudiantes activos de la carrera ordenados por cédula
+func (s *CarrerasService) EstudiantesDeCarrera(codigo string) ([]*domain.Estudiante, error) {
+	estudiantes, err := s.repos.Carreras.GetEstudiantes(strings.TrimSpace(codigo))
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los estudiantes de la carrera: %w", err)
+	}
+	return estudiantes, nil
+}
+
+// MateriaAuditada es una materia del pensum con su estado para el estudiante: completada
+// si la aprobó en algún periodo, en curso si la definitiva de su último intento todavía es
+// parcial, y faltante si no la ha cursado o la reprobó
+type MateriaAuditada struct {
+	MateriaDelPensum
+	Estado string
+	// Definitiva es la del intento aprobado o la del último; cero si no la ha cursado
+	Definitiva domain.Definitiva
+}
+
+// AuditoriaGrado muestra cuánto le falta a un estudiante para graduarse de su carrera. Los
+// créditos aprobados suman las materias del pensum aprobadas, obligatorias y electivas, una
+// sola vez cada una; las materias fuera del pensum no cuentan.
+type AuditoriaGrado struct {
+	Estudiante *domain.Estudiante
+	Carrera    *domain.Carrera
+	Materias   []MateriaAuditada
+	// Obligatorias completadas, en curso y faltantes
+	Completadas int
+	EnCurso     int
+	Faltantes   int
+	// CreditosAprobados y CreditosEnCurso son los de las materias del pensum
+	CreditosAprobados int
+	CreditosEnCurso   int
+	// CreditosRestantes son los que faltan para los créditos de la carrera; cero si ya los tiene
+	CreditosRestantes int
+}
+
+// PuedeGraduarse indica si el estudiante completó todas las obligatorias y los créditos de la carrera
+func (a *AuditoriaGrado) PuedeGraduarse() bool {
+	return a.EnCurso == 0 && a.Faltantes == 0 && a.CreditosRestantes == 0
+}
+
+// AuditoriaDeGrado arma la auditoría de grado del estudiante en su carrera; nil si el
+// estudiante no existe y ErrEstudianteSinCarrera si no pertenece a ninguna
+func (s *CarrerasService) AuditoriaDeGrado(cedula string) (*AuditoriaGrado, error) {
+	historial, err := s.historiales.HistorialPorCedula(cedula)
+	if err != nil || historial == nil {
+		return nil, err
+	}
+	codigo, err := s.repos.Carreras.CarreraDeEstudiante(historial.Estudiante.Cedula)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar la carrera del estudiante: %w", err)
+	}
+	if codigo == "" {
+		return nil, fmt.Errorf("%w: %s", ErrEstudianteSinCarrera, historial.Estudiante.Cedula)
+	}
+	pensum, err := s.PensumDeCarrera(codigo)
+	if err != nil {
+		return nil, err
+	}
+
+	// El historial va del periodo más antiguo al más reciente: la última definitiva de
+	// cada materia es la de su último intento, salvo que alguna anterior la haya aprobado
+	cursadas := make(map[string]MateriaCursada)
+	for _, periodo := range historial.Periodos {
+		for _, m := range periodo.Materias {
+			if anterior, ok := cursadas[m.Materia.Codigo]; !ok || !anterior.Definitiva.Aprobada {
+				cursadas[m.Materia.Codigo] = m
+			}
+		}
+	}
+
+	auditoria := &AuditoriaGrado{Estudiante: historial.Estudiante, Carrera: pensum.Carrera}
+	for _, m := range pensum.Materias {
+		auditada := MateriaAuditada{MateriaDelPensum: m, Estado: EstadoFaltante}
+		cursada, ok := cursadas[m.Codigo]
+		if ok {
+			auditada.Definitiva = cursada.Definitiva
+			if m.Materia == nil {
+				auditada.Materia = cursada.Materia
+			}
+			switch cursada.Estado() {
+			case EstadoAprobada:
+				auditada.Estado = EstadoCompletada
+			case EstadoEnCurso:
+				auditada.Estado = EstadoEnCurso
+			}
+		}
+
+		creditos := 0
+		if auditada.Materia != nil {
+			creditos = auditada.Materia.Creditos
+		}
+		switch auditada.Estado {
+		case EstadoCompletada:
+			auditoria.CreditosAprobados += creditos
+		case EstadoEnCurso:
+			auditoria.CreditosEnCurso += creditos
+		}
+		if m.Obligatoria {
+			switch auditada.Estado {
+			case EstadoCompletada:
+				auditoria.Completadas++
+			case EstadoEnCurso:
+				auditoria.EnCurso++
+			default:
+				auditoria.Faltantes++
+			}
+		}
+		auditoria.Materias = append(auditoria.Materias, auditada)
+	}
+	if restantes := pensum.Carrera.Creditos - auditoria.CreditosAprobados; restantes > 0 {
+		auditoria.CreditosRestantes = restantes
+	}
+	return auditoria, nil
+}
+
+// nuevaCarrera valida los datos de una carrera escritos en la consola
+func nuevaCarrera(codigo, nombre string, creditos int) (*domain.Carrera, error) {
+	carrera := &domain.Carrera{Codigo: strings.TrimSpace(codigo), Nombre: strings.TrimSpace(nombre), Creditos: creditos}
+	if len(carrera.Codigo) < 2 {
+		return nil, fmt.Errorf("el código de carrera '%s' debe tener al menos 2 caracteres", carrera.Codigo)
+	}
+	if len(carrera.Nombre) < 2 {
+		return nil, fmt.Errorf("el nombre de la carrera '%s' debe tener al menos 2 caracteres", carrera.Nombre)
+	}
+	if creditos < 1 {
+		return nil, fmt.Errorf("los créditos de la carrera deben ser positivos, se recibió %d", creditos)
+	}
+	return carrera, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func TestAuditoriaDeGrado(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	carreras := NewCarrerasService(repos)
+	if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+		t.Fatalf("Create estudiante: %v", err)
+	}
+	for _, m := range []struct {
+		codigo, nombre string
+		creditos       int
+	}{{"1040", "Cálculo", 4}, {"1050", "Física I", 3}, {"1060", "Administración", 3}, {"1070", "Química", 3}, {"1080", "Dibujo", 2}} {
+		materia := domain.NewMateria(m.codigo, m.nombre)
+		materia.Creditos = m.creditos
+		if err := repos.Materias.Create(materia); err != nil {
+			t.Fatalf("Create materia: %v", err)
+		}
+		if err := repos.Componentes.Create(domain.Componente{Materia: m.codigo, Nombre: "Final", Peso: domain.PesoTotal}); err != nil {
+			t.Fatalf("Create componente: %v", err)
+		}
+	}
+
+	if err := carreras.CrearCarrera(" IS ", "Ingeniería de Sistemas", 12); err != nil {
+		t.Fatalf("CrearCarrera: %v", err)
+	}
+	for _, m := range []struct {
+		codigo      string
+		semestre    int
+		obligatoria bool
+	}{{"1040", 1, true}, {"1060", 1, false}, {"1050", 2, true}, {"1070", 2, true}} {
+		if err := carreras.AgregarAlPensum("IS", m.codigo, m.semestre, m.obligatoria); err != nil {
+			t.Fatalf("AgregarAlPensum %s: %v", m.codigo, err)
+		}
+	}
+	if err := carreras.AgregarAlPensum("IS", "1080", domain.MaximoSemestres+1, true); err == nil {
+		t.Fatal("AgregarAlPensum en un semestre fuera del rango: se esperaba error")
+	}
+
+	if _, err := carreras.AuditoriaDeGrado("1234567"); !errors.Is(err, ErrEstudianteSinCarrera) {
+		t.Fatalf("AuditoriaDeGrado sin carrera = %v, se esperaba ErrEstudianteSinCarrera", err)
+	}
+	if err := carreras.AsignarEstudiante("1234567", "IS"); err != nil {
+		t.Fatalf("AsignarEstudiante: %v", err)
+	}
+
+	// En 2025-2 Lulú aprueba Cálculo y reprueba Administración; en el periodo actual cursa
+	// Física, todavía sin notas, y Dibujo, que no está en el pensum
+	anterior, err := reposDelPeriodo(repos, "2025-2")
+	if err != nil {
+		t.Fatalf("reposDelPeriodo: %v", err)
+	}
+	for _, n := range []struct {
+		repos  *repository.Repositorios
+		codigo string
+		nota   float64
+	}{
+		{anterior, "1040", 4.0},
+		{anterior, "1060", 2.0},
+		{repos, "1050", -1},
+		{repos, "1080", 5.0},
+	} {
+		if err := n.repos.Inscripciones.Create("1234567", n.codigo); err != nil {
+			t.Fatalf("Create inscripción %s: %v", n.codigo, err)
+		}
+		if n.nota < 0 {
+			continue
+		}
+		if err := n.repos.Calificaciones.Registrar("1234567", n.codigo, "Final", n.nota); err != nil {
+			t.Fatalf("Registrar nota %s: %v", n.codigo, err)
+		}
+	}
+
+	auditoria, err := carreras.AuditoriaDeGrado("1234567")
+	if err != nil {
+		t.Fatalf("AuditoriaDeGrado: %v", err)
+	}
+	estados := make(map[string]string)
+	for _, m := range auditoria.Materias {
+		estados[m.Codigo] = m.Estado
+	}
+	esperados := map[string]string{"1040": EstadoCompletada, "1060": EstadoFaltante, "1050": EstadoEnCurso, "1070": EstadoFaltante}
+	for codigo, estado := range esperados {
+		if estados[codigo] != estado {
+			t.Errorf("estado de %s = %q, se esperaba %q", codigo, estados[codigo], estado)
+		}
+	}
+	if auditoria.Completadas != 1 || auditoria.EnCurso != 1 || auditoria.Faltantes != 1 {
+		t.Fatalf("obligatorias = %d completadas, %d en curso y %d faltantes; se esperaba una de cada una",
+			auditoria.Completadas, auditoria.EnCurso, auditoria.Faltantes)
+	}
+	// Dibujo no está en el pensum, así que sus créditos no cuentan
+	if auditoria.CreditosAprobados != 4 || auditoria.CreditosEnCurso != 3 || auditoria.CreditosRestantes != 8 || auditoria.PuedeGraduarse() {
+		t.Fatalf("créditos = %d aprobados, %d en curso y %d restantes; se esperaban 4, 3 y 8",
+			auditoria.CreditosAprobados, auditoria.CreditosEnCurso, auditoria.CreditosRestantes)
+	}
+
+	// Con la electiva aprobada y las obligatorias completas ya puede graduarse, aunque
+	// los créditos aprobados pasen de los de la carrera
+	for _, codigo := range []string{"1050", "1070", "1060"} {
+		if existe, _ := repos.Inscripciones.Exists("1234567", codigo); !existe {
+			if err := repos.Inscripciones.Create("1234567", codigo); err != nil {
+				t.Fatalf("Create inscripción %s: %v", codigo, err)
+			}
+		}
+		if err := repos.Calificaciones.Registrar("1234567", codigo, "Final", 3.5); err != nil {
+			t.Fatalf("Registrar nota %s: %v", codigo, err)
+		}
+	}
+	auditoria, _ = carreras.AuditoriaDeGrado("1234567")
+	if auditoria.CreditosAprobados != 13 || auditoria.CreditosRestantes != 0 || !auditoria.PuedeGraduarse() {
+		t.Fatalf("auditoria completa = %+v, se esperaban 13 créditos aprobados y ninguno restante", auditoria)
+	}
+
+	if auditoria, _ := carreras.AuditoriaDeGrado("0000000"); auditoria != nil {
+		t.Fatalf("AuditoriaDeGrado de un estudiante inexistente = %+v, se esperaba nil", auditoria)
+	}
+	if err := carreras.EliminarCarrera("IS"); !errors.Is(err, repository.ErrCarreraConEstudiantes) {
+		t.Fatalf("EliminarCarrera con estudiantes = %v, se esperaba ErrCarreraConEstudiantes", err)
+	}
+}
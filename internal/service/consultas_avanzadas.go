@@ -1,29 +1,53 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"inscripciones/internal/domain"
 	"inscripciones/internal/repository"
 )
 
 type ConsultasAvanzadasService struct {
+	unidad          repository.UnidadDeTrabajo
 	estudianteRepo  repository.EstudianteRepository
 	materiaRepo     repository.MateriaRepository
 	inscripcionRepo repository.InscripcionRepository
+	limites         domain.LimitesCreditos
+	reglas          domain.ReglasCalificacion
 }
 
 func NewConsultasAvanzadasService(
+	unidad repository.UnidadDeTrabajo,
 	estudianteRepo repository.EstudianteRepository,
 	materiaRepo repository.MateriaRepository,
 	inscripcionRepo repository.InscripcionRepository,
 ) *ConsultasAvanzadasService {
 	return &ConsultasAvanzadasService{
+		unidad:          unidad,
 		estudianteRepo:  estudianteRepo,
 		materiaRepo:     materiaRepo,
 		inscripcionRepo: inscripcionRepo,
+		limites:         domain.LimitesCreditosPorDefecto,
+		reglas:          domain.ReglasCalificacionPorDefecto,
 	}
 }
 
+// EstablecerLimitesCreditos cambia la carga de créditos permitida en los registros nuevos
+func (s *ConsultasAvanzadasService) EstablecerLimitesCreditos(limites domain.LimitesCreditos) {
+	s.limites = limites
+}
+
+// EstablecerReglasCalificacion cambia las reglas con las que se decide si un prerrequisito
+// cursado quedó reprobado
+func (s *ConsultasAvanzadasService) EstablecerReglasCalificacion(reglas domain.ReglasCalificacion) {
+	s.reglas = reglas
+}
+
+// LimitesCreditos retorna la carga de créditos permitida a cada estudiante
+func (s *ConsultasAvanzadasService) LimitesCreditos() domain.LimitesCreditos {
+	return s.limites
+}
+
 // EstadisticasGenerales representa las estadísticas del sistema
 type EstadisticasGenerales struct {
 	TotalEstudiantes    int
@@ -70,6 +94,21 @@ func (s *ConsultasAvanzadasService) BuscarEstudiantePorCedula(cedula string) (*d
 	return estudiante, materias, nil
 }
 
+// BuscarPorNombre busca estudiantes y materias por nombre parcial, sin distinguir tildes ni mayúsculas
+func (s *ConsultasAvanzadasService) BuscarPorNombre(texto string, limite int) ([]*domain.Estudiante, []*domain.Materia, error) {
+	estudiantes, err := s.estudianteRepo.Search(texto, limite)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al buscar estudiantes: %w", err)
+	}
+	
+	materias, err := s.materiaRepo.Search(texto, limite)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al buscar materias: %w", err)
+	}
+	
+	return estudiantes, materias, nil
+}
+
 // ObtenerEstadisticasGenerales genera estadísticas completas del sistema
 func (s *ConsultasAvanzadasService) ObtenerEstadisticasGenerales() (*EstadisticasGenerales, error) {
 	estadisticas := &EstadisticasGenerales{}
@@ -88,16 +127,23 @@ func (s *ConsultasAvanzadasService) ObtenerEstadisticasGenerales() (*Estadistica
 	}
 	estadisticas.TotalMaterias = len(materias)
 	
+	// Contar inscripciones agrupadas por estudiante y por materia en una consulta cada una
+	conteoPorEstudiante, err := s.inscripcionRepo.CountGroupedByEstudiante()
+	if err != nil {
+		return nil, fmt.Errorf("error al contar materias por estudiante: %w", err)
+	}
+	
+	conteoPorMateria, err := s.inscripcionRepo.CountGroupedByMateria()
+	if err != nil {
+		return nil, fmt.Errorf("error al contar estudiantes por materia: %w", err)
+	}
+	
 	// Calcular total de inscripciones y estudiantes con más materias
 	totalInscripciones := 0
 	var estudiantesConMaterias []EstudianteConMaterias
 	
 	for _, estudiante := range estudiantes {
-		count, err := s.inscripcionRepo.CountByEstudiante(estudiante.Cedula)
-		if err != nil {
-			continue // Continuar con el siguiente estudiante en caso de error
-		}
-		
+		count := conteoPorEstudiante[estudiante.Cedula]
 		totalInscripciones += count
 		if count > 0 {
 			estudiantesConMaterias = append(estudiantesConMaterias, EstudianteConMaterias{
@@ -115,15 +161,11 @@ func (s *ConsultasAvanzadasService) ObtenerEstadisticasGenerales() (*Estadistica
 	var materiasConEstudiantes []MateriaConEstudiantes
 	
 	for _, materia := range materias {
-		estudiantes, err := s.inscripcionRepo.GetByMateria(materia.Codigo)
-		if err != nil {
-			continue // Continuar con la siguiente materia en caso de error
-		}
-		
-		if len(estudiantes) > 0 {
+		count := conteoPorMateria[materia.Codigo]
+		if count > 0 {
 			materiasConEstudiantes = append(materiasConEstudiantes, MateriaConEstudiantes{
 				Materia:             materia,
-				CantidadEstudiantes: len(estudiantes),
+				CantidadEstudiantes: count,
 			})
 		}
 	}
@@ -134,82 +176,173 @@ func (s *ConsultasAvanzadasService) ObtenerEstadisticasGenerales() (*Estadistica
 	return estadisticas, nil
 }
 
-// InsertarNuevoRegistro permite insertar un nuevo registro de inscripción
+// InsertarNuevoRegistro permite insertar un nuevo registro de inscripción. El estudiante,
+// la materia y la inscripción se crean juntos, con sus eventos: si un paso falla no queda
+// ninguno guardado. Una materia nueva se crea con domain.CreditosPorDefecto.
 func (s *ConsultasAvanzadasService) InsertarNuevoRegistro(cedula, nombreEstudiante, codigoMateria, nombreMateria string) error {
-	// Verificar si el estudiante existe, si no, crearlo
-	existe, err := s.estudianteRepo.Exists(cedula)
-	if err != nil {
-		return fmt.Errorf("error al verificar existencia del estudiante: %w", err)
+	return s.InsertarNuevoRegistroConCreditos(cedula, nombreEstudiante, codigoMateria, nombreMateria, domain.CreditosPorDefecto)
+}
+
+// InsertarNuevoRegistroConCreditos es InsertarNuevoRegistro indicando los créditos de la
+// materia si hay que crearla; los de una materia existente no cambian. Si la inscripción
+// deja al estudiante por encima del máximo de créditos, falla con ErrCargaExcedida, y si no
+// ha cursado los prerrequisitos de la materia, con ErrPrerrequisitosFaltantes. Si la materia
+// no tiene cupo, el estudiante queda en su lista de espera sin que sea un error; quien
+// necesite saberlo usa InsertarNuevoRegistroEnGrupo.
+func (s *ConsultasAvanzadasService) InsertarNuevoRegistroConCreditos(cedula, nombreEstudiante, codigoMateria, nombreMateria string, creditos int) error {
+	_, err := s.InsertarNuevoRegistroEnGrupo(cedula, nombreEstudiante, codigoMateria, nombreMateria, creditos, domain.SinGrupo)
+	return err
+}
+
+// ResultadoInscripcion dice cómo terminó un registro guardado: inscrito o, si la materia
+// no tenía cupo, en la lista de espera en la posición indicada
+type ResultadoInscripcion struct {
+	EnEspera bool
+	Posicion int
+}
+
+// InsertarNuevoRegistroEnGrupo es InsertarNuevoRegistroConCreditos inscribiendo al estudiante
+// en un grupo de la materia, que se crea si no existe; domain.SinGrupo no asigna ninguno.
+// Quien queda en la lista de espera espera ese grupo y, al recibir el cupo, entra en él;
+// antes de ponerlo en la lista se verifica que su carga de créditos lo admita, como al
+// promoverlo. Si el horario del grupo se cruza con el de otra materia del estudiante en el
+// periodo, falla con ErrCruceHorario nombrando las dos materias. Quedar en la lista de
+// espera no es un error: se informa en el resultado.
+func (s *ConsultasAvanzadasService) InsertarNuevoRegistroEnGrupo(cedula, nombreEstudiante, codigoMateria, nombreMateria string, creditos, grupo int) (ResultadoInscripcion, error) {
+	if creditos < 1 || creditos > domain.MaximoCreditosPorMateria {
+		return ResultadoInscripcion{}, fmt.Errorf("los créditos '%d' deben ser un número entero entre 1 y %d", creditos, domain.MaximoCreditosPorMateria)
 	}
-	
-	if !existe {
-		estudiante := domain.NewEstudiante(cedula, nombreEstudiante)
-		err = s.estudianteRepo.Create(estudiante)
-		if err != nil {
-			return fmt.Errorf("error al crear estudiante: %w", err)
+	if grupo != domain.SinGrupo {
+		if err := validarNumeroGrupo(grupo); err != nil {
+			return ResultadoInscripcion{}, err
 		}
 	}
-	
-	// Verificar si la materia existe, si no, crearla
-	existe, err = s.materiaRepo.Exists(codigoMateria)
-	if err != nil {
-		return fmt.Errorf("error al verificar existencia de la materia: %w", err)
-	}
-	
-	if !existe {
-		materia := domain.NewMateria(codigoMateria, nombreMateria)
-		err = s.materiaRepo.Create(materia)
+	var resultado ResultadoInscripcion
+	err := s.unidad.EnTransaccion(func(repos *repository.Repositorios) error {
+		resultado = ResultadoInscripcion{}
+		// Verificar si el estudiante existe, si no, crearlo
+		existe, err := repos.Estudiantes.Exists(cedula)
 		if err != nil {
-			return fmt.Errorf("error al crear materia: %w", err)
+			return fmt.Errorf("error al verificar existencia del estudiante: %w", err)
 		}
-	}
-	
-	// Verificar si la inscripción ya existe
-	existe, err = s.inscripcionRepo.Exists(cedula, codigoMateria)
+		
+		if !existe {
+			estudiante := domain.NewEstudiante(cedula, nombreEstudiante)
+			err = repos.Estudiantes.Create(estudiante)
+			if err != nil {
+				return fmt.Errorf("error al crear estudiante: %w", err)
+			}
+			if err := publicar(repos, domain.EstudianteCreado{Cedula: cedula, Nombre: estudiante.Nombre}); err != nil {
+				return err
+			}
+		}
+		
+		// Verificar si la materia existe, si no, crearla
+		existe, err = repos.Materias.Exists(codigoMateria)
+		if err != nil {
+			return fmt.Errorf("error al verificar existencia de la materia: %w", err)
+		}
+		
+		if !existe {
+			materia := domain.NewMateria(codigoMateria, nombreMateria)
+			materia.Creditos = creditos
+			err = repos.Materias.Create(materia)
+			if err != nil {
+				return fmt.Errorf("error al crear materia: %w", err)
+			}
+			if err := publicar(repos, domain.MateriaCreada{Codigo: codigoMateria, Nombre: materia.Nombre}); err != nil {
+				return err
+			}
+		}
+		
+		// Verificar si la inscripción ya existe
+		existe, err = repos.Inscripciones.Exists(cedula, codigoMateria)
+		if err != nil {
+			return fmt.Errorf("error al verificar existencia de la inscripción: %w", err)
+		}
+		
+		if existe {
+			return fmt.Errorf("el estudiante ya está inscrito en esta materia")
+		}
+		
+		if err := verificarPrerrequisitos(repos, s.reglas, cedula, codigoMateria); err != nil {
+			return err
+		}
+		if err := asegurarGrupo(repos, codigoMateria, grupo); err != nil {
+			return err
+		}
+		if err := verificarCruces(repos, cedula, codigoMateria, grupo); err != nil {
+			return err
+		}
+		
+		// Crear la inscripción
+		err = repos.Inscripciones.CreateEnGrupo(cedula, codigoMateria, grupo)
+		if errors.Is(err, repository.ErrCupoAgotado) {
+			// Solo espera quien podría recibir el cupo
+			materia, err := repos.Materias.GetByCodigo(codigoMateria)
+			if err != nil {
+				return fmt.Errorf("error al obtener materia: %w", err)
+			}
+			if err := verificarCargaCon(repos, s.limites, cedula, materia.Creditos); err != nil {
+				return err
+			}
+			posicion, err := repos.ListaEspera.Agregar(cedula, codigoMateria, grupo)
+			if err != nil {
+				return fmt.Errorf("error al poner en lista de espera: %w", err)
+			}
+			resultado = ResultadoInscripcion{EnEspera: true, Posicion: posicion}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error al crear inscripción: %w", err)
+		}
+		if err := verificarCarga(repos, s.limites, cedula); err != nil {
+			return err
+		}
+		
+		return publicar(repos, domain.InscripcionCreada{Cedula: cedula, Codigo: codigoMateria, Periodo: repos.Periodo()})
+	})
 	if err != nil {
-		return fmt.Errorf("error al verificar existencia de la inscripción: %w", err)
+		return ResultadoInscripcion{}, err
 	}
-	
-	if existe {
-		return fmt.Errorf("el estudiante ya está inscrito en esta materia")
+	return resultado, nil
+}
+
+// ObtenerTodosLosRegistros obtiene todos los registros de inscripciones
+func (s *ConsultasAvanzadasService) ObtenerTodosLosRegistros() ([]RegistroCompleto, error) {
+	// Una sola consulta con los datos del estudiante y la materia
+	inscripciones, err := s.inscripcionRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener inscripciones: %w", err)
 	}
 	
-	// Crear la inscripción
-	err = s.inscripcionRepo.Create(cedula, codigoMateria)
-	if err != nil {
-		return fmt.Errorf("error al crear inscripción: %w", err)
+	registros := make([]RegistroCompleto, 0, len(inscripciones))
+	for _, inscripcion := range inscripciones {
+		registros = append(registros, RegistroCompleto{
+			Estudiante: inscripcion.Estudiante,
+			Materia:    inscripcion.Materia,
+		})
 	}
 	
-	return nil
+	return registros, nil
 }
 
-// ObtenerTodosLosRegistros obtiene todos los registros de inscripciones
-func (s *ConsultasAvanzadasService) ObtenerTodosLosRegistros() ([]RegistroCompleto, error) {
-	var registros []RegistroCompleto
-	
-	// Obtener todos los estudiantes
-	estudiantes, err := s.estudianteRepo.GetAll()
+// ObtenerPaginaDeRegistros obtiene una página de registros de inscripciones y el cursor de la siguiente
+func (s *ConsultasAvanzadasService) ObtenerPaginaDeRegistros(consulta repository.Consulta) ([]RegistroCompleto, string, error) {
+	pagina, err := s.inscripcionRepo.List(consulta)
 	if err != nil {
-		return nil, fmt.Errorf("error al obtener estudiantes: %w", err)
+		return nil, "", fmt.Errorf("error al obtener inscripciones: %w", err)
 	}
 	
-	// Para cada estudiante, obtener sus materias
-	for _, estudiante := range estudiantes {
-		materias, err := s.inscripcionRepo.GetByEstudiante(estudiante.Cedula)
-		if err != nil {
-			continue // Continuar con el siguiente estudiante en caso de error
-		}
-		
-		// Crear un registro por cada materia del estudiante
-		for _, materia := range materias {
-			registros = append(registros, RegistroCompleto{
-				Estudiante: estudiante,
-				Materia:    materia,
-			})
-		}
+	registros := make([]RegistroCompleto, 0, len(pagina.Elementos))
+	for _, inscripcion := range pagina.Elementos {
+		registros = append(registros, RegistroCompleto{
+			Estudiante: inscripcion.Estudiante,
+			Materia:    inscripcion.Materia,
+		})
 	}
 	
-	return registros, nil
+	return registros, pagina.SiguienteCursor, nil
 }
 
 // Función auxiliar para obtener top 5 estudiantes con más materias
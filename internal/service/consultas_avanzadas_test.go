@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func nuevaConsultasEnMemoria() (*ConsultasAvanzadasService, *repository.Repositorios) {
+	repos := repository.NewRepositoriosEnMemoria()
+	return NewConsultasAvanzadasService(repos, repos.Estudiantes, repos.Materias, repos.Inscripciones), repos
+}
+
+func TestInsertarNuevoRegistro(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	// El estudiante y la materia ya existen: solo se agrega la inscripción
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1060", "Administración"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro segunda materia: %v", err)
+	}
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err == nil {
+		t.Fatal("se esperaba error al repetir la inscripción")
+	}
+
+	estudiante, materias, err := svc.BuscarEstudiantePorCedula("1234567")
+	if err != nil || estudiante == nil {
+		t.Fatalf("BuscarEstudiantePorCedula = %v, %v", estudiante, err)
+	}
+	if len(materias) != 2 {
+		t.Fatalf("se esperaban 2 materias, se obtuvieron %d", len(materias))
+	}
+
+	todas, _ := repos.Materias.GetAll()
+	if len(todas) != 2 {
+		t.Fatalf("se esperaban 2 materias registradas, hay %d", len(todas))
+	}
+}
+
+func TestInsertarNuevoRegistroEsAtomico(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+	if err := repos.Materias.Create(domain.NewMateria("1040", "Cálculo")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repos.Materias.Delete("1040"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// La materia eliminada hace fallar el registro después de crear al estudiante
+	err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo")
+	if !errors.Is(err, repository.ErrEliminado) {
+		t.Fatalf("InsertarNuevoRegistro = %v, se esperaba ErrEliminado", err)
+	}
+	if existe, _ := repos.Estudiantes.Exists("1234567"); existe {
+		t.Fatal("el estudiante quedó creado aunque el registro falló")
+	}
+}
+
+func TestObtenerEstadisticasGenerales(t *testing.T) {
+	svc, _ := nuevaConsultasEnMemoria()
+	registros := [][4]string{
+		{"1234567", "Lulú López", "1040", "Cálculo"},
+		{"9876534", "Pepito Pérez", "1040", "Cálculo"},
+		{"4567766", "Calvin Clein", "1050", "Física I"},
+		{"1234567", "Lulú López", "1060", "Administración"},
+	}
+	for _, r := range registros {
+		if err := svc.InsertarNuevoRegistro(r[0], r[1], r[2], r[3]); err != nil {
+			t.Fatalf("InsertarNuevoRegistro %v: %v", r, err)
+		}
+	}
+
+	est, err := svc.ObtenerEstadisticasGenerales()
+	if err != nil {
+		t.Fatalf("ObtenerEstadisticasGenerales: %v", err)
+	}
+	if est.TotalEstudiantes != 3 || est.TotalMaterias != 3 || est.TotalInscripciones != 4 {
+		t.Fatalf("totales = %d/%d/%d, se esperaba 3/3/4", est.TotalEstudiantes, est.TotalMaterias, est.TotalInscripciones)
+	}
+	if top := est.EstudiantesConMasMaterias[0]; top.Estudiante.Cedula != "1234567" || top.CantidadMaterias != 2 {
+		t.Errorf("estudiante con más materias = %+v", top)
+	}
+	if top := est.MateriasConMasEstudiantes[0]; top.Materia.Codigo != "1040" || top.CantidadEstudiantes != 2 {
+		t.Errorf("materia con más estudiantes = %+v", top)
+	}
+
+	registrosCompletos, err := svc.ObtenerTodosLosRegistros()
+	if err != nil || len(registrosCompletos) != 4 {
+		t.Fatalf("ObtenerTodosLosRegistros = %d, %v; se esperaban 4", len(registrosCompletos), err)
+	}
+}
+
+func TestObtenerPaginaDeRegistros(t *testing.T) {
+	svc, _ := nuevaConsultasEnMemoria()
+	for _, r := range [][4]string{
+		{"1234567", "Lulú López", "1040", "Cálculo"},
+		{"9876534", "Pepito Pérez", "1040", "Cálculo"},
+		{"4567766", "Calvin Clein", "1050", "Física I"},
+	} {
+		if err := svc.InsertarNuevoRegistro(r[0], r[1], r[2], r[3]); err != nil {
+			t.Fatalf("InsertarNuevoRegistro %v: %v", r, err)
+		}
+	}
+
+	registros, siguiente, err := svc.ObtenerPaginaDeRegistros(repository.Consulta{Limite: 2})
+	if err != nil || len(registros) != 2 || siguiente == "" {
+		t.Fatalf("primera página = %d registros, cursor %q, %v", len(registros), siguiente, err)
+	}
+
+	registros, siguiente, err = svc.ObtenerPaginaDeRegistros(repository.Consulta{Limite: 2, Cursor: siguiente})
+	if err != nil || len(registros) != 1 || siguiente != "" {
+		t.Fatalf("segunda página = %d registros, cursor %q, %v", len(registros), siguiente, err)
+	}
+	if registros[0].Estudiante.Cedula != "9876534" {
+		t.Errorf("último registro = %s, se esperaba 9876534", registros[0].Estudiante.Cedula)
+	}
+}
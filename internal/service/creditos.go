@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// Variables de entorno con los límites de la carga de créditos de cada estudiante
+const (
+	EnvCreditosMinimo = "INSCRIPCIONES_CREDITOS_MINIMO"
+	EnvCreditosMaximo = "INSCRIPCIONES_CREDITOS_MAXIMO"
+)
+
+// ErrCargaExcedida indica que una inscripción dejaría al estudiante por encima del máximo de créditos
+var ErrCargaExcedida = errors.New("la inscripción supera la carga máxima de créditos")
+
+// LimitesCreditosDesdeEntorno lee los límites de créditos del entorno; los que no se
+// definen toman el valor de domain.LimitesCreditosPorDefecto
+func LimitesCreditosDesdeEntorno() (domain.LimitesCreditos, error) {
+	limites := domain.LimitesCreditosPorDefecto
+	for _, v := range []struct {
+		nombre string
+		valor  *int
+	}{
+		{EnvCreditosMinimo, &limites.Minimo},
+		{EnvCreditosMaximo, &limites.Maximo},
+	} {
+		texto := strings.TrimSpace(os.Getenv(v.nombre))
+		if texto == "" {
+			continue
+		}
+		n, err := strconv.Atoi(texto)
+		if err != nil || n < 0 {
+			return domain.LimitesCreditos{}, fmt.Errorf("%s debe ser un número entero no negativo, se recibió %q", v.nombre, texto)
+		}
+		*v.valor = n
+	}
+	if limites.Minimo > limites.Maximo {
+		return domain.LimitesCreditos{}, fmt.Errorf("el mínimo de créditos (%d) no puede superar el máximo (%d)", limites.Minimo, limites.Maximo)
+	}
+	return limites, nil
+}
+
+// validarCreditos aplica a los créditos de una materia la misma regla en archivos y ediciones
+func validarCreditos(texto string) (int, error) {
+	creditos, err := strconv.Atoi(strings.TrimSpace(texto))
+	if err != nil || creditos < 1 || creditos > domain.MaximoCreditosPorMateria {
+		return 0, fmt.Errorf("los créditos '%s' deben ser un número entero entre 1 y %d", texto, domain.MaximoCreditosPorMateria)
+	}
+	return creditos, nil
+}
+
+// verificarCarga comprueba, ya guardadas las inscripciones del estudiante en el periodo,
+// que su carga no supere el máximo; así, dentro de una unidad de trabajo, la sobrecarga
+// deshace la inscripción que la produjo
+func verificarCarga(repos *repository.Repositorios, limites domain.LimitesCreditos, cedula string) error {
+	return verificarCargaCon(repos, limites, cedula, 0)
+}
+
+// verificarCargaCon es verificarCarga sumando los créditos de una materia que todavía no
+// está guardada, como la de quien pasa a una lista de espera
+func verificarCargaCon(repos *repository.Repositorios, limites domain.LimitesCreditos, cedula string, creditos int) error {
+	materias, err := repos.Inscripciones.GetByEstudiante(cedula)
+	if err != nil {
+		return fmt.Errorf("error al calcular los créditos del estudiante %s: %w", cedula, err)
+	}
+	total := creditos
+	for _, m := range materias {
+		total += m.Creditos
+	}
+	if limites.Excede(total) {
+		return fmt.Errorf("%w: el estudiante %s tendría %d créditos en el periodo %s (máximo %d)",
+			ErrCargaExcedida, cedula, total, repos.Periodo(), limites.Maximo)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+)
+
+func TestLimitesCreditosDesdeEntorno(t *testing.T) {
+	t.Setenv(EnvCreditosMinimo, "")
+	t.Setenv(EnvCreditosMaximo, "")
+	if limites, err := LimitesCreditosDesdeEntorno(); err != nil || limites != domain.LimitesCreditosPorDefecto {
+		t.Fatalf("LimitesCreditosDesdeEntorno sin variables = %+v, %v; se esperaban los valores por defecto", limites, err)
+	}
+
+	t.Setenv(EnvCreditosMaximo, " 12 ")
+	limites, err := LimitesCreditosDesdeEntorno()
+	if err != nil || limites != (domain.LimitesCreditos{Minimo: domain.LimitesCreditosPorDefecto.Minimo, Maximo: 12}) {
+		t.Fatalf("LimitesCreditosDesdeEntorno = %+v, %v; se esperaba el máximo 12", limites, err)
+	}
+
+	t.Setenv(EnvCreditosMinimo, "15")
+	if _, err := LimitesCreditosDesdeEntorno(); err == nil {
+		t.Fatal("se esperaba error con el mínimo por encima del máximo")
+	}
+	t.Setenv(EnvCreditosMinimo, "diez")
+	if _, err := LimitesCreditosDesdeEntorno(); err == nil {
+		t.Fatal("se esperaba error con un mínimo no numérico")
+	}
+}
+
+func TestInsertarNuevoRegistroRechazaSobrecarga(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+	svc.EstablecerLimitesCreditos(domain.LimitesCreditos{Minimo: 0, Maximo: 8})
+
+	if err := svc.InsertarNuevoRegistroConCreditos("1234567", "Lulú López", "1040", "Cálculo", 5); err != nil {
+		t.Fatalf("InsertarNuevoRegistroConCreditos: %v", err)
+	}
+	// La segunda materia dejaría al estudiante con 9 créditos: no se guarda ni la materia
+	err := svc.InsertarNuevoRegistroConCreditos("1234567", "Lulú López", "1050", "Física I", 4)
+	if !errors.Is(err, ErrCargaExcedida) {
+		t.Fatalf("InsertarNuevoRegistroConCreditos = %v, se esperaba ErrCargaExcedida", err)
+	}
+	if existe, _ := repos.Materias.Exists("1050"); existe {
+		t.Fatal("la materia quedó creada aunque la inscripción superaba el máximo")
+	}
+	if err := svc.InsertarNuevoRegistroConCreditos("1234567", "Lulú López", "1050", "Física I", 3); err != nil {
+		t.Fatalf("InsertarNuevoRegistroConCreditos hasta el máximo: %v", err)
+	}
+
+	if err := svc.InsertarNuevoRegistroConCreditos("1234567", "Lulú López", "1060", "Administración", 11); err == nil {
+		t.Fatal("se esperaba error con créditos por encima del máximo por materia")
+	}
+}
+
+func TestCambiarCreditosMateria(t *testing.T) {
+	_, repos := nuevaConsultasEnMemoria()
+	edicion := NewEdicionService(repos.Estudiantes, repos.Materias)
+	if err := repos.Materias.Create(domain.NewMateria("1040", "Cálculo")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	materia, err := edicion.ObtenerMateria("1040")
+	if err != nil {
+		t.Fatalf("ObtenerMateria: %v", err)
+	}
+	if err := edicion.CambiarCreditosMateria(materia, 0); err == nil {
+		t.Fatal("se esperaba error con cero créditos")
+	}
+	if err := edicion.CambiarCreditosMateria(materia, 4); err != nil {
+		t.Fatalf("CambiarCreditosMateria: %v", err)
+	}
+	if m, _ := repos.Materias.GetByCodigo("1040"); m.Creditos != 4 || m.Version != 2 {
+		t.Fatalf("materia tras cambiar créditos = %+v, se esperaban 4 créditos en la versión 2", m)
+	}
+}
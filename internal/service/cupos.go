@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// CuposService administra el cupo de las materias y las listas de espera del periodo.
+// Cuando se libera un cupo, el primero de la lista que pueda inscribirse pasa a estar inscrito.
+type CuposService struct {
+	repos   *repository.Repositorios
+	limites domain.LimitesCreditos
+}
+
+func NewCuposService(repos *repository.Repositorios) *CuposService {
+	return &CuposService{repos: repos, limites: domain.LimitesCreditosPorDefecto}
+}
+
+// EstablecerLimitesCreditos cambia la carga de créditos que se respeta al promover a alguien de la lista
+func (s *CuposService) EstablecerLimitesCreditos(limites domain.LimitesCreditos) {
+	s.limites = limites
+}
+
+// OcupacionMateria resume, para una materia, su cupo, los inscritos del periodo y cuántos esperan
+type OcupacionMateria struct {
+	Codigo    string
+	Nombre    string
+	Cupo      int
+	Inscritos int
+	EnEspera  int
+}
+
+// Disponibles retorna los cupos libres; sin límite de cupo retorna -1
+func (o OcupacionMateria) Disponibles() int {
+	if o.Cupo == domain.SinCupoLimite {
+		return -1
+	}
+	if o.Inscritos >= o.Cupo {
+		return 0
+	}
+	return o.Cupo - o.Inscritos
+}
+
+// EstablecerCupo cambia el cupo de la materia, domain.SinCupoLimite para quitar el límite.
+// Un cupo menor que los inscritos no cancela ninguna inscripción; uno mayor promueve a los
+// primeros de la lista de espera del periodo, cuyas cédulas se retornan.
+func (s *CuposService) EstablecerCupo(codigo string, cupo int) ([]string, error) {
+	if cupo < 0 {
+		return nil, fmt.Errorf("el cupo '%d' debe ser un número entero no negativo", cupo)
+	}
+	codigo = strings.TrimSpace(codigo)
+	var promovidos []string
+	err := s.repos.EnTransaccion(func(repos *repository.Repositorios) error {
+		materia, err := repos.Materias.GetByCodigo(codigo)
+		if err != nil {
+			return fmt.Errorf("error al obtener materia: %w", err)
+		}
+		if materia == nil {
+			return fmt.Errorf("%w: materia %s", repository.ErrNoEncontrado, codigo)
+		}
+		materia.Cupo = cupo
+		if err := repos.Materias.Update(materia); err != nil {
+			return fmt.Errorf("error al actualizar materia: %w", err)
+		}
+		promovidos, err = promoverEspera(repos, s.limites, codigo)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return promovidos, nil
+}
+
+// Ocupacion retorna la ocupación de cada materia en el periodo, ordenada por código
+func (s *CuposService) Ocupacion() ([]OcupacionMateria, error) {
+	materias, err := s.repos.Materias.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener materias: %w", err)
+	}
+	inscritos, err := s.repos.Inscripciones.CountGroupedByMateria()
+	if err != nil {
+		return nil, fmt.Errorf("error al contar inscritos: %w", err)
+	}
+	enEspera, err := s.repos.ListaEspera.CountGroupedByMateria()
+	if err != nil {
+		return nil, fmt.Errorf("error al contar listas de espera: %w", err)
+	}
+
+	ocupacion := make([]OcupacionMateria, 0, len(materias))
+	for _, m := range materias {
+		ocupacion = append(ocupacion, OcupacionMateria{
+			Codigo:    m.Codigo,
+			Nombre:    m.Nombre,
+			Cupo:      m.Cupo,
+			Inscritos: inscritos[m.Codigo],
+			EnEspera:  enEspera[m.Codigo],
+		})
+	}
+	return ocupacion, nil
+}
+
+// ListaDeEspera retorna, en orden, quienes esperan cupo en la materia durante el periodo
+func (s *CuposService) ListaDeEspera(codigo string) ([]*domain.Espera, error) {
+	lista, err := s.repos.ListaEspera.GetByMateria(strings.TrimSpace(codigo))
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener la lista de espera: %w", err)
+	}
+	return lista, nil
+}
+
+// PosicionEnEspera retorna el lugar del estudiante en la lista de la materia; 0 si no está en ella
+func (s *CuposService) PosicionEnEspera(cedula, codigo string) (int, error) {
+	posicion, err := s.repos.ListaEspera.Posicion(strings.TrimSpace(cedula), strings.TrimSpace(codigo))
+	if err != nil {
+		return 0, fmt.Errorf("error al consultar la lista de espera: %w", err)
+	}
+	return posicion, nil
+}
+
+// SalirDeEspera saca al estudiante de la lista de espera de la materia
+func (s *CuposService) SalirDeEspera(cedula, codigo string) error {
+	if err := s.repos.ListaEspera.Quitar(strings.TrimSpace(cedula), strings.TrimSpace(codigo)); err != nil {
+		return fmt.Errorf("error al retirar de la lista de espera: %w", err)
+	}
+	return nil
+}
+
+// promoverEspera inscribe, en orden, a los estudiantes de la lista de espera de la materia
+// en el grupo que pidieron mientras haya cupo, dentro de la unidad de trabajo de repos, y
+// retorna sus cédulas. Quien superaría el máximo de créditos o cruzaría su horario conserva
+// su lugar y se sigue con el siguiente; quien ya no puede inscribirse porque la inscripción existe o algo fue eliminado
+// sale de la lista.
+func promoverEspera(repos *repository.Repositorios, limites domain.LimitesCreditos, codigo string) ([]string, error) {
+	materia, err := repos.Materias.GetByCodigo(codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener materia: %w", err)
+	}
+	if materia == nil {
+		return nil, nil
+	}
+	lista, err := repos.ListaEspera.GetByMateria(codigo)
+	if err != nil || len(lista) == 0 {
+		return nil, err
+	}
+	cargas, err := repos.Inscripciones.CreditosGroupedByEstudiante()
+	if err != nil {
+		return nil, fmt.Errorf("error al calcular la carga de créditos: %w", err)
+	}
+
+	var promovidos []string
+	for _, espera := range lista {
+		cedula := espera.Estudiante.Cedula
+		if limites.Excede(cargas[cedula] + materia.Creditos) {
+			continue
+		}
+		cruce, err := buscarCruce(repos, cedula, codigo, espera.Grupo)
+		if err != nil {
+			return nil, err
+		}
+		if cruce != nil {
+			continue
+		}
+		errCrear := repos.Inscripciones.CreateEnGrupo(cedula, codigo, espera.Grupo)
+		if errors.Is(errCrear, repository.ErrCupoAgotado) {
+			break
+		}
+		descartada := errors.Is(errCrear, repository.ErrDuplicado) || errors.Is(errCrear, repository.ErrEliminado) ||
+			errors.Is(errCrear, repository.ErrReferenciaInvalida)
+		if errCrear != nil && !descartada {
+			return nil, fmt.Errorf("error al inscribir a %s desde la lista de espera: %w", cedula, errCrear)
+		}
+		if err := repos.ListaEspera.Quitar(cedula, codigo); err != nil {
+			return nil, err
+		}
+		if descartada {
+			continue
+		}
+		if err := publicar(repos, domain.InscripcionCreada{Cedula: cedula, Codigo: codigo, Periodo: repos.Periodo()}); err != nil {
+			return nil, err
+		}
+		promovidos = append(promovidos, cedula)
+	}
+	return promovidos, nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func TestInsertarNuevoRegistroSinCupoPasaALaListaDeEspera(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+	cupos := NewCuposService(repos)
+	eliminacion := NewEliminacionService(repos, repos.Estudiantes, repos.Materias, repos.Inscripciones)
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	if _, err := cupos.EstablecerCupo("1040", 1); err != nil {
+		t.Fatalf("EstablecerCupo: %v", err)
+	}
+
+	// Sin cupo el registro no falla: el estudiante, creado, queda esperando y el resultado lo dice
+	for i, e := range [][2]string{{"9876534", "Pepito Pérez"}, {"1111111", "Ana García"}} {
+		resultado, err := svc.InsertarNuevoRegistroEnGrupo(e[0], e[1], "1040", "Cálculo", domain.CreditosPorDefecto, domain.SinGrupo)
+		if err != nil {
+			t.Fatalf("InsertarNuevoRegistroEnGrupo sin cupo: %v", err)
+		}
+		if resultado != (ResultadoInscripcion{EnEspera: true, Posicion: i + 1}) {
+			t.Fatalf("resultado sin cupo = %+v, se esperaba la posición %d en espera", resultado, i+1)
+		}
+	}
+	if existe, _ := repos.Estudiantes.Exists("1111111"); !existe {
+		t.Fatal("el estudiante que quedó en espera no se creó")
+	}
+	if existe, _ := repos.Inscripciones.Exists("9876534", "1040"); existe {
+		t.Fatal("se inscribió a Pepito por encima del cupo")
+	}
+	if posicion, _ := cupos.PosicionEnEspera("1111111", "1040"); posicion != 2 {
+		t.Fatalf("PosicionEnEspera = %d, se esperaba 2", posicion)
+	}
+	if err := svc.InsertarNuevoRegistro("9876534", "Pepito Pérez", "1040", "Cálculo"); !errors.Is(err, repository.ErrDuplicado) {
+		t.Fatalf("InsertarNuevoRegistro estando en espera = %v, se esperaba ErrDuplicado", err)
+	}
+
+	// Cancelar una inscripción promueve al primero de la lista
+	promovidos, err := eliminacion.EliminarInscripcion("1234567", "1040")
+	if err != nil {
+		t.Fatalf("EliminarInscripcion: %v", err)
+	}
+	if !reflect.DeepEqual(promovidos, []string{"9876534"}) {
+		t.Fatalf("promovidos = %v, se esperaba a Pepito", promovidos)
+	}
+	if existe, _ := repos.Inscripciones.Exists("9876534", "1040"); !existe {
+		t.Fatal("Pepito no quedó inscrito al liberarse el cupo")
+	}
+	if posicion, _ := cupos.PosicionEnEspera("1111111", "1040"); posicion != 1 {
+		t.Fatalf("PosicionEnEspera tras la promoción = %d, se esperaba 1", posicion)
+	}
+
+	// La inscripción cancelada no puede restaurarse mientras no haya cupo
+	if err := eliminacion.RestaurarInscripcion("1234567", "1040"); !errors.Is(err, repository.ErrCupoAgotado) {
+		t.Fatalf("RestaurarInscripcion sin cupo = %v, se esperaba ErrCupoAgotado", err)
+	}
+}
+
+func TestListaDeEsperaConservaElGrupo(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+	cupos := NewCuposService(repos)
+	eliminacion := NewEliminacionService(repos, repos.Estudiantes, repos.Materias, repos.Inscripciones)
+	horarios := NewHorariosService(repos)
+	if _, err := svc.InsertarNuevoRegistroEnGrupo("1234567", "Lulú López", "1040", "Cálculo", 4, 1); err != nil {
+		t.Fatalf("InsertarNuevoRegistroEnGrupo: %v", err)
+	}
+	if err := svc.InsertarNuevoRegistro("1111111", "Ana García", "1050", "Física I"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	if _, err := cupos.EstablecerCupo("1040", 1); err != nil {
+		t.Fatalf("EstablecerCupo: %v", err)
+	}
+	if err := NewGruposService(repos).CrearGrupo("1040", 2); err != nil {
+		t.Fatalf("CrearGrupo: %v", err)
+	}
+	if err := horarios.AgregarSesion("1040", 2, "lunes", "08:00", "10:00", "A-101"); err != nil {
+		t.Fatalf("AgregarSesion: %v", err)
+	}
+	if err := horarios.AgregarSesion("1050", domain.SinGrupo, "lunes", "09:00", "11:00", "B-202"); err != nil {
+		t.Fatalf("AgregarSesion: %v", err)
+	}
+
+	// El horario que se revisa antes de esperar es el del grupo pedido
+	if _, err := svc.InsertarNuevoRegistroEnGrupo("1111111", "Ana García", "1040", "Cálculo", 4, 2); !errors.Is(err, ErrCruceHorario) {
+		t.Fatalf("InsertarNuevoRegistroEnGrupo sin cupo y con cruce = %v, se esperaba ErrCruceHorario", err)
+	}
+	resultado, err := svc.InsertarNuevoRegistroEnGrupo("9876534", "Pepito Pérez", "1040", "Cálculo", 4, 2)
+	if err != nil || resultado != (ResultadoInscripcion{EnEspera: true, Posicion: 1}) {
+		t.Fatalf("InsertarNuevoRegistroEnGrupo sin cupo = %+v, %v; se esperaba la posición 1 en espera", resultado, err)
+	}
+	if lista, _ := cupos.ListaDeEspera("1040"); len(lista) != 1 || lista[0].Grupo != 2 {
+		t.Fatalf("ListaDeEspera = %+v, se esperaba a Pepito esperando el grupo 2", lista)
+	}
+
+	// Al liberarse el cupo, Pepito entra en el grupo que pidió
+	promovidos, err := eliminacion.EliminarInscripcion("1234567", "1040")
+	if err != nil || !reflect.DeepEqual(promovidos, []string{"9876534"}) {
+		t.Fatalf("EliminarInscripcion = %v, %v; se esperaba promover a Pepito", promovidos, err)
+	}
+	if inscritos, _ := repos.Inscripciones.GetByGrupo("1040", 2); len(inscritos) != 1 || inscritos[0].Cedula != "9876534" {
+		t.Fatalf("inscritos en el grupo 2 = %+v, se esperaba a Pepito", inscritos)
+	}
+}
+
+func TestListaDeEsperaRespetaLaCargaDeCreditos(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+	svc.EstablecerLimitesCreditos(domain.LimitesCreditos{Minimo: 0, Maximo: 8})
+	cupos := NewCuposService(repos)
+	if err := svc.InsertarNuevoRegistroConCreditos("1234567", "Lulú López", "1040", "Cálculo", 5); err != nil {
+		t.Fatalf("InsertarNuevoRegistroConCreditos: %v", err)
+	}
+	if err := svc.InsertarNuevoRegistroConCreditos("9876534", "Pepito Pérez", "1050", "Física I", 4); err != nil {
+		t.Fatalf("InsertarNuevoRegistroConCreditos: %v", err)
+	}
+	if _, err := cupos.EstablecerCupo("1050", 1); err != nil {
+		t.Fatalf("EstablecerCupo: %v", err)
+	}
+
+	// Con Física Lulú tendría 9 créditos: no entra a la lista de espera, donde nunca recibiría el cupo
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1050", "Física I"); !errors.Is(err, ErrCargaExcedida) {
+		t.Fatalf("InsertarNuevoRegistro sin cupo y con sobrecarga = %v, se esperaba ErrCargaExcedida", err)
+	}
+	if posicion, _ := cupos.PosicionEnEspera("1234567", "1050"); posicion != 0 {
+		t.Fatalf("PosicionEnEspera = %d, no se esperaba a Lulú en la lista", posicion)
+	}
+	if err := svc.InsertarNuevoRegistro("1111111", "Ana García", "1050", "Física I"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro sin cupo: %v", err)
+	}
+	if posicion, _ := cupos.PosicionEnEspera("1111111", "1050"); posicion != 1 {
+		t.Fatalf("PosicionEnEspera = %d, se esperaba a Ana de primera", posicion)
+	}
+}
+
+func TestPromocionRespetaLaCargaDeCreditos(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	cupos := NewCuposService(repos)
+	limites := domain.LimitesCreditos{Minimo: 0, Maximo: 5}
+	cupos.EstablecerLimitesCreditos(limites)
+	for _, e := range [][2]string{{"1234567", "Lulú López"}, {"9876534", "Pepito Pérez"}, {"1111111", "Ana García"}} {
+		if err := repos.Estudiantes.Create(domain.NewEstudiante(e[0], e[1])); err != nil {
+			t.Fatalf("Create estudiante: %v", err)
+		}
+	}
+	calculo := domain.NewMateria("1040", "Cálculo")
+	calculo.Creditos, calculo.Cupo = 4, 1
+	for _, m := range []*domain.Materia{calculo, domain.NewMateria("1050", "Física I")} {
+		if err := repos.Materias.Create(m); err != nil {
+			t.Fatalf("Create materia: %v", err)
+		}
+	}
+	for _, i := range [][2]string{{"1234567", "1040"}, {"9876534", "1050"}} {
+		if err := repos.Inscripciones.Create(i[0], i[1]); err != nil {
+			t.Fatalf("Create inscripción: %v", err)
+		}
+	}
+	for _, cedula := range []string{"9876534", "1111111"} {
+		if _, err := repos.ListaEspera.Agregar(cedula, "1040", domain.SinGrupo); err != nil {
+			t.Fatalf("Agregar: %v", err)
+		}
+	}
+
+	// Con dos cupos entra Ana: Pepito tendría 7 créditos y conserva su lugar
+	promovidos, err := cupos.EstablecerCupo("1040", 2)
+	if err != nil {
+		t.Fatalf("EstablecerCupo: %v", err)
+	}
+	if !reflect.DeepEqual(promovidos, []string{"1111111"}) {
+		t.Fatalf("promovidos = %v, se esperaba a Ana", promovidos)
+	}
+	lista, _ := cupos.ListaDeEspera("1040")
+	if len(lista) != 1 || lista[0].Estudiante.Cedula != "9876534" || lista[0].Posicion != 1 {
+		t.Fatalf("ListaDeEspera = %+v, se esperaba a Pepito primero", lista)
+	}
+
+	ocupacion, err := cupos.Ocupacion()
+	if err != nil {
+		t.Fatalf("Ocupacion: %v", err)
+	}
+	esperada := []OcupacionMateria{
+		{Codigo: "1040", Nombre: "Cálculo", Cupo: 2, Inscritos: 2, EnEspera: 1},
+		{Codigo: "1050", Nombre: "Física I", Cupo: domain.SinCupoLimite, Inscritos: 1},
+	}
+	if !reflect.DeepEqual(ocupacion, esperada) {
+		t.Fatalf("Ocupacion = %+v, se esperaba %+v", ocupacion, esperada)
+	}
+	if ocupacion[0].Disponibles() != 0 || ocupacion[1].Disponibles() != -1 {
+		t.Fatalf("Disponibles = %d y %d, se esperaban 0 y -1", ocupacion[0].Disponibles(), ocupacion[1].Disponibles())
+	}
+
+	if err := cupos.SalirDeEspera("9876534", "1040"); err != nil {
+		t.Fatalf("SalirDeEspera: %v", err)
+	}
+	if _, err := cupos.EstablecerCupo("1040", -1); err == nil {
+		t.Fatal("se esperaba error con un cupo negativo")
+	}
+}
+
+func TestProcesarArchivoSinCupoPasaALaListaDeEspera(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+	calculo := domain.NewMateria("1040", "Cálculo")
+	calculo.Cupo = 2
+	if err := repos.Materias.Create(calculo); err != nil {
+		t.Fatalf("Create materia: %v", err)
+	}
+
+	// El archivo inscribe a cuatro estudiantes en Cálculo; los dos últimos quedan esperando
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_validas.txt"); err != nil {
+		t.Fatalf("ProcesarArchivo: %v", err)
+	}
+	if inscritos, _ := repos.Inscripciones.GetByMateria("1040"); len(inscritos) != 2 {
+		t.Fatalf("inscritos en 1040 = %d, se esperaba el cupo de 2", len(inscritos))
+	}
+	lista, _ := repos.ListaEspera.GetByMateria("1040")
+	if len(lista) != 2 || lista[0].Estudiante.Cedula != "1111111" || lista[1].Estudiante.Cedula != "4444444" {
+		t.Fatalf("lista de espera de 1040 = %d estudiantes, se esperaban Ana y Juan en orden de llegada", len(lista))
+	}
+
+	// Volver a cargar el archivo no los duplica en la lista
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_validas.txt"); err != nil {
+		t.Fatalf("ProcesarArchivo repetido: %v", err)
+	}
+	if lista, _ := repos.ListaEspera.GetByMateria("1040"); len(lista) != 2 {
+		t.Fatalf("lista de espera tras recargar = %d, se esperaban 2", len(lista))
+	}
+}
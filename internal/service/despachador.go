@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+	"sync"
+	"time"
+)
+
+// Suscriptor recibe los eventos de un tipo. La entrega es al menos una vez: si un
+// suscriptor falla, el evento se reintenta para todos los del tipo, así que cada uno
+// debe tolerar recibir el mismo evento (mismo ID) más de una vez.
+type Suscriptor func(evento *domain.Evento) error
+
+// Valores por defecto del despachador
+const (
+	eventosPorLote        = 100
+	esperaPrimerReintento = time.Second
+	esperaMaxima          = 5 * time.Minute
+)
+
+// Despachador entrega a los suscriptores registrados los eventos pendientes del outbox.
+// Un evento se marca entregado solo cuando todos sus suscriptores lo procesaron sin
+// error; si alguno falla, se reintenta con una espera que se duplica en cada intento.
+type Despachador struct {
+	eventos repository.EventoRepository
+
+	mu           sync.RWMutex
+	suscriptores map[string][]Suscriptor
+	// reloj permite fijar la hora en las pruebas
+	reloj func() time.Time
+}
+
+func NewDespachador(eventos repository.EventoRepository) *Despachador {
+	return &Despachador{
+		eventos:      eventos,
+		suscriptores: make(map[string][]Suscriptor),
+		reloj:        time.Now,
+	}
+}
+
+// Suscribir registra un suscriptor para los eventos del tipo indicado
+func (d *Despachador) Suscribir(tipo string, suscriptor Suscriptor) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.suscriptores[tipo] = append(d.suscriptores[tipo], suscriptor)
+}
+
+// Despachar hace una pasada sobre los eventos pendientes y retorna cuántos entregó.
+// Los eventos de tipos sin suscriptores se dan por entregados.
+func (d *Despachador) Despachar() (int, error) {
+	pendientes, err := d.eventos.Pendientes(eventosPorLote)
+	if err != nil {
+		return 0, fmt.Errorf("error al leer eventos pendientes: %w", err)
+	}
+
+	entregados := 0
+	for _, evento := range pendientes {
+		if errEntrega := d.entregar(evento); errEntrega != nil {
+			proximo := d.reloj().Add(esperaReintento(evento.Intentos + 1))
+			if err := d.eventos.MarcarFallido(evento.ID, proximo, errEntrega.Error()); err != nil {
+				return entregados, fmt.Errorf("error al registrar la falla del evento %d: %w", evento.ID, err)
+			}
+			continue
+		}
+		if err := d.eventos.MarcarEntregado(evento.ID); err != nil {
+			return entregados, fmt.Errorf("error al marcar entregado el evento %d: %w", evento.ID, err)
+		}
+		entregados++
+	}
+	return entregados, nil
+}
+
+// Iniciar despacha periódicamente hasta que se cancele el contexto. Los errores de una
+// pasada se informan a alError y no detienen las siguientes.
+func (d *Despachador) Iniciar(ctx context.Context, intervalo time.Duration, alError func(error)) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Despachar(); err != nil && alError != nil {
+			alError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Despachador) entregar(evento *domain.Evento) error {
+	d.mu.RLock()
+	suscriptores := d.suscriptores[evento.Tipo]
+	d.mu.RUnlock()
+
+	for _, suscriptor := range suscriptores {
+		if err := llamarSuscriptor(suscriptor, evento); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// llamarSuscriptor convierte el pánico de un suscriptor en un error, para que se reintente
+// como cualquier otra falla en lugar de detener el despachador
+func llamarSuscriptor(suscriptor Suscriptor, evento *domain.Evento) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("pánico en el suscriptor de %s: %v", evento.Tipo, r)
+		}
+	}()
+	copia := *evento
+	return suscriptor(&copia)
+}
+
+// esperaReintento duplica la espera en cada intento, hasta esperaMaxima
+func esperaReintento(intentos int) time.Duration {
+	espera := esperaPrimerReintento
+	for i := 1; i < intentos && espera < esperaMaxima; i++ {
+		espera *= 2
+	}
+	if espera > esperaMaxima {
+		return esperaMaxima
+	}
+	return espera
+}
+
+// publicar guarda los eventos en el outbox de la unidad de trabajo en curso
+func publicar(repos *repository.Repositorios, eventos ...domain.DatosEvento) error {
+	for _, datos := range eventos {
+		if err := repos.Eventos.Publicar(domain.NewEvento(datos)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func TestServiciosPublicanEventos(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+	eliminacion := NewEliminacionService(repos, repos.Estudiantes, repos.Materias, repos.Inscripciones)
+
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	// Un registro que falla no deja eventos
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err == nil {
+		t.Fatal("se esperaba error al repetir la inscripción")
+	}
+	if _, err := eliminacion.EliminarInscripcion("1234567", "1040"); err != nil {
+		t.Fatalf("EliminarInscripcion: %v", err)
+	}
+
+	pendientes, err := repos.Eventos.Pendientes(10)
+	if err != nil {
+		t.Fatalf("Pendientes: %v", err)
+	}
+	var tipos []string
+	for _, e := range pendientes {
+		tipos = append(tipos, e.Tipo)
+	}
+	esperados := []string{
+		domain.EventoEstudianteCreado,
+		domain.EventoMateriaCreada,
+		domain.EventoInscripcionCreada,
+		domain.EventoInscripcionCancelada,
+	}
+	if !reflect.DeepEqual(tipos, esperados) {
+		t.Fatalf("eventos = %v, se esperaban %v", tipos, esperados)
+	}
+
+	var cancelada domain.InscripcionCancelada
+	if err := pendientes[3].Decodificar(&cancelada); err != nil || cancelada != (domain.InscripcionCancelada{Cedula: "1234567", Codigo: "1040", Periodo: repos.Periodo()}) {
+		t.Fatalf("InscripcionCancelada = %+v, %v", cancelada, err)
+	}
+}
+
+func TestProcesarArchivoPublicaEventos(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_validas.txt"); err != nil {
+		t.Fatalf("ProcesarArchivo: %v", err)
+	}
+
+	estudiantes, _ := repos.Estudiantes.GetAll()
+	materias, _ := repos.Materias.GetAll()
+	inscripciones, _ := repos.Inscripciones.GetAll()
+	pendientes, _ := repos.Eventos.Pendientes(1000)
+	if esperados := len(estudiantes) + len(materias) + len(inscripciones); len(pendientes) != esperados {
+		t.Fatalf("se publicaron %d eventos, se esperaban %d", len(pendientes), esperados)
+	}
+}
+
+func TestDespachadorEntregaYReintenta(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	for _, datos := range []domain.DatosEvento{
+		domain.EstudianteCreado{Cedula: "1234567", Nombre: "Lulú López"},
+		domain.InscripcionCreada{Cedula: "1234567", Codigo: "1040"},
+		domain.MateriaCreada{Codigo: "1040", Nombre: "Cálculo"},
+	} {
+		if err := repos.Eventos.Publicar(domain.NewEvento(datos)); err != nil {
+			t.Fatalf("Publicar: %v", err)
+		}
+	}
+
+	despachador := NewDespachador(repos.Eventos)
+	// Los reintentos se programan en el pasado para no esperar en la prueba
+	despachador.reloj = func() time.Time { return time.Now().Add(-time.Hour) }
+
+	var recibidos []string
+	despachador.Suscribir(domain.EventoEstudianteCreado, func(e *domain.Evento) error {
+		recibidos = append(recibidos, e.Tipo)
+		return nil
+	})
+	llamadas := 0
+	despachador.Suscribir(domain.EventoInscripcionCreada, func(e *domain.Evento) error {
+		llamadas++
+		switch llamadas {
+		case 1:
+			return errors.New("servicio externo no disponible")
+		case 2:
+			panic("fallo inesperado")
+		}
+		recibidos = append(recibidos, fmt.Sprintf("%s tras %d intentos", e.Tipo, e.Intentos))
+		return nil
+	})
+
+	// Primera pasada: la materia no tiene suscriptores y se da por entregada
+	entregados, err := despachador.Despachar()
+	if err != nil || entregados != 2 {
+		t.Fatalf("Despachar = %d, %v; se esperaban 2 entregados", entregados, err)
+	}
+	// El error y el pánico se reintentan hasta que el suscriptor responde bien
+	for i := 0; i < 2; i++ {
+		if _, err := despachador.Despachar(); err != nil {
+			t.Fatalf("Despachar: %v", err)
+		}
+	}
+
+	esperados := []string{domain.EventoEstudianteCreado, domain.EventoInscripcionCreada + " tras 2 intentos"}
+	if !reflect.DeepEqual(recibidos, esperados) {
+		t.Fatalf("recibidos = %v, se esperaban %v", recibidos, esperados)
+	}
+	if pendientes, _ := repos.Eventos.Pendientes(10); len(pendientes) != 0 {
+		t.Fatalf("quedaron %d eventos pendientes", len(pendientes))
+	}
+}
+
+func TestDespachadorRespetaLaEspera(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	if err := repos.Eventos.Publicar(domain.NewEvento(domain.MateriaCreada{Codigo: "1040"})); err != nil {
+		t.Fatalf("Publicar: %v", err)
+	}
+
+	despachador := NewDespachador(repos.Eventos)
+	llamadas := 0
+	despachador.Suscribir(domain.EventoMateriaCreada, func(*domain.Evento) error {
+		llamadas++
+		return errors.New("no disponible")
+	})
+
+	despachador.Despachar()
+	despachador.Despachar()
+	if llamadas != 1 {
+		t.Fatalf("el suscriptor se llamó %d veces antes de vencer la espera, se esperaba 1", llamadas)
+	}
+}
+
+func TestEsperaReintento(t *testing.T) {
+	casos := map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		4:  8 * time.Second,
+		20: 5 * time.Minute,
+	}
+	for intentos, esperada := range casos {
+		if espera := esperaReintento(intentos); espera != esperada {
+			t.Errorf("esperaReintento(%d) = %v, se esperaba %v", intentos, espera, esperada)
+		}
+	}
+}
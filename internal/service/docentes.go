@@ -0,0 +1,177 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// DocentesService administra los docentes de la facultad y las materias que dicta cada
+// uno en el periodo de los repositorios
+type DocentesService struct {
+	repos *repository.Repositorios
+}
+
+func NewDocentesService(repos *repository.Repositorios) *DocentesService {
+	return &DocentesService{repos: repos}
+}
+
+// CrearDocente registra al docente en la facultad
+func (s *DocentesService) CrearDocente(cedula, nombre string) error {
+	docente, err := nuevoDocente(cedula, nombre)
+	if err != nil {
+		return err
+	}
+	if err := s.repos.Docentes.Create(docente); err != nil {
+		return fmt.Errorf("error al crear docente: %w", err)
+	}
+	return nil
+}
+
+// ActualizarDocente cambia el nombre del docente
+func (s *DocentesService) ActualizarDocente(cedula, nombre string) error {
+	docente, err := nuevoDocente(cedula, nombre)
+	if err != nil {
+		return err
+	}
+	if err := s.repos.Docentes.Update(docente); err != nil {
+		return fmt.Errorf("error al actualizar docente: %w", err)
+	}
+	return nil
+}
+
+// EliminarDocente quita al docente; falla con repository.ErrDocenteConAsignaciones mientras
+// dicte alguna materia en cualquier periodo
+func (s *DocentesService) EliminarDocente(cedula string) error {
+	if err := s.repos.Docentes.Delete(strings.TrimSpace(cedula)); err != nil {
+		return fmt.Errorf("error al eliminar docente: %w", err)
+	}
+	return nil
+}
+
+// ListarDocentes retorna los docentes ordenados por cédula
+func (s *DocentesService) ListarDocentes() ([]*domain.Docente, error) {
+	docentes, err := s.repos.Docentes.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener docentes: %w", err)
+	}
+	return docentes, nil
+}
+
+// AsignarMateria pone al docente a dictar el grupo de la materia, o la materia completa con
+// domain.SinGrupo; si ya tenía docente, lo reemplaza
+func (s *DocentesService) AsignarMateria(cedula, materia string, grupo int) error {
+	if grupo < domain.SinGrupo {
+		return fmt.Errorf("el grupo %d no es válido", grupo)
+	}
+	if err := s.repos.Docentes.Asignar(strings.TrimSpace(cedula), strings.TrimSpace(materia), grupo); err != nil {
+		return fmt.Errorf("error al asignar docente: %w", err)
+	}
+	return nil
+}
+
+// QuitarAsignacion deja al grupo de la materia, o a la materia completa, sin docente
+func (s *DocentesService) QuitarAsignacion(materia string, grupo int) error {
+	if err := s.repos.Docentes.Desasignar(strings.TrimSpace(materia), grupo); err != nil {
+		return fmt.Errorf("error al quitar docente: %w", err)
+	}
+	return nil
+}
+
+// EstudiantesDeDocente retorna los estudiantes inscritos en las materias y grupos que dicta
+// el docente, una sola vez cada uno y ordenados por cédula
+func (s *DocentesService) EstudiantesDeDocente(cedula string) ([]*domain.Estudiante, error) {
+	estudiantes, err := s.repos.Docentes.GetEstudiantes(strings.TrimSpace(cedula))
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener los estudiantes del docente: %w", err)
+	}
+	return estudiantes, nil
+}
+
+// MateriaDictada es una materia, o uno de sus grupos, que dicta el docente, con sus inscritos
+type MateriaDictada struct {
+	Asignacion domain.AsignacionDocente
+	Materia    *domain.Materia
+	Inscritos  int
+}
+
+// CargaDocente son las materias que dicta un docente en el periodo. TotalInscritos suma
+// los inscritos de cada una, así que un estudiante que toma dos de ellas cuenta dos veces.
+type CargaDocente struct {
+	Docente        *domain.Docente
+	Materias       []MateriaDictada
+	TotalInscritos int
+}
+
+// MateriasDeDocente retorna la carga del docente ordenada por materia y grupo; nil si el
+// docente no existe
+func (s *DocentesService) MateriasDeDocente(cedula string) (*CargaDocente, error) {
+	docente, err := s.repos.Docentes.GetByCedula(strings.TrimSpace(cedula))
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar docente: %w", err)
+	}
+	if docente == nil {
+		return nil, nil
+	}
+	return s.cargaDe(docente)
+}
+
+// CargaDocentes retorna la carga de cada docente de la facultad, incluso de los que no
+// dictan nada en el periodo, ordenada por cédula
+func (s *DocentesService) CargaDocentes() ([]*CargaDocente, error) {
+	docentes, err := s.ListarDocentes()
+	if err != nil {
+		return nil, err
+	}
+	cargas := make([]*CargaDocente, 0, len(docentes))
+	for _, docente := range docentes {
+		carga, err := s.cargaDe(docente)
+		if err != nil {
+			return nil, err
+		}
+		cargas = append(cargas, carga)
+	}
+	return cargas, nil
+}
+
+// cargaDe cuenta los inscritos de cada materia que dicta el docente: los de toda la
+// materia si la dicta completa y los del grupo si no
+func (s *DocentesService) cargaDe(docente *domain.Docente) (*CargaDocente, error) {
+	asignaciones, err := s.repos.Docentes.GetByDocente(docente.Cedula)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener las materias del docente: %w", err)
+	}
+	carga := &CargaDocente{Docente: docente, Materias: make([]MateriaDictada, 0, len(asignaciones))}
+	for _, a := range asignaciones {
+		materia, err := s.repos.Materias.GetByCodigo(a.Materia)
+		if err != nil {
+			return nil, fmt.Errorf("error al buscar materia: %w", err)
+		}
+		var inscritos []*domain.Estudiante
+		if a.Grupo == domain.SinGrupo {
+			inscritos, err = s.repos.Inscripciones.GetByMateria(a.Materia)
+		} else {
+			inscritos, err = s.repos.Inscripciones.GetByGrupo(a.Materia, a.Grupo)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error al obtener los inscritos de %s: %w", a.Materia, err)
+		}
+		carga.Materias = append(carga.Materias, MateriaDictada{Asignacion: a, Materia: materia, Inscritos: len(inscritos)})
+		carga.TotalInscritos += len(inscritos)
+	}
+	return carga, nil
+}
+
+// nuevoDocente valida los datos de un docente escritos en la consola o en un archivo
+func nuevoDocente(cedula, nombre string) (*domain.Docente, error) {
+	docente := domain.NewDocente(strings.TrimSpace(cedula), strings.TrimSpace(nombre))
+	if len(docente.Cedula) < 6 || len(docente.Cedula) > 12 {
+		return nil, fmt.Errorf("cédula '%s' debe tener entre 6 y 12 caracteres", docente.Cedula)
+	}
+	if len(docente.Nombre) < 2 {
+		return nil, fmt.Errorf("el nombre del docente '%s' debe tener al menos 2 caracteres", docente.Nombre)
+	}
+	return docente, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+	"inscripciones/pkg/fileutil"
+)
+
+func TestCargaDocentes(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_grupos.txt"); err != nil {
+		t.Fatalf("ProcesarArchivo: %v", err)
+	}
+	docentes := NewDocentesService(repos)
+
+	// La línea del grupo inexistente y la de la materia inexistente se omiten, aunque el
+	// docente de esta última queda registrado; las mal escritas no registran nada
+	archivo := NewProcesadorDocentes(&fileutil.LectorArchivoTexto{}, repos)
+	registradas, err := archivo.ProcesarArchivo("../../testdata/docentes.txt")
+	if err != nil {
+		t.Fatalf("ProcesarArchivo de docentes: %v", err)
+	}
+	if registradas != 3 {
+		t.Fatalf("registradas = %d, se esperaban 3", registradas)
+	}
+
+	// Ramiro dicta el grupo 1 de Cálculo, con Lulú y Ana, y Física completa, con Juan
+	carga, err := docentes.MateriasDeDocente(" 7654321 ")
+	if err != nil {
+		t.Fatalf("MateriasDeDocente: %v", err)
+	}
+	if len(carga.Materias) != 2 || carga.Materias[0].Asignacion.Grupo != 1 || carga.Materias[0].Inscritos != 2 ||
+		carga.Materias[1].Materia.Nombre != "Física I" || carga.Materias[1].Inscritos != 1 || carga.TotalInscritos != 3 {
+		t.Fatalf("carga de Ramiro = %+v", carga)
+	}
+	if carga, _ := docentes.MateriasDeDocente("0000000"); carga != nil {
+		t.Fatalf("MateriasDeDocente de un docente inexistente = %+v, se esperaba nil", carga)
+	}
+
+	cargas, err := docentes.CargaDocentes()
+	if err != nil {
+		t.Fatalf("CargaDocentes: %v", err)
+	}
+	if len(cargas) != 3 || cargas[1].Docente.Cedula != "8765432" || cargas[1].TotalInscritos != 1 ||
+		cargas[2].Docente.Nombre != "Pedro Páez" || len(cargas[2].Materias) != 0 {
+		t.Fatalf("CargaDocentes = %+v, se esperaban Ramiro, Sofía con Pepito y Pedro sin materias", cargas)
+	}
+
+	// Sofía pasa a dictar Cálculo completa y tiene a todos sus inscritos
+	if err := docentes.QuitarAsignacion("1040", 2); err != nil {
+		t.Fatalf("QuitarAsignacion: %v", err)
+	}
+	if err := docentes.AsignarMateria("8765432", "1040", domain.SinGrupo); err != nil {
+		t.Fatalf("AsignarMateria: %v", err)
+	}
+	estudiantes, err := docentes.EstudiantesDeDocente("8765432")
+	if err != nil {
+		t.Fatalf("EstudiantesDeDocente: %v", err)
+	}
+	if len(estudiantes) != 4 {
+		t.Fatalf("estudiantes de Sofía = %d, se esperaban los 4 de Cálculo", len(estudiantes))
+	}
+	if err := docentes.AsignarMateria("8765432", "1040", -1); err == nil {
+		t.Fatal("AsignarMateria con un grupo negativo: se esperaba error")
+	}
+
+	if err := docentes.EliminarDocente("8765432"); !errors.Is(err, repository.ErrDocenteConAsignaciones) {
+		t.Fatalf("EliminarDocente con asignaciones = %v, se esperaba ErrDocenteConAsignaciones", err)
+	}
+	if err := docentes.EliminarDocente("9999999"); err != nil {
+		t.Fatalf("EliminarDocente: %v", err)
+	}
+
+	// Las asignaciones de otro periodo no cambian las del periodo de la sesión
+	if registradas, err := archivo.ProcesarArchivoEnPeriodo("../../testdata/docentes.txt", "2020-2"); err != nil || registradas != 3 {
+		t.Fatalf("ProcesarArchivoEnPeriodo = %d, %v; se esperaban 3", registradas, err)
+	}
+	otro, _ := repos.EnPeriodo("2020-2")
+	if carga, _ := NewDocentesService(otro).MateriasDeDocente("8765432"); len(carga.Materias) != 1 || carga.Materias[0].Asignacion.Grupo != 2 {
+		t.Fatalf("carga de Sofía en 2020-2 = %+v, se esperaba el grupo 2 de Cálculo", carga)
+	}
+	if carga, _ := docentes.MateriasDeDocente("8765432"); len(carga.Materias) != 1 || carga.Materias[0].Asignacion.Grupo != domain.SinGrupo {
+		t.Fatalf("carga de Sofía = %+v, se esperaba Cálculo completa", carga)
+	}
+	if _, err := archivo.ProcesarArchivo("../../testdata/calificaciones.txt"); err == nil {
+		t.Fatal("se esperaba error con un archivo sin líneas de docentes válidas")
+	}
+}
+
+func TestValidarDocente(t *testing.T) {
+	docentes := NewDocentesService(repository.NewRepositoriosEnMemoria())
+	if err := docentes.CrearDocente("123", "Ramiro Ruiz"); err == nil {
+		t.Fatal("CrearDocente con cédula corta: se esperaba error")
+	}
+	if err := docentes.CrearDocente("7654321", " R "); err == nil {
+		t.Fatal("CrearDocente con nombre corto: se esperaba error")
+	}
+	if err := docentes.CrearDocente(" 7654321 ", " Ramiro Ruiz "); err != nil {
+		t.Fatalf("CrearDocente: %v", err)
+	}
+	if err := docentes.CrearDocente("7654321", "Otro"); !errors.Is(err, repository.ErrDuplicado) {
+		t.Fatalf("CrearDocente repetido = %v, se esperaba ErrDuplicado", err)
+	}
+	if err := docentes.ActualizarDocente("7654321", "Ramiro Ruiz Rey"); err != nil {
+		t.Fatalf("ActualizarDocente: %v", err)
+	}
+	lista, _ := docentes.ListarDocentes()
+	if len(lista) != 1 || lista[0].Nombre != "Ramiro Ruiz Rey" {
+		t.Fatalf("ListarDocentes = %+v", lista)
+	}
+}
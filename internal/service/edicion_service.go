@@ -0,0 +1,109 @@
+package service
+
+import (
+	"fmt"
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+	"strings"
+)
+
+// EdicionService modifica los datos de estudiantes y materias. Cada edición parte de la
+// versión leída: si otro usuario guardó un cambio en el medio, falla con repository.ErrConflicto
+// y hay que volver a leer el registro antes de reintentar.
+type EdicionService struct {
+	estudianteRepo repository.EstudianteRepository
+	materiaRepo    repository.MateriaRepository
+}
+
+func NewEdicionService(
+	estudianteRepo repository.EstudianteRepository,
+	materiaRepo repository.MateriaRepository,
+) *EdicionService {
+	return &EdicionService{
+		estudianteRepo: estudianteRepo,
+		materiaRepo:    materiaRepo,
+	}
+}
+
+// ObtenerEstudiante lee el estudiante con su versión actual, para editarlo
+func (s *EdicionService) ObtenerEstudiante(cedula string) (*domain.Estudiante, error) {
+	estudiante, err := s.estudianteRepo.GetByCedula(cedula)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener estudiante: %w", err)
+	}
+	if estudiante == nil {
+		return nil, fmt.Errorf("%w: estudiante %s", repository.ErrNoEncontrado, cedula)
+	}
+	return estudiante, nil
+}
+
+// RenombrarEstudiante guarda el nuevo nombre sobre la versión leída; si tiene éxito,
+// el estudiante queda con el nombre y la versión nuevos
+func (s *EdicionService) RenombrarEstudiante(estudiante *domain.Estudiante, nombre string) error {
+	nombre, err := validarNombre(nombre)
+	if err != nil {
+		return err
+	}
+
+	editado := *estudiante
+	editado.Nombre = nombre
+	if err := s.estudianteRepo.Update(&editado); err != nil {
+		return fmt.Errorf("error al actualizar estudiante: %w", err)
+	}
+	*estudiante = editado
+	return nil
+}
+
+// ObtenerMateria lee la materia con su versión actual, para editarla
+func (s *EdicionService) ObtenerMateria(codigo string) (*domain.Materia, error) {
+	materia, err := s.materiaRepo.GetByCodigo(codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener materia: %w", err)
+	}
+	if materia == nil {
+		return nil, fmt.Errorf("%w: materia %s", repository.ErrNoEncontrado, codigo)
+	}
+	return materia, nil
+}
+
+// RenombrarMateria guarda el nuevo nombre sobre la versión leída; si tiene éxito,
+// la materia queda con el nombre y la versión nuevos
+func (s *EdicionService) RenombrarMateria(materia *domain.Materia, nombre string) error {
+	nombre, err := validarNombre(nombre)
+	if err != nil {
+		return err
+	}
+
+	editada := *materia
+	editada.Nombre = nombre
+	if err := s.materiaRepo.Update(&editada); err != nil {
+		return fmt.Errorf("error al actualizar materia: %w", err)
+	}
+	*materia = editada
+	return nil
+}
+
+// CambiarCreditosMateria guarda los créditos sobre la versión leída; las inscripciones ya
+// hechas no se revisan contra el máximo de créditos
+func (s *EdicionService) CambiarCreditosMateria(materia *domain.Materia, creditos int) error {
+	if creditos < 1 || creditos > domain.MaximoCreditosPorMateria {
+		return fmt.Errorf("los créditos '%d' deben ser un número entero entre 1 y %d", creditos, domain.MaximoCreditosPorMateria)
+	}
+
+	editada := *materia
+	editada.Creditos = creditos
+	if err := s.materiaRepo.Update(&editada); err != nil {
+		return fmt.Errorf("error al actualizar materia: %w", err)
+	}
+	*materia = editada
+	return nil
+}
+
+// validarNombre aplica a las ediciones la misma regla que a los archivos de inscripciones
+func validarNombre(nombre string) (string, error) {
+	nombre = strings.TrimSpace(nombre)
+	if len(nombre) < 2 {
+		return "", fmt.Errorf("el nombre '%s' debe tener al menos 2 caracteres", nombre)
+	}
+	return nombre, nil
+}
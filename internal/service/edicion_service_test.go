@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func TestRenombrarEstudianteDetectaConflicto(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	edicion := NewEdicionService(repos.Estudiantes, repos.Materias)
+	if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulu Lopez")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Dos coordinadores abren el mismo estudiante
+	primero, err := edicion.ObtenerEstudiante("1234567")
+	if err != nil {
+		t.Fatalf("ObtenerEstudiante: %v", err)
+	}
+	segundo, _ := edicion.ObtenerEstudiante("1234567")
+
+	if err := edicion.RenombrarEstudiante(primero, "  Lulú López "); err != nil {
+		t.Fatalf("RenombrarEstudiante: %v", err)
+	}
+	if primero.Nombre != "Lulú López" || primero.Version != 2 {
+		t.Fatalf("estudiante tras renombrar = %+v, se esperaba Lulú López en la versión 2", primero)
+	}
+
+	err = edicion.RenombrarEstudiante(segundo, "Lucía López")
+	if !errors.Is(err, repository.ErrConflicto) {
+		t.Fatalf("RenombrarEstudiante concurrente = %v, se esperaba ErrConflicto", err)
+	}
+	if segundo.Nombre != "Lulu Lopez" {
+		t.Errorf("un renombrado fallido modificó el estudiante en memoria: %+v", segundo)
+	}
+}
+
+func TestRenombrarMateria(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	edicion := NewEdicionService(repos.Estudiantes, repos.Materias)
+	if err := repos.Materias.Create(domain.NewMateria("1040", "Calculo")); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	materia, err := edicion.ObtenerMateria("1040")
+	if err != nil {
+		t.Fatalf("ObtenerMateria: %v", err)
+	}
+	if err := edicion.RenombrarMateria(materia, "x"); err == nil {
+		t.Fatal("se esperaba error por un nombre demasiado corto")
+	}
+	if err := edicion.RenombrarMateria(materia, "Cálculo"); err != nil {
+		t.Fatalf("RenombrarMateria: %v", err)
+	}
+
+	if _, err := edicion.ObtenerMateria("9999"); !errors.Is(err, repository.ErrNoEncontrado) {
+		t.Fatalf("ObtenerMateria inexistente = %v, se esperaba ErrNoEncontrado", err)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// EliminacionService elimina y restaura lógicamente estudiantes, materias e inscripciones.
+// Los registros eliminados dejan de aparecer en las consultas pero conservan su historial.
+type EliminacionService struct {
+	unidad          repository.UnidadDeTrabajo
+	estudianteRepo  repository.EstudianteRepository
+	materiaRepo     repository.MateriaRepository
+	inscripcionRepo repository.InscripcionRepository
+	limites         domain.LimitesCreditos
+}
+
+// RegistrosEliminados agrupa los registros que están eliminados lógicamente
+type RegistrosEliminados struct {
+	Estudiantes   []*domain.Estudiante
+	Materias      []*domain.Materia
+	Inscripciones []*domain.Inscripcion
+}
+
+func NewEliminacionService(
+	unidad repository.UnidadDeTrabajo,
+	estudianteRepo repository.EstudianteRepository,
+	materiaRepo repository.MateriaRepository,
+	inscripcionRepo repository.InscripcionRepository,
+) *EliminacionService {
+	return &EliminacionService{
+		unidad:          unidad,
+		estudianteRepo:  estudianteRepo,
+		materiaRepo:     materiaRepo,
+		inscripcionRepo: inscripcionRepo,
+		limites:         domain.LimitesCreditosPorDefecto,
+	}
+}
+
+// EstablecerLimitesCreditos cambia la carga de créditos que se respeta al promover a alguien
+// de una lista de espera
+func (s *EliminacionService) EstablecerLimitesCreditos(limites domain.LimitesCreditos) {
+	s.limites = limites
+}
+
+// EliminarEstudiante oculta al estudiante y, con él, todas sus inscripciones. Los cupos que
+// libera no se asignan a las listas de espera; tampoco los de EliminarMateria.
+func (s *EliminacionService) EliminarEstudiante(cedula string) error {
+	if err := s.estudianteRepo.Delete(cedula); err != nil {
+		return fmt.Errorf("error al eliminar estudiante: %w", err)
+	}
+	return nil
+}
+
+func (s *EliminacionService) RestaurarEstudiante(cedula string) error {
+	if err := s.estudianteRepo.Restore(cedula); err != nil {
+		return fmt.Errorf("error al restaurar estudiante: %w", err)
+	}
+	return nil
+}
+
+// EliminarMateria oculta la materia y, con ella, todas sus inscripciones
+func (s *EliminacionService) EliminarMateria(codigo string) error {
+	if err := s.materiaRepo.Delete(codigo); err != nil {
+		return fmt.Errorf("error al eliminar materia: %w", err)
+	}
+	return nil
+}
+
+func (s *EliminacionService) RestaurarMateria(codigo string) error {
+	if err := s.materiaRepo.Restore(codigo); err != nil {
+		return fmt.Errorf("error al restaurar materia: %w", err)
+	}
+	return nil
+}
+
+// EliminarInscripcion cancela la inscripción y publica InscripcionCancelada en la misma unidad
+// de trabajo, en la que además el cupo liberado pasa al siguiente de la lista de espera.
+// Retorna las cédulas de los estudiantes promovidos desde la lista.
+func (s *EliminacionService) EliminarInscripcion(cedula, codigo string) ([]string, error) {
+	var promovidos []string
+	err := s.unidad.EnTransaccion(func(repos *repository.Repositorios) error {
+		if err := repos.Inscripciones.Delete(cedula, codigo); err != nil {
+			return fmt.Errorf("error al eliminar inscripción: %w", err)
+		}
+		if err := publicar(repos, domain.InscripcionCancelada{Cedula: cedula, Codigo: codigo, Periodo: repos.Periodo()}); err != nil {
+			return err
+		}
+		var err error
+		promovidos, err = promoverEspera(repos, s.limites, codigo)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return promovidos, nil
+}
+
+// RestaurarInscripcion vuelve a activar la inscripción; para los suscriptores es una inscripción
+// nueva. Falla con repository.ErrCupoAgotado si la materia ya no tiene cupo.
+func (s *EliminacionService) RestaurarInscripcion(cedula, codigo string) error {
+	return s.unidad.EnTransaccion(func(repos *repository.Repositorios) error {
+		if err := repos.Inscripciones.Restore(cedula, codigo); err != nil {
+			return fmt.Errorf("error al restaurar inscripción: %w", err)
+		}
+		return publicar(repos, domain.InscripcionCreada{Cedula: cedula, Codigo: codigo, Periodo: repos.Periodo()})
+	})
+}
+
+// ObtenerEliminados retorna hasta limite registros eliminados de cada tipo. Las inscripciones
+// incluyen las ocultas porque su estudiante o su materia fueron eliminados.
+func (s *EliminacionService) ObtenerEliminados(limite int) (*RegistrosEliminados, error) {
+	consulta := repository.Consulta{Limite: limite, Eliminados: repository.SoloEliminados}
+
+	estudiantes, err := s.estudianteRepo.List(consulta)
+	if err != nil {
+		return nil, fmt.Errorf("error al listar estudiantes eliminados: %w", err)
+	}
+	materias, err := s.materiaRepo.List(consulta)
+	if err != nil {
+		return nil, fmt.Errorf("error al listar materias eliminadas: %w", err)
+	}
+	inscripciones, err := s.inscripcionRepo.List(consulta)
+	if err != nil {
+		return nil, fmt.Errorf("error al listar inscripciones eliminadas: %w", err)
+	}
+
+	return &RegistrosEliminados{
+		Estudiantes:   estudiantes.Elementos,
+		Materias:      materias.Elementos,
+		Inscripciones: inscripciones.Elementos,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/repository"
+)
+
+func TestEliminarYRestaurarEstudiante(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	consultas := NewConsultasAvanzadasService(repos, repos.Estudiantes, repos.Materias, repos.Inscripciones)
+	eliminacion := NewEliminacionService(repos, repos.Estudiantes, repos.Materias, repos.Inscripciones)
+
+	if err := consultas.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	if err := eliminacion.EliminarEstudiante("1234567"); err != nil {
+		t.Fatalf("EliminarEstudiante: %v", err)
+	}
+
+	eliminados, err := eliminacion.ObtenerEliminados(10)
+	if err != nil {
+		t.Fatalf("ObtenerEliminados: %v", err)
+	}
+	if len(eliminados.Estudiantes) != 1 || len(eliminados.Materias) != 0 || len(eliminados.Inscripciones) != 1 {
+		t.Fatalf("eliminados = %d estudiantes, %d materias, %d inscripciones; se esperaban 1, 0 y 1",
+			len(eliminados.Estudiantes), len(eliminados.Materias), len(eliminados.Inscripciones))
+	}
+
+	estadisticas, err := consultas.ObtenerEstadisticasGenerales()
+	if err != nil {
+		t.Fatalf("ObtenerEstadisticasGenerales: %v", err)
+	}
+	if estadisticas.TotalEstudiantes != 0 || estadisticas.TotalInscripciones != 0 {
+		t.Fatalf("las estadísticas cuentan registros eliminados: %+v", estadisticas)
+	}
+
+	// Insertar de nuevo al estudiante eliminado exige restaurarlo primero
+	err = consultas.InsertarNuevoRegistro("1234567", "Lulú López", "1050", "Física I")
+	if !errors.Is(err, repository.ErrEliminado) {
+		t.Fatalf("InsertarNuevoRegistro sobre eliminado = %v, se esperaba ErrEliminado", err)
+	}
+
+	if err := eliminacion.RestaurarEstudiante("1234567"); err != nil {
+		t.Fatalf("RestaurarEstudiante: %v", err)
+	}
+	if err := eliminacion.RestaurarEstudiante("1234567"); !errors.Is(err, repository.ErrNoEncontrado) {
+		t.Fatalf("RestaurarEstudiante repetido = %v, se esperaba ErrNoEncontrado", err)
+	}
+}
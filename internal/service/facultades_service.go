@@ -0,0 +1,63 @@
+package service
+
+import (
+	"fmt"
+	"inscripciones/internal/repository"
+)
+
+// FacultadesService informa la facultad activa y arma los reportes que cruzan
+// facultades, disponibles solo en modo administrativo
+type FacultadesService struct {
+	repos *repository.Repositorios
+}
+
+func NewFacultadesService(repos *repository.Repositorios) *FacultadesService {
+	return &FacultadesService{repos: repos}
+}
+
+// ResumenFacultad cuenta los registros vigentes de una facultad
+type ResumenFacultad struct {
+	Facultad           string
+	TotalEstudiantes   int
+	TotalMaterias      int
+	TotalInscripciones int
+}
+
+// FacultadActiva retorna la facultad sobre la que opera la sesión
+func (s *FacultadesService) FacultadActiva() string {
+	return s.repos.Facultad()
+}
+
+// ModoAdministrativo indica si la sesión puede consultar los reportes entre facultades
+func (s *FacultadesService) ModoAdministrativo() bool {
+	return s.repos.Administrador()
+}
+
+// ResumenPorFacultad retorna los totales de cada facultad con datos, ordenados por nombre.
+// Fuera del modo administrativo retorna repository.ErrNoAutorizado.
+func (s *FacultadesService) ResumenPorFacultad() ([]ResumenFacultad, error) {
+	facultades, err := s.repos.Facultades()
+	if err != nil {
+		return nil, fmt.Errorf("error al listar facultades: %w", err)
+	}
+
+	var resumenes []ResumenFacultad
+	for _, facultad := range facultades {
+		repos, err := s.repos.ConFacultad(facultad)
+		if err != nil {
+			return nil, fmt.Errorf("error al abrir la facultad %s: %w", facultad, err)
+		}
+		consultas := NewConsultasAvanzadasService(repos, repos.Estudiantes, repos.Materias, repos.Inscripciones)
+		estadisticas, err := consultas.ObtenerEstadisticasGenerales()
+		if err != nil {
+			return nil, fmt.Errorf("error al resumir la facultad %s: %w", facultad, err)
+		}
+		resumenes = append(resumenes, ResumenFacultad{
+			Facultad:           facultad,
+			TotalEstudiantes:   estadisticas.TotalEstudiantes,
+			TotalMaterias:      estadisticas.TotalMaterias,
+			TotalInscripciones: estadisticas.TotalInscripciones,
+		})
+	}
+	return resumenes, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"inscripciones/internal/repository"
+)
+
+func TestResumenPorFacultad(t *testing.T) {
+	repos, err := repository.NewRepositoriosEnMemoriaConAcceso(repository.Acceso{Administrador: true})
+	if err != nil {
+		t.Fatalf("NewRepositoriosEnMemoriaConAcceso: %v", err)
+	}
+	norte, err := repos.ConFacultad("sede-norte")
+	if err != nil {
+		t.Fatalf("ConFacultad: %v", err)
+	}
+
+	principal := NewConsultasAvanzadasService(repos, repos.Estudiantes, repos.Materias, repos.Inscripciones)
+	if err := principal.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	sede := NewConsultasAvanzadasService(norte, norte.Estudiantes, norte.Materias, norte.Inscripciones)
+	for _, codigo := range []string{"1040", "1050"} {
+		if err := sede.InsertarNuevoRegistro("1234567", "Lulú López", codigo, "Materia "+codigo); err != nil {
+			t.Fatalf("InsertarNuevoRegistro en sede-norte: %v", err)
+		}
+	}
+
+	resumenes, err := NewFacultadesService(repos).ResumenPorFacultad()
+	if err != nil {
+		t.Fatalf("ResumenPorFacultad: %v", err)
+	}
+	esperados := []ResumenFacultad{
+		{Facultad: repository.FacultadPorDefecto, TotalEstudiantes: 1, TotalMaterias: 1, TotalInscripciones: 1},
+		{Facultad: "sede-norte", TotalEstudiantes: 1, TotalMaterias: 2, TotalInscripciones: 2},
+	}
+	if !reflect.DeepEqual(resumenes, esperados) {
+		t.Fatalf("ResumenPorFacultad = %+v, se esperaba %+v", resumenes, esperados)
+	}
+}
+
+func TestResumenPorFacultadRequiereModoAdministrativo(t *testing.T) {
+	facultades := NewFacultadesService(repository.NewRepositoriosEnMemoria())
+	if facultades.ModoAdministrativo() {
+		t.Fatal("los repositorios por defecto no deben estar en modo administrativo")
+	}
+	if _, err := facultades.ResumenPorFacultad(); !errors.Is(err, repository.ErrNoAutorizado) {
+		t.Fatalf("ResumenPorFacultad = %v, se esperaba ErrNoAutorizado", err)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// GruposService administra los grupos de las materias y las listas de clase de cada grupo
+// en el periodo
+type GruposService struct {
+	repos *repository.Repositorios
+}
+
+func NewGruposService(repos *repository.Repositorios) *GruposService {
+	return &GruposService{repos: repos}
+}
+
+// ListaGrupo es la lista de clase de un grupo de una materia en el periodo; el grupo
+// domain.SinGrupo reúne a los inscritos que no están en ninguno
+type ListaGrupo struct {
+	Numero      int
+	Estudiantes []*domain.Estudiante
+}
+
+// CrearGrupo agrega a la materia el grupo con el número indicado
+func (s *GruposService) CrearGrupo(codigo string, numero int) error {
+	if err := validarNumeroGrupo(numero); err != nil {
+		return err
+	}
+	if err := s.repos.Grupos.Create(domain.Grupo{Materia: strings.TrimSpace(codigo), Numero: numero}); err != nil {
+		return fmt.Errorf("error al crear grupo: %w", err)
+	}
+	return nil
+}
+
+// EliminarGrupo quita el grupo de la materia; falla con repository.ErrGrupoConInscritos
+// mientras alguna inscripción, de cualquier periodo, esté en él
+func (s *GruposService) EliminarGrupo(codigo string, numero int) error {
+	if err := s.repos.Grupos.Delete(domain.Grupo{Materia: strings.TrimSpace(codigo), Numero: numero}); err != nil {
+		return fmt.Errorf("error al eliminar grupo: %w", err)
+	}
+	return nil
+}
+
+// GruposDeMateria retorna los números de los grupos de la materia, en orden
+func (s *GruposService) GruposDeMateria(codigo string) ([]int, error) {
+	grupos, err := s.repos.Grupos.GetByMateria(strings.TrimSpace(codigo))
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener grupos: %w", err)
+	}
+	numeros := make([]int, 0, len(grupos))
+	for _, g := range grupos {
+		numeros = append(numeros, g.Numero)
+	}
+	return numeros, nil
+}
+
+// AsignarGrupo pasa la inscripción del estudiante en la materia al grupo indicado, o la
+// deja sin grupo con domain.SinGrupo. Falla con ErrCruceHorario si el horario del grupo se
+// cruza con el de otra materia del estudiante.
+func (s *GruposService) AsignarGrupo(cedula, codigo string, numero int) error {
+	if numero != domain.SinGrupo {
+		if err := validarNumeroGrupo(numero); err != nil {
+			return err
+		}
+	}
+	cedula, codigo = strings.TrimSpace(cedula), strings.TrimSpace(codigo)
+	return s.repos.EnTransaccion(func(repos *repository.Repositorios) error {
+		if err := verificarCruces(repos, cedula, codigo, numero); err != nil {
+			return err
+		}
+		if err := repos.Inscripciones.CambiarGrupo(cedula, codigo, numero); err != nil {
+			return fmt.Errorf("error al asignar grupo: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListasPorGrupo retorna la lista de clase de cada grupo de la materia en el periodo, en
+// orden y aunque esté vacía; al final, si los hay, van los inscritos sin grupo
+func (s *GruposService) ListasPorGrupo(codigo string) ([]ListaGrupo, error) {
+	codigo = strings.TrimSpace(codigo)
+	numeros, err := s.GruposDeMateria(codigo)
+	if err != nil {
+		return nil, err
+	}
+
+	listas := make([]ListaGrupo, 0, len(numeros)+1)
+	for _, numero := range append(numeros, domain.SinGrupo) {
+		estudiantes, err := s.repos.Inscripciones.GetByGrupo(codigo, numero)
+		if err != nil {
+			return nil, fmt.Errorf("error al obtener la lista del grupo %d: %w", numero, err)
+		}
+		if numero == domain.SinGrupo && len(estudiantes) == 0 {
+			continue
+		}
+		listas = append(listas, ListaGrupo{Numero: numero, Estudiantes: estudiantes})
+	}
+	return listas, nil
+}
+
+// asegurarGrupo crea el grupo de la materia si todavía no existe, dentro de la unidad de
+// trabajo de repos, como se crean las materias nuevas al inscribir
+func asegurarGrupo(repos *repository.Repositorios, codigo string, numero int) error {
+	if numero == domain.SinGrupo {
+		return nil
+	}
+	grupos, err := repos.Grupos.GetByMateria(codigo)
+	if err != nil {
+		return fmt.Errorf("error al obtener grupos: %w", err)
+	}
+	for _, g := range grupos {
+		if g.Numero == numero {
+			return nil
+		}
+	}
+	if err := repos.Grupos.Create(domain.Grupo{Materia: codigo, Numero: numero}); err != nil {
+		return fmt.Errorf("error al crear grupo: %w", err)
+	}
+	return nil
+}
+
+func validarNumeroGrupo(numero int) error {
+	if numero < 1 {
+		return fmt.Errorf("el grupo '%d' debe ser un número entero positivo", numero)
+	}
+	return nil
+}
+
+// validarGrupo interpreta el número de grupo de un archivo o de la consola
+func validarGrupo(texto string) (int, error) {
+	numero, err := strconv.Atoi(strings.TrimSpace(texto))
+	if err != nil || numero < 1 {
+		return 0, fmt.Errorf("el grupo '%s' debe ser un número entero positivo", strings.TrimSpace(texto))
+	}
+	return numero, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// cedulasPorGrupo resume las listas como número de grupo y cédulas de sus inscritos
+func cedulasPorGrupo(listas []ListaGrupo) map[int][]string {
+	resultado := make(map[int][]string, len(listas))
+	for _, lista := range listas {
+		cedulas := []string{}
+		for _, e := range lista.Estudiantes {
+			cedulas = append(cedulas, e.Cedula)
+		}
+		resultado[lista.Numero] = cedulas
+	}
+	return resultado
+}
+
+func TestInsertarNuevoRegistroEnGrupo(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+	grupos := NewGruposService(repos)
+
+	// El grupo se crea con la primera inscripción que lo nombra, como la materia
+	if _, err := svc.InsertarNuevoRegistroEnGrupo("1234567", "Lulú López", "1040", "Cálculo", 4, 2); err != nil {
+		t.Fatalf("InsertarNuevoRegistroEnGrupo: %v", err)
+	}
+	if err := svc.InsertarNuevoRegistro("9876534", "Pepito Pérez", "1040", "Cálculo"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	if err := grupos.CrearGrupo("1040", 1); err != nil {
+		t.Fatalf("CrearGrupo: %v", err)
+	}
+	if _, err := svc.InsertarNuevoRegistroEnGrupo("1111111", "Ana García", "1040", "Cálculo", 4, -1); err == nil {
+		t.Fatal("se esperaba error con un grupo negativo")
+	}
+
+	listas, err := grupos.ListasPorGrupo("1040")
+	if err != nil {
+		t.Fatalf("ListasPorGrupo: %v", err)
+	}
+	esperadas := map[int][]string{1: {}, 2: {"1234567"}, domain.SinGrupo: {"9876534"}}
+	if !reflect.DeepEqual(cedulasPorGrupo(listas), esperadas) {
+		t.Fatalf("ListasPorGrupo = %v, se esperaba %v", cedulasPorGrupo(listas), esperadas)
+	}
+	if listas[len(listas)-1].Numero != domain.SinGrupo {
+		t.Fatalf("los inscritos sin grupo deben ir al final: %+v", listas)
+	}
+
+	// Al asignar grupo a Pepito ya no queda nadie sin grupo
+	if err := grupos.AsignarGrupo("9876534", "1040", 1); err != nil {
+		t.Fatalf("AsignarGrupo: %v", err)
+	}
+	listas, _ = grupos.ListasPorGrupo("1040")
+	esperadas = map[int][]string{1: {"9876534"}, 2: {"1234567"}}
+	if !reflect.DeepEqual(cedulasPorGrupo(listas), esperadas) {
+		t.Fatalf("ListasPorGrupo tras asignar = %v, se esperaba %v", cedulasPorGrupo(listas), esperadas)
+	}
+	if err := grupos.EliminarGrupo("1040", 2); !errors.Is(err, repository.ErrGrupoConInscritos) {
+		t.Fatalf("EliminarGrupo con inscritos = %v, se esperaba ErrGrupoConInscritos", err)
+	}
+	if err := grupos.CrearGrupo("1040", 0); err == nil {
+		t.Fatal("se esperaba error al crear el grupo 0")
+	}
+}
+
+func TestProcesarArchivoConGrupos(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+	grupos := NewGruposService(repos)
+
+	// La línea con grupo 0 es inválida y se omite; las demás crean los grupos que nombran
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_grupos.txt"); err != nil {
+		t.Fatalf("ProcesarArchivo: %v", err)
+	}
+	if numeros, _ := grupos.GruposDeMateria("1040"); !reflect.DeepEqual(numeros, []int{1, 2}) {
+		t.Fatalf("GruposDeMateria = %v, se esperaban los grupos 1 y 2", numeros)
+	}
+	listas, err := grupos.ListasPorGrupo("1040")
+	if err != nil {
+		t.Fatalf("ListasPorGrupo: %v", err)
+	}
+	esperadas := map[int][]string{1: {"1111111", "1234567"}, 2: {"9876534"}, domain.SinGrupo: {"2222222"}}
+	if !reflect.DeepEqual(cedulasPorGrupo(listas), esperadas) {
+		t.Fatalf("ListasPorGrupo = %v, se esperaba %v", cedulasPorGrupo(listas), esperadas)
+	}
+	// La materia nueva toma los créditos de su primera línea, aunque otras los omitan por traer grupo
+	if m, _ := repos.Materias.GetByCodigo("1040"); m == nil || m.Creditos != 4 {
+		t.Fatalf("GetByCodigo = %+v, se esperaban 4 créditos", m)
+	}
+	if existe, _ := repos.Inscripciones.Exists("3333333", "1040"); existe {
+		t.Fatal("se inscribió la línea con un grupo inválido")
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// HistorialAcademicoService arma el historial académico de los estudiantes: las materias
+// que cursaron en todos los periodos, con sus créditos y definitivas, y sus promedios
+// ponderados por créditos
+type HistorialAcademicoService struct {
+	repos  *repository.Repositorios
+	reglas domain.ReglasCalificacion
+}
+
+func NewHistorialAcademicoService(repos *repository.Repositorios) *HistorialAcademicoService {
+	return &HistorialAcademicoService{repos: repos, reglas: domain.ReglasCalificacionPorDefecto}
+}
+
+// EstablecerReglas cambia las reglas de las definitivas y de los promedios
+func (s *HistorialAcademicoService) EstablecerReglas(reglas domain.ReglasCalificacion) {
+	s.reglas = reglas
+}
+
+// Reglas retorna las reglas con las que se calculan las definitivas y los promedios
+func (s *HistorialAcademicoService) Reglas() domain.ReglasCalificacion {
+	return s.reglas
+}
+
+// Estados de una materia en el historial académico
+const (
+	EstadoAprobada  = "Aprobada"
+	EstadoReprobada = "Reprobada"
+	EstadoEnCurso   = "En curso"
+)
+
+// MateriaCursada es una materia del historial con su definitiva en el periodo
+type MateriaCursada struct {
+	Materia    *domain.Materia
+	Definitiva domain.Definitiva
+}
+
+// Estado indica si la materia se aprobó, se reprobó o sigue en curso porque su definitiva
+// todavía es parcial
+func (m MateriaCursada) Estado() string {
+	switch {
+	case !m.Definitiva.Completa:
+		return EstadoEnCurso
+	case m.Definitiva.Aprobada:
+		return EstadoAprobada
+	default:
+		return EstadoReprobada
+	}
+}
+
+// PeriodoCursado son las materias de un periodo del historial, ordenadas por código, con
+// sus créditos y su promedio
+type PeriodoCursado struct {
+	Periodo  string
+	Materias []MateriaCursada
+	// CreditosInscritos suma los créditos de todas las materias del periodo
+	CreditosInscritos int
+	// CreditosCalificados suma los de las materias con definitiva completa, que son las
+	// únicas que cuentan en el promedio
+	CreditosCalificados int
+	CreditosAprobados   int
+	// Promedio es el promedio ponderado por créditos del periodo; cero sin créditos calificados
+	Promedio float64
+}
+
+// HistorialAcademico es el historial de un estudiante, del periodo más antiguo al más
+// reciente. El promedio acumulado cuenta todas las materias calificadas, también las
+// reprobadas y las repetidas; los créditos aprobados cuentan una sola vez cada materia.
+type HistorialAcademico struct {
+	Estudiante          *domain.Estudiante
+	Periodos            []PeriodoCursado
+	CreditosCalificados int
+	CreditosAprobados   int
+	PromedioAcumulado   float64
+}
+
+// HistorialPorCedula arma el historial académico del estudiante; nil si no existe
+func (s *HistorialAcademicoService) HistorialPorCedula(cedula string) (*HistorialAcademico, error) {
+	estudiante, err := s.repos.Estudiantes.GetByCedula(strings.TrimSpace(cedula))
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar estudiante: %w", err)
+	}
+	if estudiante == nil {
+		return nil, nil
+	}
+	return s.HistorialDe(estudiante)
+}
+
+// HistorialDe arma el historial académico de un estudiante ya encontrado, como el que
+// retorna ConsultasAvanzadasService.BuscarEstudiantePorCedula
+func (s *HistorialAcademicoService) HistorialDe(estudiante *domain.Estudiante) (*HistorialAcademico, error) {
+	inscripciones, err := s.repos.Inscripciones.HistorialByEstudiante(estudiante.Cedula)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar las inscripciones del estudiante %s: %w", estudiante.Cedula, err)
+	}
+
+	historial := &HistorialAcademico{Estudiante: estudiante}
+	componentes := make(map[string][]domain.Componente)
+	aprobadas := make(map[string]int)
+	var acumulado domain.PromedioPonderado
+	var periodo *PeriodoCursado
+	var promedioPeriodo domain.PromedioPonderado
+	var repos *repository.Repositorios
+
+	// Las inscripciones vienen ordenadas por periodo y código de materia
+	for _, i := range inscripciones {
+		if periodo == nil || periodo.Periodo != i.Periodo {
+			if periodo != nil {
+				periodo.Promedio = promedioPeriodo.Valor(s.reglas)
+				historial.Periodos = append(historial.Periodos, *periodo)
+			}
+			periodo, promedioPeriodo = &PeriodoCursado{Periodo: i.Periodo}, domain.PromedioPonderado{}
+			if repos, err = s.repos.EnPeriodo(i.Periodo); err != nil {
+				return nil, fmt.Errorf("error al abrir el periodo %s: %w", i.Periodo, err)
+			}
+		}
+
+		codigo := i.Materia.Codigo
+		if _, ok := componentes[codigo]; !ok {
+			if componentes[codigo], err = s.repos.Componentes.GetByMateria(codigo); err != nil {
+				return nil, fmt.Errorf("error al obtener los componentes de %s: %w", codigo, err)
+			}
+		}
+		calificaciones, err := repos.Calificaciones.GetByInscripcion(estudiante.Cedula, codigo)
+		if err != nil {
+			return nil, fmt.Errorf("error al obtener las notas de %s en %s: %w", codigo, i.Periodo, err)
+		}
+		notas := notasPorInscripcion(calificaciones)[claveNotas{estudiante.Cedula, codigo}]
+		cursada := MateriaCursada{Materia: i.Materia, Definitiva: s.reglas.Definitiva(componentes[codigo], notas)}
+		periodo.Materias = append(periodo.Materias, cursada)
+
+		creditos := i.Materia.Creditos
+		periodo.CreditosInscritos += creditos
+		if cursada.Definitiva.Completa {
+			periodo.CreditosCalificados += creditos
+			promedioPeriodo.Agregar(cursada.Definitiva.Nota, creditos)
+			acumulado.Agregar(cursada.Definitiva.Nota, creditos)
+		}
+		if cursada.Definitiva.Aprobada {
+			periodo.CreditosAprobados += creditos
+			aprobadas[codigo] = creditos
+		}
+	}
+	if periodo != nil {
+		periodo.Promedio = promedioPeriodo.Valor(s.reglas)
+		historial.Periodos = append(historial.Periodos, *periodo)
+	}
+
+	historial.CreditosCalificados = acumulado.Creditos
+	historial.PromedioAcumulado = acumulado.Valor(s.reglas)
+	for _, creditos := range aprobadas {
+		historial.CreditosAprobados += creditos
+	}
+	return historial, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func TestHistorialAcademico(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	historiales := NewHistorialAcademicoService(repos)
+	if err := repos.Estudiantes.Create(domain.NewEstudiante("1234567", "Lulú López")); err != nil {
+		t.Fatalf("Create estudiante: %v", err)
+	}
+	calculo := domain.NewMateria("1040", "Cálculo")
+	calculo.Creditos = 4
+	for _, m := range []*domain.Materia{calculo, domain.NewMateria("1050", "Física I"), domain.NewMateria("1060", "Administración")} {
+		if err := repos.Materias.Create(m); err != nil {
+			t.Fatalf("Create materia: %v", err)
+		}
+	}
+	for _, codigo := range []string{"1040", "1060"} {
+		if err := repos.Componentes.Create(domain.Componente{Materia: codigo, Nombre: "Final", Peso: domain.PesoTotal}); err != nil {
+			t.Fatalf("Create componente: %v", err)
+		}
+	}
+
+	// En 2025-2 Lulú reprueba Cálculo y aprueba Administración; en el periodo actual repite
+	// Cálculo y la aprueba, y Física, sin componentes, sigue en curso
+	anterior, err := reposDelPeriodo(repos, "2025-2")
+	if err != nil {
+		t.Fatalf("reposDelPeriodo: %v", err)
+	}
+	for _, n := range []struct {
+		repos  *repository.Repositorios
+		codigo string
+		nota   float64
+	}{
+		{anterior, "1040", 2.0},
+		{anterior, "1060", 4.0},
+		{repos, "1040", 3.5},
+		{repos, "1050", -1},
+	} {
+		if err := n.repos.Inscripciones.Create("1234567", n.codigo); err != nil {
+			t.Fatalf("Create inscripción %s: %v", n.codigo, err)
+		}
+		if n.nota < 0 {
+			continue
+		}
+		if err := n.repos.Calificaciones.Registrar("1234567", n.codigo, "Final", n.nota); err != nil {
+			t.Fatalf("Registrar nota %s: %v", n.codigo, err)
+		}
+	}
+
+	historial, err := historiales.HistorialPorCedula("1234567")
+	if err != nil {
+		t.Fatalf("HistorialPorCedula: %v", err)
+	}
+	if len(historial.Periodos) != 2 || historial.Periodos[0].Periodo != "2025-2" || historial.Periodos[1].Periodo != repos.Periodo() {
+		t.Fatalf("Periodos = %+v, se esperaban 2025-2 y %s", historial.Periodos, repos.Periodo())
+	}
+
+	// (2.0 × 4 + 4.0 × 3) / 7 = 2.857...
+	primero := historial.Periodos[0]
+	if primero.Promedio != 2.86 || primero.CreditosInscritos != 7 || primero.CreditosCalificados != 7 || primero.CreditosAprobados != 3 {
+		t.Fatalf("periodo 2025-2 = %+v, se esperaba el promedio 2.86 con 3 de 7 créditos aprobados", primero)
+	}
+	if estados := []string{primero.Materias[0].Estado(), primero.Materias[1].Estado()}; estados[0] != EstadoReprobada || estados[1] != EstadoAprobada {
+		t.Fatalf("estados en 2025-2 = %v, se esperaba Cálculo reprobada y Administración aprobada", estados)
+	}
+	actual := historial.Periodos[1]
+	if actual.Promedio != 3.5 || actual.CreditosInscritos != 7 || actual.CreditosCalificados != 4 || actual.Materias[1].Estado() != EstadoEnCurso {
+		t.Fatalf("periodo actual = %+v, se esperaba el promedio 3.5 sin contar Física, en curso", actual)
+	}
+
+	// Cálculo cuenta dos veces en el promedio y una sola en los créditos aprobados:
+	// (8 + 12 + 14) / 11 = 3.0909...
+	if historial.PromedioAcumulado != 3.09 || historial.CreditosCalificados != 11 || historial.CreditosAprobados != 7 {
+		t.Fatalf("acumulado = %v con %d calificados y %d aprobados, se esperaba 3.09 con 11 y 7",
+			historial.PromedioAcumulado, historial.CreditosCalificados, historial.CreditosAprobados)
+	}
+
+	// Truncando, las definitivas y los promedios bajan
+	historiales.EstablecerReglas(domain.ReglasCalificacion{Decimales: 1, Redondeo: domain.Truncar, NotaAprobatoria: 3})
+	if historial, _ := historiales.HistorialPorCedula("1234567"); historial.Periodos[0].Promedio != 2.85 {
+		t.Fatalf("promedio truncado = %v, se esperaba 2.85", historial.Periodos[0].Promedio)
+	}
+
+	if historial, _ := historiales.HistorialPorCedula("0000000"); historial != nil {
+		t.Fatalf("HistorialPorCedula de un estudiante inexistente = %+v, se esperaba nil", historial)
+	}
+	if err := repos.Estudiantes.Create(domain.NewEstudiante("9876534", "Pepito Pérez")); err != nil {
+		t.Fatalf("Create estudiante: %v", err)
+	}
+	if historial, _ := historiales.HistorialPorCedula("9876534"); len(historial.Periodos) != 0 || historial.PromedioAcumulado != 0 {
+		t.Fatalf("historial sin materias = %+v, se esperaba vacío", historial)
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"fmt"
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// HistorialService consulta la auditoría de cambios de estudiantes y materias
+type HistorialService struct {
+	auditoriaRepo repository.AuditoriaRepository
+}
+
+func NewHistorialService(auditoriaRepo repository.AuditoriaRepository) *HistorialService {
+	return &HistorialService{auditoriaRepo: auditoriaRepo}
+}
+
+// HistorialEstudiante retorna los cambios del estudiante y de sus inscripciones en orden cronológico
+func (s *HistorialService) HistorialEstudiante(cedula string) ([]*domain.RegistroAuditoria, error) {
+	registros, err := s.auditoriaRepo.HistorialEstudiante(cedula)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar historial del estudiante: %w", err)
+	}
+	return registros, nil
+}
+
+// HistorialMateria retorna los cambios de la materia y de sus inscripciones en orden cronológico
+func (s *HistorialService) HistorialMateria(codigo string) ([]*domain.RegistroAuditoria, error) {
+	registros, err := s.auditoriaRepo.HistorialMateria(codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar historial de la materia: %w", err)
+	}
+	return registros, nil
+}
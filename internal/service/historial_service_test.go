@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+func TestHistorialDistingueOrigen(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	consola := repos.ConAuditoria(repository.ContextoAuditoria{Actor: "ana", Origen: domain.OrigenConsola})
+	archivo := repos.ConAuditoria(repository.ContextoAuditoria{Actor: "ana", Origen: domain.OrigenArchivo})
+
+	consultas := NewConsultasAvanzadasService(consola, consola.Estudiantes, consola.Materias, consola.Inscripciones)
+	if err := consultas.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	if err := archivo.Materias.Create(domain.NewMateria("1060", "Administración")); err != nil {
+		t.Fatalf("Create materia: %v", err)
+	}
+	if err := archivo.Inscripciones.Create("1234567", "1060"); err != nil {
+		t.Fatalf("Create inscripción: %v", err)
+	}
+
+	historial := NewHistorialService(repos.Auditoria)
+	registros, err := historial.HistorialEstudiante("1234567")
+	if err != nil {
+		t.Fatalf("HistorialEstudiante: %v", err)
+	}
+
+	var origenes []string
+	for _, r := range registros {
+		origenes = append(origenes, r.Entidad+":"+r.Origen)
+	}
+	esperado := []string{"estudiante:consola", "inscripcion:consola", "inscripcion:archivo"}
+	if len(origenes) != len(esperado) {
+		t.Fatalf("historial = %v, se esperaba %v", origenes, esperado)
+	}
+	for i := range esperado {
+		if origenes[i] != esperado[i] {
+			t.Fatalf("historial = %v, se esperaba %v", origenes, esperado)
+		}
+	}
+}
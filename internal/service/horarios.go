@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// ErrCruceHorario indica dos sesiones de clase que ocupan el mismo tiempo en el horario de un estudiante
+var ErrCruceHorario = errors.New("cruce de horario")
+
+// HorariosService administra las sesiones semanales de las materias y arma el horario de
+// cada estudiante en el periodo
+type HorariosService struct {
+	repos *repository.Repositorios
+}
+
+func NewHorariosService(repos *repository.Repositorios) *HorariosService {
+	return &HorariosService{repos: repos}
+}
+
+// SesionInscrita es una sesión del horario de un estudiante junto con el nombre de su materia
+type SesionInscrita struct {
+	domain.Sesion
+	NombreMateria string
+}
+
+// HorarioEstudiante es el horario semanal de un estudiante en el periodo, con las sesiones
+// ordenadas por día y hora y los cruces entre ellas
+type HorarioEstudiante struct {
+	Estudiante *domain.Estudiante
+	Sesiones   []SesionInscrita
+	Cruces     []domain.Cruce
+}
+
+// AgregarSesion agrega una sesión semanal a la materia, del grupo indicado o de todos sus
+// inscritos con domain.SinGrupo. El día acepta el nombre o el número y las horas el formato
+// HH:MM. Falla con ErrCruceHorario si se cruza con otra sesión de la materia que tengan los
+// mismos inscritos; los cruces con otras materias no la impiden, pero quedan en el horario
+// de los estudiantes afectados.
+func (s *HorariosService) AgregarSesion(codigo string, grupo int, dia, inicio, fin, salon string) error {
+	sesion, err := nuevaSesion(codigo, grupo, dia, inicio, fin, salon)
+	if err != nil {
+		return err
+	}
+	return s.repos.EnTransaccion(func(repos *repository.Repositorios) error {
+		existentes, err := repos.Horarios.GetByMateria(sesion.Materia)
+		if err != nil {
+			return fmt.Errorf("error al obtener el horario de %s: %w", sesion.Materia, err)
+		}
+		for _, otra := range existentes {
+			// La misma sesión repetida la rechaza el repositorio como duplicada
+			repetida := otra.Grupo == sesion.Grupo && otra.Dia == sesion.Dia && otra.Inicio == sesion.Inicio
+			mismosInscritos := otra.Grupo == sesion.Grupo || otra.Grupo == domain.SinGrupo || sesion.Grupo == domain.SinGrupo
+			if !repetida && mismosInscritos && otra.SeCruzaCon(sesion) {
+				return fmt.Errorf("%w: la sesión del %s de %s se cruza con la del %s", ErrCruceHorario, sesion.Franja(), sesion.Materia, otra.Franja())
+			}
+		}
+		if err := repos.Horarios.Create(sesion); err != nil {
+			return fmt.Errorf("error al agregar sesión: %w", err)
+		}
+		return nil
+	})
+}
+
+// QuitarSesion elimina la sesión de la materia y el grupo que empieza a esa hora de ese día
+func (s *HorariosService) QuitarSesion(sesion domain.Sesion) error {
+	if err := s.repos.Horarios.Delete(sesion); err != nil {
+		return fmt.Errorf("error al quitar sesión: %w", err)
+	}
+	return nil
+}
+
+// SesionesDeMateria retorna las sesiones de la materia ordenadas por grupo, día y hora
+func (s *HorariosService) SesionesDeMateria(codigo string) ([]domain.Sesion, error) {
+	sesiones, err := s.repos.Horarios.GetByMateria(strings.TrimSpace(codigo))
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el horario: %w", err)
+	}
+	return sesiones, nil
+}
+
+// HorarioDeEstudiante retorna el horario del estudiante en el periodo con sus cruces; nil
+// si el estudiante no existe
+func (s *HorariosService) HorarioDeEstudiante(cedula string) (*HorarioEstudiante, error) {
+	cedula = strings.TrimSpace(cedula)
+	estudiante, err := s.repos.Estudiantes.GetByCedula(cedula)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar estudiante: %w", err)
+	}
+	if estudiante == nil {
+		return nil, nil
+	}
+	sesiones, nombres, err := sesionesInscritas(s.repos, cedula, "")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(sesiones, func(i, j int) bool { return sesiones[i].Antes(sesiones[j]) })
+
+	horario := &HorarioEstudiante{Estudiante: estudiante, Cruces: domain.Cruces(sesiones)}
+	for _, sesion := range sesiones {
+		horario.Sesiones = append(horario.Sesiones, SesionInscrita{Sesion: sesion, NombreMateria: nombres[sesion.Materia]})
+	}
+	return horario, nil
+}
+
+// sesionesInscritas retorna las sesiones de las materias que el estudiante tiene inscritas
+// en el periodo, según el grupo de cada inscripción, sin las de la materia excluida, junto
+// con el nombre de cada materia
+func sesionesInscritas(repos *repository.Repositorios, cedula, excluida string) ([]domain.Sesion, map[string]string, error) {
+	historial, err := repos.Inscripciones.HistorialByEstudiante(cedula)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al consultar las inscripciones del estudiante %s: %w", cedula, err)
+	}
+	grupos := make(map[string]int)
+	nombres := make(map[string]string)
+	for _, i := range historial {
+		if i.Periodo == repos.Periodo() && i.Materia.Codigo != excluida {
+			grupos[i.Materia.Codigo] = i.Grupo
+			nombres[i.Materia.Codigo] = i.Materia.Nombre
+		}
+	}
+	if len(grupos) == 0 {
+		return nil, nombres, nil
+	}
+
+	todas, err := repos.Horarios.GetAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al obtener los horarios: %w", err)
+	}
+	var sesiones []domain.Sesion
+	for _, sesion := range todas {
+		if grupo, inscrita := grupos[sesion.Materia]; inscrita && sesion.AplicaA(grupo) {
+			sesiones = append(sesiones, sesion)
+		}
+	}
+	return sesiones, nombres, nil
+}
+
+// buscarCruce retorna el primer cruce entre las sesiones que tendría el estudiante en el
+// grupo de la materia y las de sus demás materias del periodo; nil si no hay ninguno
+func buscarCruce(repos *repository.Repositorios, cedula, codigo string, grupo int) (*domain.Cruce, error) {
+	propias, err := repos.Horarios.GetByMateria(codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener el horario de %s: %w", codigo, err)
+	}
+	var nuevas []domain.Sesion
+	for _, sesion := range propias {
+		if sesion.AplicaA(grupo) {
+			nuevas = append(nuevas, sesion)
+		}
+	}
+	if len(nuevas) == 0 {
+		return nil, nil
+	}
+	otras, _, err := sesionesInscritas(repos, cedula, codigo)
+	if err != nil {
+		return nil, err
+	}
+	for _, nueva := range nuevas {
+		for _, otra := range otras {
+			if nueva.SeCruzaCon(otra) {
+				return &domain.Cruce{A: nueva, B: otra}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// verificarCruces falla con ErrCruceHorario, nombrando el par de materias, si inscribir al
+// estudiante en el grupo de la materia le cruzaría el horario
+func verificarCruces(repos *repository.Repositorios, cedula, codigo string, grupo int) error {
+	cruce, err := buscarCruce(repos, cedula, codigo, grupo)
+	if err != nil {
+		return err
+	}
+	if cruce != nil {
+		return errorCruce(cedula, *cruce)
+	}
+	return nil
+}
+
+func errorCruce(cedula string, c domain.Cruce) error {
+	return fmt.Errorf("%w: para %s, %s (%s) se cruza con %s (%s)",
+		ErrCruceHorario, cedula, c.A.Materia, c.A.Franja(), c.B.Materia, c.B.Franja())
+}
+
+// nuevaSesion interpreta y valida los datos de una sesión escritos en la consola
+func nuevaSesion(codigo string, grupo int, dia, inicio, fin, salon string) (domain.Sesion, error) {
+	sesion := domain.Sesion{Materia: strings.TrimSpace(codigo), Grupo: grupo, Salon: strings.TrimSpace(salon)}
+	if grupo != domain.SinGrupo {
+		if err := validarNumeroGrupo(grupo); err != nil {
+			return domain.Sesion{}, err
+		}
+	}
+	var err error
+	if sesion.Dia, err = domain.ParseDia(dia); err != nil {
+		return domain.Sesion{}, err
+	}
+	if sesion.Inicio, err = domain.ParseHora(inicio); err != nil {
+		return domain.Sesion{}, err
+	}
+	if sesion.Fin, err = domain.ParseHora(fin); err != nil {
+		return domain.Sesion{}, err
+	}
+	if sesion.Fin <= sesion.Inicio {
+		return domain.Sesion{}, fmt.Errorf("la sesión debe terminar después de empezar: %s-%s",
+			domain.FormatearHora(sesion.Inicio), domain.FormatearHora(sesion.Fin))
+	}
+	if sesion.Salon == "" {
+		return domain.Sesion{}, fmt.Errorf("el salón de la sesión no puede estar vacío")
+	}
+	return sesion, nil
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// franjas resume las sesiones del horario como "materia día inicio-fin"
+func franjas(horario *HorarioEstudiante) []string {
+	resultado := []string{}
+	for _, s := range horario.Sesiones {
+		resultado = append(resultado, s.Materia+" "+s.Franja())
+	}
+	return resultado
+}
+
+func TestInsertarNuevoRegistroConCruceDeHorario(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+	grupos := NewGruposService(repos)
+	horarios := NewHorariosService(repos)
+
+	if _, err := svc.InsertarNuevoRegistroEnGrupo("1234567", "Lulú López", "1040", "Cálculo", 4, 1); err != nil {
+		t.Fatalf("InsertarNuevoRegistroEnGrupo: %v", err)
+	}
+	if err := svc.InsertarNuevoRegistro("9876534", "Pepito Pérez", "1050", "Física I"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	if err := grupos.CrearGrupo("1040", 2); err != nil {
+		t.Fatalf("CrearGrupo: %v", err)
+	}
+	for _, s := range []struct {
+		codigo           string
+		grupo            int
+		dia, inicio, fin string
+	}{
+		{"1040", 1, "lunes", "08:00", "10:00"},
+		{"1040", 2, "Martes", "08:00", "10:00"},
+		{"1050", domain.SinGrupo, "1", "09:00", "11:00"},
+	} {
+		if err := horarios.AgregarSesion(s.codigo, s.grupo, s.dia, s.inicio, s.fin, "A-101"); err != nil {
+			t.Fatalf("AgregarSesion(%s, %d): %v", s.codigo, s.grupo, err)
+		}
+	}
+
+	// Física se cruza el lunes con el grupo 1 de Cálculo: el error nombra las dos materias
+	err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1050", "Física I")
+	if !errors.Is(err, ErrCruceHorario) || !strings.Contains(err.Error(), "1050 (Lunes 09:00-11:00)") || !strings.Contains(err.Error(), "1040 (Lunes 08:00-10:00)") {
+		t.Fatalf("InsertarNuevoRegistro con cruce = %v, se esperaba ErrCruceHorario entre 1050 y 1040", err)
+	}
+	if existe, _ := repos.Inscripciones.Exists("1234567", "1050"); existe {
+		t.Fatal("la inscripción con cruce quedó guardada")
+	}
+
+	// En el grupo 2 de Cálculo ya no hay cruce, y volver al grupo 1 lo crearía
+	if err := grupos.AsignarGrupo("1234567", "1040", 2); err != nil {
+		t.Fatalf("AsignarGrupo: %v", err)
+	}
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1050", "Física I"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro sin cruce: %v", err)
+	}
+	if err := grupos.AsignarGrupo("1234567", "1040", 1); !errors.Is(err, ErrCruceHorario) {
+		t.Fatalf("AsignarGrupo con cruce = %v, se esperaba ErrCruceHorario", err)
+	}
+
+	horario, err := horarios.HorarioDeEstudiante("1234567")
+	if err != nil {
+		t.Fatalf("HorarioDeEstudiante: %v", err)
+	}
+	esperadas := []string{"1050 Lunes 09:00-11:00", "1040 Martes 08:00-10:00"}
+	if !reflect.DeepEqual(franjas(horario), esperadas) || len(horario.Cruces) != 0 {
+		t.Fatalf("HorarioDeEstudiante = %v con cruces %v, se esperaba %v sin cruces", franjas(horario), horario.Cruces, esperadas)
+	}
+	if horario.Sesiones[0].NombreMateria != "Física I" {
+		t.Fatalf("NombreMateria = %q, se esperaba Física I", horario.Sesiones[0].NombreMateria)
+	}
+
+	// Una sesión nueva de Física para todos sus inscritos aparece en el horario como cruce
+	if err := horarios.AgregarSesion("1050", domain.SinGrupo, "martes", "09:30", "10:30", "B-202"); err != nil {
+		t.Fatalf("AgregarSesion: %v", err)
+	}
+	horario, _ = horarios.HorarioDeEstudiante("1234567")
+	if len(horario.Cruces) != 1 || horario.Cruces[0].A.Materia != "1040" || horario.Cruces[0].B.Materia != "1050" {
+		t.Fatalf("Cruces = %+v, se esperaba el de 1040 con 1050 el martes", horario.Cruces)
+	}
+	if horario, _ := horarios.HorarioDeEstudiante("0000000"); horario != nil {
+		t.Fatalf("HorarioDeEstudiante de un estudiante inexistente = %+v, se esperaba nil", horario)
+	}
+}
+
+func TestAgregarSesionValidaLosDatos(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+	horarios := NewHorariosService(repos)
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	if err := horarios.AgregarSesion("1040", domain.SinGrupo, "miercoles", "14:00", "16:00", "A-101"); err != nil {
+		t.Fatalf("AgregarSesion: %v", err)
+	}
+
+	for _, caso := range []struct {
+		nombre                  string
+		dia, inicio, fin, salon string
+	}{
+		{"día inexistente", "feriado", "08:00", "10:00", "A-101"},
+		{"hora mal escrita", "lunes", "8", "10:00", "A-101"},
+		{"fin antes del inicio", "lunes", "10:00", "08:00", "A-101"},
+		{"sin salón", "lunes", "08:00", "10:00", " "},
+	} {
+		if err := horarios.AgregarSesion("1040", domain.SinGrupo, caso.dia, caso.inicio, caso.fin, caso.salon); err == nil {
+			t.Errorf("AgregarSesion con %s: se esperaba error", caso.nombre)
+		}
+	}
+
+	// Dos sesiones de la misma materia para los mismos inscritos no pueden cruzarse
+	if err := horarios.AgregarSesion("1040", domain.SinGrupo, "Miércoles", "15:00", "17:00", "B-202"); !errors.Is(err, ErrCruceHorario) {
+		t.Fatalf("AgregarSesion cruzada = %v, se esperaba ErrCruceHorario", err)
+	}
+	if err := horarios.AgregarSesion("1040", domain.SinGrupo, "3", "14:00", "15:00", "B-202"); !errors.Is(err, repository.ErrDuplicado) {
+		t.Fatalf("AgregarSesion repetida = %v, se esperaba ErrDuplicado", err)
+	}
+	if err := horarios.AgregarSesion("1040", domain.SinGrupo, "miércoles", "16:00", "18:00", "B-202"); err != nil {
+		t.Fatalf("AgregarSesion a continuación de otra: %v", err)
+	}
+
+	sesiones, _ := horarios.SesionesDeMateria("1040")
+	if len(sesiones) != 2 {
+		t.Fatalf("SesionesDeMateria = %+v, se esperaban 2", sesiones)
+	}
+	if err := horarios.QuitarSesion(sesiones[0]); err != nil {
+		t.Fatalf("QuitarSesion: %v", err)
+	}
+	if err := horarios.QuitarSesion(sesiones[0]); !errors.Is(err, repository.ErrNoEncontrado) {
+		t.Fatalf("QuitarSesion repetida = %v, se esperaba ErrNoEncontrado", err)
+	}
+}
+
+func TestProcesarArchivoOmiteLosCruces(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+	horarios := NewHorariosService(repos)
+	for _, m := range []*domain.Materia{domain.NewMateria("1040", "Cálculo"), domain.NewMateria("1050", "Física I")} {
+		if err := repos.Materias.Create(m); err != nil {
+			t.Fatalf("Create materia: %v", err)
+		}
+	}
+	if err := horarios.AgregarSesion("1040", domain.SinGrupo, "lunes", "08:00", "10:00", "A-101"); err != nil {
+		t.Fatalf("AgregarSesion: %v", err)
+	}
+	if err := horarios.AgregarSesion("1050", domain.SinGrupo, "lunes", "09:00", "11:00", "B-202"); err != nil {
+		t.Fatalf("AgregarSesion: %v", err)
+	}
+
+	// Pepito, Ana y Juan ya están en Cálculo cuando llega su línea de Física, que se omite
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_validas.txt"); err != nil {
+		t.Fatalf("ProcesarArchivo: %v", err)
+	}
+	inscritos, _ := repos.Inscripciones.GetByMateria("1050")
+	if len(inscritos) != 1 || inscritos[0].Cedula != "4567766" {
+		t.Fatalf("inscritos en 1050 = %+v, se esperaba solo a Calvin", inscritos)
+	}
+	if inscritos, _ := repos.Inscripciones.GetByMateria("1040"); len(inscritos) != 4 {
+		t.Fatalf("inscritos en 1040 = %d, se esperaban 4", len(inscritos))
+	}
+}
+
+func TestPromocionRespetaElHorario(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	cupos := NewCuposService(repos)
+	horarios := NewHorariosService(repos)
+	for _, e := range [][2]string{{"1234567", "Lulú López"}, {"9876534", "Pepito Pérez"}} {
+		if err := repos.Estudiantes.Create(domain.NewEstudiante(e[0], e[1])); err != nil {
+			t.Fatalf("Create estudiante: %v", err)
+		}
+	}
+	calculo := domain.NewMateria("1040", "Cálculo")
+	calculo.Cupo = 1
+	for _, m := range []*domain.Materia{calculo, domain.NewMateria("1050", "Física I")} {
+		if err := repos.Materias.Create(m); err != nil {
+			t.Fatalf("Create materia: %v", err)
+		}
+	}
+	if err := horarios.AgregarSesion("1040", domain.SinGrupo, "jueves", "10:00", "12:00", "A-101"); err != nil {
+		t.Fatalf("AgregarSesion: %v", err)
+	}
+	if err := horarios.AgregarSesion("1050", domain.SinGrupo, "jueves", "11:00", "13:00", "B-202"); err != nil {
+		t.Fatalf("AgregarSesion: %v", err)
+	}
+	if err := repos.Inscripciones.Create("9876534", "1050"); err != nil {
+		t.Fatalf("Create inscripción: %v", err)
+	}
+	for _, cedula := range []string{"9876534", "1234567"} {
+		if _, err := repos.ListaEspera.Agregar(cedula, "1040", domain.SinGrupo); err != nil {
+			t.Fatalf("Agregar: %v", err)
+		}
+	}
+
+	// Pepito cruzaría Física con Cálculo: conserva su lugar y el cupo pasa a Lulú
+	promovidos, err := cupos.EstablecerCupo("1040", 2)
+	if err != nil {
+		t.Fatalf("EstablecerCupo: %v", err)
+	}
+	if !reflect.DeepEqual(promovidos, []string{"1234567"}) {
+		t.Fatalf("promovidos = %v, se esperaba a Lulú", promovidos)
+	}
+	if posicion, _ := cupos.PosicionEnEspera("9876534", "1040"); posicion != 1 {
+		t.Fatalf("PosicionEnEspera de Pepito = %d, se esperaba 1", posicion)
+	}
+}
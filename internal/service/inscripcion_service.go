@@ -35,6 +35,21 @@ func (s *InscripcionService) ContarMateriasPorEstudiante(cedula string) (int, er
 	return s.InscripcionRepo.CountByEstudiante(cedula)
 }
 
+// ContarMateriasDeTodosLosEstudiantes retorna la cantidad de materias por cédula en una sola consulta
+func (s *InscripcionService) ContarMateriasDeTodosLosEstudiantes() (map[string]int, error) {
+	return s.InscripcionRepo.CountGroupedByEstudiante()
+}
+
+// CreditosDeTodosLosEstudiantes retorna la suma de créditos inscritos por cédula en una sola consulta
+func (s *InscripcionService) CreditosDeTodosLosEstudiantes() (map[string]int, error) {
+	return s.InscripcionRepo.CreditosGroupedByEstudiante()
+}
+
+// ObtenerTodasLasInscripciones retorna todas las inscripciones con estudiante y materia en una sola consulta
+func (s *InscripcionService) ObtenerTodasLasInscripciones() ([]*domain.Inscripcion, error) {
+	return s.InscripcionRepo.GetAll()
+}
+
 func (s *InscripcionService) ExportarDatos() (*domain.ConsolidadoInscripciones, error) {
 	// Obtener todos los estudiantes
 	estudiantes, err := s.EstudianteRepo.GetAll()
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"fmt"
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// PeriodosService administra los periodos académicos de la facultad y da acceso a las
+// consultas, estadísticas y exportaciones de cualquiera de ellos
+type PeriodosService struct {
+	repos   *repository.Repositorios
+	limites domain.LimitesCreditos
+	reglas  domain.ReglasCalificacion
+}
+
+func NewPeriodosService(repos *repository.Repositorios) *PeriodosService {
+	return &PeriodosService{repos: repos, limites: domain.LimitesCreditosPorDefecto, reglas: domain.ReglasCalificacionPorDefecto}
+}
+
+// EstablecerLimitesCreditos cambia la carga de créditos de los servicios de cada periodo
+func (s *PeriodosService) EstablecerLimitesCreditos(limites domain.LimitesCreditos) {
+	s.limites = limites
+}
+
+// EstablecerReglasCalificacion cambia las reglas de la nota definitiva de cada periodo
+func (s *PeriodosService) EstablecerReglasCalificacion(reglas domain.ReglasCalificacion) {
+	s.reglas = reglas
+}
+
+// ResumenPeriodo cuenta las inscripciones vigentes de un periodo y cuántos estudiantes
+// y materias distintos participan en ellas
+type ResumenPeriodo struct {
+	Codigo             string
+	Actual             bool
+	TotalInscripciones int
+	TotalEstudiantes   int
+	TotalMaterias      int
+}
+
+// PeriodoActivo retorna el periodo sobre el que opera la sesión
+func (s *PeriodosService) PeriodoActivo() string {
+	return s.repos.Periodo()
+}
+
+// ResumenPorPeriodo retorna los totales de cada periodo de la facultad, del más antiguo al más reciente
+func (s *PeriodosService) ResumenPorPeriodo() ([]ResumenPeriodo, error) {
+	periodos, err := s.repos.Periodos.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("error al listar periodos: %w", err)
+	}
+
+	resumenes := make([]ResumenPeriodo, 0, len(periodos))
+	for _, periodo := range periodos {
+		repos, err := s.repos.EnPeriodo(periodo.Codigo)
+		if err != nil {
+			return nil, fmt.Errorf("error al abrir el periodo %s: %w", periodo.Codigo, err)
+		}
+		porEstudiante, err := repos.Inscripciones.CountGroupedByEstudiante()
+		if err != nil {
+			return nil, fmt.Errorf("error al resumir el periodo %s: %w", periodo.Codigo, err)
+		}
+		porMateria, err := repos.Inscripciones.CountGroupedByMateria()
+		if err != nil {
+			return nil, fmt.Errorf("error al resumir el periodo %s: %w", periodo.Codigo, err)
+		}
+
+		resumen := ResumenPeriodo{
+			Codigo:           periodo.Codigo,
+			Actual:           periodo.Actual,
+			TotalEstudiantes: len(porEstudiante),
+			TotalMaterias:    len(porMateria),
+		}
+		for _, cantidad := range porEstudiante {
+			resumen.TotalInscripciones += cantidad
+		}
+		resumenes = append(resumenes, resumen)
+	}
+	return resumenes, nil
+}
+
+// CrearPeriodo registra un periodo nuevo sin cambiar el actual
+func (s *PeriodosService) CrearPeriodo(codigo string) error {
+	if err := s.repos.Periodos.Create(codigo); err != nil {
+		return fmt.Errorf("error al crear periodo: %w", err)
+	}
+	return nil
+}
+
+// EstablecerPeriodoActual cambia el periodo con el que empiezan las sesiones siguientes
+func (s *PeriodosService) EstablecerPeriodoActual(codigo string) error {
+	if err := s.repos.Periodos.EstablecerActual(codigo); err != nil {
+		return fmt.Errorf("error al establecer el periodo actual: %w", err)
+	}
+	return nil
+}
+
+// ServiciosDelPeriodo agrupa los servicios que dependen del periodo de las inscripciones
+type ServiciosDelPeriodo struct {
+	Periodo            string
+	Inscripciones      *InscripcionService
+	ConsultasAvanzadas *ConsultasAvanzadasService
+	Eliminacion        *EliminacionService
+	Prerrequisitos     *PrerrequisitosService
+	Cupos              *CuposService
+	Grupos             *GruposService
+	Horarios           *HorariosService
+	Calificaciones     *CalificacionesService
+	Docentes           *DocentesService
+}
+
+// Servicios retorna los servicios de consultas, estadísticas y exportaciones sobre las
+// inscripciones de otro periodo, con el mismo contexto de auditoría que la sesión
+func (s *PeriodosService) Servicios(codigo string) (*ServiciosDelPeriodo, error) {
+	repos, err := s.repos.EnPeriodo(codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al abrir el periodo %s: %w", codigo, err)
+	}
+	consultas := NewConsultasAvanzadasService(repos, repos.Estudiantes, repos.Materias, repos.Inscripciones)
+	consultas.EstablecerLimitesCreditos(s.limites)
+	consultas.EstablecerReglasCalificacion(s.reglas)
+	eliminacion := NewEliminacionService(repos, repos.Estudiantes, repos.Materias, repos.Inscripciones)
+	eliminacion.EstablecerLimitesCreditos(s.limites)
+	cupos := NewCuposService(repos)
+	cupos.EstablecerLimitesCreditos(s.limites)
+	calificaciones := NewCalificacionesService(repos)
+	calificaciones.EstablecerReglas(s.reglas)
+	prerrequisitos := NewPrerrequisitosService(repos)
+	prerrequisitos.EstablecerReglasCalificacion(s.reglas)
+	return &ServiciosDelPeriodo{
+		Periodo:            repos.Periodo(),
+		Inscripciones:      NewInscripcionService(repos.Estudiantes, repos.Materias, repos.Inscripciones),
+		ConsultasAvanzadas: consultas,
+		Eliminacion:        eliminacion,
+		Prerrequisitos:     prerrequisitos,
+		Cupos:              cupos,
+		Grupos:             NewGruposService(repos),
+		Horarios:           NewHorariosService(repos),
+		Calificaciones:     calificaciones,
+		Docentes:           NewDocentesService(repos),
+	}, nil
+}
+
+// reposDelPeriodo retorna los repositorios del periodo, creándolo si todavía no existe
+func reposDelPeriodo(repos *repository.Repositorios, periodo string) (*repository.Repositorios, error) {
+	existe, err := repos.Periodos.Exists(periodo)
+	if err != nil {
+		return nil, fmt.Errorf("error al verificar existencia del periodo %s: %w", periodo, err)
+	}
+	if !existe {
+		if err := repos.Periodos.Create(periodo); err != nil {
+			return nil, err
+		}
+	}
+	return repos.EnPeriodo(periodo)
+}
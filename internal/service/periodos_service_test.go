@@ -0,0 +1,50 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"inscripciones/internal/repository"
+)
+
+func TestResumenPorPeriodo(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	periodos := NewPeriodosService(repos)
+
+	actual := NewConsultasAvanzadasService(repos, repos.Estudiantes, repos.Materias, repos.Inscripciones)
+	if err := actual.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	if err := periodos.CrearPeriodo("2020-2"); err != nil {
+		t.Fatalf("CrearPeriodo: %v", err)
+	}
+	servicios, err := periodos.Servicios("2020-2")
+	if err != nil {
+		t.Fatalf("Servicios: %v", err)
+	}
+	for _, codigo := range []string{"1040", "1050"} {
+		if err := servicios.ConsultasAvanzadas.InsertarNuevoRegistro("7654321", "Ana Ruiz", codigo, "Materia "+codigo); err != nil {
+			t.Fatalf("InsertarNuevoRegistro en 2020-2: %v", err)
+		}
+	}
+
+	resumenes, err := periodos.ResumenPorPeriodo()
+	if err != nil {
+		t.Fatalf("ResumenPorPeriodo: %v", err)
+	}
+	esperados := []ResumenPeriodo{
+		{Codigo: "2020-2", TotalInscripciones: 2, TotalEstudiantes: 1, TotalMaterias: 2},
+		{Codigo: periodos.PeriodoActivo(), Actual: true, TotalInscripciones: 1, TotalEstudiantes: 1, TotalMaterias: 1},
+	}
+	if !reflect.DeepEqual(resumenes, esperados) {
+		t.Fatalf("ResumenPorPeriodo = %+v, se esperaba %+v", resumenes, esperados)
+	}
+
+	if err := periodos.EstablecerPeriodoActual("2020-2"); err != nil {
+		t.Fatalf("EstablecerPeriodoActual: %v", err)
+	}
+	// La sesión sigue en su periodo; el cambio vale para las sesiones siguientes
+	if actual, _ := repos.Periodos.Actual(); actual != "2020-2" || periodos.PeriodoActivo() == "2020-2" {
+		t.Fatalf("Actual = %q, PeriodoActivo = %q", actual, periodos.PeriodoActivo())
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// ErrPrerrequisitosFaltantes indica que el estudiante no ha cursado los prerrequisitos de la materia
+var ErrPrerrequisitosFaltantes = errors.New("el estudiante no ha cursado los prerrequisitos de la materia")
+
+// PrerrequisitosService administra los prerrequisitos entre materias y revisa quién, entre
+// los inscritos del periodo, no los ha cursado
+type PrerrequisitosService struct {
+	repos  *repository.Repositorios
+	reglas domain.ReglasCalificacion
+}
+
+func NewPrerrequisitosService(repos *repository.Repositorios) *PrerrequisitosService {
+	return &PrerrequisitosService{repos: repos, reglas: domain.ReglasCalificacionPorDefecto}
+}
+
+// EstablecerReglasCalificacion cambia las reglas con las que se decide si un prerrequisito
+// cursado quedó reprobado
+func (s *PrerrequisitosService) EstablecerReglasCalificacion(reglas domain.ReglasCalificacion) {
+	s.reglas = reglas
+}
+
+// EslabonCadena es una materia de la cadena de prerrequisitos con su nivel de profundidad
+type EslabonCadena struct {
+	Materia *domain.Materia
+	Nivel   int
+}
+
+// EstudianteSinPrerrequisitos es un inscrito en la materia junto con los prerrequisitos
+// directos que no ha cursado
+type EstudianteSinPrerrequisitos struct {
+	Estudiante *domain.Estudiante
+	Faltantes  []string
+}
+
+// AgregarPrerrequisito registra que para inscribir la materia hay que haber cursado el
+// requisito; falla con repository.ErrCiclo si la materia terminaría requiriéndose a sí misma
+func (s *PrerrequisitosService) AgregarPrerrequisito(materia, requisito string) error {
+	p := domain.Prerrequisito{Materia: strings.TrimSpace(materia), Requisito: strings.TrimSpace(requisito)}
+	if err := s.repos.Prerrequisitos.Create(p); err != nil {
+		return fmt.Errorf("error al agregar prerrequisito: %w", err)
+	}
+	return nil
+}
+
+// QuitarPrerrequisito elimina el prerrequisito; las inscripciones existentes no cambian
+func (s *PrerrequisitosService) QuitarPrerrequisito(materia, requisito string) error {
+	p := domain.Prerrequisito{Materia: strings.TrimSpace(materia), Requisito: strings.TrimSpace(requisito)}
+	if err := s.repos.Prerrequisitos.Delete(p); err != nil {
+		return fmt.Errorf("error al quitar prerrequisito: %w", err)
+	}
+	return nil
+}
+
+// CadenaDePrerrequisitos retorna la materia y sus prerrequisitos directos e indirectos en
+// el orden en que se muestran como árbol; la materia es nil si no existe
+func (s *PrerrequisitosService) CadenaDePrerrequisitos(codigo string) (*domain.Materia, []EslabonCadena, error) {
+	materia, err := s.repos.Materias.GetByCodigo(codigo)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al buscar materia: %w", err)
+	}
+	if materia == nil {
+		return nil, nil, nil
+	}
+	grafo, err := s.repos.Prerrequisitos.GetGrafo()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al obtener prerrequisitos: %w", err)
+	}
+	materias, err := s.repos.Materias.GetAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al obtener materias: %w", err)
+	}
+	porCodigo := make(map[string]*domain.Materia, len(materias))
+	for _, m := range materias {
+		porCodigo[m.Codigo] = m
+	}
+
+	var cadena []EslabonCadena
+	for _, eslabon := range grafo.Cadena(codigo) {
+		cadena = append(cadena, EslabonCadena{Materia: porCodigo[eslabon.Codigo], Nivel: eslabon.Nivel})
+	}
+	return materia, cadena, nil
+}
+
+// EstudiantesSinPrerrequisitos retorna los inscritos en la materia durante el periodo que
+// no han cursado alguno de sus prerrequisitos directos
+func (s *PrerrequisitosService) EstudiantesSinPrerrequisitos(codigo string) ([]EstudianteSinPrerrequisitos, error) {
+	grafo, err := s.repos.Prerrequisitos.GetGrafo()
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener prerrequisitos: %w", err)
+	}
+	if len(grafo[codigo]) == 0 {
+		return nil, nil
+	}
+	estudiantes, err := s.repos.Inscripciones.GetByMateria(codigo)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener inscritos: %w", err)
+	}
+
+	var resultado []EstudianteSinPrerrequisitos
+	for _, e := range estudiantes {
+		faltantes, err := prerrequisitosFaltantes(s.repos, s.reglas, grafo, e.Cedula, codigo)
+		if err != nil {
+			return nil, err
+		}
+		if len(faltantes) > 0 {
+			resultado = append(resultado, EstudianteSinPrerrequisitos{Estudiante: e, Faltantes: faltantes})
+		}
+	}
+	return resultado, nil
+}
+
+// prerrequisitosFaltantes retorna los prerrequisitos directos de la materia que el
+// estudiante no ha cursado. Una materia cuenta como cursada si el estudiante tiene una
+// inscripción vigente en ella en un periodo anterior al de los repositorios cuya definitiva
+// no está completa y reprobada según las reglas; las que aún no tienen todas sus notas cuentan.
+// Las inscripciones y las notas del estudiante se leen de una vez, y los componentes una vez
+// por prerrequisito intentado.
+func prerrequisitosFaltantes(repos *repository.Repositorios, reglas domain.ReglasCalificacion, grafo domain.GrafoPrerrequisitos, cedula, codigo string) ([]string, error) {
+	requisitos := grafo[codigo]
+	if len(requisitos) == 0 {
+		return nil, nil
+	}
+	historial, err := repos.Inscripciones.HistorialByEstudiante(cedula)
+	if err != nil {
+		return nil, fmt.Errorf("error al consultar el historial del estudiante %s: %w", cedula, err)
+	}
+	esRequisito := make(map[string]bool, len(requisitos))
+	for _, requisito := range requisitos {
+		esRequisito[requisito] = true
+	}
+	var intentos []*domain.Inscripcion
+	for _, i := range historial {
+		if i.Periodo < repos.Periodo() && esRequisito[i.Materia.Codigo] {
+			intentos = append(intentos, i)
+		}
+	}
+
+	cursadas := make(map[string]bool)
+	if len(intentos) > 0 {
+		calificaciones, err := repos.Calificaciones.HistorialByEstudiante(cedula)
+		if err != nil {
+			return nil, fmt.Errorf("error al obtener las notas del estudiante %s: %w", cedula, err)
+		}
+		notas := notasPorPeriodo(calificaciones)
+		componentes := make(map[string][]domain.Componente)
+		for _, i := range intentos {
+			requisito := i.Materia.Codigo
+			if cursadas[requisito] {
+				continue
+			}
+			if _, ok := componentes[requisito]; !ok {
+				if componentes[requisito], err = repos.Componentes.GetByMateria(requisito); err != nil {
+					return nil, fmt.Errorf("error al obtener los componentes de %s: %w", requisito, err)
+				}
+			}
+			definitiva := reglas.Definitiva(componentes[requisito], notas[claveNotasPeriodo{i.Periodo, requisito}])
+			cursadas[requisito] = !definitiva.Completa || definitiva.Aprobada
+		}
+	}
+
+	var faltantes []string
+	for _, requisito := range requisitos {
+		if !cursadas[requisito] {
+			faltantes = append(faltantes, requisito)
+		}
+	}
+	return faltantes, nil
+}
+
+// verificarPrerrequisitos falla con ErrPrerrequisitosFaltantes si el estudiante no ha
+// cursado todos los prerrequisitos directos de la materia
+func verificarPrerrequisitos(repos *repository.Repositorios, reglas domain.ReglasCalificacion, cedula, codigo string) error {
+	grafo, err := repos.Prerrequisitos.GetGrafo()
+	if err != nil {
+		return fmt.Errorf("error al obtener prerrequisitos: %w", err)
+	}
+	faltantes, err := prerrequisitosFaltantes(repos, reglas, grafo, cedula, codigo)
+	if err != nil {
+		return err
+	}
+	if len(faltantes) > 0 {
+		return fmt.Errorf("%w: a %s le faltan %s para inscribir %s",
+			ErrPrerrequisitosFaltantes, cedula, strings.Join(faltantes, ", "), codigo)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+)
+
+// cursarEn inscribe al estudiante en la materia en otro periodo, creándolo si hace falta
+func cursarEn(t *testing.T, repos *repository.Repositorios, periodo, cedula, codigo string) {
+	t.Helper()
+	anterior, err := reposDelPeriodo(repos, periodo)
+	if err != nil {
+		t.Fatalf("reposDelPeriodo(%q): %v", periodo, err)
+	}
+	if err := anterior.Inscripciones.Create(cedula, codigo); err != nil {
+		t.Fatalf("Create en %s: %v", periodo, err)
+	}
+}
+
+func TestInsertarNuevoRegistroRechazaPrerrequisitosFaltantes(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+	prerrequisitos := NewPrerrequisitosService(repos)
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	if err := repos.Materias.Create(domain.NewMateria("1050", "Física I")); err != nil {
+		t.Fatalf("Create materia: %v", err)
+	}
+	if err := prerrequisitos.AgregarPrerrequisito("1050", "1040"); err != nil {
+		t.Fatalf("AgregarPrerrequisito: %v", err)
+	}
+	if err := prerrequisitos.AgregarPrerrequisito("1040", "1050"); !errors.Is(err, repository.ErrCiclo) {
+		t.Fatalf("AgregarPrerrequisito inverso = %v, se esperaba ErrCiclo", err)
+	}
+
+	// Estar inscrito en Cálculo en el mismo periodo no basta: hay que haberla cursado antes
+	err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1050", "Física I")
+	if !errors.Is(err, ErrPrerrequisitosFaltantes) {
+		t.Fatalf("InsertarNuevoRegistro sin prerrequisito = %v, se esperaba ErrPrerrequisitosFaltantes", err)
+	}
+	if existe, _ := repos.Inscripciones.Exists("1234567", "1050"); existe {
+		t.Fatal("la inscripción quedó guardada aunque faltaban prerrequisitos")
+	}
+
+	cursarEn(t, repos, "2020-2", "1234567", "1040")
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1050", "Física I"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro con el prerrequisito cursado: %v", err)
+	}
+
+	if err := prerrequisitos.QuitarPrerrequisito("1050", "1040"); err != nil {
+		t.Fatalf("QuitarPrerrequisito: %v", err)
+	}
+	if err := prerrequisitos.QuitarPrerrequisito("1050", "1040"); !errors.Is(err, repository.ErrNoEncontrado) {
+		t.Fatalf("QuitarPrerrequisito repetido = %v, se esperaba ErrNoEncontrado", err)
+	}
+}
+
+func TestPrerrequisitoReprobadoNoCuenta(t *testing.T) {
+	svc, repos := nuevaConsultasEnMemoria()
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1040", "Cálculo"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro: %v", err)
+	}
+	if err := repos.Materias.Create(domain.NewMateria("1050", "Física I")); err != nil {
+		t.Fatalf("Create materia: %v", err)
+	}
+	if err := NewPrerrequisitosService(repos).AgregarPrerrequisito("1050", "1040"); err != nil {
+		t.Fatalf("AgregarPrerrequisito: %v", err)
+	}
+	cursarEn(t, repos, "2020-2", "1234567", "1040")
+	anterior, _ := reposDelPeriodo(repos, "2020-2")
+	calificaciones := NewCalificacionesService(anterior)
+	if err := calificaciones.AgregarComponente("1040", "Final", 100); err != nil {
+		t.Fatalf("AgregarComponente: %v", err)
+	}
+
+	// Mientras la definitiva de 2020-2 no esté completa, Cálculo cuenta como cursada;
+	// reprobada ya no
+	if err := calificaciones.RegistrarNota("1234567", "1040", "Final", "2.0"); err != nil {
+		t.Fatalf("RegistrarNota: %v", err)
+	}
+	err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1050", "Física I")
+	if !errors.Is(err, ErrPrerrequisitosFaltantes) {
+		t.Fatalf("InsertarNuevoRegistro con el prerrequisito reprobado = %v, se esperaba ErrPrerrequisitosFaltantes", err)
+	}
+
+	// La nota aprobatoria es la de las reglas configuradas
+	if err := calificaciones.RegistrarNota("1234567", "1040", "Final", "3.5"); err != nil {
+		t.Fatalf("RegistrarNota: %v", err)
+	}
+	exigentes := domain.ReglasCalificacionPorDefecto
+	exigentes.NotaAprobatoria = 4.0
+	svc.EstablecerReglasCalificacion(exigentes)
+	err = svc.InsertarNuevoRegistro("1234567", "Lulú López", "1050", "Física I")
+	if !errors.Is(err, ErrPrerrequisitosFaltantes) {
+		t.Fatalf("InsertarNuevoRegistro con 3.5 y nota aprobatoria 4.0 = %v, se esperaba ErrPrerrequisitosFaltantes", err)
+	}
+	svc.EstablecerReglasCalificacion(domain.ReglasCalificacionPorDefecto)
+	if err := svc.InsertarNuevoRegistro("1234567", "Lulú López", "1050", "Física I"); err != nil {
+		t.Fatalf("InsertarNuevoRegistro con el prerrequisito aprobado: %v", err)
+	}
+}
+
+func TestProcesarArchivoAdvierteSinPrerrequisitos(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+	prerrequisitos := NewPrerrequisitosService(repos)
+	for _, m := range [][2]string{{"1040", "Cálculo"}, {"1050", "Física I"}} {
+		if err := repos.Materias.Create(domain.NewMateria(m[0], m[1])); err != nil {
+			t.Fatalf("Create materia: %v", err)
+		}
+	}
+	if err := prerrequisitos.AgregarPrerrequisito("1050", "1040"); err != nil {
+		t.Fatalf("AgregarPrerrequisito: %v", err)
+	}
+	if err := repos.Estudiantes.Create(domain.NewEstudiante("9876534", "Pepito Pérez")); err != nil {
+		t.Fatalf("Create estudiante: %v", err)
+	}
+	cursarEn(t, repos, "2020-2", "9876534", "1040")
+
+	// El archivo inscribe a cuatro estudiantes en Física I; solo Pepito cursó Cálculo antes
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_validas.txt"); err != nil {
+		t.Fatalf("ProcesarArchivo: %v", err)
+	}
+	if inscritos, _ := repos.Inscripciones.GetByMateria("1050"); len(inscritos) != 4 {
+		t.Fatalf("inscritos en 1050 = %d, las inscripciones sin prerrequisitos también se guardan", len(inscritos))
+	}
+
+	faltantes, err := prerrequisitos.EstudiantesSinPrerrequisitos("1050")
+	if err != nil {
+		t.Fatalf("EstudiantesSinPrerrequisitos: %v", err)
+	}
+	cedulas := make(map[string]bool)
+	for _, f := range faltantes {
+		if len(f.Faltantes) != 1 || f.Faltantes[0] != "1040" {
+			t.Fatalf("faltantes de %s = %v, se esperaba 1040", f.Estudiante.Cedula, f.Faltantes)
+		}
+		cedulas[f.Estudiante.Cedula] = true
+	}
+	if len(cedulas) != 3 || cedulas["9876534"] {
+		t.Fatalf("EstudiantesSinPrerrequisitos = %v, se esperaban todos menos Pepito", cedulas)
+	}
+}
+
+func TestCadenaDePrerrequisitos(t *testing.T) {
+	repos := repository.NewRepositoriosEnMemoria()
+	svc := NewPrerrequisitosService(repos)
+	for _, m := range [][2]string{{"1040", "Cálculo"}, {"1045", "Álgebra"}, {"1050", "Física I"}, {"1060", "Física II"}} {
+		if err := repos.Materias.Create(domain.NewMateria(m[0], m[1])); err != nil {
+			t.Fatalf("Create materia: %v", err)
+		}
+	}
+	for _, p := range [][2]string{{"1060", "1050"}, {"1060", "1045"}, {"1050", "1040"}} {
+		if err := svc.AgregarPrerrequisito(p[0], p[1]); err != nil {
+			t.Fatalf("AgregarPrerrequisito: %v", err)
+		}
+	}
+
+	materia, cadena, err := svc.CadenaDePrerrequisitos("1060")
+	if err != nil || materia == nil || materia.Nombre != "Física II" {
+		t.Fatalf("CadenaDePrerrequisitos = %+v, %v; se esperaba Física II", materia, err)
+	}
+	esperada := []struct {
+		codigo string
+		nivel  int
+	}{{"1045", 1}, {"1050", 1}, {"1040", 2}}
+	if len(cadena) != len(esperada) {
+		t.Fatalf("cadena con %d eslabones, se esperaban %d", len(cadena), len(esperada))
+	}
+	for i, e := range esperada {
+		if cadena[i].Materia.Codigo != e.codigo || cadena[i].Nivel != e.nivel {
+			t.Fatalf("eslabón %d = %s nivel %d, se esperaba %s nivel %d", i, cadena[i].Materia.Codigo, cadena[i].Nivel, e.codigo, e.nivel)
+		}
+	}
+
+	if materia, _, _ := svc.CadenaDePrerrequisitos("9999"); materia != nil {
+		t.Fatalf("CadenaDePrerrequisitos inexistente = %+v, se esperaba nil", materia)
+	}
+}
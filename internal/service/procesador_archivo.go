@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"inscripciones/internal/domain"
@@ -9,27 +10,46 @@ import (
 )
 
 type ProcesadorArchivo struct {
-	lector          fileutil.LectorArchivo
-	estudianteRepo  repository.EstudianteRepository
-	materiaRepo     repository.MateriaRepository
-	inscripcionRepo repository.InscripcionRepository
+	lector  fileutil.LectorArchivo
+	unidad  repository.UnidadDeTrabajo
+	limites domain.LimitesCreditos
+	reglas  domain.ReglasCalificacion
 }
 
+// NewProcesadorArchivo crea el procesador; cada archivo se guarda en una sola unidad de
+// trabajo, de modo que un error a mitad de la importación no deja datos a medias
 func NewProcesadorArchivo(
 	lector fileutil.LectorArchivo,
-	estudianteRepo repository.EstudianteRepository,
-	materiaRepo repository.MateriaRepository,
-	inscripcionRepo repository.InscripcionRepository,
+	unidad repository.UnidadDeTrabajo,
 ) *ProcesadorArchivo {
 	return &ProcesadorArchivo{
-		lector:          lector,
-		estudianteRepo:  estudianteRepo,
-		materiaRepo:     materiaRepo,
-		inscripcionRepo: inscripcionRepo,
+		lector:  lector,
+		unidad:  unidad,
+		limites: domain.LimitesCreditosPorDefecto,
+		reglas:  domain.ReglasCalificacionPorDefecto,
 	}
 }
 
+// EstablecerLimitesCreditos cambia la carga de créditos permitida; las inscripciones del
+// archivo que la superen se omiten con una advertencia
+func (p *ProcesadorArchivo) EstablecerLimitesCreditos(limites domain.LimitesCreditos) {
+	p.limites = limites
+}
+
+// EstablecerReglasCalificacion cambia las reglas con las que se decide si un prerrequisito
+// cursado quedó reprobado
+func (p *ProcesadorArchivo) EstablecerReglasCalificacion(reglas domain.ReglasCalificacion) {
+	p.reglas = reglas
+}
+
+// ProcesarArchivo guarda las inscripciones del archivo en el periodo de la sesión
 func (p *ProcesadorArchivo) ProcesarArchivo(ruta string) (*domain.ConsolidadoInscripciones, error) {
+	return p.ProcesarArchivoEnPeriodo(ruta, "")
+}
+
+// ProcesarArchivoEnPeriodo guarda las inscripciones del archivo en el periodo académico
+// indicado, que se crea si todavía no existe; vacío es el periodo de la sesión
+func (p *ProcesadorArchivo) ProcesarArchivoEnPeriodo(ruta, periodo string) (*domain.ConsolidadoInscripciones, error) {
 	lineas, err := p.lector.ObtenerLineas(ruta)
 	if err != nil {
 		return nil, fmt.Errorf("error al leer archivo: %w", err)
@@ -56,9 +76,13 @@ func (p *ProcesadorArchivo) ProcesarArchivo(ruta string) (*domain.ConsolidadoIns
 			consolidado.Estudiantes[cedula] = domain.NewEstudiante(cedula, nombreEstudiante)
 		}
 
-		// Agregar materia si no existe en el consolidado
+		// Agregar materia si no existe en el consolidado; los créditos son opcionales
 		if _, ok := consolidado.Materias[codigoMateria]; !ok {
-			consolidado.Materias[codigoMateria] = domain.NewMateria(codigoMateria, nombreMateria)
+			materia := domain.NewMateria(codigoMateria, nombreMateria)
+			if len(campos) >= 5 && strings.TrimSpace(campos[4]) != "" {
+				materia.Creditos, _ = validarCreditos(campos[4])
+			}
+			consolidado.Materias[codigoMateria] = materia
 		}
 
 		lineasValidas = append(lineasValidas, linea)
@@ -68,8 +92,16 @@ func (p *ProcesadorArchivo) ProcesarArchivo(ruta string) (*domain.ConsolidadoIns
 		return nil, fmt.Errorf("no se encontraron líneas válidas en el archivo")
 	}
 
-	// Guardar en base de datos
-	err = p.guardarEnBaseDatos(consolidado, lineasValidas)
+	// Guardar en base de datos: todo el archivo o nada
+	err = p.unidad.EnTransaccion(func(repos *repository.Repositorios) error {
+		if periodo = strings.TrimSpace(periodo); periodo != "" {
+			var err error
+			if repos, err = reposDelPeriodo(repos, periodo); err != nil {
+				return err
+			}
+		}
+		return p.guardarEnBaseDatos(repos, consolidado, lineasValidas)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error al guardar en base de datos: %w", err)
 	}
@@ -83,13 +115,13 @@ func (p *ProcesadorArchivo) validarLinea(linea string, _ int) error {
 	}
 
 	campos := strings.Split(linea, ",")
-	if len(campos) != 4 {
-		return fmt.Errorf("formato incorrecto - se esperan 4 campos separados por coma, encontrados %d", len(campos))
+	if len(campos) < 4 || len(campos) > 6 {
+		return fmt.Errorf("formato incorrecto - se esperan de 4 a 6 campos separados por coma, encontrados %d", len(campos))
 	}
 
-	// Validar que ningún campo esté vacío
+	// Validar que ningún campo esté vacío; con grupo, los créditos pueden omitirse
 	for i, campo := range campos {
-		if strings.TrimSpace(campo) == "" {
+		if strings.TrimSpace(campo) == "" && !(i == 4 && len(campos) == 6) {
 			return fmt.Errorf("campo %d está vacío", i+1)
 		}
 	}
@@ -116,59 +148,154 @@ func (p *ProcesadorArchivo) validarLinea(linea string, _ int) error {
 		return fmt.Errorf("nombre de materia '%s' debe tener al menos 2 caracteres", nombreMateria)
 	}
 
+	if len(campos) >= 5 && strings.TrimSpace(campos[4]) != "" {
+		if _, err := validarCreditos(campos[4]); err != nil {
+			return err
+		}
+	}
+
+	if len(campos) == 6 {
+		if _, err := validarGrupo(campos[5]); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (p *ProcesadorArchivo) guardarEnBaseDatos(consolidado *domain.ConsolidadoInscripciones, lineas []string) error {
+func (p *ProcesadorArchivo) guardarEnBaseDatos(repos *repository.Repositorios, consolidado *domain.ConsolidadoInscripciones, lineas []string) error {
 	// Guardar estudiantes
 	for _, estudiante := range consolidado.Estudiantes {
-		exists, err := p.estudianteRepo.Exists(estudiante.Cedula)
+		exists, err := repos.Estudiantes.Exists(estudiante.Cedula)
 		if err != nil {
 			return fmt.Errorf("error al verificar existencia del estudiante %s: %w", estudiante.Cedula, err)
 		}
 		if !exists {
-			err = p.estudianteRepo.Create(estudiante)
+			err = repos.Estudiantes.Create(estudiante)
+			if errors.Is(err, repository.ErrEliminado) {
+				// Un estudiante eliminado no se recrea desde un archivo; debe restaurarse explícitamente
+				fmt.Printf("Advertencia: el estudiante %s está eliminado, se omiten sus inscripciones\n", estudiante.Cedula)
+				continue
+			}
 			if err != nil {
 				return fmt.Errorf("error al crear estudiante %s: %w", estudiante.Cedula, err)
 			}
+			if err := publicar(repos, domain.EstudianteCreado{Cedula: estudiante.Cedula, Nombre: estudiante.Nombre}); err != nil {
+				return err
+			}
 		}
 	}
 
-	// Guardar materias
+	// Guardar materias; las que ya existen conservan sus créditos
+	creditosMateria := make(map[string]int, len(consolidado.Materias))
 	for _, materia := range consolidado.Materias {
-		exists, err := p.materiaRepo.Exists(materia.Codigo)
+		existente, err := repos.Materias.GetByCodigo(materia.Codigo)
 		if err != nil {
 			return fmt.Errorf("error al verificar existencia de la materia %s: %w", materia.Codigo, err)
 		}
-		if !exists {
-			err = p.materiaRepo.Create(materia)
-			if err != nil {
-				return fmt.Errorf("error al crear materia %s: %w", materia.Codigo, err)
-			}
+		if existente != nil {
+			creditosMateria[materia.Codigo] = existente.Creditos
+			continue
+		}
+		err = repos.Materias.Create(materia)
+		if errors.Is(err, repository.ErrEliminado) {
+			fmt.Printf("Advertencia: la materia %s está eliminada, se omiten sus inscripciones\n", materia.Codigo)
+			continue
 		}
+		if err != nil {
+			return fmt.Errorf("error al crear materia %s: %w", materia.Codigo, err)
+		}
+		if err := publicar(repos, domain.MateriaCreada{Codigo: materia.Codigo, Nombre: materia.Nombre}); err != nil {
+			return err
+		}
+		creditosMateria[materia.Codigo] = materia.Creditos
+	}
+
+	// Carga de créditos de cada estudiante en el periodo antes de este archivo
+	cargas, err := repos.Inscripciones.CreditosGroupedByEstudiante()
+	if err != nil {
+		return fmt.Errorf("error al calcular la carga de créditos: %w", err)
+	}
+
+	// Los prerrequisitos no impiden la inscripción desde un archivo: solo se advierte
+	grafo, err := repos.Prerrequisitos.GetGrafo()
+	if err != nil {
+		return fmt.Errorf("error al obtener prerrequisitos: %w", err)
 	}
 
 	// Procesar y guardar inscripciones
 	for _, linea := range lineas {
 		campos := strings.Split(linea, ",")
-		if len(campos) != 4 {
+		if len(campos) < 4 {
 			continue // Esta validación ya se hizo antes, pero por seguridad
 		}
 
 		cedula := strings.TrimSpace(campos[0])
 		codigoMateria := strings.TrimSpace(campos[2])
+		grupo := domain.SinGrupo
+		if len(campos) == 6 {
+			grupo, _ = validarGrupo(campos[5])
+		}
 
 		// Verificar si la inscripción ya existe
-		exists, err := p.inscripcionRepo.Exists(cedula, codigoMateria)
+		exists, err := repos.Inscripciones.Exists(cedula, codigoMateria)
 		if err != nil {
 			return fmt.Errorf("error al verificar existencia de inscripción %s-%s: %w", cedula, codigoMateria, err)
 		}
 
 		if !exists {
-			err = p.inscripcionRepo.Create(cedula, codigoMateria)
+			// Una inscripción que sobrecarga al estudiante se omite, sin abortar el archivo
+			if carga := cargas[cedula] + creditosMateria[codigoMateria]; p.limites.Excede(carga) {
+				fmt.Printf("Advertencia: se omite la inscripción %s-%s: %v\n", cedula, codigoMateria,
+					fmt.Errorf("%w: el estudiante tendría %d créditos (máximo %d)", ErrCargaExcedida, carga, p.limites.Maximo))
+				continue
+			}
+			// También se omite la que cruzaría el horario del estudiante
+			if err := verificarCruces(repos, cedula, codigoMateria, grupo); errors.Is(err, ErrCruceHorario) {
+				fmt.Printf("Advertencia: se omite la inscripción %s-%s: %v\n", cedula, codigoMateria, err)
+				continue
+			} else if err != nil {
+				return err
+			}
+			// Los grupos que nombra el archivo se crean como las materias
+			err = asegurarGrupo(repos, codigoMateria, grupo)
+			if err == nil {
+				err = repos.Inscripciones.CreateEnGrupo(cedula, codigoMateria, grupo)
+			}
+			// Sin cupo, el estudiante pasa a la lista de espera del grupo; si ya esperaba,
+			// conserva su lugar
+			if errors.Is(err, repository.ErrCupoAgotado) {
+				posicion, err := repos.ListaEspera.Agregar(cedula, codigoMateria, grupo)
+				if errors.Is(err, repository.ErrDuplicado) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Advertencia: la materia %s no tiene cupo, el estudiante %s quedó en lista de espera en la posición %d\n",
+					codigoMateria, cedula, posicion)
+				continue
+			}
+			if errors.Is(err, repository.ErrEliminado) || errors.Is(err, repository.ErrReferenciaInvalida) {
+				fmt.Printf("Advertencia: se omite la inscripción %s-%s: %v\n", cedula, codigoMateria, err)
+				continue
+			}
 			if err != nil {
 				return fmt.Errorf("error al crear inscripción %s-%s: %w", cedula, codigoMateria, err)
 			}
+			if err := publicar(repos, domain.InscripcionCreada{Cedula: cedula, Codigo: codigoMateria, Periodo: repos.Periodo()}); err != nil {
+				return err
+			}
+			cargas[cedula] += creditosMateria[codigoMateria]
+
+			faltantes, err := prerrequisitosFaltantes(repos, p.reglas, grafo, cedula, codigoMateria)
+			if err != nil {
+				return err
+			}
+			if len(faltantes) > 0 {
+				fmt.Printf("Advertencia: el estudiante %s se inscribió en %s sin haber cursado %s\n",
+					cedula, codigoMateria, strings.Join(faltantes, ", "))
+			}
 		}
 	}
 
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"inscripciones/internal/repository"
+	"inscripciones/pkg/fileutil"
+)
+
+func nuevoProcesadorEnMemoria() (*ProcesadorArchivo, *repository.Repositorios) {
+	repos := repository.NewRepositoriosEnMemoria()
+	procesador := NewProcesadorArchivo(&fileutil.LectorArchivoTexto{}, repos)
+	return procesador, repos
+}
+
+func TestProcesarArchivoValido(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+
+	consolidado, err := procesador.ProcesarArchivo("../../testdata/inscripciones_validas.txt")
+	if err != nil {
+		t.Fatalf("ProcesarArchivo: %v", err)
+	}
+	if len(consolidado.Estudiantes) != 8 || len(consolidado.Materias) != 5 {
+		t.Fatalf("consolidado con %d estudiantes y %d materias, se esperaban 8 y 5",
+			len(consolidado.Estudiantes), len(consolidado.Materias))
+	}
+
+	count, err := repos.Inscripciones.CountByEstudiante("1234567")
+	if err != nil || count != 2 {
+		t.Fatalf("CountByEstudiante = %d, %v; se esperaba 2", count, err)
+	}
+
+	// Cargar el mismo archivo dos veces no debe duplicar inscripciones
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_validas.txt"); err != nil {
+		t.Fatalf("segunda carga: %v", err)
+	}
+	count, _ = repos.Inscripciones.CountByEstudiante("1234567")
+	if count != 2 {
+		t.Fatalf("la segunda carga duplicó inscripciones: %d", count)
+	}
+}
+
+func TestProcesarArchivoInvalido(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+
+	consolidado, err := procesador.ProcesarArchivo("../../testdata/inscripciones_invalidas.txt")
+	if err != nil {
+		t.Fatalf("ProcesarArchivo: %v", err)
+	}
+
+	// Solo las líneas completas y válidas llegan a los repositorios
+	for _, cedula := range []string{"1234567", "1111111", "5555555"} {
+		if _, ok := consolidado.Estudiantes[cedula]; !ok {
+			t.Errorf("falta el estudiante válido %s", cedula)
+		}
+	}
+	if existe, _ := repos.Estudiantes.Exists("999"); existe {
+		t.Error("se guardó un estudiante con cédula inválida")
+	}
+}
+
+func TestProcesarArchivoRespetaEliminados(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_validas.txt"); err != nil {
+		t.Fatalf("ProcesarArchivo: %v", err)
+	}
+	if err := repos.Estudiantes.Delete("1234567"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	// Recargar el archivo no revive al estudiante eliminado ni aborta la carga
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_validas.txt"); err != nil {
+		t.Fatalf("segunda carga: %v", err)
+	}
+	if existe, _ := repos.Estudiantes.Exists("1234567"); existe {
+		t.Error("la carga restauró al estudiante eliminado")
+	}
+}
+
+func TestProcesarArchivoEnPeriodo(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+
+	if _, err := procesador.ProcesarArchivoEnPeriodo("../../testdata/inscripciones_validas.txt", "2020-2"); err != nil {
+		t.Fatalf("ProcesarArchivoEnPeriodo: %v", err)
+	}
+	// El periodo se crea al importar, sin dejar de ser el actual el de la sesión
+	if actual, _ := repos.Periodos.Actual(); actual != repos.Periodo() {
+		t.Fatalf("Actual = %q, la importación no debía cambiar el periodo actual %q", actual, repos.Periodo())
+	}
+	if count, _ := repos.Inscripciones.CountByEstudiante("1234567"); count != 0 {
+		t.Fatalf("CountByEstudiante en el periodo actual = %d, se esperaba 0", count)
+	}
+	anterior, err := repos.EnPeriodo("2020-2")
+	if err != nil {
+		t.Fatalf("EnPeriodo: %v", err)
+	}
+	if count, _ := anterior.Inscripciones.CountByEstudiante("1234567"); count != 2 {
+		t.Fatalf("CountByEstudiante en 2020-2 = %d, se esperaba 2", count)
+	}
+
+	if _, err := procesador.ProcesarArchivoEnPeriodo("../../testdata/inscripciones_validas.txt", "2020"); !errors.Is(err, repository.ErrPeriodoInvalido) {
+		t.Fatalf("ProcesarArchivoEnPeriodo con periodo inválido = %v, se esperaba ErrPeriodoInvalido", err)
+	}
+}
+
+func TestProcesarArchivoOmiteSobrecarga(t *testing.T) {
+	procesador, repos := nuevoProcesadorEnMemoria()
+
+	if _, err := procesador.ProcesarArchivo("../../testdata/inscripciones_creditos.txt"); err != nil {
+		t.Fatalf("ProcesarArchivo: %v", err)
+	}
+	// El quinto campo fija los créditos de la materia al crearla
+	if m, _ := repos.Materias.GetByCodigo("1060"); m == nil || m.Creditos != 2 {
+		t.Fatalf("GetByCodigo = %+v, se esperaban 2 créditos", m)
+	}
+
+	// Las inscripciones que pasan de 20 créditos se omiten sin abortar la carga
+	creditos, err := repos.Inscripciones.CreditosGroupedByEstudiante()
+	if err != nil {
+		t.Fatalf("CreditosGroupedByEstudiante: %v", err)
+	}
+	if creditos["1234567"] != 10 || creditos["7777777"] != 20 {
+		t.Fatalf("CreditosGroupedByEstudiante = %v, se esperaban 10 y 20", creditos)
+	}
+	if existe, _ := repos.Inscripciones.Exists("7777777", "2050"); existe {
+		t.Fatal("se guardó la inscripción que superaba el máximo de créditos")
+	}
+}
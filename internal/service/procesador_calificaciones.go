@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+	"inscripciones/pkg/fileutil"
+)
+
+// ProcesadorCalificaciones importa notas desde archivos de texto con una línea por nota:
+// cédula, código de materia, componente y nota, separados por coma. La nota lleva punto
+// decimal, porque la coma separa los campos.
+type ProcesadorCalificaciones struct {
+	lector fileutil.LectorArchivo
+	unidad repository.UnidadDeTrabajo
+}
+
+// NewProcesadorCalificaciones crea el procesador; como en NewProcesadorArchivo, cada
+// archivo se guarda en una sola unidad de trabajo
+func NewProcesadorCalificaciones(
+	lector fileutil.LectorArchivo,
+	unidad repository.UnidadDeTrabajo,
+) *ProcesadorCalificaciones {
+	return &ProcesadorCalificaciones{lector: lector, unidad: unidad}
+}
+
+// notaArchivo es una línea válida del archivo de notas
+type notaArchivo struct {
+	linea                      int
+	cedula, codigo, componente string
+	nota                       float64
+}
+
+// ProcesarArchivo guarda las notas del archivo en el periodo de la sesión y retorna
+// cuántas quedaron registradas
+func (p *ProcesadorCalificaciones) ProcesarArchivo(ruta string) (int, error) {
+	return p.ProcesarArchivoEnPeriodo(ruta, "")
+}
+
+// ProcesarArchivoEnPeriodo guarda las notas del archivo en el periodo académico indicado;
+// vacío es el periodo de la sesión. Las líneas mal escritas, las de estudiantes que no
+// están inscritos en la materia y las de componentes que la materia no tiene se omiten con
+// una advertencia.
+func (p *ProcesadorCalificaciones) ProcesarArchivoEnPeriodo(ruta, periodo string) (int, error) {
+	lineas, err := p.lector.ObtenerLineas(ruta)
+	if err != nil {
+		return 0, fmt.Errorf("error al leer archivo: %w", err)
+	}
+
+	var notas []notaArchivo
+	for i, linea := range lineas {
+		nota, err := validarLineaNota(linea)
+		if err != nil {
+			fmt.Printf("Advertencia línea %d: %v\n", i+1, err)
+			continue
+		}
+		nota.linea = i + 1
+		notas = append(notas, nota)
+	}
+	if len(notas) == 0 {
+		return 0, fmt.Errorf("no se encontraron líneas válidas en el archivo")
+	}
+
+	registradas := 0
+	err = p.unidad.EnTransaccion(func(repos *repository.Repositorios) error {
+		if periodo = strings.TrimSpace(periodo); periodo != "" {
+			var err error
+			if repos, err = reposDelPeriodo(repos, periodo); err != nil {
+				return err
+			}
+		}
+		registradas = 0
+		for _, n := range notas {
+			err := repos.Calificaciones.Registrar(n.cedula, n.codigo, n.componente, n.nota)
+			if errors.Is(err, repository.ErrReferenciaInvalida) {
+				fmt.Printf("Advertencia línea %d: se omite la nota: %v\n", n.linea, err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			registradas++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error al guardar en base de datos: %w", err)
+	}
+	return registradas, nil
+}
+
+// validarLineaNota interpreta una línea del archivo de notas
+func validarLineaNota(linea string) (notaArchivo, error) {
+	if strings.TrimSpace(linea) == "" {
+		return notaArchivo{}, fmt.Errorf("línea vacía")
+	}
+	campos := strings.Split(linea, ",")
+	if len(campos) != 4 {
+		return notaArchivo{}, fmt.Errorf("formato incorrecto - se esperan 4 campos separados por coma, encontrados %d", len(campos))
+	}
+	for i, campo := range campos {
+		if strings.TrimSpace(campo) == "" {
+			return notaArchivo{}, fmt.Errorf("campo %d está vacío", i+1)
+		}
+	}
+
+	n := notaArchivo{
+		cedula:     strings.TrimSpace(campos[0]),
+		codigo:     strings.TrimSpace(campos[1]),
+		componente: strings.TrimSpace(campos[2]),
+	}
+	if len(n.cedula) < 6 || len(n.cedula) > 12 {
+		return notaArchivo{}, fmt.Errorf("cédula '%s' debe tener entre 6 y 12 caracteres", n.cedula)
+	}
+	var err error
+	if n.nota, err = domain.ParseNota(campos[3]); err != nil {
+		return notaArchivo{}, err
+	}
+	return n, nil
+}
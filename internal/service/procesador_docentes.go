@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
+	"inscripciones/pkg/fileutil"
+)
+
+// ProcesadorDocentes importa las materias que dicta cada docente desde archivos de texto
+// con una línea por asignación: cédula, nombre del docente, código de materia y,
+// opcionalmente, el grupo, separados por coma. Sin grupo el docente dicta la materia completa.
+type ProcesadorDocentes struct {
+	lector fileutil.LectorArchivo
+	unidad repository.UnidadDeTrabajo
+}
+
+// NewProcesadorDocentes crea el procesador; como en NewProcesadorArchivo, cada archivo se
+// guarda en una sola unidad de trabajo
+func NewProcesadorDocentes(
+	lector fileutil.LectorArchivo,
+	unidad repository.UnidadDeTrabajo,
+) *ProcesadorDocentes {
+	return &ProcesadorDocentes{lector: lector, unidad: unidad}
+}
+
+// asignacionArchivo es una línea válida del archivo de docentes
+type asignacionArchivo struct {
+	linea   int
+	docente *domain.Docente
+	codigo  string
+	grupo   int
+}
+
+// ProcesarArchivo guarda las asignaciones del archivo en el periodo de la sesión y retorna
+// cuántas quedaron registradas
+func (p *ProcesadorDocentes) ProcesarArchivo(ruta string) (int, error) {
+	return p.ProcesarArchivoEnPeriodo(ruta, "")
+}
+
+// ProcesarArchivoEnPeriodo guarda las asignaciones del archivo en el periodo académico
+// indicado; vacío es el periodo de la sesión. Los docentes que no existen se registran con
+// el nombre del archivo. Las líneas mal escritas y las de materias o grupos que no existen
+// se omiten con una advertencia.
+func (p *ProcesadorDocentes) ProcesarArchivoEnPeriodo(ruta, periodo string) (int, error) {
+	lineas, err := p.lector.ObtenerLineas(ruta)
+	if err != nil {
+		return 0, fmt.Errorf("error al leer archivo: %w", err)
+	}
+
+	var asignaciones []asignacionArchivo
+	for i, linea := range lineas {
+		asignacion, err := validarLineaDocente(linea)
+		if err != nil {
+			fmt.Printf("Advertencia línea %d: %v\n", i+1, err)
+			continue
+		}
+		asignacion.linea = i + 1
+		asignaciones = append(asignaciones, asignacion)
+	}
+	if len(asignaciones) == 0 {
+		return 0, fmt.Errorf("no se encontraron líneas válidas en el archivo")
+	}
+
+	registradas := 0
+	err = p.unidad.EnTransaccion(func(repos *repository.Repositorios) error {
+		if periodo = strings.TrimSpace(periodo); periodo != "" {
+			var err error
+			if repos, err = reposDelPeriodo(repos, periodo); err != nil {
+				return err
+			}
+		}
+		registradas = 0
+		for _, a := range asignaciones {
+			docente, err := repos.Docentes.GetByCedula(a.docente.Cedula)
+			if err != nil {
+				return err
+			}
+			if docente == nil {
+				if err := repos.Docentes.Create(a.docente); err != nil {
+					return err
+				}
+			}
+			err = repos.Docentes.Asignar(a.docente.Cedula, a.codigo, a.grupo)
+			if errors.Is(err, repository.ErrReferenciaInvalida) {
+				fmt.Printf("Advertencia línea %d: se omite la asignación: %v\n", a.linea, err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			registradas++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error al guardar en base de datos: %w", err)
+	}
+	return registradas, nil
+}
+
+// validarLineaDocente interpreta una línea del archivo de docentes
+func validarLineaDocente(linea string) (asignacionArchivo, error) {
+	if strings.TrimSpace(linea) == "" {
+		return asignacionArchivo{}, fmt.Errorf("línea vacía")
+	}
+	campos := strings.Split(linea, ",")
+	if len(campos) != 3 && len(campos) != 4 {
+		return asignacionArchivo{}, fmt.Errorf("formato incorrecto - se esperan 3 o 4 campos separados por coma, encontrados %d", len(campos))
+	}
+	for i, campo := range campos {
+		if strings.TrimSpace(campo) == "" {
+			return asignacionArchivo{}, fmt.Errorf("campo %d está vacío", i+1)
+		}
+	}
+
+	docente, err := nuevoDocente(campos[0], campos[1])
+	if err != nil {
+		return asignacionArchivo{}, err
+	}
+	a := asignacionArchivo{docente: docente, codigo: strings.TrimSpace(campos[2]), grupo: domain.SinGrupo}
+	if len(campos) == 4 {
+		if a.grupo, err = validarGrupo(campos[3]); err != nil {
+			return asignacionArchivo{}, err
+		}
+	}
+	return a, nil
+}
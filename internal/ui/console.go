@@ -4,31 +4,92 @@ import (
 	"bufio"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"inscripciones/internal/domain"
+	"inscripciones/internal/repository"
 	"inscripciones/internal/service"
+	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// registrosPorPagina es la cantidad de filas que muestra la consola por página
+const registrosPorPagina = 20
+
+// resultadosBusqueda es la cantidad máxima de coincidencias que muestra la búsqueda por nombre
+const resultadosBusqueda = 10
+
 type ConsoleUI struct {
 	procesador         *service.ProcesadorArchivo
 	inscripcionSvc     *service.InscripcionService
 	consultasAvanzadas *service.ConsultasAvanzadasService
-	consolidado        *domain.ConsolidadoInscripciones
-	archivoCargado     bool
+	historial          *service.HistorialService
+	eliminacion        *service.EliminacionService
+	edicion            *service.EdicionService
+	facultades         *service.FacultadesService
+	periodos           *service.PeriodosService
+	prerrequisitos     *service.PrerrequisitosService
+	cupos              *service.CuposService
+	grupos             *service.GruposService
+	horarios           *service.HorariosService
+	calificaciones     *service.CalificacionesService
+	// procesadorNotas importa las notas desde archivos
+	procesadorNotas    *service.ProcesadorCalificaciones
+	historialAcademico *service.HistorialAcademicoService
+	carreras           *service.CarrerasService
+	docentes           *service.DocentesService
+	// procesadorDocentes importa las materias que dicta cada docente desde archivos
+	procesadorDocentes *service.ProcesadorDocentes
+	// periodo es el periodo académico de las consultas, estadísticas y exportaciones
+	periodo        string
+	consolidado    *domain.ConsolidadoInscripciones
+	archivoCargado bool
 }
 
 func NewConsoleUI(
 	procesador *service.ProcesadorArchivo,
 	inscripcionSvc *service.InscripcionService,
 	consultasAvanzadas *service.ConsultasAvanzadasService,
+	historial *service.HistorialService,
+	eliminacion *service.EliminacionService,
+	edicion *service.EdicionService,
+	facultades *service.FacultadesService,
+	periodos *service.PeriodosService,
+	prerrequisitos *service.PrerrequisitosService,
+	cupos *service.CuposService,
+	grupos *service.GruposService,
+	horarios *service.HorariosService,
+	calificaciones *service.CalificacionesService,
+	procesadorNotas *service.ProcesadorCalificaciones,
+	historialAcademico *service.HistorialAcademicoService,
+	carreras *service.CarrerasService,
+	docentes *service.DocentesService,
+	procesadorDocentes *service.ProcesadorDocentes,
 ) *ConsoleUI {
 	return &ConsoleUI{
 		procesador:         procesador,
 		inscripcionSvc:     inscripcionSvc,
 		consultasAvanzadas: consultasAvanzadas,
+		historial:          historial,
+		eliminacion:        eliminacion,
+		edicion:            edicion,
+		facultades:         facultades,
+		periodos:           periodos,
+		prerrequisitos:     prerrequisitos,
+		cupos:              cupos,
+		grupos:             grupos,
+		horarios:           horarios,
+		calificaciones:     calificaciones,
+		procesadorNotas:    procesadorNotas,
+		historialAcademico: historialAcademico,
+		carreras:           carreras,
+		docentes:           docentes,
+		procesadorDocentes: procesadorDocentes,
+		periodo:            periodos.PeriodoActivo(),
 		consolidado:        domain.NewConsolidadoInscripciones(),
 		archivoCargado:     false,
 	}
@@ -39,13 +100,15 @@ func (c *ConsoleUI) MostrarMenu() {
 
 	for {
 		fmt.Println("\n=== SISTEMA DE INSCRIPCIONES UNIVERSITARIAS ===")
+		fmt.Printf("Facultad: %s | Periodo: %s\n", c.facultades.FacultadActiva(), c.periodo)
 		fmt.Println("1. Cargar archivo de inscripciones")
 		fmt.Println("2. Mostrar total de materias por estudiante")
 		fmt.Println("3. Filtrar estudiantes por materia")
 		fmt.Println("4. Exportar datos a JSON")
 		fmt.Println("5. Exportar datos a CSV")
 		fmt.Println("6. Consultas avanzadas")
-		fmt.Println("7. Salir")
+		fmt.Println("7. Periodos académicos")
+		fmt.Println("8. Salir")
 		fmt.Print("Seleccione una opción: ")
 
 		scanner.Scan()
@@ -65,6 +128,8 @@ func (c *ConsoleUI) MostrarMenu() {
 		case "6":
 			c.mostrarMenuConsultasAvanzadas(scanner)
 		case "7":
+			c.mostrarMenuPeriodos(scanner)
+		case "8":
 			fmt.Println("Saliendo del programa...")
 			return
 		default:
@@ -80,7 +145,21 @@ func (c *ConsoleUI) mostrarMenuConsultasAvanzadas(scanner *bufio.Scanner) {
 		fmt.Println("2. Ver estadísticas generales")
 		fmt.Println("3. Insertar nuevo registro")
 		fmt.Println("4. Ver todos los registros")
-		fmt.Println("5. Volver al menú principal")
+		fmt.Println("5. Buscar estudiantes y materias por nombre")
+		fmt.Println("6. Ver historial de cambios")
+		fmt.Println("7. Eliminar o restaurar registros")
+		fmt.Println("8. Editar nombre o créditos")
+		fmt.Println("9. Prerrequisitos entre materias")
+		fmt.Println("10. Cupos y listas de espera")
+		fmt.Println("11. Grupos de las materias")
+		fmt.Println("12. Horarios de clase")
+		fmt.Println("13. Calificaciones")
+		fmt.Println("14. Carreras y pensums")
+		fmt.Println("15. Docentes")
+		fmt.Println("16. Volver al menú principal")
+		if c.facultades.ModoAdministrativo() {
+			fmt.Println("17. Resumen por facultad (modo administrativo)")
+		}
 		fmt.Print("Seleccione una opción: ")
 
 		scanner.Scan()
@@ -94,9 +173,33 @@ func (c *ConsoleUI) mostrarMenuConsultasAvanzadas(scanner *bufio.Scanner) {
 		case "3":
 			c.insertarNuevoRegistro(scanner)
 		case "4":
-			c.mostrarTodosLosRegistros()
+			c.mostrarTodosLosRegistros(scanner)
 		case "5":
+			c.buscarPorNombre(scanner)
+		case "6":
+			c.mostrarHistorial(scanner)
+		case "7":
+			c.eliminarORestaurar(scanner)
+		case "8":
+			c.editarNombre(scanner)
+		case "9":
+			c.administrarPrerrequisitos(scanner)
+		case "10":
+			c.administrarCupos(scanner)
+		case "11":
+			c.administrarGrupos(scanner)
+		case "12":
+			c.administrarHorarios(scanner)
+		case "13":
+			c.administrarCalificaciones(scanner)
+		case "14":
+			c.administrarCarreras(scanner)
+		case "15":
+			c.administrarDocentes(scanner)
+		case "16":
 			return // Volver al menú principal
+		case "17":
+			c.mostrarResumenPorFacultad()
 		default:
 			fmt.Println("Opción no válida. Intente nuevamente.")
 		}
@@ -113,16 +216,30 @@ func (c *ConsoleUI) cargarArchivo(scanner *bufio.Scanner) {
 		ruta = filepath.Join("testdata", ruta)
 	}
 
-	consolidado, err := c.procesador.ProcesarArchivo(ruta)
+	fmt.Printf("Periodo académico (Enter para %s): ", c.periodo)
+	scanner.Scan()
+	periodo := strings.TrimSpace(scanner.Text())
+	if periodo == "" {
+		periodo = c.periodo
+	}
+
+	consolidado, err := c.procesador.ProcesarArchivoEnPeriodo(ruta, periodo)
 	if err != nil {
 		fmt.Printf("\nError al procesar archivo: %v\n", err)
 		return
 	}
 
+	// Las consultas siguientes se refieren al periodo en el que se cargó el archivo
+	if periodo != c.periodo {
+		if err := c.cambiarPeriodo(periodo); err != nil {
+			fmt.Printf("Error al cambiar al periodo %s: %v\n", periodo, err)
+		}
+	}
+
 	c.consolidado = consolidado
 	c.archivoCargado = true
 
-	fmt.Printf("\nArchivo cargado exitosamente!\n")
+	fmt.Printf("\nArchivo cargado exitosamente en el periodo %s!\n", c.periodo)
 	fmt.Printf("Estudiantes registrados: %d\n", len(consolidado.Estudiantes))
 	fmt.Printf("Materias registradas: %d\n", len(consolidado.Materias))
 }
@@ -133,14 +250,29 @@ func (c *ConsoleUI) mostrarMateriasPorEstudiante() {
 		return
 	}
 
-	fmt.Println("\n=== MATERIAS POR ESTUDIANTE ===")
+	conteos, err := c.inscripcionSvc.ContarMateriasDeTodosLosEstudiantes()
+	if err != nil {
+		fmt.Printf("Error al contar materias: %v\n", err)
+		return
+	}
+	creditos, err := c.inscripcionSvc.CreditosDeTodosLosEstudiantes()
+	if err != nil {
+		fmt.Printf("Error al sumar créditos: %v\n", err)
+		return
+	}
+
+	limites := c.consultasAvanzadas.LimitesCreditos()
+	fmt.Printf("\n=== MATERIAS POR ESTUDIANTE (periodo %s) ===\n", c.periodo)
+	fmt.Printf("Carga permitida: entre %d y %d créditos\n", limites.Minimo, limites.Maximo)
 	for cedula, estudiante := range c.consolidado.Estudiantes {
-		count, err := c.inscripcionSvc.ContarMateriasPorEstudiante(cedula)
-		if err != nil {
-			fmt.Printf("Error al contar materias para %s: %v\n", estudiante.Nombre, err)
-			continue
+		aviso := ""
+		switch {
+		case limites.Excede(creditos[cedula]):
+			aviso = " [sobrecarga]"
+		case limites.PorDebajo(creditos[cedula]):
+			aviso = " [por debajo del mínimo]"
 		}
-		fmt.Printf("- %s (Cédula: %s): %d materias\n", estudiante.Nombre, cedula, count)
+		fmt.Printf("- %s (Cédula: %s): %d materias, %d créditos%s\n", estudiante.Nombre, cedula, conteos[cedula], creditos[cedula], aviso)
 	}
 }
 
@@ -166,19 +298,47 @@ func (c *ConsoleUI) filtrarPorMateria(scanner *bufio.Scanner) {
 		fmt.Printf("Error al obtener estudiantes: %v\n", err)
 		return
 	}
+	listas, err := c.grupos.ListasPorGrupo(codigo)
+	if err != nil {
+		fmt.Printf("Error al obtener los grupos: %v\n", err)
+		return
+	}
 
-	fmt.Printf("\n=== ESTUDIANTES INSCRITOS EN %s (%s) ===\n", materia.Nombre, materia.Codigo)
-	if len(estudiantes) == 0 {
+	fmt.Printf("\n=== ESTUDIANTES INSCRITOS EN %s (%s), PERIODO %s ===\n", materia.Nombre, materia.Codigo, c.periodo)
+	if len(estudiantes) == 0 && len(listas) == 0 {
 		fmt.Println("No hay estudiantes inscritos en esta materia")
 		return
 	}
 
-	for i, estudiante := range estudiantes {
-		fmt.Printf("%d. %s (Cédula: %s)\n", i+1, estudiante.Nombre, estudiante.Cedula)
+	// Una materia sin grupos se lista completa; con grupos, cada grupo tiene su lista
+	if len(listas) == 1 && listas[0].Numero == domain.SinGrupo {
+		listas = nil
+	}
+	if len(listas) == 0 {
+		for i, estudiante := range estudiantes {
+			fmt.Printf("%d. %s (Cédula: %s)\n", i+1, estudiante.Nombre, estudiante.Cedula)
+		}
+	}
+	for _, lista := range listas {
+		c.mostrarListaGrupo(lista)
 	}
 	fmt.Printf("\nTotal: %d estudiantes\n", len(estudiantes))
 }
 
+func (c *ConsoleUI) mostrarListaGrupo(lista service.ListaGrupo) {
+	if lista.Numero == domain.SinGrupo {
+		fmt.Printf("\n--- Sin grupo (%d estudiantes) ---\n", len(lista.Estudiantes))
+	} else {
+		fmt.Printf("\n--- Grupo %d (%d estudiantes) ---\n", lista.Numero, len(lista.Estudiantes))
+	}
+	if len(lista.Estudiantes) == 0 {
+		fmt.Println("No hay estudiantes inscritos en este grupo")
+	}
+	for i, estudiante := range lista.Estudiantes {
+		fmt.Printf("%d. %s (Cédula: %s)\n", i+1, estudiante.Nombre, estudiante.Cedula)
+	}
+}
+
 func (c *ConsoleUI) buscarEstudiantePorCedula(scanner *bufio.Scanner) {
 	fmt.Print("\nIngrese la cédula del estudiante: ")
 	scanner.Scan()
@@ -203,220 +363,1894 @@ func (c *ConsoleUI) buscarEstudiantePorCedula(scanner *bufio.Scanner) {
 	fmt.Printf("\n=== INFORMACIÓN DEL ESTUDIANTE ===\n")
 	fmt.Printf("Cédula: %s\n", estudiante.Cedula)
 	fmt.Printf("Nombre: %s\n", estudiante.Nombre)
-	fmt.Printf("Total de materias inscritas: %d\n\n", len(materias))
+	totalCreditos := 0
+	for _, materia := range materias {
+		totalCreditos += materia.Creditos
+	}
+	fmt.Printf("Total de materias inscritas: %d (%d créditos)\n\n", len(materias), totalCreditos)
 
 	if len(materias) > 0 {
 		fmt.Println("Materias inscritas:")
 		for i, materia := range materias {
-			fmt.Printf("%d. %s - %s\n", i+1, materia.Codigo, materia.Nombre)
+			fmt.Printf("%d. %s - %s (%d créditos)\n", i+1, materia.Codigo, materia.Nombre, materia.Creditos)
 		}
 	} else {
 		fmt.Println("El estudiante no tiene materias inscritas.")
 	}
+
+	historial, err := c.historialAcademico.HistorialDe(estudiante)
+	if err != nil {
+		fmt.Printf("Error al calcular el promedio: %v\n", err)
+		return
+	}
+	if historial.CreditosCalificados > 0 {
+		fmt.Printf("\nPromedio acumulado: %s (%d créditos calificados, %d aprobados)\n",
+			domain.FormatearNota(historial.PromedioAcumulado, domain.MaximoDecimales), historial.CreditosCalificados, historial.CreditosAprobados)
+	}
 }
 
-func (c *ConsoleUI) mostrarEstadisticasGenerales() {
-	estadisticas, err := c.consultasAvanzadas.ObtenerEstadisticasGenerales()
+func (c *ConsoleUI) buscarPorNombre(scanner *bufio.Scanner) {
+	fmt.Print("\nIngrese el nombre o parte del nombre a buscar: ")
+	scanner.Scan()
+	texto := strings.TrimSpace(scanner.Text())
+
+	if texto == "" {
+		fmt.Println("El texto de búsqueda no puede estar vacío.")
+		return
+	}
+
+	estudiantes, materias, err := c.consultasAvanzadas.BuscarPorNombre(texto, resultadosBusqueda)
 	if err != nil {
-		fmt.Printf("Error al obtener estadísticas: %v\n", err)
+		fmt.Printf("Error al buscar: %v\n", err)
 		return
 	}
 
-	fmt.Println("\n=== ESTADÍSTICAS GENERALES ===")
-	fmt.Printf("Total de estudiantes: %d\n", estadisticas.TotalEstudiantes)
-	fmt.Printf("Total de materias: %d\n", estadisticas.TotalMaterias)
-	fmt.Printf("Total de inscripciones: %d\n\n", estadisticas.TotalInscripciones)
+	if len(estudiantes) == 0 && len(materias) == 0 {
+		fmt.Printf("No se encontraron coincidencias para: %s\n", texto)
+		return
+	}
 
-	if len(estadisticas.EstudiantesConMasMaterias) > 0 {
-		fmt.Println("TOP 5 - Estudiantes con más materias:")
-		for i, item := range estadisticas.EstudiantesConMasMaterias {
-			fmt.Printf("%d. %s (%s): %d materias\n", 
-				i+1, item.Estudiante.Nombre, item.Estudiante.Cedula, item.CantidadMaterias)
+	if len(estudiantes) > 0 {
+		fmt.Println("\n=== ESTUDIANTES ENCONTRADOS ===")
+		for i, estudiante := range estudiantes {
+			fmt.Printf("%d. %s (Cédula: %s)\n", i+1, estudiante.Nombre, estudiante.Cedula)
 		}
-		fmt.Println()
 	}
 
-	if len(estadisticas.MateriasConMasEstudiantes) > 0 {
-		fmt.Println("TOP 5 - Materias con más estudiantes:")
-		for i, item := range estadisticas.MateriasConMasEstudiantes {
-			fmt.Printf("%d. %s (%s): %d estudiantes\n", 
-				i+1, item.Materia.Nombre, item.Materia.Codigo, item.CantidadEstudiantes)
+	if len(materias) > 0 {
+		fmt.Println("\n=== MATERIAS ENCONTRADAS ===")
+		for i, materia := range materias {
+			fmt.Printf("%d. %s - %s\n", i+1, materia.Codigo, materia.Nombre)
 		}
 	}
 }
 
-func (c *ConsoleUI) insertarNuevoRegistro(scanner *bufio.Scanner) {
-	fmt.Println("\n=== INSERTAR NUEVO REGISTRO ===")
-	
-	fmt.Print("Ingrese la cédula del estudiante: ")
-	scanner.Scan()
-	cedula := strings.TrimSpace(scanner.Text())
-	
-	fmt.Print("Ingrese el nombre del estudiante: ")
-	scanner.Scan()
-	nombreEstudiante := strings.TrimSpace(scanner.Text())
-	
-	fmt.Print("Ingrese el código de la materia: ")
-	scanner.Scan()
-	codigoMateria := strings.TrimSpace(scanner.Text())
-	
-	fmt.Print("Ingrese el nombre de la materia: ")
+func (c *ConsoleUI) mostrarHistorial(scanner *bufio.Scanner) {
+	fmt.Println("\n=== HISTORIAL DE CAMBIOS ===")
+	fmt.Println("1. De un estudiante")
+	fmt.Println("2. De una materia")
+	fmt.Print("Seleccione una opción: ")
 	scanner.Scan()
-	nombreMateria := strings.TrimSpace(scanner.Text())
+	tipo := strings.TrimSpace(scanner.Text())
 
-	// Validaciones básicas
-	if cedula == "" || nombreEstudiante == "" || codigoMateria == "" || nombreMateria == "" {
-		fmt.Println("Todos los campos son obligatorios.")
+	var registros []*domain.RegistroAuditoria
+	var err error
+	switch tipo {
+	case "1":
+		fmt.Print("Ingrese la cédula del estudiante: ")
+		scanner.Scan()
+		registros, err = c.historial.HistorialEstudiante(strings.TrimSpace(scanner.Text()))
+	case "2":
+		fmt.Print("Ingrese el código de la materia: ")
+		scanner.Scan()
+		registros, err = c.historial.HistorialMateria(strings.TrimSpace(scanner.Text()))
+	default:
+		fmt.Println("Opción no válida.")
 		return
 	}
 
-	err := c.consultasAvanzadas.InsertarNuevoRegistro(cedula, nombreEstudiante, codigoMateria, nombreMateria)
 	if err != nil {
-		fmt.Printf("Error al insertar registro: %v\n", err)
+		fmt.Printf("Error al consultar historial: %v\n", err)
 		return
 	}
 
-	fmt.Println("Registro insertado exitosamente!")
+	if len(registros) == 0 {
+		fmt.Println("No hay cambios registrados.")
+		return
+	}
+
+	fmt.Printf("\n%-20s %-12s %-10s %-12s %-11s %-18s\n", "FECHA", "ACTOR", "ORIGEN", "ENTIDAD", "OPERACIÓN", "CLAVE")
+	fmt.Println(strings.Repeat("-", 88))
+	for _, registro := range registros {
+		fmt.Printf("%-20s %-12s %-10s %-12s %-11s %-18s\n",
+			registro.Fecha.Local().Format("2006-01-02 15:04:05"),
+			c.truncateString(registro.Actor, 12),
+			registro.Origen,
+			registro.Entidad,
+			registro.Operacion,
+			registro.Clave)
+		if registro.Antes != "" {
+			fmt.Printf("    antes:   %s\n", registro.Antes)
+		}
+		if registro.Despues != "" {
+			fmt.Printf("    después: %s\n", registro.Despues)
+		}
+	}
 }
 
-func (c *ConsoleUI) mostrarTodosLosRegistros() {
-	registros, err := c.consultasAvanzadas.ObtenerTodosLosRegistros()
+func (c *ConsoleUI) mostrarResumenPorFacultad() {
+	resumenes, err := c.facultades.ResumenPorFacultad()
+	if errors.Is(err, repository.ErrNoAutorizado) {
+		fmt.Println("Opción no válida. Intente nuevamente.")
+		return
+	}
 	if err != nil {
-		fmt.Printf("Error al obtener registros: %v\n", err)
+		fmt.Printf("Error al obtener el resumen: %v\n", err)
 		return
 	}
 
-	if len(registros) == 0 {
-		fmt.Println("\nNo hay registros en la base de datos.")
+	fmt.Println("\n=== RESUMEN POR FACULTAD ===")
+	if len(resumenes) == 0 {
+		fmt.Println("No hay facultades con datos.")
 		return
 	}
+	fmt.Printf("%-20s %12s %10s %14s\n", "FACULTAD", "ESTUDIANTES", "MATERIAS", "INSCRIPCIONES")
+	fmt.Println(strings.Repeat("-", 59))
+	total := service.ResumenFacultad{}
+	for _, r := range resumenes {
+		marca := ""
+		if r.Facultad == c.facultades.FacultadActiva() {
+			marca = " *"
+		}
+		fmt.Printf("%-20s %12d %10d %14d\n", c.truncateString(r.Facultad, 18)+marca, r.TotalEstudiantes, r.TotalMaterias, r.TotalInscripciones)
+		total.TotalEstudiantes += r.TotalEstudiantes
+		total.TotalMaterias += r.TotalMaterias
+		total.TotalInscripciones += r.TotalInscripciones
+	}
+	fmt.Println(strings.Repeat("-", 59))
+	fmt.Printf("%-20s %12d %10d %14d\n", "TOTAL", total.TotalEstudiantes, total.TotalMaterias, total.TotalInscripciones)
+	fmt.Println("* facultad activa")
+}
 
-	fmt.Printf("\n=== TODOS LOS REGISTROS (%d) ===\n", len(registros))
-	fmt.Printf("%-12s %-25s %-10s %-25s\n", "CÉDULA", "NOMBRE ESTUDIANTE", "CÓD MAT", "NOMBRE MATERIA")
-	fmt.Println(strings.Repeat("-", 75))
+func (c *ConsoleUI) mostrarMenuPeriodos(scanner *bufio.Scanner) {
+	for {
+		fmt.Println("\n=== PERIODOS ACADÉMICOS ===")
+		fmt.Printf("Periodo de trabajo: %s\n", c.periodo)
+		fmt.Println("1. Ver resumen por periodo")
+		fmt.Println("2. Crear periodo")
+		fmt.Println("3. Establecer periodo actual")
+		fmt.Println("4. Cambiar periodo de trabajo")
+		fmt.Println("5. Volver al menú principal")
+		fmt.Print("Seleccione una opción: ")
 
-	for _, registro := range registros {
-		fmt.Printf("%-12s %-25s %-10s %-25s\n",
-			registro.Estudiante.Cedula,
-			c.truncateString(registro.Estudiante.Nombre, 25),
-			registro.Materia.Codigo,
-			c.truncateString(registro.Materia.Nombre, 25))
+		scanner.Scan()
+		opcion := scanner.Text()
+
+		switch opcion {
+		case "1":
+			c.mostrarResumenPorPeriodo()
+		case "2":
+			codigo := c.leerPeriodo(scanner)
+			if err := c.periodos.CrearPeriodo(codigo); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			fmt.Printf("Periodo %s creado.\n", codigo)
+		case "3":
+			codigo := c.leerPeriodo(scanner)
+			if err := c.periodos.EstablecerPeriodoActual(codigo); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			fmt.Printf("El periodo actual es ahora %s; las próximas sesiones empezarán en él.\n", codigo)
+		case "4":
+			codigo := c.leerPeriodo(scanner)
+			if err := c.cambiarPeriodo(codigo); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			fmt.Printf("Las consultas, estadísticas y exportaciones se refieren ahora al periodo %s.\n", c.periodo)
+		case "5":
+			return
+		default:
+			fmt.Println("Opción no válida. Intente nuevamente.")
+		}
 	}
 }
 
-func (c *ConsoleUI) exportarJSON() {
-	if !c.archivoCargado {
-		fmt.Println("\nPrimero debe cargar un archivo de inscripciones (Opción 1)")
+func (c *ConsoleUI) leerPeriodo(scanner *bufio.Scanner) string {
+	fmt.Print("Ingrese el código del periodo (por ejemplo, 2026-1): ")
+	scanner.Scan()
+	return strings.TrimSpace(scanner.Text())
+}
+
+// cambiarPeriodo reemplaza los servicios que dependen del periodo por los del periodo indicado
+func (c *ConsoleUI) cambiarPeriodo(codigo string) error {
+	servicios, err := c.periodos.Servicios(codigo)
+	if err != nil {
+		return err
+	}
+	c.inscripcionSvc = servicios.Inscripciones
+	c.consultasAvanzadas = servicios.ConsultasAvanzadas
+	c.eliminacion = servicios.Eliminacion
+	c.prerrequisitos = servicios.Prerrequisitos
+	c.cupos = servicios.Cupos
+	c.grupos = servicios.Grupos
+	c.horarios = servicios.Horarios
+	c.calificaciones = servicios.Calificaciones
+	c.docentes = servicios.Docentes
+	c.periodo = servicios.Periodo
+	return nil
+}
+
+func (c *ConsoleUI) mostrarResumenPorPeriodo() {
+	resumenes, err := c.periodos.ResumenPorPeriodo()
+	if err != nil {
+		fmt.Printf("Error al obtener el resumen: %v\n", err)
 		return
 	}
 
-	type EstudianteExport struct {
-		Cedula string `json:"cedula"`
-		Nombre string `json:"nombre"`
+	fmt.Println("\n=== RESUMEN POR PERIODO ===")
+	fmt.Printf("%-12s %14s %12s %10s\n", "PERIODO", "INSCRIPCIONES", "ESTUDIANTES", "MATERIAS")
+	fmt.Println(strings.Repeat("-", 51))
+	for _, r := range resumenes {
+		marca := ""
+		if r.Actual {
+			marca = " *"
+		}
+		fmt.Printf("%-12s %14d %12d %10d\n", r.Codigo+marca, r.TotalInscripciones, r.TotalEstudiantes, r.TotalMaterias)
 	}
+	fmt.Println("* periodo actual")
+}
 
-	type MateriaExport struct {
-		Codigo string `json:"codigo"`
-		Nombre string `json:"nombre"`
+func (c *ConsoleUI) eliminarORestaurar(scanner *bufio.Scanner) {
+	fmt.Println("\n=== ELIMINAR O RESTAURAR REGISTROS ===")
+	fmt.Println("1. Eliminar estudiante")
+	fmt.Println("2. Restaurar estudiante")
+	fmt.Println("3. Eliminar materia")
+	fmt.Println("4. Restaurar materia")
+	fmt.Println("5. Eliminar inscripción")
+	fmt.Println("6. Restaurar inscripción")
+	fmt.Println("7. Ver registros eliminados")
+	fmt.Print("Seleccione una opción: ")
+	scanner.Scan()
+	opcion := strings.TrimSpace(scanner.Text())
+
+	leer := func(mensaje string) string {
+		fmt.Print(mensaje)
+		scanner.Scan()
+		return strings.TrimSpace(scanner.Text())
 	}
 
-	type InscripcionExport struct {
-		Estudiante EstudianteExport `json:"estudiante"`
-		Materia    MateriaExport    `json:"materia"`
+	var err error
+	var promovidos []string
+	switch opcion {
+	case "1":
+		cedula := leer("Ingrese la cédula del estudiante: ")
+		fmt.Println("Sus inscripciones dejarán de aparecer hasta que sea restaurado.")
+		if !c.confirmar(scanner) {
+			return
+		}
+		err = c.eliminacion.EliminarEstudiante(cedula)
+	case "2":
+		err = c.eliminacion.RestaurarEstudiante(leer("Ingrese la cédula del estudiante: "))
+	case "3":
+		codigo := leer("Ingrese el código de la materia: ")
+		fmt.Println("Sus inscripciones dejarán de aparecer hasta que sea restaurada.")
+		if !c.confirmar(scanner) {
+			return
+		}
+		err = c.eliminacion.EliminarMateria(codigo)
+	case "4":
+		err = c.eliminacion.RestaurarMateria(leer("Ingrese el código de la materia: "))
+	case "5":
+		cedula := leer("Ingrese la cédula del estudiante: ")
+		codigo := leer("Ingrese el código de la materia: ")
+		if !c.confirmar(scanner) {
+			return
+		}
+		promovidos, err = c.eliminacion.EliminarInscripcion(cedula, codigo)
+	case "6":
+		cedula := leer("Ingrese la cédula del estudiante: ")
+		codigo := leer("Ingrese el código de la materia: ")
+		err = c.eliminacion.RestaurarInscripcion(cedula, codigo)
+	case "7":
+		c.mostrarEliminados()
+		return
+	default:
+		fmt.Println("Opción no válida.")
+		return
 	}
 
-	var inscripciones []InscripcionExport
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("Operación realizada exitosamente!")
+	for _, cedula := range promovidos {
+		fmt.Printf("El estudiante %s pasó de la lista de espera a estar inscrito.\n", cedula)
+	}
+}
 
-	for cedula, estudiante := range c.consolidado.Estudiantes {
-		materias, err := c.inscripcionSvc.ObtenerMateriasPorEstudiante(cedula)
-		if err != nil {
-			fmt.Printf("Error al obtener materias para %s: %v\n", estudiante.Nombre, err)
-			continue
-		}
+// editarNombre guarda el nombre nuevo solo si nadie modificó el registro desde que se mostró
+func (c *ConsoleUI) editarNombre(scanner *bufio.Scanner) {
+	fmt.Println("\n=== EDITAR ===")
+	fmt.Println("1. Nombre de un estudiante")
+	fmt.Println("2. Nombre de una materia")
+	fmt.Println("3. Créditos de una materia")
+	fmt.Print("Seleccione una opción: ")
+	scanner.Scan()
+	opcion := strings.TrimSpace(scanner.Text())
+
+	leer := func(mensaje string) string {
+		fmt.Print(mensaje)
+		scanner.Scan()
+		return strings.TrimSpace(scanner.Text())
+	}
 
-		for _, materia := range materias {
-			inscripciones = append(inscripciones, InscripcionExport{
-				Estudiante: EstudianteExport{
-					Cedula: estudiante.Cedula,
-					Nombre: estudiante.Nombre,
-				},
-				Materia: MateriaExport{
-					Codigo: materia.Codigo,
-					Nombre: materia.Nombre,
-				},
-			})
+	var err error
+	switch opcion {
+	case "1":
+		estudiante, errLectura := c.edicion.ObtenerEstudiante(leer("Ingrese la cédula del estudiante: "))
+		if errLectura != nil {
+			fmt.Printf("Error: %v\n", errLectura)
+			return
+		}
+		fmt.Printf("Nombre actual: %s (versión %d)\n", estudiante.Nombre, estudiante.Version)
+		err = c.edicion.RenombrarEstudiante(estudiante, leer("Ingrese el nuevo nombre: "))
+	case "2":
+		materia, errLectura := c.edicion.ObtenerMateria(leer("Ingrese el código de la materia: "))
+		if errLectura != nil {
+			fmt.Printf("Error: %v\n", errLectura)
+			return
+		}
+		fmt.Printf("Nombre actual: %s (versión %d)\n", materia.Nombre, materia.Version)
+		err = c.edicion.RenombrarMateria(materia, leer("Ingrese el nuevo nombre: "))
+	case "3":
+		materia, errLectura := c.edicion.ObtenerMateria(leer("Ingrese el código de la materia: "))
+		if errLectura != nil {
+			fmt.Printf("Error: %v\n", errLectura)
+			return
+		}
+		fmt.Printf("Créditos actuales: %d (versión %d)\n", materia.Creditos, materia.Version)
+		creditos, errNumero := strconv.Atoi(leer("Ingrese los nuevos créditos: "))
+		if errNumero != nil {
+			fmt.Println("Los créditos deben ser un número entero.")
+			return
 		}
+		err = c.edicion.CambiarCreditosMateria(materia, creditos)
+	default:
+		fmt.Println("Opción no válida.")
+		return
 	}
 
-	jsonData, err := json.MarshalIndent(inscripciones, "", "  ")
+	if errors.Is(err, repository.ErrConflicto) {
+		fmt.Println("Otro usuario modificó el registro mientras usted lo editaba; sus cambios no se guardaron.")
+		fmt.Println("Vuelva a abrirlo para ver los datos actuales e intente de nuevo.")
+		return
+	}
 	if err != nil {
-		fmt.Printf("Error al generar JSON: %v\n", err)
+		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	fmt.Println("Registro actualizado exitosamente!")
+}
 
-	filename := "inscripciones.json"
-	err = os.WriteFile(filename, jsonData, 0644)
-	if err != nil {
-		fmt.Printf("Error al escribir archivo JSON: %v\n", err)
+func (c *ConsoleUI) administrarPrerrequisitos(scanner *bufio.Scanner) {
+	fmt.Println("\n=== PRERREQUISITOS ENTRE MATERIAS ===")
+	fmt.Println("1. Agregar prerrequisito")
+	fmt.Println("2. Quitar prerrequisito")
+	fmt.Println("3. Ver cadena de prerrequisitos de una materia")
+	fmt.Println("4. Ver inscritos sin los prerrequisitos")
+	fmt.Print("Seleccione una opción: ")
+	scanner.Scan()
+	opcion := strings.TrimSpace(scanner.Text())
+
+	leer := func(mensaje string) string {
+		fmt.Print(mensaje)
+		scanner.Scan()
+		return strings.TrimSpace(scanner.Text())
+	}
+
+	var err error
+	switch opcion {
+	case "1":
+		materia := leer("Ingrese el código de la materia: ")
+		requisito := leer("Ingrese el código de la materia que debe cursarse antes: ")
+		err = c.prerrequisitos.AgregarPrerrequisito(materia, requisito)
+	case "2":
+		materia := leer("Ingrese el código de la materia: ")
+		requisito := leer("Ingrese el código del prerrequisito a quitar: ")
+		err = c.prerrequisitos.QuitarPrerrequisito(materia, requisito)
+	case "3":
+		c.mostrarCadenaDePrerrequisitos(leer("Ingrese el código de la materia: "))
+		return
+	case "4":
+		c.mostrarInscritosSinPrerrequisitos(leer("Ingrese el código de la materia: "))
+		return
+	default:
+		fmt.Println("Opción no válida.")
 		return
 	}
 
-	fmt.Printf("\nDatos exportados exitosamente a %s\n", filename)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("Operación realizada exitosamente!")
 }
 
-func (c *ConsoleUI) exportarCSV() {
-	if !c.archivoCargado {
-		fmt.Println("\nPrimero debe cargar un archivo de inscripciones (Opción 1)")
+// mostrarCadenaDePrerrequisitos muestra los prerrequisitos como árbol, sangrando cada nivel
+func (c *ConsoleUI) mostrarCadenaDePrerrequisitos(codigo string) {
+	materia, cadena, err := c.prerrequisitos.CadenaDePrerrequisitos(codigo)
+	if err != nil {
+		fmt.Printf("Error al obtener prerrequisitos: %v\n", err)
+		return
+	}
+	if materia == nil {
+		fmt.Println("Materia no encontrada. Intente con un código válido.")
 		return
 	}
 
-	filename := "inscripciones.csv"
-	file, err := os.Create(filename)
-	if err != nil {
-		fmt.Printf("Error al crear archivo CSV: %v\n", err)
+	fmt.Printf("\n=== CADENA DE PRERREQUISITOS DE %s (%s) ===\n", materia.Nombre, materia.Codigo)
+	if len(cadena) == 0 {
+		fmt.Println("La materia no tiene prerrequisitos")
 		return
 	}
-	defer file.Close()
+	fmt.Printf("%s - %s\n", materia.Codigo, materia.Nombre)
+	for _, eslabon := range cadena {
+		fmt.Printf("%s└─ %s - %s\n", strings.Repeat("   ", eslabon.Nivel-1), eslabon.Materia.Codigo, eslabon.Materia.Nombre)
+	}
+}
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+func (c *ConsoleUI) mostrarInscritosSinPrerrequisitos(codigo string) {
+	estudiantes, err := c.prerrequisitos.EstudiantesSinPrerrequisitos(codigo)
+	if err != nil {
+		fmt.Printf("Error al revisar prerrequisitos: %v\n", err)
+		return
+	}
 
-	// Escribir encabezados
-	headers := []string{"CEDULA", "NOMBRE_ESTUDIANTE", "CODIGO_MATERIA", "NOMBRE_MATERIA"}
-	if err := writer.Write(headers); err != nil {
-		fmt.Printf("Error al escribir encabezados CSV: %v\n", err)
+	fmt.Printf("\n=== INSCRITOS EN %s SIN LOS PRERREQUISITOS, PERIODO %s ===\n", codigo, c.periodo)
+	if len(estudiantes) == 0 {
+		fmt.Println("Todos los inscritos han cursado los prerrequisitos")
 		return
 	}
+	for i, e := range estudiantes {
+		fmt.Printf("%d. %s (Cédula: %s) - le falta: %s\n", i+1, e.Estudiante.Nombre, e.Estudiante.Cedula, strings.Join(e.Faltantes, ", "))
+	}
+	fmt.Printf("\nTotal: %d estudiantes\n", len(estudiantes))
+}
 
-	// Escribir datos
-	for cedula, estudiante := range c.consolidado.Estudiantes {
-		materias, err := c.inscripcionSvc.ObtenerMateriasPorEstudiante(cedula)
-		if err != nil {
-			fmt.Printf("Error al obtener materias para %s: %v\n", estudiante.Nombre, err)
-			continue
-		}
+func (c *ConsoleUI) administrarCupos(scanner *bufio.Scanner) {
+	fmt.Println("\n=== CUPOS Y LISTAS DE ESPERA ===")
+	fmt.Println("1. Establecer el cupo de una materia")
+	fmt.Println("2. Ver ocupación de las materias")
+	fmt.Println("3. Ver lista de espera de una materia")
+	fmt.Println("4. Retirar a un estudiante de una lista de espera")
+	fmt.Print("Seleccione una opción: ")
+	scanner.Scan()
+	opcion := strings.TrimSpace(scanner.Text())
 
-		for _, materia := range materias {
-			record := []string{
-				estudiante.Cedula,
-				estudiante.Nombre,
-				materia.Codigo,
-				materia.Nombre,
-			}
-			if err := writer.Write(record); err != nil {
-				fmt.Printf("Error al escribir registro CSV: %v\n", err)
-				continue
-			}
+	leer := func(mensaje string) string {
+		fmt.Print(mensaje)
+		scanner.Scan()
+		return strings.TrimSpace(scanner.Text())
+	}
+
+	var err error
+	var promovidos []string
+	switch opcion {
+	case "1":
+		codigo := leer("Ingrese el código de la materia: ")
+		cupo, errCupo := strconv.Atoi(leer(fmt.Sprintf("Ingrese el cupo (%d para no limitarlo): ", domain.SinCupoLimite)))
+		if errCupo != nil {
+			fmt.Println("El cupo debe ser un número entero.")
+			return
 		}
+		promovidos, err = c.cupos.EstablecerCupo(codigo, cupo)
+	case "2":
+		c.mostrarOcupacion()
+		return
+	case "3":
+		c.mostrarListaDeEspera(leer("Ingrese el código de la materia: "))
+		return
+	case "4":
+		cedula := leer("Ingrese la cédula del estudiante: ")
+		codigo := leer("Ingrese el código de la materia: ")
+		err = c.cupos.SalirDeEspera(cedula, codigo)
+	default:
+		fmt.Println("Opción no válida.")
+		return
 	}
 
-	fmt.Printf("\nDatos exportados exitosamente a %s\n", filename)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("Operación realizada exitosamente!")
+	for _, cedula := range promovidos {
+		fmt.Printf("El estudiante %s pasó de la lista de espera a estar inscrito.\n", cedula)
+	}
 }
 
-// Función auxiliar para truncar strings
-func (c *ConsoleUI) truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+func (c *ConsoleUI) mostrarOcupacion() {
+	ocupacion, err := c.cupos.Ocupacion()
+	if err != nil {
+		fmt.Printf("Error al obtener la ocupación: %v\n", err)
+		return
+	}
+
+	fmt.Printf("\n=== OCUPACIÓN DE LAS MATERIAS, PERIODO %s ===\n", c.periodo)
+	if len(ocupacion) == 0 {
+		fmt.Println("No hay materias registradas")
+		return
+	}
+	fmt.Printf("%-10s %-30s %6s %10s %12s %10s\n", "Código", "Materia", "Cupo", "Inscritos", "Disponibles", "En espera")
+	fmt.Println(strings.Repeat("-", 83))
+	for _, o := range ocupacion {
+		cupo, disponibles := "-", "-"
+		if o.Cupo != domain.SinCupoLimite {
+			cupo, disponibles = strconv.Itoa(o.Cupo), strconv.Itoa(o.Disponibles())
+		}
+		fmt.Printf("%-10s %-30s %6s %10d %12s %10d\n", o.Codigo, c.truncateString(o.Nombre, 30), cupo, o.Inscritos, disponibles, o.EnEspera)
+	}
+}
+
+func (c *ConsoleUI) mostrarListaDeEspera(codigo string) {
+	lista, err := c.cupos.ListaDeEspera(codigo)
+	if err != nil {
+		fmt.Printf("Error al obtener la lista de espera: %v\n", err)
+		return
+	}
+
+	fmt.Printf("\n=== LISTA DE ESPERA DE %s, PERIODO %s ===\n", codigo, c.periodo)
+	if len(lista) == 0 {
+		fmt.Println("Nadie espera cupo en esta materia")
+		return
+	}
+	for _, espera := range lista {
+		grupo := ""
+		if espera.Grupo != domain.SinGrupo {
+			grupo = fmt.Sprintf(", grupo %d", espera.Grupo)
+		}
+		fmt.Printf("%d. %s (Cédula: %s%s)\n", espera.Posicion, espera.Estudiante.Nombre, espera.Estudiante.Cedula, grupo)
+	}
+	fmt.Printf("\nTotal: %d estudiantes\n", len(lista))
+}
+
+func (c *ConsoleUI) administrarGrupos(scanner *bufio.Scanner) {
+	fmt.Println("\n=== GRUPOS DE LAS MATERIAS ===")
+	fmt.Println("1. Crear un grupo")
+	fmt.Println("2. Eliminar un grupo")
+	fmt.Println("3. Ver las listas por grupo de una materia")
+	fmt.Println("4. Cambiar el grupo de un inscrito")
+	fmt.Print("Seleccione una opción: ")
+	scanner.Scan()
+	opcion := strings.TrimSpace(scanner.Text())
+
+	leer := func(mensaje string) string {
+		fmt.Print(mensaje)
+		scanner.Scan()
+		return strings.TrimSpace(scanner.Text())
+	}
+	leerNumero := func(mensaje string) (int, bool) {
+		numero, err := strconv.Atoi(leer(mensaje))
+		if err != nil {
+			fmt.Println("El grupo debe ser un número entero.")
+			return 0, false
+		}
+		return numero, true
+	}
+
+	var err error
+	switch opcion {
+	case "1", "2":
+		codigo := leer("Ingrese el código de la materia: ")
+		numero, ok := leerNumero("Ingrese el número del grupo: ")
+		if !ok {
+			return
+		}
+		if opcion == "1" {
+			err = c.grupos.CrearGrupo(codigo, numero)
+		} else {
+			err = c.grupos.EliminarGrupo(codigo, numero)
+		}
+	case "3":
+		c.mostrarListasPorGrupo(leer("Ingrese el código de la materia: "))
+		return
+	case "4":
+		cedula := leer("Ingrese la cédula del estudiante: ")
+		codigo := leer("Ingrese el código de la materia: ")
+		numero, ok := leerNumero(fmt.Sprintf("Ingrese el nuevo grupo (%d para ninguno): ", domain.SinGrupo))
+		if !ok {
+			return
+		}
+		err = c.grupos.AsignarGrupo(cedula, codigo, numero)
+	default:
+		fmt.Println("Opción no válida.")
+		return
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("Operación realizada exitosamente!")
+}
+
+func (c *ConsoleUI) mostrarListasPorGrupo(codigo string) {
+	listas, err := c.grupos.ListasPorGrupo(codigo)
+	if err != nil {
+		fmt.Printf("Error al obtener los grupos: %v\n", err)
+		return
+	}
+
+	fmt.Printf("\n=== GRUPOS DE %s, PERIODO %s ===\n", codigo, c.periodo)
+	if len(listas) == 0 {
+		fmt.Println("La materia no tiene grupos ni inscritos")
+		return
+	}
+	for _, lista := range listas {
+		c.mostrarListaGrupo(lista)
+	}
+}
+
+func (c *ConsoleUI) administrarHorarios(scanner *bufio.Scanner) {
+	fmt.Println("\n=== HORARIOS DE CLASE ===")
+	fmt.Println("1. Agregar una sesión a una materia")
+	fmt.Println("2. Quitar una sesión de una materia")
+	fmt.Println("3. Ver el horario de una materia")
+	fmt.Println("4. Ver el horario semanal de un estudiante")
+	fmt.Print("Seleccione una opción: ")
+	scanner.Scan()
+	opcion := strings.TrimSpace(scanner.Text())
+
+	leer := func(mensaje string) string {
+		fmt.Print(mensaje)
+		scanner.Scan()
+		return strings.TrimSpace(scanner.Text())
+	}
+
+	var err error
+	switch opcion {
+	case "1":
+		codigo := leer("Ingrese el código de la materia: ")
+		grupo := domain.SinGrupo
+		if texto := leer("Ingrese el grupo (Enter para todos los inscritos): "); texto != "" {
+			if grupo, err = strconv.Atoi(texto); err != nil {
+				fmt.Println("El grupo debe ser un número entero.")
+				return
+			}
+		}
+		dia := leer("Ingrese el día (lunes a domingo): ")
+		inicio := leer("Ingrese la hora de inicio (HH:MM): ")
+		fin := leer("Ingrese la hora de fin (HH:MM): ")
+		salon := leer("Ingrese el salón: ")
+		err = c.horarios.AgregarSesion(codigo, grupo, dia, inicio, fin, salon)
+	case "2":
+		codigo := leer("Ingrese el código de la materia: ")
+		sesiones := c.mostrarSesionesDeMateria(codigo)
+		if len(sesiones) == 0 {
+			return
+		}
+		numero, errNumero := strconv.Atoi(leer("Ingrese el número de la sesión a quitar: "))
+		if errNumero != nil || numero < 1 || numero > len(sesiones) {
+			fmt.Println("Número de sesión no válido.")
+			return
+		}
+		err = c.horarios.QuitarSesion(sesiones[numero-1])
+	case "3":
+		c.mostrarSesionesDeMateria(leer("Ingrese el código de la materia: "))
+		return
+	case "4":
+		c.mostrarHorarioEstudiante(leer("Ingrese la cédula del estudiante: "))
+		return
+	default:
+		fmt.Println("Opción no válida.")
+		return
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("Operación realizada exitosamente!")
+}
+
+// mostrarSesionesDeMateria lista, numeradas, las sesiones de la materia y las retorna
+func (c *ConsoleUI) mostrarSesionesDeMateria(codigo string) []domain.Sesion {
+	sesiones, err := c.horarios.SesionesDeMateria(codigo)
+	if err != nil {
+		fmt.Printf("Error al obtener el horario: %v\n", err)
+		return nil
+	}
+
+	fmt.Printf("\n=== HORARIO DE %s ===\n", codigo)
+	if len(sesiones) == 0 {
+		fmt.Println("La materia no tiene sesiones")
+		return nil
+	}
+	for i, s := range sesiones {
+		fmt.Printf("%d. %s, salón %s, %s\n", i+1, s.Franja(), s.Salon, descripcionGrupo(s.Grupo))
+	}
+	return sesiones
+}
+
+func descripcionGrupo(grupo int) string {
+	if grupo == domain.SinGrupo {
+		return "todos los inscritos"
+	}
+	return fmt.Sprintf("grupo %d", grupo)
+}
+
+// anchoCeldaHorario es el ancho de cada columna de la cuadrícula del horario semanal
+const anchoCeldaHorario = 11
+
+// mostrarHorarioEstudiante imprime el horario semanal del estudiante en el periodo como una
+// cuadrícula de una fila por hora y una columna por día, seguida del detalle de las sesiones
+// y de los cruces. Una celda con dos materias es un cruce.
+func (c *ConsoleUI) mostrarHorarioEstudiante(cedula string) {
+	horario, err := c.horarios.HorarioDeEstudiante(cedula)
+	if err != nil {
+		fmt.Printf("Error al obtener el horario: %v\n", err)
+		return
+	}
+	if horario == nil {
+		fmt.Printf("No se encontró ningún estudiante con la cédula: %s\n", cedula)
+		return
+	}
+
+	fmt.Printf("\n=== HORARIO DE %s, PERIODO %s ===\n", horario.Estudiante.Nombre, c.periodo)
+	if len(horario.Sesiones) == 0 {
+		fmt.Println("El estudiante no tiene sesiones de clase en el periodo")
+		return
+	}
+
+	// El domingo solo se muestra si hay clases ese día
+	ultimoDia, primeraHora, ultimaHora := domain.Sabado, 24, 0
+	for _, s := range horario.Sesiones {
+		if s.Dia > ultimoDia {
+			ultimoDia = s.Dia
+		}
+		primeraHora = min(primeraHora, s.Inicio/60)
+		ultimaHora = max(ultimaHora, (s.Fin+59)/60)
+	}
+
+	fmt.Printf("%-*s", anchoCeldaHorario, "Hora")
+	for dia := domain.Lunes; dia <= ultimoDia; dia++ {
+		fmt.Printf("|%-*s", anchoCeldaHorario, dia.String())
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("-", (anchoCeldaHorario+1)*int(ultimoDia+1)-1))
+	for hora := primeraHora; hora < ultimaHora; hora++ {
+		franja := domain.Sesion{Inicio: hora * 60, Fin: (hora + 1) * 60}
+		fmt.Printf("%-*s", anchoCeldaHorario, domain.FormatearHora(franja.Inicio)+"-"+domain.FormatearHora(franja.Fin))
+		for dia := domain.Lunes; dia <= ultimoDia; dia++ {
+			franja.Dia = dia
+			var materias []string
+			for _, s := range horario.Sesiones {
+				if s.SeCruzaCon(franja) {
+					materias = append(materias, s.Materia)
+				}
+			}
+			celda := strings.Join(materias, "/")
+			if utf8.RuneCountInString(celda) > anchoCeldaHorario {
+				celda = string([]rune(celda)[:anchoCeldaHorario-1]) + "…"
+			}
+			fmt.Printf("|%-*s", anchoCeldaHorario, celda)
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("\nSesiones:")
+	for _, s := range horario.Sesiones {
+		fmt.Printf("- %s: %s %s, salón %s", s.Franja(), s.Materia, s.NombreMateria, s.Salon)
+		if s.Grupo != domain.SinGrupo {
+			fmt.Printf(", grupo %d", s.Grupo)
+		}
+		fmt.Println()
+	}
+	if len(horario.Cruces) > 0 {
+		fmt.Println("\nCruces de horario:")
+		for _, cruce := range horario.Cruces {
+			fmt.Printf("- %s (%s) se cruza con %s (%s)\n", cruce.A.Materia, cruce.A.Franja(), cruce.B.Materia, cruce.B.Franja())
+		}
+	}
+}
+
+func (c *ConsoleUI) administrarCalificaciones(scanner *bufio.Scanner) {
+	fmt.Println("\n=== CALIFICACIONES ===")
+	fmt.Println("1. Agregar un componente de evaluación a una materia")
+	fmt.Println("2. Cambiar el peso de un componente")
+	fmt.Println("3. Quitar un componente")
+	fmt.Println("4. Registrar la nota de un estudiante")
+	fmt.Println("5. Quitar la nota de un estudiante")
+	fmt.Println("6. Ver la planilla de notas de una materia")
+	fmt.Println("7. Ver la nota definitiva de un estudiante")
+	fmt.Println("8. Cargar archivo de notas")
+	fmt.Println("9. Ver el historial académico de un estudiante")
+	fmt.Print("Seleccione una opción: ")
+	scanner.Scan()
+	opcion := strings.TrimSpace(scanner.Text())
+
+	leer := func(mensaje string) string {
+		fmt.Print(mensaje)
+		scanner.Scan()
+		return strings.TrimSpace(scanner.Text())
+	}
+	leerPeso := func() (int, bool) {
+		peso, err := strconv.Atoi(leer("Ingrese el peso en porcentaje (1 a 100): "))
+		if err != nil {
+			fmt.Println("El peso debe ser un número entero.")
+			return 0, false
+		}
+		return peso, true
+	}
+
+	var err error
+	switch opcion {
+	case "1", "2":
+		codigo := leer("Ingrese el código de la materia: ")
+		nombre := leer("Ingrese el nombre del componente (por ejemplo, Parciales): ")
+		peso, ok := leerPeso()
+		if !ok {
+			return
+		}
+		if opcion == "1" {
+			err = c.calificaciones.AgregarComponente(codigo, nombre, peso)
+		} else {
+			err = c.calificaciones.CambiarPeso(codigo, nombre, peso)
+		}
+	case "3":
+		codigo := leer("Ingrese el código de la materia: ")
+		err = c.calificaciones.QuitarComponente(codigo, leer("Ingrese el nombre del componente: "))
+	case "4":
+		cedula := leer("Ingrese la cédula del estudiante: ")
+		codigo := leer("Ingrese el código de la materia: ")
+		componente := leer("Ingrese el nombre del componente: ")
+		nota := leer(fmt.Sprintf("Ingrese la nota (%.1f a %.1f): ", domain.NotaMinima, domain.NotaMaxima))
+		err = c.calificaciones.RegistrarNota(cedula, codigo, componente, nota)
+	case "5":
+		cedula := leer("Ingrese la cédula del estudiante: ")
+		codigo := leer("Ingrese el código de la materia: ")
+		err = c.calificaciones.QuitarNota(cedula, codigo, leer("Ingrese el nombre del componente: "))
+	case "6":
+		c.mostrarPlanilla(leer("Ingrese el código de la materia: "))
+		return
+	case "7":
+		cedula := leer("Ingrese la cédula del estudiante: ")
+		c.mostrarDefinitiva(cedula, leer("Ingrese el código de la materia: "))
+		return
+	case "8":
+		c.cargarArchivoNotas(scanner)
+		return
+	case "9":
+		c.mostrarHistorialAcademico(scanner, leer("Ingrese la cédula del estudiante: "))
+		return
+	default:
+		fmt.Println("Opción no válida.")
+		return
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("Operación realizada exitosamente!")
+}
+
+// anchoColumnaNota es el ancho de cada columna de componente en la planilla de notas
+const anchoColumnaNota = 14
+
+// mostrarPlanilla imprime la planilla de notas de la materia en el periodo: una fila por
+// inscrito con su nota en cada componente y la definitiva según las reglas vigentes
+func (c *ConsoleUI) mostrarPlanilla(codigo string) {
+	planilla, err := c.calificaciones.PlanillaDeMateria(codigo)
+	if err != nil {
+		fmt.Printf("Error al obtener la planilla: %v\n", err)
+		return
+	}
+	if planilla == nil {
+		fmt.Printf("No se encontró ninguna materia con el código: %s\n", codigo)
+		return
+	}
+
+	reglas := c.calificaciones.Reglas()
+	fmt.Printf("\n=== PLANILLA DE %s (%s), PERIODO %s ===\n", planilla.Materia.Nombre, planilla.Materia.Codigo, c.periodo)
+	if len(planilla.Componentes) == 0 {
+		fmt.Println("La materia no tiene componentes de evaluación")
+		return
+	}
+	pesos := 0
+	fmt.Printf("%-12s %-25s", "CÉDULA", "NOMBRE")
+	for _, componente := range planilla.Componentes {
+		encabezado := fmt.Sprintf("%s %d%%", componente.Nombre, componente.Peso)
+		fmt.Printf(" %*s", anchoColumnaNota, c.truncateString(encabezado, anchoColumnaNota))
+		pesos += componente.Peso
+	}
+	fmt.Printf(" %10s\n", "DEFINITIVA")
+	fmt.Println(strings.Repeat("-", 38+(anchoColumnaNota+1)*len(planilla.Componentes)+11))
+
+	aprobados := 0
+	for _, fila := range planilla.Estudiantes {
+		fmt.Printf("%-12s %-25s", fila.Estudiante.Cedula, c.truncateString(fila.Estudiante.Nombre, 25))
+		for _, componente := range planilla.Componentes {
+			celda := "-"
+			if nota, ok := fila.Notas[componente.Nombre]; ok {
+				celda = domain.FormatearNota(nota, domain.MaximoDecimales)
+			}
+			fmt.Printf(" %*s", anchoColumnaNota, celda)
+		}
+		fmt.Printf(" %10s %s\n", domain.FormatearNota(fila.Definitiva.Nota, reglas.Decimales), estadoDefinitiva(fila.Definitiva))
+		if fila.Definitiva.Aprobada {
+			aprobados++
+		}
+	}
+	if len(planilla.Estudiantes) == 0 {
+		fmt.Println("No hay estudiantes inscritos en esta materia")
+	}
+	fmt.Printf("\nAprobados: %d de %d (nota aprobatoria %s; redondeo: %s, decimales: %d)\n",
+		aprobados, len(planilla.Estudiantes), domain.FormatearNota(reglas.NotaAprobatoria, domain.MaximoDecimales), reglas.Redondeo, reglas.Decimales)
+	if pesos < domain.PesoTotal {
+		fmt.Printf("Advertencia: los componentes suman %d%%, las definitivas quedan parciales hasta completar el 100%%\n", pesos)
+	}
+}
+
+// estadoDefinitiva describe una definitiva: aprobada, reprobada o parcial con el peso calificado
+func estadoDefinitiva(d domain.Definitiva) string {
+	switch {
+	case !d.Completa:
+		return fmt.Sprintf("(parcial, %d%% calificado)", d.PesoCalificado)
+	case d.Aprobada:
+		return "Aprobada"
+	default:
+		return "Reprobada"
+	}
+}
+
+func (c *ConsoleUI) mostrarDefinitiva(cedula, codigo string) {
+	notas, err := c.calificaciones.DefinitivaDeInscripcion(cedula, codigo)
+	if err != nil {
+		fmt.Printf("Error al obtener las notas: %v\n", err)
+		return
+	}
+	if notas == nil {
+		fmt.Printf("El estudiante %s no está inscrito en %s en el periodo %s\n", cedula, codigo, c.periodo)
+		return
+	}
+	componentes, err := c.calificaciones.ComponentesDeMateria(codigo)
+	if err != nil {
+		fmt.Printf("Error al obtener los componentes: %v\n", err)
+		return
+	}
+
+	fmt.Printf("\n=== NOTAS DE %s EN %s, PERIODO %s ===\n", notas.Estudiante.Nombre, codigo, c.periodo)
+	for _, componente := range componentes {
+		nota := "sin nota"
+		if valor, ok := notas.Notas[componente.Nombre]; ok {
+			nota = domain.FormatearNota(valor, domain.MaximoDecimales)
+		}
+		fmt.Printf("- %s (%d%%): %s\n", componente.Nombre, componente.Peso, nota)
+	}
+	fmt.Printf("Definitiva: %s %s\n", domain.FormatearNota(notas.Definitiva.Nota, c.calificaciones.Reglas().Decimales), estadoDefinitiva(notas.Definitiva))
+}
+
+func (c *ConsoleUI) cargarArchivoNotas(scanner *bufio.Scanner) {
+	fmt.Print("\nIngrese la ruta del archivo de notas: ")
+	scanner.Scan()
+	ruta := scanner.Text()
+
+	// Como en los archivos de inscripciones, los nombres sueltos se buscan en testdata
+	if !filepath.IsAbs(ruta) && !strings.Contains(ruta, string(filepath.Separator)) {
+		ruta = filepath.Join("testdata", ruta)
+	}
+
+	registradas, err := c.procesadorNotas.ProcesarArchivoEnPeriodo(ruta, c.periodo)
+	if err != nil {
+		fmt.Printf("\nError al procesar archivo: %v\n", err)
+		return
+	}
+	fmt.Printf("\nArchivo de notas cargado en el periodo %s: %d notas registradas\n", c.periodo, registradas)
+}
+
+// mostrarHistorialAcademico imprime el historial académico del estudiante en todos los
+// periodos y ofrece guardarlo como documento de texto o como JSON
+func (c *ConsoleUI) mostrarHistorialAcademico(scanner *bufio.Scanner, cedula string) {
+	estudiante, _, err := c.consultasAvanzadas.BuscarEstudiantePorCedula(cedula)
+	if err != nil {
+		fmt.Printf("Error al buscar estudiante: %v\n", err)
+		return
+	}
+	if estudiante == nil {
+		fmt.Printf("No se encontró un estudiante con cédula: %s\n", cedula)
+		return
+	}
+	historial, err := c.historialAcademico.HistorialDe(estudiante)
+	if err != nil {
+		fmt.Printf("Error al obtener el historial académico: %v\n", err)
+		return
+	}
+
+	fmt.Println()
+	decimales := c.historialAcademico.Reglas().Decimales
+	c.escribirHistorialAcademico(os.Stdout, historial, decimales)
+
+	fmt.Print("\nExportar el historial (1. Documento de texto, 2. JSON, Enter para no exportar): ")
+	scanner.Scan()
+	var filename string
+	switch strings.TrimSpace(scanner.Text()) {
+	case "1":
+		filename = fmt.Sprintf("historial_%s.txt", estudiante.Cedula)
+		err = c.exportarHistorialTexto(filename, historial, decimales)
+	case "2":
+		filename = fmt.Sprintf("historial_%s.json", estudiante.Cedula)
+		err = exportarHistorialJSON(filename, historial)
+	default:
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error al exportar el historial: %v\n", err)
+		return
+	}
+	fmt.Printf("Historial exportado exitosamente a %s\n", filename)
+}
+
+// escribirHistorialAcademico escribe el historial como documento: una tabla de materias por
+// periodo, con el promedio del periodo, y al final el promedio acumulado
+func (c *ConsoleUI) escribirHistorialAcademico(w io.Writer, h *service.HistorialAcademico, decimales int) {
+	fmt.Fprintln(w, "=== HISTORIAL ACADÉMICO ===")
+	fmt.Fprintf(w, "Estudiante: %s\n", h.Estudiante.Nombre)
+	fmt.Fprintf(w, "Cédula: %s\n", h.Estudiante.Cedula)
+	if len(h.Periodos) == 0 {
+		fmt.Fprintln(w, "\nEl estudiante no ha cursado materias.")
+		return
+	}
+
+	for _, p := range h.Periodos {
+		fmt.Fprintf(w, "\nPeriodo %s\n", p.Periodo)
+		fmt.Fprintf(w, "%-10s %-30s %8s %10s  %s\n", "CÓDIGO", "MATERIA", "CRÉDITOS", "DEFINITIVA", "ESTADO")
+		fmt.Fprintln(w, strings.Repeat("-", 72))
+		for _, m := range p.Materias {
+			nota := "-"
+			if m.Definitiva.Completa {
+				nota = domain.FormatearNota(m.Definitiva.Nota, decimales)
+			}
+			fmt.Fprintf(w, "%-10s %-30s %8d %10s  %s\n", m.Materia.Codigo, c.truncateString(m.Materia.Nombre, 30), m.Materia.Creditos, nota, m.Estado())
+		}
+		fmt.Fprintf(w, "Créditos: %d inscritos, %d aprobados", p.CreditosInscritos, p.CreditosAprobados)
+		if p.CreditosCalificados > 0 {
+			fmt.Fprintf(w, " | Promedio del periodo: %s", domain.FormatearNota(p.Promedio, domain.MaximoDecimales))
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, strings.Repeat("=", 72))
+	fmt.Fprintf(w, "Créditos aprobados: %d\n", h.CreditosAprobados)
+	if h.CreditosCalificados > 0 {
+		fmt.Fprintf(w, "Promedio acumulado: %s (%d créditos calificados)\n",
+			domain.FormatearNota(h.PromedioAcumulado, domain.MaximoDecimales), h.CreditosCalificados)
+	} else {
+		fmt.Fprintln(w, "Promedio acumulado: sin materias calificadas")
+	}
+}
+
+func (c *ConsoleUI) exportarHistorialTexto(filename string, h *service.HistorialAcademico, decimales int) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error al crear archivo: %w", err)
+	}
+	c.escribirHistorialAcademico(file, h, decimales)
+	return file.Close()
+}
+
+func exportarHistorialJSON(filename string, h *service.HistorialAcademico) error {
+	type MateriaExport struct {
+		Codigo     string   `json:"codigo"`
+		Nombre     string   `json:"nombre"`
+		Creditos   int      `json:"creditos"`
+		Definitiva *float64 `json:"definitiva"`
+		Estado     string   `json:"estado"`
+	}
+
+	type PeriodoExport struct {
+		Periodo           string          `json:"periodo"`
+		Materias          []MateriaExport `json:"materias"`
+		CreditosInscritos int             `json:"creditos_inscritos"`
+		CreditosAprobados int             `json:"creditos_aprobados"`
+		Promedio          *float64        `json:"promedio"`
+	}
+
+	type HistorialExport struct {
+		Cedula            string          `json:"cedula"`
+		Nombre            string          `json:"nombre"`
+		Periodos          []PeriodoExport `json:"periodos"`
+		CreditosAprobados int             `json:"creditos_aprobados"`
+		PromedioAcumulado *float64        `json:"promedio_acumulado"`
+	}
+
+	// Sin materias calificadas no hay promedio: se exporta null en lugar de cero
+	promedio := func(valor float64, creditos int) *float64 {
+		if creditos == 0 {
+			return nil
+		}
+		return &valor
+	}
+
+	export := HistorialExport{
+		Cedula:            h.Estudiante.Cedula,
+		Nombre:            h.Estudiante.Nombre,
+		Periodos:          []PeriodoExport{},
+		CreditosAprobados: h.CreditosAprobados,
+		PromedioAcumulado: promedio(h.PromedioAcumulado, h.CreditosCalificados),
+	}
+	for _, p := range h.Periodos {
+		periodo := PeriodoExport{
+			Periodo:           p.Periodo,
+			CreditosInscritos: p.CreditosInscritos,
+			CreditosAprobados: p.CreditosAprobados,
+			Promedio:          promedio(p.Promedio, p.CreditosCalificados),
+		}
+		for _, m := range p.Materias {
+			materia := MateriaExport{Codigo: m.Materia.Codigo, Nombre: m.Materia.Nombre, Creditos: m.Materia.Creditos, Estado: m.Estado()}
+			if m.Definitiva.Completa {
+				nota := m.Definitiva.Nota
+				materia.Definitiva = &nota
+			}
+			periodo.Materias = append(periodo.Materias, materia)
+		}
+		export.Periodos = append(export.Periodos, periodo)
+	}
+
+	jsonData, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error al generar JSON: %w", err)
+	}
+	return os.WriteFile(filename, jsonData, 0644)
+}
+
+func (c *ConsoleUI) confirmar(scanner *bufio.Scanner) bool {
+	fmt.Print("¿Confirma la operación? (s/n): ")
+	scanner.Scan()
+	if strings.EqualFold(strings.TrimSpace(scanner.Text()), "s") {
+		return true
+	}
+	fmt.Println("Operación cancelada.")
+	return false
+}
+
+func (c *ConsoleUI) mostrarEliminados() {
+	eliminados, err := c.eliminacion.ObtenerEliminados(registrosPorPagina)
+	if err != nil {
+		fmt.Printf("Error al obtener registros eliminados: %v\n", err)
+		return
+	}
+
+	if len(eliminados.Estudiantes)+len(eliminados.Materias)+len(eliminados.Inscripciones) == 0 {
+		fmt.Println("\nNo hay registros eliminados.")
+		return
+	}
+
+	formatoFecha := "2006-01-02 15:04:05"
+	if len(eliminados.Estudiantes) > 0 {
+		fmt.Println("\nEstudiantes eliminados:")
+		for _, e := range eliminados.Estudiantes {
+			fmt.Printf("  %-12s %-25s eliminado el %s\n",
+				e.Cedula, c.truncateString(e.Nombre, 25), e.EliminadoEn.Local().Format(formatoFecha))
+		}
+	}
+	if len(eliminados.Materias) > 0 {
+		fmt.Println("\nMaterias eliminadas:")
+		for _, m := range eliminados.Materias {
+			fmt.Printf("  %-12s %-25s eliminada el %s\n",
+				m.Codigo, c.truncateString(m.Nombre, 25), m.EliminadoEn.Local().Format(formatoFecha))
+		}
+	}
+	if len(eliminados.Inscripciones) > 0 {
+		fmt.Println("\nInscripciones ocultas:")
+		for _, i := range eliminados.Inscripciones {
+			motivo := "inscripción eliminada"
+			switch {
+			case i.Estudiante.EliminadoEn != nil:
+				motivo = "estudiante eliminado"
+			case i.Materia.EliminadoEn != nil:
+				motivo = "materia eliminada"
+			}
+			fmt.Printf("  %-12s %-10s (%s)\n", i.Estudiante.Cedula, i.Materia.Codigo, motivo)
+		}
+	}
+}
+
+func (c *ConsoleUI) mostrarEstadisticasGenerales() {
+	estadisticas, err := c.consultasAvanzadas.ObtenerEstadisticasGenerales()
+	if err != nil {
+		fmt.Printf("Error al obtener estadísticas: %v\n", err)
+		return
+	}
+
+	fmt.Printf("\n=== ESTADÍSTICAS GENERALES (periodo %s) ===\n", c.periodo)
+	fmt.Printf("Total de estudiantes: %d\n", estadisticas.TotalEstudiantes)
+	fmt.Printf("Total de materias: %d\n", estadisticas.TotalMaterias)
+	fmt.Printf("Total de inscripciones: %d\n\n", estadisticas.TotalInscripciones)
+
+	if len(estadisticas.EstudiantesConMasMaterias) > 0 {
+		fmt.Println("TOP 5 - Estudiantes con más materias:")
+		for i, item := range estadisticas.EstudiantesConMasMaterias {
+			fmt.Printf("%d. %s (%s): %d materias\n", 
+				i+1, item.Estudiante.Nombre, item.Estudiante.Cedula, item.CantidadMaterias)
+		}
+		fmt.Println()
+	}
+
+	if len(estadisticas.MateriasConMasEstudiantes) > 0 {
+		fmt.Println("TOP 5 - Materias con más estudiantes:")
+		for i, item := range estadisticas.MateriasConMasEstudiantes {
+			fmt.Printf("%d. %s (%s): %d estudiantes\n", 
+				i+1, item.Materia.Nombre, item.Materia.Codigo, item.CantidadEstudiantes)
+		}
+	}
+}
+
+func (c *ConsoleUI) insertarNuevoRegistro(scanner *bufio.Scanner) {
+	fmt.Println("\n=== INSERTAR NUEVO REGISTRO ===")
+	
+	fmt.Print("Ingrese la cédula del estudiante: ")
+	scanner.Scan()
+	cedula := strings.TrimSpace(scanner.Text())
+	
+	fmt.Print("Ingrese el nombre del estudiante: ")
+	scanner.Scan()
+	nombreEstudiante := strings.TrimSpace(scanner.Text())
+	
+	fmt.Print("Ingrese el código de la materia: ")
+	scanner.Scan()
+	codigoMateria := strings.TrimSpace(scanner.Text())
+	
+	fmt.Print("Ingrese el nombre de la materia: ")
+	scanner.Scan()
+	nombreMateria := strings.TrimSpace(scanner.Text())
+
+	fmt.Printf("Créditos de la materia, si es nueva (Enter para %d): ", domain.CreditosPorDefecto)
+	scanner.Scan()
+	creditos := domain.CreditosPorDefecto
+	if texto := strings.TrimSpace(scanner.Text()); texto != "" {
+		n, err := strconv.Atoi(texto)
+		if err != nil {
+			fmt.Println("Los créditos deben ser un número entero.")
+			return
+		}
+		creditos = n
+	}
+
+	fmt.Print("Grupo de la materia (Enter para ninguno): ")
+	scanner.Scan()
+	grupo := domain.SinGrupo
+	if texto := strings.TrimSpace(scanner.Text()); texto != "" {
+		n, err := strconv.Atoi(texto)
+		if err != nil {
+			fmt.Println("El grupo debe ser un número entero.")
+			return
+		}
+		grupo = n
+	}
+
+	// Validaciones básicas
+	if cedula == "" || nombreEstudiante == "" || codigoMateria == "" || nombreMateria == "" {
+		fmt.Println("Todos los campos son obligatorios.")
+		return
+	}
+
+	resultado, err := c.consultasAvanzadas.InsertarNuevoRegistroEnGrupo(cedula, nombreEstudiante, codigoMateria, nombreMateria, creditos, grupo)
+	if errors.Is(err, service.ErrCargaExcedida) {
+		fmt.Printf("No se insertó el registro: %v\n", err)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error al insertar registro: %v\n", err)
+		return
+	}
+	// Sin cupo, el servicio deja al estudiante en la lista de espera en lugar de inscribirlo
+	if resultado.EnEspera {
+		fmt.Printf("La materia no tiene cupo: el estudiante quedó en lista de espera en la posición %d.\n", resultado.Posicion)
+		return
+	}
+	fmt.Println("Registro insertado exitosamente!")
+}
+
+func (c *ConsoleUI) mostrarTodosLosRegistros(scanner *bufio.Scanner) {
+	fmt.Print("\nFiltrar por nombre de estudiante (Enter para omitir): ")
+	scanner.Scan()
+	nombre := strings.TrimSpace(scanner.Text())
+
+	fmt.Print("Filtrar por código de materia (Enter para omitir): ")
+	scanner.Scan()
+	codigo := strings.TrimSpace(scanner.Text())
+
+	consulta := repository.Consulta{
+		Limite: registrosPorPagina,
+		Nombre: nombre,
+		Codigo: codigo,
+	}
+
+	for numeroPagina := 1; ; numeroPagina++ {
+		registros, siguiente, err := c.consultasAvanzadas.ObtenerPaginaDeRegistros(consulta)
+		if err != nil {
+			fmt.Printf("Error al obtener registros: %v\n", err)
+			return
+		}
+
+		if len(registros) == 0 && numeroPagina == 1 {
+			fmt.Println("\nNo hay registros en la base de datos.")
+			return
+		}
+
+		fmt.Printf("\n=== TODOS LOS REGISTROS (página %d) ===\n", numeroPagina)
+		fmt.Printf("%-12s %-25s %-10s %-25s\n", "CÉDULA", "NOMBRE ESTUDIANTE", "CÓD MAT", "NOMBRE MATERIA")
+		fmt.Println(strings.Repeat("-", 75))
+
+		for _, registro := range registros {
+			fmt.Printf("%-12s %-25s %-10s %-25s\n",
+				registro.Estudiante.Cedula,
+				c.truncateString(registro.Estudiante.Nombre, 25),
+				registro.Materia.Codigo,
+				c.truncateString(registro.Materia.Nombre, 25))
+		}
+
+		if siguiente == "" {
+			return
+		}
+
+		fmt.Print("\nPresione Enter para ver la siguiente página o 'q' para volver: ")
+		scanner.Scan()
+		if strings.EqualFold(strings.TrimSpace(scanner.Text()), "q") {
+			return
+		}
+		consulta.Cursor = siguiente
+	}
+}
+
+func (c *ConsoleUI) exportarJSON() {
+	if !c.archivoCargado {
+		fmt.Println("\nPrimero debe cargar un archivo de inscripciones (Opción 1)")
+		return
+	}
+
+	type EstudianteExport struct {
+		Cedula string `json:"cedula"`
+		Nombre string `json:"nombre"`
+	}
+
+	type MateriaExport struct {
+		Codigo   string `json:"codigo"`
+		Nombre   string `json:"nombre"`
+		Creditos int    `json:"creditos"`
+	}
+
+	type InscripcionExport struct {
+		Estudiante EstudianteExport `json:"estudiante"`
+		Materia    MateriaExport    `json:"materia"`
+		Periodo    string           `json:"periodo"`
+	}
+
+	registros, err := c.inscripcionesDelConsolidado()
+	if err != nil {
+		fmt.Printf("Error al obtener inscripciones: %v\n", err)
+		return
+	}
+
+	var inscripciones []InscripcionExport
+
+	for _, inscripcion := range registros {
+		inscripciones = append(inscripciones, InscripcionExport{
+			Estudiante: EstudianteExport{
+				Cedula: inscripcion.Estudiante.Cedula,
+				Nombre: inscripcion.Estudiante.Nombre,
+			},
+			Materia: MateriaExport{
+				Codigo:   inscripcion.Materia.Codigo,
+				Nombre:   inscripcion.Materia.Nombre,
+				Creditos: inscripcion.Materia.Creditos,
+			},
+			Periodo: inscripcion.Periodo,
+		})
+	}
+
+	jsonData, err := json.MarshalIndent(inscripciones, "", "  ")
+	if err != nil {
+		fmt.Printf("Error al generar JSON: %v\n", err)
+		return
+	}
+
+	filename := "inscripciones.json"
+	err = os.WriteFile(filename, jsonData, 0644)
+	if err != nil {
+		fmt.Printf("Error al escribir archivo JSON: %v\n", err)
+		return
+	}
+
+	fmt.Printf("\nDatos exportados exitosamente a %s\n", filename)
+}
+
+func (c *ConsoleUI) exportarCSV() {
+	if !c.archivoCargado {
+		fmt.Println("\nPrimero debe cargar un archivo de inscripciones (Opción 1)")
+		return
+	}
+
+	registros, err := c.inscripcionesDelConsolidado()
+	if err != nil {
+		fmt.Printf("Error al obtener inscripciones: %v\n", err)
+		return
+	}
+
+	filename := "inscripciones.csv"
+	file, err := os.Create(filename)
+	if err != nil {
+		fmt.Printf("Error al crear archivo CSV: %v\n", err)
+		return
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	// Escribir encabezados
+	headers := []string{"CEDULA", "NOMBRE_ESTUDIANTE", "CODIGO_MATERIA", "NOMBRE_MATERIA", "CREDITOS", "PERIODO"}
+	if err := writer.Write(headers); err != nil {
+		fmt.Printf("Error al escribir encabezados CSV: %v\n", err)
+		return
+	}
+
+	// Escribir datos
+	for _, inscripcion := range registros {
+		record := []string{
+			inscripcion.Estudiante.Cedula,
+			inscripcion.Estudiante.Nombre,
+			inscripcion.Materia.Codigo,
+			inscripcion.Materia.Nombre,
+			strconv.Itoa(inscripcion.Materia.Creditos),
+			inscripcion.Periodo,
+		}
+		if err := writer.Write(record); err != nil {
+			fmt.Printf("Error al escribir registro CSV: %v\n", err)
+			continue
+		}
+	}
+
+	fmt.Printf("\nDatos exportados exitosamente a %s\n", filename)
+}
+
+// inscripcionesDelConsolidado obtiene en una sola consulta las inscripciones de los estudiantes del archivo cargado
+func (c *ConsoleUI) inscripcionesDelConsolidado() ([]*domain.Inscripcion, error) {
+	todas, err := c.inscripcionSvc.ObtenerTodasLasInscripciones()
+	if err != nil {
+		return nil, err
+	}
+
+	var inscripciones []*domain.Inscripcion
+	for _, inscripcion := range todas {
+		if _, ok := c.consolidado.Estudiantes[inscripcion.Estudiante.Cedula]; ok {
+			inscripciones = append(inscripciones, inscripcion)
+		}
+	}
+	return inscripciones, nil
+}
+
+// Función auxiliar para truncar strings
+func (c *ConsoleUI) truncateString(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
 	}
 	return s[:maxLen-3] + "..."
-}
\ No newline at end of file
+}
+
+func (c *ConsoleUI) administrarCarreras(scanner *bufio.Scanner) {
+	fmt.Println("\n=== CARRERAS Y PENSUMS ===")
+	fmt.Println("1. Crear carrera")
+	fmt.Println("2. Cambiar el nombre o los créditos de una carrera")
+	fmt.Println("3. Eliminar carrera")
+	fmt.Println("4. Ver carreras")
+	fmt.Println("5. Agregar una materia al pensum")
+	fmt.Println("6. Quitar una materia del pensum")
+	fmt.Println("7. Ver el pensum de una carrera")
+	fmt.Println("8. Asignar un estudiante a una carrera")
+	fmt.Println("9. Quitar a un estudiante de su carrera")
+	fmt.Println("10. Ver los estudiantes de una carrera")
+	fmt.Println("11. Auditoría de grado de un estudiante")
+	fmt.Print("Seleccione una opción: ")
+	scanner.Scan()
+	opcion := strings.TrimSpace(scanner.Text())
+
+	leer := func(mensaje string) string {
+		fmt.Print(mensaje)
+		scanner.Scan()
+		return strings.TrimSpace(scanner.Text())
+	}
+	leerNumero := func(mensaje, nombre string) (int, bool) {
+		numero, err := strconv.Atoi(leer(mensaje))
+		if err != nil {
+			fmt.Printf("%s debe ser un número entero.\n", nombre)
+			return 0, false
+		}
+		return numero, true
+	}
+
+	var err error
+	switch opcion {
+	case "1", "2":
+		codigo := leer("Ingrese el código de la carrera: ")
+		nombre := leer("Ingrese el nombre de la carrera: ")
+		creditos, ok := leerNumero("Ingrese los créditos necesarios para graduarse: ", "Los créditos")
+		if !ok {
+			return
+		}
+		if opcion == "1" {
+			err = c.carreras.CrearCarrera(codigo, nombre, creditos)
+		} else {
+			err = c.carreras.ActualizarCarrera(codigo, nombre, creditos)
+		}
+	case "3":
+		err = c.carreras.EliminarCarrera(leer("Ingrese el código de la carrera: "))
+	case "4":
+		c.mostrarCarreras()
+		return
+	case "5":
+		carrera := leer("Ingrese el código de la carrera: ")
+		materia := leer("Ingrese el código de la materia: ")
+		semestre, ok := leerNumero(fmt.Sprintf("Ingrese el semestre (1 a %d): ", domain.MaximoSemestres), "El semestre")
+		if !ok {
+			return
+		}
+		obligatoria := !strings.EqualFold(leer("¿Es obligatoria? (S/n): "), "n")
+		err = c.carreras.AgregarAlPensum(carrera, materia, semestre, obligatoria)
+	case "6":
+		carrera := leer("Ingrese el código de la carrera: ")
+		err = c.carreras.QuitarDelPensum(carrera, leer("Ingrese el código de la materia: "))
+	case "7":
+		c.mostrarPensum(leer("Ingrese el código de la carrera: "))
+		return
+	case "8":
+		cedula := leer("Ingrese la cédula del estudiante: ")
+		err = c.carreras.AsignarEstudiante(cedula, leer("Ingrese el código de la carrera: "))
+	case "9":
+		err = c.carreras.QuitarEstudiante(leer("Ingrese la cédula del estudiante: "))
+	case "10":
+		c.mostrarEstudiantesDeCarrera(leer("Ingrese el código de la carrera: "))
+		return
+	case "11":
+		c.mostrarAuditoriaDeGrado(leer("Ingrese la cédula del estudiante: "))
+		return
+	default:
+		fmt.Println("Opción no válida.")
+		return
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("Operación realizada exitosamente!")
+}
+
+func (c *ConsoleUI) mostrarCarreras() {
+	carreras, err := c.carreras.ListarCarreras()
+	if err != nil {
+		fmt.Printf("Error al obtener las carreras: %v\n", err)
+		return
+	}
+	if len(carreras) == 0 {
+		fmt.Println("No hay carreras registradas.")
+		return
+	}
+
+	fmt.Println("\n=== CARRERAS ===")
+	fmt.Printf("%-10s %-40s %8s\n", "CÓDIGO", "NOMBRE", "CRÉDITOS")
+	fmt.Println(strings.Repeat("-", 60))
+	for _, carrera := range carreras {
+		fmt.Printf("%-10s %-40s %8d\n", carrera.Codigo, c.truncateString(carrera.Nombre, 40), carrera.Creditos)
+	}
+}
+
+// nombreMateriaPensum retorna el nombre de la materia del pensum, o una marca si fue eliminada
+func nombreMateriaPensum(m *domain.Materia) (string, int) {
+	if m == nil {
+		return "(materia eliminada)", 0
+	}
+	return m.Nombre, m.Creditos
+}
+
+// tipoMateriaPensum describe si la materia es obligatoria o electiva
+func tipoMateriaPensum(obligatoria bool) string {
+	if obligatoria {
+		return "Obligatoria"
+	}
+	return "Electiva"
+}
+
+// mostrarPensum imprime el pensum de la carrera agrupado por semestre
+func (c *ConsoleUI) mostrarPensum(codigo string) {
+	pensum, err := c.carreras.PensumDeCarrera(codigo)
+	if err != nil {
+		fmt.Printf("Error al obtener el pensum: %v\n", err)
+		return
+	}
+	if pensum == nil {
+		fmt.Printf("No se encontró la carrera: %s\n", codigo)
+		return
+	}
+
+	fmt.Printf("\n=== PENSUM DE %s - %s ===\n", pensum.Carrera.Codigo, pensum.Carrera.Nombre)
+	fmt.Printf("Créditos para graduarse: %d\n", pensum.Carrera.Creditos)
+	if len(pensum.Materias) == 0 {
+		fmt.Println("El pensum no tiene materias.")
+		return
+	}
+	semestre := 0
+	for _, m := range pensum.Materias {
+		if m.Semestre != semestre {
+			semestre = m.Semestre
+			fmt.Printf("\nSemestre %d\n", semestre)
+			fmt.Printf("%-10s %-30s %8s  %s\n", "CÓDIGO", "MATERIA", "CRÉDITOS", "TIPO")
+			fmt.Println(strings.Repeat("-", 62))
+		}
+		nombre, creditos := nombreMateriaPensum(m.Materia)
+		fmt.Printf("%-10s %-30s %8d  %s\n", m.Codigo, c.truncateString(nombre, 30), creditos, tipoMateriaPensum(m.Obligatoria))
+	}
+}
+
+func (c *ConsoleUI) mostrarEstudiantesDeCarrera(codigo string) {
+	estudiantes, err := c.carreras.EstudiantesDeCarrera(codigo)
+	if err != nil {
+		fmt.Printf("Error al obtener los estudiantes: %v\n", err)
+		return
+	}
+	if len(estudiantes) == 0 {
+		fmt.Printf("La carrera %s no tiene estudiantes.\n", codigo)
+		return
+	}
+
+	fmt.Printf("\n=== ESTUDIANTES DE %s ===\n", codigo)
+	fmt.Printf("%-15s %-30s\n", "CÉDULA", "NOMBRE")
+	fmt.Println(strings.Repeat("-", 46))
+	for _, e := range estudiantes {
+		fmt.Printf("%-15s %-30s\n", e.Cedula, c.truncateString(e.Nombre, 30))
+	}
+	fmt.Printf("Total: %d estudiantes\n", len(estudiantes))
+}
+
+// mostrarAuditoriaDeGrado imprime, semestre por semestre, el estado de cada materia del
+// pensum para el estudiante, y al final cuántas obligatorias y créditos le faltan
+func (c *ConsoleUI) mostrarAuditoriaDeGrado(cedula string) {
+	auditoria, err := c.carreras.AuditoriaDeGrado(cedula)
+	if err != nil {
+		fmt.Printf("Error al auditar el grado: %v\n", err)
+		return
+	}
+	if auditoria == nil {
+		fmt.Printf("No se encontró un estudiante con cédula: %s\n", cedula)
+		return
+	}
+
+	fmt.Println("\n=== AUDITORÍA DE GRADO ===")
+	fmt.Printf("Estudiante: %s (%s)\n", auditoria.Estudiante.Nombre, auditoria.Estudiante.Cedula)
+	fmt.Printf("Carrera: %s - %s\n", auditoria.Carrera.Codigo, auditoria.Carrera.Nombre)
+	semestre := 0
+	for _, m := range auditoria.Materias {
+		if m.Semestre != semestre {
+			semestre = m.Semestre
+			fmt.Printf("\nSemestre %d\n", semestre)
+			fmt.Printf("%-10s %-30s %8s  %-12s %s\n", "CÓDIGO", "MATERIA", "CRÉDITOS", "TIPO", "ESTADO")
+			fmt.Println(strings.Repeat("-", 75))
+		}
+		nombre, creditos := nombreMateriaPensum(m.Materia)
+		fmt.Printf("%-10s %-30s %8d  %-12s %s\n", m.Codigo, c.truncateString(nombre, 30), creditos, tipoMateriaPensum(m.Obligatoria), m.Estado)
+	}
+
+	fmt.Println(strings.Repeat("=", 75))
+	fmt.Printf("Obligatorias: %d completadas, %d en curso, %d faltantes\n", auditoria.Completadas, auditoria.EnCurso, auditoria.Faltantes)
+	fmt.Printf("Créditos: %d aprobados, %d en curso, %d restantes de %d\n",
+		auditoria.CreditosAprobados, auditoria.CreditosEnCurso, auditoria.CreditosRestantes, auditoria.Carrera.Creditos)
+	if auditoria.PuedeGraduarse() {
+		fmt.Println("El estudiante cumple los requisitos de grado de la carrera.")
+	}
+}
+
+func (c *ConsoleUI) administrarDocentes(scanner *bufio.Scanner) {
+	fmt.Println("\n=== DOCENTES ===")
+	fmt.Println("1. Registrar docente")
+	fmt.Println("2. Cambiar el nombre de un docente")
+	fmt.Println("3. Eliminar docente")
+	fmt.Println("4. Ver docentes")
+	fmt.Println("5. Asignar un docente a una materia o grupo")
+	fmt.Println("6. Quitar el docente de una materia o grupo")
+	fmt.Println("7. Ver las materias que dicta un docente")
+	fmt.Println("8. Ver los estudiantes de un docente")
+	fmt.Println("9. Carga docente del periodo")
+	fmt.Println("10. Cargar archivo de docentes")
+	fmt.Print("Seleccione una opción: ")
+	scanner.Scan()
+	opcion := strings.TrimSpace(scanner.Text())
+
+	leer := func(mensaje string) string {
+		fmt.Print(mensaje)
+		scanner.Scan()
+		return strings.TrimSpace(scanner.Text())
+	}
+	leerGrupo := func() (int, bool) {
+		texto := leer("Ingrese el grupo (Enter para la materia completa): ")
+		if texto == "" {
+			return domain.SinGrupo, true
+		}
+		grupo, err := strconv.Atoi(texto)
+		if err != nil {
+			fmt.Println("El grupo debe ser un número entero.")
+			return 0, false
+		}
+		return grupo, true
+	}
+
+	var err error
+	switch opcion {
+	case "1", "2":
+		cedula := leer("Ingrese la cédula del docente: ")
+		nombre := leer("Ingrese el nombre del docente: ")
+		if opcion == "1" {
+			err = c.docentes.CrearDocente(cedula, nombre)
+		} else {
+			err = c.docentes.ActualizarDocente(cedula, nombre)
+		}
+	case "3":
+		err = c.docentes.EliminarDocente(leer("Ingrese la cédula del docente: "))
+	case "4":
+		c.mostrarDocentes()
+		return
+	case "5":
+		cedula := leer("Ingrese la cédula del docente: ")
+		materia := leer("Ingrese el código de la materia: ")
+		grupo, ok := leerGrupo()
+		if !ok {
+			return
+		}
+		err = c.docentes.AsignarMateria(cedula, materia, grupo)
+	case "6":
+		materia := leer("Ingrese el código de la materia: ")
+		grupo, ok := leerGrupo()
+		if !ok {
+			return
+		}
+		err = c.docentes.QuitarAsignacion(materia, grupo)
+	case "7":
+		c.mostrarMateriasDeDocente(leer("Ingrese la cédula del docente: "))
+		return
+	case "8":
+		c.mostrarEstudiantesDeDocente(leer("Ingrese la cédula del docente: "))
+		return
+	case "9":
+		c.mostrarCargaDocente()
+		return
+	case "10":
+		c.cargarArchivoDocentes(scanner)
+		return
+	default:
+		fmt.Println("Opción no válida.")
+		return
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("Operación realizada exitosamente!")
+}
+
+func (c *ConsoleUI) mostrarDocentes() {
+	docentes, err := c.docentes.ListarDocentes()
+	if err != nil {
+		fmt.Printf("Error al obtener los docentes: %v\n", err)
+		return
+	}
+	if len(docentes) == 0 {
+		fmt.Println("No hay docentes registrados.")
+		return
+	}
+
+	fmt.Println("\n=== DOCENTES ===")
+	fmt.Printf("%-15s %-30s\n", "CÉDULA", "NOMBRE")
+	fmt.Println(strings.Repeat("-", 46))
+	for _, d := range docentes {
+		fmt.Printf("%-15s %-30s\n", d.Cedula, c.truncateString(d.Nombre, 30))
+	}
+	fmt.Printf("Total: %d docentes\n", len(docentes))
+}
+
+// grupoDictado describe el grupo de una asignación en la columna GRUPO
+func grupoDictado(grupo int) string {
+	if grupo == domain.SinGrupo {
+		return "Todos"
+	}
+	return strconv.Itoa(grupo)
+}
+
+// mostrarMateriasDeDocente imprime las materias y grupos que dicta el docente en el periodo
+// con sus inscritos
+func (c *ConsoleUI) mostrarMateriasDeDocente(cedula string) {
+	carga, err := c.docentes.MateriasDeDocente(cedula)
+	if err != nil {
+		fmt.Printf("Error al obtener las materias del docente: %v\n", err)
+		return
+	}
+	if carga == nil {
+		fmt.Printf("No se encontró un docente con cédula: %s\n", cedula)
+		return
+	}
+
+	fmt.Printf("\n=== MATERIAS DE %s (%s) - PERIODO %s ===\n", carga.Docente.Nombre, carga.Docente.Cedula, c.periodo)
+	if len(carga.Materias) == 0 {
+		fmt.Println("El docente no dicta materias en el periodo.")
+		return
+	}
+	fmt.Printf("%-10s %-30s %6s %10s\n", "CÓDIGO", "MATERIA", "GRUPO", "INSCRITOS")
+	fmt.Println(strings.Repeat("-", 59))
+	for _, m := range carga.Materias {
+		fmt.Printf("%-10s %-30s %6s %10d\n", m.Asignacion.Materia, c.truncateString(m.Materia.Nombre, 30), grupoDictado(m.Asignacion.Grupo), m.Inscritos)
+	}
+	fmt.Printf("Total: %d materias, %d inscritos\n", len(carga.Materias), carga.TotalInscritos)
+}
+
+func (c *ConsoleUI) mostrarEstudiantesDeDocente(cedula string) {
+	estudiantes, err := c.docentes.EstudiantesDeDocente(cedula)
+	if err != nil {
+		fmt.Printf("Error al obtener los estudiantes: %v\n", err)
+		return
+	}
+	if len(estudiantes) == 0 {
+		fmt.Printf("El docente %s no tiene estudiantes en el periodo %s.\n", cedula, c.periodo)
+		return
+	}
+
+	fmt.Printf("\n=== ESTUDIANTES DEL DOCENTE %s ===\n", cedula)
+	fmt.Printf("%-15s %-30s\n", "CÉDULA", "NOMBRE")
+	fmt.Println(strings.Repeat("-", 46))
+	for _, e := range estudiantes {
+		fmt.Printf("%-15s %-30s\n", e.Cedula, c.truncateString(e.Nombre, 30))
+	}
+	fmt.Printf("Total: %d estudiantes\n", len(estudiantes))
+}
+
+// mostrarCargaDocente imprime, por docente, cuántas materias dicta en el periodo y el total
+// de inscritos en ellas
+func (c *ConsoleUI) mostrarCargaDocente() {
+	cargas, err := c.docentes.CargaDocentes()
+	if err != nil {
+		fmt.Printf("Error al obtener la carga docente: %v\n", err)
+		return
+	}
+	if len(cargas) == 0 {
+		fmt.Println("No hay docentes registrados.")
+		return
+	}
+
+	fmt.Printf("\n=== CARGA DOCENTE - PERIODO %s ===\n", c.periodo)
+	fmt.Printf("%-15s %-30s %9s %10s\n", "CÉDULA", "NOMBRE", "MATERIAS", "INSCRITOS")
+	fmt.Println(strings.Repeat("-", 67))
+	for _, carga := range cargas {
+		fmt.Printf("%-15s %-30s %9d %10d\n", carga.Docente.Cedula, c.truncateString(carga.Docente.Nombre, 30), len(carga.Materias), carga.TotalInscritos)
+	}
+}
+
+func (c *ConsoleUI) cargarArchivoDocentes(scanner *bufio.Scanner) {
+	fmt.Print("\nIngrese la ruta del archivo de docentes: ")
+	scanner.Scan()
+	ruta := scanner.Text()
+
+	// Como en los archivos de inscripciones, los nombres sueltos se buscan en testdata
+	if !filepath.IsAbs(ruta) && !strings.Contains(ruta, string(filepath.Separator)) {
+		ruta = filepath.Join("testdata", ruta)
+	}
+
+	registradas, err := c.procesadorDocentes.ProcesarArchivoEnPeriodo(ruta, c.periodo)
+	if err != nil {
+		fmt.Printf("\nError al procesar archivo: %v\n", err)
+		return
+	}
+	fmt.Printf("\nArchivo de docentes cargado en el periodo %s: %d asignaciones registradas\n", c.periodo, registradas)
+}